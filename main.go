@@ -2,16 +2,22 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"log"
 	"os"
+	"os/signal"
 	"runtime"
+	"strings"
+	"syscall"
 
 	"github.com/docker/docker/client"
 	"github.com/go-errors/errors"
 	"github.com/integrii/flaggy"
 	"github.com/jesseduffield/lazydocker/pkg/app"
+	"github.com/jesseduffield/lazydocker/pkg/commands"
 	"github.com/jesseduffield/lazydocker/pkg/config"
+	"github.com/jesseduffield/lazydocker/pkg/gui"
 	"github.com/jesseduffield/yaml"
 )
 
@@ -21,9 +27,15 @@ var (
 	date        string
 	buildSource = "unknown"
 
-	configFlag    = false
-	debuggingFlag = false
-	composeFiles  []string
+	configFlag          = false
+	debuggingFlag       = false
+	printConnectionFlag = false
+	keymapFlag          = false
+	readOnlyFlag        = false
+	composeFiles        []string
+
+	listKind     string
+	listJSONFlag = false
 )
 
 func main() {
@@ -43,9 +55,20 @@ func main() {
 
 	flaggy.Bool(&configFlag, "c", "config", "Print the current default config")
 	flaggy.Bool(&debuggingFlag, "d", "debug", "a boolean")
+	flaggy.Bool(&printConnectionFlag, "", "print-connection", "Print how lazydocker would connect to docker, without connecting")
+	flaggy.Bool(&keymapFlag, "", "keymap", "Print the effective keymap, i.e. the default keybindings with your config's remappings applied")
 	flaggy.StringSlice(&composeFiles, "f", "file", "Specify alternate compose files")
+	flaggy.Bool(&readOnlyFlag, "", "read-only", "Look but don't touch: turn off everything that would change anything, e.g. stopping or removing containers")
+	// the root parser has to know --json is a bool for `ls images --json` to
+	// parse, so it's not just ls's
+	flaggy.Bool(&listJSONFlag, "", "json", "With ls, print JSON rather than a table")
 	flaggy.SetVersion(info)
 
+	listCommand := flaggy.NewSubcommand("ls")
+	listCommand.Description = "Print a list of containers, images, volumes or networks, then exit"
+	listCommand.AddPositionalValue(&listKind, "kind", 1, true, "What to list: "+strings.Join(commands.ListKinds, ", "))
+	flaggy.AttachSubcommand(listCommand, 1)
+
 	flaggy.Parse()
 
 	if configFlag {
@@ -69,7 +92,40 @@ func main() {
 		log.Fatal(err.Error())
 	}
 
-	app, err := app.NewApp(appConfig)
+	if readOnlyFlag {
+		appConfig.UserConfig.ReadOnly = true
+	}
+
+	if printConnectionFlag {
+		plan, err := commands.PreviewDockerConnection(appConfig)
+		if err != nil {
+			log.Fatal(err.Error())
+		}
+		fmt.Println(plan)
+		os.Exit(0)
+	}
+
+	if keymapFlag {
+		keymap, err := gui.GetKeymap(appConfig)
+		if err != nil {
+			log.Fatal(err.Error())
+		}
+		var buf bytes.Buffer
+		encoder := yaml.NewEncoder(&buf)
+		if err := encoder.Encode(config.UserConfig{Keybinding: keymap}); err != nil {
+			log.Fatal(err.Error())
+		}
+		fmt.Printf("%v\n", buf.String())
+		os.Exit(0)
+	}
+
+	if listCommand.Used {
+		listAndExit(appConfig)
+	}
+
+	ctx, stopStartupSignals := newStartupContext()
+	app, err := app.NewApp(ctx, appConfig)
+	stopStartupSignals()
 	if err == nil {
 		err = app.Run()
 	}
@@ -93,3 +149,61 @@ func main() {
 		log.Fatal(fmt.Sprintf("%s\n\n%s", app.Tr.ErrorOccurred, stackTrace))
 	}
 }
+
+// listAndExit prints what `lazydocker ls` asked for. We connect just as we
+// would for the gui, so an ssh:// DOCKER_HOST is tunneled to, and the tunnel
+// is torn down again before we exit.
+func listAndExit(appConfig *config.AppConfig) {
+	known := false
+	for _, kind := range commands.ListKinds {
+		known = known || kind == listKind
+	}
+	if !known {
+		log.Fatalf("can't list %q, only %s", listKind, strings.Join(commands.ListKinds, ", "))
+	}
+
+	ctx, stopStartupSignals := newStartupContext()
+	app, err := app.NewListApp(ctx, appConfig)
+	stopStartupSignals()
+	if err == nil {
+		err = app.List(os.Stdout, listKind, listJSONFlag)
+	}
+	app.Close()
+
+	if err != nil {
+		if errMessage, known := app.KnownError(err); known {
+			log.Fatal(errMessage)
+		}
+		if client.IsErrConnectionFailed(err) {
+			log.Fatal(app.Tr.ConnectionFailed)
+		}
+		log.Fatal(err.Error())
+	}
+	os.Exit(0)
+}
+
+// newStartupContext returns a context which is cancelled if we're interrupted
+// before the gui takes over the terminal, so that e.g. hitting ctrl+c during a
+// hanging ssh handshake aborts it rather than leaving ssh running. Call the
+// returned func once startup is done to restore the default signal handling;
+// the context itself is never cancelled after that.
+func newStartupContext() (context.Context, func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	done := make(chan struct{})
+
+	go func() {
+		select {
+		case <-signals:
+			cancel()
+		case <-done:
+		}
+	}()
+
+	return ctx, func() {
+		signal.Stop(signals)
+		close(done)
+	}
+}
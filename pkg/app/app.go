@@ -1,6 +1,8 @@
 package app
 
 import (
+	"context"
+	"errors"
 	"io"
 	"strings"
 
@@ -26,8 +28,40 @@ type App struct {
 	ErrorChan     chan error
 }
 
-// NewApp bootstrap a new application
-func NewApp(config *config.AppConfig) (*App, error) {
+// NewApp bootstrap a new application. Cancelling ctx aborts any slow startup
+// work, such as setting up an ssh tunnel to the docker host.
+func NewApp(ctx context.Context, config *config.AppConfig) (*App, error) {
+	app, err := newApp(config)
+	if err != nil {
+		return app, err
+	}
+	// checking the keybinding config before we connect to docker, which can
+	// take a while over ssh, so that a typo doesn't mean waiting to find out
+	if _, err := gui.GetKeymap(config); err != nil {
+		return app, err
+	}
+	if err := app.connect(ctx); err != nil {
+		return app, err
+	}
+	app.Gui, err = gui.NewGui(app.Log, app.DockerCommand, app.OSCommand, app.Tr, config, app.ErrorChan)
+	if err != nil {
+		return app, err
+	}
+	return app, nil
+}
+
+// NewListApp bootstraps an application that connects to docker, tunneling
+// over ssh if need be, but only to list things with List rather than run the
+// gui. Close it to tear the tunnel down.
+func NewListApp(ctx context.Context, config *config.AppConfig) (*App, error) {
+	app, err := newApp(config)
+	if err != nil {
+		return app, err
+	}
+	return app, app.connect(ctx)
+}
+
+func newApp(config *config.AppConfig) (*App, error) {
 	app := &App{
 		closers:   []io.Closer{},
 		Config:    config,
@@ -40,19 +74,19 @@ func NewApp(config *config.AppConfig) (*App, error) {
 		return app, err
 	}
 	app.OSCommand = commands.NewOSCommand(app.Log, config)
+	return app, nil
+}
 
+func (app *App) connect(ctx context.Context) error {
 	// here is the place to make use of the docker-compose.yml file in the current directory
 
-	app.DockerCommand, err = commands.NewDockerCommand(app.Log, app.OSCommand, app.Tr, app.Config, app.ErrorChan)
+	var err error
+	app.DockerCommand, err = commands.NewDockerCommand(ctx, app.Log, app.OSCommand, app.Tr, app.Config, app.ErrorChan)
 	if err != nil {
-		return app, err
+		return err
 	}
 	app.closers = append(app.closers, app.DockerCommand)
-	app.Gui, err = gui.NewGui(app.Log, app.DockerCommand, app.OSCommand, app.Tr, config, app.ErrorChan)
-	if err != nil {
-		return app, err
-	}
-	return app, nil
+	return nil
 }
 
 func (app *App) Run() error {
@@ -60,6 +94,19 @@ func (app *App) Run() error {
 	return err
 }
 
+// List prints the containers, images, volumes or networks to w, as JSON if
+// asJSON or otherwise as a table
+func (app *App) List(w io.Writer, kind string, asJSON bool) error {
+	listing, err := app.DockerCommand.List(kind)
+	if err != nil {
+		return err
+	}
+	if asJSON {
+		return listing.WriteJSON(w)
+	}
+	return listing.WriteTable(w)
+}
+
 func (app *App) Close() error {
 	return utils.CloseMany(app.closers)
 }
@@ -71,6 +118,11 @@ type errorMapping struct {
 
 // KnownError takes an error and tells us whether it's an error that we know about where we can print a nicely formatted version of it rather than panicking with a stack trace
 func (app *App) KnownError(err error) (string, bool) {
+	var keybindingErr *gui.KeybindingConfigError
+	if errors.As(err, &keybindingErr) {
+		return keybindingErr.Error(), true
+	}
+
 	errorMessage := err.Error()
 
 	mappings := []errorMapping{
@@ -0,0 +1,47 @@
+package commands
+
+import (
+	"sync"
+)
+
+// bulkWorkers is how many containers a bulk action works on at once. Enough to
+// make acting on a lot of containers quick, not so many that we swamp the
+// daemon.
+const bulkWorkers = 4
+
+// BulkResult is what happened to one container in a bulk action
+type BulkResult struct {
+	Container *Container
+	Err       error
+}
+
+// RunBulkContainerAction runs action on each of the containers, a few at a
+// time, and returns what happened to each of them in the order they were given.
+func RunBulkContainerAction(containers []*Container, action func(*Container) error) []BulkResult {
+	return runBulkContainerAction(containers, bulkWorkers, action)
+}
+
+func runBulkContainerAction(containers []*Container, workers int, action func(*Container) error) []BulkResult {
+	results := make([]BulkResult, len(containers))
+	indices := make(chan int)
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers && i < len(containers); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range indices {
+				// each worker writes to its own slots, so no locking needed
+				results[index] = BulkResult{Container: containers[index], Err: action(containers[index])}
+			}
+		}()
+	}
+
+	for i := range containers {
+		indices <- i
+	}
+	close(indices)
+	wg.Wait()
+
+	return results
+}
@@ -0,0 +1,57 @@
+package commands
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunBulkContainerAction(t *testing.T) {
+	containers := []*Container{{Name: "a"}, {Name: "b"}, {Name: "c"}, {Name: "d"}, {Name: "e"}}
+
+	var mutex sync.Mutex
+	running := 0
+	maxRunning := 0
+	results := runBulkContainerAction(containers, 2, func(container *Container) error {
+		mutex.Lock()
+		running++
+		if running > maxRunning {
+			maxRunning = running
+		}
+		mutex.Unlock()
+
+		time.Sleep(10 * time.Millisecond)
+
+		mutex.Lock()
+		running--
+		mutex.Unlock()
+
+		if container.Name == "c" {
+			return errors.New("no such container")
+		}
+		return nil
+	})
+
+	assert.True(t, maxRunning <= 2, "expected at most 2 at once, got %d", maxRunning)
+	if assert.Len(t, results, 5) {
+		for i, result := range results {
+			assert.Equal(t, containers[i], result.Container)
+			if result.Container.Name == "c" {
+				assert.EqualError(t, result.Err, "no such container")
+			} else {
+				assert.NoError(t, result.Err)
+			}
+		}
+	}
+}
+
+func TestRunBulkContainerActionNoContainers(t *testing.T) {
+	results := runBulkContainerAction(nil, 4, func(container *Container) error {
+		t.Error("expected no calls")
+		return nil
+	})
+	assert.Empty(t, results)
+}
@@ -0,0 +1,114 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/jesseduffield/lazydocker/pkg/utils"
+)
+
+const composeWorkingDirLabel = "com.docker.compose.project.working_dir"
+
+// ComposeProject is a compose project that some of our containers belong to,
+// as told by the labels compose puts on them. Unlike the services panel, which
+// is for the project in the current directory, these can come from anywhere.
+type ComposeProject struct {
+	Name string
+	// WorkingDir is where the project was brought up from, which is where we
+	// run compose to act on the whole stack. It's blank if the containers
+	// don't say.
+	WorkingDir string
+	Containers []*Container
+}
+
+// GroupContainersByProject sorts containers into their compose projects.
+// Projects come in the order their first container does, and containers keep
+// their order within a project. Containers that aren't from a project are
+// returned separately.
+func GroupContainersByProject(containers []*Container) ([]*ComposeProject, []*Container) {
+	projects := []*ComposeProject{}
+	byName := map[string]*ComposeProject{}
+	standalone := []*Container{}
+
+	for _, container := range containers {
+		name := container.ProjectName
+		if name == "" {
+			standalone = append(standalone, container)
+			continue
+		}
+		project, ok := byName[name]
+		if !ok {
+			project = &ComposeProject{Name: name}
+			byName[name] = project
+			projects = append(projects, project)
+		}
+		if project.WorkingDir == "" {
+			project.WorkingDir = container.Container.Labels[composeWorkingDirLabel]
+		}
+		project.Containers = append(project.Containers, container)
+	}
+
+	return projects, standalone
+}
+
+// RunningCount is how many of the project's containers are running
+func (p *ComposeProject) RunningCount() int {
+	count := 0
+	for _, container := range p.Containers {
+		if container.Container.State == "running" {
+			count++
+		}
+	}
+	return count
+}
+
+// GetDisplayStatus sums up the state of the project's containers: running if
+// they all are, exited if none are, and partial otherwise
+func (p *ComposeProject) GetDisplayStatus() string {
+	switch p.RunningCount() {
+	case len(p.Containers):
+		return utils.ColoredString("running", color.FgGreen)
+	case 0:
+		return utils.ColoredString("exited", color.FgYellow)
+	default:
+		return utils.ColoredString("partial", color.FgYellow)
+	}
+}
+
+// GetDisplaySubstatus is how many of the project's containers are running,
+// e.g. (2/3)
+func (p *ComposeProject) GetDisplaySubstatus() string {
+	return fmt.Sprintf("(%d/%d)", p.RunningCount(), len(p.Containers))
+}
+
+// CheckComposeWorkingDir makes sure we've got somewhere to run compose from.
+// The labels stick around after a project's directory is moved or deleted, and
+// compose would then fail to find the project's files.
+func (c *DockerCommand) CheckComposeWorkingDir(project *ComposeProject) error {
+	if project.WorkingDir == "" {
+		return fmt.Errorf(c.Tr.NoComposeWorkingDirError, project.Name)
+	}
+	info, err := os.Stat(project.WorkingDir)
+	if os.IsNotExist(err) || (err == nil && !info.IsDir()) {
+		return fmt.Errorf(c.Tr.ComposeWorkingDirMissingError, project.Name, project.WorkingDir)
+	}
+	if err != nil {
+		return fmt.Errorf("check working directory of compose project %s: %w", project.Name, err)
+	}
+	return nil
+}
+
+// RunComposeProjectCommand runs one of the compose project command templates
+// against the project, from its working directory
+func (c *DockerCommand) RunComposeProjectCommand(project *ComposeProject, templateString string) error {
+	defer c.InvalidateContainerCache()
+	if err := c.CheckComposeWorkingDir(project); err != nil {
+		return err
+	}
+
+	command := utils.ApplyTemplate(templateString, c.NewCommandObject(CommandObject{Project: project}))
+	cmd := c.OSCommand.ExecutableFromString(command)
+	cmd.Dir = project.WorkingDir
+	return c.OSCommand.RunExecutable(cmd)
+}
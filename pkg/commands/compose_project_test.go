@@ -0,0 +1,131 @@
+package commands
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/jesseduffield/lazydocker/pkg/config"
+	"github.com/jesseduffield/lazydocker/pkg/i18n"
+	"github.com/stretchr/testify/assert"
+)
+
+func newProjectContainer(name string, projectName string, workingDir string, state string) *Container {
+	labels := map[string]string{}
+	if workingDir != "" {
+		labels[composeWorkingDirLabel] = workingDir
+	}
+	return &Container{
+		Name:        name,
+		ProjectName: projectName,
+		Container:   types.Container{State: state, Labels: labels},
+	}
+}
+
+func TestGroupContainersByProject(t *testing.T) {
+	web := newProjectContainer("shop_web_1", "shop", "", "running")
+	db := newProjectContainer("shop_db_1", "shop", "/srv/shop", "exited")
+	standalone := newProjectContainer("redis", "", "", "running")
+	blog := newProjectContainer("blog_app_1", "blog", "/srv/blog", "running")
+
+	projects, others := GroupContainersByProject([]*Container{web, standalone, blog, db})
+
+	assert.Len(t, projects, 2)
+	assert.Equal(t, "shop", projects[0].Name)
+	// the first container had no working dir label, so we took the next one's
+	assert.Equal(t, "/srv/shop", projects[0].WorkingDir)
+	assert.EqualValues(t, []*Container{web, db}, projects[0].Containers)
+	assert.Equal(t, "blog", projects[1].Name)
+	assert.EqualValues(t, []*Container{blog}, projects[1].Containers)
+	assert.EqualValues(t, []*Container{standalone}, others)
+}
+
+func TestComposeProjectStatus(t *testing.T) {
+	type scenario struct {
+		testName          string
+		states            []string
+		expectedStatus    string
+		expectedSubstatus string
+	}
+
+	scenarios := []scenario{
+		{"All running", []string{"running", "running"}, "running", "(2/2)"},
+		{"Some running", []string{"running", "exited", "exited"}, "partial", "(1/3)"},
+		{"None running", []string{"exited", "created"}, "exited", "(0/2)"},
+	}
+
+	for _, s := range scenarios {
+		s := s
+		t.Run(s.testName, func(t *testing.T) {
+			project := &ComposeProject{Name: "shop"}
+			for _, state := range s.states {
+				project.Containers = append(project.Containers, newProjectContainer("c", "shop", "", state))
+			}
+			assert.Contains(t, project.GetDisplayStatus(), s.expectedStatus)
+			assert.Equal(t, s.expectedSubstatus, project.GetDisplaySubstatus())
+		})
+	}
+}
+
+func TestDockerCommandRunComposeProjectCommand(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lazydocker-compose-project")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "docker-compose.yml")
+	assert.NoError(t, ioutil.WriteFile(file, []byte{}, 0644))
+
+	type scenario struct {
+		testName            string
+		workingDir          string
+		expectedErrorSubstr string
+		expectedRun         bool
+	}
+
+	scenarios := []scenario{
+		{testName: "Working dir exists", workingDir: dir, expectedRun: true},
+		{testName: "No working dir label", workingDir: "", expectedErrorSubstr: "don't say what directory"},
+		{testName: "Working dir deleted", workingDir: filepath.Join(dir, "gone"), expectedErrorSubstr: "no longer exists"},
+		{testName: "Working dir is a file", workingDir: file, expectedErrorSubstr: "no longer exists"},
+	}
+
+	for _, s := range scenarios {
+		s := s
+		t.Run(s.testName, func(t *testing.T) {
+			userConfig := config.GetDefaultConfig()
+			appConfig := &config.AppConfig{UserConfig: &userConfig}
+			osCommand := NewOSCommand(NewDummyLog(), appConfig)
+
+			var ran *exec.Cmd
+			osCommand.SetCommand(func(name string, args ...string) *exec.Cmd {
+				ran = exec.Command("true")
+				ran.Args = append([]string{name}, args...)
+				return ran
+			})
+			dockerCommand := &DockerCommand{
+				OSCommand: osCommand,
+				Config:    appConfig,
+				Tr:        i18n.NewTranslationSet(NewDummyLog(), "en"),
+			}
+
+			project := &ComposeProject{Name: "shop", WorkingDir: s.workingDir}
+			err := dockerCommand.RunComposeProjectCommand(project, userConfig.CommandTemplates.ComposeProjectDown)
+			if s.expectedErrorSubstr != "" {
+				if assert.Error(t, err) {
+					assert.Contains(t, err.Error(), s.expectedErrorSubstr)
+				}
+				assert.Nil(t, ran)
+				return
+			}
+
+			assert.NoError(t, err)
+			if assert.NotNil(t, ran) {
+				assert.EqualValues(t, []string{"docker", "compose", "-p", "shop", "down"}, ran.Args)
+				assert.Equal(t, dir, ran.Dir)
+			}
+		})
+	}
+}
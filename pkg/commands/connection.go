@@ -0,0 +1,141 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/docker/docker/client"
+	"github.com/jesseduffield/lazydocker/pkg/commands/ssh"
+)
+
+// connectionPingTimeout is how long we give the daemon to answer a ping
+const connectionPingTimeout = 5 * time.Second
+
+// connectionFailedAfter is how many pings in a row can go unanswered before
+// we stop saying we're reconnecting and say the connection's failed
+const connectionFailedAfter = 3
+
+// ConnectionState is how our connection to the daemon is doing
+type ConnectionState int
+
+const (
+	// ConnectionConnected means the daemon answered our last ping
+	ConnectionConnected ConnectionState = iota
+	// ConnectionReconnecting means the daemon's stopped answering, but not
+	// for long enough to give up on it
+	ConnectionReconnecting
+	// ConnectionFailed means the daemon hasn't answered for a while, or the
+	// ssh tunnel to it has gone down for good. Only reconnecting will help.
+	ConnectionFailed
+)
+
+// ConnectionStatus is what we last knew about our connection to the daemon
+type ConnectionStatus struct {
+	State ConnectionState
+	// Endpoint is where the daemon is, e.g. ssh://me@box or
+	// unix:///var/run/docker.sock, rather than any tunnel we go through
+	Endpoint string
+	// Err is why the last ping failed
+	Err error
+}
+
+// Endpoint is where the daemon we're connected to is
+func (c *DockerCommand) Endpoint() string {
+	c.connectionMutex.Lock()
+	defer c.connectionMutex.Unlock()
+
+	return c.endpoint()
+}
+
+func (c *DockerCommand) endpoint() string {
+	if host := c.dockerContextEnv(c.dockerContext)["DOCKER_HOST"]; host != "" {
+		return host
+	}
+	return client.DefaultDockerHost
+}
+
+// ConnectionStatus is how our connection to the daemon was doing as of the
+// last CheckConnection
+func (c *DockerCommand) ConnectionStatus() ConnectionStatus {
+	c.connectionMutex.Lock()
+	defer c.connectionMutex.Unlock()
+
+	return c.connection
+}
+
+// CheckConnection pings the daemon to see how our connection to it is doing
+func (c *DockerCommand) CheckConnection() ConnectionStatus {
+	c.connectionMutex.Lock()
+	connectionID := c.connectionID
+	c.connectionMutex.Unlock()
+	c.ContainerMutex.Lock()
+	cli := c.Client
+	c.ContainerMutex.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), connectionPingTimeout)
+	defer cancel()
+	_, err := cli.Ping(ctx)
+	return c.recordPing(connectionID, err)
+}
+
+// recordPing works out how the connection's doing from how a ping went. A
+// ping that went out on a connection we've since replaced tells us nothing.
+func (c *DockerCommand) recordPing(connectionID int, err error) ConnectionStatus {
+	c.connectionMutex.Lock()
+	defer c.connectionMutex.Unlock()
+
+	if connectionID != c.connectionID {
+		return c.connection
+	}
+
+	status := ConnectionStatus{Endpoint: c.endpoint(), Err: err}
+	switch {
+	case err == nil:
+		c.failedPings = 0
+		status.State = ConnectionConnected
+	case c.tunnelDown:
+		status.State = ConnectionFailed
+		if c.tunnelErr != nil {
+			status.Err = fmt.Errorf("the ssh tunnel went down: %w", c.tunnelErr)
+		}
+	default:
+		c.failedPings++
+		status.State = ConnectionReconnecting
+		if c.failedPings >= connectionFailedAfter {
+			status.State = ConnectionFailed
+		}
+	}
+	c.connection = status
+	return status
+}
+
+// connected records that we've connected to dockerContext's daemon, through
+// tunnel if it's over ssh, and watches for the tunnel going down
+func (c *DockerCommand) connected(dockerContext ssh.DockerContext, tunnel ssh.Tunnel) {
+	c.connectionMutex.Lock()
+	c.connectionID++
+	connectionID := c.connectionID
+	c.dockerContext = dockerContext
+	c.failedPings = 0
+	c.tunnelDown = false
+	c.tunnelErr = nil
+	c.connection = ConnectionStatus{State: ConnectionConnected, Endpoint: c.endpoint()}
+	c.connectionMutex.Unlock()
+
+	if tunnel == nil || tunnel.Done() == nil {
+		return
+	}
+	go func() {
+		// the tunnel brings itself back up after a drop if it can, so this
+		// only happens once it's given up, or we've closed it
+		err := <-tunnel.Done()
+
+		c.connectionMutex.Lock()
+		defer c.connectionMutex.Unlock()
+		if connectionID == c.connectionID {
+			c.tunnelDown = true
+			c.tunnelErr = err
+		}
+	}()
+}
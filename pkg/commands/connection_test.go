@@ -0,0 +1,204 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jesseduffield/lazydocker/pkg/commands/ssh"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeSSHEnv is set when the test binary is standing in for the ssh binary
+const fakeSSHEnv = "LAZYDOCKER_TEST_FAKE_SSH"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(fakeSSHEnv) != "" {
+		fakeSSH(os.Args[1:])
+		return
+	}
+	os.Exit(m.Run())
+}
+
+// fakeSSH does what `ssh -L local:remote ... host -N` would, minus the ssh:
+// it forwards the local unix socket to the remote tcp address on this machine
+func fakeSSH(args []string) {
+	var forward string
+	for i, arg := range args {
+		if arg == "-L" && i+1 < len(args) {
+			forward = args[i+1]
+		}
+	}
+	parts := strings.SplitN(forward, ":", 2)
+	if len(parts) != 2 {
+		os.Exit(255)
+	}
+	listener, err := net.Listen("unix", parts[0])
+	if err != nil {
+		os.Exit(255)
+	}
+	for {
+		local, err := listener.Accept()
+		if err != nil {
+			os.Exit(255)
+		}
+		go func() {
+			defer local.Close()
+			remote, err := net.Dial("tcp", parts[1])
+			if err != nil {
+				return
+			}
+			defer remote.Close()
+			go func() { _, _ = io.Copy(remote, local) }()
+			_, _ = io.Copy(local, remote)
+		}()
+	}
+}
+
+// fakeTunnel is an ssh tunnel we can bring down when we like
+type fakeTunnel struct {
+	done chan error
+}
+
+func (t *fakeTunnel) Close() error           { return nil }
+func (t *fakeTunnel) SocketPath() string     { return "unix:///tmp/fake.sock" }
+func (t *fakeTunnel) Done() <-chan error     { return t.done }
+func (t *fakeTunnel) Stats() ssh.TunnelStats { return ssh.TunnelStats{} }
+func (t *fakeTunnel) ForwardPort(ctx context.Context, remoteAddress string) (*ssh.PortForward, error) {
+	return nil, errors.New("not supported")
+}
+
+func TestDockerCommandRecordPing(t *testing.T) {
+	unanswered := errors.New("Cannot connect to the Docker daemon")
+
+	type scenario struct {
+		testName      string
+		pings         []error
+		expectedState ConnectionState
+	}
+
+	scenarios := []scenario{
+		{testName: "Answered", pings: []error{nil}, expectedState: ConnectionConnected},
+		{testName: "One unanswered", pings: []error{nil, unanswered}, expectedState: ConnectionReconnecting},
+		{testName: "Unanswered for a while", pings: []error{unanswered, unanswered, unanswered}, expectedState: ConnectionFailed},
+		{testName: "Answered again", pings: []error{unanswered, unanswered, unanswered, nil}, expectedState: ConnectionConnected},
+		{testName: "Answered in between", pings: []error{unanswered, unanswered, nil, unanswered, unanswered}, expectedState: ConnectionReconnecting},
+	}
+
+	for _, s := range scenarios {
+		s := s
+		t.Run(s.testName, func(t *testing.T) {
+			dockerCommand := newDockerContextTestCommand(t, "tcp://127.0.0.1:1")
+			dockerCommand.connected(ssh.DockerContext{Name: "remote", Host: "ssh://me@box"}, nil)
+
+			var status ConnectionStatus
+			for _, err := range s.pings {
+				status = dockerCommand.recordPing(dockerCommand.connectionID, err)
+			}
+			assert.Equal(t, s.expectedState, status.State)
+			assert.Equal(t, "ssh://me@box", status.Endpoint)
+			assert.Equal(t, status, dockerCommand.ConnectionStatus())
+		})
+	}
+}
+
+func TestDockerCommandRecordPingIgnoresOldConnections(t *testing.T) {
+	dockerCommand := newDockerContextTestCommand(t, "tcp://127.0.0.1:1")
+	dockerCommand.connected(ssh.DockerContext{Name: "default"}, nil)
+	oldConnectionID := dockerCommand.connectionID
+	dockerCommand.connected(ssh.DockerContext{Name: "default"}, nil)
+
+	status := dockerCommand.recordPing(oldConnectionID, errors.New("use of closed network connection"))
+	assert.Equal(t, ConnectionConnected, status.State)
+}
+
+func TestDockerCommandTunnelGoingDownFailsTheConnection(t *testing.T) {
+	dockerCommand := newDockerContextTestCommand(t, "tcp://127.0.0.1:1")
+	tunnel := &fakeTunnel{done: make(chan error, 1)}
+	dockerCommand.connected(ssh.DockerContext{Name: "remote", Host: "ssh://me@box"}, tunnel)
+
+	tunnel.done <- errors.New("ssh tunnel dropped and could not be re-established after 3 attempts")
+	tunnelDown := func() bool {
+		dockerCommand.connectionMutex.Lock()
+		defer dockerCommand.connectionMutex.Unlock()
+		return dockerCommand.tunnelDown
+	}
+	for deadline := time.Now().Add(time.Second); !tunnelDown() && time.Now().Before(deadline); {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	status := dockerCommand.recordPing(dockerCommand.connectionID, errors.New("no such file or directory"))
+	assert.Equal(t, ConnectionFailed, status.State)
+	assert.EqualError(t, status.Err, "the ssh tunnel went down: ssh tunnel dropped and could not be re-established after 3 attempts")
+}
+
+func TestDockerCommandCheckConnectionAndReconnect(t *testing.T) {
+	for _, key := range dockerEnvVars {
+		defer os.Setenv(key, os.Getenv(key))
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/_ping", r.URL.Path)
+		_, _ = w.Write([]byte("OK"))
+	}))
+	defer server.Close()
+	host := "tcp://" + server.Listener.Addr().String()
+
+	// we know where the daemon is, but the client we have doesn't get there
+	dockerCommand := newDockerContextTestCommand(t, "tcp://127.0.0.1:1")
+	dockerCommand.connected(ssh.DockerContext{Name: "remote", Host: host}, nil)
+	status := dockerCommand.CheckConnection()
+	assert.Equal(t, ConnectionReconnecting, status.State)
+	assert.Error(t, status.Err)
+
+	assert.NoError(t, dockerCommand.Reconnect(context.Background(), nil, nil))
+	assert.Equal(t, host, dockerCommand.Client.DaemonHost())
+	// it's the same daemon, so what we knew about it is kept
+	assert.Len(t, dockerCommand.Images, 1)
+
+	status = dockerCommand.CheckConnection()
+	assert.Equal(t, ConnectionStatus{State: ConnectionConnected, Endpoint: host}, status)
+}
+
+func TestDockerCommandReconnectTunnelOutlivesContext(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake ssh binary serves a unix socket")
+	}
+	for _, key := range append(dockerEnvVars, fakeSSHEnv) {
+		defer os.Setenv(key, os.Getenv(key))
+	}
+	os.Setenv(fakeSSHEnv, "1")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("OK"))
+	}))
+	defer server.Close()
+
+	binary, err := os.Executable()
+	assert.NoError(t, err)
+	dockerCommand := newDockerContextTestCommand(t, "tcp://127.0.0.1:1")
+	dockerCommand.Config.UserConfig.SSH.Binary = binary
+	dockerCommand.connected(ssh.DockerContext{Name: "remote", Host: "ssh://me@box?remote=tcp://" + server.Listener.Addr().String()}, nil)
+
+	// as the gui does, which cancels the context once we're reconnected
+	ctx, cancel := context.WithCancel(context.Background())
+	assert.NoError(t, dockerCommand.Reconnect(ctx, nil, nil))
+	cancel()
+	defer dockerCommand.tunnel.Close()
+
+	select {
+	case err := <-dockerCommand.tunnel.Done():
+		t.Fatalf("tunnel went down when the context was cancelled: %v", err)
+	case <-time.After(200 * time.Millisecond):
+	}
+	_, err = dockerCommand.Client.Ping(context.Background())
+	assert.NoError(t, err)
+}
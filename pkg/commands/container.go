@@ -3,7 +3,9 @@ package commands
 import (
 	"context"
 	"fmt"
+	"io"
 	"os/exec"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
@@ -11,8 +13,8 @@ import (
 	"github.com/docker/docker/api/types/container"
 
 	"github.com/docker/docker/api/types"
-	"github.com/docker/docker/api/types/filters"
 	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/stdcopy"
 	"github.com/fatih/color"
 	"github.com/go-errors/errors"
 	"github.com/jesseduffield/lazydocker/pkg/config"
@@ -29,21 +31,20 @@ type Container struct {
 	ContainerNumber string // might make this an int in the future if need be
 
 	// OneOff tells us if the container is just a job container or is actually bound to the service
-	OneOff          bool
-	ProjectName     string
-	ID              string
-	Container       types.Container
-	DisplayString   string
-	Client          *client.Client
-	OSCommand       *OSCommand
-	Config          *config.AppConfig
-	Log             *logrus.Entry
-	CLIStats        ContainerCliStat // for realtime we use the CLI, for long-term we use the client
-	StatHistory     []RecordedStats
-	Details         Details
-	MonitoringStats bool
-	DockerCommand   LimitedDockerCommand
-	Tr              *i18n.TranslationSet
+	OneOff        bool
+	ProjectName   string
+	ID            string
+	Container     types.Container
+	DisplayString string
+	Client        *client.Client
+	OSCommand     *OSCommand
+	Config        *config.AppConfig
+	Log           *logrus.Entry
+	CLIStats      ContainerCliStat // for realtime we use the CLI, for long-term we use the client
+	StatHistory   []RecordedStats
+	Details       Details
+	DockerCommand LimitedDockerCommand
+	Tr            *i18n.TranslationSet
 }
 
 // Details is a struct containing what we get back from `docker inspect` on a container
@@ -251,19 +252,92 @@ type ContainerCliStat struct {
 func (c *Container) GetDisplayStrings(isFocused bool) []string {
 	image := strings.TrimPrefix(c.Container.Image, "sha256:")
 
-	return []string{c.GetDisplayStatus(), c.GetDisplaySubstatus(), c.Name, c.GetDisplayCPUPerc(), utils.ColoredString(image, color.FgMagenta)}
+	return []string{c.GetDisplayStatus(), c.GetDisplaySubstatus(), c.Name, c.GetDisplayUptime(), c.GetDisplayRestartCount(), c.GetDisplayCPUPerc(), utils.ColoredString(image, color.FgMagenta)}
+}
+
+// highRestartCount is how many restarts it takes for a container to look like
+// it's flapping
+const highRestartCount = 5
+
+// Uptime is how long the container has been running for, or 0 if it isn't
+// running or we don't know yet
+func (c *Container) Uptime(now time.Time) time.Duration {
+	if c.Container.State != "running" || !c.DetailsLoaded() || c.Details.State.StartedAt.IsZero() {
+		return 0
+	}
+	uptime := now.Sub(c.Details.State.StartedAt)
+	if uptime < 0 {
+		// the daemon's clock is ahead of ours
+		return 0
+	}
+	return uptime
+}
+
+// GetDisplayUptime returns how long the container's been running for, e.g. 2d3h
+func (c *Container) GetDisplayUptime() string {
+	uptime := c.Uptime(time.Now())
+	if uptime == 0 {
+		return ""
+	}
+	return FormatUptime(uptime)
+}
+
+// GetDisplayRestartCount returns how many times the daemon has restarted the
+// container, if it has, e.g. because of its restart policy. Lots of restarts
+// stand out, since they mean the container keeps crashing.
+func (c *Container) GetDisplayRestartCount() string {
+	count := c.Details.RestartCount
+	if count == 0 {
+		return ""
+	}
+
+	text := fmt.Sprintf(c.Tr.RestartCount, count)
+	if count == 1 {
+		text = c.Tr.RestartedOnce
+	}
+	if count >= highRestartCount {
+		return utils.ThemedString(text, c.theme().FailedColor)
+	}
+	return utils.ColoredString(text, color.FgYellow)
+}
+
+// FormatUptime formats a duration compactly with its two largest units, e.g.
+// 2d3h, 4h12m, 5m or 30s
+func FormatUptime(d time.Duration) string {
+	d = d.Truncate(time.Second)
+	days := d / (24 * time.Hour)
+	hours := (d % (24 * time.Hour)) / time.Hour
+	minutes := (d % time.Hour) / time.Minute
+	seconds := (d % time.Minute) / time.Second
+
+	join := func(major time.Duration, majorUnit string, minor time.Duration, minorUnit string) string {
+		if minor == 0 {
+			return fmt.Sprintf("%d%s", major, majorUnit)
+		}
+		return fmt.Sprintf("%d%s%d%s", major, majorUnit, minor, minorUnit)
+	}
+	switch {
+	case days > 0:
+		return join(days, "d", hours, "h")
+	case hours > 0:
+		return join(hours, "h", minutes, "m")
+	case minutes > 0:
+		return fmt.Sprintf("%dm", minutes)
+	default:
+		return fmt.Sprintf("%ds", seconds)
+	}
 }
 
 // GetDisplayStatus returns the colored status of the container
 func (c *Container) GetDisplayStatus() string {
-	return utils.ColoredString(c.Container.State, c.GetColor())
+	return utils.ThemedString(c.Container.State, c.GetColor())
 }
 
 // GetDisplayStatus returns the exit code if the container has exited, and the health status if the container is running (and has a health check)
 func (c *Container) GetDisplaySubstatus() string {
 	switch c.Container.State {
 	case "exited":
-		return utils.ColoredString(
+		return utils.ThemedString(
 			fmt.Sprintf("(%s)", strconv.Itoa(c.Details.State.ExitCode)), c.GetColor(),
 		)
 	case "running":
@@ -274,17 +348,50 @@ func (c *Container) GetDisplaySubstatus() string {
 }
 
 func (c *Container) getHealthStatus() string {
-	healthStatusColorMap := map[string]color.Attribute{
-		"healthy":   color.FgGreen,
-		"unhealthy": color.FgRed,
-		"starting":  color.FgYellow,
+	healthStatus := c.HealthStatus()
+	if healthStatus == "" {
+		return ""
 	}
+	return utils.ThemedString(fmt.Sprintf("(%s)", healthStatus), c.GetHealthColor())
+}
 
-	healthStatus := c.Details.State.Health.Status
-	if healthStatusColor, ok := healthStatusColorMap[healthStatus]; ok {
-		return utils.ColoredString(fmt.Sprintf("(%s)", healthStatus), healthStatusColor)
+// HealthStatus is starting, healthy or unhealthy for a container with a health
+// check, and empty otherwise
+func (c *Container) HealthStatus() string {
+	return c.Details.State.Health.Status
+}
+
+// Unhealthy tells us if the container is running but failing its health check
+func (c *Container) Unhealthy() bool {
+	return c.Container.State == "running" && c.HealthStatus() == types.Unhealthy
+}
+
+// GetHealthColor returns the theme's colors for the container's health status
+func (c *Container) GetHealthColor() []string {
+	theme := c.theme()
+	switch c.HealthStatus() {
+	case types.Healthy:
+		return theme.RunningColor
+	case types.Unhealthy:
+		return theme.FailedColor
+	case types.Starting:
+		return []string{"yellow"}
+	default:
+		return []string{"default"}
 	}
-	return ""
+}
+
+// RecentHealthChecks returns up to the last n health check results, newest
+// first
+func (c *Container) RecentHealthChecks(n int) []*types.HealthcheckResult {
+	log := c.Details.State.Health.Log
+	results := []*types.HealthcheckResult{}
+	for i := len(log) - 1; i >= 0 && len(results) < n; i-- {
+		if log[i] != nil {
+			results = append(results, log[i])
+		}
+	}
+	return results
 }
 
 // GetDisplayCPUPerc colors the cpu percentage based on how extreme it is
@@ -295,7 +402,7 @@ func (c *Container) GetDisplayCPUPerc() string {
 		return ""
 	}
 
-	percentage, err := strconv.ParseFloat(strings.TrimSuffix(stats.CPUPerc, "%"), 32)
+	percentage, err := parseCPUPerc(stats.CPUPerc)
 	if err != nil {
 		// probably complaining about not being able to convert '--'
 		return ""
@@ -318,34 +425,47 @@ func (c *Container) ProducingLogs() bool {
 	return c.Container.State == "running" && !(c.Details.HostConfig.LogConfig.Type == "none")
 }
 
-// GetColor Container color
-func (c *Container) GetColor() color.Attribute {
+// GetColor returns the theme's colors for the container's status
+func (c *Container) GetColor() []string {
+	theme := c.theme()
 	switch c.Container.State {
 	case "exited":
 		if c.Details.State.ExitCode == 0 {
-			return color.FgYellow
+			return theme.StoppedColor
 		}
-		return color.FgRed
+		return theme.FailedColor
 	case "created":
-		return color.FgCyan
+		return []string{"cyan"}
 	case "running":
-		return color.FgGreen
+		// so that a failing health check stands out in the list
+		if c.Unhealthy() {
+			return theme.FailedColor
+		}
+		return theme.RunningColor
 	case "paused":
-		return color.FgYellow
+		return theme.StoppedColor
 	case "dead":
-		return color.FgRed
+		return theme.FailedColor
 	case "restarting":
-		return color.FgBlue
+		return []string{"blue"}
 	case "removing":
-		return color.FgMagenta
+		return []string{"magenta"}
 	default:
-		return color.FgWhite
+		return []string{"default"}
 	}
 }
 
+func (c *Container) theme() config.ThemeConfig {
+	if c.Config != nil {
+		return c.Config.UserConfig.Gui.Theme.Resolved()
+	}
+	return config.ThemeConfig{}.Resolved()
+}
+
 // Remove removes the container
 func (c *Container) Remove(options types.ContainerRemoveOptions) error {
 	c.Log.Warn(fmt.Sprintf("removing container %s", c.Name))
+	defer c.DockerCommand.InvalidateContainerCache()
 	if err := c.Client.ContainerRemove(context.Background(), c.ID, options); err != nil {
 		if strings.Contains(err.Error(), "Stop the container before attempting removal or force remove") {
 			return ComplexError{
@@ -362,16 +482,113 @@ func (c *Container) Remove(options types.ContainerRemoveOptions) error {
 
 // Stop stops the container
 func (c *Container) Stop() error {
+	var timeout time.Duration
+	if c.Config != nil {
+		timeout = c.Config.UserConfig.StopTimeout
+	}
+	return c.StopWithTimeout(timeout)
+}
+
+// StopWithTimeout stops the container, giving it timeout to exit before it's
+// killed. A zero timeout leaves it to the container.
+func (c *Container) StopWithTimeout(timeout time.Duration) error {
 	c.Log.Warn(fmt.Sprintf("stopping container %s", c.Name))
-	return c.Client.ContainerStop(context.Background(), c.ID, nil)
+	defer c.DockerCommand.InvalidateContainerCache()
+	if timeout == 0 {
+		return c.Client.ContainerStop(context.Background(), c.ID, nil)
+	}
+	return c.Client.ContainerStop(context.Background(), c.ID, &timeout)
+}
+
+// Kill kills the container straight away, for when it won't stop
+func (c *Container) Kill() error {
+	c.Log.Warn(fmt.Sprintf("killing container %s", c.Name))
+	defer c.DockerCommand.InvalidateContainerCache()
+	return c.Client.ContainerKill(context.Background(), c.ID, "KILL")
+}
+
+// ParseStopTimeout reads a stop timeout as the user typed it, either as a
+// number of seconds like 30 or as a duration like 1m30s
+func ParseStopTimeout(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	duration := value
+	if seconds, err := strconv.Atoi(value); err == nil {
+		duration = fmt.Sprintf("%ds", seconds)
+	}
+	timeout, err := time.ParseDuration(duration)
+	if err != nil || timeout < 0 {
+		return 0, fmt.Errorf("%q isn't a timeout: give a number of seconds, or a duration like 1m30s", value)
+	}
+	return timeout, nil
 }
 
 // Restart restarts the container
 func (c *Container) Restart() error {
 	c.Log.Warn(fmt.Sprintf("restarting container %s", c.Name))
+	defer c.DockerCommand.InvalidateContainerCache()
 	return c.Client.ContainerRestart(context.Background(), c.ID, nil)
 }
 
+// RestartPolicies are the restart policies a container can be given, named as
+// `docker run --restart` names them
+var RestartPolicies = []string{"no", "on-failure", "always", "unless-stopped"}
+
+// RestartPolicy is the container's restart policy, e.g. always, or e.g.
+// on-failure:3 if it's only retried so many times
+func (c *Container) RestartPolicy() string {
+	policy := c.Details.HostConfig.RestartPolicy
+	if policy.Name == "" {
+		return "no"
+	}
+	if policy.Name == "on-failure" && policy.MaximumRetryCount > 0 {
+		return fmt.Sprintf("%s:%d", policy.Name, policy.MaximumRetryCount)
+	}
+	return policy.Name
+}
+
+// UpdateRestartPolicy gives the container a new restart policy, like `docker
+// update --restart`. It takes effect straight away, without a restart.
+func (c *Container) UpdateRestartPolicy(name string) error {
+	c.Log.Warn(fmt.Sprintf("setting restart policy of container %s to %s", c.Name, name))
+	defer c.DockerCommand.InvalidateContainerCache()
+	_, err := c.Client.ContainerUpdate(context.Background(), c.ID, container.UpdateConfig{
+		RestartPolicy: container.RestartPolicy{Name: name},
+	})
+	return err
+}
+
+// containerNameRegex is what docker allows containers to be called
+var containerNameRegex = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]+$`)
+
+// ValidateContainerName checks that docker would let a container be called
+// name, so that we can say so before asking it to
+func ValidateContainerName(name string) error {
+	if !containerNameRegex.MatchString(name) {
+		return fmt.Errorf("%q isn't a valid name: it needs at least two of a-z, A-Z, 0-9, _, . and -, starting with a letter or digit", name)
+	}
+	return nil
+}
+
+// Rename renames the container, like `docker rename`
+func (c *Container) Rename(name string) error {
+	if err := ValidateContainerName(name); err != nil {
+		return err
+	}
+	if name == c.Name {
+		return nil
+	}
+
+	c.Log.Warn(fmt.Sprintf("renaming container %s to %s", c.Name, name))
+	defer c.DockerCommand.InvalidateContainerCache()
+	if err := c.Client.ContainerRename(context.Background(), c.ID, name); err != nil {
+		if strings.Contains(err.Error(), "is already in use") {
+			return fmt.Errorf("there's already a container called %s", name)
+		}
+		return err
+	}
+	return nil
+}
+
 // Attach attaches the container
 func (c *Container) Attach() (*exec.Cmd, error) {
 	c.Log.Warn(fmt.Sprintf("attaching to container %s", c.Name))
@@ -389,6 +606,76 @@ func (c *Container) Attach() (*exec.Cmd, error) {
 	return cmd, nil
 }
 
+// shellCandidates are the shells ExecShell tries, best first
+var shellCandidates = []string{"/bin/bash", "/bin/sh"}
+
+// ExecShell returns a command that opens an interactive shell in the container,
+// to run as a subprocess. We go through the docker cli so that it owns the
+// terminal: it sets up the pty and passes resizes along for us.
+func (c *Container) ExecShell() (*exec.Cmd, error) {
+	if c.Container.State != "running" {
+		return nil, errors.New(c.Tr.CannotExecStoppedContainerError)
+	}
+
+	shell, err := c.findShell(context.Background(), shellCandidates)
+	if err != nil {
+		return nil, err
+	}
+
+	c.Log.Warn(fmt.Sprintf("opening %s in container %s", shell, c.Name))
+	return c.OSCommand.PrepareSubProcess("docker", "exec", "-it", c.ID, shell), nil
+}
+
+// findShell returns the first of shells that the container can run. We find
+// out by running each one with a no-op script, which is the only way to be
+// sure: the executable could be missing, or there for the wrong platform.
+func (c *Container) findShell(ctx context.Context, shells []string) (string, error) {
+	for _, shell := range shells {
+		ok, err := c.canExec(ctx, []string{shell, "-c", "exit 0"})
+		if err != nil {
+			return "", err
+		}
+		if ok {
+			return shell, nil
+		}
+	}
+	return "", fmt.Errorf(c.Tr.NoShellInContainerError, strings.Join(shells, ", "))
+}
+
+// canExec tells us whether cmd runs successfully in the container
+func (c *Container) canExec(ctx context.Context, cmd []string) (bool, error) {
+	created, err := c.Client.ContainerExecCreate(ctx, c.ID, types.ExecConfig{Cmd: cmd})
+	if err != nil {
+		return false, err
+	}
+
+	if err := c.Client.ContainerExecStart(ctx, created.ID, types.ExecStartCheck{Detach: true}); err != nil {
+		// newer daemons fail here when the executable doesn't exist, older
+		// ones start it and give it an exit code of 126 or 127
+		c.Log.Warn(err)
+		return false, nil
+	}
+
+	ticker := time.NewTicker(50 * time.Millisecond)
+	defer ticker.Stop()
+	timeout := time.After(5 * time.Second)
+	for {
+		inspect, err := c.Client.ContainerExecInspect(ctx, created.ID)
+		if err != nil {
+			return false, err
+		}
+		if !inspect.Running {
+			return inspect.ExitCode == 0, nil
+		}
+
+		select {
+		case <-ticker.C:
+		case <-timeout:
+			return false, fmt.Errorf("timed out checking for %s in container %s", cmd[0], c.Name)
+		}
+	}
+}
+
 // Top returns process information
 func (c *Container) Top() (container.ContainerTopOKBody, error) {
 	detail, err := c.Inspect()
@@ -432,9 +719,54 @@ func (c *Container) ViewLogs() (*exec.Cmd, error) {
 	return cmd, nil
 }
 
-// PruneContainers prunes containers
-func (c *DockerCommand) PruneContainers() error {
-	_, err := c.Client.ContainersPrune(context.Background(), filters.Args{})
+// StreamLogs writes the container's logs to w, following them as new lines
+// arrive until the container stops or ctx is cancelled. since is anything
+// docker logs --since takes, e.g. "5m", or blank for all the logs.
+func (c *Container) StreamLogs(ctx context.Context, since string, w io.Writer) error {
+	tty := c.Details.Config.Tty
+	if !c.DetailsLoaded() {
+		details, err := c.Inspect()
+		if err != nil {
+			return err
+		}
+		tty = details.Config != nil && details.Config.Tty
+	}
+
+	reader, err := c.Client.ContainerLogs(ctx, c.ID, types.ContainerLogsOptions{
+		ShowStdout: true,
+		ShowStderr: true,
+		Follow:     true,
+		Timestamps: true,
+		Since:      since,
+	})
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	// cancelling the request doesn't reliably interrupt a read that's already
+	// blocked waiting for the next line, so we close the stream ourselves
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			reader.Close()
+		case <-done:
+		}
+	}()
+
+	// without a tty docker multiplexes stdout and stderr into one stream, with
+	// a header on each frame
+	if tty {
+		_, err = io.Copy(w, reader)
+	} else {
+		_, err = stdcopy.StdCopy(w, w, reader)
+	}
+	if ctx.Err() != nil {
+		// we were told to stop, so whatever the read failed with is expected
+		return nil
+	}
 	return err
 }
 
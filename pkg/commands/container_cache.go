@@ -0,0 +1,183 @@
+package commands
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+)
+
+// containerListCache holds on to ContainerList results for a short while, so
+// that refreshing the containers panel on every tick doesn't mean a round trip
+// to the daemon every time, which adds up over an ssh tunnel. Results are
+// cached per set of list options.
+type containerListCache struct {
+	mutex   sync.Mutex
+	ttl     time.Duration
+	now     func() time.Time
+	entries map[string]containerListCacheEntry
+}
+
+type containerListCacheEntry struct {
+	containers []types.Container
+	fetchedAt  time.Time
+}
+
+// newContainerListCache returns a cache whose entries expire after ttl. A ttl
+// of zero or less turns caching off, as does a nil cache.
+func newContainerListCache(ttl time.Duration) *containerListCache {
+	return &containerListCache{
+		ttl:     ttl,
+		now:     time.Now,
+		entries: map[string]containerListCacheEntry{},
+	}
+}
+
+// get returns the cached containers for the given options, calling fetch if
+// there are none or they've expired. Errors aren't cached.
+func (c *containerListCache) get(options types.ContainerListOptions, fetch func(types.ContainerListOptions) ([]types.Container, error)) ([]types.Container, error) {
+	if c == nil || c.ttl <= 0 {
+		return fetch(options)
+	}
+
+	key, err := containerListCacheKey(options)
+	if err != nil {
+		return fetch(options)
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if entry, ok := c.entries[key]; ok && c.now().Sub(entry.fetchedAt) < c.ttl {
+		return entry.containers, nil
+	}
+
+	// fetching with the lock held so that concurrent refreshes wait for the
+	// one request rather than all hitting the daemon at once
+	containers, err := fetch(options)
+	if err != nil {
+		return nil, err
+	}
+	c.entries[key] = containerListCacheEntry{containers: containers, fetchedAt: c.now()}
+
+	return containers, nil
+}
+
+// invalidate drops everything in the cache, so that the next get goes to the
+// daemon. We do this whenever the user does something to a container, so they
+// see the result straight away.
+func (c *containerListCache) invalidate() {
+	if c == nil {
+		return
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.entries = map[string]containerListCacheEntry{}
+}
+
+func containerListCacheKey(options types.ContainerListOptions) (string, error) {
+	// filters marshal with sorted keys, so equal filters give equal keys
+	filterJSON, err := filters.ToJSON(options.Filters)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf(
+		"quiet=%t size=%t all=%t latest=%t since=%q before=%q limit=%d filters=%s",
+		options.Quiet, options.Size, options.All, options.Latest, options.Since, options.Before, options.Limit, filterJSON,
+	), nil
+}
+
+// containerDetailsCache keeps track of when we last inspected each container,
+// so that UpdateContainerDetails only inspects the containers that the list
+// says have changed since, or that we haven't inspected for a while. A restart
+// counts as a change, since it resets the uptime in the list's status. Over an
+// ssh tunnel that saves inspecting every container every second.
+type containerDetailsCache struct {
+	mutex   sync.Mutex
+	ttl     time.Duration
+	now     func() time.Time
+	entries map[string]containerDetailsCacheEntry
+}
+
+type containerDetailsCacheEntry struct {
+	// fingerprint is what the container list said about the container when
+	// we inspected it
+	fingerprint string
+	fetchedAt   time.Time
+}
+
+// newContainerDetailsCache returns a cache whose entries expire after ttl. A
+// ttl of zero or less turns caching off, as does a nil cache.
+func newContainerDetailsCache(ttl time.Duration) *containerDetailsCache {
+	return &containerDetailsCache{
+		ttl:     ttl,
+		now:     time.Now,
+		entries: map[string]containerDetailsCacheEntry{},
+	}
+}
+
+// stale returns those of containers we need to inspect again, and forgets
+// about any containers that have gone
+func (c *containerDetailsCache) stale(containers []*Container) []*Container {
+	if c == nil || c.ttl <= 0 {
+		return containers
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	now := c.now()
+	current := map[string]bool{}
+	stale := []*Container{}
+	for _, container := range containers {
+		current[container.ID] = true
+		entry, ok := c.entries[container.ID]
+		if !ok || !container.DetailsLoaded() || entry.fingerprint != containerFingerprint(container.Container) || now.Sub(entry.fetchedAt) >= c.ttl {
+			stale = append(stale, container)
+		}
+	}
+	for id := range c.entries {
+		if !current[id] {
+			delete(c.entries, id)
+		}
+	}
+	return stale
+}
+
+// fetched notes that we've just inspected containers
+func (c *containerDetailsCache) fetched(containers []*Container) {
+	if c == nil || c.ttl <= 0 {
+		return
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	now := c.now()
+	for _, container := range containers {
+		c.entries[container.ID] = containerDetailsCacheEntry{fingerprint: containerFingerprint(container.Container), fetchedAt: now}
+	}
+}
+
+// invalidate makes the next stale return every container
+func (c *containerDetailsCache) invalidate() {
+	if c == nil {
+		return
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.entries = map[string]containerDetailsCacheEntry{}
+}
+
+// containerFingerprint sums up what the container list says about a
+// container that inspecting it would tell us more about, e.g. "running" and
+// "Up 2 hours (healthy)"
+func containerFingerprint(container types.Container) string {
+	return container.State + "\x00" + container.Status
+}
@@ -0,0 +1,201 @@
+package commands
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContainerListCache(t *testing.T) {
+	now := time.Unix(0, 0)
+	cache := newContainerListCache(time.Second)
+	cache.now = func() time.Time { return now }
+
+	fetchCount := 0
+	fetch := func(options types.ContainerListOptions) ([]types.Container, error) {
+		fetchCount++
+		return []types.Container{{ID: "1"}}, nil
+	}
+	options := types.ContainerListOptions{All: true}
+
+	containers, err := cache.get(options, fetch)
+	assert.NoError(t, err)
+	assert.EqualValues(t, []types.Container{{ID: "1"}}, containers)
+	assert.Equal(t, 1, fetchCount)
+
+	// within the ttl we reuse the last result
+	now = now.Add(500 * time.Millisecond)
+	_, err = cache.get(options, fetch)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, fetchCount)
+
+	// different options are cached separately
+	_, err = cache.get(types.ContainerListOptions{All: false}, fetch)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, fetchCount)
+
+	// once it expires we fetch again
+	now = now.Add(time.Second)
+	_, err = cache.get(options, fetch)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, fetchCount)
+
+	// and after an invalidation
+	cache.invalidate()
+	_, err = cache.get(options, fetch)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, fetchCount)
+}
+
+func TestContainerListCacheKeyOnFilters(t *testing.T) {
+	cache := newContainerListCache(time.Minute)
+
+	fetchCount := 0
+	fetch := func(options types.ContainerListOptions) ([]types.Container, error) {
+		fetchCount++
+		return []types.Container{{ID: options.Filters.Get("name")[0]}}, nil
+	}
+
+	first, err := cache.get(types.ContainerListOptions{Filters: filters.NewArgs(filters.Arg("name", "a"), filters.Arg("label", "x"))}, fetch)
+	assert.NoError(t, err)
+	// the same filters added in a different order are the same query
+	again, err := cache.get(types.ContainerListOptions{Filters: filters.NewArgs(filters.Arg("label", "x"), filters.Arg("name", "a"))}, fetch)
+	assert.NoError(t, err)
+	other, err := cache.get(types.ContainerListOptions{Filters: filters.NewArgs(filters.Arg("name", "b"))}, fetch)
+	assert.NoError(t, err)
+
+	assert.Equal(t, 2, fetchCount)
+	assert.Equal(t, "a", first[0].ID)
+	assert.Equal(t, "a", again[0].ID)
+	assert.Equal(t, "b", other[0].ID)
+}
+
+func TestContainerListCacheDoesNotCacheErrors(t *testing.T) {
+	cache := newContainerListCache(time.Minute)
+
+	fetchCount := 0
+	fetch := func(options types.ContainerListOptions) ([]types.Container, error) {
+		fetchCount++
+		if fetchCount == 1 {
+			return nil, errors.New("connection refused")
+		}
+		return []types.Container{}, nil
+	}
+
+	_, err := cache.get(types.ContainerListOptions{}, fetch)
+	assert.Error(t, err)
+	_, err = cache.get(types.ContainerListOptions{}, fetch)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, fetchCount)
+}
+
+func TestContainerListCacheDisabled(t *testing.T) {
+	type scenario struct {
+		testName string
+		cache    *containerListCache
+	}
+
+	scenarios := []scenario{
+		{testName: "Zero ttl", cache: newContainerListCache(0)},
+		{testName: "Negative ttl", cache: newContainerListCache(-time.Second)},
+		{testName: "Nil cache", cache: nil},
+	}
+
+	for _, s := range scenarios {
+		s := s
+		t.Run(s.testName, func(t *testing.T) {
+			fetchCount := 0
+			fetch := func(options types.ContainerListOptions) ([]types.Container, error) {
+				fetchCount++
+				return nil, nil
+			}
+
+			_, _ = s.cache.get(types.ContainerListOptions{}, fetch)
+			_, _ = s.cache.get(types.ContainerListOptions{}, fetch)
+			s.cache.invalidate()
+			assert.Equal(t, 2, fetchCount)
+		})
+	}
+}
+
+func TestContainerListCacheConcurrentAccess(t *testing.T) {
+	cache := newContainerListCache(time.Minute)
+
+	var mutex sync.Mutex
+	fetchCount := 0
+	fetch := func(options types.ContainerListOptions) ([]types.Container, error) {
+		mutex.Lock()
+		fetchCount++
+		mutex.Unlock()
+		return []types.Container{}, nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if i%5 == 0 {
+				cache.invalidate()
+			}
+			_, err := cache.get(types.ContainerListOptions{All: true}, fetch)
+			assert.NoError(t, err)
+		}(i)
+	}
+	wg.Wait()
+
+	// at most one fetch per invalidation, plus the first
+	assert.True(t, fetchCount >= 1 && fetchCount <= 5, "unexpected fetch count %d", fetchCount)
+}
+
+func TestContainerDetailsCache(t *testing.T) {
+	now := time.Unix(0, 0)
+	cache := newContainerDetailsCache(10 * time.Second)
+	cache.now = func() time.Time { return now }
+
+	newContainer := func(id, state, status string) *Container {
+		container := &Container{ID: id}
+		container.Container.State = state
+		container.Container.Status = status
+		return container
+	}
+	web := newContainer("1", "running", "Up 2 hours")
+	db := newContainer("2", "running", "Up 3 hours")
+	ids := func(containers []*Container) []string {
+		result := []string{}
+		for _, container := range containers {
+			result = append(result, container.ID)
+		}
+		return result
+	}
+
+	// nothing's been inspected yet
+	assert.Equal(t, []string{"1", "2"}, ids(cache.stale([]*Container{web, db})))
+	web.Details.Image, db.Details.Image = "sha256:abc", "sha256:def"
+	cache.fetched([]*Container{web, db})
+	assert.Empty(t, cache.stale([]*Container{web, db}))
+
+	// a restart resets the uptime in the status
+	now = now.Add(5 * time.Second)
+	web.Container.Status = "Up 1 second"
+	assert.Equal(t, []string{"1"}, ids(cache.stale([]*Container{web, db})))
+	cache.fetched([]*Container{web})
+
+	// once it expires we inspect again, even without a change
+	now = now.Add(5 * time.Second)
+	assert.Equal(t, []string{"2"}, ids(cache.stale([]*Container{web, db})))
+
+	// and after an invalidation
+	cache.invalidate()
+	assert.Equal(t, []string{"1", "2"}, ids(cache.stale([]*Container{web, db})))
+
+	// without a ttl we inspect every time
+	cache = newContainerDetailsCache(0)
+	cache.fetched([]*Container{web, db})
+	assert.Equal(t, []string{"1", "2"}, ids(cache.stale([]*Container{web, db})))
+}
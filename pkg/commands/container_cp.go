@@ -0,0 +1,339 @@
+package commands
+
+import (
+	"archive/tar"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
+	"github.com/jesseduffield/lazydocker/pkg/utils"
+)
+
+// copyProgressInterval stops a big copy from telling us how it's going more
+// often than is worth showing
+const copyProgressInterval = 100 * time.Millisecond
+
+// CopyProgress is how far along a copy between a container and this machine
+// is
+type CopyProgress struct {
+	Copied int64
+	// Total is how much there is to copy, or 0 if we don't know
+	Total int64
+}
+
+// Render says how much has been copied, and how much of the whole that is if
+// we know
+func (p CopyProgress) Render() string {
+	if p.Total <= 0 {
+		return utils.FormatBinaryBytes(int(p.Copied))
+	}
+	percent := p.Copied * 100 / p.Total
+	if percent > 100 {
+		// a file that grew while we were copying it
+		percent = 100
+	}
+	return fmt.Sprintf("%s / %s (%d%%)", utils.FormatBinaryBytes(int(p.Copied)), utils.FormatBinaryBytes(int(p.Total)), percent)
+}
+
+// copyCounter counts the bytes of file content going through it, reporting
+// them every so often
+type copyCounter struct {
+	progress   CopyProgress
+	onProgress func(CopyProgress)
+	reportedAt time.Time
+}
+
+func (c *copyCounter) add(n int64) {
+	c.progress.Copied += n
+	if time.Since(c.reportedAt) >= copyProgressInterval {
+		c.report()
+	}
+}
+
+func (c *copyCounter) report() {
+	c.reportedAt = time.Now()
+	c.onProgress(c.progress)
+}
+
+func (c *copyCounter) copy(dst io.Writer, src io.Reader) error {
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := src.Read(buf)
+		if n > 0 {
+			if _, werr := dst.Write(buf[:n]); werr != nil {
+				return werr
+			}
+			c.add(int64(n))
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// CopyFromContainer copies srcPath out of the container to dstPath on this
+// machine, like docker cp. If dstPath is a directory, what's copied goes in
+// it; otherwise it's copied to dstPath, whose parent directory must exist.
+// The files come to us as a tar stream through the API, so this works the
+// same over an ssh tunnel.
+func (c *Container) CopyFromContainer(ctx context.Context, srcPath, dstPath string, onProgress func(CopyProgress)) error {
+	content, stat, err := c.Client.CopyFromContainer(ctx, c.ID, srcPath)
+	if err != nil {
+		return containerCopyError(err, srcPath)
+	}
+	defer content.Close()
+
+	dstDir, rename, err := localCopyDestination(dstPath, stat.Mode.IsDir())
+	if err != nil {
+		return err
+	}
+
+	counter := &copyCounter{onProgress: onProgress}
+	if stat.Mode.IsRegular() {
+		counter.progress.Total = stat.Size
+	}
+	if err := extractTar(tar.NewReader(content), dstDir, rename, counter); err != nil {
+		return localCopyError(err)
+	}
+	counter.report()
+	return nil
+}
+
+// localCopyDestination works out which directory on this machine to extract
+// into, and what to rename what's copied to, if anything
+func localCopyDestination(dstPath string, srcIsDir bool) (string, string, error) {
+	info, err := os.Stat(dstPath)
+	switch {
+	case err == nil && info.IsDir():
+		return dstPath, "", nil
+	case err == nil && srcIsDir:
+		return "", "", fmt.Errorf("can't copy a directory over the file %s", dstPath)
+	case err != nil && !os.IsNotExist(err):
+		return "", "", localCopyError(err)
+	}
+
+	dstDir := filepath.Dir(dstPath)
+	if info, err := os.Stat(dstDir); err != nil || !info.IsDir() {
+		return "", "", fmt.Errorf("there's no directory %s on this machine to copy into", dstDir)
+	}
+	return dstDir, filepath.Base(dstPath), nil
+}
+
+// extractTar writes what's in the tar stream into dstDir. The stream's
+// entries all sit under the one top-level name, which is swapped for rename
+// if it's given.
+func extractTar(reader *tar.Reader, dstDir, rename string, counter *copyCounter) error {
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		name := path.Clean(strings.TrimPrefix(header.Name, "/"))
+		if name == ".." || strings.HasPrefix(name, "../") {
+			return fmt.Errorf("refusing to copy %s, as it's outside the directory being copied", header.Name)
+		}
+		if rename != "" {
+			parts := strings.SplitN(name, "/", 2)
+			parts[0] = rename
+			name = strings.Join(parts, "/")
+		}
+		target := filepath.Join(dstDir, filepath.FromSlash(name))
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, header.FileInfo().Mode().Perm()|0700); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := extractFile(reader, target, header.FileInfo().Mode().Perm(), counter); err != nil {
+				return err
+			}
+		case tar.TypeSymlink:
+			_ = os.Remove(target)
+			if err := os.Symlink(header.Linkname, target); err != nil {
+				return err
+			}
+		default:
+			// devices, fifos and hard links aren't worth the trouble here
+			continue
+		}
+	}
+}
+
+func extractFile(reader io.Reader, target string, mode os.FileMode, counter *copyCounter) error {
+	file, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+	if err := counter.copy(file, reader); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// CopyToContainer copies srcPath on this machine into the container at
+// dstPath, like docker cp. If dstPath is a directory in the container, what's
+// copied goes in it; otherwise it's copied to dstPath, whose parent directory
+// must exist. We stream it to the daemon as a tar as we go, so nothing big is
+// held in memory.
+func (c *Container) CopyToContainer(ctx context.Context, srcPath, dstPath string, onProgress func(CopyProgress)) error {
+	srcInfo, err := os.Lstat(srcPath)
+	if err != nil {
+		return localCopyError(err)
+	}
+
+	dstDir, name, err := c.containerCopyDestination(ctx, dstPath, filepath.Base(srcPath), srcInfo.IsDir())
+	if err != nil {
+		return err
+	}
+
+	total, err := copySize(srcPath)
+	if err != nil {
+		return localCopyError(err)
+	}
+	counter := &copyCounter{onProgress: onProgress, progress: CopyProgress{Total: total}}
+
+	reader, writer := io.Pipe()
+	tarErr := make(chan error, 1)
+	go func() {
+		err := writeTar(tar.NewWriter(writer), srcPath, name, counter)
+		tarErr <- err
+		writer.CloseWithError(err)
+	}()
+
+	err = c.Client.CopyToContainer(ctx, c.ID, dstDir, reader, types.CopyToContainerOptions{})
+	// if the daemon gave up first, this stops the tar being written
+	reader.CloseWithError(io.ErrClosedPipe)
+	if terr := <-tarErr; terr != nil && terr != io.ErrClosedPipe {
+		return localCopyError(terr)
+	}
+	if err != nil {
+		return containerCopyError(err, dstPath)
+	}
+	counter.report()
+	return nil
+}
+
+// containerCopyDestination works out which directory in the container to copy
+// into, and what to call what's copied
+func (c *Container) containerCopyDestination(ctx context.Context, dstPath, srcName string, srcIsDir bool) (string, string, error) {
+	stat, err := c.Client.ContainerStatPath(ctx, c.ID, dstPath)
+	switch {
+	case err == nil && stat.Mode.IsDir():
+		return dstPath, srcName, nil
+	case err == nil && srcIsDir:
+		return "", "", fmt.Errorf("can't copy a directory over the file %s in the container", dstPath)
+	case err != nil && !client.IsErrNotFound(err):
+		return "", "", containerCopyError(err, dstPath)
+	}
+
+	dstDir := path.Dir(filepath.ToSlash(dstPath))
+	if stat, err := c.Client.ContainerStatPath(ctx, c.ID, dstDir); err != nil || !stat.Mode.IsDir() {
+		return "", "", fmt.Errorf("there's no directory %s in the container to copy into", dstDir)
+	}
+	return dstDir, path.Base(filepath.ToSlash(dstPath)), nil
+}
+
+// copySize adds up the size of the files under srcPath
+func copySize(srcPath string) (int64, error) {
+	total := int64(0)
+	err := filepath.Walk(srcPath, func(_ string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.Mode().IsRegular() {
+			total += info.Size()
+		}
+		return nil
+	})
+	return total, err
+}
+
+// writeTar writes srcPath to the tar as name, and everything under it if it's
+// a directory
+func writeTar(writer *tar.Writer, srcPath, name string, counter *copyCounter) error {
+	err := filepath.Walk(srcPath, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(srcPath, filePath)
+		if err != nil {
+			return err
+		}
+
+		link := ""
+		if info.Mode()&os.ModeSymlink != 0 {
+			if link, err = os.Readlink(filePath); err != nil {
+				return err
+			}
+		}
+		header, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			return err
+		}
+		header.Name = path.Join(name, filepath.ToSlash(rel))
+		if info.IsDir() {
+			header.Name += "/"
+		}
+		if err := writer.WriteHeader(header); err != nil {
+			return err
+		}
+
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		file, err := os.Open(filePath)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		return counter.copy(writer, file)
+	})
+	if err != nil {
+		return err
+	}
+	return writer.Close()
+}
+
+// localCopyError makes errors from this machine's filesystem say what went
+// wrong in plain words
+func localCopyError(err error) error {
+	if pathErr, ok := err.(*os.PathError); ok {
+		switch {
+		case os.IsPermission(err):
+			return fmt.Errorf("permission denied for %s on this machine", pathErr.Path)
+		case os.IsNotExist(err):
+			return fmt.Errorf("there's no %s on this machine", pathErr.Path)
+		}
+	}
+	return err
+}
+
+// containerCopyError does the same for errors from the daemon
+func containerCopyError(err error, containerPath string) error {
+	message := strings.ToLower(err.Error())
+	switch {
+	case client.IsErrNotFound(err):
+		return fmt.Errorf("there's no %s in the container", containerPath)
+	case strings.Contains(message, "permission denied"):
+		return fmt.Errorf("permission denied for %s in the container: %w", containerPath, err)
+	case strings.Contains(message, "read-only"):
+		return fmt.Errorf("%s is on a read-only filesystem in the container: %w", containerPath, err)
+	}
+	return err
+}
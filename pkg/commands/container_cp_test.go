@@ -0,0 +1,267 @@
+package commands
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeContainerFS answers the archive endpoints as the daemon would for a
+// container with the given directories and files in it
+type fakeContainerFS struct {
+	dirs  map[string]bool
+	files map[string]string
+	// put is the path and tar entries of what was last copied in
+	putPath    string
+	putEntries map[string]string
+	putErr     string
+}
+
+func (f *fakeContainerFS) stat(w http.ResponseWriter, name string) bool {
+	stat := types.ContainerPathStat{Name: filepath.Base(name)}
+	switch {
+	case f.dirs[name]:
+		stat.Mode = os.ModeDir | 0755
+	case f.files[name] != "":
+		stat.Mode = 0644
+		stat.Size = int64(len(f.files[name]))
+	default:
+		http.Error(w, "Could not find the file "+name+" in container", http.StatusNotFound)
+		return false
+	}
+	encoded, _ := json.Marshal(stat)
+	w.Header().Set("X-Docker-Container-Path-Stat", base64.StdEncoding.EncodeToString(encoded))
+	return true
+}
+
+func (f *fakeContainerFS) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("path")
+	switch r.Method {
+	case http.MethodHead:
+		f.stat(w, name)
+	case http.MethodGet:
+		if !f.stat(w, name) {
+			return
+		}
+		writer := tar.NewWriter(w)
+		base := filepath.Base(name)
+		if f.dirs[name] {
+			_ = writer.WriteHeader(&tar.Header{Name: base + "/", Typeflag: tar.TypeDir, Mode: 0755})
+			for file, content := range f.files {
+				if filepath.Dir(file) == name {
+					_ = writer.WriteHeader(&tar.Header{Name: base + "/" + filepath.Base(file), Typeflag: tar.TypeReg, Mode: 0644, Size: int64(len(content))})
+					_, _ = writer.Write([]byte(content))
+				}
+			}
+		} else {
+			content := f.files[name]
+			_ = writer.WriteHeader(&tar.Header{Name: base, Typeflag: tar.TypeReg, Mode: 0644, Size: int64(len(content))})
+			_, _ = writer.Write([]byte(content))
+		}
+		_ = writer.Close()
+	case http.MethodPut:
+		if f.putErr != "" {
+			http.Error(w, f.putErr, http.StatusInternalServerError)
+			return
+		}
+		f.putPath = name
+		f.putEntries = map[string]string{}
+		reader := tar.NewReader(r.Body)
+		for {
+			header, err := reader.Next()
+			if err != nil {
+				break
+			}
+			content, _ := ioutil.ReadAll(reader)
+			f.putEntries[header.Name] = string(content)
+		}
+	}
+}
+
+func newCopyTestContainer(t *testing.T, fs *fakeContainerFS) *Container {
+	dockerCommand := newFakeDaemonCommand(t, fs.ServeHTTP)
+	return &Container{ID: "web", Name: "web", Client: dockerCommand.Client}
+}
+
+func TestContainerCopyFromContainer(t *testing.T) {
+	fs := &fakeContainerFS{
+		dirs:  map[string]bool{"/app": true, "/app/config": true},
+		files: map[string]string{"/app/config/a.yml": "a: 1", "/app/config/b.yml": "b: 2", "/app/log.txt": "hello"},
+	}
+	container := newCopyTestContainer(t, fs)
+
+	type scenario struct {
+		testName string
+		src      string
+		dst      string
+		expected map[string]string
+		err      string
+	}
+
+	scenarios := []scenario{
+		{
+			testName: "A directory into an existing directory",
+			src:      "/app/config",
+			dst:      ".",
+			expected: map[string]string{"config/a.yml": "a: 1", "config/b.yml": "b: 2"},
+		},
+		{
+			testName: "A directory to a new name",
+			src:      "/app/config",
+			dst:      "settings",
+			expected: map[string]string{"settings/a.yml": "a: 1", "settings/b.yml": "b: 2"},
+		},
+		{
+			testName: "A file to a new name",
+			src:      "/app/log.txt",
+			dst:      "web.log",
+			expected: map[string]string{"web.log": "hello"},
+		},
+		{
+			testName: "Into a directory that isn't there",
+			src:      "/app/log.txt",
+			dst:      "missing/web.log",
+			err:      "there's no directory",
+		},
+		{
+			testName: "A file that isn't in the container",
+			src:      "/app/missing.txt",
+			dst:      ".",
+			err:      "there's no /app/missing.txt in the container",
+		},
+	}
+
+	for _, s := range scenarios {
+		s := s
+		t.Run(s.testName, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "lazydocker-cp")
+			assert.NoError(t, err)
+			defer os.RemoveAll(dir)
+
+			var last CopyProgress
+			err = container.CopyFromContainer(context.Background(), s.src, filepath.Join(dir, s.dst), func(progress CopyProgress) {
+				last = progress
+			})
+			if s.err != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), s.err)
+				return
+			}
+			assert.NoError(t, err)
+
+			copied := map[string]string{}
+			total := int64(0)
+			_ = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+				if info.Mode().IsRegular() {
+					content, _ := ioutil.ReadFile(path)
+					rel, _ := filepath.Rel(dir, path)
+					copied[filepath.ToSlash(rel)] = string(content)
+					total += info.Size()
+				}
+				return nil
+			})
+			assert.Equal(t, s.expected, copied)
+			assert.Equal(t, total, last.Copied)
+		})
+	}
+}
+
+func TestContainerCopyToContainer(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lazydocker-cp")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "config"), 0755))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "config", "a.yml"), []byte("a: 1"), 0644))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "seed.sql"), bytes.Repeat([]byte("x"), 100), 0644))
+
+	type scenario struct {
+		testName     string
+		src          string
+		dst          string
+		putErr       string
+		expectedPath string
+		expected     map[string]string
+		total        int64
+		err          string
+	}
+
+	scenarios := []scenario{
+		{
+			testName:     "A directory into an existing directory",
+			src:          "config",
+			dst:          "/app",
+			expectedPath: "/app",
+			expected:     map[string]string{"config/": "", "config/a.yml": "a: 1"},
+			total:        4,
+		},
+		{
+			testName:     "A file to a new name",
+			src:          "seed.sql",
+			dst:          "/app/init.sql",
+			expectedPath: "/app",
+			expected:     map[string]string{"init.sql": string(bytes.Repeat([]byte("x"), 100))},
+			total:        100,
+		},
+		{
+			testName: "Into a directory that isn't there",
+			src:      "seed.sql",
+			dst:      "/missing/init.sql",
+			err:      "there's no directory /missing in the container to copy into",
+		},
+		{
+			testName: "A file that isn't on this machine",
+			src:      "missing.sql",
+			dst:      "/app",
+			err:      "there's no " + filepath.Join(dir, "missing.sql") + " on this machine",
+		},
+		{
+			testName: "The container won't let us write",
+			src:      "seed.sql",
+			dst:      "/app",
+			putErr:   "open /app/seed.sql: permission denied",
+			err:      "permission denied for /app in the container",
+		},
+	}
+
+	for _, s := range scenarios {
+		s := s
+		t.Run(s.testName, func(t *testing.T) {
+			fs := &fakeContainerFS{dirs: map[string]bool{"/app": true}, files: map[string]string{}, putErr: s.putErr}
+			container := newCopyTestContainer(t, fs)
+
+			var last CopyProgress
+			err := container.CopyToContainer(context.Background(), filepath.Join(dir, s.src), s.dst, func(progress CopyProgress) {
+				last = progress
+			})
+			if s.err != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), s.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, s.expectedPath, fs.putPath)
+			assert.Equal(t, s.expected, fs.putEntries)
+			assert.Equal(t, CopyProgress{Copied: s.total, Total: s.total}, last)
+		})
+	}
+}
+
+func TestExtractTarRefusesToEscape(t *testing.T) {
+	buf := &bytes.Buffer{}
+	writer := tar.NewWriter(buf)
+	_ = writer.WriteHeader(&tar.Header{Name: "../evil", Typeflag: tar.TypeReg, Size: 0})
+	_ = writer.Close()
+
+	err := extractTar(tar.NewReader(buf), os.TempDir(), "", &copyCounter{onProgress: func(CopyProgress) {}})
+	assert.EqualError(t, err, "refusing to copy ../evil, as it's outside the directory being copied")
+}
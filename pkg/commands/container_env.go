@@ -0,0 +1,54 @@
+package commands
+
+import "strings"
+
+// MaskedEnvValue is shown in place of a secret's value. It's the same length
+// whatever the value, so that it doesn't give away how long the secret is.
+const MaskedEnvValue = "********"
+
+// secretEnvKeyPatterns are the parts of a variable's name that suggest its
+// value is a secret, e.g. DB_PASSWORD, GITHUB_TOKEN or AWS_SECRET_ACCESS_KEY
+var secretEnvKeyPatterns = []string{"PASSWORD", "PASSWD", "TOKEN", "SECRET", "KEY"}
+
+// EnvVar is one of a container's environment variables
+type EnvVar struct {
+	Key   string
+	Value string
+}
+
+// IsSecret tells us whether the variable's name looks like it holds a secret.
+// We'd rather hide a value that didn't need it than show one that did, so any
+// name containing one of the patterns counts.
+func (e EnvVar) IsSecret() bool {
+	key := strings.ToUpper(e.Key)
+	for _, pattern := range secretEnvKeyPatterns {
+		if strings.Contains(key, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// DisplayValue is the value to show, masked if it's a secret and we haven't
+// been asked to reveal it. There's nothing to hide in an empty value.
+func (e EnvVar) DisplayValue(reveal bool) string {
+	if reveal || e.Value == "" || !e.IsSecret() {
+		return e.Value
+	}
+	return MaskedEnvValue
+}
+
+// EnvVars returns the container's environment variables from its inspect
+// data, in the order they were set
+func (c *Container) EnvVars() []EnvVar {
+	envVars := make([]EnvVar, 0, len(c.Details.Config.Env))
+	for _, env := range c.Details.Config.Env {
+		splitEnv := strings.SplitN(env, "=", 2)
+		envVar := EnvVar{Key: splitEnv[0]}
+		if len(splitEnv) > 1 {
+			envVar.Value = splitEnv[1]
+		}
+		envVars = append(envVars, envVar)
+	}
+	return envVars
+}
@@ -0,0 +1,69 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContainerEnvVars(t *testing.T) {
+	container := &Container{}
+	container.Details.Config.Env = []string{"PATH=/usr/bin", "EMPTY=", "FLAG", "URL=postgres://db?sslmode=disable"}
+
+	assert.EqualValues(t, []EnvVar{
+		{Key: "PATH", Value: "/usr/bin"},
+		{Key: "EMPTY", Value: ""},
+		{Key: "FLAG", Value: ""},
+		{Key: "URL", Value: "postgres://db?sslmode=disable"},
+	}, container.EnvVars())
+}
+
+func TestEnvVarDisplayValue(t *testing.T) {
+	type scenario struct {
+		testName string
+		envVar   EnvVar
+		reveal   bool
+		expected string
+	}
+
+	scenarios := []scenario{
+		{
+			testName: "Not a secret",
+			envVar:   EnvVar{Key: "PATH", Value: "/usr/bin"},
+			expected: "/usr/bin",
+		},
+		{
+			testName: "Password",
+			envVar:   EnvVar{Key: "POSTGRES_PASSWORD", Value: "hunter2"},
+			expected: MaskedEnvValue,
+		},
+		{
+			testName: "Lowercase key",
+			envVar:   EnvVar{Key: "github_token", Value: "ghp_abcdef"},
+			expected: MaskedEnvValue,
+		},
+		{
+			testName: "Secret key",
+			envVar:   EnvVar{Key: "AWS_SECRET_ACCESS_KEY", Value: "abc"},
+			expected: MaskedEnvValue,
+		},
+		{
+			testName: "Revealed",
+			envVar:   EnvVar{Key: "API_KEY", Value: "abc"},
+			reveal:   true,
+			expected: "abc",
+		},
+		{
+			testName: "Empty secret",
+			envVar:   EnvVar{Key: "REDIS_PASSWORD", Value: ""},
+			expected: "",
+		},
+	}
+
+	for _, s := range scenarios {
+		s := s
+		t.Run(s.testName, func(t *testing.T) {
+			assert.Equal(t, s.expected, s.envVar.DisplayValue(s.reveal))
+		})
+	}
+}
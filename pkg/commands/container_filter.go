@@ -0,0 +1,98 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+)
+
+// containerStatuses are the statuses docker lets you filter containers by
+var containerStatuses = []string{"created", "restarting", "running", "removing", "paused", "exited", "dead"}
+
+// ParseContainerFilter turns a filter typed into the containers panel into
+// docker list filters. It takes the same terms as `docker ps --filter`,
+// separated by spaces, e.g. `status=running name=web label=app=foo`. Only the
+// status, name and label keys are supported. A blank filter matches
+// everything.
+func ParseContainerFilter(text string) (filters.Args, error) {
+	args := filters.NewArgs()
+	for _, term := range strings.Fields(text) {
+		parts := strings.SplitN(term, "=", 2)
+		if len(parts) != 2 || parts[1] == "" {
+			return filters.NewArgs(), fmt.Errorf("expected key=value, got %q", term)
+		}
+		key, value := parts[0], parts[1]
+
+		switch key {
+		case "status":
+			if !isContainerStatus(value) {
+				return filters.NewArgs(), fmt.Errorf("unknown status %q: expected one of %s", value, strings.Join(containerStatuses, ", "))
+			}
+		case "name", "label":
+		default:
+			return filters.NewArgs(), fmt.Errorf("unknown filter %q: expected status, name or label", key)
+		}
+		args.Add(key, value)
+	}
+	return args, nil
+}
+
+func isContainerStatus(status string) bool {
+	for _, s := range containerStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// SetContainerFilter sets the filter the containers panel is narrowed down by.
+// It takes effect on the next refresh.
+func (c *DockerCommand) SetContainerFilter(args filters.Args) {
+	c.ContainerMutex.Lock()
+	defer c.ContainerMutex.Unlock()
+
+	c.containerFilter = args
+}
+
+// filterContainers returns the containers matching our container filter, if we
+// have one. Rather than reimplement docker's matching we ask the daemon which
+// containers match, so the filter means exactly what it does for docker ps.
+func (c *DockerCommand) filterContainers(containers []*Container) ([]*Container, error) {
+	c.ContainerMutex.Lock()
+	args := c.containerFilter
+	c.ContainerMutex.Unlock()
+
+	if args.Len() == 0 {
+		return containers, nil
+	}
+
+	matches, err := c.containerListCache.get(types.ContainerListOptions{All: true, Filters: args}, c.listContainers)
+	if err != nil {
+		return nil, err
+	}
+	matchingIDs := make(map[string]bool, len(matches))
+	for _, match := range matches {
+		matchingIDs[match.ID] = true
+	}
+
+	toReturn := []*Container{}
+	for _, container := range containers {
+		if matchingIDs[container.ID] {
+			toReturn = append(toReturn, container)
+		}
+	}
+	return toReturn, nil
+}
+
+// hasStatusFilter tells us whether the container filter picks containers by
+// status, in which case it's the filter that decides whether we show exited
+// containers
+func (c *DockerCommand) hasStatusFilter() bool {
+	c.ContainerMutex.Lock()
+	defer c.ContainerMutex.Unlock()
+
+	return c.containerFilter.Len() > 0 && c.containerFilter.Include("status")
+}
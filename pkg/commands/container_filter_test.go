@@ -0,0 +1,115 @@
+package commands
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseContainerFilter(t *testing.T) {
+	type scenario struct {
+		testName            string
+		text                string
+		expected            map[string][]string
+		expectedErrorSubstr string
+	}
+
+	scenarios := []scenario{
+		{
+			testName: "Blank filter",
+			text:     "  ",
+			expected: map[string][]string{},
+		},
+		{
+			testName: "Status",
+			text:     "status=running",
+			expected: map[string][]string{"status": {"running"}},
+		},
+		{
+			testName: "Label with a value",
+			text:     "label=app=foo",
+			expected: map[string][]string{"label": {"app=foo"}},
+		},
+		{
+			testName: "Several terms",
+			text:     "name=web  status=exited status=paused",
+			expected: map[string][]string{"name": {"web"}, "status": {"exited", "paused"}},
+		},
+		{
+			testName:            "Missing value",
+			text:                "name=",
+			expectedErrorSubstr: `expected key=value, got "name="`,
+		},
+		{
+			testName:            "Missing equals sign",
+			text:                "web",
+			expectedErrorSubstr: `expected key=value, got "web"`,
+		},
+		{
+			testName:            "Unknown key",
+			text:                "ancestor=alpine",
+			expectedErrorSubstr: `unknown filter "ancestor"`,
+		},
+		{
+			testName:            "Unknown status",
+			text:                "status=up",
+			expectedErrorSubstr: `unknown status "up"`,
+		},
+	}
+
+	for _, s := range scenarios {
+		s := s
+		t.Run(s.testName, func(t *testing.T) {
+			args, err := ParseContainerFilter(s.text)
+			if s.expectedErrorSubstr != "" {
+				if assert.Error(t, err) {
+					assert.Contains(t, err.Error(), s.expectedErrorSubstr)
+				}
+				return
+			}
+			assert.NoError(t, err)
+
+			expected := filters.NewArgs()
+			for key, values := range s.expected {
+				for _, value := range values {
+					expected.Add(key, value)
+				}
+			}
+			expectedJSON, _ := filters.ToJSON(expected)
+			actualJSON, _ := filters.ToJSON(args)
+			assert.Equal(t, expectedJSON, actualJSON)
+		})
+	}
+}
+
+func TestDockerCommandFilterContainers(t *testing.T) {
+	queries := []string{}
+	dockerCommand := newFakeDaemonCommand(t, func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.Query().Get("filters"))
+		_ = json.NewEncoder(w).Encode([]types.Container{{ID: "2"}})
+	})
+	dockerCommand.containerListCache = newContainerListCache(time.Minute)
+	containers := []*Container{{ID: "1"}, {ID: "2"}, {ID: "3"}}
+
+	// without a filter we don't need to ask the daemon anything
+	filtered, err := dockerCommand.filterContainers(containers)
+	assert.NoError(t, err)
+	assert.Len(t, filtered, 3)
+	assert.Empty(t, queries)
+
+	dockerCommand.SetContainerFilter(filters.NewArgs(filters.Arg("status", "running")))
+	filtered, err = dockerCommand.filterContainers(containers)
+	assert.NoError(t, err)
+	if assert.Len(t, filtered, 1) {
+		assert.Equal(t, "2", filtered[0].ID)
+	}
+	if assert.Len(t, queries, 1) {
+		assert.Contains(t, queries[0], `"status":{"running":true}`)
+	}
+	assert.True(t, dockerCommand.hasStatusFilter())
+}
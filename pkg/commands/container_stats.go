@@ -27,6 +27,48 @@ type RecordedStats struct {
 type DerivedStats struct {
 	CPUPercentage    float64
 	MemoryPercentage float64
+	// MemoryUsage is in bytes, not counting the page cache, as docker stats
+	// does
+	MemoryUsage int
+	MemoryLimit int64
+	// NetworkRxBytes and NetworkTxBytes are totals across all of the
+	// container's networks
+	NetworkRxBytes int
+	NetworkTxBytes int
+}
+
+// containerNetworkStats is the part of the stats we need to add up traffic
+// over every network, rather than just eth0
+type containerNetworkStats struct {
+	Networks map[string]struct {
+		RxBytes int `json:"rx_bytes"`
+		TxBytes int `json:"tx_bytes"`
+	} `json:"networks"`
+}
+
+// newRecordedStats makes a record of the stats docker sent us, as json
+func newRecordedStats(data []byte, recordedAt time.Time) (RecordedStats, error) {
+	var stats ContainerStats
+	if err := json.Unmarshal(data, &stats); err != nil {
+		return RecordedStats{}, err
+	}
+	var networkStats containerNetworkStats
+	if err := json.Unmarshal(data, &networkStats); err != nil {
+		return RecordedStats{}, err
+	}
+
+	derived := DerivedStats{
+		CPUPercentage:    stats.CalculateContainerCPUPercentage(),
+		MemoryPercentage: stats.CalculateContainerMemoryUsage(),
+		MemoryUsage:      stats.memoryUsageWithoutCache(),
+		MemoryLimit:      stats.MemoryStats.Limit,
+	}
+	for _, network := range networkStats.Networks {
+		derived.NetworkRxBytes += network.RxBytes
+		derived.NetworkTxBytes += network.TxBytes
+	}
+
+	return RecordedStats{ClientStats: stats, DerivedStats: derived, RecordedAt: recordedAt}, nil
 }
 
 // ContainerStats autogenerated at https://mholt.github.io/json-to-go/
@@ -144,29 +186,46 @@ type ContainerStats struct {
 	} `json:"networks"`
 }
 
-// CalculateContainerCPUPercentage calculates the cpu usage of the container as a percent of total CPU usage
+// CalculateContainerCPUPercentage calculates the cpu usage of the container as a percent of total CPU usage, the same way the docker cli does
 // to calculate CPU usage, we take the increase in CPU time from the container since the last poll, divide that by the total increase in CPU time since the last poll, times by the number of cores, and times by 100 to get a percentage
-// I'm not entirely sure why we need to multiply by the number of cores, but the numbers work
+// system usage is summed across all the cores, which is why we need to multiply by the number of cores: a container maxing out one of four cores gets 100%, not 25%
 func (s *ContainerStats) CalculateContainerCPUPercentage() float64 {
 	cpuUsageDelta := s.CPUStats.CPUUsage.TotalUsage - s.PrecpuStats.CPUUsage.TotalUsage
 	cpuTotalUsageDelta := s.CPUStats.SystemCPUUsage - s.PrecpuStats.SystemCPUUsage
-	numberOfCores := len(s.CPUStats.CPUUsage.PercpuUsage)
+	numberOfCores := s.CPUStats.OnlineCpus
+	if numberOfCores == 0 {
+		// older daemons don't send online_cpus
+		numberOfCores = len(s.CPUStats.CPUUsage.PercpuUsage)
+	}
 
-	value := float64(cpuUsageDelta*100) * float64(numberOfCores) / float64(cpuTotalUsageDelta)
-	if math.IsNaN(value) {
+	// the first sample has nothing to compare against
+	if cpuUsageDelta <= 0 || cpuTotalUsageDelta <= 0 {
 		return 0
 	}
-	return value
+
+	return float64(cpuUsageDelta) / float64(cpuTotalUsageDelta) * float64(numberOfCores) * 100
 }
 
 // CalculateContainerMemoryUsage calculates the memory usage of the container as a percent of total available memory
 func (s *ContainerStats) CalculateContainerMemoryUsage() float64 {
-
-	value := float64(s.MemoryStats.Usage*100) / float64(s.MemoryStats.Limit)
-	if math.IsNaN(value) {
+	if s.MemoryStats.Limit == 0 {
 		return 0
 	}
-	return value
+	return float64(s.memoryUsageWithoutCache()*100) / float64(s.MemoryStats.Limit)
+}
+
+// memoryUsageWithoutCache is the usage docker stats shows: the page cache
+// doesn't count, because the kernel will give it up if it needs to. It's
+// called total_inactive_file on cgroup v1 and inactive_file on cgroup v2.
+func (s *ContainerStats) memoryUsageWithoutCache() int {
+	usage := s.MemoryStats.Usage
+	if inactive := s.MemoryStats.Stats.TotalInactiveFile; inactive > 0 && inactive < usage {
+		return usage - inactive
+	}
+	if inactive := s.MemoryStats.Stats.InactiveFile; inactive > 0 && inactive < usage {
+		return usage - inactive
+	}
+	return usage
 }
 
 // RenderStats returns a string containing the rendered stats of the container
@@ -187,17 +246,22 @@ func (c *Container) RenderStats(viewWidth int) (string, error) {
 		graphs[i] = utils.ColoredString(graph, utils.GetColorAttribute(spec.Color))
 	}
 
+	derived := currentStats.DerivedStats
+	cpu := fmt.Sprintf("CPU: %.2f%%", derived.CPUPercentage)
+	memory := fmt.Sprintf("Memory: %s / %s (%.2f%%)", utils.FormatBinaryBytes(derived.MemoryUsage), utils.FormatBinaryBytes(int(derived.MemoryLimit)), derived.MemoryPercentage)
 	pidsCount := fmt.Sprintf("PIDs: %d", currentStats.ClientStats.PidsStats.Current)
-	dataReceived := fmt.Sprintf("Traffic received: %s", utils.FormatDecimalBytes(currentStats.ClientStats.Networks.Eth0.RxBytes))
-	dataSent := fmt.Sprintf("Traffic sent: %s", utils.FormatDecimalBytes(currentStats.ClientStats.Networks.Eth0.TxBytes))
+	dataReceived := fmt.Sprintf("Traffic received: %s", utils.FormatDecimalBytes(derived.NetworkRxBytes))
+	dataSent := fmt.Sprintf("Traffic sent: %s", utils.FormatDecimalBytes(derived.NetworkTxBytes))
 
 	originalJSON, err := json.MarshalIndent(currentStats, "", "  ")
 	if err != nil {
 		return "", err
 	}
 
-	contents := fmt.Sprintf("\n\n%s\n\n%s\n\n%s\n%s\n\n%s",
+	contents := fmt.Sprintf("\n\n%s\n\n%s\n%s\n%s\n\n%s\n%s\n\n%s",
 		utils.ColoredString(strings.Join(graphs, "\n\n"), color.FgGreen),
+		cpu,
+		memory,
 		pidsCount,
 		dataReceived,
 		dataSent,
@@ -0,0 +1,121 @@
+package commands
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/jesseduffield/lazydocker/pkg/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCalculateContainerCPUPercentage(t *testing.T) {
+	type scenario struct {
+		testName string
+		stats    func(stats *ContainerStats)
+		expected float64
+	}
+
+	scenarios := []scenario{
+		{
+			testName: "Online cpus",
+			stats: func(stats *ContainerStats) {
+				stats.CPUStats.CPUUsage.TotalUsage = 300
+				stats.PrecpuStats.CPUUsage.TotalUsage = 100
+				stats.CPUStats.SystemCPUUsage = 2000
+				stats.PrecpuStats.SystemCPUUsage = 1000
+				stats.CPUStats.OnlineCpus = 4
+			},
+			expected: 80,
+		},
+		{
+			testName: "Falls back to the per-cpu usage for older daemons",
+			stats: func(stats *ContainerStats) {
+				stats.CPUStats.CPUUsage.TotalUsage = 300
+				stats.PrecpuStats.CPUUsage.TotalUsage = 100
+				stats.CPUStats.SystemCPUUsage = 2000
+				stats.PrecpuStats.SystemCPUUsage = 1000
+				stats.CPUStats.CPUUsage.PercpuUsage = []int64{1, 2}
+			},
+			expected: 40,
+		},
+		{
+			testName: "First sample",
+			stats: func(stats *ContainerStats) {
+				stats.CPUStats.CPUUsage.TotalUsage = 300
+				stats.CPUStats.SystemCPUUsage = 2000
+				stats.PrecpuStats.SystemCPUUsage = 2000
+				stats.CPUStats.OnlineCpus = 4
+			},
+			expected: 0,
+		},
+	}
+
+	for _, s := range scenarios {
+		s := s
+		t.Run(s.testName, func(t *testing.T) {
+			stats := &ContainerStats{}
+			s.stats(stats)
+			assert.InDelta(t, s.expected, stats.CalculateContainerCPUPercentage(), 0.001)
+		})
+	}
+}
+
+func TestNewRecordedStats(t *testing.T) {
+	data := []byte(`{
+		"memory_stats": {"usage": 1000, "limit": 4000, "stats": {"total_inactive_file": 200}},
+		"networks": {
+			"eth0": {"rx_bytes": 10, "tx_bytes": 20},
+			"eth1": {"rx_bytes": 1, "tx_bytes": 2}
+		}
+	}`)
+	recordedAt := time.Unix(100, 0)
+
+	stats, err := newRecordedStats(data, recordedAt)
+	assert.NoError(t, err)
+	assert.Equal(t, recordedAt, stats.RecordedAt)
+	assert.Equal(t, 800, stats.DerivedStats.MemoryUsage)
+	assert.EqualValues(t, 4000, stats.DerivedStats.MemoryLimit)
+	assert.InDelta(t, 20, stats.DerivedStats.MemoryPercentage, 0.001)
+	assert.Equal(t, 11, stats.DerivedStats.NetworkRxBytes)
+	assert.Equal(t, 22, stats.DerivedStats.NetworkTxBytes)
+	assert.Equal(t, 10, stats.ClientStats.Networks.Eth0.RxBytes)
+}
+
+func TestDockerCommandStreamContainerStats(t *testing.T) {
+	dockerCommand := newFakeDaemonCommand(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "1", r.URL.Query().Get("stream"))
+		_, _ = w.Write([]byte(`{"memory_stats": {"usage": 1, "limit": 2}}` + "\n"))
+		_, _ = w.Write([]byte(`{"memory_stats": {"usage": 2, "limit": 2}}` + "\n"))
+		w.(http.Flusher).Flush()
+		// docker keeps the stream open for as long as the container's running
+		<-r.Context().Done()
+	})
+	userConfig := config.GetDefaultConfig()
+	container := &Container{ID: "123", Config: &config.AppConfig{UserConfig: &userConfig}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- dockerCommand.StreamContainerStats(ctx, container) }()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		dockerCommand.ContainerMutex.Lock()
+		count := len(container.StatHistory)
+		dockerCommand.ContainerMutex.Unlock()
+		if count == 2 || time.Now().After(deadline) {
+			assert.Equal(t, 2, count)
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected cancelling to close the stream")
+	}
+}
@@ -0,0 +1,466 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/jesseduffield/lazydocker/pkg/i18n"
+	"github.com/jesseduffield/lazydocker/pkg/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+// syncBuffer lets the test read what's been streamed so far while StreamLogs
+// is still writing to it
+type syncBuffer struct {
+	mutex sync.Mutex
+	buf   bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.buf.String()
+}
+
+// newFakeDaemonCommand starts a fake docker daemon that answers with handler
+// and gives back a DockerCommand whose client talks to it. The daemon is shut
+// down when the test finishes.
+func newFakeDaemonCommand(t *testing.T, handler http.HandlerFunc) *DockerCommand {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	cli, err := client.NewClientWithOpts(client.WithHost("tcp://"+server.Listener.Addr().String()), client.WithVersion(APIVersion))
+	assert.NoError(t, err)
+	return &DockerCommand{Client: cli}
+}
+
+func TestContainerStreamLogs(t *testing.T) {
+	type scenario struct {
+		testName string
+		tty      bool
+		write    func(w http.ResponseWriter)
+	}
+
+	scenarios := []scenario{
+		{
+			testName: "Multiplexed stream",
+			tty:      false,
+			write: func(w http.ResponseWriter) {
+				_, _ = stdcopy.NewStdWriter(w, stdcopy.Stdout).Write([]byte("out line\n"))
+				_, _ = stdcopy.NewStdWriter(w, stdcopy.Stderr).Write([]byte("err line\n"))
+			},
+		},
+		{
+			testName: "Raw tty stream",
+			tty:      true,
+			write: func(w http.ResponseWriter) {
+				_, _ = w.Write([]byte("out line\nerr line\n"))
+			},
+		},
+	}
+
+	for _, s := range scenarios {
+		s := s
+		t.Run(s.testName, func(t *testing.T) {
+			queries := make(chan string, 1)
+			dockerCommand := newFakeDaemonCommand(t, func(w http.ResponseWriter, r *http.Request) {
+				if !strings.HasSuffix(r.URL.Path, "/containers/123/logs") {
+					http.NotFound(w, r)
+					return
+				}
+				queries <- r.URL.RawQuery
+				s.write(w)
+				w.(http.Flusher).Flush()
+				// we're following, so the stream stays open until we're
+				// cancelled
+				<-r.Context().Done()
+			})
+
+			container := &Container{ID: "123", Client: dockerCommand.Client}
+			container.Details.Image = "alpine"
+			container.Details.Config.Tty = s.tty
+
+			ctx, cancel := context.WithCancel(context.Background())
+			output := &syncBuffer{}
+			done := make(chan error, 1)
+			go func() { done <- container.StreamLogs(ctx, "5m", output) }()
+
+			query := <-queries
+			assert.Contains(t, query, "follow=1")
+			assert.Contains(t, query, "since=")
+
+			deadline := time.Now().Add(5 * time.Second)
+			for output.String() != "out line\nerr line\n" && time.Now().Before(deadline) {
+				time.Sleep(10 * time.Millisecond)
+			}
+			assert.Equal(t, "out line\nerr line\n", output.String())
+
+			cancel()
+			select {
+			case err := <-done:
+				assert.NoError(t, err)
+			case <-time.After(5 * time.Second):
+				t.Fatal("expected cancelling to stop the stream")
+			}
+		})
+	}
+}
+
+// fakeExecDaemon answers the exec endpoints as a daemon would for a container
+// with the given shells. missingFailsStart is how newer daemons report a
+// missing executable; older ones start it and exit with 127.
+func fakeExecDaemon(t *testing.T, shells map[string]bool, missingFailsStart bool) http.HandlerFunc {
+	var mutex sync.Mutex
+	execs := map[string]string{}
+	polls := map[string]int{}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+		switch {
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/containers/123/exec"):
+			config := types.ExecConfig{}
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&config))
+			id := fmt.Sprintf("exec%d", len(execs))
+			execs[id] = config.Cmd[0]
+			_ = json.NewEncoder(w).Encode(types.IDResponse{ID: id})
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/start"):
+			shell := execs[parts[len(parts)-2]]
+			if !shells[shell] && missingFailsStart {
+				http.Error(w, `{"message": "exec: \"`+shell+`\": stat `+shell+`: no such file or directory"}`, http.StatusBadRequest)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/json"):
+			id := parts[len(parts)-2]
+			polls[id]++
+			inspect := types.ContainerExecInspect{ExecID: id, Running: polls[id] == 1}
+			if !shells[execs[id]] {
+				inspect.ExitCode = 127
+			}
+			_ = json.NewEncoder(w).Encode(inspect)
+		default:
+			http.NotFound(w, r)
+		}
+	}
+}
+
+func TestContainerFindShell(t *testing.T) {
+	type scenario struct {
+		testName            string
+		shells              map[string]bool
+		missingFailsStart   bool
+		expectedShell       string
+		expectedErrorSubstr string
+	}
+
+	scenarios := []scenario{
+		{
+			testName:      "Bash is preferred",
+			shells:        map[string]bool{"/bin/bash": true, "/bin/sh": true},
+			expectedShell: "/bin/bash",
+		},
+		{
+			testName:          "Falls back to sh when starting bash fails",
+			shells:            map[string]bool{"/bin/sh": true},
+			missingFailsStart: true,
+			expectedShell:     "/bin/sh",
+		},
+		{
+			testName:      "Falls back to sh when bash exits with 127",
+			shells:        map[string]bool{"/bin/sh": true},
+			expectedShell: "/bin/sh",
+		},
+		{
+			testName:            "No shell at all",
+			shells:              map[string]bool{},
+			missingFailsStart:   true,
+			expectedErrorSubstr: "Could not find a shell in this container (tried /bin/bash, /bin/sh)",
+		},
+	}
+
+	for _, s := range scenarios {
+		s := s
+		t.Run(s.testName, func(t *testing.T) {
+			dockerCommand := newFakeDaemonCommand(t, fakeExecDaemon(t, s.shells, s.missingFailsStart))
+
+			log := NewDummyLog()
+			container := &Container{ID: "123", Name: "web", Client: dockerCommand.Client, Log: log, Tr: i18n.NewTranslationSet(log, "en")}
+
+			shell, err := container.findShell(context.Background(), shellCandidates)
+			if s.expectedErrorSubstr != "" {
+				if assert.Error(t, err) {
+					assert.Contains(t, err.Error(), s.expectedErrorSubstr)
+				}
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, s.expectedShell, shell)
+		})
+	}
+}
+
+func TestContainerRestartPolicy(t *testing.T) {
+	type scenario struct {
+		testName          string
+		name              string
+		maximumRetryCount int
+		expected          string
+	}
+
+	scenarios := []scenario{
+		{testName: "Not set", name: "", expected: "no"},
+		{testName: "Always", name: "always", expected: "always"},
+		{testName: "On failure", name: "on-failure", expected: "on-failure"},
+		{testName: "On failure with retries", name: "on-failure", maximumRetryCount: 3, expected: "on-failure:3"},
+	}
+
+	for _, s := range scenarios {
+		s := s
+		t.Run(s.testName, func(t *testing.T) {
+			container := &Container{}
+			container.Details.HostConfig.RestartPolicy.Name = s.name
+			container.Details.HostConfig.RestartPolicy.MaximumRetryCount = s.maximumRetryCount
+			assert.Equal(t, s.expected, container.RestartPolicy())
+		})
+	}
+}
+
+func TestContainerHealth(t *testing.T) {
+	type scenario struct {
+		testName          string
+		state             string
+		health            string
+		expectedUnhealthy bool
+		expectedColor     []string
+	}
+
+	scenarios := []scenario{
+		{testName: "No health check", state: "running", expectedColor: []string{"green"}},
+		{testName: "Healthy", state: "running", health: "healthy", expectedColor: []string{"green"}},
+		{testName: "Unhealthy", state: "running", health: "unhealthy", expectedUnhealthy: true, expectedColor: []string{"red"}},
+		{testName: "Unhealthy but stopped", state: "exited", health: "unhealthy", expectedColor: []string{"yellow"}},
+	}
+
+	for _, s := range scenarios {
+		s := s
+		t.Run(s.testName, func(t *testing.T) {
+			container := &Container{}
+			container.Container.State = s.state
+			container.Details.State.Health.Status = s.health
+			assert.Equal(t, s.expectedUnhealthy, container.Unhealthy())
+			assert.Equal(t, s.expectedColor, container.GetColor())
+		})
+	}
+}
+
+func TestContainerRecentHealthChecks(t *testing.T) {
+	container := &Container{}
+	for i := 0; i < 4; i++ {
+		container.Details.State.Health.Log = append(container.Details.State.Health.Log, &types.HealthcheckResult{ExitCode: i})
+	}
+
+	checks := container.RecentHealthChecks(2)
+	assert.Len(t, checks, 2)
+	assert.Equal(t, 3, checks[0].ExitCode)
+	assert.Equal(t, 2, checks[1].ExitCode)
+	assert.Len(t, container.RecentHealthChecks(10), 4)
+}
+
+func TestContainerUpdateRestartPolicy(t *testing.T) {
+	bodies := make(chan map[string]interface{}, 1)
+	dockerCommand := newFakeDaemonCommand(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || !strings.HasSuffix(r.URL.Path, "/containers/123/update") {
+			http.NotFound(w, r)
+			return
+		}
+		body := map[string]interface{}{}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		bodies <- body
+		_, _ = w.Write([]byte(`{"Warnings": []}`))
+	})
+	dockerCommand.containerListCache = newContainerListCache(0)
+
+	container := &Container{ID: "123", Name: "web", Client: dockerCommand.Client, Log: NewDummyLog(), DockerCommand: dockerCommand}
+	assert.NoError(t, container.UpdateRestartPolicy("unless-stopped"))
+	assert.Equal(t, map[string]interface{}{"Name": "unless-stopped", "MaximumRetryCount": float64(0)}, (<-bodies)["RestartPolicy"])
+}
+
+func TestContainerStopAndKill(t *testing.T) {
+	requests := make(chan string, 1)
+	dockerCommand := newFakeDaemonCommand(t, func(w http.ResponseWriter, r *http.Request) {
+		requests <- r.URL.Path[strings.Index(r.URL.Path, "/containers/"):] + "?" + r.URL.RawQuery
+		w.WriteHeader(http.StatusNoContent)
+	})
+	dockerCommand.containerListCache = newContainerListCache(0)
+
+	container := &Container{ID: "123", Name: "web", Client: dockerCommand.Client, Log: NewDummyLog(), DockerCommand: dockerCommand}
+
+	assert.NoError(t, container.Stop())
+	assert.Equal(t, "/containers/123/stop?", <-requests)
+
+	assert.NoError(t, container.StopWithTimeout(30*time.Second))
+	assert.Equal(t, "/containers/123/stop?t=30", <-requests)
+
+	assert.NoError(t, container.Kill())
+	assert.Equal(t, "/containers/123/kill?signal=KILL", <-requests)
+}
+
+func TestParseStopTimeout(t *testing.T) {
+	type scenario struct {
+		value    string
+		expected time.Duration
+		isError  bool
+	}
+
+	scenarios := []scenario{
+		{value: "30", expected: 30 * time.Second},
+		{value: " 1m30s ", expected: 90 * time.Second},
+		{value: "0", expected: 0},
+		{value: "-5", isError: true},
+		{value: "soon", isError: true},
+	}
+
+	for _, s := range scenarios {
+		s := s
+		t.Run(s.value, func(t *testing.T) {
+			timeout, err := ParseStopTimeout(s.value)
+			if s.isError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, s.expected, timeout)
+		})
+	}
+}
+
+func TestFormatUptime(t *testing.T) {
+	type scenario struct {
+		duration time.Duration
+		expected string
+	}
+
+	scenarios := []scenario{
+		{duration: 0, expected: "0s"},
+		{duration: 30*time.Second + 500*time.Millisecond, expected: "30s"},
+		{duration: 5*time.Minute + 59*time.Second, expected: "5m"},
+		{duration: 4*time.Hour + 12*time.Minute + 3*time.Second, expected: "4h12m"},
+		{duration: 3 * time.Hour, expected: "3h"},
+		{duration: 2*24*time.Hour + 3*time.Hour + 30*time.Minute, expected: "2d3h"},
+		{duration: 400 * 24 * time.Hour, expected: "400d"},
+	}
+
+	for _, s := range scenarios {
+		s := s
+		t.Run(s.expected, func(t *testing.T) {
+			assert.Equal(t, s.expected, FormatUptime(s.duration))
+		})
+	}
+}
+
+func TestContainerUptime(t *testing.T) {
+	now := time.Date(2020, 1, 3, 12, 0, 0, 0, time.UTC)
+
+	container := &Container{}
+	container.Container.State = "running"
+	assert.Equal(t, time.Duration(0), container.Uptime(now), "details not loaded yet")
+
+	container.Details.Image = "sha256:abc"
+	container.Details.State.StartedAt = now.Add(-26 * time.Hour)
+	assert.Equal(t, 26*time.Hour, container.Uptime(now))
+
+	container.Container.State = "exited"
+	assert.Equal(t, time.Duration(0), container.Uptime(now))
+}
+
+func TestContainerGetDisplayRestartCount(t *testing.T) {
+	type scenario struct {
+		restartCount int
+		expected     string
+	}
+
+	scenarios := []scenario{
+		{restartCount: 0, expected: ""},
+		{restartCount: 1, expected: "1 restart"},
+		{restartCount: 7, expected: "7 restarts"},
+	}
+
+	for _, s := range scenarios {
+		s := s
+		t.Run(fmt.Sprint(s.restartCount), func(t *testing.T) {
+			container := &Container{Tr: i18n.NewTranslationSet(NewDummyLog(), "en")}
+			container.Details.RestartCount = s.restartCount
+			assert.Equal(t, s.expected, utils.Decolorise(container.GetDisplayRestartCount()))
+		})
+	}
+}
+
+func TestContainerRename(t *testing.T) {
+	renamedTo := ""
+	dockerCommand := newFakeDaemonCommand(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || !strings.HasSuffix(r.URL.Path, "/containers/123/rename") {
+			http.NotFound(w, r)
+			return
+		}
+		name := r.URL.Query().Get("name")
+		if name == "db" {
+			http.Error(w, `Conflict. The container name "/db" is already in use by container "456".`, http.StatusConflict)
+			return
+		}
+		renamedTo = name
+		w.WriteHeader(http.StatusNoContent)
+	})
+	dockerCommand.containerListCache = newContainerListCache(0)
+
+	type scenario struct {
+		testName string
+		name     string
+		expected string
+		err      string
+	}
+
+	scenarios := []scenario{
+		{testName: "A new name", name: "web.old-1", expected: "web.old-1"},
+		{testName: "The same name", name: "web", expected: ""},
+		{testName: "A name in use", name: "db", err: "there's already a container called db"},
+		{testName: "A name with a space", name: "my web", err: `"my web" isn't a valid name`},
+		{testName: "A name starting with a dash", name: "-web", err: `"-web" isn't a valid name`},
+		{testName: "A name that's too short", name: "w", err: `"w" isn't a valid name`},
+	}
+
+	for _, s := range scenarios {
+		s := s
+		t.Run(s.testName, func(t *testing.T) {
+			renamedTo = ""
+			container := &Container{ID: "123", Name: "web", Client: dockerCommand.Client, Log: NewDummyLog(), DockerCommand: dockerCommand}
+			err := container.Rename(s.name)
+			if s.err != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), s.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, s.expected, renamedTo)
+		})
+	}
+}
@@ -0,0 +1,168 @@
+package commands
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
+)
+
+// the kinds of thing disk usage is broken down into
+const (
+	DiskUsageImages     = "images"
+	DiskUsageContainers = "containers"
+	DiskUsageVolumes    = "volumes"
+	DiskUsageBuildCache = "build cache"
+)
+
+// buildCacheAPIVersion is the first API version with build cache pruning
+const buildCacheAPIVersion = "1.31"
+
+// DiskUsageCategory is how much space one kind of thing takes up, like a row
+// of `docker system df`
+type DiskUsageCategory struct {
+	Kind string
+	// Count is how many there are and Active how many are in use
+	Count  int
+	Active int
+	Size   int64
+	// Reclaimable is how much space pruning would get back
+	Reclaimable int64
+	// Items are the things themselves, biggest first
+	Items []DiskUsageItem
+}
+
+// DiskUsageItem is one thing taking up space, e.g. an image
+type DiskUsageItem struct {
+	Name  string
+	Size  int64
+	InUse bool
+}
+
+// GetDiskUsage asks the daemon how much space images, containers, volumes and
+// the build cache take up. The daemon has to add it all up, which can take a
+// while, so this is for calling when asked rather than on every refresh.
+func (c *DockerCommand) GetDiskUsage(ctx context.Context) ([]DiskUsageCategory, error) {
+	usage, err := c.Client.DiskUsage(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return summariseDiskUsage(usage), nil
+}
+
+// summariseDiskUsage works out the totals the way `docker system df` does
+func summariseDiskUsage(usage types.DiskUsage) []DiskUsageCategory {
+	images := DiskUsageCategory{Kind: DiskUsageImages, Size: usage.LayersSize}
+	var usedByImages int64
+	for _, image := range usage.Images {
+		images.Count++
+		name := ShortID(image.ID)
+		if len(image.RepoTags) > 0 && image.RepoTags[0] != "<none>:<none>" {
+			name = image.RepoTags[0]
+		}
+		inUse := image.Containers > 0
+		if inUse {
+			images.Active++
+			// layers shared with other images would still be needed by them
+			if image.SharedSize != -1 {
+				usedByImages += image.Size - image.SharedSize
+			}
+		}
+		images.Items = append(images.Items, DiskUsageItem{Name: name, Size: image.Size, InUse: inUse})
+	}
+	images.Reclaimable = images.Size - usedByImages
+	if images.Reclaimable < 0 {
+		images.Reclaimable = 0
+	}
+
+	containers := DiskUsageCategory{Kind: DiskUsageContainers}
+	for _, container := range usage.Containers {
+		containers.Count++
+		name := ShortID(container.ID)
+		if len(container.Names) > 0 {
+			name = strings.TrimLeft(container.Names[0], "/")
+		}
+		inUse := container.State == "running" || container.State == "paused"
+		containers.Size += container.SizeRw
+		if inUse {
+			containers.Active++
+		} else {
+			containers.Reclaimable += container.SizeRw
+		}
+		containers.Items = append(containers.Items, DiskUsageItem{Name: name, Size: container.SizeRw, InUse: inUse})
+	}
+
+	volumes := DiskUsageCategory{Kind: DiskUsageVolumes}
+	for _, volume := range usage.Volumes {
+		volumes.Count++
+		// -1 means the daemon couldn't tell
+		var size int64
+		inUse := false
+		if volume.UsageData != nil {
+			if volume.UsageData.Size != -1 {
+				size = volume.UsageData.Size
+			}
+			inUse = volume.UsageData.RefCount > 0
+		}
+		volumes.Size += size
+		if inUse {
+			volumes.Active++
+		} else {
+			volumes.Reclaimable += size
+		}
+		volumes.Items = append(volumes.Items, DiskUsageItem{Name: volume.Name, Size: size, InUse: inUse})
+	}
+
+	buildCache := DiskUsageCategory{Kind: DiskUsageBuildCache}
+	for _, record := range usage.BuildCache {
+		buildCache.Count++
+		name := record.Description
+		if name == "" {
+			name = ShortID(record.ID)
+		}
+		buildCache.Size += record.Size
+		if record.InUse {
+			buildCache.Active++
+		} else if !record.Shared {
+			buildCache.Reclaimable += record.Size
+		}
+		buildCache.Items = append(buildCache.Items, DiskUsageItem{Name: name, Size: record.Size, InUse: record.InUse})
+	}
+
+	categories := []DiskUsageCategory{images, containers, volumes, buildCache}
+	for _, category := range categories {
+		items := category.Items
+		sort.SliceStable(items, func(i, j int) bool { return items[i].Size > items[j].Size })
+	}
+	return categories
+}
+
+// ShortID is how `docker ps` and friends show an ID
+func ShortID(id string) string {
+	id = strings.TrimPrefix(id, "sha256:")
+	if len(id) > 12 {
+		return id[:12]
+	}
+	return id
+}
+
+// PruneBuildCache prunes the build cache that nothing is using, like docker
+// builder prune. That needs a newer API version than we otherwise ask for, so
+// we make the request with a client that asks for it, sharing our connection.
+func (c *DockerCommand) PruneBuildCache() (PruneReport, error) {
+	cli, err := client.NewClientWithOpts(
+		client.WithHTTPClient(c.Client.HTTPClient()),
+		client.WithHost(c.Client.DaemonHost()),
+		client.WithVersion(buildCacheAPIVersion),
+	)
+	if err != nil {
+		return PruneReport{}, err
+	}
+	report, err := cli.BuildCachePrune(context.Background(), types.BuildCachePruneOptions{})
+	if err != nil {
+		return PruneReport{}, err
+	}
+	return PruneReport{ItemsDeleted: len(report.CachesDeleted), SpaceReclaimed: report.SpaceReclaimed}, nil
+}
@@ -0,0 +1,66 @@
+package commands
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSummariseDiskUsage(t *testing.T) {
+	usage := types.DiskUsage{
+		LayersSize: 1000,
+		Images: []*types.ImageSummary{
+			{ID: "sha256:1111111111111111", RepoTags: []string{"alpine:latest"}, Size: 300, SharedSize: 100, Containers: 1},
+			{ID: "sha256:2222222222222222", RepoTags: []string{"<none>:<none>"}, Size: 500, SharedSize: 100, Containers: 0},
+		},
+		Containers: []*types.Container{
+			{ID: "abc", Names: []string{"/web"}, State: "running", SizeRw: 10},
+			{ID: "def", Names: []string{"/old"}, State: "exited", SizeRw: 40},
+		},
+		Volumes: []*types.Volume{
+			{Name: "pgdata", UsageData: &types.VolumeUsageData{Size: 200, RefCount: 1}},
+			{Name: "scratch", UsageData: &types.VolumeUsageData{Size: 50, RefCount: 0}},
+			{Name: "unknown", UsageData: &types.VolumeUsageData{Size: -1, RefCount: 0}},
+		},
+		BuildCache: []*types.BuildCache{
+			{ID: "a", Description: "mount / from exec /bin/sh", Size: 70, InUse: true},
+			{ID: "b", Size: 30, Shared: true},
+			{ID: "c", Size: 20},
+		},
+	}
+
+	assert.EqualValues(t, []DiskUsageCategory{
+		{
+			Kind: DiskUsageImages, Count: 2, Active: 1, Size: 1000, Reclaimable: 800,
+			Items: []DiskUsageItem{{Name: "222222222222", Size: 500}, {Name: "alpine:latest", Size: 300, InUse: true}},
+		},
+		{
+			Kind: DiskUsageContainers, Count: 2, Active: 1, Size: 50, Reclaimable: 40,
+			Items: []DiskUsageItem{{Name: "old", Size: 40}, {Name: "web", Size: 10, InUse: true}},
+		},
+		{
+			Kind: DiskUsageVolumes, Count: 3, Active: 1, Size: 250, Reclaimable: 50,
+			Items: []DiskUsageItem{{Name: "pgdata", Size: 200, InUse: true}, {Name: "scratch", Size: 50}, {Name: "unknown", Size: 0}},
+		},
+		{
+			Kind: DiskUsageBuildCache, Count: 3, Active: 1, Size: 120, Reclaimable: 20,
+			Items: []DiskUsageItem{{Name: "mount / from exec /bin/sh", Size: 70, InUse: true}, {Name: "b", Size: 30}, {Name: "c", Size: 20}},
+		},
+	}, summariseDiskUsage(usage))
+}
+
+func TestDockerCommandPruneBuildCache(t *testing.T) {
+	paths := make(chan string, 1)
+	dockerCommand := newFakeDaemonCommand(t, func(w http.ResponseWriter, r *http.Request) {
+		paths <- r.URL.Path
+		_, _ = w.Write([]byte(`{"CachesDeleted": ["a", "b"], "SpaceReclaimed": 1234}`))
+	})
+
+	report, err := dockerCommand.PruneBuildCache()
+	assert.NoError(t, err)
+	assert.Equal(t, PruneReport{ItemsDeleted: 2, SpaceReclaimed: 1234}, report)
+	// build cache pruning is newer than the API version we usually ask for
+	assert.Equal(t, "/v1.31/build/prune", <-paths)
+}
@@ -4,9 +4,11 @@ import (
 	"bufio"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	ogLog "log"
+	"os"
 	"os/exec"
 	"sort"
 	"strings"
@@ -15,6 +17,7 @@ import (
 
 	"github.com/acarl005/stripansi"
 	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
 	"github.com/docker/docker/client"
 	"github.com/imdario/mergo"
 	"github.com/jesseduffield/lazydocker/pkg/commands/ssh"
@@ -22,6 +25,7 @@ import (
 	"github.com/jesseduffield/lazydocker/pkg/i18n"
 	"github.com/jesseduffield/lazydocker/pkg/utils"
 	"github.com/sirupsen/logrus"
+	"golang.org/x/crypto/ssh/terminal"
 )
 
 const (
@@ -46,7 +50,66 @@ type DockerCommand struct {
 	DisplayContainers []*Container
 	Images            []*Image
 	Volumes           []*Volume
+	Networks          []*Network
 	Closers           []io.Closer
+
+	// TotalDisplayContainers is how many containers we'd display if it weren't
+	// for the container filter
+	TotalDisplayContainers int
+
+	// containerListCache saves us asking the daemon for the container list on
+	// every refresh
+	containerListCache *containerListCache
+	// containerDetailsCache saves us inspecting every container on every
+	// refresh
+	containerDetailsCache *containerDetailsCache
+
+	// containerFilter narrows down the containers we display. It's guarded by
+	// ContainerMutex.
+	containerFilter filters.Args
+
+	// containerSort and imageSort are how the containers and images lists are
+	// sorted. They're guarded by ContainerMutex.
+	containerSort SortOrder
+	imageSort     SortOrder
+	// liveSort stops containers sorted by their stats shuffling every tick
+	liveSort liveSort
+
+	// ContextName is the docker context we're connected to
+	ContextName string
+	// tunnel is the ssh tunnel to the daemon, if we're connected over one
+	tunnel ssh.Tunnel
+	// startupEnv is how dockerEnvVars were set when we started, which is what
+	// switching back to the default context restores
+	startupEnv map[string]string
+
+	// statsCmd is the `docker stats` process feeding MonitorCLIContainerStats
+	statsCmd   *exec.Cmd
+	statsMutex sync.Mutex
+
+	// portForwards are the ports we've forwarded through the tunnel, keyed
+	// by the address they go to on the remote host
+	portForwards     map[string]*ssh.PortForward
+	portForwardMutex sync.Mutex
+
+	// events is the feed of the daemon's events kept by MonitorEvents
+	events *eventFeed
+
+	// connectionMutex guards what follows, which is how our connection to
+	// the daemon is doing
+	connectionMutex sync.Mutex
+	// dockerContext is the docker context we're connected to, for
+	// reconnecting to it
+	dockerContext ssh.DockerContext
+	connection    ConnectionStatus
+	// connectionID goes up every time we connect, so that we can tell a ping
+	// or tunnel from an old connection from one from the current one
+	connectionID int
+	failedPings  int
+	// tunnelDown is whether the ssh tunnel has gone down for good, and
+	// tunnelErr why
+	tunnelDown bool
+	tunnelErr  error
 }
 
 var _ io.Closer = &DockerCommand{}
@@ -54,6 +117,8 @@ var _ io.Closer = &DockerCommand{}
 // LimitedDockerCommand is a stripped-down DockerCommand with just the methods the container/service/image might need
 type LimitedDockerCommand interface {
 	NewCommandObject(CommandObject) CommandObject
+	InvalidateContainerCache()
+	PullImage(ctx context.Context, ref string, onProgress func(*ImagePullProgress)) error
 }
 
 // CommandObject is what we pass to our template resolvers when we are running a custom command. We do not guarantee that all fields will be populated: just the ones that make sense for the current context
@@ -63,6 +128,7 @@ type CommandObject struct {
 	Container     *Container
 	Image         *Image
 	Volume        *Volume
+	Project       *ComposeProject
 }
 
 // NewCommandObject takes a command object and returns a default command object with the passed command object merged in
@@ -72,13 +138,58 @@ func (c *DockerCommand) NewCommandObject(obj CommandObject) CommandObject {
 	return defaultObj
 }
 
+// sshOptions configures the ssh handler from the user config. prompt asks
+// for the password if password auth is on.
+func sshOptions(sshConfig config.SSHConfig, prompt ssh.PasswordPrompt) []ssh.Option {
+	opts := []ssh.Option{
+		ssh.WithSSHOptions(sshConfig.Options...),
+		ssh.WithSSHBinary(sshConfig.Binary),
+		ssh.WithKeepAlive(sshConfig.KeepAliveInterval, sshConfig.KeepAliveCountMax),
+		ssh.WithLocalBind(sshConfig.LocalBind),
+	}
+	if sshConfig.IdentitiesOnly {
+		opts = append(opts, ssh.WithIdentitiesOnly())
+	}
+	if sshConfig.PasswordAuth {
+		// the ssh binary's run in batch mode, so it can't ask for one
+		opts = append(opts, ssh.WithBackend(ssh.BackendNative), ssh.WithPasswordPrompt(prompt))
+	}
+	return opts
+}
+
+// terminalPasswordPrompt asks for the ssh password on the terminal, without
+// echoing it, for when we connect at startup before the gui's up
+func terminalPasswordPrompt(user, host string) ([]byte, error) {
+	fd := int(os.Stdin.Fd())
+	if !terminal.IsTerminal(fd) {
+		return nil, errors.New("can't ask for the ssh password as stdin isn't a terminal")
+	}
+	fmt.Fprintf(os.Stderr, "%s@%s's password: ", user, host)
+	defer fmt.Fprintln(os.Stderr)
+	return terminal.ReadPassword(fd)
+}
+
+// PreviewDockerConnection describes how NewDockerCommand would connect to
+// docker given the current DOCKER_HOST, without connecting
+func PreviewDockerConnection(config *config.AppConfig) (ssh.ConnectionPlan, error) {
+	return ssh.NewSSHHandler(sshOptions(config.UserConfig.SSH, nil)...).PreviewConnection(os.Getenv("DOCKER_HOST"))
+}
+
 // NewDockerCommand it runs docker commands
-func NewDockerCommand(log *logrus.Entry, osCommand *OSCommand, tr *i18n.TranslationSet, config *config.AppConfig, errorChan chan error) (*DockerCommand, error) {
-	tunnelCloser, err := ssh.NewSSHHandler().HandleSSHDockerHost()
+func NewDockerCommand(ctx context.Context, log *logrus.Entry, osCommand *OSCommand, tr *i18n.TranslationSet, config *config.AppConfig, errorChan chan error) (*DockerCommand, error) {
+	sshHandler := ssh.NewSSHHandler(append(sshOptions(config.UserConfig.SSH, terminalPasswordPrompt), ssh.WithLogger(log))...)
+	// before DOCKER_HOST is pointed at a tunnel or the current context's host
+	startupEnv := getDockerEnv()
+	contextName := sshHandler.ActiveDockerContextName()
+	tunnelCloser, err := sshHandler.HandleSSHDockerHost(ctx)
 	if err != nil {
 		ogLog.Fatal(err)
 	}
 
+	if err := sshHandler.CheckDockerTLS(); err != nil {
+		ogLog.Fatal(err)
+	}
+
 	cli, err := client.NewClientWithOpts(client.FromEnv, client.WithVersion(APIVersion))
 	if err != nil {
 		ogLog.Fatal(err)
@@ -93,8 +204,15 @@ func NewDockerCommand(log *logrus.Entry, osCommand *OSCommand, tr *i18n.Translat
 		ErrorChan:              errorChan,
 		ShowExited:             true,
 		InDockerComposeProject: true,
-		Closers:                []io.Closer{tunnelCloser},
+		Closers:                []io.Closer{},
+		ContextName:            contextName,
+		tunnel:                 tunnelCloser,
+		startupEnv:             startupEnv,
+		containerListCache:     newContainerListCache(config.UserConfig.Update.ContainerCacheTTL),
+		containerDetailsCache:  newContainerDetailsCache(config.UserConfig.Update.ContainerDetailsTTL),
+		events:                 newEventFeed(),
 	}
+	dockerCommand.connected(dockerCommand.findDockerContext(contextName), tunnelCloser)
 
 	command := utils.ApplyTemplate(
 		config.UserConfig.CommandTemplates.CheckDockerComposeConfig,
@@ -117,15 +235,27 @@ func NewDockerCommand(log *logrus.Entry, osCommand *OSCommand, tr *i18n.Translat
 	return dockerCommand, nil
 }
 
+// InvalidateContainerCache makes the next refresh fetch the containers afresh.
+// Call it after doing anything that changes a container.
+func (c *DockerCommand) InvalidateContainerCache() {
+	c.containerListCache.invalidate()
+	c.containerDetailsCache.invalidate()
+}
+
 func (c *DockerCommand) Close() error {
-	return utils.CloseMany(c.Closers)
+	c.stopCLIContainerStats()
+	c.stopEvents()
+	closers := c.Closers
+	if c.tunnel != nil {
+		closers = append(closers, c.tunnel)
+	}
+	return utils.CloseMany(closers)
 }
 
 // MonitorContainerStats is a function
 func (c *DockerCommand) MonitorContainerStats() {
 	// TODO: pass in a stop channel to these so we don't restart every time we come back from a subprocess
 	go c.MonitorCLIContainerStats()
-	go c.MonitorClientContainerStats()
 }
 
 // MonitorCLIContainerStats monitors a stream of container stats and updates the containers as each new stats object is received
@@ -140,6 +270,9 @@ func (c *DockerCommand) MonitorCLIContainerStats() {
 	}
 
 	cmd.Start()
+	c.statsMutex.Lock()
+	c.statsCmd = cmd
+	c.statsMutex.Unlock()
 
 	scanner := bufio.NewScanner(r)
 	scanner.Split(bufio.ScanLines)
@@ -148,6 +281,9 @@ func (c *DockerCommand) MonitorCLIContainerStats() {
 		// need to strip ANSI codes because uses escape sequences to clear the screen with each refresh
 		cleanString := stripansi.Strip(scanner.Text())
 		if err := json.Unmarshal([]byte(cleanString), &stats); err != nil {
+			if c.statsStopped(cmd) {
+				return
+			}
 			c.ErrorChan <- err
 			return
 		}
@@ -163,44 +299,61 @@ func (c *DockerCommand) MonitorCLIContainerStats() {
 	cmd.Wait()
 }
 
-// MonitorClientContainerStats is a function
-func (c *DockerCommand) MonitorClientContainerStats() {
-	// periodically loop through running containers and see if we need to create a monitor goroutine for any
-	// every second we check if we need to spawn a new goroutine
-	ticker := time.NewTicker(time.Second)
-	defer ticker.Stop()
-	for range ticker.C {
-		for _, container := range c.Containers {
-			if !container.MonitoringStats {
-				go c.createClientStatMonitor(container)
-			}
-		}
+// stopCLIContainerStats kills the `docker stats` process, e.g. because we've
+// switched to another daemon
+func (c *DockerCommand) stopCLIContainerStats() {
+	c.statsMutex.Lock()
+	defer c.statsMutex.Unlock()
+	if c.statsCmd != nil && c.statsCmd.Process != nil {
+		_ = c.statsCmd.Process.Kill()
 	}
+	c.statsCmd = nil
+}
+
+// statsStopped tells us whether cmd has been stopped with stopCLIContainerStats
+func (c *DockerCommand) statsStopped(cmd *exec.Cmd) bool {
+	c.statsMutex.Lock()
+	defer c.statsMutex.Unlock()
+	return c.statsCmd != cmd
 }
 
-func (c *DockerCommand) createClientStatMonitor(container *Container) {
-	container.MonitoringStats = true
-	stream, err := c.Client.ContainerStats(context.Background(), container.ID, true)
+// StreamContainerStats records the container's stats into its stat history as
+// docker sends them, about once a second, until ctx is cancelled or the
+// container stops. We only do this for the container being looked at, so
+// that we're not holding a stream open for every container, which over an ssh
+// tunnel adds up.
+func (c *DockerCommand) StreamContainerStats(ctx context.Context, container *Container) error {
+	stream, err := c.Client.ContainerStats(ctx, container.ID, true)
 	if err != nil {
-		c.ErrorChan <- err
-		return
+		return err
 	}
-
 	defer stream.Body.Close()
 
-	scanner := bufio.NewScanner(stream.Body)
-	for scanner.Scan() {
-		data := scanner.Bytes()
-		var stats ContainerStats
-		json.Unmarshal(data, &stats)
-
-		recordedStats := RecordedStats{
-			ClientStats: stats,
-			DerivedStats: DerivedStats{
-				CPUPercentage:    stats.CalculateContainerCPUPercentage(),
-				MemoryPercentage: stats.CalculateContainerMemoryUsage(),
-			},
-			RecordedAt: time.Now(),
+	// as with logs, cancelling doesn't interrupt a blocked read, so we close
+	// the stream ourselves
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			stream.Body.Close()
+		case <-done:
+		}
+	}()
+
+	decoder := json.NewDecoder(stream.Body)
+	for {
+		var data json.RawMessage
+		if err := decoder.Decode(&data); err != nil {
+			if err == io.EOF || ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+
+		recordedStats, err := newRecordedStats(data, time.Now())
+		if err != nil {
+			return err
 		}
 
 		c.ContainerMutex.Lock()
@@ -208,8 +361,6 @@ func (c *DockerCommand) createClientStatMonitor(container *Container) {
 		container.EraseOldHistory()
 		c.ContainerMutex.Unlock()
 	}
-
-	container.MonitoringStats = false
 }
 
 // RefreshContainersAndServices returns a slice of docker containers
@@ -255,10 +406,19 @@ func (c *DockerCommand) RefreshContainersAndServices() error {
 		return services[i].Name < services[j].Name
 	})
 
+	if !c.hasStatusFilter() {
+		displayContainers = c.filterOutExited(displayContainers)
+	}
+	totalDisplayContainers := len(displayContainers)
+	displayContainers, err = c.filterContainers(displayContainers)
+	if err != nil {
+		return err
+	}
+
 	c.Containers = containers
 	c.Services = services
-	c.DisplayContainers = c.filterOutExited(displayContainers)
-	c.DisplayContainers = c.sortedContainers(c.DisplayContainers)
+	c.TotalDisplayContainers = totalDisplayContainers
+	c.DisplayContainers = c.sortedContainers(displayContainers)
 
 	return nil
 }
@@ -290,23 +450,18 @@ func (c *DockerCommand) filterOutExited(containers []*Container) []*Container {
 	return toReturn
 }
 
-// sortedContainers returns containers sorted by state if c.SortContainersByState is true (follows 1- running, 2- exited, 3- created)
-// and sorted by name if c.SortContainersByState is false
+// sortedContainers returns containers sorted how the user asked. Until they've
+// asked, containers are sorted by state (follows 1- running, 2- exited, 3- created)
+// and then by name, unless Gui.LegacySortContainers is true
 func (c *DockerCommand) sortedContainers(containers []*Container) []*Container {
-	if !c.Config.UserConfig.Gui.LegacySortContainers {
-		states := map[string]int{
-			"running": 1,
-			"exited":  2,
-			"created": 3,
-		}
-		sort.Slice(containers, func(i, j int) bool {
-			stateLeft := states[containers[i].Container.State]
-			stateRight := states[containers[j].Container.State]
-			if stateLeft == stateRight {
-				return containers[i].Name < containers[j].Name
-			}
-			return states[containers[i].Container.State] < states[containers[j].Container.State]
-		})
+	order := c.ContainerSort()
+	switch {
+	case order.Field.Live():
+		c.liveSort.sort(containers, order, c.containerUsage(containers, order.Field), time.Now())
+	case order.Field != "":
+		sortContainers(containers, order, nil)
+	case !c.Config.UserConfig.Gui.LegacySortContainers:
+		sortContainers(containers, SortOrder{Field: SortByStatus}, nil)
 	}
 	return containers
 }
@@ -334,7 +489,7 @@ func (c *DockerCommand) GetContainers() ([]*Container, error) {
 
 	existingContainers := c.Containers
 
-	containers, err := c.Client.ContainerList(context.Background(), types.ContainerListOptions{All: true})
+	containers, err := c.containerListCache.get(types.ContainerListOptions{All: true}, c.listContainers)
 	if err != nil {
 		return nil, err
 	}
@@ -383,6 +538,10 @@ func (c *DockerCommand) GetContainers() ([]*Container, error) {
 	return ownContainers, nil
 }
 
+func (c *DockerCommand) listContainers(options types.ContainerListOptions) ([]types.Container, error) {
+	return c.Client.ContainerList(context.Background(), options)
+}
+
 // GetServices gets services
 func (c *DockerCommand) GetServices() ([]*Service, error) {
 	if !c.InDockerComposeProject {
@@ -416,12 +575,16 @@ func (c *DockerCommand) GetServices() ([]*Service, error) {
 }
 
 // UpdateContainerDetails attaches the details returned from docker inspect to each of the containers
-// this contains a bit more info than what you get from the go-docker client
+// this contains a bit more info than what you get from the go-docker client. Containers that haven't
+// changed since we last inspected them keep their details for ContainerDetailsTTL.
 func (c *DockerCommand) UpdateContainerDetails() error {
 	c.ContainerMutex.Lock()
 	defer c.ContainerMutex.Unlock()
 
-	containers := c.Containers
+	containers := c.containerDetailsCache.stale(c.Containers)
+	if len(containers) == 0 {
+		return nil
+	}
 
 	ids := make([]string, len(containers))
 	for i, container := range containers {
@@ -442,6 +605,7 @@ func (c *DockerCommand) UpdateContainerDetails() error {
 	for i, container := range containers {
 		container.Details = *details[i]
 	}
+	c.containerDetailsCache.fetched(containers)
 
 	return nil
 }
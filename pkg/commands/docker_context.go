@@ -0,0 +1,207 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/docker/docker/client"
+	"github.com/jesseduffield/lazydocker/pkg/commands/ssh"
+	"github.com/jesseduffield/lazydocker/pkg/utils"
+)
+
+// dockerEnvVars are the environment variables saying which daemon to talk to
+// and how. We point them at whichever context we switch to, so that the docker
+// CLI commands we run (e.g. `docker stats` and custom commands) go to the same
+// daemon as the client.
+var dockerEnvVars = []string{"DOCKER_HOST", "DOCKER_CERT_PATH", "DOCKER_TLS_VERIFY"}
+
+// getDockerEnv takes a copy of dockerEnvVars as they are now
+func getDockerEnv() map[string]string {
+	env := map[string]string{}
+	for _, key := range dockerEnvVars {
+		env[key] = os.Getenv(key)
+	}
+	return env
+}
+
+func setDockerEnv(env map[string]string) error {
+	for _, key := range dockerEnvVars {
+		var err error
+		if env[key] == "" {
+			err = os.Unsetenv(key)
+		} else {
+			err = os.Setenv(key, env[key])
+		}
+		if err != nil {
+			return fmt.Errorf("set %s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// ListDockerContexts returns the docker contexts we can switch to, starting
+// with the default one
+func (c *DockerCommand) ListDockerContexts() ([]ssh.DockerContext, error) {
+	return ssh.NewSSHHandler(ssh.WithLogger(c.Log)).ListDockerContexts()
+}
+
+// dockerContextEnv is what dockerEnvVars should be set to for the given
+// context. The default context is however things were when we started.
+func (c *DockerCommand) dockerContextEnv(dockerContext ssh.DockerContext) map[string]string {
+	if dockerContext.Name == ssh.DefaultDockerContext {
+		env := map[string]string{}
+		for key, value := range c.startupEnv {
+			env[key] = value
+		}
+		return env
+	}
+	return dockerContext.Env()
+}
+
+// SwitchDockerContext connects to the daemon of the given docker context and,
+// once it's answering, makes it the one we talk to. For an ssh:// context we
+// open a new tunnel first, telling progress about each dial attempt and asking
+// prompt for the password if the host wants one and password auth is on. If we
+// can't connect, we stay connected to the current context.
+func (c *DockerCommand) SwitchDockerContext(ctx context.Context, dockerContext ssh.DockerContext, progress ssh.ProgressFunc, prompt ssh.PasswordPrompt) error {
+	if err := c.connect(ctx, dockerContext, progress, prompt, true); err != nil {
+		return fmt.Errorf("connect to docker context %q: %w", dockerContext.Name, err)
+	}
+	return nil
+}
+
+// Reconnect connects to the daemon we're talking to all over again, replacing
+// the client and, for an ssh:// host, tearing down the tunnel and opening a
+// new one. It's for when the connection's dropped and hasn't come back by
+// itself. What we know about the daemon's containers and so on is kept, as
+// it's the same daemon. progress and prompt are as for SwitchDockerContext.
+func (c *DockerCommand) Reconnect(ctx context.Context, progress ssh.ProgressFunc, prompt ssh.PasswordPrompt) error {
+	c.connectionMutex.Lock()
+	dockerContext := c.dockerContext
+	c.connectionMutex.Unlock()
+
+	if err := c.connect(ctx, dockerContext, progress, prompt, false); err != nil {
+		return fmt.Errorf("reconnect to %s: %w", c.Endpoint(), err)
+	}
+	return nil
+}
+
+// connect does the connecting for SwitchDockerContext and Reconnect. If
+// switching, everything we know about is forgotten, being from the old
+// daemon.
+func (c *DockerCommand) connect(ctx context.Context, dockerContext ssh.DockerContext, progress ssh.ProgressFunc, prompt ssh.PasswordPrompt, switching bool) error {
+	env := c.dockerContextEnv(dockerContext)
+
+	host := env["DOCKER_HOST"]
+	if host == "" {
+		// rather than leaving DOCKER_HOST unset, which would have the docker
+		// CLI follow whichever context is selected in its config
+		host = client.DefaultDockerHost
+	}
+	var tunnel ssh.Tunnel
+	if ssh.IsSSHDockerHost(host) {
+		sshHandler := ssh.NewSSHHandler(append(sshOptions(c.Config.UserConfig.SSH, prompt), ssh.WithLogger(c.Log), ssh.WithProgress(progress))...)
+		sshTunnel, err := sshHandler.OpenTunnel(ctx, host)
+		if err != nil {
+			return err
+		}
+		tunnel = sshTunnel
+		host = tunnel.SocketPath()
+	}
+	env["DOCKER_HOST"] = host
+
+	cli, err := newDockerClient(host, env["DOCKER_CERT_PATH"])
+	if err == nil {
+		_, err = cli.Ping(ctx)
+	}
+	if err != nil {
+		closers := []io.Closer{}
+		if cli != nil {
+			closers = append(closers, cli)
+		}
+		if tunnel != nil {
+			closers = append(closers, tunnel)
+		}
+		_ = utils.CloseMany(closers)
+		return err
+	}
+
+	if err := setDockerEnv(env); err != nil {
+		return err
+	}
+
+	c.ServiceMutex.Lock()
+	c.ContainerMutex.Lock()
+	oldClient, oldTunnel := c.Client, c.tunnel
+	c.Client = cli
+	c.tunnel = tunnel
+	c.ContextName = dockerContext.Name
+	if switching {
+		// everything we know about is from the old daemon
+		c.Containers = nil
+		c.DisplayContainers = nil
+		c.TotalDisplayContainers = 0
+		c.Images = nil
+		c.Volumes = nil
+	}
+	c.ContainerMutex.Unlock()
+	c.ServiceMutex.Unlock()
+	c.InvalidateContainerCache()
+	c.connected(dockerContext, tunnel)
+	if switching {
+		// the event stream picks up from the new daemon once the old client's
+		// closed, and the old daemon's events mean nothing there
+		c.events.clear()
+	}
+
+	// these go away with the old tunnel
+	c.portForwardMutex.Lock()
+	c.portForwards = nil
+	c.portForwardMutex.Unlock()
+
+	closers := []io.Closer{oldClient}
+	if oldTunnel != nil {
+		closers = append(closers, oldTunnel)
+	}
+	if err := utils.CloseMany(closers); err != nil {
+		c.Log.Warn(err)
+	}
+
+	// `docker stats` is still watching the old daemon, or the old tunnel
+	c.stopCLIContainerStats()
+	go c.MonitorCLIContainerStats()
+
+	return nil
+}
+
+// findDockerContext returns the docker context with the given name, for
+// connecting to it again later, or failing that just its name
+func (c *DockerCommand) findDockerContext(name string) ssh.DockerContext {
+	dockerContexts, err := c.ListDockerContexts()
+	if err != nil {
+		c.Log.Warn(err)
+	}
+	for _, dockerContext := range dockerContexts {
+		if dockerContext.Name == name {
+			return dockerContext
+		}
+	}
+	return ssh.DockerContext{Name: name}
+}
+
+// newDockerClient returns a client for the daemon at host, using the TLS files
+// in certPath if there are any
+func newDockerClient(host string, certPath string) (*client.Client, error) {
+	opts := []func(*client.Client) error{client.WithHost(host), client.WithVersion(APIVersion)}
+	if certPath != "" {
+		opts = append(opts, client.WithTLSClientConfig(
+			filepath.Join(certPath, "ca.pem"),
+			filepath.Join(certPath, "cert.pem"),
+			filepath.Join(certPath, "key.pem"),
+		))
+	}
+	return client.NewClientWithOpts(opts...)
+}
@@ -0,0 +1,84 @@
+package commands
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"testing"
+
+	"github.com/docker/docker/client"
+	"github.com/jesseduffield/lazydocker/pkg/commands/ssh"
+	"github.com/jesseduffield/lazydocker/pkg/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func newDockerContextTestCommand(t *testing.T, host string) *DockerCommand {
+	userConfig := config.GetDefaultConfig()
+	osCommand := NewDummyOSCommand()
+	// standing in for `docker stats`, which is restarted after a switch
+	osCommand.SetCommand(func(name string, args ...string) *exec.Cmd { return exec.Command("true") })
+
+	cli, err := client.NewClientWithOpts(client.WithHost(host), client.WithVersion(APIVersion))
+	assert.NoError(t, err)
+	return &DockerCommand{
+		Log:                NewDummyLog(),
+		OSCommand:          osCommand,
+		Config:             &config.AppConfig{UserConfig: &userConfig},
+		Client:             cli,
+		ErrorChan:          make(chan error, 10),
+		ContextName:        "default",
+		Images:             []*Image{{Name: "old"}},
+		startupEnv:         map[string]string{},
+		containerListCache: newContainerListCache(0),
+		events:             newEventFeed(),
+	}
+}
+
+func TestDockerCommandSwitchDockerContext(t *testing.T) {
+	for _, key := range dockerEnvVars {
+		defer os.Setenv(key, os.Getenv(key))
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/_ping", r.URL.Path)
+		_, _ = w.Write([]byte("OK"))
+	}))
+	defer server.Close()
+	host := "tcp://" + server.Listener.Addr().String()
+
+	dockerCommand := newDockerContextTestCommand(t, "tcp://127.0.0.1:1")
+	err := dockerCommand.SwitchDockerContext(context.Background(), ssh.DockerContext{Name: "remote", Host: host}, nil, nil)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "remote", dockerCommand.ContextName)
+	assert.Equal(t, host, dockerCommand.Client.DaemonHost())
+	assert.Equal(t, host, os.Getenv("DOCKER_HOST"))
+	// the images were on the old daemon
+	assert.Len(t, dockerCommand.Images, 0)
+}
+
+func TestDockerCommandSwitchDockerContextFailureKeepsTheOldOne(t *testing.T) {
+	for _, key := range dockerEnvVars {
+		defer os.Setenv(key, os.Getenv(key))
+	}
+	assert.NoError(t, os.Setenv("DOCKER_HOST", "tcp://127.0.0.1:1"))
+
+	// a server that's gone away by the time we try it
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	host := "tcp://" + server.Listener.Addr().String()
+	server.Close()
+
+	dockerCommand := newDockerContextTestCommand(t, "tcp://127.0.0.1:1")
+	oldClient := dockerCommand.Client
+	err := dockerCommand.SwitchDockerContext(context.Background(), ssh.DockerContext{Name: "remote", Host: host}, nil, nil)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), `connect to docker context "remote"`)
+	}
+
+	assert.Equal(t, "default", dockerCommand.ContextName)
+	assert.Equal(t, oldClient, dockerCommand.Client)
+	assert.Equal(t, "tcp://127.0.0.1:1", os.Getenv("DOCKER_HOST"))
+	assert.Len(t, dockerCommand.Images, 1)
+}
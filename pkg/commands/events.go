@@ -0,0 +1,253 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/events"
+)
+
+const (
+	// maxEvents is how many of the daemon's events we hold on to
+	maxEvents = 1000
+	// initialEventsRetryInterval and maxEventsRetryInterval bound how long we
+	// wait before resubscribing when the event stream drops
+	initialEventsRetryInterval = time.Second
+	maxEventsRetryInterval     = 30 * time.Second
+)
+
+// eventTypes are the kinds of object docker reports events for
+var eventTypes = []string{"container", "image", "volume", "network", "daemon", "plugin", "service", "node", "secret", "config"}
+
+// eventFeed holds on to the most recent events from the daemon, along with
+// why the stream is down if it is
+type eventFeed struct {
+	mutex  sync.Mutex
+	events []events.Message
+	err    error
+	// changed gets a value whenever there's something new to show. It only
+	// holds one, so a burst of events makes for one re-render.
+	changed chan struct{}
+	cancel  context.CancelFunc
+}
+
+func newEventFeed() *eventFeed {
+	return &eventFeed{changed: make(chan struct{}, 1)}
+}
+
+func (f *eventFeed) notify() {
+	select {
+	case f.changed <- struct{}{}:
+	default:
+	}
+}
+
+func (f *eventFeed) add(message events.Message) {
+	f.mutex.Lock()
+	f.events = append(f.events, message)
+	if len(f.events) > maxEvents {
+		f.events = f.events[len(f.events)-maxEvents:]
+	}
+	f.mutex.Unlock()
+	f.notify()
+}
+
+func (f *eventFeed) setErr(err error) {
+	f.mutex.Lock()
+	f.err = err
+	f.mutex.Unlock()
+	f.notify()
+}
+
+func (f *eventFeed) clear() {
+	f.mutex.Lock()
+	f.events = nil
+	f.err = nil
+	f.mutex.Unlock()
+	f.notify()
+}
+
+// since is where to pick the stream up from after it drops, so that we hear
+// about what happened in the meantime, which the daemon holds on to for a
+// while. It's blank if we've no events to go on.
+func (f *eventFeed) since() string {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if len(f.events) == 0 {
+		return ""
+	}
+	next := f.events[len(f.events)-1].TimeNano + 1
+	return fmt.Sprintf("%d.%09d", next/int64(time.Second), next%int64(time.Second))
+}
+
+// MonitorEvents follows the daemon's event stream in the background until
+// we're closed. If the stream drops, e.g. because the ssh tunnel did, we keep
+// resubscribing, backing off as we go. It's fine to call this more than once.
+func (c *DockerCommand) MonitorEvents() {
+	c.events.mutex.Lock()
+	defer c.events.mutex.Unlock()
+	if c.events.cancel != nil {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	c.events.cancel = cancel
+	go c.followEvents(ctx)
+}
+
+func (c *DockerCommand) stopEvents() {
+	c.events.mutex.Lock()
+	defer c.events.mutex.Unlock()
+	if c.events.cancel != nil {
+		c.events.cancel()
+	}
+}
+
+func (c *DockerCommand) followEvents(ctx context.Context) {
+	interval := initialEventsRetryInterval
+	for {
+		connected, err := c.streamEvents(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		c.Log.Warnf("docker event stream dropped: %v", err)
+		c.events.setErr(err)
+
+		if connected {
+			interval = initialEventsRetryInterval
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+		if interval *= 2; interval > maxEventsRetryInterval {
+			interval = maxEventsRetryInterval
+		}
+	}
+}
+
+// streamEvents subscribes to the event stream and records what comes in until
+// the stream ends, which it only does on an error. connected says whether the
+// daemon was there to subscribe to.
+func (c *DockerCommand) streamEvents(ctx context.Context) (connected bool, err error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Events doesn't say whether it's subscribed until the first event or
+	// error, which could be a long time coming, so we check the daemon's there
+	// first
+	if _, err := c.Client.Ping(ctx); err != nil {
+		return false, err
+	}
+	messages, errs := c.Client.Events(ctx, types.EventsOptions{Since: c.events.since()})
+	c.events.setErr(nil)
+
+	for {
+		select {
+		case message := <-messages:
+			c.events.add(message)
+		case err := <-errs:
+			return true, err
+		}
+	}
+}
+
+// Events returns the events we've heard about, oldest first, and why the
+// event stream is down if it is
+func (c *DockerCommand) Events() ([]events.Message, error) {
+	c.events.mutex.Lock()
+	defer c.events.mutex.Unlock()
+	return append([]events.Message{}, c.events.events...), c.events.err
+}
+
+// EventsChanged gets a value when there's a new event, or the event stream
+// goes down or comes back
+func (c *DockerCommand) EventsChanged() <-chan struct{} {
+	return c.events.changed
+}
+
+// EventFilter narrows down the events we show
+type EventFilter struct {
+	types   []string
+	actions []string
+	terms   []string
+}
+
+// ParseEventFilter turns a filter typed into the events tab into an
+// EventFilter. It takes terms separated by spaces: `type=` for the kind of
+// object, `event=` for what happened to it, and anything else to match the
+// object's name or ID, e.g. `type=container event=die web`. Terms with the
+// same key match either way and the rest must all match. A blank filter
+// matches everything.
+func ParseEventFilter(text string) (EventFilter, error) {
+	filter := EventFilter{}
+	for _, term := range strings.Fields(text) {
+		parts := strings.SplitN(term, "=", 2)
+		if len(parts) == 1 {
+			filter.terms = append(filter.terms, strings.ToLower(term))
+			continue
+		}
+		key, value := parts[0], parts[1]
+		if value == "" {
+			return EventFilter{}, fmt.Errorf("expected key=value, got %q", term)
+		}
+
+		switch key {
+		case "type":
+			if !containsString(eventTypes, value) {
+				return EventFilter{}, fmt.Errorf("unknown type %q: expected one of %s", value, strings.Join(eventTypes, ", "))
+			}
+			filter.types = append(filter.types, value)
+		case "event":
+			filter.actions = append(filter.actions, value)
+		default:
+			return EventFilter{}, fmt.Errorf("unknown filter %q: expected type or event", key)
+		}
+	}
+	return filter, nil
+}
+
+// Matches tells us whether the event gets through the filter
+func (f EventFilter) Matches(message events.Message) bool {
+	if len(f.types) > 0 && !containsString(f.types, message.Type) {
+		return false
+	}
+	// some actions come with details, e.g. `exec_start: sh`, which we don't
+	// expect anyone to type
+	action := strings.SplitN(message.Action, ":", 2)[0]
+	if len(f.actions) > 0 && !containsString(f.actions, action) {
+		return false
+	}
+	for _, term := range f.terms {
+		if !strings.Contains(strings.ToLower(EventResource(message)), term) && !strings.HasPrefix(message.Actor.ID, term) {
+			return false
+		}
+	}
+	return true
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// EventResource is the name of the object the event is about, or its short ID
+// if it hasn't got a name
+func EventResource(message events.Message) string {
+	if name := message.Actor.Attributes["name"]; name != "" {
+		return name
+	}
+	id := message.Actor.ID
+	if len(id) == 64 {
+		return id[:12]
+	}
+	return id
+}
@@ -0,0 +1,109 @@
+package commands
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types/events"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDockerCommandMonitorEvents(t *testing.T) {
+	sinces := make(chan string, 2)
+	dockerCommand := newFakeDaemonCommand(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/_ping"):
+			_, _ = w.Write([]byte("OK"))
+		case strings.HasSuffix(r.URL.Path, "/events"):
+			since := r.URL.Query().Get("since")
+			sinces <- since
+			if since == "" {
+				// the first subscription gets an event and is then dropped
+				_ = json.NewEncoder(w).Encode(events.Message{Type: "container", Action: "start", Actor: events.Actor{ID: "123"}, TimeNano: 1500000000000000000})
+				return
+			}
+			_ = json.NewEncoder(w).Encode(events.Message{Type: "container", Action: "die", Actor: events.Actor{ID: "123"}, TimeNano: 1500000001000000000})
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	dockerCommand.Log = NewDummyLog()
+	dockerCommand.events = newEventFeed()
+
+	dockerCommand.MonitorEvents()
+	dockerCommand.MonitorEvents()
+
+	assert.Equal(t, "", <-sinces)
+	// resubscribing picks up just after the last event we heard about
+	assert.Equal(t, "1500000000.000000001", <-sinces)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if messages, _ := dockerCommand.Events(); len(messages) == 2 {
+			break
+		}
+		select {
+		case <-dockerCommand.EventsChanged():
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	messages, err := dockerCommand.Events()
+	assert.NoError(t, err)
+	assert.Len(t, messages, 2)
+	assert.Equal(t, "die", messages[len(messages)-1].Action)
+
+	assert.NoError(t, dockerCommand.Close())
+}
+
+func TestParseEventFilter(t *testing.T) {
+	die := events.Message{Type: "container", Action: "die", Actor: events.Actor{ID: "abcdef", Attributes: map[string]string{"name": "web_1"}}}
+	exec := events.Message{Type: "container", Action: "exec_start: sh", Actor: events.Actor{ID: "abcdef", Attributes: map[string]string{"name": "web_1"}}}
+	pull := events.Message{Type: "image", Action: "pull", Actor: events.Actor{ID: "alpine:latest", Attributes: map[string]string{"name": "alpine"}}}
+	mount := events.Message{Type: "volume", Action: "mount", Actor: events.Actor{ID: "pgdata"}}
+	all := []events.Message{die, exec, pull, mount}
+
+	type scenario struct {
+		testName            string
+		text                string
+		expected            []events.Message
+		expectedErrorSubstr string
+	}
+
+	scenarios := []scenario{
+		{testName: "Blank filter", text: " ", expected: all},
+		{testName: "By type", text: "type=image type=volume", expected: []events.Message{pull, mount}},
+		{testName: "By event, ignoring details", text: "event=exec_start", expected: []events.Message{exec}},
+		{testName: "By name", text: "WEB event=die", expected: []events.Message{die}},
+		{testName: "By ID prefix", text: "abc", expected: []events.Message{die, exec}},
+		{testName: "By volume name", text: "pgdata", expected: []events.Message{mount}},
+		{testName: "Unknown type", text: "type=box", expectedErrorSubstr: `unknown type "box"`},
+		{testName: "Unknown key", text: "label=a", expectedErrorSubstr: `unknown filter "label"`},
+		{testName: "Missing value", text: "type=", expectedErrorSubstr: "expected key=value"},
+	}
+
+	for _, s := range scenarios {
+		s := s
+		t.Run(s.testName, func(t *testing.T) {
+			filter, err := ParseEventFilter(s.text)
+			if s.expectedErrorSubstr != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), s.expectedErrorSubstr)
+				return
+			}
+			assert.NoError(t, err)
+
+			matches := []events.Message{}
+			for _, message := range all {
+				if filter.Matches(message) {
+					matches = append(matches, message)
+				}
+			}
+			assert.EqualValues(t, s.expected, matches)
+		})
+	}
+}
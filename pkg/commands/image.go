@@ -2,12 +2,14 @@ package commands
 
 import (
 	"context"
-	"github.com/docker/docker/api/types/image"
+	"fmt"
 	"strings"
+	"time"
 
 	"github.com/docker/docker/api/types"
-	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/image"
 	"github.com/docker/docker/client"
+	units "github.com/docker/go-units"
 	"github.com/fatih/color"
 	"github.com/jesseduffield/lazydocker/pkg/utils"
 	"github.com/sirupsen/logrus"
@@ -40,9 +42,15 @@ func (i *Image) Remove(options types.ImageRemoveOptions) error {
 	return nil
 }
 
+// largeLayerShare is the percentage of an image's size past which we highlight
+// a layer, as being worth a look when slimming the image down
+const largeLayerShare = 10
+
 // Layer is a layer in an image's history
 type Layer struct {
 	image.HistoryResponseItem
+	// Share is the percentage of the image's size that's down to this layer
+	Share int
 }
 
 // GetDisplayStrings returns the array of strings describing the layer
@@ -75,30 +83,55 @@ func (l *Layer) GetDisplayStrings(isFocused bool) []string {
 	sizeColor := color.FgWhite
 	if size == "0B" {
 		sizeColor = color.FgBlue
+	} else if l.Share >= largeLayerShare {
+		sizeColor = color.FgRed
 	}
 
 	return []string{
 		utils.ColoredString(id, idColor),
 		utils.ColoredString(tag, color.FgGreen),
 		utils.ColoredString(size, sizeColor),
+		utils.ColoredString(fmt.Sprintf("%d%%", l.Share), sizeColor),
+		units.HumanDuration(time.Since(time.Unix(l.Created, 0))) + " ago",
 		createdBy,
 	}
 }
 
-// RenderHistory renders the history of the image
-func (i *Image) RenderHistory() (string, error) {
-
+// History returns the image's layers, newest first, as docker history lists
+// them
+func (i *Image) History() ([]*Layer, error) {
 	history, err := i.Client.ImageHistory(context.Background(), i.ID)
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+	return newLayers(history), nil
+}
+
+func newLayers(history []image.HistoryResponseItem) []*Layer {
+	var total int64
+	for _, layer := range history {
+		total += layer.Size
 	}
 
 	layers := make([]*Layer, len(history))
 	for i, layer := range history {
-		layers[i] = &Layer{layer}
+		layers[i] = &Layer{HistoryResponseItem: layer}
+		if total > 0 {
+			layers[i].Share = int(layer.Size * 100 / total)
+		}
+	}
+	return layers
+}
+
+// RenderHistory renders the history of the image, with the layers that take up
+// the most space highlighted
+func (i *Image) RenderHistory() (string, error) {
+	layers, err := i.History()
+	if err != nil {
+		return "", err
 	}
 
-	return utils.RenderList(layers, utils.WithHeader([]string{"ID", "TAG", "SIZE", "COMMAND"}))
+	return utils.RenderList(layers, utils.WithHeader([]string{"ID", "TAG", "SIZE", "SHARE", "CREATED", "COMMAND"}))
 }
 
 // RefreshImages returns a slice of docker images
@@ -139,11 +172,7 @@ func (c *DockerCommand) RefreshImages() ([]*Image, error) {
 		}
 	}
 
-	return ownImages, nil
-}
+	sortImages(ownImages, c.ImageSort())
 
-// PruneImages prunes images
-func (c *DockerCommand) PruneImages() error {
-	_, err := c.Client.ImagesPrune(context.Background(), filters.Args{})
-	return err
+	return ownImages, nil
 }
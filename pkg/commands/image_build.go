@@ -0,0 +1,467 @@
+package commands
+
+import (
+	"archive/tar"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/builder/dockerignore"
+	"github.com/docker/docker/pkg/fileutils"
+)
+
+const (
+	// runningStepLogLines is how many of its last lines of output we show for
+	// each step that's still running, like docker build does
+	runningStepLogLines = 5
+	// failedStepLogLines is how many of its last lines of output we show for a
+	// step that's failed
+	failedStepLogLines = 30
+)
+
+// buildMessage is one message from the json stream the daemon sends back while
+// building
+type buildMessage struct {
+	ID     string          `json:"id"`
+	Stream string          `json:"stream"`
+	Aux    json.RawMessage `json:"aux"`
+	Error  string          `json:"error"`
+}
+
+// buildkitTraceID is the id of the messages about BuildKit's progress. Their
+// aux is a StatusResponse from BuildKit's control api, in protobuf.
+const buildkitTraceID = "moby.buildkit.trace"
+
+type buildStep struct {
+	name      string
+	cached    bool
+	started   bool
+	completed bool
+	err       string
+	logs      []string
+	// partial is the last line of output, until we've seen the end of it
+	partial string
+}
+
+// ImageBuildProgress is how far along a build is: each of BuildKit's steps,
+// with the output of those that are running or have failed
+type ImageBuildProgress struct {
+	stepDigests []string
+	steps       map[string]*buildStep
+	// output is what's not about any one step, e.g. everything if the daemon
+	// builds with the classic builder
+	output []string
+}
+
+func newImageBuildProgress() *ImageBuildProgress {
+	return &ImageBuildProgress{steps: map[string]*buildStep{}}
+}
+
+func (p *ImageBuildProgress) step(digest string) *buildStep {
+	step, ok := p.steps[digest]
+	if !ok {
+		step = &buildStep{}
+		p.steps[digest] = step
+		p.stepDigests = append(p.stepDigests, digest)
+	}
+	return step
+}
+
+func (p *ImageBuildProgress) update(message buildMessage) error {
+	if message.Stream != "" {
+		p.output = append(p.output, strings.Split(strings.TrimRight(message.Stream, "\n"), "\n")...)
+	}
+	if message.ID != buildkitTraceID {
+		return nil
+	}
+
+	var data []byte
+	if err := json.Unmarshal(message.Aux, &data); err != nil {
+		return err
+	}
+	status, err := decodeBuildkitStatus(data)
+	if err != nil {
+		return err
+	}
+
+	for _, vertex := range status.vertexes {
+		step := p.step(vertex.digest)
+		if vertex.name != "" {
+			step.name = vertex.name
+		}
+		step.cached = step.cached || vertex.cached
+		step.started = step.started || vertex.started
+		step.completed = step.completed || vertex.completed
+		if vertex.err != "" {
+			step.err = vertex.err
+		}
+	}
+	for _, log := range status.logs {
+		step := p.step(log.vertex)
+		lines := strings.Split(step.partial+string(log.msg), "\n")
+		step.partial = lines[len(lines)-1]
+		for _, line := range lines[:len(lines)-1] {
+			step.logs = append(step.logs, lastCarriageReturnSegment(line))
+		}
+	}
+	return nil
+}
+
+// lastCarriageReturnSegment is what's left on screen of a line that redraws
+// itself with carriage returns, like a progress bar
+func lastCarriageReturnSegment(line string) string {
+	line = strings.TrimRight(line, "\r")
+	return line[strings.LastIndex(line, "\r")+1:]
+}
+
+// Render lays out the progress like docker build does: a line per step, with
+// the last few lines of output of those that are running, and more of the
+// output of a step that's failed
+func (p *ImageBuildProgress) Render() string {
+	lines := []string{}
+	for i, digest := range p.stepDigests {
+		step := p.steps[digest]
+		if step.name == "" {
+			continue
+		}
+
+		line := fmt.Sprintf("#%d %s", i+1, step.name)
+		logLines := 0
+		switch {
+		case step.err != "":
+			line += " ERROR"
+			logLines = failedStepLogLines
+		case step.cached:
+			line += " CACHED"
+		case step.completed:
+			line += " DONE"
+		case step.started:
+			logLines = runningStepLogLines
+		}
+		lines = append(lines, line)
+
+		logs := append([]string{}, step.logs...)
+		if step.partial != "" {
+			logs = append(logs, lastCarriageReturnSegment(step.partial))
+		}
+		if len(logs) > logLines {
+			logs = logs[len(logs)-logLines:]
+		}
+		for _, log := range logs {
+			lines = append(lines, "  "+log)
+		}
+		if step.err != "" {
+			lines = append(lines, "  "+step.err)
+		}
+	}
+	lines = append(lines, p.output...)
+	return strings.Join(lines, "\n")
+}
+
+// BuildImage builds the image in dir from its Dockerfile, a path in dir, with
+// BuildKit and tags it tag, calling onProgress each time the daemon tells us
+// anything. The build context is read from this machine and sent to the
+// daemon, wherever it is, minus what the .dockerignore leaves out. Cancelling
+// ctx aborts the build.
+func (c *DockerCommand) BuildImage(ctx context.Context, dir string, dockerfile string, tag string, onProgress func(*ImageBuildProgress)) error {
+	excludes, err := readDockerignore(dir)
+	if err != nil {
+		return err
+	}
+
+	reader, writer := io.Pipe()
+	go func() {
+		writer.CloseWithError(writeBuildContext(writer, dir, append(excludes, "!"+dockerfile, "!.dockerignore")))
+	}()
+	defer reader.Close()
+
+	response, err := c.Client.ImageBuild(ctx, reader, types.ImageBuildOptions{
+		Dockerfile: filepath.ToSlash(dockerfile),
+		Tags:       []string{tag},
+		Version:    types.BuilderBuildKit,
+	})
+	if err != nil {
+		return err
+	}
+	return followBuildProgress(ctx, response.Body, onProgress)
+}
+
+// followBuildProgress reads the json stream the daemon sends back while
+// building until it's done, calling onProgress as it goes. Cancelling ctx
+// closes the stream, which is how the daemon knows to stop.
+func followBuildProgress(ctx context.Context, stream io.ReadCloser, onProgress func(*ImageBuildProgress)) error {
+	defer stream.Close()
+	defer closeOnCancel(ctx, stream)()
+
+	progress := newImageBuildProgress()
+	decoder := json.NewDecoder(stream)
+	for {
+		var message buildMessage
+		if err := decoder.Decode(&message); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		if message.Error != "" {
+			return errors.New(message.Error)
+		}
+
+		if err := progress.update(message); err != nil {
+			return err
+		}
+		onProgress(progress)
+	}
+}
+
+// FindDockerfile works out what to build from a path typed in: either a
+// directory with a Dockerfile in it, or a Dockerfile itself, in which case its
+// directory is the build context. It returns the directory, and where the
+// Dockerfile is in it.
+func FindDockerfile(path string) (string, string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", "", err
+	}
+	if !info.IsDir() {
+		return filepath.Dir(path), filepath.Base(path), nil
+	}
+
+	if _, err := os.Stat(filepath.Join(path, "Dockerfile")); err != nil {
+		if os.IsNotExist(err) {
+			return "", "", fmt.Errorf("there's no Dockerfile in %s", path)
+		}
+		return "", "", err
+	}
+	return path, "Dockerfile", nil
+}
+
+// invalidTagCharsRegex matches what can't go in the name part of a tag
+var invalidTagCharsRegex = regexp.MustCompile(`[^a-z0-9._-]+`)
+
+// DefaultBuildTag is the tag to give an image built from dir if we're not told
+// one: the directory's name, like docker compose names the images it builds
+func DefaultBuildTag(dir string) string {
+	name := invalidTagCharsRegex.ReplaceAllString(strings.ToLower(filepath.Base(dir)), "-")
+	name = strings.TrimLeft(name, "._-")
+	if name == "" {
+		name = "image"
+	}
+	return name + ":latest"
+}
+
+func readDockerignore(dir string) ([]string, error) {
+	file, err := os.Open(filepath.Join(dir, ".dockerignore"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer file.Close()
+	return dockerignore.ReadAll(file)
+}
+
+// writeBuildContext writes dir to w as a tar, leaving out what the excludes
+// match, like docker build does before it sends the context to the daemon
+func writeBuildContext(w io.Writer, dir string, excludes []string) error {
+	matcher, err := fileutils.NewPatternMatcher(excludes)
+	if err != nil {
+		return err
+	}
+
+	tarWriter := tar.NewWriter(w)
+	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		relPath, err := filepath.Rel(dir, path)
+		if err != nil || relPath == "." {
+			return err
+		}
+
+		excluded, err := matcher.Matches(relPath)
+		if err != nil {
+			return err
+		}
+		if excluded {
+			// there could be an exception for something in the directory
+			if info.IsDir() && !matcher.Exclusions() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		link := ""
+		switch {
+		case info.Mode()&os.ModeSymlink != 0:
+			if link, err = os.Readlink(path); err != nil {
+				return err
+			}
+		case !info.IsDir() && !info.Mode().IsRegular():
+			// sockets and the like don't go in the context
+			return nil
+		}
+
+		header, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(relPath)
+		if info.IsDir() {
+			header.Name += "/"
+		}
+		// the files belong to root in the image, like they do with docker build
+		header.Uid, header.Gid, header.Uname, header.Gname = 0, 0, "", ""
+		if err := tarWriter.WriteHeader(header); err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		_, err = io.Copy(tarWriter, file)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return tarWriter.Close()
+}
+
+// buildkitStatus is the part of BuildKit's StatusResponse we show
+type buildkitStatus struct {
+	vertexes []buildkitVertex
+	logs     []buildkitLog
+}
+
+type buildkitVertex struct {
+	digest    string
+	name      string
+	cached    bool
+	started   bool
+	completed bool
+	err       string
+}
+
+type buildkitLog struct {
+	vertex string
+	msg    []byte
+}
+
+// decodeBuildkitStatus reads a StatusResponse, which is small enough that we
+// can read it field by field rather than pulling in all of BuildKit's api
+func decodeBuildkitStatus(data []byte) (buildkitStatus, error) {
+	status := buildkitStatus{}
+	err := readProtoFields(data, func(number int, value []byte) error {
+		switch number {
+		case 1:
+			vertex := buildkitVertex{}
+			err := readProtoFields(value, func(number int, value []byte) error {
+				switch number {
+				case 1:
+					vertex.digest = string(value)
+				case 3:
+					vertex.name = string(value)
+				case 4:
+					vertex.cached = len(value) > 0 && value[0] != 0
+				case 5:
+					vertex.started = true
+				case 6:
+					vertex.completed = true
+				case 7:
+					vertex.err = string(value)
+				}
+				return nil
+			})
+			status.vertexes = append(status.vertexes, vertex)
+			return err
+		case 3:
+			log := buildkitLog{}
+			err := readProtoFields(value, func(number int, value []byte) error {
+				switch number {
+				case 1:
+					log.vertex = string(value)
+				case 4:
+					log.msg = value
+				}
+				return nil
+			})
+			status.logs = append(status.logs, log)
+			return err
+		}
+		return nil
+	})
+	return status, err
+}
+
+// readProtoFields calls f with each field of a protobuf message in turn. The
+// value of a varint field is passed as a single byte, 1 if it's set and 0 if
+// not, which is all we need for bools; fixed size fields are skipped.
+func readProtoFields(data []byte, f func(number int, value []byte) error) error {
+	for len(data) > 0 {
+		key, n := binary.Uvarint(data)
+		if n <= 0 {
+			return errors.New("invalid protobuf message")
+		}
+		data = data[n:]
+
+		number := int(key >> 3)
+		var value []byte
+		switch key & 7 {
+		case 0:
+			varint, n := binary.Uvarint(data)
+			if n <= 0 {
+				return errors.New("invalid protobuf message")
+			}
+			data = data[n:]
+			if varint != 0 {
+				value = []byte{1}
+			} else {
+				value = []byte{0}
+			}
+		case 1:
+			if len(data) < 8 {
+				return errors.New("invalid protobuf message")
+			}
+			data = data[8:]
+			continue
+		case 2:
+			length, n := binary.Uvarint(data)
+			if n <= 0 || uint64(len(data)-n) < length {
+				return errors.New("invalid protobuf message")
+			}
+			value = data[n : n+int(length)]
+			data = data[n+int(length):]
+		case 5:
+			if len(data) < 4 {
+				return errors.New("invalid protobuf message")
+			}
+			data = data[4:]
+			continue
+		default:
+			return fmt.Errorf("unsupported protobuf wire type %d", key&7)
+		}
+
+		if err := f(number, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,303 @@
+package commands
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func appendVarint(data []byte, value uint64) []byte {
+	buffer := make([]byte, binary.MaxVarintLen64)
+	return append(data, buffer[:binary.PutUvarint(buffer, value)]...)
+}
+
+// protoField encodes a length-delimited protobuf field
+func protoField(number int, value []byte) []byte {
+	field := appendVarint(nil, uint64(number<<3|2))
+	field = appendVarint(field, uint64(len(value)))
+	return append(field, value...)
+}
+
+func protoVarint(number int, value uint64) []byte {
+	return appendVarint(appendVarint(nil, uint64(number<<3)), value)
+}
+
+type testVertex struct {
+	digest    string
+	name      string
+	cached    bool
+	started   bool
+	completed bool
+	err       string
+}
+
+// buildkitTrace returns the message the daemon sends with BuildKit's progress
+func buildkitTrace(vertexes []testVertex, logs map[string]string) string {
+	status := []byte{}
+	for _, vertex := range vertexes {
+		encoded := protoField(1, []byte(vertex.digest))
+		encoded = append(encoded, protoField(2, []byte("sha256:input"))...)
+		encoded = append(encoded, protoField(3, []byte(vertex.name))...)
+		if vertex.cached {
+			encoded = append(encoded, protoVarint(4, 1)...)
+		}
+		// a timestamp, with its seconds
+		timestamp := protoVarint(1, 1600000000)
+		if vertex.started {
+			encoded = append(encoded, protoField(5, timestamp)...)
+		}
+		if vertex.completed {
+			encoded = append(encoded, protoField(6, timestamp)...)
+		}
+		if vertex.err != "" {
+			encoded = append(encoded, protoField(7, []byte(vertex.err))...)
+		}
+		status = append(status, protoField(1, encoded)...)
+	}
+
+	digests := []string{}
+	for digest := range logs {
+		digests = append(digests, digest)
+	}
+	sort.Strings(digests)
+	for _, digest := range digests {
+		encoded := protoField(1, []byte(digest))
+		encoded = append(encoded, protoVarint(3, 1)...)
+		encoded = append(encoded, protoField(4, []byte(logs[digest]))...)
+		status = append(status, protoField(3, encoded)...)
+	}
+
+	aux, _ := json.Marshal(status)
+	message, _ := json.Marshal(map[string]json.RawMessage{"id": json.RawMessage(`"moby.buildkit.trace"`), "aux": aux})
+	return string(message) + "\n"
+}
+
+func TestImageBuildProgressRender(t *testing.T) {
+	type scenario struct {
+		testName string
+		messages []string
+		expected string
+	}
+
+	scenarios := []scenario{
+		{
+			testName: "Steps as they go",
+			messages: []string{
+				buildkitTrace([]testVertex{
+					{digest: "a", name: "[internal] load build definition from Dockerfile", started: true},
+					{digest: "b", name: "[1/2] FROM docker.io/library/alpine", started: true},
+				}, nil),
+				buildkitTrace([]testVertex{
+					{digest: "a", name: "[internal] load build definition from Dockerfile", started: true, completed: true},
+					{digest: "b", name: "[1/2] FROM docker.io/library/alpine", cached: true},
+					{digest: "c", name: "[2/2] RUN make", started: true},
+				}, map[string]string{"c": "one\ntwo\nthr"}),
+				buildkitTrace(nil, map[string]string{"c": "ee\n10%\r50%\r"}),
+			},
+			expected: "#1 [internal] load build definition from Dockerfile DONE\n" +
+				"#2 [1/2] FROM docker.io/library/alpine CACHED\n" +
+				"#3 [2/2] RUN make\n" +
+				"  one\n" +
+				"  two\n" +
+				"  three\n" +
+				"  50%",
+		},
+		{
+			testName: "The failing step's output",
+			messages: []string{
+				buildkitTrace([]testVertex{
+					{digest: "c", name: "[2/2] RUN make", started: true},
+				}, map[string]string{"c": "compiling\nmain.go:1: syntax error\n"}),
+				buildkitTrace([]testVertex{
+					{digest: "c", name: "[2/2] RUN make", started: true, completed: true, err: "exit code: 2"},
+				}, nil),
+			},
+			expected: "#1 [2/2] RUN make ERROR\n" +
+				"  compiling\n" +
+				"  main.go:1: syntax error\n" +
+				"  exit code: 2",
+		},
+		{
+			testName: "The classic builder",
+			messages: []string{
+				`{"stream": "Step 1/2 : FROM alpine\n"}`,
+				`{"stream": " ---> 3fd9065eaf02\n"}`,
+			},
+			expected: "Step 1/2 : FROM alpine\n ---> 3fd9065eaf02",
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.testName, func(t *testing.T) {
+			progress := newImageBuildProgress()
+			for _, encoded := range s.messages {
+				var message buildMessage
+				assert.NoError(t, json.Unmarshal([]byte(encoded), &message))
+				assert.NoError(t, progress.update(message))
+			}
+			assert.Equal(t, s.expected, progress.Render())
+		})
+	}
+}
+
+func TestReadProtoFieldsInvalid(t *testing.T) {
+	// a field that says it's longer than the message
+	err := readProtoFields([]byte{1<<3 | 2, 10, 'a'}, func(int, []byte) error { return nil })
+	assert.Error(t, err)
+}
+
+func TestWriteBuildContext(t *testing.T) {
+	dir, err := ioutil.TempDir("", "build-context")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"Dockerfile":       "FROM alpine\n",
+		".dockerignore":    "**/*.log\nnode_modules\nDockerfile\n",
+		"main.go":          "package main\n",
+		"debug.log":        "noise",
+		"node_modules/a":   "dependency",
+		"src/lib/lib.go":   "package lib\n",
+		"src/lib/test.log": "noise",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NoError(t, ioutil.WriteFile(path, []byte(content), 0644))
+	}
+
+	excludes, err := readDockerignore(dir)
+	assert.NoError(t, err)
+	buffer := bytes.Buffer{}
+	assert.NoError(t, writeBuildContext(&buffer, dir, append(excludes, "!Dockerfile", "!.dockerignore")))
+
+	names := []string{}
+	contents := map[string]string{}
+	reader := tar.NewReader(&buffer)
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		assert.NoError(t, err)
+		names = append(names, header.Name)
+		content, err := ioutil.ReadAll(reader)
+		assert.NoError(t, err)
+		contents[header.Name] = string(content)
+	}
+
+	// the Dockerfile is sent even though it's ignored, like with docker build
+	assert.ElementsMatch(t, []string{".dockerignore", "Dockerfile", "main.go", "src/", "src/lib/", "src/lib/lib.go"}, names)
+	assert.Equal(t, "package lib\n", contents["src/lib/lib.go"])
+}
+
+func TestFindDockerfile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "find-dockerfile")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	assert.NoError(t, os.Mkdir(filepath.Join(dir, "web"), 0755))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "web", "Dockerfile"), []byte("FROM alpine\n"), 0644))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "web", "Dockerfile.dev"), []byte("FROM alpine\n"), 0644))
+
+	contextDir, dockerfile, err := FindDockerfile(filepath.Join(dir, "web"))
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "web"), contextDir)
+	assert.Equal(t, "Dockerfile", dockerfile)
+
+	contextDir, dockerfile, err = FindDockerfile(filepath.Join(dir, "web", "Dockerfile.dev"))
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "web"), contextDir)
+	assert.Equal(t, "Dockerfile.dev", dockerfile)
+
+	_, _, err = FindDockerfile(dir)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "no Dockerfile")
+	}
+}
+
+func TestDefaultBuildTag(t *testing.T) {
+	assert.Equal(t, "web:latest", DefaultBuildTag("/src/web"))
+	assert.Equal(t, "my-app:latest", DefaultBuildTag("/src/My App"))
+	assert.Equal(t, "image:latest", DefaultBuildTag("/src/_"))
+}
+
+func TestDockerCommandBuildImage(t *testing.T) {
+	type scenario struct {
+		testName            string
+		body                string
+		expectedErrorSubstr string
+		expectedRendered    string
+	}
+
+	scenarios := []scenario{
+		{
+			testName: "Successful build",
+			body: buildkitTrace([]testVertex{
+				{digest: "a", name: "[1/1] FROM docker.io/library/alpine", started: true, completed: true},
+			}, nil) + `{"id": "moby.image.id", "aux": {"ID": "sha256:abc"}}` + "\n",
+			expectedRendered: "#1 [1/1] FROM docker.io/library/alpine DONE",
+		},
+		{
+			testName: "Failed build",
+			body: buildkitTrace([]testVertex{
+				{digest: "a", name: "[2/2] RUN false", started: true, completed: true, err: "exit code: 1"},
+			}, nil) + `{"errorDetail": {"message": "executor failed running [/bin/sh -c false]"}, "error": "executor failed running [/bin/sh -c false]"}` + "\n",
+			expectedErrorSubstr: "executor failed",
+			expectedRendered:    "#1 [2/2] RUN false ERROR\n  exit code: 1",
+		},
+	}
+
+	dir, err := ioutil.TempDir("", "build-image")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "Dockerfile"), []byte("FROM alpine\n"), 0644))
+
+	for _, s := range scenarios {
+		s := s
+		t.Run(s.testName, func(t *testing.T) {
+			dockerCommand := newFakeDaemonCommand(t, func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "2", r.URL.Query().Get("version"))
+				assert.Equal(t, "web:latest", r.URL.Query().Get("t"))
+				assert.Equal(t, "Dockerfile", r.URL.Query().Get("dockerfile"))
+
+				names := []string{}
+				reader := tar.NewReader(r.Body)
+				for {
+					header, err := reader.Next()
+					if err != nil {
+						assert.Equal(t, io.EOF, err)
+						break
+					}
+					names = append(names, header.Name)
+				}
+				assert.Equal(t, []string{"Dockerfile"}, names)
+				_, _ = w.Write([]byte(s.body))
+			})
+
+			rendered := ""
+			err := dockerCommand.BuildImage(context.Background(), dir, "Dockerfile", "web:latest", func(progress *ImageBuildProgress) {
+				rendered = progress.Render()
+			})
+			if s.expectedErrorSubstr != "" {
+				if assert.Error(t, err) {
+					assert.Contains(t, err.Error(), s.expectedErrorSubstr)
+				}
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, s.expectedRendered, rendered)
+		})
+	}
+}
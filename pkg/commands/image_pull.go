@@ -0,0 +1,161 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/jesseduffield/lazydocker/pkg/utils"
+)
+
+// pullProgressBarWidth is how many characters wide each layer's progress bar is
+const pullProgressBarWidth = 30
+
+// pullMessage is one message from the json stream the daemon sends back while
+// pulling or pushing
+type pullMessage struct {
+	ID             string `json:"id"`
+	Status         string `json:"status"`
+	ProgressDetail struct {
+		Current int64 `json:"current"`
+		Total   int64 `json:"total"`
+	} `json:"progressDetail"`
+	Error string `json:"error"`
+}
+
+type pullLayer struct {
+	status  string
+	current int64
+	total   int64
+}
+
+// ImagePullProgress is how far along a pull or push is: the latest status of
+// each layer, along with the messages that aren't about any one layer
+type ImagePullProgress struct {
+	// header is e.g. "latest: Pulling from library/alpine", or "The push
+	// refers to repository [docker.io/library/alpine]"
+	header   []string
+	layerIDs []string
+	layers   map[string]pullLayer
+	// summary is the digest and final status, which come once we're done
+	summary []string
+}
+
+func newImagePullProgress() *ImagePullProgress {
+	return &ImagePullProgress{layers: map[string]pullLayer{}}
+}
+
+func (p *ImagePullProgress) update(message pullMessage) {
+	switch {
+	case message.ID == "" && message.Status == "":
+		// e.g. the digest of a push again, for machines to read
+	case message.ID == "" && len(p.layerIDs) == 0:
+		p.header = append(p.header, message.Status)
+	case message.ID == "":
+		p.summary = append(p.summary, message.Status)
+	case strings.HasPrefix(message.Status, "Pulling from "):
+		// this has the tag as its id, rather than a layer
+		p.header = append(p.header, message.ID+": "+message.Status)
+	default:
+		if _, ok := p.layers[message.ID]; !ok {
+			p.layerIDs = append(p.layerIDs, message.ID)
+		}
+		p.layers[message.ID] = pullLayer{
+			status:  message.Status,
+			current: message.ProgressDetail.Current,
+			total:   message.ProgressDetail.Total,
+		}
+	}
+}
+
+// Render lays out the progress like docker pull does: a line per layer, with
+// a progress bar for those being downloaded or extracted
+func (p *ImagePullProgress) Render() string {
+	lines := append([]string{}, p.header...)
+	for _, id := range p.layerIDs {
+		layer := p.layers[id]
+		line := fmt.Sprintf("%s: %s", id, layer.status)
+		if layer.total > 0 {
+			line += " " + renderProgressBar(layer.current, layer.total)
+		}
+		lines = append(lines, line)
+	}
+	lines = append(lines, p.summary...)
+	return strings.Join(lines, "\n")
+}
+
+func renderProgressBar(current int64, total int64) string {
+	if current > total {
+		current = total
+	}
+	filled := int(current * pullProgressBarWidth / total)
+	bar := strings.Repeat("=", filled)
+	if filled < pullProgressBarWidth {
+		bar += ">" + strings.Repeat(" ", pullProgressBarWidth-filled-1)
+	}
+	return fmt.Sprintf("[%s] %s/%s", bar, utils.FormatDecimalBytes(int(current)), utils.FormatDecimalBytes(int(total)))
+}
+
+// PullImage pulls ref, calling onProgress with how far along it is each time
+// the daemon tells us anything. Credentials for the registry come from the
+// docker config, as they would for docker pull. Cancelling ctx aborts the
+// pull, in which case the daemon stops pulling too.
+func (c *DockerCommand) PullImage(ctx context.Context, ref string, onProgress func(*ImagePullProgress)) error {
+	registryAuth, err := registryAuthFor(ref, dockerConfigDir(), runCredentialHelper)
+	if err != nil {
+		return err
+	}
+
+	stream, err := c.Client.ImagePull(ctx, ref, types.ImagePullOptions{RegistryAuth: registryAuth})
+	if err != nil {
+		return err
+	}
+	return followImageProgress(ctx, stream, onProgress)
+}
+
+// followImageProgress reads the json stream the daemon sends back while
+// pulling or pushing until it's done, calling onProgress as it goes.
+// Cancelling ctx closes the stream, which is how the daemon knows to stop.
+func followImageProgress(ctx context.Context, stream io.ReadCloser, onProgress func(*ImagePullProgress)) error {
+	defer stream.Close()
+	defer closeOnCancel(ctx, stream)()
+
+	progress := newImagePullProgress()
+	decoder := json.NewDecoder(stream)
+	for {
+		var message pullMessage
+		if err := decoder.Decode(&message); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		if message.Error != "" {
+			return errors.New(message.Error)
+		}
+
+		progress.update(message)
+		onProgress(progress)
+	}
+}
+
+// closeOnCancel closes stream if ctx is cancelled before the returned func is
+// called, so that reading from it returns straight away
+func closeOnCancel(ctx context.Context, stream io.Closer) func() {
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			stream.Close()
+		case <-done:
+		}
+	}()
+	return func() { close(done) }
+}
@@ -0,0 +1,125 @@
+package commands
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImagePullProgressRender(t *testing.T) {
+	progress := newImagePullProgress()
+	messages := []pullMessage{
+		{ID: "3.12", Status: "Pulling from library/alpine"},
+		{ID: "aaa", Status: "Pulling fs layer"},
+		{ID: "bbb", Status: "Pulling fs layer"},
+		{ID: "aaa", Status: "Downloading"},
+		{ID: "bbb", Status: "Download complete"},
+	}
+	for _, message := range messages {
+		progress.update(message)
+	}
+	// halfway through downloading the first layer
+	message := pullMessage{ID: "aaa", Status: "Downloading"}
+	message.ProgressDetail.Current = 1500
+	message.ProgressDetail.Total = 3000
+	progress.update(message)
+	progress.update(pullMessage{Status: "Digest: sha256:abc"})
+
+	assert.Equal(t,
+		"3.12: Pulling from library/alpine\n"+
+			"aaa: Downloading [===============>              ] 1.50kB/3.00kB\n"+
+			"bbb: Download complete\n"+
+			"Digest: sha256:abc",
+		progress.Render(),
+	)
+}
+
+func TestRenderProgressBar(t *testing.T) {
+	assert.Equal(t, "[>                             ] 0B/10.00B", renderProgressBar(0, 10))
+	assert.Equal(t, "[==============================] 10.00B/10.00B", renderProgressBar(10, 10))
+	// the daemon can overshoot
+	assert.Equal(t, "[==============================] 10.00B/10.00B", renderProgressBar(12, 10))
+}
+
+func TestDockerCommandPullImage(t *testing.T) {
+	type scenario struct {
+		testName            string
+		body                string
+		expectedErrorSubstr string
+		expectedRendered    string
+	}
+
+	scenarios := []scenario{
+		{
+			testName:         "Successful pull",
+			body:             `{"status": "Pulling from library/alpine", "id": "latest"}` + "\n" + `{"status": "Pull complete", "id": "aaa"}` + "\n" + `{"status": "Status: Downloaded newer image for alpine:latest"}`,
+			expectedRendered: "latest: Pulling from library/alpine\naaa: Pull complete\nStatus: Downloaded newer image for alpine:latest",
+		},
+		{
+			testName:            "Error partway through",
+			body:                `{"status": "Pulling from library/alpine", "id": "latest"}` + "\n" + `{"error": "manifest unknown", "errorDetail": {"message": "manifest unknown"}}`,
+			expectedErrorSubstr: "manifest unknown",
+			expectedRendered:    "latest: Pulling from library/alpine",
+		},
+	}
+
+	// no credentials to find
+	dir := t.Name()
+	defer os.Setenv("DOCKER_CONFIG", os.Getenv("DOCKER_CONFIG"))
+	os.Setenv("DOCKER_CONFIG", dir)
+
+	for _, s := range scenarios {
+		s := s
+		t.Run(s.testName, func(t *testing.T) {
+			dockerCommand := newFakeDaemonCommand(t, func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "alpine", r.URL.Query().Get("fromImage"))
+				assert.Equal(t, "latest", r.URL.Query().Get("tag"))
+				_, _ = w.Write([]byte(s.body))
+			})
+
+			rendered := ""
+			err := dockerCommand.PullImage(context.Background(), "alpine", func(progress *ImagePullProgress) {
+				rendered = progress.Render()
+			})
+			if s.expectedErrorSubstr != "" {
+				if assert.Error(t, err) {
+					assert.Contains(t, err.Error(), s.expectedErrorSubstr)
+				}
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, s.expectedRendered, rendered)
+		})
+	}
+}
+
+func TestDockerCommandPullImageCancel(t *testing.T) {
+	defer os.Setenv("DOCKER_CONFIG", os.Getenv("DOCKER_CONFIG"))
+	os.Setenv("DOCKER_CONFIG", t.Name())
+
+	dockerCommand := newFakeDaemonCommand(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"status": "Pulling fs layer", "id": "aaa"}` + "\n"))
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- dockerCommand.PullImage(ctx, "alpine", func(progress *ImagePullProgress) {
+			// we've heard from the daemon, so now the user gives up
+			cancel()
+		})
+	}()
+
+	select {
+	case err := <-done:
+		assert.Equal(t, context.Canceled, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected cancelling to abort the pull")
+	}
+}
@@ -0,0 +1,80 @@
+package commands
+
+import (
+	"context"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+)
+
+// RegistryCredentials are what the user typed in to log in to a registry
+type RegistryCredentials struct {
+	Username string
+	Password string
+}
+
+// PushImage pushes ref, calling onProgress with how far along it is each time
+// the daemon tells us anything. Without credentials, we use what's in the
+// docker config, as docker push would. Cancelling ctx aborts the push, in
+// which case the daemon stops uploading too.
+func (c *DockerCommand) PushImage(ctx context.Context, ref string, credentials *RegistryCredentials, onProgress func(*ImagePullProgress)) error {
+	registryAuth, err := pushRegistryAuth(ref, credentials)
+	if err != nil {
+		return err
+	}
+
+	stream, err := c.Client.ImagePush(ctx, ref, types.ImagePushOptions{RegistryAuth: registryAuth})
+	if err != nil {
+		return err
+	}
+	return followImageProgress(ctx, stream, onProgress)
+}
+
+func pushRegistryAuth(ref string, credentials *RegistryCredentials) (string, error) {
+	if credentials == nil {
+		registryAuth, err := registryAuthFor(ref, dockerConfigDir(), runCredentialHelper)
+		if err != nil || registryAuth != "" {
+			return registryAuth, err
+		}
+		// some daemons turn down a push without the header at all, so like
+		// docker push we send empty credentials, leaving it to the registry
+		// to say if it wants some
+		return encodeRegistryAuth(types.AuthConfig{})
+	}
+
+	_, serverAddress, err := registryFor(ref)
+	if err != nil {
+		return "", err
+	}
+	return encodeRegistryAuth(types.AuthConfig{
+		Username:      credentials.Username,
+		Password:      credentials.Password,
+		ServerAddress: serverAddress,
+	})
+}
+
+// IsRegistryAuthError tells us whether a push or pull failed because the
+// registry wants credentials we haven't got, or turned down the ones we have,
+// e.g. "denied: requested access to the resource is denied"
+func IsRegistryAuthError(err error) bool {
+	if err == nil {
+		return false
+	}
+	message := strings.ToLower(err.Error())
+	for _, substr := range []string{"denied:", "unauthorized:", "authentication required", "no basic auth credentials"} {
+		if strings.Contains(message, substr) {
+			return true
+		}
+	}
+	return false
+}
+
+// RegistryDomain is the registry ref is pushed to or pulled from, e.g.
+// docker.io
+func RegistryDomain(ref string) string {
+	domain, _, err := registryFor(ref)
+	if err != nil {
+		return ref
+	}
+	return domain
+}
@@ -0,0 +1,102 @@
+package commands
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDockerCommandPushImage(t *testing.T) {
+	type scenario struct {
+		testName            string
+		credentials         *RegistryCredentials
+		body                string
+		expectedAuth        types.AuthConfig
+		expectedErrorSubstr string
+		expectedRendered    string
+	}
+
+	scenarios := []scenario{
+		{
+			testName: "Successful push",
+			body: `{"status": "The push refers to repository [registry.example.com/web]"}` + "\n" +
+				`{"status": "Preparing", "id": "aaa"}` + "\n" +
+				`{"status": "Pushed", "id": "aaa"}` + "\n" +
+				`{"status": "v2: digest: sha256:abc size: 528"}` + "\n" +
+				`{"progressDetail": {}, "aux": {"Tag": "v2", "Digest": "sha256:abc", "Size": 528}}`,
+			expectedRendered: "The push refers to repository [registry.example.com/web]\naaa: Pushed\nv2: digest: sha256:abc size: 528",
+		},
+		{
+			testName:    "Pushing with credentials typed in",
+			credentials: &RegistryCredentials{Username: "jesse", Password: "hunter2"},
+			body:        `{"status": "v2: digest: sha256:abc size: 528"}`,
+			expectedAuth: types.AuthConfig{
+				Username:      "jesse",
+				Password:      "hunter2",
+				ServerAddress: "registry.example.com",
+			},
+			expectedRendered: "v2: digest: sha256:abc size: 528",
+		},
+		{
+			testName:            "Turned down by the registry",
+			body:                `{"status": "The push refers to repository [registry.example.com/web]"}` + "\n" + `{"error": "denied: requested access to the resource is denied"}`,
+			expectedErrorSubstr: "denied: requested access to the resource is denied",
+			expectedRendered:    "The push refers to repository [registry.example.com/web]",
+		},
+	}
+
+	// no credentials to find
+	defer os.Setenv("DOCKER_CONFIG", os.Getenv("DOCKER_CONFIG"))
+	os.Setenv("DOCKER_CONFIG", t.Name())
+
+	for _, s := range scenarios {
+		s := s
+		t.Run(s.testName, func(t *testing.T) {
+			dockerCommand := newFakeDaemonCommand(t, func(w http.ResponseWriter, r *http.Request) {
+				assert.True(t, strings.HasSuffix(r.URL.Path, "/images/registry.example.com/web/push"))
+				assert.Equal(t, "v2", r.URL.Query().Get("tag"))
+
+				decoded, err := base64.URLEncoding.DecodeString(r.Header.Get("X-Registry-Auth"))
+				assert.NoError(t, err)
+				auth := types.AuthConfig{}
+				assert.NoError(t, json.Unmarshal(decoded, &auth))
+				assert.Equal(t, s.expectedAuth, auth)
+
+				_, _ = w.Write([]byte(s.body))
+			})
+
+			rendered := ""
+			err := dockerCommand.PushImage(context.Background(), "registry.example.com/web:v2", s.credentials, func(progress *ImagePullProgress) {
+				rendered = progress.Render()
+			})
+			if s.expectedErrorSubstr != "" {
+				if assert.Error(t, err) {
+					assert.Contains(t, err.Error(), s.expectedErrorSubstr)
+				}
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, s.expectedRendered, rendered)
+		})
+	}
+}
+
+func TestIsRegistryAuthError(t *testing.T) {
+	assert.True(t, IsRegistryAuthError(errors.New("denied: requested access to the resource is denied")))
+	assert.True(t, IsRegistryAuthError(errors.New("unauthorized: authentication required")))
+	assert.False(t, IsRegistryAuthError(errors.New("manifest unknown")))
+	assert.False(t, IsRegistryAuthError(nil))
+}
+
+func TestRegistryDomain(t *testing.T) {
+	assert.Equal(t, "docker.io", RegistryDomain("alpine:latest"))
+	assert.Equal(t, "registry.example.com:5000", RegistryDomain("registry.example.com:5000/web:v2"))
+}
@@ -0,0 +1,70 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/docker/distribution/reference"
+	"github.com/docker/docker/api/types"
+)
+
+// NormaliseImageTag checks that a tag typed in is one docker would take, and
+// returns it as docker shows it, e.g. `web:latest` for `web` or
+// `docker.io/library/web`
+func NormaliseImageTag(tag string) (string, error) {
+	named, err := reference.ParseNormalizedNamed(tag)
+	if err != nil {
+		return "", fmt.Errorf("%q isn't a valid tag: %w", tag, err)
+	}
+	if _, ok := named.(reference.Digested); ok {
+		return "", fmt.Errorf("%q isn't a valid tag: images can't be tagged with a digest", tag)
+	}
+	return reference.FamiliarString(reference.TagNameOnly(named)), nil
+}
+
+// ImageWithTag returns the image the tag is on, or nil if it's on none of them.
+// The tag should be normalised first.
+func (c *DockerCommand) ImageWithTag(tag string) *Image {
+	for _, image := range c.Images {
+		for _, repoTag := range image.Image.RepoTags {
+			if repoTag == tag {
+				return image
+			}
+		}
+	}
+	return nil
+}
+
+// AddTag tags the image, like docker tag. If the tag is already on another
+// image, it's moved to this one.
+func (i *Image) AddTag(tag string) error {
+	return i.Client.ImageTag(context.Background(), i.ID, tag)
+}
+
+// RemoveTag takes the tag off the image, like docker rmi does given a tag. We
+// won't take off the image's last tag, as the daemon would remove the image
+// along with it.
+func (i *Image) RemoveTag(tag string) error {
+	tags := i.Tags()
+	if !containsString(tags, tag) {
+		return fmt.Errorf("%s isn't a tag of this image", tag)
+	}
+	if len(tags) == 1 {
+		return fmt.Errorf("%s is the image's only tag, and removing it would remove the image", tag)
+	}
+
+	_, err := i.Client.ImageRemove(context.Background(), tag, types.ImageRemoveOptions{})
+	return err
+}
+
+// Tags returns the image's tags, leaving out the placeholder docker gives
+// untagged images
+func (i *Image) Tags() []string {
+	tags := []string{}
+	for _, tag := range i.Image.RepoTags {
+		if tag != "<none>:<none>" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
@@ -0,0 +1,124 @@
+package commands
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormaliseImageTag(t *testing.T) {
+	type scenario struct {
+		testName string
+		tag      string
+		expected string
+		err      string
+	}
+
+	scenarios := []scenario{
+		{
+			testName: "A tag without a version gets latest",
+			tag:      "web",
+			expected: "web:latest",
+		},
+		{
+			testName: "Docker hub names are shortened",
+			tag:      "docker.io/library/web:v2",
+			expected: "web:v2",
+		},
+		{
+			testName: "A tag for another registry",
+			tag:      "registry.example.com:5000/team/web:v2",
+			expected: "registry.example.com:5000/team/web:v2",
+		},
+		{
+			testName: "Upper case isn't allowed",
+			tag:      "Web:v2",
+			err:      `"Web:v2" isn't a valid tag`,
+		},
+		{
+			testName: "Nor are digests",
+			tag:      "web@sha256:" + "0123456789012345678901234567890123456789012345678901234567890123",
+			err:      "images can't be tagged with a digest",
+		},
+	}
+
+	for _, s := range scenarios {
+		s := s
+		t.Run(s.testName, func(t *testing.T) {
+			tag, err := NormaliseImageTag(s.tag)
+			if s.err != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), s.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, s.expected, tag)
+		})
+	}
+}
+
+func TestDockerCommandImageWithTag(t *testing.T) {
+	web := &Image{ID: "1", Image: types.ImageSummary{RepoTags: []string{"web:latest", "web:v2"}}}
+	db := &Image{ID: "2", Image: types.ImageSummary{RepoTags: []string{"db:latest"}}}
+	dockerCommand := &DockerCommand{Images: []*Image{web, db}}
+
+	assert.Equal(t, web, dockerCommand.ImageWithTag("web:v2"))
+	assert.Equal(t, db, dockerCommand.ImageWithTag("db:latest"))
+	assert.Nil(t, dockerCommand.ImageWithTag("web:v3"))
+}
+
+func TestImageRemoveTag(t *testing.T) {
+	removed := make(chan string, 1)
+	dockerCommand := newFakeDaemonCommand(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete {
+			http.NotFound(w, r)
+			return
+		}
+		removed <- r.URL.Path
+		_, _ = w.Write([]byte(`[{"Untagged": "web:v2"}]`))
+	})
+
+	type scenario struct {
+		testName string
+		tags     []string
+		tag      string
+		err      string
+	}
+
+	scenarios := []scenario{
+		{
+			testName: "Removing one of several tags",
+			tags:     []string{"web:latest", "web:v2"},
+			tag:      "web:v2",
+		},
+		{
+			testName: "Removing the only tag",
+			tags:     []string{"web:latest"},
+			tag:      "web:latest",
+			err:      "web:latest is the image's only tag, and removing it would remove the image",
+		},
+		{
+			testName: "Removing a tag the image hasn't got",
+			tags:     []string{"web:latest", "web:v2"},
+			tag:      "db:latest",
+			err:      "db:latest isn't a tag of this image",
+		},
+	}
+
+	for _, s := range scenarios {
+		s := s
+		t.Run(s.testName, func(t *testing.T) {
+			image := &Image{ID: "sha256:abc", Client: dockerCommand.Client, Image: types.ImageSummary{RepoTags: s.tags}}
+			err := image.RemoveTag(s.tag)
+			if s.err != "" {
+				assert.EqualError(t, err, s.err)
+				return
+			}
+			assert.NoError(t, err)
+			// it's the tag that's removed, not the image
+			assert.Equal(t, "/v"+APIVersion+"/images/"+s.tag, <-removed)
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package commands
+
+import (
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types/image"
+	"github.com/jesseduffield/lazydocker/pkg/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewLayers(t *testing.T) {
+	type scenario struct {
+		testName string
+		history  []image.HistoryResponseItem
+		expected []int
+	}
+
+	scenarios := []scenario{
+		{
+			testName: "Shares of the image's size",
+			history:  []image.HistoryResponseItem{{Size: 750}, {Size: 0}, {Size: 250}},
+			expected: []int{75, 0, 25},
+		},
+		{
+			testName: "An image with nothing in it",
+			history:  []image.HistoryResponseItem{{Size: 0}},
+			expected: []int{0},
+		},
+	}
+
+	for _, s := range scenarios {
+		s := s
+		t.Run(s.testName, func(t *testing.T) {
+			shares := []int{}
+			for _, layer := range newLayers(s.history) {
+				shares = append(shares, layer.Share)
+			}
+			assert.Equal(t, s.expected, shares)
+		})
+	}
+}
+
+func TestLayerGetDisplayStrings(t *testing.T) {
+	layer := &Layer{
+		HistoryResponseItem: image.HistoryResponseItem{
+			ID:        "<missing>",
+			Size:      2048,
+			Created:   time.Now().Add(-3 * 24 * time.Hour).Unix(),
+			CreatedBy: "/bin/sh -c #(nop) COPY dir:abc in /app",
+		},
+		Share: 40,
+	}
+
+	displayStrings := layer.GetDisplayStrings(false)
+	for i, displayString := range displayStrings {
+		displayStrings[i] = utils.Decolorise(displayString)
+	}
+	assert.Equal(t, []string{"<missing>", "", "2.00kiB", "40%", "3 days ago", "COPY dir:abc in /app"}, displayStrings)
+}
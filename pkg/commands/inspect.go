@@ -0,0 +1,102 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/fatih/color"
+	"github.com/jesseduffield/lazydocker/pkg/utils"
+)
+
+// FormatInspectJSON pretty-prints what one of docker's inspect endpoints gave
+// us. Keys come out sorted, so two inspects of the same object only differ
+// where the object itself has.
+func FormatInspectJSON(raw []byte) (string, error) {
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	// so that big numbers like sizes don't turn into floats on the way through
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return "", err
+	}
+
+	buf := &bytes.Buffer{}
+	encoder := json.NewEncoder(buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(value); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+// HighlightJSON colours JSON for the main panel: keys, strings, numbers, and
+// true/false/null each get their own colour
+func HighlightJSON(s string) string {
+	out := &strings.Builder{}
+	for i := 0; i < len(s); {
+		switch ch := s[i]; {
+		case ch == '"':
+			end := i + 1
+			for end < len(s) && s[end] != '"' {
+				if s[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end < len(s) {
+				end++
+			}
+			clr := color.FgGreen
+			if strings.HasPrefix(strings.TrimLeft(s[end:], " "), ":") {
+				clr = color.FgCyan
+			}
+			out.WriteString(utils.ColoredString(s[i:end], clr))
+			i = end
+		case ch == '-' || (ch >= '0' && ch <= '9'):
+			end := i + 1
+			for end < len(s) && strings.IndexByte("0123456789.eE+-", s[end]) != -1 {
+				end++
+			}
+			out.WriteString(utils.ColoredString(s[i:end], color.FgYellow))
+			i = end
+		case strings.HasPrefix(s[i:], "true"), strings.HasPrefix(s[i:], "null"):
+			out.WriteString(utils.ColoredString(s[i:i+4], color.FgMagenta))
+			i += 4
+		case strings.HasPrefix(s[i:], "false"):
+			out.WriteString(utils.ColoredString(s[i:i+5], color.FgMagenta))
+			i += 5
+		default:
+			out.WriteByte(ch)
+			i++
+		}
+	}
+	return out.String()
+}
+
+// InspectRaw returns the container's inspect output as the API sent it
+func (c *Container) InspectRaw() ([]byte, error) {
+	_, raw, err := c.Client.ContainerInspectWithRaw(context.Background(), c.ID, false)
+	return raw, err
+}
+
+// InspectRaw returns the image's inspect output as the API sent it
+func (i *Image) InspectRaw() ([]byte, error) {
+	_, raw, err := i.Client.ImageInspectWithRaw(context.Background(), i.ID)
+	return raw, err
+}
+
+// InspectRaw returns the volume's inspect output as the API sent it
+func (v *Volume) InspectRaw() ([]byte, error) {
+	_, raw, err := v.Client.VolumeInspectWithRaw(context.Background(), v.Name)
+	return raw, err
+}
+
+// InspectRaw returns the network's inspect output as the API sent it
+func (n *Network) InspectRaw() ([]byte, error) {
+	_, raw, err := n.Client.NetworkInspectWithRaw(context.Background(), n.ID, types.NetworkInspectOptions{})
+	return raw, err
+}
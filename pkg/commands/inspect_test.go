@@ -0,0 +1,69 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/jesseduffield/lazydocker/pkg/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatInspectJSON(t *testing.T) {
+	type scenario struct {
+		testName            string
+		raw                 string
+		expected            string
+		expectedErrorSubstr string
+	}
+
+	scenarios := []scenario{
+		{
+			testName: "Keys are sorted",
+			raw:      `{"b": 1, "a": {"d": true, "c": null}}`,
+			expected: "{\n  \"a\": {\n    \"c\": null,\n    \"d\": true\n  },\n  \"b\": 1\n}",
+		},
+		{
+			testName: "Big numbers stay as they are",
+			raw:      `{"Size": 123456789012345678}`,
+			expected: "{\n  \"Size\": 123456789012345678\n}",
+		},
+		{
+			testName: "No escaping of html characters",
+			raw:      `{"Cmd": ["sh", "-c", "a && b > c"]}`,
+			expected: "{\n  \"Cmd\": [\n    \"sh\",\n    \"-c\",\n    \"a && b > c\"\n  ]\n}",
+		},
+		{
+			testName:            "Not JSON",
+			raw:                 `Error: no such container`,
+			expectedErrorSubstr: "invalid character",
+		},
+	}
+
+	for _, s := range scenarios {
+		s := s
+		t.Run(s.testName, func(t *testing.T) {
+			formatted, err := FormatInspectJSON([]byte(s.raw))
+			if s.expectedErrorSubstr != "" {
+				if assert.Error(t, err) {
+					assert.Contains(t, err.Error(), s.expectedErrorSubstr)
+				}
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, s.expected, formatted)
+		})
+	}
+}
+
+func TestHighlightJSON(t *testing.T) {
+	formatted := "{\n  \"Name\": \"web \\\"1\\\"\",\n  \"Ports\": [80, -1.5e3],\n  \"Running\": false,\n  \"Health\": null\n}"
+	highlighted := HighlightJSON(formatted)
+
+	// highlighting only adds colour
+	assert.Equal(t, formatted, utils.Decolorise(highlighted))
+	assert.Contains(t, highlighted, utils.ColoredString(`"Name"`, color.FgCyan))
+	assert.Contains(t, highlighted, utils.ColoredString(`"web \"1\""`, color.FgGreen))
+	assert.Contains(t, highlighted, utils.ColoredString("-1.5e3", color.FgYellow))
+	assert.Contains(t, highlighted, utils.ColoredString("false", color.FgMagenta))
+	assert.Contains(t, highlighted, utils.ColoredString("null", color.FgMagenta))
+}
@@ -0,0 +1,39 @@
+package commands
+
+import "sort"
+
+// Label is one of the labels on a container or image
+type Label struct {
+	Key   string
+	Value string
+}
+
+// String is the label as docker run --label takes it
+func (l Label) String() string {
+	return l.Key + "=" + l.Value
+}
+
+// SortedLabels returns the labels sorted by key, so that they're listed the
+// same way every time
+func SortedLabels(labels map[string]string) []Label {
+	sorted := make([]Label, 0, len(labels))
+	for key, value := range labels {
+		sorted = append(sorted, Label{Key: key, Value: value})
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Key < sorted[j].Key
+	})
+	return sorted
+}
+
+// Labels returns the container's labels. Docker can't change them once the
+// container's been created, so there's no setting them.
+func (c *Container) Labels() []Label {
+	return SortedLabels(c.Container.Labels)
+}
+
+// Labels returns the image's labels, which come from the LABEL instructions
+// it was built with
+func (i *Image) Labels() []Label {
+	return SortedLabels(i.Image.Labels)
+}
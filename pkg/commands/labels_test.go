@@ -0,0 +1,46 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContainerLabels(t *testing.T) {
+	type scenario struct {
+		testName string
+		labels   map[string]string
+		expected []Label
+	}
+
+	scenarios := []scenario{
+		{
+			testName: "No labels",
+			labels:   nil,
+			expected: []Label{},
+		},
+		{
+			testName: "Sorted by key",
+			labels:   map[string]string{"com.docker.compose.service": "web", "com.docker.compose.project": "shop", "maintainer": ""},
+			expected: []Label{
+				{Key: "com.docker.compose.project", Value: "shop"},
+				{Key: "com.docker.compose.service", Value: "web"},
+				{Key: "maintainer", Value: ""},
+			},
+		},
+	}
+
+	for _, s := range scenarios {
+		s := s
+		t.Run(s.testName, func(t *testing.T) {
+			container := &Container{Container: types.Container{Labels: s.labels}}
+			assert.Equal(t, s.expected, container.Labels())
+		})
+	}
+}
+
+func TestLabelString(t *testing.T) {
+	assert.Equal(t, "tier=backend", Label{Key: "tier", Value: "backend"}.String())
+	assert.Equal(t, "maintainer=", Label{Key: "maintainer"}.String())
+}
@@ -0,0 +1,210 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/jesseduffield/lazydocker/pkg/utils"
+)
+
+// ListKinds are what `lazydocker ls` can list
+var ListKinds = []string{"containers", "images", "volumes", "networks"}
+
+// Listing is a list of containers, images, volumes or networks, ready for
+// printing from a script
+type Listing struct {
+	// Items are what we print as JSON
+	Items interface{}
+	// Headers and Rows are what we print as a table
+	Headers []string
+	Rows    [][]string
+}
+
+// ListedContainer is what `lazydocker ls containers` prints about a container
+type ListedContainer struct {
+	ID      string    `json:"id"`
+	Name    string    `json:"name"`
+	Image   string    `json:"image"`
+	State   string    `json:"state"`
+	Status  string    `json:"status"`
+	Service string    `json:"service,omitempty"`
+	Project string    `json:"project,omitempty"`
+	Created time.Time `json:"created"`
+}
+
+// ListedImage is what `lazydocker ls images` prints about an image
+type ListedImage struct {
+	ID      string    `json:"id"`
+	Name    string    `json:"name"`
+	Tag     string    `json:"tag"`
+	Size    int64     `json:"size"`
+	Created time.Time `json:"created"`
+}
+
+// ListedVolume is what `lazydocker ls volumes` prints about a volume
+type ListedVolume struct {
+	Name       string `json:"name"`
+	Driver     string `json:"driver"`
+	Mountpoint string `json:"mountpoint"`
+}
+
+// ListedNetwork is what `lazydocker ls networks` prints about a network
+type ListedNetwork struct {
+	ID         string   `json:"id"`
+	Name       string   `json:"name"`
+	Driver     string   `json:"driver"`
+	Containers []string `json:"containers"`
+}
+
+// List fetches the containers, images, volumes or networks the same way the
+// gui does, for printing rather than showing
+func (c *DockerCommand) List(kind string) (*Listing, error) {
+	switch kind {
+	case "containers":
+		return c.listContainersForPrinting()
+	case "images":
+		return c.listImagesForPrinting()
+	case "volumes":
+		return c.listVolumesForPrinting()
+	case "networks":
+		return c.listNetworksForPrinting()
+	}
+	return nil, fmt.Errorf("can't list %q, only %s", kind, strings.Join(ListKinds, ", "))
+}
+
+func (c *DockerCommand) listContainersForPrinting() (*Listing, error) {
+	containers, err := c.GetContainers()
+	if err != nil {
+		return nil, err
+	}
+	sortContainers(containers, SortOrder{Field: SortByStatus}, nil)
+
+	items := make([]ListedContainer, len(containers))
+	rows := make([][]string, len(containers))
+	for i, container := range containers {
+		items[i] = ListedContainer{
+			ID:      container.ID,
+			Name:    container.Name,
+			Image:   container.Container.Image,
+			State:   container.Container.State,
+			Status:  container.Container.Status,
+			Service: container.ServiceName,
+			Project: container.ProjectName,
+			Created: time.Unix(container.Container.Created, 0).UTC(),
+		}
+		rows[i] = []string{ShortID(container.ID), container.Name, container.Container.Image, container.Container.Status}
+	}
+
+	return &Listing{
+		Items:   items,
+		Headers: []string{"ID", "NAME", "IMAGE", "STATUS"},
+		Rows:    rows,
+	}, nil
+}
+
+func (c *DockerCommand) listImagesForPrinting() (*Listing, error) {
+	images, err := c.RefreshImages()
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]ListedImage, len(images))
+	rows := make([][]string, len(images))
+	for i, image := range images {
+		items[i] = ListedImage{
+			ID:      image.ID,
+			Name:    image.Name,
+			Tag:     image.Tag,
+			Size:    image.Image.Size,
+			Created: time.Unix(image.Image.Created, 0).UTC(),
+		}
+		rows[i] = []string{ShortID(image.ID), image.Name, image.Tag, utils.FormatDecimalBytes(int(image.Image.Size))}
+	}
+
+	return &Listing{
+		Items:   items,
+		Headers: []string{"ID", "NAME", "TAG", "SIZE"},
+		Rows:    rows,
+	}, nil
+}
+
+func (c *DockerCommand) listVolumesForPrinting() (*Listing, error) {
+	if err := c.RefreshVolumes(); err != nil {
+		return nil, err
+	}
+
+	items := make([]ListedVolume, len(c.Volumes))
+	rows := make([][]string, len(c.Volumes))
+	for i, volume := range c.Volumes {
+		items[i] = ListedVolume{
+			Name:       volume.Name,
+			Driver:     volume.Volume.Driver,
+			Mountpoint: volume.Volume.Mountpoint,
+		}
+		rows[i] = []string{volume.Volume.Driver, volume.Name}
+	}
+
+	return &Listing{
+		Items:   items,
+		Headers: []string{"DRIVER", "NAME"},
+		Rows:    rows,
+	}, nil
+}
+
+func (c *DockerCommand) listNetworksForPrinting() (*Listing, error) {
+	// the networks only know which of our containers are on them
+	containers, err := c.GetContainers()
+	if err != nil {
+		return nil, err
+	}
+	c.ContainerMutex.Lock()
+	c.Containers = containers
+	c.ContainerMutex.Unlock()
+
+	if err := c.RefreshNetworks(); err != nil {
+		return nil, err
+	}
+
+	items := make([]ListedNetwork, len(c.Networks))
+	rows := make([][]string, len(c.Networks))
+	for i, network := range c.Networks {
+		containerNames := make([]string, len(network.Attachments))
+		for j, attachment := range network.Attachments {
+			containerNames[j] = attachment.Container.Name
+		}
+		items[i] = ListedNetwork{
+			ID:         network.ID,
+			Name:       network.Name,
+			Driver:     network.Network.Driver,
+			Containers: containerNames,
+		}
+		rows[i] = []string{ShortID(network.ID), network.Name, network.Network.Driver, fmt.Sprintf("%d", len(containerNames))}
+	}
+
+	return &Listing{
+		Items:   items,
+		Headers: []string{"ID", "NAME", "DRIVER", "CONTAINERS"},
+		Rows:    rows,
+	}, nil
+}
+
+// WriteJSON writes the listing as a JSON array
+func (l *Listing) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(l.Items)
+}
+
+// WriteTable writes the listing as a table like the docker cli's
+func (l *Listing) WriteTable(w io.Writer) error {
+	table := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(table, strings.Join(l.Headers, "\t"))
+	for _, row := range l.Rows {
+		fmt.Fprintln(table, strings.Join(row, "\t"))
+	}
+	return table.Flush()
+}
@@ -0,0 +1,85 @@
+package commands
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDockerCommandList(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/images/json"):
+			_, _ = w.Write([]byte(`[{"Id": "sha256:0123456789abcdef", "RepoTags": ["redis:7"], "Size": 1500000, "Created": 0}]`))
+		case strings.HasSuffix(r.URL.Path, "/volumes"):
+			_, _ = w.Write([]byte(`{"Volumes": [{"Name": "pgdata", "Driver": "local", "Mountpoint": "/var/lib/docker/volumes/pgdata/_data"}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	type scenario struct {
+		testName string
+		kind     string
+		asJSON   bool
+		expected string
+	}
+
+	scenarios := []scenario{
+		{
+			testName: "Images as a table",
+			kind:     "images",
+			expected: "ID             NAME    TAG   SIZE\n" +
+				"0123456789ab   redis   7     1.50MB\n",
+		},
+		{
+			testName: "Images as JSON",
+			kind:     "images",
+			asJSON:   true,
+			expected: `[
+  {
+    "id": "sha256:0123456789abcdef",
+    "name": "redis",
+    "tag": "7",
+    "size": 1500000,
+    "created": "1970-01-01T00:00:00Z"
+  }
+]
+`,
+		},
+		{
+			testName: "Volumes as a table",
+			kind:     "volumes",
+			expected: "DRIVER   NAME\n" +
+				"local    pgdata\n",
+		},
+	}
+
+	for _, s := range scenarios {
+		s := s
+		t.Run(s.testName, func(t *testing.T) {
+			dockerCommand := newDockerContextTestCommand(t, "tcp://"+server.Listener.Addr().String())
+			listing, err := dockerCommand.List(s.kind)
+			assert.NoError(t, err)
+
+			var buf bytes.Buffer
+			if s.asJSON {
+				assert.NoError(t, listing.WriteJSON(&buf))
+			} else {
+				assert.NoError(t, listing.WriteTable(&buf))
+			}
+			assert.Equal(t, s.expected, buf.String())
+		})
+	}
+}
+
+func TestDockerCommandListSomethingElse(t *testing.T) {
+	dockerCommand := newDockerContextTestCommand(t, "tcp://127.0.0.1:1")
+	_, err := dockerCommand.List("secrets")
+	assert.EqualError(t, err, `can't list "secrets", only containers, images, volumes, networks`)
+}
@@ -0,0 +1,189 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// mergedLogsRestartCheckInterval is how often we check whether a container
+// whose logs have ended has been started again
+const mergedLogsRestartCheckInterval = time.Second
+
+// MergedLogLine is a line of logs from one of the containers MergedLogs follows
+type MergedLogLine struct {
+	Container *Container
+	Text      string
+}
+
+// MergedLogs follows the logs of several containers at once, like `docker
+// compose logs`, keeping their lines in the order they arrive. Only the most
+// recent maxLines are kept. Lines from containers that have been hidden are
+// still kept, so that showing them again brings them back.
+type MergedLogs struct {
+	Containers []*Container
+
+	maxLines int
+	mutex    sync.Mutex
+	lines    []MergedLogLine
+	hidden   map[string]bool
+	// changed gets a value when there's something new to show, holding just
+	// the one so that a burst of lines makes for one re-render
+	changed chan struct{}
+}
+
+// NewMergedLogs returns a MergedLogs for the given containers, which starts
+// following them once Follow is called
+func NewMergedLogs(containers []*Container, maxLines int) *MergedLogs {
+	return &MergedLogs{
+		Containers: containers,
+		maxLines:   maxLines,
+		hidden:     map[string]bool{},
+		changed:    make(chan struct{}, 1),
+	}
+}
+
+// Follow streams each container's logs into the merged logs until ctx is
+// cancelled. since is as for StreamLogs. When a container stops, we wait for
+// it to be started again and carry on from there, and if it goes away
+// altogether we stop following it.
+func (m *MergedLogs) Follow(ctx context.Context, since string) {
+	wg := sync.WaitGroup{}
+	for _, container := range m.Containers {
+		container := container
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			m.follow(ctx, container, since)
+		}()
+	}
+	wg.Wait()
+}
+
+func (m *MergedLogs) follow(ctx context.Context, container *Container, since string) {
+	for {
+		w := &mergedLogWriter{logs: m, container: container}
+		err := container.StreamLogs(ctx, since, w)
+		w.flush()
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			container.Log.Warn(err)
+		}
+
+		// carrying on from here when it's back, rather than from the start
+		ended := time.Now().UnixNano()
+		since = fmt.Sprintf("%d.%09d", ended/int64(time.Second), ended%int64(time.Second))
+		if !waitForContainerStart(ctx, container) {
+			return
+		}
+	}
+}
+
+// waitForContainerStart waits for the container to be running, returning false
+// if ctx is cancelled first or the container's gone
+func waitForContainerStart(ctx context.Context, container *Container) bool {
+	ticker := time.NewTicker(mergedLogsRestartCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-ticker.C:
+			details, err := container.Client.ContainerInspect(ctx, container.ID)
+			if err != nil {
+				return false
+			}
+			if details.State != nil && details.State.Running {
+				return true
+			}
+		}
+	}
+}
+
+func (m *MergedLogs) add(container *Container, text string) {
+	m.mutex.Lock()
+	m.lines = append(m.lines, MergedLogLine{Container: container, Text: text})
+	if len(m.lines) > m.maxLines {
+		// dropping the oldest
+		m.lines = m.lines[len(m.lines)-m.maxLines:]
+	}
+	m.mutex.Unlock()
+	m.notify()
+}
+
+func (m *MergedLogs) notify() {
+	select {
+	case m.changed <- struct{}{}:
+	default:
+	}
+}
+
+// Lines returns the lines from the containers that aren't hidden, oldest first
+func (m *MergedLogs) Lines() []MergedLogLine {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	lines := make([]MergedLogLine, 0, len(m.lines))
+	for _, line := range m.lines {
+		if !m.hidden[line.Container.ID] {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// ToggleHidden hides the container's lines, or shows them again if they're
+// hidden, and returns whether they're hidden now
+func (m *MergedLogs) ToggleHidden(container *Container) bool {
+	m.mutex.Lock()
+	hidden := !m.hidden[container.ID]
+	m.hidden[container.ID] = hidden
+	m.mutex.Unlock()
+	m.notify()
+	return hidden
+}
+
+// IsHidden tells us whether the container's lines are hidden
+func (m *MergedLogs) IsHidden(container *Container) bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.hidden[container.ID]
+}
+
+// Changed gets a value when there are new lines or a container's been hidden
+// or shown
+func (m *MergedLogs) Changed() <-chan struct{} {
+	return m.changed
+}
+
+// mergedLogWriter splits what a container's log stream writes into lines, so
+// that lines from different containers don't get mixed up mid-line
+type mergedLogWriter struct {
+	logs      *MergedLogs
+	container *Container
+	partial   []byte
+}
+
+func (w *mergedLogWriter) Write(p []byte) (int, error) {
+	w.partial = append(w.partial, p...)
+	for {
+		i := bytes.IndexByte(w.partial, '\n')
+		if i == -1 {
+			break
+		}
+		w.logs.add(w.container, string(bytes.TrimRight(w.partial[:i], "\r")))
+		w.partial = w.partial[i+1:]
+	}
+	return len(p), nil
+}
+
+// flush adds whatever's left over once the stream has ended
+func (w *mergedLogWriter) flush() {
+	if len(w.partial) > 0 {
+		w.logs.add(w.container, string(w.partial))
+		w.partial = nil
+	}
+}
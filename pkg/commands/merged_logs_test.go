@@ -0,0 +1,122 @@
+package commands
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func mergedLogTexts(lines []MergedLogLine) []string {
+	texts := make([]string, len(lines))
+	for i, line := range lines {
+		texts[i] = line.Container.Name + ": " + line.Text
+	}
+	return texts
+}
+
+func TestMergedLogs(t *testing.T) {
+	web := &Container{ID: "1", Name: "web"}
+	db := &Container{ID: "2", Name: "db"}
+
+	type scenario struct {
+		testName string
+		maxLines int
+		hide     []*Container
+		expected []string
+	}
+
+	scenarios := []scenario{
+		{
+			testName: "Lines are kept in the order they arrive",
+			maxLines: 10,
+			expected: []string{"web: one", "db: two", "web: three", "db: four"},
+		},
+		{
+			testName: "The oldest lines are dropped past the cap",
+			maxLines: 2,
+			expected: []string{"web: three", "db: four"},
+		},
+		{
+			testName: "Hidden containers' lines are left out",
+			maxLines: 10,
+			hide:     []*Container{db},
+			expected: []string{"web: one", "web: three"},
+		},
+		{
+			testName: "Hiding twice shows them again",
+			maxLines: 10,
+			hide:     []*Container{db, db},
+			expected: []string{"web: one", "db: two", "web: three", "db: four"},
+		},
+	}
+
+	for _, s := range scenarios {
+		s := s
+		t.Run(s.testName, func(t *testing.T) {
+			logs := NewMergedLogs([]*Container{web, db}, s.maxLines)
+			webWriter := &mergedLogWriter{logs: logs, container: web}
+			dbWriter := &mergedLogWriter{logs: logs, container: db}
+
+			// a line split across writes is only added once it's whole
+			_, _ = webWriter.Write([]byte("one\nthr"))
+			_, _ = dbWriter.Write([]byte("two\r\n"))
+			_, _ = webWriter.Write([]byte("ee\n"))
+			_, _ = dbWriter.Write([]byte("four"))
+			dbWriter.flush()
+
+			for _, container := range s.hide {
+				logs.ToggleHidden(container)
+			}
+			assert.Equal(t, s.expected, mergedLogTexts(logs.Lines()))
+		})
+	}
+}
+
+func TestMergedLogsFollow(t *testing.T) {
+	dockerCommand := newFakeDaemonCommand(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/containers/web/logs"):
+			_, _ = w.Write([]byte("web line\n"))
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+		case strings.HasSuffix(r.URL.Path, "/containers/job/logs"):
+			// the job's finished, and it's gone by the time we check on it
+			_, _ = w.Write([]byte("job line\n"))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+
+	containers := []*Container{}
+	for _, name := range []string{"web", "job"} {
+		container := &Container{ID: name, Name: name, Client: dockerCommand.Client, Log: NewDummyLog()}
+		container.Details.Image = "alpine"
+		container.Details.Config.Tty = true
+		containers = append(containers, container)
+	}
+	logs := NewMergedLogs(containers, 10)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		logs.Follow(ctx, "")
+		close(done)
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(logs.Lines()) < 2 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.ElementsMatch(t, []string{"web: web line", "job: job line"}, mergedLogTexts(logs.Lines()))
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected cancelling to stop following")
+	}
+}
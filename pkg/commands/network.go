@@ -0,0 +1,126 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/client"
+	"github.com/fatih/color"
+	"github.com/jesseduffield/lazydocker/pkg/utils"
+	"github.com/sirupsen/logrus"
+)
+
+// Network : A docker Network
+type Network struct {
+	Name    string
+	ID      string
+	Network types.NetworkResource
+	// Attachments are the containers on the network, as of when the networks
+	// were last refreshed
+	Attachments   []*NetworkAttachment
+	Client        *client.Client
+	OSCommand     *OSCommand
+	Log           *logrus.Entry
+	DockerCommand LimitedDockerCommand
+}
+
+// NetworkAttachment is a container's place on a network
+type NetworkAttachment struct {
+	Container *Container
+	Endpoint  *network.EndpointSettings
+}
+
+// GetDisplayStrings returns the display string of Network
+func (n *Network) GetDisplayStrings(isFocused bool) []string {
+	attached := ""
+	if len(n.Attachments) > 0 {
+		attached = utils.ColoredString(fmt.Sprintf("%d", len(n.Attachments)), color.FgBlue)
+	}
+	return []string{n.Network.Driver, n.Name, attached}
+}
+
+// RefreshNetworks gets the networks and stores them, along with which of our
+// containers are on each of them
+func (c *DockerCommand) RefreshNetworks() error {
+	networks, err := c.Client.NetworkList(context.Background(), types.NetworkListOptions{})
+	if err != nil {
+		return err
+	}
+
+	sort.Slice(networks, func(i, j int) bool {
+		return networks[i].Name < networks[j].Name
+	})
+
+	c.ContainerMutex.Lock()
+	containers := c.Containers
+	c.ContainerMutex.Unlock()
+
+	ownNetworks := make([]*Network, len(networks))
+	for i, resource := range networks {
+		ownNetworks[i] = &Network{
+			Name:          resource.Name,
+			ID:            resource.ID,
+			Network:       resource,
+			Attachments:   networkAttachments(resource.Name, containers),
+			Client:        c.Client,
+			OSCommand:     c.OSCommand,
+			Log:           c.Log,
+			DockerCommand: c,
+		}
+	}
+
+	c.Networks = ownNetworks
+
+	return nil
+}
+
+func networkAttachments(networkName string, containers []*Container) []*NetworkAttachment {
+	attachments := []*NetworkAttachment{}
+	for _, container := range containers {
+		if endpoint, ok := ContainerNetworks(container)[networkName]; ok {
+			attachments = append(attachments, &NetworkAttachment{Container: container, Endpoint: endpoint})
+		}
+	}
+	sort.Slice(attachments, func(i, j int) bool {
+		return attachments[i].Container.Name < attachments[j].Container.Name
+	})
+	return attachments
+}
+
+// ContainerNetworks returns the networks the container is on, keyed by name
+func ContainerNetworks(container *Container) map[string]*network.EndpointSettings {
+	if container.Container.NetworkSettings == nil || container.Container.NetworkSettings.Networks == nil {
+		return map[string]*network.EndpointSettings{}
+	}
+	return container.Container.NetworkSettings.Networks
+}
+
+// HasContainer tells us whether the container is on the network
+func (n *Network) HasContainer(container *Container) bool {
+	for _, attachment := range n.Attachments {
+		if attachment.Container.ID == container.ID {
+			return true
+		}
+	}
+	return false
+}
+
+// Connect puts the container on the network, like docker network connect.
+// Other containers on the network can reach it by any of the aliases as well
+// as by its name.
+func (n *Network) Connect(container *Container, aliases []string) error {
+	defer n.DockerCommand.InvalidateContainerCache()
+
+	return n.Client.NetworkConnect(context.Background(), n.ID, container.ID, &network.EndpointSettings{Aliases: aliases})
+}
+
+// Disconnect takes the container off the network, like docker network
+// disconnect
+func (n *Network) Disconnect(container *Container) error {
+	defer n.DockerCommand.InvalidateContainerCache()
+
+	return n.Client.NetworkDisconnect(context.Background(), n.ID, container.ID, false)
+}
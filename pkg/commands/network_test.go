@@ -0,0 +1,78 @@
+package commands
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/network"
+	"github.com/stretchr/testify/assert"
+)
+
+func containerOnNetworks(name string, networks ...string) *Container {
+	settings := &types.SummaryNetworkSettings{Networks: map[string]*network.EndpointSettings{}}
+	for _, networkName := range networks {
+		settings.Networks[networkName] = &network.EndpointSettings{IPAddress: "172.18.0.2"}
+	}
+	return &Container{ID: name, Name: name, Container: types.Container{NetworkSettings: settings}}
+}
+
+func TestDockerCommandRefreshNetworks(t *testing.T) {
+	dockerCommand := newFakeDaemonCommand(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[
+			{"Name": "web_default", "Id": "2", "Driver": "bridge"},
+			{"Name": "bridge", "Id": "1", "Driver": "bridge"},
+			{"Name": "host", "Id": "3", "Driver": "host"}
+		]`))
+	})
+	dockerCommand.Containers = []*Container{
+		containerOnNetworks("web", "web_default"),
+		containerOnNetworks("db", "web_default", "bridge"),
+		{ID: "job", Name: "job"},
+	}
+	assert.NoError(t, dockerCommand.RefreshNetworks())
+
+	attached := map[string][]string{}
+	names := []string{}
+	for _, network := range dockerCommand.Networks {
+		names = append(names, network.Name)
+		for _, attachment := range network.Attachments {
+			attached[network.Name] = append(attached[network.Name], attachment.Container.Name)
+		}
+	}
+
+	assert.Equal(t, []string{"bridge", "host", "web_default"}, names)
+	assert.Equal(t, map[string][]string{
+		"bridge":      {"db"},
+		"web_default": {"db", "web"},
+	}, attached)
+	assert.True(t, dockerCommand.Networks[2].HasContainer(dockerCommand.Containers[0]))
+	assert.False(t, dockerCommand.Networks[0].HasContainer(dockerCommand.Containers[0]))
+}
+
+func TestNetworkConnect(t *testing.T) {
+	type request struct {
+		path    string
+		connect types.NetworkConnect
+	}
+	requests := make(chan request, 1)
+	dockerCommand := newFakeDaemonCommand(t, func(w http.ResponseWriter, r *http.Request) {
+		req := request{path: r.URL.Path}
+		_ = json.NewDecoder(r.Body).Decode(&req.connect)
+		requests <- req
+	})
+
+	network := &Network{Name: "web_default", ID: "abc", Client: dockerCommand.Client, DockerCommand: dockerCommand}
+	container := &Container{ID: "123", Name: "web"}
+
+	assert.NoError(t, network.Connect(container, []string{"api", "www"}))
+	req := <-requests
+	assert.Equal(t, "/v"+APIVersion+"/networks/abc/connect", req.path)
+	assert.Equal(t, "123", req.connect.Container)
+	assert.Equal(t, []string{"api", "www"}, req.connect.EndpointConfig.Aliases)
+
+	assert.NoError(t, network.Disconnect(container))
+	req = <-requests
+	assert.Equal(t, "/v"+APIVersion+"/networks/abc/disconnect", req.path)
+}
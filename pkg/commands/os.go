@@ -148,6 +148,29 @@ func (c *OSCommand) OpenLink(link string) error {
 	return err
 }
 
+// ErrNoClipboard is returned when there's no clipboard to copy to, e.g. in an
+// ssh session
+var ErrNoClipboard = errors.New("There's no clipboard to copy to here, as neither DISPLAY nor WAYLAND_DISPLAY is set. If you're over ssh, you can set os.copyToClipboardCommand to a command that copies some other way")
+
+// CopyToClipboard copies text to the clipboard, using whatever command is set
+// up for the platform
+func (c *OSCommand) CopyToClipboard(text string) error {
+	command := c.Config.UserConfig.OS.CopyToClipboardCommand
+	// the linux default needs a display server to talk to, and without one
+	// it'd fail with something less helpful
+	if c.Platform.os == "linux" && command == config.GetDefaultConfig().OS.CopyToClipboardCommand &&
+		c.getenv("DISPLAY") == "" && c.getenv("WAYLAND_DISPLAY") == "" {
+		return ErrNoClipboard
+	}
+
+	cmd := c.ExecutableFromString(command)
+	cmd.Stdin = strings.NewReader(text)
+	if err := c.RunExecutable(cmd); err != nil {
+		return fmt.Errorf("Couldn't copy to the clipboard with `%s`: %w", command, err)
+	}
+	return nil
+}
+
 // EditFile opens a file in a subprocess using whatever editor is available,
 // falling back to core.editor, VISUAL, EDITOR, then vi
 func (c *OSCommand) EditFile(filename string) (*exec.Cmd, error) {
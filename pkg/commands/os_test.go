@@ -6,6 +6,7 @@ import (
 	"os/exec"
 	"testing"
 
+	"github.com/jesseduffield/lazydocker/pkg/config"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -289,3 +290,76 @@ func TestOSCommandCreateTempFile(t *testing.T) {
 		})
 	}
 }
+
+func TestOSCommandCopyToClipboard(t *testing.T) {
+	type scenario struct {
+		testName string
+		os       string
+		command  string
+		display  string
+		run      func(string, ...string) *exec.Cmd
+		test     func(error)
+	}
+
+	defaultCommand := config.GetDefaultConfig().OS.CopyToClipboardCommand
+	scenarios := []scenario{
+		{
+			testName: "Copying with the user's command",
+			os:       "linux",
+			command:  "xsel --clipboard",
+			run: func(name string, arg ...string) *exec.Cmd {
+				assert.EqualValues(t, "xsel", name)
+				return exec.Command("cat")
+			},
+			test: func(err error) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			testName: "The command failing",
+			os:       "linux",
+			command:  defaultCommand,
+			display:  ":0",
+			run: func(name string, arg ...string) *exec.Cmd {
+				return exec.Command("exit", "1")
+			},
+			test: func(err error) {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), "Couldn't copy to the clipboard with `"+defaultCommand+"`")
+			},
+		},
+		{
+			testName: "No display on linux",
+			os:       "linux",
+			command:  defaultCommand,
+			run: func(name string, arg ...string) *exec.Cmd {
+				t.Fatal("expected the command not to be run")
+				return nil
+			},
+			test: func(err error) {
+				assert.Equal(t, ErrNoClipboard, err)
+			},
+		},
+	}
+
+	for _, s := range scenarios {
+		s := s
+		t.Run(s.testName, func(t *testing.T) {
+			userConfig := config.GetDefaultConfig()
+			userConfig.OS.CopyToClipboardCommand = s.command
+
+			OSCmd := NewDummyOSCommand()
+			OSCmd.Config.UserConfig = &userConfig
+			OSCmd.Platform.os = s.os
+			OSCmd.command = s.run
+			OSCmd.getenv = func(env string) string {
+				if env == "DISPLAY" {
+					return s.display
+				}
+				return ""
+			}
+
+			s.test(OSCmd.CopyToClipboard("123"))
+		})
+	}
+}
@@ -0,0 +1,167 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jesseduffield/lazydocker/pkg/commands/ssh"
+)
+
+// PublishedPort is a container port published on the docker host
+type PublishedPort struct {
+	HostIP        string
+	HostPort      int
+	ContainerPort int
+	Protocol      string
+}
+
+// httpPorts are the container ports that usually speak http, and httpsPorts
+// the ones among them that usually speak it over TLS
+var (
+	httpPorts  = map[int]bool{80: true, 443: true, 3000: true, 4200: true, 5000: true, 5173: true, 8000: true, 8008: true, 8080: true, 8081: true, 8443: true, 8888: true, 9000: true}
+	httpsPorts = map[int]bool{443: true, 8443: true}
+)
+
+// String is the port as `docker ps` shows it, e.g. 0.0.0.0:8080->80/tcp
+func (p PublishedPort) String() string {
+	return fmt.Sprintf("%s->%d/%s", p.Host(), p.ContainerPort, p.Protocol)
+}
+
+// Host is the address the port is published on, e.g. 0.0.0.0:8080
+func (p PublishedPort) Host() string {
+	return net.JoinHostPort(p.HostIP, strconv.Itoa(p.HostPort))
+}
+
+// IsHTTP guesses whether there's a web server behind the port, going by the
+// port number, since there's no knowing for sure without connecting
+func (p PublishedPort) IsHTTP() bool {
+	return p.Protocol == "tcp" && (httpPorts[p.ContainerPort] || httpPorts[p.HostPort])
+}
+
+// URL is the link for opening the port in a browser at the given address
+func (p PublishedPort) URL(address string) string {
+	scheme := "http"
+	if httpsPorts[p.ContainerPort] {
+		scheme = "https"
+	}
+	return fmt.Sprintf("%s://%s/", scheme, address)
+}
+
+// HostAddress is where the port can be reached on the docker host itself
+func (p PublishedPort) HostAddress() string {
+	host := p.HostIP
+	if isUnspecifiedIP(host) {
+		host = "localhost"
+	}
+	return net.JoinHostPort(host, strconv.Itoa(p.HostPort))
+}
+
+func isUnspecifiedIP(ip string) bool {
+	return ip == "" || net.ParseIP(ip).IsUnspecified()
+}
+
+// PublishedPorts returns the container's published ports, ordered by container
+// port. They come from the inspect data, falling back to the container list
+// until that's been loaded. Ports the container exposes without publishing
+// are left out, and so is the IPv6 twin docker publishes alongside a port
+// bound to every address.
+func (c *Container) PublishedPorts() []PublishedPort {
+	ports := []PublishedPort{}
+	if c.DetailsLoaded() {
+		for key, bindings := range c.Details.NetworkSettings.Ports {
+			parts := strings.SplitN(key, "/", 2)
+			containerPort, err := strconv.Atoi(parts[0])
+			if err != nil {
+				continue
+			}
+			protocol := "tcp"
+			if len(parts) == 2 {
+				protocol = parts[1]
+			}
+			for _, binding := range bindings {
+				hostPort, err := strconv.Atoi(binding.HostPort)
+				if err != nil {
+					continue
+				}
+				ports = append(ports, PublishedPort{HostIP: binding.HostIP, HostPort: hostPort, ContainerPort: containerPort, Protocol: protocol})
+			}
+		}
+	} else {
+		for _, port := range c.Container.Ports {
+			if port.PublicPort == 0 {
+				continue
+			}
+			ports = append(ports, PublishedPort{HostIP: port.IP, HostPort: int(port.PublicPort), ContainerPort: int(port.PrivatePort), Protocol: port.Type})
+		}
+	}
+
+	sort.Slice(ports, func(i, j int) bool {
+		a, b := ports[i], ports[j]
+		if a.ContainerPort != b.ContainerPort {
+			return a.ContainerPort < b.ContainerPort
+		}
+		if a.Protocol != b.Protocol {
+			return a.Protocol < b.Protocol
+		}
+		if a.HostPort != b.HostPort {
+			return a.HostPort < b.HostPort
+		}
+		// 0.0.0.0 sorts before ::, so it's the one we keep below
+		return a.HostIP < b.HostIP
+	})
+
+	deduped := []PublishedPort{}
+	for _, port := range ports {
+		if len(deduped) > 0 {
+			last := deduped[len(deduped)-1]
+			if last.ContainerPort == port.ContainerPort && last.Protocol == port.Protocol && last.HostPort == port.HostPort && isUnspecifiedIP(last.HostIP) && isUnspecifiedIP(port.HostIP) {
+				continue
+			}
+		}
+		deduped = append(deduped, port)
+	}
+	return deduped
+}
+
+// IsTunneled tells us whether we're connected to docker over an ssh tunnel, in
+// which case published ports are on the remote host rather than this one
+func (c *DockerCommand) IsTunneled() bool {
+	return c.tunnel != nil && c.tunnel.SocketPath() != ""
+}
+
+// ForwardPort makes a port published on the remote docker host reachable
+// locally, through the ssh tunnel, and returns the local address to use.
+// Asking for the same port again reuses the forward.
+func (c *DockerCommand) ForwardPort(ctx context.Context, port PublishedPort) (string, error) {
+	if !c.IsTunneled() {
+		return "", ssh.ErrNotTunneled
+	}
+
+	c.portForwardMutex.Lock()
+	defer c.portForwardMutex.Unlock()
+
+	// not localhost, which may well resolve to ::1 on the remote host while
+	// the port is only published on IPv4
+	remoteHost := port.HostIP
+	if isUnspecifiedIP(remoteHost) {
+		remoteHost = "127.0.0.1"
+	}
+	remoteAddress := net.JoinHostPort(remoteHost, strconv.Itoa(port.HostPort))
+	if forward, ok := c.portForwards[remoteAddress]; ok {
+		return forward.LocalAddress, nil
+	}
+
+	forward, err := c.tunnel.ForwardPort(ctx, remoteAddress)
+	if err != nil {
+		return "", err
+	}
+	if c.portForwards == nil {
+		c.portForwards = map[string]*ssh.PortForward{}
+	}
+	c.portForwards[remoteAddress] = forward
+	return forward.LocalAddress, nil
+}
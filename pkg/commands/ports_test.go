@@ -0,0 +1,117 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/jesseduffield/lazydocker/pkg/commands/ssh"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContainerPublishedPorts(t *testing.T) {
+	type scenario struct {
+		testName  string
+		container types.Container
+		details   string
+		expected  []PublishedPort
+	}
+
+	scenarios := []scenario{
+		{
+			testName: "Ports from the inspect data, with the IPv6 twin left out",
+			details: `{"Image": "sha256:abc", "NetworkSettings": {"Ports": {
+				"8080/tcp": [{"HostIp": "0.0.0.0", "HostPort": "8081"}, {"HostIp": "::", "HostPort": "8081"}],
+				"53/udp": [{"HostIp": "127.0.0.1", "HostPort": "5353"}],
+				"9000/tcp": null,
+				"oops/tcp": [{"HostIp": "0.0.0.0", "HostPort": "1"}]
+			}}}`,
+			expected: []PublishedPort{
+				{HostIP: "127.0.0.1", HostPort: 5353, ContainerPort: 53, Protocol: "udp"},
+				{HostIP: "0.0.0.0", HostPort: 8081, ContainerPort: 8080, Protocol: "tcp"},
+			},
+		},
+		{
+			testName: "Ports from the container list until the details are loaded",
+			container: types.Container{Ports: []types.Port{
+				{IP: "0.0.0.0", PrivatePort: 80, PublicPort: 8000, Type: "tcp"},
+				{PrivatePort: 22, Type: "tcp"},
+			}},
+			expected: []PublishedPort{
+				{HostIP: "0.0.0.0", HostPort: 8000, ContainerPort: 80, Protocol: "tcp"},
+			},
+		},
+		{
+			testName: "No published ports",
+			details:  `{"Image": "sha256:abc", "NetworkSettings": {"Ports": {"80/tcp": null}}}`,
+			expected: []PublishedPort{},
+		},
+	}
+
+	for _, s := range scenarios {
+		s := s
+		t.Run(s.testName, func(t *testing.T) {
+			container := &Container{Container: s.container}
+			if s.details != "" {
+				assert.NoError(t, json.Unmarshal([]byte(s.details), &container.Details))
+			}
+			assert.EqualValues(t, s.expected, container.PublishedPorts())
+		})
+	}
+}
+
+func TestPublishedPort(t *testing.T) {
+	type scenario struct {
+		testName     string
+		port         PublishedPort
+		expectedHTTP bool
+		expectedURL  string
+		expectedText string
+	}
+
+	scenarios := []scenario{
+		{
+			testName:     "Web server on every address",
+			port:         PublishedPort{HostIP: "0.0.0.0", HostPort: 8081, ContainerPort: 8080, Protocol: "tcp"},
+			expectedHTTP: true,
+			expectedURL:  "http://localhost:8081/",
+			expectedText: "0.0.0.0:8081->8080/tcp",
+		},
+		{
+			testName:     "TLS on an IPv6 address",
+			port:         PublishedPort{HostIP: "::1", HostPort: 9443, ContainerPort: 443, Protocol: "tcp"},
+			expectedHTTP: true,
+			expectedURL:  "https://[::1]:9443/",
+			expectedText: "[::1]:9443->443/tcp",
+		},
+		{
+			testName:     "Not a web port",
+			port:         PublishedPort{HostIP: "127.0.0.1", HostPort: 5432, ContainerPort: 5432, Protocol: "tcp"},
+			expectedHTTP: false,
+			expectedURL:  "http://127.0.0.1:5432/",
+			expectedText: "127.0.0.1:5432->5432/tcp",
+		},
+		{
+			testName:     "udp is never http",
+			port:         PublishedPort{HostIP: "0.0.0.0", HostPort: 8080, ContainerPort: 8080, Protocol: "udp"},
+			expectedHTTP: false,
+			expectedURL:  "http://localhost:8080/",
+			expectedText: "0.0.0.0:8080->8080/udp",
+		},
+	}
+
+	for _, s := range scenarios {
+		s := s
+		t.Run(s.testName, func(t *testing.T) {
+			assert.Equal(t, s.expectedHTTP, s.port.IsHTTP())
+			assert.Equal(t, s.expectedURL, s.port.URL(s.port.HostAddress()))
+			assert.Equal(t, s.expectedText, s.port.String())
+		})
+	}
+}
+
+func TestDockerCommandForwardPortNotTunneled(t *testing.T) {
+	_, err := (&DockerCommand{}).ForwardPort(context.Background(), PublishedPort{HostPort: 8080, Protocol: "tcp"})
+	assert.Equal(t, ssh.ErrNotTunneled, err)
+}
@@ -0,0 +1,65 @@
+package commands
+
+import (
+	"context"
+	"github.com/docker/docker/api/types/filters"
+)
+
+// PruneReport is what a prune got rid of
+type PruneReport struct {
+	ItemsDeleted   int
+	SpaceReclaimed uint64
+}
+
+// PruneContainers prunes containers
+func (c *DockerCommand) PruneContainers() (PruneReport, error) {
+	defer c.InvalidateContainerCache()
+	report, err := c.Client.ContainersPrune(context.Background(), filters.Args{})
+	if err != nil {
+		return PruneReport{}, err
+	}
+	return PruneReport{ItemsDeleted: len(report.ContainersDeleted), SpaceReclaimed: report.SpaceReclaimed}, nil
+}
+
+// PruneImages prunes dangling images, i.e. untagged ones that nothing
+// depends on. If all is set it prunes every image that no container is using,
+// like docker image prune --all.
+func (c *DockerCommand) PruneImages(all bool) (PruneReport, error) {
+	args := filters.NewArgs()
+	if all {
+		args.Add("dangling", "false")
+	}
+	report, err := c.Client.ImagesPrune(context.Background(), args)
+	if err != nil {
+		return PruneReport{}, err
+	}
+
+	// untagging an image is reported too, but it's only deleting one that
+	// counts
+	deleted := 0
+	for _, item := range report.ImagesDeleted {
+		if item.Deleted != "" {
+			deleted++
+		}
+	}
+	return PruneReport{ItemsDeleted: deleted, SpaceReclaimed: report.SpaceReclaimed}, nil
+}
+
+// PruneVolumes prunes volumes that no container is using
+func (c *DockerCommand) PruneVolumes() (PruneReport, error) {
+	report, err := c.Client.VolumesPrune(context.Background(), filters.Args{})
+	if err != nil {
+		return PruneReport{}, err
+	}
+	return PruneReport{ItemsDeleted: len(report.VolumesDeleted), SpaceReclaimed: report.SpaceReclaimed}, nil
+}
+
+// PruneNetworks prunes networks that no container is using. Networks don't
+// take up any space, so there's none reclaimed.
+func (c *DockerCommand) PruneNetworks() (PruneReport, error) {
+	report, err := c.Client.NetworksPrune(context.Background(), filters.Args{})
+	if err != nil {
+		return PruneReport{}, err
+	}
+	return PruneReport{ItemsDeleted: len(report.NetworksDeleted)}, nil
+}
@@ -0,0 +1,55 @@
+package commands
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDockerCommandPruneImages(t *testing.T) {
+	type scenario struct {
+		testName        string
+		all             bool
+		expectedFilters string
+	}
+
+	scenarios := []scenario{
+		{
+			testName:        "Dangling only",
+			all:             false,
+			expectedFilters: "",
+		},
+		{
+			testName:        "All unused",
+			all:             true,
+			expectedFilters: `{"dangling":{"false":true}}`,
+		},
+	}
+
+	for _, s := range scenarios {
+		s := s
+		t.Run(s.testName, func(t *testing.T) {
+			dockerCommand := newFakeDaemonCommand(t, func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, s.expectedFilters, r.URL.Query().Get("filters"))
+				_, _ = w.Write([]byte(`{"ImagesDeleted": [{"Untagged": "alpine:3.11"}, {"Deleted": "sha256:aaa"}, {"Deleted": "sha256:bbb"}], "SpaceReclaimed": 1200}`))
+			})
+
+			report, err := dockerCommand.PruneImages(s.all)
+			assert.NoError(t, err)
+			// the untagging doesn't count as a deletion
+			assert.Equal(t, PruneReport{ItemsDeleted: 2, SpaceReclaimed: 1200}, report)
+		})
+	}
+}
+
+func TestDockerCommandPruneNetworks(t *testing.T) {
+	dockerCommand := newFakeDaemonCommand(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v"+APIVersion+"/networks/prune", r.URL.Path)
+		_, _ = w.Write([]byte(`{"NetworksDeleted": ["old_default", "test_default"]}`))
+	})
+
+	report, err := dockerCommand.PruneNetworks()
+	assert.NoError(t, err)
+	assert.Equal(t, PruneReport{ItemsDeleted: 2}, report)
+}
@@ -0,0 +1,180 @@
+package commands
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/docker/distribution/reference"
+	"github.com/docker/docker/api/types"
+)
+
+// dockerHubServerAddress is what docker login saves docker hub credentials
+// against
+const dockerHubServerAddress = "https://index.docker.io/v1/"
+
+// dockerConfigFile is the part of ~/.docker/config.json we need to find
+// registry credentials
+type dockerConfigFile struct {
+	Auths map[string]struct {
+		Auth          string `json:"auth"`
+		IdentityToken string `json:"identitytoken"`
+	} `json:"auths"`
+	CredsStore  string            `json:"credsStore"`
+	CredHelpers map[string]string `json:"credHelpers"`
+}
+
+// credentialHelperRunner runs `docker-credential-<helper> get` for the server
+// address and returns what it printed
+type credentialHelperRunner func(helper string, serverAddress string) ([]byte, error)
+
+func runCredentialHelper(helper string, serverAddress string) ([]byte, error) {
+	cmd := exec.Command("docker-credential-"+helper, "get")
+	cmd.Stdin = strings.NewReader(serverAddress)
+	output, err := cmd.Output()
+	if err != nil {
+		// helpers say what went wrong on stdout
+		return output, fmt.Errorf("docker-credential-%s: %s", helper, strings.TrimSpace(string(output)))
+	}
+	return output, nil
+}
+
+func dockerConfigDir() string {
+	if dir := os.Getenv("DOCKER_CONFIG"); dir != "" {
+		return dir
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".docker")
+}
+
+// registryAuthFor returns the encoded credentials to send when pulling ref,
+// found the same way the docker cli finds them: a credential helper for the
+// registry, the default credential store, or whatever's saved in the config
+// file. It's blank if we have no credentials for the registry, in which case
+// we pull anonymously.
+func registryAuthFor(ref string, configDir string, runHelper credentialHelperRunner) (string, error) {
+	domain, serverAddress, err := registryFor(ref)
+	if err != nil {
+		return "", err
+	}
+
+	content, err := ioutil.ReadFile(filepath.Join(configDir, "config.json"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	var configFile dockerConfigFile
+	if err := json.Unmarshal(content, &configFile); err != nil {
+		return "", fmt.Errorf("parse docker config: %w", err)
+	}
+
+	auth, found, err := configFile.authFor(domain, serverAddress, runHelper)
+	if err != nil || !found {
+		return "", err
+	}
+	return encodeRegistryAuth(auth)
+}
+
+// registryFor returns the domain of ref's registry, and the address its
+// credentials are saved against, which for docker hub isn't its domain
+func registryFor(ref string) (domain string, serverAddress string, err error) {
+	named, err := reference.ParseNormalizedNamed(ref)
+	if err != nil {
+		return "", "", err
+	}
+	domain = reference.Domain(named)
+	serverAddress = domain
+	if domain == "docker.io" {
+		serverAddress = dockerHubServerAddress
+	}
+	return domain, serverAddress, nil
+}
+
+// encodeRegistryAuth encodes credentials the way the daemon expects them in
+// the X-Registry-Auth header
+func encodeRegistryAuth(auth types.AuthConfig) (string, error) {
+	encoded, err := json.Marshal(auth)
+	if err != nil {
+		return "", err
+	}
+	return base64.URLEncoding.EncodeToString(encoded), nil
+}
+
+func (f dockerConfigFile) authFor(domain string, serverAddress string, runHelper credentialHelperRunner) (types.AuthConfig, bool, error) {
+	helper := f.CredsStore
+	if registryHelper, ok := f.CredHelpers[domain]; ok {
+		helper = registryHelper
+	}
+	if helper != "" {
+		output, err := runHelper(helper, serverAddress)
+		if err != nil {
+			if strings.Contains(err.Error(), "credentials not found") {
+				return types.AuthConfig{}, false, nil
+			}
+			return types.AuthConfig{}, false, err
+		}
+		var credentials struct {
+			Username string
+			Secret   string
+		}
+		if err := json.Unmarshal(output, &credentials); err != nil {
+			return types.AuthConfig{}, false, fmt.Errorf("parse docker-credential-%s output: %w", helper, err)
+		}
+		auth := types.AuthConfig{ServerAddress: serverAddress}
+		if credentials.Username == "<token>" {
+			auth.IdentityToken = credentials.Secret
+		} else {
+			auth.Username = credentials.Username
+			auth.Password = credentials.Secret
+		}
+		return auth, true, nil
+	}
+
+	for key, entry := range f.Auths {
+		if registryDomain(key) != domain {
+			continue
+		}
+		auth := types.AuthConfig{ServerAddress: serverAddress, IdentityToken: entry.IdentityToken}
+		if entry.Auth != "" {
+			decoded, err := base64.StdEncoding.DecodeString(entry.Auth)
+			if err != nil {
+				return types.AuthConfig{}, false, fmt.Errorf("decode credentials for %s: %w", key, err)
+			}
+			parts := strings.SplitN(string(decoded), ":", 2)
+			if len(parts) != 2 {
+				return types.AuthConfig{}, false, fmt.Errorf("malformed credentials for %s", key)
+			}
+			auth.Username, auth.Password = parts[0], parts[1]
+		}
+		return auth, true, nil
+	}
+
+	return types.AuthConfig{}, false, nil
+}
+
+// registryDomain normalises a key from the config file's auths, which could be
+// a bare hostname or a url, to the domain an image reference would have
+func registryDomain(key string) string {
+	domain := key
+	if strings.Contains(key, "://") {
+		if u, err := url.Parse(key); err == nil {
+			domain = u.Host
+		}
+	}
+	domain = strings.SplitN(domain, "/", 2)[0]
+	if domain == "index.docker.io" || domain == "registry-1.docker.io" {
+		return "docker.io"
+	}
+	return domain
+}
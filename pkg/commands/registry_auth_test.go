@@ -0,0 +1,131 @@
+package commands
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistryAuthFor(t *testing.T) {
+	type scenario struct {
+		testName            string
+		config              string
+		ref                 string
+		helperOutput        string
+		helperErr           error
+		expectedHelper      string
+		expectedServer      string
+		expected            *types.AuthConfig
+		expectedErrorSubstr string
+	}
+
+	basicAuth := base64.StdEncoding.EncodeToString([]byte("me:hunter2"))
+
+	scenarios := []scenario{
+		{
+			testName: "No config file",
+			ref:      "alpine",
+		},
+		{
+			testName: "Docker hub credentials in the config",
+			config:   `{"auths": {"https://index.docker.io/v1/": {"auth": "` + basicAuth + `"}}}`,
+			ref:      "alpine:3.12",
+			expected: &types.AuthConfig{Username: "me", Password: "hunter2", ServerAddress: "https://index.docker.io/v1/"},
+		},
+		{
+			testName: "Private registry credentials in the config",
+			config:   `{"auths": {"registry.example.com": {"auth": "` + basicAuth + `"}, "https://index.docker.io/v1/": {"auth": "bm9wZTpub3Bl"}}}`,
+			ref:      "registry.example.com/team/app:1.0",
+			expected: &types.AuthConfig{Username: "me", Password: "hunter2", ServerAddress: "registry.example.com"},
+		},
+		{
+			testName: "No credentials for the registry",
+			config:   `{"auths": {"registry.example.com": {"auth": "` + basicAuth + `"}}}`,
+			ref:      "alpine",
+		},
+		{
+			testName:       "Credential store",
+			config:         `{"credsStore": "desktop"}`,
+			ref:            "alpine",
+			helperOutput:   `{"ServerURL": "https://index.docker.io/v1/", "Username": "me", "Secret": "hunter2"}`,
+			expectedHelper: "desktop",
+			expectedServer: "https://index.docker.io/v1/",
+			expected:       &types.AuthConfig{Username: "me", Password: "hunter2", ServerAddress: "https://index.docker.io/v1/"},
+		},
+		{
+			testName:       "Registry's own credential helper with an identity token",
+			config:         `{"credsStore": "desktop", "credHelpers": {"123.dkr.ecr.us-east-1.amazonaws.com": "ecr-login"}}`,
+			ref:            "123.dkr.ecr.us-east-1.amazonaws.com/app",
+			helperOutput:   `{"Username": "<token>", "Secret": "abc"}`,
+			expectedHelper: "ecr-login",
+			expectedServer: "123.dkr.ecr.us-east-1.amazonaws.com",
+			expected:       &types.AuthConfig{IdentityToken: "abc", ServerAddress: "123.dkr.ecr.us-east-1.amazonaws.com"},
+		},
+		{
+			testName:       "Credential store has nothing for the registry",
+			config:         `{"credsStore": "desktop"}`,
+			ref:            "alpine",
+			helperErr:      errors.New("docker-credential-desktop: credentials not found in native keychain"),
+			expectedHelper: "desktop",
+			expectedServer: "https://index.docker.io/v1/",
+		},
+		{
+			testName:            "Credential store fails",
+			config:              `{"credsStore": "desktop"}`,
+			ref:                 "alpine",
+			helperErr:           errors.New("docker-credential-desktop: executable file not found in $PATH"),
+			expectedHelper:      "desktop",
+			expectedServer:      "https://index.docker.io/v1/",
+			expectedErrorSubstr: "executable file not found",
+		},
+		{
+			testName:            "Invalid reference",
+			ref:                 "Not Valid",
+			expectedErrorSubstr: "invalid reference format",
+		},
+	}
+
+	for _, s := range scenarios {
+		s := s
+		t.Run(s.testName, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "lazydocker-docker-config")
+			assert.NoError(t, err)
+			defer os.RemoveAll(dir)
+			if s.config != "" {
+				assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "config.json"), []byte(s.config), 0600))
+			}
+
+			runHelper := func(helper string, serverAddress string) ([]byte, error) {
+				assert.Equal(t, s.expectedHelper, helper)
+				assert.Equal(t, s.expectedServer, serverAddress)
+				return []byte(s.helperOutput), s.helperErr
+			}
+
+			encoded, err := registryAuthFor(s.ref, dir, runHelper)
+			if s.expectedErrorSubstr != "" {
+				if assert.Error(t, err) {
+					assert.Contains(t, err.Error(), s.expectedErrorSubstr)
+				}
+				return
+			}
+			assert.NoError(t, err)
+
+			if s.expected == nil {
+				assert.Equal(t, "", encoded)
+				return
+			}
+			decoded, err := base64.URLEncoding.DecodeString(encoded)
+			assert.NoError(t, err)
+			var actual types.AuthConfig
+			assert.NoError(t, json.Unmarshal(decoded, &actual))
+			assert.Equal(t, *s.expected, actual)
+		})
+	}
+}
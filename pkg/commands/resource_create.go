@@ -0,0 +1,185 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/api/types/volume"
+)
+
+// resourceNameRegex is what docker allows volumes and networks to be called
+var resourceNameRegex = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+
+// createInput is what was typed in to create a volume or network: the name,
+// followed by flags as docker volume create and docker network create take
+// them, e.g. `data --driver local --label env=dev`
+type createInput struct {
+	name   string
+	values map[string][]string
+}
+
+// parseCreateInput splits up the input, allowing only the given flags. Values
+// can follow their flag or be joined to it with `=`. There's no quoting, so
+// values can't have spaces in them.
+func parseCreateInput(input string, flags []string) (*createInput, error) {
+	result := &createInput{values: map[string][]string{}}
+	fields := strings.Fields(input)
+	for i := 0; i < len(fields); i++ {
+		field := fields[i]
+		if !strings.HasPrefix(field, "-") {
+			if result.name != "" {
+				return nil, fmt.Errorf("expected one name, got %q and %q", result.name, field)
+			}
+			if !resourceNameRegex.MatchString(field) {
+				return nil, fmt.Errorf("%q isn't a valid name: only letters, digits, _, . and - are allowed, starting with a letter or digit", field)
+			}
+			result.name = field
+			continue
+		}
+
+		flag := strings.TrimLeft(field, "-")
+		value := ""
+		hasValue := false
+		if j := strings.Index(flag, "="); j != -1 {
+			flag, value, hasValue = flag[:j], flag[j+1:], true
+		}
+		if !containsString(flags, flag) {
+			return nil, fmt.Errorf("unknown flag %s: expected one of --%s", field, strings.Join(flags, ", --"))
+		}
+		if !hasValue {
+			if i+1 == len(fields) {
+				return nil, fmt.Errorf("--%s needs a value", flag)
+			}
+			i++
+			value = fields[i]
+		}
+		result.values[flag] = append(result.values[flag], value)
+	}
+	return result, nil
+}
+
+// single returns the flag's value, or def if it wasn't given
+func (c *createInput) single(flag, def string) (string, error) {
+	values := c.values[flag]
+	switch len(values) {
+	case 0:
+		return def, nil
+	case 1:
+		return values[0], nil
+	default:
+		return "", fmt.Errorf("--%s can only be given once", flag)
+	}
+}
+
+// labels returns the labels from the --label flags, which are key=value, or
+// just key for an empty value
+func (c *createInput) labels() (map[string]string, error) {
+	labels := map[string]string{}
+	for _, label := range c.values["label"] {
+		key, value := label, ""
+		if i := strings.Index(label, "="); i != -1 {
+			key, value = label[:i], label[i+1:]
+		}
+		if key == "" {
+			return nil, fmt.Errorf("%q isn't a valid label: expected key=value", label)
+		}
+		labels[key] = value
+	}
+	return labels, nil
+}
+
+// ParseVolumeCreate checks what was typed in to create a volume, e.g.
+// `data --driver local --label env=dev`. Without a name, the daemon makes one
+// up, as docker volume create does.
+func ParseVolumeCreate(input string) (volume.VolumeCreateBody, error) {
+	parsed, err := parseCreateInput(input, []string{"driver", "label"})
+	if err != nil {
+		return volume.VolumeCreateBody{}, err
+	}
+	driver, err := parsed.single("driver", "local")
+	if err != nil {
+		return volume.VolumeCreateBody{}, err
+	}
+	labels, err := parsed.labels()
+	if err != nil {
+		return volume.VolumeCreateBody{}, err
+	}
+	return volume.VolumeCreateBody{Name: parsed.name, Driver: driver, Labels: labels}, nil
+}
+
+// ParseNetworkCreate checks what was typed in to create a network, e.g.
+// `backend --subnet 172.28.0.0/16 --gateway 172.28.0.1`, returning the
+// network's name and how to create it
+func ParseNetworkCreate(input string) (string, types.NetworkCreate, error) {
+	parsed, err := parseCreateInput(input, []string{"driver", "subnet", "gateway", "label"})
+	if err != nil {
+		return "", types.NetworkCreate{}, err
+	}
+	if parsed.name == "" {
+		return "", types.NetworkCreate{}, fmt.Errorf("a network needs a name")
+	}
+
+	options := types.NetworkCreate{CheckDuplicate: true}
+	if options.Driver, err = parsed.single("driver", "bridge"); err != nil {
+		return "", types.NetworkCreate{}, err
+	}
+	if options.Labels, err = parsed.labels(); err != nil {
+		return "", types.NetworkCreate{}, err
+	}
+
+	subnet, err := parsed.single("subnet", "")
+	if err != nil {
+		return "", types.NetworkCreate{}, err
+	}
+	gateway, err := parsed.single("gateway", "")
+	if err != nil {
+		return "", types.NetworkCreate{}, err
+	}
+	if subnet == "" {
+		if gateway != "" {
+			return "", types.NetworkCreate{}, fmt.Errorf("a gateway needs a subnet to be in")
+		}
+		return parsed.name, options, nil
+	}
+
+	_, subnetNet, err := net.ParseCIDR(subnet)
+	if err != nil {
+		return "", types.NetworkCreate{}, fmt.Errorf("%q isn't a valid subnet: expected CIDR notation, e.g. 172.28.0.0/16", subnet)
+	}
+	if gateway != "" {
+		ip := net.ParseIP(gateway)
+		if ip == nil {
+			return "", types.NetworkCreate{}, fmt.Errorf("%q isn't a valid gateway: expected an IP address, e.g. 172.28.0.1", gateway)
+		}
+		if !subnetNet.Contains(ip) {
+			return "", types.NetworkCreate{}, fmt.Errorf("gateway %s isn't in subnet %s", gateway, subnet)
+		}
+	}
+	options.IPAM = &network.IPAM{Config: []network.IPAMConfig{{Subnet: subnet, Gateway: gateway}}}
+
+	return parsed.name, options, nil
+}
+
+// CreateVolume creates a volume, like docker volume create, returning its name
+func (c *DockerCommand) CreateVolume(options volume.VolumeCreateBody) (string, error) {
+	created, err := c.Client.VolumeCreate(context.Background(), options)
+	if err != nil {
+		return "", err
+	}
+	return created.Name, nil
+}
+
+// CreateNetwork creates a network, like docker network create, returning its
+// ID
+func (c *DockerCommand) CreateNetwork(name string, options types.NetworkCreate) (string, error) {
+	created, err := c.Client.NetworkCreate(context.Background(), name, options)
+	if err != nil {
+		return "", err
+	}
+	return created.ID, nil
+}
@@ -0,0 +1,155 @@
+package commands
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/api/types/volume"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseVolumeCreate(t *testing.T) {
+	type scenario struct {
+		testName string
+		input    string
+		expected volume.VolumeCreateBody
+		err      string
+	}
+
+	scenarios := []scenario{
+		{
+			testName: "Nothing at all leaves the name to the daemon",
+			input:    "",
+			expected: volume.VolumeCreateBody{Driver: "local", Labels: map[string]string{}},
+		},
+		{
+			testName: "A name, driver and labels",
+			input:    "data --driver=nfs --label env=dev -label backup",
+			expected: volume.VolumeCreateBody{Name: "data", Driver: "nfs", Labels: map[string]string{"env": "dev", "backup": ""}},
+		},
+		{
+			testName: "A bad name",
+			input:    "my/data",
+			err:      `"my/data" isn't a valid name`,
+		},
+		{
+			testName: "Two names",
+			input:    "data more",
+			err:      `expected one name, got "data" and "more"`,
+		},
+		{
+			testName: "A flag volumes don't have",
+			input:    "data --subnet 10.0.0.0/8",
+			err:      "unknown flag --subnet: expected one of --driver, --label",
+		},
+		{
+			testName: "A flag without its value",
+			input:    "data --driver",
+			err:      "--driver needs a value",
+		},
+		{
+			testName: "Two drivers",
+			input:    "data --driver local --driver nfs",
+			err:      "--driver can only be given once",
+		},
+		{
+			testName: "A label without a key",
+			input:    "data --label =dev",
+			err:      `"=dev" isn't a valid label`,
+		},
+	}
+
+	for _, s := range scenarios {
+		s := s
+		t.Run(s.testName, func(t *testing.T) {
+			options, err := ParseVolumeCreate(s.input)
+			if s.err != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), s.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, s.expected, options)
+		})
+	}
+}
+
+func TestParseNetworkCreate(t *testing.T) {
+	type scenario struct {
+		testName string
+		input    string
+		expected types.NetworkCreate
+		err      string
+	}
+
+	scenarios := []scenario{
+		{
+			testName: "Just a name",
+			input:    "backend",
+			expected: types.NetworkCreate{CheckDuplicate: true, Driver: "bridge", Labels: map[string]string{}},
+		},
+		{
+			testName: "A subnet and gateway",
+			input:    "backend --driver bridge --subnet 172.28.0.0/16 --gateway=172.28.0.1 --label env=dev",
+			expected: types.NetworkCreate{
+				CheckDuplicate: true,
+				Driver:         "bridge",
+				Labels:         map[string]string{"env": "dev"},
+				IPAM:           &network.IPAM{Config: []network.IPAMConfig{{Subnet: "172.28.0.0/16", Gateway: "172.28.0.1"}}},
+			},
+		},
+		{
+			testName: "No name",
+			input:    "--driver overlay",
+			err:      "a network needs a name",
+		},
+		{
+			testName: "A subnet that isn't CIDR",
+			input:    "backend --subnet 172.28.0.0",
+			err:      `"172.28.0.0" isn't a valid subnet`,
+		},
+		{
+			testName: "A gateway that isn't an IP",
+			input:    "backend --subnet 172.28.0.0/16 --gateway gw",
+			err:      `"gw" isn't a valid gateway`,
+		},
+		{
+			testName: "A gateway outside the subnet",
+			input:    "backend --subnet 172.28.0.0/16 --gateway 10.0.0.1",
+			err:      "gateway 10.0.0.1 isn't in subnet 172.28.0.0/16",
+		},
+		{
+			testName: "A gateway without a subnet",
+			input:    "backend --gateway 172.28.0.1",
+			err:      "a gateway needs a subnet to be in",
+		},
+	}
+
+	for _, s := range scenarios {
+		s := s
+		t.Run(s.testName, func(t *testing.T) {
+			name, options, err := ParseNetworkCreate(s.input)
+			if s.err != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), s.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, "backend", name)
+			assert.Equal(t, s.expected, options)
+		})
+	}
+}
+
+func TestDockerCommandCreateVolume(t *testing.T) {
+	dockerCommand := newFakeDaemonCommand(t, func(w http.ResponseWriter, r *http.Request) {
+		// the daemon makes up a name when it isn't given one
+		_, _ = w.Write([]byte(`{"Name": "3f2a9c", "Driver": "local"}`))
+	})
+
+	name, err := dockerCommand.CreateVolume(volume.VolumeCreateBody{Driver: "local"})
+	assert.NoError(t, err)
+	assert.Equal(t, "3f2a9c", name)
+}
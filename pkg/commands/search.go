@@ -0,0 +1,261 @@
+package commands
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/fatih/color"
+	"github.com/jesseduffield/lazydocker/pkg/utils"
+)
+
+// the kinds of thing the global search looks through
+const (
+	SearchKindContainer = "container"
+	SearchKindImage     = "image"
+	SearchKindVolume    = "volume"
+	SearchKindNetwork   = "network"
+)
+
+// SearchItem is something the global search can find: a container, image,
+// volume or network, with the parts of it we match against
+type SearchItem struct {
+	Kind   string
+	ID     string
+	Name   string
+	Labels map[string]string
+}
+
+// SearchResult is a SearchItem that matched. Field is the text that matched,
+// which is the item's name unless it was the ID or a label, and Positions are
+// the indexes of the matched runes within it.
+type SearchResult struct {
+	Item      SearchItem
+	Score     int
+	Field     string
+	Positions []int
+}
+
+// MatchedName tells us whether it was the item's name that matched
+func (r SearchResult) MatchedName() bool {
+	return r.Field == r.Item.Name
+}
+
+// GetSearchItems gathers up what the global search looks through
+func GetSearchItems(containers []*Container, images []*Image, volumes []*Volume, networks []*Network) []SearchItem {
+	items := []SearchItem{}
+	for _, container := range containers {
+		items = append(items, SearchItem{Kind: SearchKindContainer, ID: container.ID, Name: container.Name, Labels: container.Container.Labels})
+	}
+	for _, image := range images {
+		name := image.Name
+		if image.Tag != "" {
+			name += ":" + image.Tag
+		}
+		items = append(items, SearchItem{Kind: SearchKindImage, ID: image.ID, Name: name, Labels: image.Image.Labels})
+	}
+	for _, volume := range volumes {
+		var labels map[string]string
+		if volume.Volume != nil {
+			labels = volume.Volume.Labels
+		}
+		items = append(items, SearchItem{Kind: SearchKindVolume, ID: volume.Name, Name: volume.Name, Labels: labels})
+	}
+	for _, network := range networks {
+		items = append(items, SearchItem{Kind: SearchKindNetwork, ID: network.ID, Name: network.Name, Labels: network.Network.Labels})
+	}
+	return items
+}
+
+// Search returns the items matching query, best first, and at most limit of
+// them. Names are matched fuzzily, so 'wdb' finds 'web_db_1'. IDs have to
+// start with the query, and labels (as key=value) have to contain it, because
+// a fuzzy match against a long hash matches nearly anything.
+func Search(query string, items []SearchItem, limit int) []SearchResult {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return []SearchResult{}
+	}
+
+	results := []SearchResult{}
+	for _, item := range items {
+		if result, ok := matchSearchItem(query, item); ok {
+			results = append(results, result)
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		// a shorter name matched as well is a closer match
+		return len(results[i].Item.Name) < len(results[j].Item.Name)
+	})
+
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
+
+func matchSearchItem(query string, item SearchItem) (SearchResult, bool) {
+	best := SearchResult{Item: item}
+	found := false
+	consider := func(field string, score int, positions []int) {
+		if !found || score > best.Score {
+			best.Field, best.Score, best.Positions = field, score, positions
+			found = true
+		}
+	}
+
+	if score, positions, ok := FuzzyMatch(query, item.Name); ok {
+		consider(item.Name, score, positions)
+	}
+
+	lowerQuery := strings.ToLower(query)
+	// image IDs start with the digest's algorithm, which nobody types
+	id := strings.TrimPrefix(item.ID, "sha256:")
+	if strings.HasPrefix(strings.ToLower(id), lowerQuery) && item.ID != item.Name {
+		consider(id, len([]rune(query))*matchScore+prefixBonus, runeRange(0, len([]rune(query))))
+	}
+
+	// in order, so that which of two equally good labels we show doesn't
+	// change from one keypress to the next
+	keys := make([]string, 0, len(item.Labels))
+	for key := range item.Labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		label := key + "=" + item.Labels[key]
+		if index := strings.Index(strings.ToLower(label), lowerQuery); index != -1 {
+			start := len([]rune(label[:index]))
+			// labels score below a name matched as tightly, so they only
+			// win when nothing better matched
+			consider(label, len([]rune(query))*matchScore, runeRange(start, start+len([]rune(query))))
+		}
+	}
+
+	return best, found
+}
+
+func runeRange(start, end int) []int {
+	positions := make([]int, 0, end-start)
+	for i := start; i < end; i++ {
+		positions = append(positions, i)
+	}
+	return positions
+}
+
+const (
+	matchScore       = 16
+	consecutiveBonus = 24
+	boundaryBonus    = 20
+	prefixBonus      = 16
+)
+
+// FuzzyMatch tells us whether the runes of pattern appear in text in order,
+// ignoring case, along with a score for how good a match it is and the
+// positions of the matched runes. Runs of consecutive runes, runes at the
+// start of a word, and matching from the very start all score higher, and
+// every skipped rune in between costs a point.
+func FuzzyMatch(pattern string, text string) (int, []int, bool) {
+	patternRunes := []rune(strings.ToLower(pattern))
+	textRunes := []rune(text)
+	lowerRunes := []rune(strings.ToLower(text))
+	if len(patternRunes) == 0 {
+		return 0, []int{}, true
+	}
+	// lowercasing can change the number of runes in rare cases, in which
+	// case we match case-sensitively rather than highlight the wrong runes
+	if len(lowerRunes) != len(textRunes) {
+		lowerRunes = textRunes
+	}
+
+	bestScore := 0
+	var bestPositions []int
+	// trying each place the first rune appears, keeping whichever scores
+	// best, because matching greedily from the first one can miss a tighter
+	// match further on: 'db' in 'debug_db'
+	for start := range lowerRunes {
+		if lowerRunes[start] != patternRunes[0] {
+			continue
+		}
+		positions := []int{start}
+		for i, p := start+1, 1; p < len(patternRunes) && i < len(lowerRunes); i++ {
+			if lowerRunes[i] == patternRunes[p] {
+				positions = append(positions, i)
+				p++
+			}
+		}
+		if len(positions) < len(patternRunes) {
+			// no later start can match either
+			break
+		}
+		if score := fuzzyScore(textRunes, positions); bestPositions == nil || score > bestScore {
+			bestScore, bestPositions = score, positions
+		}
+	}
+
+	if bestPositions == nil {
+		return 0, nil, false
+	}
+	return bestScore, bestPositions, true
+}
+
+func fuzzyScore(text []rune, positions []int) int {
+	score := 0
+	for i, position := range positions {
+		score += matchScore
+		if i > 0 {
+			if gap := position - positions[i-1] - 1; gap == 0 {
+				score += consecutiveBonus
+			} else {
+				score -= gap
+			}
+		}
+		if isWordStart(text, position) {
+			score += boundaryBonus
+		}
+	}
+	if positions[0] == 0 {
+		score += prefixBonus
+	}
+	return score
+}
+
+func isWordStart(text []rune, position int) bool {
+	if position == 0 {
+		return true
+	}
+	previous, current := text[position-1], text[position]
+	if strings.ContainsRune("-_./:= ", previous) {
+		return true
+	}
+	return unicode.IsLower(previous) && unicode.IsUpper(current)
+}
+
+// HighlightPositions colours the runes of text at the given positions, for
+// showing what a search matched
+func HighlightPositions(text string, positions []int) string {
+	highlighted := map[int]bool{}
+	for _, position := range positions {
+		highlighted[position] = true
+	}
+
+	out := &strings.Builder{}
+	runes := []rune(text)
+	for i := 0; i < len(runes); {
+		end := i + 1
+		for end < len(runes) && highlighted[end] == highlighted[i] {
+			end++
+		}
+		if highlighted[i] {
+			out.WriteString(utils.ColoredString(string(runes[i:end]), color.FgYellow))
+		} else {
+			out.WriteString(string(runes[i:end]))
+		}
+		i = end
+	}
+	return out.String()
+}
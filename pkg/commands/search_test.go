@@ -0,0 +1,154 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/fatih/color"
+	"github.com/jesseduffield/lazydocker/pkg/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFuzzyMatch(t *testing.T) {
+	type scenario struct {
+		pattern           string
+		text              string
+		expectedOk        bool
+		expectedPositions []int
+	}
+
+	scenarios := []scenario{
+		{pattern: "web", text: "web_1", expectedOk: true, expectedPositions: []int{0, 1, 2}},
+		{pattern: "WEB", text: "web_1", expectedOk: true, expectedPositions: []int{0, 1, 2}},
+		{pattern: "wdb", text: "web_db_1", expectedOk: true, expectedPositions: []int{0, 4, 5}},
+		// the tighter match later on beats the greedy one from the first 'd'
+		{pattern: "db", text: "debug_db", expectedOk: true, expectedPositions: []int{6, 7}},
+		{pattern: "bew", text: "web_1", expectedOk: false},
+		{pattern: "webs", text: "web", expectedOk: false},
+		{pattern: "", text: "web", expectedOk: true, expectedPositions: []int{}},
+	}
+
+	for _, s := range scenarios {
+		s := s
+		t.Run(s.pattern+" in "+s.text, func(t *testing.T) {
+			_, positions, ok := FuzzyMatch(s.pattern, s.text)
+			assert.Equal(t, s.expectedOk, ok)
+			assert.EqualValues(t, s.expectedPositions, positions)
+		})
+	}
+}
+
+func TestFuzzyMatchRanking(t *testing.T) {
+	score := func(pattern, text string) int {
+		score, _, ok := FuzzyMatch(pattern, text)
+		assert.True(t, ok)
+		return score
+	}
+
+	// consecutive beats scattered
+	assert.True(t, score("api", "api_1") > score("api", "a_p_i"))
+	// the start of a word beats the middle of one
+	assert.True(t, score("db", "web_db") > score("db", "webdb"))
+	// the very start beats the start of a later word
+	assert.True(t, score("web", "web_db") > score("web", "db_web"))
+}
+
+func TestSearch(t *testing.T) {
+	items := []SearchItem{
+		{Kind: SearchKindContainer, ID: "c0ffee1234", Name: "shop_web_1", Labels: map[string]string{"com.docker.compose.project": "shop"}},
+		{Kind: SearchKindContainer, ID: "deadbeef99", Name: "web", Labels: map[string]string{}},
+		{Kind: SearchKindImage, ID: "sha256:abc123", Name: "nginx:latest", Labels: map[string]string{"maintainer": "NGINX Docker Maintainers"}},
+		{Kind: SearchKindVolume, ID: "webdata", Name: "webdata"},
+	}
+
+	type scenario struct {
+		testName       string
+		query          string
+		expectedNames  []string
+		expectedFields []string
+	}
+
+	scenarios := []scenario{
+		{
+			testName:       "Names, best match first",
+			query:          "web",
+			expectedNames:  []string{"web", "webdata", "shop_web_1"},
+			expectedFields: []string{"web", "webdata", "shop_web_1"},
+		},
+		{
+			testName:       "Container ID prefix",
+			query:          "c0ff",
+			expectedNames:  []string{"shop_web_1"},
+			expectedFields: []string{"c0ffee1234"},
+		},
+		{
+			testName:       "Image ID without its algorithm",
+			query:          "abc1",
+			expectedNames:  []string{"nginx:latest"},
+			expectedFields: []string{"abc123"},
+		},
+		{
+			testName:       "Label",
+			query:          "maintainers",
+			expectedNames:  []string{"nginx:latest"},
+			expectedFields: []string{"maintainer=NGINX Docker Maintainers"},
+		},
+		{
+			// 'ee' would fuzzily match the middle of both IDs, but IDs only
+			// match from the start
+			testName:       "No fuzzy matching against IDs",
+			query:          "ee",
+			expectedNames:  []string{},
+			expectedFields: []string{},
+		},
+		{
+			testName:       "Blank query",
+			query:          "  ",
+			expectedNames:  []string{},
+			expectedFields: []string{},
+		},
+	}
+
+	for _, s := range scenarios {
+		s := s
+		t.Run(s.testName, func(t *testing.T) {
+			results := Search(s.query, items, 10)
+			names := []string{}
+			fields := []string{}
+			for _, result := range results {
+				names = append(names, result.Item.Name)
+				fields = append(fields, result.Field)
+			}
+			assert.EqualValues(t, s.expectedNames, names)
+			assert.EqualValues(t, s.expectedFields, fields)
+		})
+	}
+
+	assert.Len(t, Search("e", items, 2), 2)
+}
+
+func TestGetSearchItems(t *testing.T) {
+	items := GetSearchItems(
+		[]*Container{{ID: "c1", Name: "web", Container: types.Container{Labels: map[string]string{"a": "b"}}}},
+		[]*Image{{ID: "sha256:i1", Name: "nginx", Tag: "latest"}, {ID: "sha256:i2", Name: "<none>"}},
+		[]*Volume{{Name: "data"}},
+		[]*Network{{ID: "n1", Name: "backend", Network: types.NetworkResource{Labels: map[string]string{"c": "d"}}}},
+	)
+
+	assert.EqualValues(t, []SearchItem{
+		{Kind: SearchKindContainer, ID: "c1", Name: "web", Labels: map[string]string{"a": "b"}},
+		{Kind: SearchKindImage, ID: "sha256:i1", Name: "nginx:latest"},
+		{Kind: SearchKindImage, ID: "sha256:i2", Name: "<none>"},
+		{Kind: SearchKindVolume, ID: "data", Name: "data"},
+		{Kind: SearchKindNetwork, ID: "n1", Name: "backend", Labels: map[string]string{"c": "d"}},
+	}, items)
+}
+
+func TestHighlightPositions(t *testing.T) {
+	highlighted := HighlightPositions("web_db", []int{0, 1, 4})
+
+	// highlighting only adds colour
+	assert.Equal(t, "web_db", utils.Decolorise(highlighted))
+	assert.Equal(t, utils.ColoredString("we", color.FgYellow)+"b_"+utils.ColoredString("d", color.FgYellow)+"b", highlighted)
+	assert.Equal(t, "web", HighlightPositions("web", nil))
+}
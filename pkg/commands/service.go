@@ -39,6 +39,7 @@ func (s *Service) Remove(options types.ContainerRemoveOptions) error {
 
 // Stop stops the service's containers
 func (s *Service) Stop() error {
+	defer s.DockerCommand.InvalidateContainerCache()
 	templateString := s.OSCommand.Config.UserConfig.CommandTemplates.StopService
 	command := utils.ApplyTemplate(
 		templateString,
@@ -49,6 +50,7 @@ func (s *Service) Stop() error {
 
 // Restart restarts the service
 func (s *Service) Restart() error {
+	defer s.DockerCommand.InvalidateContainerCache()
 	templateString := s.OSCommand.Config.UserConfig.CommandTemplates.RestartService
 	command := utils.ApplyTemplate(
 		templateString,
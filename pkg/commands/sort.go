@@ -0,0 +1,279 @@
+package commands
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	units "github.com/docker/go-units"
+)
+
+// liveSortInterval is how long we keep containers where they are when
+// they're sorted by what they're using, which would otherwise have them
+// jumping about with every stats tick
+const liveSortInterval = 5 * time.Second
+
+// SortField is what a list is sorted by
+type SortField string
+
+const (
+	SortByName    SortField = "name"
+	SortByStatus  SortField = "status"
+	SortByCreated SortField = "created"
+	SortBySize    SortField = "size"
+	SortByCPU     SortField = "cpu"
+	SortByMemory  SortField = "memory"
+)
+
+// ContainerSortFields are what the containers list can be sorted by. We
+// don't ask the daemon for container sizes, as that's slow, so size isn't one.
+var ContainerSortFields = []SortField{SortByStatus, SortByName, SortByCreated, SortByCPU, SortByMemory}
+
+// ImageSortFields are what the images list can be sorted by
+var ImageSortFields = []SortField{SortByName, SortByCreated, SortBySize}
+
+// containerStateOrder is the order containers go in when sorted by status
+var containerStateOrder = map[string]int{
+	"running": 1,
+	"exited":  2,
+	"created": 3,
+}
+
+// Live tells us whether the field changes from one stats tick to the next
+func (f SortField) Live() bool {
+	return f == SortByCPU || f == SortByMemory
+}
+
+// startsDescending tells us whether the field is first sorted biggest or
+// newest first, that being what you usually want to see at the top
+func (f SortField) startsDescending() bool {
+	switch f {
+	case SortByCreated, SortBySize, SortByCPU, SortByMemory:
+		return true
+	}
+	return false
+}
+
+// SortOrder is how a list is sorted. The zero value leaves the list in its
+// usual order.
+type SortOrder struct {
+	Field      SortField
+	Descending bool
+}
+
+// Select sorts by field, flipping the direction if we're already sorting by
+// it
+func (o SortOrder) Select(field SortField) SortOrder {
+	if o.Field == field {
+		return o.Reversed()
+	}
+	return SortOrder{Field: field, Descending: field.startsDescending()}
+}
+
+// Reversed sorts the other way
+func (o SortOrder) Reversed() SortOrder {
+	if o.Field == "" {
+		return o
+	}
+	o.Descending = !o.Descending
+	return o
+}
+
+// ContainerSort is how the containers list is sorted
+func (c *DockerCommand) ContainerSort() SortOrder {
+	c.ContainerMutex.Lock()
+	defer c.ContainerMutex.Unlock()
+
+	return c.containerSort
+}
+
+// SetContainerSort sorts the containers list, from the next refresh on
+func (c *DockerCommand) SetContainerSort(order SortOrder) {
+	c.ContainerMutex.Lock()
+	defer c.ContainerMutex.Unlock()
+
+	c.containerSort = order
+}
+
+// ImageSort is how the images list is sorted
+func (c *DockerCommand) ImageSort() SortOrder {
+	c.ContainerMutex.Lock()
+	defer c.ContainerMutex.Unlock()
+
+	return c.imageSort
+}
+
+// SetImageSort sorts the images list, from the next refresh on
+func (c *DockerCommand) SetImageSort(order SortOrder) {
+	c.ContainerMutex.Lock()
+	defer c.ContainerMutex.Unlock()
+
+	c.imageSort = order
+}
+
+// sortContainers sorts the containers by order. It's a stable sort, and
+// containers that tie go by name, so they don't swap places between
+// refreshes.
+func sortContainers(containers []*Container, order SortOrder, usage map[string]float64) {
+	compare := func(left, right *Container) int {
+		switch order.Field {
+		case SortByName:
+			return strings.Compare(left.Name, right.Name)
+		case SortByStatus:
+			return containerStateOrder[left.Container.State] - containerStateOrder[right.Container.State]
+		case SortByCreated:
+			return compareInt64(left.Container.Created, right.Container.Created)
+		case SortByCPU, SortByMemory:
+			return compareFloat64(usage[left.ID], usage[right.ID])
+		}
+		return 0
+	}
+
+	sort.SliceStable(containers, func(i, j int) bool {
+		result := compare(containers[i], containers[j])
+		if order.Descending {
+			result = -result
+		}
+		if result == 0 {
+			return containers[i].Name < containers[j].Name
+		}
+		return result < 0
+	})
+}
+
+// sortImages does the same for images, which tie by name, then tag, then ID
+func sortImages(images []*Image, order SortOrder) {
+	if order.Field == "" {
+		return
+	}
+
+	compare := func(left, right *Image) int {
+		switch order.Field {
+		case SortByName:
+			if left.Name != right.Name {
+				return strings.Compare(left.Name, right.Name)
+			}
+			return strings.Compare(left.Tag, right.Tag)
+		case SortByCreated:
+			return compareInt64(left.Image.Created, right.Image.Created)
+		case SortBySize:
+			return compareInt64(left.Image.Size, right.Image.Size)
+		}
+		return 0
+	}
+
+	sort.SliceStable(images, func(i, j int) bool {
+		result := compare(images[i], images[j])
+		if order.Descending {
+			result = -result
+		}
+		if result != 0 {
+			return result < 0
+		}
+		if images[i].Name != images[j].Name {
+			return images[i].Name < images[j].Name
+		}
+		if images[i].Tag != images[j].Tag {
+			return images[i].Tag < images[j].Tag
+		}
+		return images[i].ID < images[j].ID
+	})
+}
+
+// containerUsage is how much of field each container is using as of its
+// latest stats from `docker stats`, keyed by container ID. That's the only
+// place we get stats for every container from. Containers without stats count
+// as using nothing.
+func (c *DockerCommand) containerUsage(containers []*Container, field SortField) map[string]float64 {
+	c.ContainerMutex.Lock()
+	defer c.ContainerMutex.Unlock()
+
+	usage := make(map[string]float64, len(containers))
+	for _, container := range containers {
+		var value float64
+		var err error
+		if field == SortByCPU {
+			value, err = parseCPUPerc(container.CLIStats.CPUPerc)
+		} else {
+			value, err = parseMemUsage(container.CLIStats.MemUsage)
+		}
+		if err != nil {
+			// e.g. '--' for a container that's not running, or no stats yet
+			continue
+		}
+		usage[container.ID] = value
+	}
+	return usage
+}
+
+// parseCPUPerc parses the CPU usage as `docker stats` shows it, e.g. '12.5%'
+func parseCPUPerc(cpuPerc string) (float64, error) {
+	return strconv.ParseFloat(strings.TrimSuffix(cpuPerc, "%"), 64)
+}
+
+// parseMemUsage parses the memory usage as `docker stats` shows it, e.g.
+// '12.5MiB / 1.944GiB', into the bytes used
+func parseMemUsage(memUsage string) (float64, error) {
+	used := strings.TrimSpace(strings.SplitN(memUsage, "/", 2)[0])
+	bytes, err := units.RAMInBytes(used)
+	return float64(bytes), err
+}
+
+// liveSort keeps containers sorted by what they're using in the same order
+// for liveSortInterval at a time. It's only used while refreshing, which
+// ServiceMutex guards.
+type liveSort struct {
+	order    SortOrder
+	sortedAt time.Time
+	// ranks are where each container ended up last time we sorted, by ID
+	ranks map[string]int
+}
+
+func (l *liveSort) sort(containers []*Container, order SortOrder, usage map[string]float64, now time.Time) {
+	if order == l.order && now.Sub(l.sortedAt) < liveSortInterval {
+		// containers new since we last sorted go at the bottom
+		rank := func(container *Container) int {
+			if rank, ok := l.ranks[container.ID]; ok {
+				return rank
+			}
+			return len(l.ranks)
+		}
+		sort.SliceStable(containers, func(i, j int) bool {
+			left, right := rank(containers[i]), rank(containers[j])
+			if left == right {
+				return containers[i].Name < containers[j].Name
+			}
+			return left < right
+		})
+		return
+	}
+
+	sortContainers(containers, order, usage)
+	l.order = order
+	l.sortedAt = now
+	l.ranks = make(map[string]int, len(containers))
+	for i, container := range containers {
+		l.ranks[container.ID] = i
+	}
+}
+
+func compareInt64(left, right int64) int {
+	switch {
+	case left < right:
+		return -1
+	case left > right:
+		return 1
+	}
+	return 0
+}
+
+func compareFloat64(left, right float64) int {
+	switch {
+	case left < right:
+		return -1
+	case left > right:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,183 @@
+package commands
+
+import (
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func sortTestContainers() []*Container {
+	return []*Container{
+		{ID: "a", Name: "api", Container: types.Container{State: "running", Created: 30}},
+		{ID: "d", Name: "db", Container: types.Container{State: "exited", Created: 10}},
+		{ID: "c", Name: "cache", Container: types.Container{State: "running", Created: 20}},
+		{ID: "w", Name: "worker", Container: types.Container{State: "created", Created: 20}},
+	}
+}
+
+func containerNames(containers []*Container) []string {
+	names := make([]string, len(containers))
+	for i, container := range containers {
+		names[i] = container.Name
+	}
+	return names
+}
+
+func TestSortContainersBy(t *testing.T) {
+	usage := map[string]float64{"a": 5, "c": 50, "w": 5}
+
+	type scenario struct {
+		testName string
+		order    SortOrder
+		expected []string
+	}
+
+	scenarios := []scenario{
+		{
+			testName: "By name",
+			order:    SortOrder{Field: SortByName},
+			expected: []string{"api", "cache", "db", "worker"},
+		},
+		{
+			testName: "By name, descending",
+			order:    SortOrder{Field: SortByName, Descending: true},
+			expected: []string{"worker", "db", "cache", "api"},
+		},
+		{
+			testName: "By status, ties going by name",
+			order:    SortOrder{Field: SortByStatus},
+			expected: []string{"api", "cache", "db", "worker"},
+		},
+		{
+			testName: "By created, newest first, ties still going by name",
+			order:    SortOrder{Field: SortByCreated, Descending: true},
+			expected: []string{"api", "cache", "worker", "db"},
+		},
+		{
+			testName: "By CPU, with no stats counting as nothing",
+			order:    SortOrder{Field: SortByCPU, Descending: true},
+			expected: []string{"cache", "api", "worker", "db"},
+		},
+		{
+			testName: "By CPU, ascending",
+			order:    SortOrder{Field: SortByCPU},
+			expected: []string{"db", "api", "worker", "cache"},
+		},
+	}
+
+	for _, s := range scenarios {
+		s := s
+		t.Run(s.testName, func(t *testing.T) {
+			containers := sortTestContainers()
+			sortContainers(containers, s.order, usage)
+			assert.Equal(t, s.expected, containerNames(containers))
+		})
+	}
+}
+
+func TestDockerCommandContainerUsage(t *testing.T) {
+	// as `docker stats` leaves them, with no stat history from streaming
+	containers := []*Container{
+		{ID: "a", CLIStats: ContainerCliStat{CPUPerc: "12.50%", MemUsage: "1.5GiB / 7.6GiB"}},
+		{ID: "c", CLIStats: ContainerCliStat{CPUPerc: "0.07%", MemUsage: "24MiB / 7.6GiB"}},
+		{ID: "d", CLIStats: ContainerCliStat{CPUPerc: "--", MemUsage: "-- / --"}},
+		{ID: "w"},
+	}
+	dockerCommand := &DockerCommand{}
+
+	assert.Equal(t, map[string]float64{"a": 12.5, "c": 0.07}, dockerCommand.containerUsage(containers, SortByCPU))
+	assert.Equal(t, map[string]float64{"a": 1.5 * 1024 * 1024 * 1024, "c": 24 * 1024 * 1024}, dockerCommand.containerUsage(containers, SortByMemory))
+}
+
+func TestSortImages(t *testing.T) {
+	images := func() []*Image {
+		return []*Image{
+			{ID: "3", Name: "node", Tag: "latest", Image: types.ImageSummary{Created: 100, Size: 900}},
+			{ID: "1", Name: "alpine", Tag: "3.12", Image: types.ImageSummary{Created: 300, Size: 5}},
+			{ID: "5", Name: "none", Tag: "", Image: types.ImageSummary{Created: 200, Size: 5}},
+			{ID: "2", Name: "alpine", Tag: "3.11", Image: types.ImageSummary{Created: 50, Size: 5}},
+		}
+	}
+
+	type scenario struct {
+		testName string
+		order    SortOrder
+		expected []string
+	}
+
+	scenarios := []scenario{
+		{
+			testName: "In the daemon's order until asked otherwise",
+			order:    SortOrder{},
+			expected: []string{"3", "1", "5", "2"},
+		},
+		{
+			testName: "By name then tag",
+			order:    SortOrder{Field: SortByName},
+			expected: []string{"2", "1", "3", "5"},
+		},
+		{
+			testName: "By size, biggest first, ties going by name",
+			order:    SortOrder{Field: SortBySize, Descending: true},
+			expected: []string{"3", "2", "1", "5"},
+		},
+		{
+			testName: "By created, oldest first",
+			order:    SortOrder{Field: SortByCreated},
+			expected: []string{"2", "3", "5", "1"},
+		},
+	}
+
+	for _, s := range scenarios {
+		s := s
+		t.Run(s.testName, func(t *testing.T) {
+			sorted := images()
+			sortImages(sorted, s.order)
+			ids := make([]string, len(sorted))
+			for i, image := range sorted {
+				ids[i] = image.ID
+			}
+			assert.Equal(t, s.expected, ids)
+		})
+	}
+}
+
+func TestSortOrderSelect(t *testing.T) {
+	order := SortOrder{}.Select(SortByName)
+	assert.Equal(t, SortOrder{Field: SortByName}, order)
+
+	order = order.Select(SortByName)
+	assert.Equal(t, SortOrder{Field: SortByName, Descending: true}, order)
+
+	order = order.Select(SortByMemory)
+	assert.Equal(t, SortOrder{Field: SortByMemory, Descending: true}, order)
+
+	assert.Equal(t, SortOrder{Field: SortByMemory}, order.Reversed())
+	assert.Equal(t, SortOrder{}, SortOrder{}.Reversed())
+}
+
+func TestLiveSortWaitsBeforeResorting(t *testing.T) {
+	order := SortOrder{Field: SortByCPU, Descending: true}
+	start := time.Unix(1000, 0)
+	live := &liveSort{}
+
+	containers := sortTestContainers()
+	live.sort(containers, order, map[string]float64{"a": 10, "c": 20}, start)
+	assert.Equal(t, []string{"cache", "api", "db", "worker"}, containerNames(containers))
+
+	// the usage has swapped round, but it's too soon to move anything, and a
+	// new container goes at the bottom
+	containers = append(sortTestContainers(), &Container{ID: "b", Name: "backup"})
+	live.sort(containers, order, map[string]float64{"a": 90, "c": 1, "b": 99}, start.Add(time.Second))
+	assert.Equal(t, []string{"cache", "api", "db", "worker", "backup"}, containerNames(containers))
+
+	live.sort(containers, order, map[string]float64{"a": 90, "c": 1, "b": 99}, start.Add(liveSortInterval))
+	assert.Equal(t, []string{"backup", "api", "cache", "db", "worker"}, containerNames(containers))
+
+	// changing how we sort doesn't wait
+	order = order.Reversed()
+	live.sort(containers, order, map[string]float64{"a": 90, "c": 1, "b": 99}, start.Add(liveSortInterval+time.Second))
+	assert.Equal(t, []string{"db", "worker", "cache", "api", "backup"}, containerNames(containers))
+}
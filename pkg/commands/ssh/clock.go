@@ -0,0 +1,74 @@
+package ssh
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// withTimeout is context.WithTimeout, but driven by deps.now and deps.after so
+// that tests can decide when the timeout elapses
+func (self *SSHHandler) withTimeout(parent context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	ctx := &clockContext{
+		Context:  parent,
+		deadline: self.deps.now().Add(timeout),
+		done:     make(chan struct{}),
+	}
+	if parentDeadline, ok := parent.Deadline(); ok && parentDeadline.Before(ctx.deadline) {
+		ctx.deadline = parentDeadline
+	}
+
+	cancelled := make(chan struct{})
+	var cancelOnce sync.Once
+	cancel := func() {
+		cancelOnce.Do(func() { close(cancelled) })
+	}
+
+	timer := self.deps.after(timeout)
+	go func() {
+		select {
+		case <-parent.Done():
+			ctx.finish(parent.Err())
+		case <-timer:
+			ctx.finish(context.DeadlineExceeded)
+		case <-cancelled:
+			ctx.finish(context.Canceled)
+		}
+	}()
+
+	return ctx, cancel
+}
+
+// clockContext is a context which is done when its parent is, when it's
+// cancelled, or when its clock says the deadline has passed
+type clockContext struct {
+	context.Context
+	deadline time.Time
+
+	mutex sync.Mutex
+	err   error
+	done  chan struct{}
+}
+
+func (c *clockContext) Deadline() (time.Time, bool) {
+	return c.deadline, true
+}
+
+func (c *clockContext) Done() <-chan struct{} {
+	return c.done
+}
+
+func (c *clockContext) Err() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.err
+}
+
+func (c *clockContext) finish(err error) {
+	c.mutex.Lock()
+	c.err = err
+	c.mutex.Unlock()
+
+	close(c.done)
+}
@@ -0,0 +1,209 @@
+package ssh
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeClock stands in for time.Now and time.After. Timers only fire when the
+// test says so, except for the durations in immediate, which fire straight away.
+type fakeClock struct {
+	mutex     sync.Mutex
+	now       time.Time
+	immediate map[time.Duration]bool
+	timers    map[time.Duration]chan time.Time
+	requested []time.Duration
+}
+
+func newFakeClock(immediate ...time.Duration) *fakeClock {
+	clock := &fakeClock{
+		now:       time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		immediate: map[time.Duration]bool{},
+		timers:    map[time.Duration]chan time.Time{},
+	}
+	for _, d := range immediate {
+		clock.immediate[d] = true
+	}
+	return clock
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.requested = append(c.requested, d)
+	if c.immediate[d] {
+		ch := make(chan time.Time, 1)
+		ch <- c.now
+		return ch
+	}
+	return c.timer(d)
+}
+
+// fire fires every timer of the given duration, including ones created later
+func (c *fakeClock) fire(d time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	close(c.timer(d))
+	c.immediate[d] = true
+}
+
+func (c *fakeClock) advance(d time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.now = c.now.Add(d)
+}
+
+func (c *fakeClock) timer(d time.Duration) chan time.Time {
+	if _, ok := c.timers[d]; !ok {
+		c.timers[d] = make(chan time.Time)
+	}
+	return c.timers[d]
+}
+
+func (c *fakeClock) requestedDurations() []time.Duration {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return append([]time.Duration{}, c.requested...)
+}
+
+func TestSSHHandlerWithTimeout(t *testing.T) {
+	type scenario struct {
+		testName    string
+		trigger     func(clock *fakeClock, parentCancel context.CancelFunc, cancel context.CancelFunc)
+		expectedErr error
+	}
+
+	scenarios := []scenario{
+		{
+			testName:    "Timeout elapses",
+			trigger:     func(clock *fakeClock, parentCancel, cancel context.CancelFunc) { clock.fire(time.Second) },
+			expectedErr: context.DeadlineExceeded,
+		},
+		{
+			testName:    "Parent cancelled",
+			trigger:     func(clock *fakeClock, parentCancel, cancel context.CancelFunc) { parentCancel() },
+			expectedErr: context.Canceled,
+		},
+		{
+			testName:    "Cancelled",
+			trigger:     func(clock *fakeClock, parentCancel, cancel context.CancelFunc) { cancel() },
+			expectedErr: context.Canceled,
+		},
+	}
+
+	for _, s := range scenarios {
+		s := s
+		t.Run(s.testName, func(t *testing.T) {
+			clock := newFakeClock()
+			handler := NewSSHHandler()
+			handler.deps.now = clock.Now
+			handler.deps.after = clock.After
+
+			parent, parentCancel := context.WithCancel(context.Background())
+			defer parentCancel()
+			ctx, cancel := handler.withTimeout(parent, time.Second)
+			defer cancel()
+
+			deadline, ok := ctx.Deadline()
+			assert.True(t, ok)
+			assert.Equal(t, clock.Now().Add(time.Second), deadline)
+			assert.NoError(t, ctx.Err())
+
+			s.trigger(clock, parentCancel, cancel)
+
+			select {
+			case <-ctx.Done():
+			case <-time.After(5 * time.Second):
+				t.Fatal("context never finished")
+			}
+			assert.Equal(t, s.expectedErr, ctx.Err())
+		})
+	}
+}
+
+func TestSSHHandlerRetrySocketDialBackoff(t *testing.T) {
+	// backoff timers fire straight away so that only the dial results matter
+	clock := newFakeClock(100*time.Millisecond, 200*time.Millisecond, 400*time.Millisecond, 800*time.Millisecond, time.Second)
+	handler := NewSSHHandler(WithDialTimeout(5 * time.Second))
+	handler.deps.now = clock.Now
+	handler.deps.after = clock.After
+
+	dials := 0
+	handler.deps.dialContext = func(ctx context.Context, network string, address string) (io.Closer, error) {
+		dials++
+		if dials < 7 {
+			return nil, errors.New("connection refused")
+		}
+		return noopCloser{}, nil
+	}
+
+	attempts, err := handler.retrySocketDial(context.Background(), unixEndpoint("/tmp/lazydocker-ssh-tunnel-12345/dockerhost.sock"))
+	assert.NoError(t, err)
+	assert.Equal(t, 7, attempts)
+
+	backoff := []time.Duration{}
+	for _, d := range clock.requestedDurations() {
+		// skip the per-attempt dial timeouts
+		if d != 5*time.Second {
+			backoff = append(backoff, d)
+		}
+	}
+	assert.EqualValues(t, []time.Duration{
+		100 * time.Millisecond,
+		200 * time.Millisecond,
+		400 * time.Millisecond,
+		800 * time.Millisecond,
+		time.Second,
+		time.Second,
+		time.Second,
+	}, backoff)
+}
+
+func TestSSHHandlerWaitForSocketTimeoutWithFakeClock(t *testing.T) {
+	clock := newFakeClock(100*time.Millisecond, 200*time.Millisecond, 400*time.Millisecond, 800*time.Millisecond, time.Second)
+	remainings := []time.Duration{}
+	handler := NewSSHHandler(WithDialTimeout(5*time.Second), WithProgress(func(attempt int, remaining time.Duration) {
+		remainings = append(remainings, remaining)
+	}))
+	handler.deps.now = clock.Now
+	handler.deps.after = clock.After
+	handler.deps.sshConfig = noSSHConfig
+
+	dials := 0
+	handler.deps.dialContext = func(ctx context.Context, network string, address string) (io.Closer, error) {
+		dials++
+		clock.advance(time.Second)
+		// the tunnel timeout elapses during the third attempt
+		if dials == 3 {
+			clock.fire(defaultTunnelTimeout)
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+		return nil, errors.New("connection refused")
+	}
+
+	attempts, err := handler.waitForSocket(context.Background(), tunnelTarget{host: "192.168.5.178"}, unixEndpoint("/tmp/lazydocker-ssh-tunnel-12345/192.168.5.178.sock"), newTailBuffer(maxStderrTailLength))
+	assert.Error(t, err)
+	assert.True(t, errors.Is(err, ErrTunnelTimeout))
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.Contains(t, err.Error(), "gave up after 8s")
+	assert.Equal(t, 3, attempts)
+	assert.EqualValues(t, []time.Duration{8 * time.Second, 7 * time.Second, 6 * time.Second}, remainings)
+}
@@ -0,0 +1,225 @@
+package ssh
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// DefaultDockerContext is the context docker uses when none has been
+// selected. It has no stored endpoint: it means DOCKER_HOST or the default
+// socket.
+const DefaultDockerContext = "default"
+
+// dockerConfigFile is the bit of ~/.docker/config.json we care about
+type dockerConfigFile struct {
+	CurrentContext string `json:"currentContext"`
+}
+
+// dockerContextMeta is the bit of a context's meta.json we care about
+type dockerContextMeta struct {
+	Name     string `json:"Name"`
+	Metadata struct {
+		Description string `json:"Description"`
+	} `json:"Metadata"`
+	Endpoints map[string]struct {
+		Host string `json:"Host"`
+	} `json:"Endpoints"`
+}
+
+// DockerContext is a docker context, as created with `docker context create`
+type DockerContext struct {
+	Name        string
+	Description string
+	// Host is blank for the default context, which means DOCKER_HOST or the
+	// default socket
+	Host string
+	// TLSDir holds the context's ca.pem, cert.pem and key.pem, if it has any
+	TLSDir string
+}
+
+// Env is what DOCKER_HOST, DOCKER_CERT_PATH and DOCKER_TLS_VERIFY should be to
+// talk to the context's daemon the way the docker CLI would. Those left out
+// should be unset.
+func (c DockerContext) Env() map[string]string {
+	env := map[string]string{"DOCKER_HOST": c.Host}
+	if c.TLSDir != "" {
+		env["DOCKER_CERT_PATH"] = c.TLSDir
+		env["DOCKER_TLS_VERIFY"] = "1"
+	}
+	return env
+}
+
+// activeDockerContext returns the active docker context, as selected with
+// `docker context use` or DOCKER_CONTEXT, or one with a blank host if it's the
+// default context. This is what the docker CLI connects to when DOCKER_HOST
+// isn't set.
+func (self *SSHHandler) activeDockerContext() (DockerContext, error) {
+	configDir, err := self.dockerConfigDir()
+	if err != nil {
+		return DockerContext{}, err
+	}
+
+	contextName, err := self.currentDockerContextName(configDir)
+	if err != nil {
+		return DockerContext{}, err
+	}
+	if contextName == DefaultDockerContext {
+		return DockerContext{Name: DefaultDockerContext}, nil
+	}
+
+	meta, err := self.readDockerContextMeta(configDir, contextName)
+	if err != nil {
+		return DockerContext{}, err
+	}
+	return DockerContext{
+		Name:        contextName,
+		Description: meta.Metadata.Description,
+		Host:        meta.Endpoints["docker"].Host,
+		TLSDir:      self.dockerContextTLSDir(configDir, dockerContextDirName(contextName)),
+	}, nil
+}
+
+// ActiveDockerContextName is the name of the docker context we connect to on
+// startup. That's the default context when DOCKER_HOST is set, or when the
+// selected context can't be read, since then we fall back to the default
+// socket.
+func (self *SSHHandler) ActiveDockerContextName() string {
+	if strings.TrimSpace(self.deps.getenv("DOCKER_HOST")) != "" {
+		return DefaultDockerContext
+	}
+	configDir, err := self.dockerConfigDir()
+	if err != nil {
+		return DefaultDockerContext
+	}
+	contextName, err := self.currentDockerContextName(configDir)
+	if err != nil || contextName == DefaultDockerContext {
+		return DefaultDockerContext
+	}
+	if _, err := self.readDockerContextMeta(configDir, contextName); err != nil {
+		return DefaultDockerContext
+	}
+	return contextName
+}
+
+// ListDockerContexts returns the default context followed by every context
+// in the docker config directory, sorted by name. Contexts we can't read are
+// logged and left out rather than hiding all the others.
+func (self *SSHHandler) ListDockerContexts() ([]DockerContext, error) {
+	configDir, err := self.dockerConfigDir()
+	if err != nil {
+		return nil, err
+	}
+
+	contexts := []DockerContext{{Name: DefaultDockerContext}}
+
+	metaDir := filepath.Join(configDir, "contexts", "meta")
+	entries, err := self.deps.readDir(metaDir)
+	if os.IsNotExist(err) {
+		return contexts, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read docker contexts: %w", err)
+	}
+
+	named := []DockerContext{}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		contents, err := self.deps.readFile(filepath.Join(metaDir, entry.Name(), "meta.json"))
+		if err != nil {
+			self.logger().Warnf("ignoring docker context in %s: %v", entry.Name(), err)
+			continue
+		}
+		var meta dockerContextMeta
+		if err := json.Unmarshal(contents, &meta); err != nil || meta.Name == "" {
+			self.logger().Warnf("ignoring docker context in %s: invalid meta.json", entry.Name())
+			continue
+		}
+
+		dockerContext := DockerContext{
+			Name:        meta.Name,
+			Description: meta.Metadata.Description,
+			Host:        meta.Endpoints["docker"].Host,
+		}
+		dockerContext.TLSDir = self.dockerContextTLSDir(configDir, entry.Name())
+		named = append(named, dockerContext)
+	}
+
+	sort.Slice(named, func(i, j int) bool { return named[i].Name < named[j].Name })
+	return append(contexts, named...), nil
+}
+
+// currentDockerContextName is the context selected with DOCKER_CONTEXT or
+// `docker context use`, or the default one if neither was
+func (self *SSHHandler) currentDockerContextName(configDir string) (string, error) {
+	contextName := self.deps.getenv("DOCKER_CONTEXT")
+	if contextName == "" {
+		contents, err := self.deps.readFile(filepath.Join(configDir, "config.json"))
+		if os.IsNotExist(err) {
+			return DefaultDockerContext, nil
+		}
+		if err != nil {
+			return "", fmt.Errorf("read docker config: %w", err)
+		}
+		var config dockerConfigFile
+		if err := json.Unmarshal(contents, &config); err != nil {
+			return "", fmt.Errorf("parse docker config: %w", err)
+		}
+		contextName = config.CurrentContext
+	}
+	if contextName == "" {
+		return DefaultDockerContext, nil
+	}
+	return contextName, nil
+}
+
+// dockerContextDirName is the name of the directories a context is stored in
+func dockerContextDirName(contextName string) string {
+	// contexts are stored under the sha256 of their name, so that any name
+	// makes for a valid directory
+	digest := sha256.Sum256([]byte(contextName))
+	return hex.EncodeToString(digest[:])
+}
+
+// dockerContextTLSDir is the directory holding the TLS files of the context
+// stored in dirName, or blank if it has none
+func (self *SSHHandler) dockerContextTLSDir(configDir string, dirName string) string {
+	tlsDir := filepath.Join(configDir, "contexts", "tls", dirName, "docker")
+	if _, err := self.deps.stat(tlsDir); err != nil {
+		return ""
+	}
+	return tlsDir
+}
+
+func (self *SSHHandler) readDockerContextMeta(configDir string, contextName string) (dockerContextMeta, error) {
+	metaPath := filepath.Join(configDir, "contexts", "meta", dockerContextDirName(contextName), "meta.json")
+	var meta dockerContextMeta
+	contents, err := self.deps.readFile(metaPath)
+	if err != nil {
+		return meta, fmt.Errorf("read docker context %q: %w", contextName, err)
+	}
+	if err := json.Unmarshal(contents, &meta); err != nil {
+		return meta, fmt.Errorf("parse docker context %q: %w", contextName, err)
+	}
+	return meta, nil
+}
+
+// dockerConfigDir is where the docker CLI keeps its config: $DOCKER_CONFIG,
+// falling back to ~/.docker
+func (self *SSHHandler) dockerConfigDir() (string, error) {
+	if dir := self.deps.getenv("DOCKER_CONFIG"); dir != "" {
+		return dir, nil
+	}
+	home, err := self.deps.homeDir()
+	if err != nil {
+		return "", fmt.Errorf("find home directory: %w", err)
+	}
+	return filepath.Join(home, ".docker"), nil
+}
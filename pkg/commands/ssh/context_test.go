@@ -0,0 +1,343 @@
+package ssh
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// writeDockerContext stores a context the way `docker context create` does
+func writeDockerContext(t *testing.T, configDir string, name string, meta string) {
+	digest := sha256.Sum256([]byte(name))
+	dir := filepath.Join(configDir, "contexts", "meta", hex.EncodeToString(digest[:]))
+	assert.NoError(t, os.MkdirAll(dir, 0700))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "meta.json"), []byte(meta), 0600))
+}
+
+func TestSSHHandlerActiveDockerContext(t *testing.T) {
+	type scenario struct {
+		testName            string
+		config              string
+		dockerContext       string
+		contexts            map[string]string
+		expectedHost        string
+		expectedErrorSubstr string
+	}
+
+	scenarios := []scenario{
+		{
+			testName:     "No docker config",
+			expectedHost: "",
+		},
+		{
+			testName:     "No current context",
+			config:       `{"auths": {}}`,
+			expectedHost: "",
+		},
+		{
+			testName:     "Default context",
+			config:       `{"currentContext": "default"}`,
+			expectedHost: "",
+		},
+		{
+			testName: "SSH context",
+			config:   `{"currentContext": "remote"}`,
+			contexts: map[string]string{
+				"remote": `{"Name": "remote", "Endpoints": {"docker": {"Host": "ssh://user@192.168.5.178"}}}`,
+			},
+			expectedHost: "ssh://user@192.168.5.178",
+		},
+		{
+			testName: "TCP context",
+			config:   `{"currentContext": "remote"}`,
+			contexts: map[string]string{
+				"remote": `{"Name": "remote", "Endpoints": {"docker": {"Host": "tcp://192.168.5.178:2376"}}}`,
+			},
+			expectedHost: "tcp://192.168.5.178:2376",
+		},
+		{
+			testName:      "DOCKER_CONTEXT overrides the current context",
+			config:        `{"currentContext": "remote"}`,
+			dockerContext: "other",
+			contexts: map[string]string{
+				"remote": `{"Name": "remote", "Endpoints": {"docker": {"Host": "ssh://user@192.168.5.178"}}}`,
+				"other":  `{"Name": "other", "Endpoints": {"docker": {"Host": "ssh://user@10.0.0.1"}}}`,
+			},
+			expectedHost: "ssh://user@10.0.0.1",
+		},
+		{
+			testName:      "DOCKER_CONTEXT set to default",
+			config:        `{"currentContext": "remote"}`,
+			dockerContext: "default",
+			expectedHost:  "",
+		},
+		{
+			testName:            "Missing context",
+			config:              `{"currentContext": "remote"}`,
+			expectedErrorSubstr: `read docker context "remote"`,
+		},
+		{
+			testName: "Invalid context",
+			config:   `{"currentContext": "remote"}`,
+			contexts: map[string]string{
+				"remote": `{"Name": `,
+			},
+			expectedErrorSubstr: `parse docker context "remote"`,
+		},
+		{
+			testName:            "Invalid docker config",
+			config:              `{"currentContext": `,
+			expectedErrorSubstr: "parse docker config",
+		},
+	}
+
+	for _, s := range scenarios {
+		s := s
+		t.Run(s.testName, func(t *testing.T) {
+			configDir, err := ioutil.TempDir("", "lazydocker-docker-config")
+			assert.NoError(t, err)
+			defer os.RemoveAll(configDir)
+
+			if s.config != "" {
+				assert.NoError(t, ioutil.WriteFile(filepath.Join(configDir, "config.json"), []byte(s.config), 0600))
+			}
+			for name, meta := range s.contexts {
+				writeDockerContext(t, configDir, name, meta)
+			}
+
+			handler := NewSSHHandler()
+			handler.deps.getenv = func(key string) string {
+				switch key {
+				case "DOCKER_CONFIG":
+					return configDir
+				case "DOCKER_CONTEXT":
+					return s.dockerContext
+				}
+				return ""
+			}
+
+			dockerContext, err := handler.activeDockerContext()
+			if s.expectedErrorSubstr == "" {
+				assert.NoError(t, err)
+			} else if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), s.expectedErrorSubstr)
+			}
+			assert.Equal(t, s.expectedHost, dockerContext.Host)
+		})
+	}
+}
+
+func TestSSHHandlerDockerConfigDirDefaultsToHome(t *testing.T) {
+	handler := NewSSHHandler()
+	handler.deps.getenv = func(key string) string { return "" }
+	handler.deps.homeDir = func() (string, error) { return "/home/user", nil }
+
+	dir, err := handler.dockerConfigDir()
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join("/home/user", ".docker"), dir)
+}
+
+func TestSSHHandlerHandleSSHDockerHostFromContext(t *testing.T) {
+	type scenario struct {
+		testName              string
+		contextHost           string
+		withTLS               bool
+		expectedStartCmdCount int
+		expectedDockerHost    string
+		expectedTLSVerify     string
+	}
+
+	scenarios := []scenario{
+		{
+			testName:              "SSH context is tunneled",
+			contextHost:           "ssh://user@192.168.5.178",
+			expectedStartCmdCount: 1,
+			expectedDockerHost:    "unix:///tmp/lazydocker-ssh-tunnel-12345/192.168.5.178.sock",
+		},
+		{
+			testName:              "TCP context is passed on to the docker client",
+			contextHost:           "tcp://192.168.5.178:2376",
+			expectedStartCmdCount: 0,
+			expectedDockerHost:    "tcp://192.168.5.178:2376",
+		},
+		{
+			testName:              "TLS context has its TLS files passed on to the docker client",
+			contextHost:           "tcp://192.168.5.178:2376",
+			withTLS:               true,
+			expectedStartCmdCount: 0,
+			expectedDockerHost:    "tcp://192.168.5.178:2376",
+			expectedTLSVerify:     "1",
+		},
+	}
+
+	for _, s := range scenarios {
+		s := s
+		t.Run(s.testName, func(t *testing.T) {
+			configDir, err := ioutil.TempDir("", "lazydocker-docker-config")
+			assert.NoError(t, err)
+			defer os.RemoveAll(configDir)
+
+			assert.NoError(t, ioutil.WriteFile(filepath.Join(configDir, "config.json"), []byte(`{"currentContext": "remote"}`), 0600))
+			writeDockerContext(t, configDir, "remote", `{"Name": "remote", "Endpoints": {"docker": {"Host": "`+s.contextHost+`"}}}`)
+			expectedCertPath := ""
+			if s.withTLS {
+				expectedCertPath = filepath.Join(configDir, "contexts", "tls", dockerContextDirName("remote"), "docker")
+				assert.NoError(t, os.MkdirAll(expectedCertPath, 0700))
+			}
+
+			// left over from some other daemon
+			env := map[string]string{"DOCKER_CONFIG": configDir, "DOCKER_CERT_PATH": "/home/user/old-certs", "DOCKER_TLS_VERIFY": "1"}
+			startCmdCount := 0
+			handler := &SSHHandler{
+				deps: dependencies{
+					now:    time.Now,
+					after:  time.After,
+					remove: func(path string) error { return os.ErrNotExist },
+					dialContext: func(ctx context.Context, network string, address string) (io.Closer, error) {
+						return noopCloser{}, nil
+					},
+					startCmd: func(cmd *exec.Cmd) error {
+						startCmdCount++
+						return nil
+					},
+					tempDir:  func(dir string, pattern string) (string, error) { return "/tmp/lazydocker-ssh-tunnel-12345", nil },
+					tempRoot: func() string { return "/tmp" },
+					getenv:   func(key string) string { return env[key] },
+					setenv: func(key, value string) error {
+						env[key] = value
+						return nil
+					},
+					unsetenv: func(key string) error {
+						delete(env, key)
+						return nil
+					},
+					lookPath:  func(file string) (string, error) { return "/usr/bin/" + file, nil },
+					sshConfig: noSSHConfig,
+					readFile:  ioutil.ReadFile,
+					stat:      os.Stat,
+					homeDir:   func() (string, error) { return "/home/user", nil },
+				},
+			}
+
+			_, err = handler.HandleSSHDockerHost(context.Background())
+			assert.NoError(t, err)
+			assert.Equal(t, s.expectedStartCmdCount, startCmdCount)
+			assert.Equal(t, s.expectedDockerHost, env["DOCKER_HOST"])
+			assert.Equal(t, expectedCertPath, env["DOCKER_CERT_PATH"])
+			assert.Equal(t, s.expectedTLSVerify, env["DOCKER_TLS_VERIFY"])
+		})
+	}
+}
+
+func TestSSHHandlerListDockerContexts(t *testing.T) {
+	configDir, err := ioutil.TempDir("", "lazydocker-docker-config")
+	assert.NoError(t, err)
+	defer os.RemoveAll(configDir)
+
+	writeDockerContext(t, configDir, "staging", `{"Name": "staging", "Metadata": {"Description": "the staging box"}, "Endpoints": {"docker": {"Host": "ssh://deploy@staging"}}}`)
+	writeDockerContext(t, configDir, "prod", `{"Name": "prod", "Endpoints": {"docker": {"Host": "tcp://prod:2376"}}}`)
+	writeDockerContext(t, configDir, "broken", `{"Name": `)
+
+	digest := sha256.Sum256([]byte("prod"))
+	tlsDir := filepath.Join(configDir, "contexts", "tls", hex.EncodeToString(digest[:]), "docker")
+	assert.NoError(t, os.MkdirAll(tlsDir, 0700))
+
+	handler := NewSSHHandler()
+	handler.deps.getenv = func(key string) string {
+		if key == "DOCKER_CONFIG" {
+			return configDir
+		}
+		return ""
+	}
+
+	contexts, err := handler.ListDockerContexts()
+	assert.NoError(t, err)
+	assert.EqualValues(t, []DockerContext{
+		{Name: "default"},
+		{Name: "prod", Host: "tcp://prod:2376", TLSDir: tlsDir},
+		{Name: "staging", Description: "the staging box", Host: "ssh://deploy@staging"},
+	}, contexts)
+
+	// with no contexts directory at all, there's just the default one
+	handler.deps.getenv = func(key string) string {
+		if key == "DOCKER_CONFIG" {
+			return filepath.Join(configDir, "missing")
+		}
+		return ""
+	}
+	contexts, err = handler.ListDockerContexts()
+	assert.NoError(t, err)
+	assert.EqualValues(t, []DockerContext{{Name: "default"}}, contexts)
+}
+
+func TestSSHHandlerActiveDockerContextName(t *testing.T) {
+	type scenario struct {
+		testName     string
+		config       string
+		env          map[string]string
+		expectedName string
+	}
+
+	scenarios := []scenario{
+		{
+			testName:     "No docker config",
+			expectedName: "default",
+		},
+		{
+			testName:     "Current context",
+			config:       `{"currentContext": "remote"}`,
+			expectedName: "remote",
+		},
+		{
+			testName:     "DOCKER_CONTEXT",
+			config:       `{"currentContext": "missing"}`,
+			env:          map[string]string{"DOCKER_CONTEXT": "remote"},
+			expectedName: "remote",
+		},
+		{
+			testName:     "DOCKER_HOST wins over the context",
+			config:       `{"currentContext": "remote"}`,
+			env:          map[string]string{"DOCKER_HOST": "tcp://10.0.0.1:2375"},
+			expectedName: "default",
+		},
+		{
+			// we fall back to the default socket, so that's what we're on
+			testName:     "Unreadable context",
+			config:       `{"currentContext": "missing"}`,
+			expectedName: "default",
+		},
+	}
+
+	for _, s := range scenarios {
+		s := s
+		t.Run(s.testName, func(t *testing.T) {
+			configDir, err := ioutil.TempDir("", "lazydocker-docker-config")
+			assert.NoError(t, err)
+			defer os.RemoveAll(configDir)
+
+			if s.config != "" {
+				assert.NoError(t, ioutil.WriteFile(filepath.Join(configDir, "config.json"), []byte(s.config), 0600))
+			}
+			writeDockerContext(t, configDir, "remote", `{"Name": "remote", "Endpoints": {"docker": {"Host": "ssh://user@192.168.5.178"}}}`)
+
+			handler := NewSSHHandler()
+			handler.deps.getenv = func(key string) string {
+				if key == "DOCKER_CONFIG" {
+					return configDir
+				}
+				return s.env[key]
+			}
+
+			assert.Equal(t, s.expectedName, handler.ActiveDockerContextName())
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package ssh
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrSSHBinaryMissing means there's no ssh binary on the PATH and we were
+	// told not to fall back to the native client
+	ErrSSHBinaryMissing = errors.New("ssh binary not found in PATH; required for ssh:// DOCKER_HOST")
+
+	// ErrTunnelTimeout means the tunneled socket didn't start accepting
+	// connections within the tunnel timeout
+	ErrTunnelTimeout = errors.New("ssh tunneled socket never became available")
+
+	// ErrDialAttemptsExhausted means we dialed the tunneled socket as many
+	// times as we were allowed to without it accepting a connection
+	ErrDialAttemptsExhausted = errors.New("ssh tunneled socket dial attempts exhausted")
+
+	// ErrMissingHost means the ssh:// DOCKER_HOST url has no host in it
+	ErrMissingHost = errors.New("ssh DOCKER_HOST missing host")
+
+	// ErrTLSFilesMissing means DOCKER_TLS_VERIFY is set but we can't find the
+	// certs the docker client needs
+	ErrTLSFilesMissing = errors.New("docker TLS files missing")
+
+	// ErrAuthFailed means the remote host rejected our credentials. Since we
+	// can't prompt, this usually means key-based auth isn't set up.
+	ErrAuthFailed = errors.New("ssh authentication failed")
+
+	// ErrNotTunneled means we aren't connected over an ssh tunnel, so there's
+	// no ssh connection to forward a port over
+	ErrNotTunneled = errors.New("not connected over an ssh tunnel")
+
+	// ErrTunnelDown means the tunnel has been closed or has dropped
+	ErrTunnelDown = errors.New("ssh tunnel is down")
+)
+
+// TunnelError is returned when we couldn't establish a tunnel to a host. Use
+// errors.Is with ErrTunnelTimeout, ErrDialAttemptsExhausted or ErrAuthFailed to
+// find out why.
+type TunnelError struct {
+	// Host is the ssh host we were tunneling to
+	Host string
+	// ResolvedHost is where Host points according to ~/.ssh/config, or blank
+	// if the config doesn't mention it
+	ResolvedHost string
+	// Stderr is the tail of ssh's output, if it printed anything
+	Stderr string
+	Cause  error
+}
+
+func (e *TunnelError) Error() string {
+	msg := e.Cause.Error()
+	// otherwise it's not obvious which machine ssh was complaining about
+	if e.ResolvedHost != "" {
+		msg = fmt.Sprintf("%s (%s resolves to %s via ssh config)", msg, e.Host, e.ResolvedHost)
+	}
+	if e.Stderr == "" {
+		return msg
+	}
+	return fmt.Sprintf("%s: %s%s", msg, e.Stderr, batchModeHint(e.Stderr))
+}
+
+func (e *TunnelError) Unwrap() error {
+	return e.Cause
+}
+
+// Is reports auth failures, which we only know about from what ssh printed
+func (e *TunnelError) Is(target error) bool {
+	return target == ErrAuthFailed && isAuthFailure(e.Stderr+e.Cause.Error())
+}
+
+func isAuthFailure(output string) bool {
+	// the former is from the ssh binary, the latter from the native client
+	return strings.Contains(output, "Permission denied") || strings.Contains(output, "unable to authenticate")
+}
+
+// sentinelError ties an underlying error to one of our sentinel errors, so
+// that errors.Is matches both
+type sentinelError struct {
+	sentinel error
+	err      error
+}
+
+func (e *sentinelError) Error() string {
+	return e.sentinel.Error() + ": " + e.err.Error()
+}
+
+func (e *sentinelError) Unwrap() error {
+	return e.err
+}
+
+func (e *sentinelError) Is(target error) bool {
+	return target == e.sentinel
+}
+
+// batchModeHint explains ssh failures caused by running in batch mode, where
+// the user would otherwise have been prompted
+func batchModeHint(stderr string) string {
+	switch {
+	case isAuthFailure(stderr):
+		return " (interactive authentication isn't supported; set up key-based authentication, e.g. by adding your key to ssh-agent or passing '-i <key>' via ssh options)"
+	case strings.Contains(stderr, "Host key verification failed"):
+		return " (the host isn't in your known_hosts and we can't prompt to add it; connect once with ssh to add it, or use StrictHostKeyChecking=accept-new)"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,55 @@
+package ssh
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTunnelErrorIs(t *testing.T) {
+	type scenario struct {
+		testName           string
+		err                *TunnelError
+		expectedAuthFailed bool
+		expectedTimeout    bool
+	}
+
+	timeout := &sentinelError{sentinel: ErrTunnelTimeout, err: context.DeadlineExceeded}
+
+	scenarios := []scenario{
+		{
+			testName:           "Timeout with auth failure from ssh",
+			err:                &TunnelError{Host: "myhost", Stderr: "user@myhost: Permission denied (publickey).", Cause: timeout},
+			expectedAuthFailed: true,
+			expectedTimeout:    true,
+		},
+		{
+			testName:           "Timeout without output",
+			err:                &TunnelError{Host: "myhost", Cause: timeout},
+			expectedAuthFailed: false,
+			expectedTimeout:    true,
+		},
+		{
+			testName:           "Auth failure from native client",
+			err:                &TunnelError{Host: "myhost", Cause: errors.New("ssh: handshake failed: ssh: unable to authenticate, attempted methods [none publickey], no supported methods remain")},
+			expectedAuthFailed: true,
+			expectedTimeout:    false,
+		},
+		{
+			testName:           "Unrelated failure",
+			err:                &TunnelError{Host: "myhost", Stderr: "ssh: Could not resolve hostname myhost", Cause: timeout},
+			expectedAuthFailed: false,
+			expectedTimeout:    true,
+		},
+	}
+
+	for _, s := range scenarios {
+		s := s
+		t.Run(s.testName, func(t *testing.T) {
+			assert.Equal(t, s.expectedAuthFailed, errors.Is(s.err, ErrAuthFailed))
+			assert.Equal(t, s.expectedTimeout, errors.Is(s.err, ErrTunnelTimeout))
+		})
+	}
+}
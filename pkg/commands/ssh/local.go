@@ -0,0 +1,78 @@
+package ssh
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"path/filepath"
+	"strconv"
+)
+
+// localEndpoint is the local end of the tunnel, i.e. what DOCKER_HOST ends up
+// pointing at
+type localEndpoint struct {
+	// network is "unix" for a socket or "tcp" for a loopback port
+	network string
+	// address is the socket path, or host:port for tcp
+	address string
+	// dir is the temp dir we own for the tunnel. It holds the socket, if
+	// there is one, and ssh's control socket.
+	dir string
+}
+
+func unixEndpoint(socketPath string) localEndpoint {
+	return localEndpoint{network: "unix", address: socketPath, dir: filepath.Dir(socketPath)}
+}
+
+// url is the endpoint in the form DOCKER_HOST takes
+func (e localEndpoint) url() string {
+	if e.network == "tcp" {
+		return "tcp://" + e.address
+	}
+	return (&url.URL{Scheme: "unix", Path: e.address}).String()
+}
+
+// parseLocalBind validates a local bind address of the form
+// tcp://127.0.0.1:<port>. Only loopback addresses are allowed: anything else
+// would hand the remote docker daemon to the whole network.
+func parseLocalBind(bind string) (host string, port int, err error) {
+	u, err := url.Parse(bind)
+	if err != nil || u.Scheme != "tcp" || u.Hostname() == "" || u.Port() == "" {
+		return "", 0, fmt.Errorf("invalid local bind address %q: expected tcp://127.0.0.1:<port>", bind)
+	}
+	if ip := net.ParseIP(u.Hostname()); ip == nil || !ip.IsLoopback() {
+		return "", 0, fmt.Errorf("invalid local bind address %q: only loopback addresses are allowed", bind)
+	}
+	port, err = strconv.Atoi(u.Port())
+	if err != nil || port < 0 || port > 65535 {
+		return "", 0, fmt.Errorf("invalid local bind address %q: bad port", bind)
+	}
+	return u.Hostname(), port, nil
+}
+
+// newLocalEndpoint decides where the local end of the tunnel goes: the socket
+// in dir, unless we've been asked to bind to a tcp port. A port of 0 gets a
+// free one from the OS.
+func (self *SSHHandler) newLocalEndpoint(dir string, socketName string) (localEndpoint, error) {
+	if self.localBind == "" {
+		return unixEndpoint(filepath.Join(dir, socketName)), nil
+	}
+
+	host, port, err := parseLocalBind(self.localBind)
+	if err != nil {
+		return localEndpoint{}, err
+	}
+	if port == 0 {
+		// ssh wants a concrete port, so we have the OS pick one and hand it
+		// straight back. Someone else could grab it in between, but then the
+		// tunnel fails to come up rather than doing anything surprising.
+		listener, err := self.deps.listen("tcp", net.JoinHostPort(host, "0"))
+		if err != nil {
+			return localEndpoint{}, fmt.Errorf("pick a free local port: %w", err)
+		}
+		port = listener.Addr().(*net.TCPAddr).Port
+		_ = listener.Close()
+	}
+
+	return localEndpoint{network: "tcp", address: net.JoinHostPort(host, strconv.Itoa(port)), dir: dir}, nil
+}
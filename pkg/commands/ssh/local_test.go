@@ -0,0 +1,170 @@
+package ssh
+
+import (
+	"context"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLocalBind(t *testing.T) {
+	type scenario struct {
+		testName            string
+		bind                string
+		expectedHost        string
+		expectedPort        int
+		expectedErrorSubstr string
+	}
+
+	scenarios := []scenario{
+		{
+			testName:     "Loopback address",
+			bind:         "tcp://127.0.0.1:2375",
+			expectedHost: "127.0.0.1",
+			expectedPort: 2375,
+		},
+		{
+			testName:     "Any free port",
+			bind:         "tcp://127.0.0.1:0",
+			expectedHost: "127.0.0.1",
+			expectedPort: 0,
+		},
+		{
+			testName:     "IPv6 loopback address",
+			bind:         "tcp://[::1]:2375",
+			expectedHost: "::1",
+			expectedPort: 2375,
+		},
+		{
+			testName:            "Non-loopback address",
+			bind:                "tcp://0.0.0.0:2375",
+			expectedErrorSubstr: "only loopback addresses are allowed",
+		},
+		{
+			testName:            "Hostname",
+			bind:                "tcp://localhost:2375",
+			expectedErrorSubstr: "only loopback addresses are allowed",
+		},
+		{
+			testName:            "Unix scheme",
+			bind:                "unix:///tmp/docker.sock",
+			expectedErrorSubstr: "expected tcp://127.0.0.1:<port>",
+		},
+		{
+			testName:            "Missing port",
+			bind:                "tcp://127.0.0.1",
+			expectedErrorSubstr: "expected tcp://127.0.0.1:<port>",
+		},
+		{
+			testName:            "Port out of range",
+			bind:                "tcp://127.0.0.1:70000",
+			expectedErrorSubstr: "bad port",
+		},
+	}
+
+	for _, s := range scenarios {
+		s := s
+		t.Run(s.testName, func(t *testing.T) {
+			host, port, err := parseLocalBind(s.bind)
+			if s.expectedErrorSubstr != "" {
+				if assert.Error(t, err) {
+					assert.Contains(t, err.Error(), s.expectedErrorSubstr)
+				}
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, s.expectedHost, host)
+			assert.Equal(t, s.expectedPort, port)
+		})
+	}
+}
+
+func TestSSHHandlerNewLocalEndpointPicksFreePort(t *testing.T) {
+	handler := NewSSHHandler(WithLocalBind("tcp://127.0.0.1:0"))
+
+	local, err := handler.newLocalEndpoint("/tmp/lazydocker-ssh-tunnel-12345", "192.168.5.178.sock")
+	assert.NoError(t, err)
+	assert.Equal(t, "tcp", local.network)
+	assert.Equal(t, "/tmp/lazydocker-ssh-tunnel-12345", local.dir)
+
+	host, port, err := net.SplitHostPort(local.address)
+	assert.NoError(t, err)
+	assert.Equal(t, "127.0.0.1", host)
+	n, err := strconv.Atoi(port)
+	assert.NoError(t, err)
+	assert.NotEqual(t, 0, n)
+
+	// the port should be free again for ssh to take
+	listener, err := net.Listen("tcp", local.address)
+	if assert.NoError(t, err) {
+		listener.Close()
+	}
+}
+
+func TestSSHHandlerHandleSSHDockerHostLocalBind(t *testing.T) {
+	env := map[string]string{"DOCKER_HOST": "ssh://myhost@192.168.5.178"}
+	var cmdArgs []string
+	dialed := []string{}
+	removed := []string{}
+	handler := &SSHHandler{
+		localBind: "tcp://127.0.0.1:2375",
+		deps: dependencies{
+			now:   time.Now,
+			after: time.After,
+			remove: func(path string) error {
+				removed = append(removed, path)
+				return os.ErrNotExist
+			},
+			dialContext: func(ctx context.Context, network string, address string) (io.Closer, error) {
+				dialed = append(dialed, network+" "+address)
+				return noopCloser{}, nil
+			},
+			startCmd: func(cmd *exec.Cmd) error {
+				cmdArgs = cmd.Args
+				return nil
+			},
+			tempDir:  func(dir string, pattern string) (string, error) { return "/tmp/lazydocker-ssh-tunnel-12345", nil },
+			tempRoot: func() string { return "/tmp" },
+			getenv:   func(key string) string { return env[key] },
+			setenv: func(key, value string) error {
+				env[key] = value
+				return nil
+			},
+			lookPath:  func(file string) (string, error) { return "/usr/bin/" + file, nil },
+			sshConfig: noSSHConfig,
+
+			initialDialInterval: time.Millisecond,
+		},
+	}
+
+	tunnel, err := handler.HandleSSHDockerHost(context.Background())
+	assert.NoError(t, err)
+
+	assert.Equal(t, "tcp://127.0.0.1:2375", tunnel.SocketPath())
+	assert.Equal(t, "tcp://127.0.0.1:2375", env["DOCKER_HOST"])
+	assert.EqualValues(t, []string{"-L", "127.0.0.1:2375:/var/run/docker.sock"}, cmdArgs[1:3])
+	assert.EqualValues(t, []string{"tcp 127.0.0.1:2375"}, dialed)
+	// there's no socket for a previous tunnel to have left behind
+	assert.Empty(t, removed)
+}
+
+func TestSSHHandlerOpenTunnelInvalidLocalBind(t *testing.T) {
+	handler := NewSSHHandler(WithLocalBind("tcp://0.0.0.0:2375"))
+	handler.deps.lookPath = func(file string) (string, error) { return "/usr/bin/" + file, nil }
+	handler.deps.sshConfig = noSSHConfig
+	handler.deps.startCmd = func(cmd *exec.Cmd) error {
+		t.Error("expected no tunnel to be started")
+		return nil
+	}
+
+	_, err := handler.OpenTunnel(context.Background(), "ssh://myhost@192.168.5.178")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "only loopback addresses are allowed")
+	}
+}
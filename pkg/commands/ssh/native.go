@@ -0,0 +1,386 @@
+package ssh
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"unsafe"
+
+	gossh "golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// defaultIdentityFiles are the keys we try when ~/.ssh/config doesn't name
+// any, in the order the ssh binary tries them
+var defaultIdentityFiles = []string{"~/.ssh/id_ed25519", "~/.ssh/id_ecdsa", "~/.ssh/id_rsa"}
+
+// nativeTarget is a tunnelTarget with the gaps filled in from ~/.ssh/config
+type nativeTarget struct {
+	// addr is the host:port to dial
+	addr string
+	user string
+	// identityFiles are the private keys to offer, with '~' expanded
+	identityFiles []string
+}
+
+// resolveNativeTarget does the job the ssh binary would otherwise do of
+// looking the host up in ~/.ssh/config. Anything given in the DOCKER_HOST url
+// takes precedence.
+func (self *SSHHandler) resolveNativeTarget(target tunnelTarget) (nativeTarget, error) {
+	alias := target.host
+
+	host, err := self.resolveSSHConfig(target)
+	if err != nil {
+		return nativeTarget{}, err
+	}
+
+	user := host.user
+	if user == "" {
+		user = self.deps.getenv("USER")
+	}
+	if user == "" {
+		user = self.deps.getenv("USERNAME")
+	}
+	if user == "" {
+		return nativeTarget{}, fmt.Errorf("could not work out the ssh user for %s; add one to the DOCKER_HOST url, e.g. ssh://user@%s", alias, alias)
+	}
+
+	identityFiles := self.deps.sshConfig(alias, "IdentityFile")
+	if len(identityFiles) == 0 {
+		identityFiles = defaultIdentityFiles
+	}
+	expanded := make([]string, 0, len(identityFiles))
+	for _, file := range identityFiles {
+		path, err := self.expandHome(file)
+		if err != nil {
+			return nativeTarget{}, err
+		}
+		expanded = append(expanded, path)
+	}
+
+	return nativeTarget{
+		addr:          host.addr(),
+		user:          user,
+		identityFiles: expanded,
+	}, nil
+}
+
+func (self *SSHHandler) expandHome(path string) (string, error) {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path, nil
+	}
+	home, err := self.deps.homeDir()
+	if err != nil {
+		return "", fmt.Errorf("find home directory: %w", err)
+	}
+	return filepath.Join(home, strings.TrimPrefix(path, "~")), nil
+}
+
+// loadSigners collects the keys we can authenticate with: those held by the
+// ssh agent, if it's running, followed by any unencrypted identity files. The
+// returned closer must be closed once the handshake is done, since signing with
+// agent keys goes via the agent connection.
+func (self *SSHHandler) loadSigners(identityFiles []string) ([]gossh.Signer, io.Closer, error) {
+	signers := []gossh.Signer{}
+	var agentConn io.Closer = noopCloser{}
+
+	if sock := self.deps.getenv("SSH_AUTH_SOCK"); sock != "" {
+		conn, err := net.Dial("unix", sock)
+		if err == nil {
+			agentSigners, err := agent.NewClient(conn).Signers()
+			if err == nil {
+				signers = append(signers, agentSigners...)
+				agentConn = conn
+			} else {
+				conn.Close()
+			}
+		}
+	}
+
+	for _, file := range identityFiles {
+		key, err := self.deps.readFile(file)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			agentConn.Close()
+			return nil, nil, fmt.Errorf("read ssh key: %w", err)
+		}
+		signer, err := gossh.ParsePrivateKey(key)
+		if err != nil {
+			// most likely passphrase protected, which we can't prompt for; the
+			// agent is the way to go for those
+			continue
+		}
+		signers = append(signers, signer)
+	}
+
+	if len(signers) == 0 {
+		agentConn.Close()
+		return nil, nil, fmt.Errorf("no usable ssh keys: none in the ssh agent and none of %s could be read without a passphrase", strings.Join(identityFiles, ", "))
+	}
+
+	return signers, agentConn, nil
+}
+
+// hostKeyCallback verifies the host against ~/.ssh/known_hosts, following the
+// configured StrictHostKeyChecking mode as closely as we can. Unlike ssh we
+// never add hosts to known_hosts ourselves.
+func (self *SSHHandler) hostKeyCallback() (gossh.HostKeyCallback, error) {
+	if self.hostKeyChecking == "no" || self.hostKeyChecking == "off" {
+		return gossh.InsecureIgnoreHostKey(), nil
+	}
+
+	path, err := self.expandHome("~/.ssh/known_hosts")
+	if err != nil {
+		return nil, err
+	}
+	callback, err := knownhosts.New(path)
+	if err != nil {
+		if self.hostKeyChecking == "accept-new" && os.IsNotExist(err) {
+			return gossh.InsecureIgnoreHostKey(), nil
+		}
+		return nil, fmt.Errorf("read known hosts: %w", err)
+	}
+
+	if self.hostKeyChecking == "accept-new" {
+		return func(hostname string, remote net.Addr, key gossh.PublicKey) error {
+			err := callback(hostname, remote, key)
+			var keyErr *knownhosts.KeyError
+			// no Want means the host is unknown, as opposed to its key having
+			// changed
+			if errors.As(err, &keyErr) && len(keyErr.Want) == 0 {
+				return nil
+			}
+			return err
+		}, nil
+	}
+
+	return callback, nil
+}
+
+// tunnelNative connects to the remote host in-process and forwards connections
+// made to the local endpoint on to the remote docker socket
+func (self *SSHHandler) tunnelNative(ctx context.Context, target tunnelTarget, local localEndpoint) (tunnelProcess, error) {
+	if target.jumpHosts != "" {
+		return nil, errors.New("jump hosts are not supported by the native ssh client; install ssh to use them")
+	}
+
+	resolved, err := self.resolveNativeTarget(target)
+	if err != nil {
+		return nil, err
+	}
+
+	// having no keys is fine if we can fall back on a password
+	auth := []gossh.AuthMethod{}
+	signers, agentConn, err := self.loadSigners(resolved.identityFiles)
+	if err == nil {
+		defer agentConn.Close()
+		auth = append(auth, gossh.PublicKeys(signers...))
+	} else if self.passwordPrompt == nil {
+		return nil, err
+	}
+	if self.passwordPrompt != nil {
+		password := &passwordAuth{prompt: self.passwordPrompt, user: resolved.user, host: resolved.addr}
+		defer password.wipe()
+		auth = append(auth, password.methods()...)
+	}
+
+	hostKeyCallback, err := self.hostKeyCallback()
+	if err != nil {
+		return nil, err
+	}
+
+	config := &gossh.ClientConfig{
+		User:            resolved.user,
+		Auth:            auth,
+		HostKeyCallback: hostKeyCallback,
+		Timeout:         self.getTunnelTimeout(),
+	}
+
+	client, err := self.deps.dialSSH(ctx, resolved.addr, config)
+	if err != nil {
+		return nil, fmt.Errorf("connect to %s: %w", resolved.addr, err)
+	}
+
+	listener, err := self.deps.listen(local.network, local.address)
+	if err != nil {
+		client.Close()
+		return nil, fmt.Errorf("listen on tunneled socket: %w", err)
+	}
+
+	process := &nativeProcess{client: client, listener: listener, remoteNetwork: "unix", remoteAddress: target.remoteSocket}
+	if target.remoteTCP != "" {
+		process.remoteNetwork, process.remoteAddress = "tcp", target.remoteTCP
+	}
+	go process.serve()
+
+	return process, nil
+}
+
+// passwordAuth answers the host's password and keyboard-interactive
+// challenges for one connection attempt. It only asks for the password the
+// first time it's wanted, so a host that takes keys never prompts, and wipe
+// zeroes it once the handshake's over.
+type passwordAuth struct {
+	prompt PasswordPrompt
+	user   string
+	host   string
+
+	asked    bool
+	password []byte
+	err      error
+}
+
+// methods are the auth methods to try after the keys: plain password, then
+// keyboard-interactive, which is how a lot of hosts ask for passwords
+func (a *passwordAuth) methods() []gossh.AuthMethod {
+	return []gossh.AuthMethod{
+		gossh.PasswordCallback(a.get),
+		gossh.KeyboardInteractive(func(name, instruction string, questions []string, echos []bool) ([]string, error) {
+			answers := make([]string, len(questions))
+			for i := range questions {
+				// a question you can see the answer to isn't after a
+				// password
+				if echos[i] {
+					return nil, fmt.Errorf("can't answer the host's question %q", questions[i])
+				}
+				answer, err := a.get()
+				if err != nil {
+					return nil, err
+				}
+				answers[i] = answer
+			}
+			return answers, nil
+		}),
+	}
+}
+
+func (a *passwordAuth) get() (string, error) {
+	if !a.asked {
+		a.asked = true
+		a.password, a.err = a.prompt(a.user, a.host)
+	}
+	if a.err != nil {
+		return "", a.err
+	}
+	return unsafeString(a.password), nil
+}
+
+// wipe zeroes the password, along with the strings get handed out, which
+// share its memory
+func (a *passwordAuth) wipe() {
+	for i := range a.password {
+		a.password[i] = 0
+	}
+	a.password = nil
+}
+
+// unsafeString returns b as a string without copying it, so that zeroing b
+// zeroes the string too. The ssh library only wants the password as a string.
+func unsafeString(b []byte) string {
+	if len(b) == 0 {
+		return ""
+	}
+	return *(*string)(unsafe.Pointer(&b))
+}
+
+func dialSSH(ctx context.Context, addr string, config *gossh.ClientConfig) (*gossh.Client, error) {
+	conn, err := (&net.Dialer{Timeout: config.Timeout}).DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	clientConn, chans, reqs, err := gossh.NewClientConn(conn, addr, config)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return gossh.NewClient(clientConn, chans, reqs), nil
+}
+
+// nativeProcess is a tunnel run by the native client. It stops forwarding when
+// the ssh connection drops or when it's shut down.
+type nativeProcess struct {
+	client   *gossh.Client
+	listener net.Listener
+
+	// remoteNetwork and remoteAddress say where we forward connections to,
+	// as passed to Dial
+	remoteNetwork string
+	remoteAddress string
+}
+
+var _ tunnelProcess = (*nativeProcess)(nil)
+
+func (p *nativeProcess) serve() {
+	for {
+		conn, err := p.listener.Accept()
+		if err != nil {
+			return
+		}
+		go p.forward(conn)
+	}
+}
+
+func (p *nativeProcess) forward(local net.Conn) {
+	remote, err := p.client.Dial(p.remoteNetwork, p.remoteAddress)
+	if err != nil {
+		local.Close()
+		return
+	}
+	pipe(local, remote)
+}
+
+// pipe copies between the two connections until both sides are done, then
+// closes them
+func pipe(local net.Conn, remote net.Conn) {
+	// copy each way independently, passing EOF along as a half-close, because
+	// the docker client relies on that when streaming to hijacked connections
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		_, _ = io.Copy(remote, local)
+		closeWrite(remote)
+	}()
+	go func() {
+		defer wg.Done()
+		_, _ = io.Copy(local, remote)
+		closeWrite(local)
+	}()
+	wg.Wait()
+
+	local.Close()
+	remote.Close()
+}
+
+func closeWrite(conn net.Conn) {
+	if c, ok := conn.(interface{ CloseWrite() error }); ok {
+		_ = c.CloseWrite()
+		return
+	}
+	_ = conn.Close()
+}
+
+func (p *nativeProcess) wait() error {
+	err := p.client.Wait()
+	// there's no point accepting connections we can't forward
+	p.listener.Close()
+	return err
+}
+
+func (p *nativeProcess) terminate() error {
+	p.listener.Close()
+	return p.client.Close()
+}
+
+func (p *nativeProcess) kill() error {
+	return p.terminate()
+}
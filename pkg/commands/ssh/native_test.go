@@ -0,0 +1,426 @@
+package ssh
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	gossh "golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+func TestSSHHandlerResolveBackend(t *testing.T) {
+	type scenario struct {
+		testName        string
+		backend         Backend
+		sshInstalled    bool
+		expectedBackend Backend
+		expectError     bool
+	}
+
+	scenarios := []scenario{
+		{
+			testName:        "Auto with ssh installed",
+			backend:         BackendAuto,
+			sshInstalled:    true,
+			expectedBackend: BackendExec,
+		},
+		{
+			testName:        "Auto without ssh installed",
+			backend:         BackendAuto,
+			sshInstalled:    false,
+			expectedBackend: BackendNative,
+		},
+		{
+			testName:     "Exec without ssh installed",
+			backend:      BackendExec,
+			sshInstalled: false,
+			expectError:  true,
+		},
+		{
+			testName:        "Native with ssh installed",
+			backend:         BackendNative,
+			sshInstalled:    true,
+			expectedBackend: BackendNative,
+		},
+	}
+
+	for _, s := range scenarios {
+		s := s
+		t.Run(s.testName, func(t *testing.T) {
+			handler := NewSSHHandler(WithBackend(s.backend))
+			handler.deps.lookPath = func(file string) (string, error) {
+				if s.sshInstalled {
+					return "/usr/bin/" + file, nil
+				}
+				return "", exec.ErrNotFound
+			}
+
+			backend, err := handler.resolveBackend()
+			if s.expectError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, s.expectedBackend, backend)
+		})
+	}
+}
+
+func TestSSHHandlerResolveNativeTarget(t *testing.T) {
+	type scenario struct {
+		testName       string
+		target         tunnelTarget
+		sshConfig      map[string][]string
+		expectedTarget nativeTarget
+	}
+
+	scenarios := []scenario{
+		{
+			testName: "Nothing configured",
+			target:   tunnelTarget{host: "myhost"},
+			expectedTarget: nativeTarget{
+				addr:          "myhost:22",
+				user:          "me",
+				identityFiles: []string{"/home/me/.ssh/id_ed25519", "/home/me/.ssh/id_ecdsa", "/home/me/.ssh/id_rsa"},
+			},
+		},
+		{
+			testName: "Alias from ssh config",
+			target:   tunnelTarget{host: "prod"},
+			sshConfig: map[string][]string{
+				"HostName":     {"%h.example.com"},
+				"Port":         {"2222"},
+				"User":         {"deploy"},
+				"IdentityFile": {"~/.ssh/prod", "/keys/backup"},
+			},
+			expectedTarget: nativeTarget{
+				addr:          "prod.example.com:2222",
+				user:          "deploy",
+				identityFiles: []string{"/home/me/.ssh/prod", "/keys/backup"},
+			},
+		},
+		{
+			testName: "URL takes precedence over ssh config",
+			target:   tunnelTarget{host: "fe80::1", port: "2022", user: "admin"},
+			sshConfig: map[string][]string{
+				"Port": {"2222"},
+				"User": {"deploy"},
+			},
+			expectedTarget: nativeTarget{
+				addr:          "[fe80::1]:2022",
+				user:          "admin",
+				identityFiles: []string{"/home/me/.ssh/id_ed25519", "/home/me/.ssh/id_ecdsa", "/home/me/.ssh/id_rsa"},
+			},
+		},
+	}
+
+	for _, s := range scenarios {
+		s := s
+		t.Run(s.testName, func(t *testing.T) {
+			handler := NewSSHHandler()
+			handler.deps.sshConfig = func(alias, key string) []string {
+				assert.Equal(t, s.target.host, alias)
+				return s.sshConfig[key]
+			}
+			handler.deps.getenv = func(key string) string {
+				if key == "USER" {
+					return "me"
+				}
+				return ""
+			}
+			handler.deps.homeDir = func() (string, error) { return "/home/me", nil }
+
+			target, err := handler.resolveNativeTarget(s.target)
+			assert.NoError(t, err)
+			assert.Equal(t, s.expectedTarget, target)
+		})
+	}
+}
+
+func TestSSHHandlerTunnelNative(t *testing.T) {
+	home, err := ioutil.TempDir("", "lazydocker-ssh-home-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(home)
+	assert.NoError(t, os.Mkdir(filepath.Join(home, ".ssh"), 0700))
+
+	clientKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	clientKeyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(clientKey)})
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(home, ".ssh", "id_rsa"), clientKeyPEM, 0600))
+	clientPublicKey, err := gossh.NewPublicKey(&clientKey.PublicKey)
+	assert.NoError(t, err)
+
+	hostKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	hostSigner, err := gossh.NewSignerFromKey(hostKey)
+	assert.NoError(t, err)
+
+	remoteSockets := make(chan string, 1)
+	serverAddr := serveEchoSSH(t, hostSigner, clientPublicKey, remoteSockets)
+
+	knownHosts := knownhosts.Line([]string{serverAddr}, hostSigner.PublicKey()) + "\n"
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(home, ".ssh", "known_hosts"), []byte(knownHosts), 0600))
+
+	handler := NewSSHHandler(WithBackend(BackendNative))
+	handler.deps.getenv = func(key string) string { return "" }
+	handler.deps.sshConfig = func(alias, key string) []string { return nil }
+	handler.deps.homeDir = func() (string, error) { return home, nil }
+
+	host, port, err := net.SplitHostPort(serverAddr)
+	assert.NoError(t, err)
+	localSocket := filepath.Join(home, "docker.sock")
+	process, err := handler.tunnelNative(context.Background(), tunnelTarget{host: host, port: port, user: "me", remoteSocket: "/run/docker.sock"}, unixEndpoint(localSocket))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	conn, err := net.Dial("unix", localSocket)
+	assert.NoError(t, err)
+	_, err = conn.Write([]byte("ping"))
+	assert.NoError(t, err)
+	assert.NoError(t, conn.(*net.UnixConn).CloseWrite())
+	reply, err := ioutil.ReadAll(conn)
+	assert.NoError(t, err)
+	assert.Equal(t, "ping", string(reply))
+	conn.Close()
+
+	assert.Equal(t, "/run/docker.sock", <-remoteSockets)
+
+	assert.NoError(t, process.terminate())
+	_ = process.wait()
+}
+
+// serveEchoSSH runs an ssh server which accepts the given client key and
+// echoes anything sent over a forwarded unix socket back to the sender,
+// reporting the requested remote socket path
+func serveEchoSSH(t *testing.T, hostSigner gossh.Signer, clientKey gossh.PublicKey, remoteSockets chan<- string) string {
+	config := &gossh.ServerConfig{
+		PublicKeyCallback: func(conn gossh.ConnMetadata, key gossh.PublicKey) (*gossh.Permissions, error) {
+			if conn.User() == "me" && bytes.Equal(key.Marshal(), clientKey.Marshal()) {
+				return nil, nil
+			}
+			return nil, errors.New("unknown key")
+		},
+	}
+	config.AddHostKey(hostSigner)
+	return serveForwardingSSH(t, config, remoteSockets)
+}
+
+// serveForwardingSSH runs an ssh server with the given config which echoes
+// anything sent over a forwarded unix socket back to the sender, reporting
+// the requested remote socket path
+func serveForwardingSSH(t *testing.T, config *gossh.ServerConfig, remoteSockets chan<- string) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		_, chans, reqs, err := gossh.NewServerConn(conn, config)
+		if err != nil {
+			return
+		}
+		go gossh.DiscardRequests(reqs)
+
+		for newChannel := range chans {
+			if newChannel.ChannelType() != "direct-streamlocal@openssh.com" {
+				_ = newChannel.Reject(gossh.UnknownChannelType, "unsupported")
+				continue
+			}
+			var payload struct {
+				SocketPath string
+				Reserved0  string
+				Reserved1  uint32
+			}
+			if err := gossh.Unmarshal(newChannel.ExtraData(), &payload); err != nil {
+				_ = newChannel.Reject(gossh.ConnectionFailed, err.Error())
+				continue
+			}
+
+			channel, channelReqs, err := newChannel.Accept()
+			if err != nil {
+				continue
+			}
+			go gossh.DiscardRequests(channelReqs)
+
+			select {
+			case remoteSockets <- payload.SocketPath:
+			default:
+			}
+
+			go func() {
+				_, _ = io.Copy(channel, channel)
+				_ = channel.CloseWrite()
+				_ = channel.Close()
+			}()
+		}
+	}()
+
+	return listener.Addr().String()
+}
+
+func TestSSHHandlerTunnelNativePassword(t *testing.T) {
+	type scenario struct {
+		testName    string
+		keyboard    bool
+		promptErr   error
+		expectError bool
+	}
+
+	scenarios := []scenario{
+		{
+			testName: "Password auth",
+		},
+		{
+			testName: "Keyboard-interactive auth",
+			keyboard: true,
+		},
+		{
+			testName:    "Cancelled prompt",
+			promptErr:   errors.New("cancelled"),
+			expectError: true,
+		},
+	}
+
+	hostKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	hostSigner, err := gossh.NewSignerFromKey(hostKey)
+	assert.NoError(t, err)
+
+	for _, s := range scenarios {
+		s := s
+		t.Run(s.testName, func(t *testing.T) {
+			// no keys, so the password's all there is
+			home, err := ioutil.TempDir("", "lazydocker-ssh-home-")
+			assert.NoError(t, err)
+			defer os.RemoveAll(home)
+			assert.NoError(t, os.Mkdir(filepath.Join(home, ".ssh"), 0700))
+
+			config := &gossh.ServerConfig{}
+			if s.keyboard {
+				config.KeyboardInteractiveCallback = func(conn gossh.ConnMetadata, challenge gossh.KeyboardInteractiveChallenge) (*gossh.Permissions, error) {
+					answers, err := challenge("", "", []string{"Password: "}, []bool{false})
+					if err == nil && conn.User() == "me" && len(answers) == 1 && answers[0] == "hunter2" {
+						return nil, nil
+					}
+					return nil, errors.New("wrong password")
+				}
+			} else {
+				config.PasswordCallback = func(conn gossh.ConnMetadata, password []byte) (*gossh.Permissions, error) {
+					if conn.User() == "me" && string(password) == "hunter2" {
+						return nil, nil
+					}
+					return nil, errors.New("wrong password")
+				}
+			}
+			config.AddHostKey(hostSigner)
+			remoteSockets := make(chan string, 1)
+			serverAddr := serveForwardingSSH(t, config, remoteSockets)
+
+			knownHosts := knownhosts.Line([]string{serverAddr}, hostSigner.PublicKey()) + "\n"
+			assert.NoError(t, ioutil.WriteFile(filepath.Join(home, ".ssh", "known_hosts"), []byte(knownHosts), 0600))
+
+			prompts := []string{}
+			var password []byte
+			handler := NewSSHHandler(WithBackend(BackendNative), WithPasswordPrompt(func(user, host string) ([]byte, error) {
+				prompts = append(prompts, user+"@"+host)
+				if s.promptErr != nil {
+					return nil, s.promptErr
+				}
+				password = []byte("hunter2")
+				return password, nil
+			}))
+			handler.deps.getenv = func(key string) string { return "" }
+			handler.deps.sshConfig = func(alias, key string) []string { return nil }
+			handler.deps.homeDir = func() (string, error) { return home, nil }
+
+			host, port, err := net.SplitHostPort(serverAddr)
+			assert.NoError(t, err)
+			process, err := handler.tunnelNative(context.Background(), tunnelTarget{host: host, port: port, user: "me", remoteSocket: "/run/docker.sock"}, unixEndpoint(filepath.Join(home, "docker.sock")))
+			assert.Equal(t, []string{"me@" + serverAddr}, prompts)
+			// the password's wiped once we're done with it
+			assert.Equal(t, strings.Repeat("\x00", len(password)), string(password))
+			if s.expectError {
+				assert.Error(t, err)
+				return
+			}
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.NoError(t, process.terminate())
+			_ = process.wait()
+		})
+	}
+}
+
+func TestSSHHandlerHostKeyCallback(t *testing.T) {
+	type scenario struct {
+		testName        string
+		hostKeyChecking string
+		expectError     bool
+	}
+
+	scenarios := []scenario{
+		{
+			testName:        "Default rejects unknown hosts",
+			hostKeyChecking: "",
+			expectError:     true,
+		},
+		{
+			testName:        "accept-new accepts unknown hosts",
+			hostKeyChecking: "accept-new",
+			expectError:     false,
+		},
+		{
+			testName:        "no accepts unknown hosts",
+			hostKeyChecking: "no",
+			expectError:     false,
+		},
+	}
+
+	home, err := ioutil.TempDir("", "lazydocker-ssh-home-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(home)
+	assert.NoError(t, os.Mkdir(filepath.Join(home, ".ssh"), 0700))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(home, ".ssh", "known_hosts"), []byte{}, 0600))
+
+	hostKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	hostPublicKey, err := gossh.NewPublicKey(&hostKey.PublicKey)
+	assert.NoError(t, err)
+
+	for _, s := range scenarios {
+		s := s
+		t.Run(s.testName, func(t *testing.T) {
+			handler := NewSSHHandler(WithHostKeyChecking(s.hostKeyChecking))
+			handler.deps.homeDir = func() (string, error) { return home, nil }
+
+			callback, err := handler.hostKeyCallback()
+			assert.NoError(t, err)
+
+			err = callback("myhost:22", &net.TCPAddr{IP: net.ParseIP("192.168.5.178"), Port: 22}, hostPublicKey)
+			if s.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,176 @@
+package ssh
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"sync"
+)
+
+// PortForward forwards connections made to a local port on to an address on
+// the remote host, over the ssh connection of a tunnel
+type PortForward struct {
+	// LocalAddress is where to connect to, e.g. 127.0.0.1:54321
+	LocalAddress string
+
+	closeOnce sync.Once
+	close     func() error
+	closeErr  error
+}
+
+func newPortForward(localAddress string, close func() error) *PortForward {
+	return &PortForward{LocalAddress: localAddress, close: close}
+}
+
+// Close stops forwarding the port. Closing it more than once is a no-op.
+func (f *PortForward) Close() error {
+	f.closeOnce.Do(func() {
+		f.closeErr = f.close()
+	})
+	return f.closeErr
+}
+
+// ForwardPort forwards a local port to remoteAddress, as seen from the remote
+// host. The native client forwards over the connection it already has. With
+// the ssh binary we run ssh again, which goes through the existing connection
+// when multiplexing is on.
+func (t *tunneledDockerHost) ForwardPort(ctx context.Context, remoteAddress string) (*PortForward, error) {
+	t.mutex.Lock()
+	process, down := t.process, t.closing || t.finished
+	t.mutex.Unlock()
+	if down {
+		return nil, ErrTunnelDown
+	}
+
+	var forward *PortForward
+	var err error
+	if _, ok := process.(*nativeProcess); ok {
+		forward, err = t.forwardNative(remoteAddress)
+	} else if t.forwardSSH != nil {
+		forward, err = t.forwardSSH(ctx, remoteAddress)
+	} else {
+		return nil, ErrNotTunneled
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	t.mutex.Lock()
+	if t.closing {
+		// Close was called while we were setting up, and it won't have known
+		// about this forward
+		t.mutex.Unlock()
+		_ = forward.Close()
+		return nil, ErrTunnelDown
+	}
+	t.forwards = append(t.forwards, forward)
+	t.mutex.Unlock()
+
+	return forward, nil
+}
+
+// forwardNative listens on a free local port and dials remoteAddress through
+// the native client for each connection. We look up the client afresh each
+// time so that the forward survives the tunnel reconnecting.
+func (t *tunneledDockerHost) forwardNative(remoteAddress string) (*PortForward, error) {
+	listener, err := t.deps.listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("forward %s over ssh: %w", remoteAddress, err)
+	}
+
+	go func() {
+		for {
+			local, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				t.mutex.Lock()
+				process, ok := t.process.(*nativeProcess)
+				t.mutex.Unlock()
+				if !ok {
+					local.Close()
+					return
+				}
+				remote, err := process.client.Dial("tcp", remoteAddress)
+				if err != nil {
+					t.log.Warnf("forwarding %s over ssh: %v", remoteAddress, err)
+					local.Close()
+					return
+				}
+				pipe(local, remote)
+			}()
+		}
+	}()
+
+	return newPortForward(listener.Addr().String(), listener.Close), nil
+}
+
+func (t *tunneledDockerHost) closeForwards() {
+	t.mutex.Lock()
+	forwards := t.forwards
+	t.forwards = nil
+	t.mutex.Unlock()
+
+	for _, forward := range forwards {
+		if err := forward.Close(); err != nil {
+			t.log.Warnf("failed to close port forward to %s: %v", forward.LocalAddress, err)
+		}
+	}
+}
+
+// forwardPortSSH runs ssh to forward a free local port to remoteAddress, and
+// waits for the local port to start accepting connections
+func (self *SSHHandler) forwardPortSSH(ctx context.Context, target tunnelTarget, controlDir string, remoteAddress string) (*PortForward, error) {
+	// ssh won't tell us which port it picked if we ask for any, so we pick
+	listener, err := self.deps.listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("forward %s over ssh: %w", remoteAddress, err)
+	}
+	localAddress := listener.Addr().String()
+	listener.Close()
+
+	// so that ssh exits, rather than carrying on without the forward, if the
+	// port has been taken since
+	args := append([]string{"-o", "ExitOnForwardFailure=yes"}, self.sshForwardArgs(target, controlDir, localAddress+":"+remoteAddress)...)
+	cmd := exec.Command(self.getSSHBinary(), args...)
+	prepareTunnelProcess(cmd)
+	stderr := newTailBuffer(maxStderrTailLength)
+	cmd.Stderr = stderr
+	if err := self.deps.startCmd(cmd); err != nil {
+		return nil, fmt.Errorf("forward %s over ssh: %w", remoteAddress, err)
+	}
+
+	process := &execProcess{cmd: cmd, deps: self.deps}
+	exited := make(chan struct{})
+	go func() {
+		_ = process.wait()
+		close(exited)
+	}()
+
+	ctx, cancel := self.withTimeout(ctx, self.getTunnelTimeout())
+	defer cancel()
+	interval, maxInterval := self.getDialIntervals()
+	for {
+		select {
+		case <-exited:
+			return nil, self.newTunnelError(target, stderr.String(), fmt.Errorf("forward %s over ssh: ssh exited", remoteAddress))
+		case <-ctx.Done():
+			_ = stopProcess(process, exited)
+			return nil, fmt.Errorf("forward %s over ssh: %w", remoteAddress, ctx.Err())
+		case <-self.deps.after(interval):
+		}
+
+		conn, err := self.deps.dialContext(ctx, "tcp", localAddress)
+		if err == nil {
+			conn.Close()
+			break
+		}
+		interval = nextDialInterval(interval, maxInterval)
+	}
+
+	self.logger().Debugf("forwarding %s to %s on %s", localAddress, remoteAddress, target.host)
+	return newPortForward(localAddress, func() error {
+		return stopProcess(process, exited)
+	}), nil
+}
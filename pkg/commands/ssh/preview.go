@@ -0,0 +1,166 @@
+package ssh
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ConnectionPlan describes how we'd connect to a docker host, as worked out by
+// PreviewConnection
+type ConnectionPlan struct {
+	// DockerHost is the docker host the plan is for. If none was given it's
+	// the one from the current docker context, or blank for the default
+	// socket.
+	DockerHost string
+
+	// Scheme is the docker host's url scheme, e.g. ssh or tcp
+	Scheme string
+
+	// Tunnel is set if we'd tunnel to the docker host over ssh. The fields
+	// below are only filled in if it is.
+	Tunnel bool
+
+	// TunnelingDisabled is set if the docker host is an ssh:// url but
+	// LAZYNERD_DISABLE_SSH_TUNNEL says not to tunnel to it
+	TunnelingDisabled bool
+
+	Backend Backend
+
+	// Host, Port and User are where we'd ssh to, once ~/.ssh/config has had
+	// its say. User is blank if it's left to ssh to decide.
+	Host string
+	Port string
+	User string
+
+	// RemoteSocket is where we'd forward to on the remote host: the path of
+	// its docker socket, or the host:port of a daemon listening on tcp
+	RemoteSocket string
+
+	// LocalSocket is roughly where the local end of the tunnel would be: a
+	// socket path, or host:port if we'd bind to a tcp port. The '*' stands in
+	// for the random part of the temp dir's name, and a port of 0 for the one
+	// we'd pick.
+	LocalSocket string
+
+	// Command is the ssh command we'd run, with the same arguments as the
+	// tunnel gets. It's empty for the native backend, which doesn't run one.
+	Command []string
+}
+
+// String lays the plan out for printing
+func (p ConnectionPlan) String() string {
+	lines := []string{}
+	add := func(key, value string) {
+		lines = append(lines, fmt.Sprintf("%-14s %s", key+":", value))
+	}
+
+	dockerHost := p.DockerHost
+	if dockerHost == "" {
+		dockerHost = "(not set, using the default socket)"
+	}
+	add("docker host", dockerHost)
+	if p.DockerHost == "" {
+		return strings.Join(lines, "\n")
+	}
+	add("scheme", p.Scheme)
+
+	switch {
+	case p.TunnelingDisabled:
+		add("tunnel", "no ("+disableTunnelEnvVar+" is set)")
+	case !p.Tunnel:
+		add("tunnel", "no")
+	default:
+		add("tunnel", "yes")
+		add("backend", string(p.Backend))
+		host := p.Host + ":" + p.Port
+		if p.User != "" {
+			host = p.User + "@" + host
+		}
+		add("ssh host", host)
+		add("remote socket", p.RemoteSocket)
+		add("local socket", p.LocalSocket)
+		if len(p.Command) > 0 {
+			add("command", strings.Join(p.Command, " "))
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// PreviewConnection works out what HandleSSHDockerHost would do with the given
+// docker host, without doing any of it: there's no dialing, no ssh process and
+// no socket dir. A blank docker host means the current docker context's, as it
+// would for DOCKER_HOST. It fails in the cases where setting up the tunnel
+// would fail before ssh is even run, e.g. a malformed url.
+func (self *SSHHandler) PreviewConnection(dockerHost string) (ConnectionPlan, error) {
+	dockerHost = strings.TrimSpace(dockerHost)
+	if dockerHost == "" {
+		dockerContext, err := self.activeDockerContext()
+		if err != nil {
+			return ConnectionPlan{}, err
+		}
+		dockerHost = dockerContext.Host
+	}
+	plan := ConnectionPlan{DockerHost: dockerHost}
+	if dockerHost == "" {
+		return plan, nil
+	}
+
+	u, err := url.Parse(dockerHost)
+	if err != nil {
+		return plan, fmt.Errorf("parse docker host: %w", err)
+	}
+	plan.Scheme = u.Scheme
+	if !isSSHScheme(u.Scheme) {
+		return plan, nil
+	}
+	if self.tunnelingDisabled() {
+		plan.TunnelingDisabled = true
+		return plan, nil
+	}
+
+	u, err = parseSSHURL(dockerHost)
+	if err != nil {
+		return plan, err
+	}
+	backend, err := self.resolveBackend()
+	if err != nil {
+		return plan, err
+	}
+	target, err := newTunnelTarget(u)
+	if err != nil {
+		return plan, err
+	}
+	host, err := self.resolveSSHConfig(target)
+	if err != nil {
+		return plan, err
+	}
+
+	socketName := socketFileNameFor(target)
+	socketDir := filepath.Join(self.socketTempRoot(socketName), socketDirPattern+"*")
+	local := unixEndpoint(filepath.Join(socketDir, socketName))
+	if self.localBind != "" {
+		host, port, err := parseLocalBind(self.localBind)
+		if err != nil {
+			return plan, err
+		}
+		local = localEndpoint{network: "tcp", address: net.JoinHostPort(host, strconv.Itoa(port)), dir: socketDir}
+	}
+
+	plan.Tunnel = true
+	plan.Backend = backend
+	plan.Host = host.hostname
+	plan.Port = host.port
+	plan.User = host.user
+	plan.RemoteSocket = target.remoteEndpoint()
+	plan.LocalSocket = local.address
+	if backend == BackendExec {
+		plan.Command = append([]string{self.getSSHBinary()}, self.sshArgs(target, local)...)
+	}
+
+	return plan, nil
+}
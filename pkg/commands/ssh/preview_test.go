@@ -0,0 +1,218 @@
+package ssh
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSSHHandlerPreviewConnection(t *testing.T) {
+	type scenario struct {
+		testName            string
+		dockerHost          string
+		disableTunnel       string
+		sshConfig           map[string]string
+		noSSHBinary         bool
+		expectedPlan        ConnectionPlan
+		expectedErrorSubstr string
+	}
+
+	scenarios := []scenario{
+		{
+			testName:     "No docker host",
+			dockerHost:   "",
+			expectedPlan: ConnectionPlan{},
+		},
+		{
+			testName:     "TCP docker host",
+			dockerHost:   "tcp://192.168.5.178:2376",
+			expectedPlan: ConnectionPlan{DockerHost: "tcp://192.168.5.178:2376", Scheme: "tcp"},
+		},
+		{
+			testName:   "SSH docker host",
+			dockerHost: "ssh://myuser@192.168.5.178:2222/run/docker.sock",
+			expectedPlan: ConnectionPlan{
+				DockerHost:   "ssh://myuser@192.168.5.178:2222/run/docker.sock",
+				Scheme:       "ssh",
+				Tunnel:       true,
+				Backend:      BackendExec,
+				Host:         "192.168.5.178",
+				Port:         "2222",
+				User:         "myuser",
+				RemoteSocket: "/run/docker.sock",
+				LocalSocket:  "/tmp/lazydocker-sshtunnel-*/192.168.5.178-2222.sock",
+				Command:      []string{"ssh", "-L", "/tmp/lazydocker-sshtunnel-*/192.168.5.178-2222.sock:/run/docker.sock", "-p", "2222", "-l", "myuser", "-o", "BatchMode=yes", "-o", "ServerAliveInterval=30", "-o", "ServerAliveCountMax=3", "192.168.5.178", "-N"},
+			},
+		},
+		{
+			testName:   "SSH docker host with a user and port",
+			dockerHost: "ssh://alice@host:2222",
+			expectedPlan: ConnectionPlan{
+				DockerHost:   "ssh://alice@host:2222",
+				Scheme:       "ssh",
+				Tunnel:       true,
+				Backend:      BackendExec,
+				Host:         "host",
+				Port:         "2222",
+				User:         "alice",
+				RemoteSocket: "/var/run/docker.sock",
+				LocalSocket:  "/tmp/lazydocker-sshtunnel-*/host-2222.sock",
+				Command:      []string{"ssh", "-L", "/tmp/lazydocker-sshtunnel-*/host-2222.sock:/var/run/docker.sock", "-p", "2222", "-l", "alice", "-o", "BatchMode=yes", "-o", "ServerAliveInterval=30", "-o", "ServerAliveCountMax=3", "host", "-N"},
+			},
+		},
+		{
+			testName:   "SSH docker host padded with whitespace",
+			dockerHost: " ssh://192.168.5.178 ",
+			expectedPlan: ConnectionPlan{
+				DockerHost:   "ssh://192.168.5.178",
+				Scheme:       "ssh",
+				Tunnel:       true,
+				Backend:      BackendExec,
+				Host:         "192.168.5.178",
+				Port:         "22",
+				RemoteSocket: "/var/run/docker.sock",
+				LocalSocket:  "/tmp/lazydocker-sshtunnel-*/192.168.5.178.sock",
+				Command:      []string{"ssh", "-L", "/tmp/lazydocker-sshtunnel-*/192.168.5.178.sock:/var/run/docker.sock", "-o", "BatchMode=yes", "-o", "ServerAliveInterval=30", "-o", "ServerAliveCountMax=3", "192.168.5.178", "-N"},
+			},
+		},
+		{
+			testName:   "SSH docker host resolved via ssh config",
+			dockerHost: "ssh://myhost",
+			sshConfig:  map[string]string{"HostName": "myhost.example.com", "User": "admin"},
+			expectedPlan: ConnectionPlan{
+				DockerHost:   "ssh://myhost",
+				Scheme:       "ssh",
+				Tunnel:       true,
+				Backend:      BackendExec,
+				Host:         "myhost.example.com",
+				Port:         "22",
+				User:         "admin",
+				RemoteSocket: "/var/run/docker.sock",
+				LocalSocket:  "/tmp/lazydocker-sshtunnel-*/myhost.sock",
+				Command:      []string{"ssh", "-L", "/tmp/lazydocker-sshtunnel-*/myhost.sock:/var/run/docker.sock", "-o", "BatchMode=yes", "-o", "ServerAliveInterval=30", "-o", "ServerAliveCountMax=3", "myhost", "-N"},
+			},
+		},
+		{
+			testName:    "SSH docker host without an ssh binary",
+			dockerHost:  "ssh://192.168.5.178",
+			noSSHBinary: true,
+			expectedPlan: ConnectionPlan{
+				DockerHost:   "ssh://192.168.5.178",
+				Scheme:       "ssh",
+				Tunnel:       true,
+				Backend:      BackendNative,
+				Host:         "192.168.5.178",
+				Port:         "22",
+				RemoteSocket: "/var/run/docker.sock",
+				LocalSocket:  "/tmp/lazydocker-sshtunnel-*/192.168.5.178.sock",
+			},
+		},
+		{
+			testName:      "Tunneling disabled",
+			dockerHost:    "ssh://192.168.5.178",
+			disableTunnel: "1",
+			expectedPlan:  ConnectionPlan{DockerHost: "ssh://192.168.5.178", Scheme: "ssh", TunnelingDisabled: true},
+		},
+		{
+			testName:            "Missing host",
+			dockerHost:          "ssh://:2222",
+			expectedErrorSubstr: "missing host",
+		},
+		{
+			testName:            "Invalid ssh config port",
+			dockerHost:          "ssh://myhost",
+			sshConfig:           map[string]string{"Port": "nope"},
+			expectedErrorSubstr: `invalid port "nope" for host myhost in ssh config`,
+		},
+	}
+
+	for _, s := range scenarios {
+		s := s
+		t.Run(s.testName, func(t *testing.T) {
+			handler := &SSHHandler{
+				deps: dependencies{
+					dialContext: func(ctx context.Context, network string, address string) (io.Closer, error) {
+						t.Error("expected no dialing")
+						return noopCloser{}, nil
+					},
+					startCmd: func(cmd *exec.Cmd) error {
+						t.Error("expected no process to be started")
+						return nil
+					},
+					tempDir: func(dir string, pattern string) (string, error) {
+						t.Error("expected no temp dir to be created")
+						return "", errors.New("unexpected")
+					},
+					tempRoot: func() string { return "/tmp" },
+					getenv: func(key string) string {
+						if key == disableTunnelEnvVar {
+							return s.disableTunnel
+						}
+						return ""
+					},
+					lookPath: func(file string) (string, error) {
+						if s.noSSHBinary {
+							return "", exec.ErrNotFound
+						}
+						return "/usr/bin/" + file, nil
+					},
+					sshConfig: func(alias, key string) []string {
+						if value, ok := s.sshConfig[key]; ok {
+							return []string{value}
+						}
+						return nil
+					},
+					homeDir:  func() (string, error) { return "/home/user", nil },
+					readFile: func(filename string) ([]byte, error) { return nil, os.ErrNotExist },
+				},
+			}
+
+			plan, err := handler.PreviewConnection(s.dockerHost)
+			if s.expectedErrorSubstr != "" {
+				if assert.Error(t, err) {
+					assert.Contains(t, err.Error(), s.expectedErrorSubstr)
+				}
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, s.expectedPlan, plan)
+			if plan.Command != nil && s.sshConfig["User"] == "" && plan.User != "" {
+				// the user we show has to be the one ssh logs in as
+				assert.Contains(t, strings.Join(plan.Command, " "), " -l "+plan.User+" ")
+			}
+		})
+	}
+}
+
+func TestConnectionPlanString(t *testing.T) {
+	plan := ConnectionPlan{
+		DockerHost:   "ssh://myuser@192.168.5.178",
+		Scheme:       "ssh",
+		Tunnel:       true,
+		Backend:      BackendExec,
+		Host:         "192.168.5.178",
+		Port:         "22",
+		User:         "myuser",
+		RemoteSocket: "/var/run/docker.sock",
+		LocalSocket:  "/tmp/lazydocker-sshtunnel-*/192.168.5.178.sock",
+		Command:      []string{"ssh", "-N"},
+	}
+
+	expected := `docker host:   ssh://myuser@192.168.5.178
+scheme:        ssh
+tunnel:        yes
+backend:       exec
+ssh host:      myuser@192.168.5.178:22
+remote socket: /var/run/docker.sock
+local socket:  /tmp/lazydocker-sshtunnel-*/192.168.5.178.sock
+command:       ssh -N`
+	assert.Equal(t, expected, plan.String())
+
+	assert.Equal(t, "docker host:   (not set, using the default socket)", ConnectionPlan{}.String())
+}
@@ -0,0 +1,103 @@
+package ssh
+
+import (
+	"context"
+	"sync"
+)
+
+// sharedTunnel is a tunnel handed out to every caller of HandleSSHDockerHost
+// asking for the same host, e.g. when the GUI re-runs it on a refresh. It's
+// only closed once all of them have closed it.
+type sharedTunnel struct {
+	tunnel *tunneledDockerHost
+	// refs is how many tunnelRefs are still open. Guarded by
+	// SSHHandler.tunnelsMutex.
+	refs int
+}
+
+// tunnelRef is one caller's handle on a sharedTunnel
+type tunnelRef struct {
+	handler *SSHHandler
+	sshURL  string
+	shared  *sharedTunnel
+	once    sync.Once
+	err     error
+}
+
+var _ Tunnel = (*tunnelRef)(nil)
+
+// acquireTunnel returns the live tunnel to sshURL if we already have one, or
+// opens a new one otherwise
+func (self *SSHHandler) acquireTunnel(ctx context.Context, sshURL string) (*tunnelRef, error) {
+	// held while opening the tunnel so that concurrent callers wait for it
+	// rather than each opening their own
+	self.tunnelsMutex.Lock()
+	defer self.tunnelsMutex.Unlock()
+
+	shared, ok := self.tunnels[sshURL]
+	if ok && shared.tunnel.alive() {
+		self.logger().Debugf("reusing ssh tunnel for %s", shared.tunnel.SocketPath())
+		shared.refs++
+		return &tunnelRef{handler: self, sshURL: sshURL, shared: shared}, nil
+	}
+
+	tunnel, err := self.openTunnel(ctx, sshURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if self.tunnels == nil {
+		self.tunnels = map[string]*sharedTunnel{}
+	}
+	// if there was a dead tunnel in here, whoever still holds it is
+	// responsible for closing it
+	shared = &sharedTunnel{tunnel: tunnel, refs: 1}
+	self.tunnels[sshURL] = shared
+
+	return &tunnelRef{handler: self, sshURL: sshURL, shared: shared}, nil
+}
+
+func (self *SSHHandler) releaseTunnel(sshURL string, shared *sharedTunnel) error {
+	self.tunnelsMutex.Lock()
+	shared.refs--
+	if shared.refs > 0 {
+		self.tunnelsMutex.Unlock()
+		return nil
+	}
+	if self.tunnels[sshURL] == shared {
+		delete(self.tunnels, sshURL)
+	}
+	self.tunnelsMutex.Unlock()
+
+	return shared.tunnel.Close()
+}
+
+// SocketPath returns the url of the local end of the tunnel
+func (r *tunnelRef) SocketPath() string {
+	return r.shared.tunnel.SocketPath()
+}
+
+// Stats describe how setting up the underlying tunnel went
+func (r *tunnelRef) Stats() TunnelStats {
+	return r.shared.tunnel.Stats()
+}
+
+// Done is the underlying tunnel's Done channel. Note that it's shared between
+// every ref, so only one of them will receive the error.
+func (r *tunnelRef) Done() <-chan error {
+	return r.shared.tunnel.Done()
+}
+
+// ForwardPort forwards a port over the underlying tunnel
+func (r *tunnelRef) ForwardPort(ctx context.Context, remoteAddress string) (*PortForward, error) {
+	return r.shared.tunnel.ForwardPort(ctx, remoteAddress)
+}
+
+// Close releases this ref, closing the tunnel if it was the last one. Closing
+// a ref more than once is a no-op.
+func (r *tunnelRef) Close() error {
+	r.once.Do(func() {
+		r.err = r.handler.releaseTunnel(r.sshURL, r.shared)
+	})
+	return r.err
+}
@@ -1,17 +1,25 @@
 package ssh
 
 import (
+	"bufio"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"net"
+	"net/http"
 	"net/url"
 	"os"
 	"os/exec"
-	"path"
-	"syscall"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
+
+	"github.com/kevinburke/ssh_config"
+	gossh "golang.org/x/crypto/ssh"
 )
 
 type dependencies struct {
@@ -19,46 +27,494 @@ type dependencies struct {
 	dialContext func(ctx context.Context, network, addr string) (io.Closer, error)
 	startCmd    func(*exec.Cmd) error
 	tempDir     func(dir string, pattern string) (name string, err error)
-	getenv      func(key string) string
-	setenv      func(key, value string) error
+	tempRoot    func() string
+	// userCacheDir is one of the places we fall back to for the socket
+	userCacheDir func() (string, error)
+	getenv       func(key string) string
+	setenv       func(key, value string) error
+	unsetenv     func(key string) error
+	removeAll    func(path string) error
+	remove       func(path string) error
+	stat         func(name string) (os.FileInfo, error)
+	readDir      func(dirname string) ([]os.FileInfo, error)
+	lookPath     func(file string) (string, error)
+
+	// terminateProcessGroup and killProcessGroup signal the ssh process group.
+	// They're passed the negated pid, as with kill(2). Unused on windows.
+	terminateProcessGroup func(pid int) error
+	killProcessGroup      func(pid int) error
+
+	// initialDialInterval and maxDialInterval bound the backoff between
+	// attempts to dial the tunneled socket. Zero means use the defaults.
+	initialDialInterval time.Duration
+	maxDialInterval     time.Duration
+
+	// now and after are the clock used for dial backoff and timeouts
+	now   func() time.Time
+	after func(d time.Duration) <-chan time.Time
+
+	// used by the native backend
+	dialSSH   func(ctx context.Context, addr string, config *gossh.ClientConfig) (*gossh.Client, error)
+	listen    func(network, addr string) (net.Listener, error)
+	sshConfig func(alias, key string) []string
+	readFile  func(filename string) ([]byte, error)
+	homeDir   func() (string, error)
 }
 
+// defaultTunnelTimeout is how long we wait for the tunneled socket to become
+// available when no timeout has been configured
+const defaultTunnelTimeout = 8 * time.Second
+
+// defaultRemoteSocket is the docker socket we forward to on the remote host
+// when the DOCKER_HOST url doesn't specify one
+const defaultRemoteSocket = "/var/run/docker.sock"
+
 type SSHHandler struct {
 	deps dependencies
+
+	// tunnelTimeout is how long we wait for the tunneled socket to start
+	// accepting connections. Zero means defaultTunnelTimeout.
+	tunnelTimeout time.Duration
+
+	// dialTimeout bounds each individual dial attempt against the tunneled
+	// socket. Zero means defaultDialTimeout.
+	dialTimeout time.Duration
+
+	// maxDialAttempts caps how many times we dial the tunneled socket before
+	// giving up, on top of tunnelTimeout. Zero means no cap.
+	maxDialAttempts int
+
+	// progress is told about each dial attempt while we wait for the tunnel.
+	// Nil means nobody's listening.
+	progress ProgressFunc
+
+	// controlMaster enables ssh connection multiplexing
+	controlMaster bool
+
+	// sshOptions are extra arguments passed verbatim to ssh
+	sshOptions []string
+
+	// maxReconnects is how many times in a row we try to restart a dropped
+	// tunnel. Zero disables auto-reconnect.
+	maxReconnects int
+
+	// backend selects between the ssh binary and the native client
+	backend Backend
+
+	// sshBinary is the ssh executable used by the exec backend. Blank means
+	// defaultSSHBinary.
+	sshBinary string
+
+	// hostKeyChecking is the StrictHostKeyChecking mode. Blank leaves it up
+	// to the user's ssh config.
+	hostKeyChecking string
+
+	// log receives tunnel lifecycle events. Nil means don't log.
+	log Logger
+
+	// pingCheck makes us ping the docker daemon through the tunnel before
+	// considering it up
+	pingCheck bool
+
+	// agentForwarding forwards the local ssh agent to the remote host
+	agentForwarding bool
+
+	// compression makes ssh compress the tunneled traffic
+	compression bool
+
+	// identitiesOnly stops ssh offering agent keys when an identity file is
+	// given in sshOptions
+	identitiesOnly bool
+
+	// keepAliveInterval is how often ssh checks the server is still there
+	// when the tunnel is idle. Zero means defaultKeepAliveInterval, negative
+	// turns keepalives off.
+	keepAliveInterval time.Duration
+	// keepAliveCountMax is how many unanswered keepalives ssh tolerates
+	// before dropping the connection. Zero means defaultKeepAliveCountMax.
+	keepAliveCountMax int
+
+	// localBind is where the local end of the tunnel listens, as a
+	// tcp://127.0.0.1:<port> url. Blank means a unix socket.
+	localBind string
+
+	// skipEnvOverride stops HandleSSHDockerHost from pointing DOCKER_HOST at
+	// the tunnel
+	skipEnvOverride bool
+
+	// passwordPrompt asks for the password when the native backend connects
+	// to a host that wants one. Nil means we only authenticate with keys.
+	passwordPrompt PasswordPrompt
+
+	// tunnels are those opened by HandleSSHDockerHost, keyed by ssh url, so
+	// that calling it again for the same host reuses the tunnel
+	tunnelsMutex sync.Mutex
+	tunnels      map[string]*sharedTunnel
+}
+
+// Logger is what we log tunnel lifecycle events to. *logrus.Entry satisfies it.
+type Logger interface {
+	Debugf(format string, args ...interface{})
+	Warnf(format string, args ...interface{})
+}
+
+type noopLogger struct{}
+
+func (noopLogger) Debugf(format string, args ...interface{}) {}
+func (noopLogger) Warnf(format string, args ...interface{})  {}
+
+// Backend is the means by which we establish the ssh tunnel
+type Backend string
+
+const (
+	// BackendAuto uses the ssh binary if there is one on the PATH, and
+	// otherwise falls back to the native client
+	BackendAuto Backend = ""
+
+	// BackendExec shells out to the ssh binary, so the user's ssh setup
+	// (config, agent, ProxyCommand etc) applies in full
+	BackendExec Backend = "exec"
+
+	// BackendNative connects in-process using golang.org/x/crypto/ssh, for when
+	// there's no ssh binary around, e.g. in minimal containers. It reads
+	// HostName, Port, User and IdentityFile from ~/.ssh/config, authenticates
+	// with the ssh agent and the user's keys, or a password given
+	// WithPasswordPrompt, and verifies the host against ~/.ssh/known_hosts.
+	// Jump hosts aren't supported.
+	BackendNative Backend = "native"
+)
+
+// Option configures an SSHHandler
+type Option func(*SSHHandler)
+
+// WithTunnelTimeout sets how long we wait for the tunneled socket to become
+// available. This is worth bumping for high-latency links or hosts that take a
+// while to authenticate. A zero value falls back to the default of 8 seconds.
+func WithTunnelTimeout(d time.Duration) Option {
+	return func(self *SSHHandler) {
+		self.tunnelTimeout = d
+	}
+}
+
+// WithDialTimeout sets how long a single dial attempt against the tunneled
+// socket may take before we give up on it and retry, so one hung dial can't eat
+// the whole tunnel timeout. A zero value falls back to the default of 1 second.
+func WithDialTimeout(d time.Duration) Option {
+	return func(self *SSHHandler) {
+		self.dialTimeout = d
+	}
+}
+
+// WithMaxDialAttempts gives up on the tunnel after dialing its socket n times
+// without success, even if the tunnel timeout hasn't elapsed yet. Whichever
+// comes first wins. Zero, the default, means no cap.
+func WithMaxDialAttempts(n int) Option {
+	return func(self *SSHHandler) {
+		self.maxDialAttempts = n
+	}
+}
+
+// ProgressFunc is called before each attempt to dial the tunneled socket with
+// the attempt number, starting at 1, and how long we'll keep trying for. The
+// latter is zero if there's no deadline.
+type ProgressFunc func(attempt int, remaining time.Duration)
+
+// WithProgress registers a callback for each dial attempt while we wait for
+// the tunnel to come up, e.g. to show "Connecting to prod (3s left)…". It's
+// called from the goroutine setting up the tunnel, so it shouldn't block.
+func WithProgress(progress ProgressFunc) Option {
+	return func(self *SSHHandler) {
+		self.progress = progress
+	}
+}
+
+// WithControlMaster makes ssh multiplex connections through a master
+// connection, so re-establishing a tunnel skips the full handshake. The control
+// socket lives alongside the tunnel's local socket so it's cleaned up when the
+// tunnel is closed.
+func WithControlMaster() Option {
+	return func(self *SSHHandler) {
+		self.controlMaster = true
+	}
+}
+
+// WithSSHOptions passes extra arguments verbatim to ssh, e.g.
+// "-o", "StrictHostKeyChecking=accept-new" or "-i", "~/.ssh/id_ed25519". They
+// are placed after the arguments lazydocker generates and before the host, so
+// they can't displace the -L forward, the host, or -N. Note that for -o
+// settings ssh uses the first value it sees, so our own -o settings take
+// precedence over any you pass for the same key.
+func WithSSHOptions(options ...string) Option {
+	return func(self *SSHHandler) {
+		self.sshOptions = append(self.sshOptions, options...)
+	}
+}
+
+// WithAutoReconnect restarts the ssh tunnel if it drops while in use, e.g.
+// because the network blipped or the laptop went to sleep. The tunnel keeps its
+// local socket path, so an existing docker client carries on working once the
+// socket is back. We give up after maxRetries consecutive failed attempts, at
+// which point the final error is sent on the tunnel's done channel.
+func WithAutoReconnect(maxRetries int) Option {
+	return func(self *SSHHandler) {
+		self.maxReconnects = maxRetries
+	}
+}
+
+// WithHostKeyChecking sets ssh's StrictHostKeyChecking mode, e.g. "yes",
+// "accept-new" or "no". Since we run ssh in batch mode it can't ask whether to
+// trust an unknown host, so with the default of "ask" connecting to a host
+// that isn't in known_hosts fails; "accept-new" is the usual fix.
+func WithHostKeyChecking(mode string) Option {
+	return func(self *SSHHandler) {
+		self.hostKeyChecking = mode
+	}
 }
 
-func NewSSHHandler() *SSHHandler {
-	return &SSHHandler{
+// WithLogger logs tunnel lifecycle events, e.g. dial attempts and the ssh
+// process exiting, which is handy when debugging a tunnel that won't come up.
+func WithLogger(log Logger) Option {
+	return func(self *SSHHandler) {
+		self.log = log
+	}
+}
+
+// WithoutEnvOverride stops HandleSSHDockerHost from setting DOCKER_HOST to the
+// tunneled socket, for when you'd rather build your own docker client from the
+// returned tunnel's SocketPath. To tunnel some other ssh url, see OpenTunnel.
+func WithoutEnvOverride() Option {
+	return func(self *SSHHandler) {
+		self.skipEnvOverride = true
+	}
+}
+
+// WithAgentForwarding passes -A to ssh so that the remote host can use your
+// local ssh agent, e.g. for builds that pull from private git repos over ssh.
+// Be aware that anyone with root on the remote host can then use your agent to
+// authenticate as you for as long as the tunnel is up, so only enable this for
+// hosts you trust. The native backend doesn't support agent forwarding.
+func WithAgentForwarding() Option {
+	return func(self *SSHHandler) {
+		self.agentForwarding = true
+	}
+}
+
+// WithCompression passes -C to ssh, which can speed up chatty traffic such as
+// log streaming over slow or metered links. It's opt-in because on fast links
+// the cost of compressing tends to outweigh the bandwidth saved, reducing
+// throughput. The native backend doesn't support compression.
+func WithCompression() Option {
+	return func(self *SSHHandler) {
+		self.compression = true
+	}
+}
+
+// WithIdentitiesOnly passes -o IdentitiesOnly=yes to ssh whenever an identity
+// file is given in the ssh options (via -i or IdentityFile), so that only that
+// key is offered. Without it ssh offers every key in your agent first, and a
+// host with a low MaxAuthTries can reject you before it gets to the right one.
+// This only affects which keys we authenticate with: with WithAgentForwarding
+// the remote host still gets access to all of your agent's keys. The native
+// backend ignores it.
+func WithIdentitiesOnly() Option {
+	return func(self *SSHHandler) {
+		self.identitiesOnly = true
+	}
+}
+
+// WithKeepAlive sets how often ssh pings the server while the tunnel is idle,
+// and how many unanswered pings it tolerates before giving up on the
+// connection. Without keepalives, a firewall between you and the host may
+// silently drop a tunnel that's been idle for a while, e.g. while you're just
+// looking at the GUI. Zero values fall back to the defaults of 30 seconds and 3
+// pings, and a negative interval turns keepalives off. The native backend
+// ignores this.
+func WithKeepAlive(interval time.Duration, countMax int) Option {
+	return func(self *SSHHandler) {
+		self.keepAliveInterval = interval
+		self.keepAliveCountMax = countMax
+	}
+}
+
+// WithPingCheck makes us check that the docker daemon answers GET /_ping over
+// the tunneled socket before declaring the tunnel up, rather than settling for
+// the socket accepting a connection. That catches forwards that connect but
+// have nothing behind them.
+func WithPingCheck() Option {
+	return func(self *SSHHandler) {
+		self.pingCheck = true
+	}
+}
+
+// WithSSHBinary sets the ssh executable to run, e.g. "/usr/local/bin/ssh" or a
+// wrapper script. A bare name is looked up in PATH. A blank value falls back to
+// "ssh".
+func WithSSHBinary(binary string) Option {
+	return func(self *SSHHandler) {
+		self.sshBinary = binary
+	}
+}
+
+// WithLocalBind makes the tunnel listen on a local tcp port rather than a unix
+// socket, e.g. so that tools which can't talk to a socket can share it. The
+// address must be a loopback one, e.g. tcp://127.0.0.1:2375. Port 0 picks a
+// free port, which SocketPath then reports.
+func WithLocalBind(address string) Option {
+	return func(self *SSHHandler) {
+		self.localBind = address
+	}
+}
+
+// PasswordPrompt asks for the password of user on host, the host being the
+// host:port we're connecting to. It returns an error to abandon the attempt,
+// e.g. if the user cancels. We zero the returned bytes once we're done with
+// them, so it mustn't hold on to them or hand out a slice it needs later.
+type PasswordPrompt func(user, host string) ([]byte, error)
+
+// WithPasswordPrompt lets the native backend log in with a password, asking
+// prompt for it each time we connect and only if the host asks for one, e.g.
+// because none of our keys were accepted. It's answered to both password and
+// keyboard-interactive auth. The ssh binary runs in batch mode, where it can't
+// ask for passwords, so this is only any use with WithBackend(BackendNative).
+//
+// The password is never passed to a process or kept between connections: we
+// zero it once the handshake is over, whether or not it worked. That only
+// goes so far, as the ssh library copies it into the packet it sends, which
+// we can't get at, and the memory may be swapped to disk before it's zeroed.
+func WithPasswordPrompt(prompt PasswordPrompt) Option {
+	return func(self *SSHHandler) {
+		self.passwordPrompt = prompt
+	}
+}
+
+// WithBackend selects how the tunnel is established. Defaults to BackendAuto.
+func WithBackend(backend Backend) Option {
+	return func(self *SSHHandler) {
+		self.backend = backend
+	}
+}
+
+func NewSSHHandler(opts ...Option) *SSHHandler {
+	handler := &SSHHandler{
 		deps: dependencies{
 			dialContext: func(ctx context.Context, network, addr string) (io.Closer, error) {
 				return (&net.Dialer{}).DialContext(ctx, network, addr)
 			},
-			startCmd: func(cmd *exec.Cmd) error { return cmd.Start() },
-			tempDir:  ioutil.TempDir,
-			getenv:   os.Getenv,
-			setenv:   os.Setenv,
+			startCmd:     func(cmd *exec.Cmd) error { return cmd.Start() },
+			tempDir:      ioutil.TempDir,
+			tempRoot:     os.TempDir,
+			userCacheDir: os.UserCacheDir,
+			getenv:       os.Getenv,
+			setenv:       os.Setenv,
+			unsetenv:     os.Unsetenv,
+			now:          time.Now,
+			after:        time.After,
+			removeAll:    os.RemoveAll,
+			remove:       os.Remove,
+			stat:         os.Stat,
+			readDir:      ioutil.ReadDir,
+			lookPath:     exec.LookPath,
+
+			terminateProcessGroup: terminateProcessGroup,
+			killProcessGroup:      killProcessGroup,
+
+			dialSSH:   dialSSH,
+			listen:    net.Listen,
+			sshConfig: ssh_config.GetAll,
+			readFile:  ioutil.ReadFile,
+			homeDir:   os.UserHomeDir,
 		},
 	}
+
+	for _, opt := range opts {
+		opt(handler)
+	}
+
+	return handler
+}
+
+// sshSchemes are the DOCKER_HOST url schemes we tunnel over ssh. Besides
+// docker's own "ssh", some tools emit "ssh+docker" or "docker+ssh".
+var sshSchemes = map[string]bool{
+	"ssh":        true,
+	"ssh+docker": true,
+	"docker+ssh": true,
+}
+
+// isSSHScheme reports whether a DOCKER_HOST with the given scheme should be
+// tunneled over ssh. Schemes are case insensitive.
+func isSSHScheme(scheme string) bool {
+	return sshSchemes[strings.ToLower(scheme)]
+}
+
+// IsSSHDockerHost reports whether we'd tunnel to the given docker host over
+// ssh, e.g. ssh://user@host
+func IsSSHDockerHost(dockerHost string) bool {
+	u, err := url.Parse(strings.TrimSpace(dockerHost))
+	return err == nil && isSSHScheme(u.Scheme)
+}
+
+// disableTunnelEnvVar is an escape hatch for environments where we shouldn't
+// tunnel, e.g. CI: when it's set we treat an ssh:// DOCKER_HOST as though it
+// weren't set, connecting to the local docker daemon instead
+const disableTunnelEnvVar = "LAZYNERD_DISABLE_SSH_TUNNEL"
+
+// tunnelingDisabled reports whether disableTunnelEnvVar is set to anything but
+// a false value
+func (self *SSHHandler) tunnelingDisabled() bool {
+	value := self.deps.getenv(disableTunnelEnvVar)
+	if value == "" {
+		return false
+	}
+	disabled, err := strconv.ParseBool(value)
+	return err != nil || disabled
 }
 
 // HandleSSHDockerHost overrides the DOCKER_HOST environment variable
 // to point towards a local unix socket tunneled over SSH to the specified ssh host.
-func (self *SSHHandler) HandleSSHDockerHost() (io.Closer, error) {
+// ctx only covers setting up the tunnel: cancelling it aborts the setup, but
+// once we've returned the tunnel stays up until it's closed, however long ctx
+// lasts. With WithoutEnvOverride the
+// tunnel is set up but DOCKER_HOST is left alone. Calling it again for the same
+// host while the tunnel is up returns the same tunnel, which is only torn down
+// once every returned closer has been closed.
+func (self *SSHHandler) HandleSSHDockerHost(ctx context.Context) (Tunnel, error) {
 	const key = "DOCKER_HOST"
-	ctx := context.Background()
-	u, err := url.Parse(self.deps.getenv(key))
+	// stray whitespace, e.g. from a script that quoted the value generously,
+	// would otherwise end up in the scheme and we'd skip tunneling
+	dockerHost := strings.TrimSpace(self.deps.getenv(key))
+	if dockerHost == "" {
+		return self.handleDockerContextHost(ctx)
+	}
+	u, err := url.Parse(dockerHost)
 	if err != nil {
 		// if no or an invalid docker host is specified, continue nominally
 		return noopCloser{}, nil
 	}
 
 	// if the docker host scheme is "ssh", forward the docker socket before creating the client
-	if u.Scheme == "ssh" {
-		tunnel, err := self.createDockerHostTunnel(ctx, u.Host)
+	if isSSHScheme(u.Scheme) {
+		if self.tunnelingDisabled() {
+			self.logger().Debugf("%s is set, not tunneling to %s", disableTunnelEnvVar, dockerHost)
+			if self.skipEnvOverride {
+				return noopCloser{}, nil
+			}
+			// the docker client can't talk ssh itself, so fall back to the
+			// default local socket
+			if err := self.deps.unsetenv(key); err != nil {
+				return noopCloser{}, fmt.Errorf("unset ssh DOCKER_HOST: %w", err)
+			}
+			return noopCloser{}, nil
+		}
+
+		tunnel, err := self.acquireTunnel(ctx, dockerHost)
 		if err != nil {
-			return noopCloser{}, fmt.Errorf("tunnel ssh docker host: %w", err)
+			return noopCloser{}, err
+		}
+		if self.skipEnvOverride {
+			return tunnel, nil
 		}
-		err = self.deps.setenv(key, tunnel.socketPath)
+		err = self.deps.setenv(key, tunnel.SocketPath())
 		if err != nil {
 			return noopCloser{}, fmt.Errorf("override DOCKER_HOST to tunneled socket: %w", err)
 		}
@@ -68,89 +524,1202 @@ func (self *SSHHandler) HandleSSHDockerHost() (io.Closer, error) {
 	return noopCloser{}, nil
 }
 
+// handleDockerContextHost follows the active docker context when DOCKER_HOST
+// isn't set, the way the docker CLI does. We point DOCKER_HOST at the context's
+// host, and DOCKER_CERT_PATH and DOCKER_TLS_VERIFY at its TLS files if it has
+// any, since the docker client doesn't know about contexts, and tunnel to it
+// if it's an ssh:// host.
+func (self *SSHHandler) handleDockerContextHost(ctx context.Context) (Tunnel, error) {
+	dockerContext, err := self.activeDockerContext()
+	if err != nil {
+		// the docker CLI would refuse to run, but we can still try the
+		// default socket
+		self.logger().Warnf("ignoring docker context: %v", err)
+		return noopCloser{}, nil
+	}
+	dockerHost := dockerContext.Host
+	if dockerHost == "" {
+		return noopCloser{}, nil
+	}
+	self.logger().Debugf("DOCKER_HOST isn't set, using %s from the current docker context", dockerHost)
+
+	if self.skipEnvOverride {
+		u, err := url.Parse(dockerHost)
+		if err != nil || !isSSHScheme(u.Scheme) {
+			return noopCloser{}, nil
+		}
+		return self.acquireTunnel(ctx, dockerHost)
+	}
+
+	// the docker CLI goes by the context's TLS files rather than these, so
+	// any left over from before are dropped
+	env := dockerContext.Env()
+	for _, key := range []string{"DOCKER_CERT_PATH", "DOCKER_TLS_VERIFY"} {
+		var err error
+		if env[key] == "" {
+			err = self.deps.unsetenv(key)
+		} else {
+			err = self.deps.setenv(key, env[key])
+		}
+		if err != nil {
+			return noopCloser{}, fmt.Errorf("set %s from docker context: %w", key, err)
+		}
+	}
+
+	// with DOCKER_HOST set, we go on exactly as if the user had set it
+	if err := self.deps.setenv("DOCKER_HOST", dockerHost); err != nil {
+		return noopCloser{}, fmt.Errorf("set DOCKER_HOST from docker context: %w", err)
+	}
+	return self.HandleSSHDockerHost(ctx)
+}
+
+// OpenTunnel tunnels the docker socket of the host in the given ssh:// url to
+// a local unix socket, without touching DOCKER_HOST. This lets you keep several
+// docker hosts connected at once, each via its own tunnel. As with
+// HandleSSHDockerHost, ctx only covers setting up the tunnel. The caller is
+// responsible for closing the tunnel, which is the only way to tear it down.
+func (self *SSHHandler) OpenTunnel(ctx context.Context, sshURL string) (Tunnel, error) {
+	tunnel, err := self.openTunnel(ctx, sshURL)
+	if err != nil {
+		return nil, err
+	}
+	return tunnel, nil
+}
+
+func (self *SSHHandler) openTunnel(ctx context.Context, sshURL string) (*tunneledDockerHost, error) {
+	u, err := parseSSHURL(sshURL)
+	if err != nil {
+		return nil, err
+	}
+
+	backend, err := self.resolveBackend()
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := newTunnelTarget(u)
+	if err != nil {
+		return nil, err
+	}
+
+	if self.localBind != "" {
+		if _, _, err := parseLocalBind(self.localBind); err != nil {
+			return nil, err
+		}
+	}
+
+	// catch a broken ~/.ssh/config entry before ssh trips over it
+	host, err := self.resolveSSHConfig(target)
+	if err != nil {
+		return nil, fmt.Errorf("tunnel ssh docker host: %w", err)
+	}
+	if host.fromConfig {
+		self.logger().Debugf("ssh config resolves %s to %s", target.host, host)
+	}
+
+	tunnel, err := self.createDockerHostTunnel(ctx, target, backend)
+	if err != nil {
+		return nil, fmt.Errorf("tunnel ssh docker host: %w", err)
+	}
+
+	return tunnel, nil
+}
+
+// parseSSHURL parses an ssh:// docker host, rejecting anything we can't tunnel to
+func parseSSHURL(sshURL string) (*url.URL, error) {
+	u, err := url.Parse(sshURL)
+	if err != nil {
+		return nil, fmt.Errorf("parse ssh docker host: %w", err)
+	}
+	if !isSSHScheme(u.Scheme) {
+		return nil, fmt.Errorf("expected an ssh:// docker host, got %q", sshURL)
+	}
+	// otherwise ssh is run with a blank host and fails cryptically
+	if u.Hostname() == "" {
+		return nil, fmt.Errorf("%w: %q", ErrMissingHost, sshURL)
+	}
+	return u, nil
+}
+
+// resolveBackend decides how we'll establish the tunnel. Unless told otherwise
+// we prefer the ssh binary, since it honours everything in the user's ssh
+// setup, and fall back to the native client when there isn't one.
+func (self *SSHHandler) resolveBackend() (Backend, error) {
+	if self.backend == BackendNative {
+		return BackendNative, nil
+	}
+
+	// check for ssh up front, otherwise a missing binary surfaces as a
+	// confusing tunnel failure
+	if _, err := self.deps.lookPath(self.getSSHBinary()); err != nil {
+		if self.backend == BackendExec {
+			return "", &sentinelError{sentinel: ErrSSHBinaryMissing, err: err}
+		}
+		return BackendNative, nil
+	}
+
+	return BackendExec, nil
+}
+
+const (
+	// defaultKeepAliveInterval is comfortably below the idle timeouts of most
+	// firewalls and NATs
+	defaultKeepAliveInterval = 30 * time.Second
+
+	// defaultKeepAliveCountMax matches what most people set alongside a 30s
+	// interval, so a dead connection is noticed within a couple of minutes
+	defaultKeepAliveCountMax = 3
+)
+
+// getKeepAlive returns the keepalive interval, rounded to whole seconds since
+// that's all ssh takes, and count. A zero interval means keepalives are off.
+func (self *SSHHandler) getKeepAlive() (time.Duration, int) {
+	interval, countMax := self.keepAliveInterval, self.keepAliveCountMax
+	if interval < 0 {
+		return 0, 0
+	}
+	if interval == 0 {
+		interval = defaultKeepAliveInterval
+	}
+	interval = interval.Round(time.Second)
+	if interval < time.Second {
+		interval = time.Second
+	}
+	if countMax <= 0 {
+		countMax = defaultKeepAliveCountMax
+	}
+	return interval, countMax
+}
+
+// defaultSSHBinary is the ssh executable we run unless told otherwise
+const defaultSSHBinary = "ssh"
+
+func (self *SSHHandler) getSSHBinary() string {
+	if self.sshBinary == "" {
+		return defaultSSHBinary
+	}
+	return self.sshBinary
+}
+
+// tunnelTarget describes the remote end of an ssh tunnel, as parsed from the
+// DOCKER_HOST url
+type tunnelTarget struct {
+	// host is the ssh host without the port. IPv6 literals have their brackets
+	// stripped, which is the form the ssh binary expects.
+	host string
+
+	// port is the ssh port, or blank if none was given
+	port string
+
+	// user is the ssh user, or blank if none was given, in which case it's
+	// whatever the ssh config or the local user says
+	user string
+
+	// remoteSocket is the path of the docker socket on the remote host
+	remoteSocket string
+
+	// remoteTCP is the host:port of a docker daemon listening on tcp, as seen
+	// from the remote host. If set we forward to it instead of remoteSocket.
+	remoteTCP string
+
+	// jumpHosts is a comma-separated list of bastion hosts to hop through, as
+	// given by the 'jump' query parameter (ssh://host?jump=bastion). It's
+	// passed as-is to ssh's -J flag.
+	jumpHosts string
+}
+
+func newTunnelTarget(u *url.URL) (tunnelTarget, error) {
+	remoteTCP, err := remoteTCPAddress(u)
+	if err != nil {
+		return tunnelTarget{}, err
+	}
+
+	return tunnelTarget{
+		host:         u.Hostname(),
+		port:         u.Port(),
+		user:         u.User.Username(),
+		remoteSocket: remoteSocketPath(u),
+		remoteTCP:    remoteTCP,
+		jumpHosts:    u.Query().Get("jump"),
+	}, nil
+}
+
+// remoteEndpoint is where on the remote host we forward the local socket to,
+// in the form ssh's -L flag expects
+func (t tunnelTarget) remoteEndpoint() string {
+	if t.remoteTCP != "" {
+		return t.remoteTCP
+	}
+	return t.remoteSocket
+}
+
+// remoteTCPAddress returns the address given by the 'remote' query parameter,
+// for daemons that listen on tcp rather than a unix socket, e.g.
+// ssh://host?remote=tcp://127.0.0.1:2375. The local end of the tunnel is a unix
+// socket either way.
+func remoteTCPAddress(u *url.URL) (string, error) {
+	remote := u.Query().Get("remote")
+	if remote == "" {
+		return "", nil
+	}
+
+	remoteURL, err := url.Parse(remote)
+	if err != nil || remoteURL.Scheme != "tcp" || remoteURL.Hostname() == "" || remoteURL.Port() == "" {
+		return "", fmt.Errorf("invalid remote %q in ssh DOCKER_HOST: expected tcp://host:port", remote)
+	}
+	// keeping the brackets around IPv6 literals, which ssh needs to tell the
+	// address apart from the port
+	return remoteURL.Host, nil
+}
+
+// remoteSocketPath returns the path of the docker socket on the remote host.
+// It can be given either as the url path (ssh://host/run/user/1000/docker.sock)
+// or as a 'socket' query parameter (ssh://host?socket=/run/user/1000/docker.sock),
+// with the query parameter taking precedence.
+func remoteSocketPath(u *url.URL) string {
+	if socket := u.Query().Get("socket"); socket != "" {
+		return socket
+	}
+	if u.Path != "" && u.Path != "/" {
+		return u.Path
+	}
+	return defaultRemoteSocket
+}
+
+// Tunnel is what HandleSSHDockerHost and OpenTunnel hand back
+type Tunnel interface {
+	io.Closer
+
+	// SocketPath returns the url of the local end of the tunnel, e.g.
+	// unix:///tmp/lazydocker-sshtunnel-123/myhost.sock
+	SocketPath() string
+
+	// Done returns a channel which receives the error that brought the tunnel
+	// down before it was closed
+	Done() <-chan error
+
+	// Stats describe how setting up the tunnel went
+	Stats() TunnelStats
+
+	// ForwardPort forwards a local port to remoteAddress, as seen from the
+	// remote host, over the tunnel's ssh connection. The forward is closed
+	// along with the tunnel if it isn't closed first.
+	ForwardPort(ctx context.Context, remoteAddress string) (*PortForward, error)
+}
+
+// noopCloser is the Tunnel we return when there's nothing to tunnel. It never
+// goes down, so Done blocks forever.
 type noopCloser struct{}
 
+var _ Tunnel = noopCloser{}
+
 func (noopCloser) Close() error { return nil }
 
+func (noopCloser) SocketPath() string { return "" }
+
+func (noopCloser) Done() <-chan error { return nil }
+
+func (noopCloser) Stats() TunnelStats { return TunnelStats{} }
+
+func (noopCloser) ForwardPort(ctx context.Context, remoteAddress string) (*PortForward, error) {
+	return nil, ErrNotTunneled
+}
+
+// tunnelProcess is whatever is doing the forwarding for a tunnel: either an ssh
+// child process or the native client
+type tunnelProcess interface {
+	// wait blocks until the tunnel stops forwarding
+	wait() error
+	// terminate asks the tunnel to shut down gracefully
+	terminate() error
+	// kill shuts the tunnel down immediately
+	kill() error
+}
+
+// execProcess is a tunnel run by the ssh binary
+type execProcess struct {
+	cmd  *exec.Cmd
+	deps dependencies
+}
+
+// errProcessNotStarted is returned when signalling a command which never
+// started, e.g. because startCmd is stubbed out
+var errProcessNotStarted = errors.New("ssh tunnel process was never started")
+
+func (p *execProcess) wait() error { return p.cmd.Wait() }
+
+func (p *execProcess) terminate() error {
+	if p.cmd.Process == nil {
+		return errProcessNotStarted
+	}
+	return terminateTunnelProcess(p.cmd, p.deps)
+}
+
+func (p *execProcess) kill() error {
+	if p.cmd.Process == nil {
+		return errProcessNotStarted
+	}
+	return killTunnelProcess(p.cmd, p.deps)
+}
+
 type tunneledDockerHost struct {
 	socketPath string
-	cmd        *exec.Cmd
+	// socketDir is the temp directory holding the local socket. We own it, so
+	// we remove it when the tunnel is closed.
+	socketDir string
+	deps      dependencies
+	log       Logger
+
+	// reconnect restarts the tunnel after it drops, returning once the socket
+	// is accepting connections again. Nil if auto-reconnect is off.
+	reconnect func() (tunnelProcess, error)
+	// maxReconnects is how many consecutive reconnect attempts we make before
+	// giving up on the tunnel
+	maxReconnects int
+	// endLifetime cancels the context reconnects run with, so that closing
+	// the tunnel calls off a reconnect that's under way. Nil if there isn't
+	// one.
+	endLifetime context.CancelFunc
+
+	// mutex guards the fields below, which change when we reconnect
+	mutex   sync.Mutex
+	process tunnelProcess
+	// exited is closed once the current tunnel process has exited
+	exited chan struct{}
+	// closing is set once Close has been called, so that we don't mistake the
+	// process exiting for a dropped connection
+	closing bool
+	// finished is set once the tunnel is down for good
+	finished bool
+
+	// closeOnce makes Close idempotent: signalling the process a second time
+	// could hit an unrelated process that has since been given its pid
+	closeOnce sync.Once
+	closeErr  error
+
+	// done receives the error that brought the tunnel down for good, then is
+	// closed
+	done chan error
+
+	// stats describe the initial setup of the tunnel. They're set before the
+	// tunnel is handed out and don't change after.
+	stats TunnelStats
+
+	// forwardSSH forwards a port by running ssh again, for when the tunnel is
+	// run by the ssh binary. Nil for the native client, which can forward
+	// over its own connection.
+	forwardSSH func(ctx context.Context, remoteAddress string) (*PortForward, error)
+	// forwards are the open port forwards, guarded by mutex
+	forwards []*PortForward
+}
+
+// TunnelStats describe how setting up a tunnel went, e.g. for showing
+// "connected in 1.2s after 3 attempts"
+type TunnelStats struct {
+	// SetupDuration is how long it took from starting the tunnel to its socket
+	// accepting connections
+	SetupDuration time.Duration
+	// DialAttempts is how many times we dialed the socket before it accepted
+	// a connection
+	DialAttempts int
+}
+
+var _ Tunnel = (*tunneledDockerHost)(nil)
+
+func newTunneledDockerHost(socketPath, socketDir string, process tunnelProcess, deps dependencies, log Logger, reconnect func() (tunnelProcess, error), maxReconnects int) *tunneledDockerHost {
+	t := &tunneledDockerHost{
+		socketPath:    socketPath,
+		socketDir:     socketDir,
+		deps:          deps,
+		log:           log,
+		reconnect:     reconnect,
+		maxReconnects: maxReconnects,
+		process:       process,
+		exited:        make(chan struct{}),
+		done:          make(chan error, 1),
+	}
+
+	go t.monitor()
+
+	return t
+}
+
+// monitor waits on the tunnel process so that we find out when it exits, whether
+// that's because we closed the tunnel or because the connection dropped. In the
+// latter case we restart it, if auto-reconnect is enabled.
+func (t *tunneledDockerHost) monitor() {
+	process, exited := t.process, t.exited
+
+	for {
+		err := process.wait()
+		close(exited)
+
+		if t.isClosing() {
+			t.log.Debugf("ssh tunnel process for %s %s", t.socketPath, describeExit(err))
+			t.finish(err)
+			return
+		}
+
+		t.log.Warnf("ssh tunnel for %s dropped: process %s", t.socketPath, describeExit(err))
+		if t.reconnect == nil {
+			t.finish(err)
+			return
+		}
+
+		process, err = t.reestablish()
+		if err != nil {
+			t.finish(err)
+			return
+		}
+
+		t.mutex.Lock()
+		if t.closing {
+			// Close was called while we were reconnecting, and it won't have
+			// known about this process
+			t.mutex.Unlock()
+			_ = process.kill()
+			t.finish(process.wait())
+			return
+		}
+		exited = make(chan struct{})
+		t.process, t.exited = process, exited
+		t.mutex.Unlock()
+
+		t.log.Debugf("ssh tunnel for %s re-established", t.socketPath)
+	}
+}
+
+func describeExit(err error) string {
+	if err == nil {
+		return "exited cleanly"
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return fmt.Sprintf("exited with code %d", exitErr.ExitCode())
+	}
+	return fmt.Sprintf("exited: %v", err)
+}
+
+// reestablish calls reconnect until it succeeds or we run out of attempts
+func (t *tunneledDockerHost) reestablish() (tunnelProcess, error) {
+	var err error
+	for attempt := 0; attempt < t.maxReconnects; attempt++ {
+		if t.isClosing() {
+			break
+		}
+
+		t.log.Debugf("reconnecting ssh tunnel for %s, attempt %d of %d", t.socketPath, attempt+1, t.maxReconnects)
+		var process tunnelProcess
+		process, err = t.reconnect()
+		if err == nil {
+			return process, nil
+		}
+		t.log.Warnf("reconnecting ssh tunnel for %s failed: %v", t.socketPath, err)
+	}
+	return nil, fmt.Errorf("ssh tunnel dropped and could not be re-established after %d attempts: %w", t.maxReconnects, err)
+}
+
+func (t *tunneledDockerHost) stopReconnecting() {
+	if t.endLifetime != nil {
+		t.endLifetime()
+	}
+}
+
+func (t *tunneledDockerHost) isClosing() bool {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	return t.closing
+}
+
+// alive reports whether the tunnel is up, or being brought back up after a drop
+func (t *tunneledDockerHost) alive() bool {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	return !t.closing && !t.finished
+}
+
+func (t *tunneledDockerHost) finish(err error) {
+	t.mutex.Lock()
+	t.finished = true
+	t.mutex.Unlock()
+	t.stopReconnecting()
+
+	t.done <- err
+	close(t.done)
+}
+
+// SocketPath returns the url of the local end of the tunnel, e.g.
+// unix:///tmp/lazydocker-sshtunnel-123/myhost.sock, which is what DOCKER_HOST
+// is set to. It stays the same across reconnects.
+func (t *tunneledDockerHost) SocketPath() string {
+	return t.socketPath
+}
+
+// Stats describe how setting up the tunnel went. Reconnects don't affect them.
+func (t *tunneledDockerHost) Stats() TunnelStats {
+	return t.stats
+}
+
+// Done returns a channel which receives the error that brought the tunnel down
+// (nil if ssh exited cleanly), after which it is closed. If this fires before
+// you've called Close, the tunnel has dropped (and, with auto-reconnect, could
+// not be brought back) and the docker socket is dead.
+func (t *tunneledDockerHost) Done() <-chan error {
+	return t.done
 }
 
-var _ io.Closer = (*tunneledDockerHost)(nil)
+// tunnelShutdownGracePeriod is how long we give ssh to exit after asking it
+// nicely before we resort to killing it
+const tunnelShutdownGracePeriod = 2 * time.Second
 
+// Close stops the ssh process and removes the temp directory holding the
+// local socket. The directory is removed even if stopping the process fails.
+// Calling it again does nothing and returns the same error.
 func (t *tunneledDockerHost) Close() error {
-	return syscall.Kill(-t.cmd.Process.Pid, syscall.SIGKILL)
+	t.closeOnce.Do(func() {
+		t.closeErr = t.close()
+	})
+	return t.closeErr
+}
+
+func (t *tunneledDockerHost) close() error {
+	t.log.Debugf("tearing down ssh tunnel for %s", t.socketPath)
+
+	t.mutex.Lock()
+	t.closing = true
+	process, exited := t.process, t.exited
+	t.mutex.Unlock()
+	t.stopReconnecting()
+
+	err := stopProcess(process, exited)
+	t.closeForwards()
+
+	if removeErr := t.deps.removeAll(t.socketDir); removeErr != nil && err == nil {
+		err = fmt.Errorf("remove ssh tunnel tmp dir: %w", removeErr)
+	}
+
+	return err
+}
+
+// abort kills a tunnel that never came up. Unlike Close there's no grace
+// period: nothing is using the tunnel yet, and we don't want a half-started ssh
+// process lingering behind if setup was cancelled.
+func (t *tunneledDockerHost) abort() {
+	t.mutex.Lock()
+	t.closing = true
+	process, exited := t.process, t.exited
+	t.mutex.Unlock()
+	t.stopReconnecting()
+
+	select {
+	case <-exited:
+	default:
+		if err := process.kill(); err != nil {
+			t.log.Warnf("failed to kill ssh tunnel for %s: %v", t.socketPath, err)
+		}
+		<-exited
+	}
+
+	_ = t.deps.removeAll(t.socketDir)
+}
+
+// stopProcess asks the tunnel process to terminate, giving ssh a chance to clean
+// up its children and control socket, and kills it if it hasn't exited within
+// the grace period.
+func stopProcess(process tunnelProcess, exited <-chan struct{}) error {
+	select {
+	case <-exited:
+		// nothing to stop, and its pid may since have been recycled
+		return nil
+	default:
+	}
+
+	if err := process.terminate(); err != nil {
+		return process.kill()
+	}
+
+	timer := time.NewTimer(tunnelShutdownGracePeriod)
+	defer timer.Stop()
+
+	select {
+	case <-exited:
+		return nil
+	case <-timer.C:
+		return process.kill()
+	}
 }
 
-func (self *SSHHandler) createDockerHostTunnel(ctx context.Context, remoteHost string) (*tunneledDockerHost, error) {
-	socketDir, err := self.deps.tempDir("/tmp", "lazydocker-sshtunnel-")
+func (self *SSHHandler) createDockerHostTunnel(ctx context.Context, target tunnelTarget, backend Backend) (*tunneledDockerHost, error) {
+	setupStart := self.deps.now()
+
+	socketName := socketFileNameFor(target)
+	socketDir, err := self.createSocketDir(socketName)
+	if err != nil {
+		return nil, err
+	}
+	local, err := self.newLocalEndpoint(socketDir, socketName)
 	if err != nil {
-		return nil, fmt.Errorf("create ssh tunnel tmp file: %w", err)
+		_ = self.deps.removeAll(socketDir)
+		return nil, err
+	}
+
+	if err := self.removeStaleSocket(local); err != nil {
+		_ = self.deps.removeAll(socketDir)
+		return nil, err
 	}
-	localSocket := path.Join(socketDir, "dockerhost.sock")
 
-	cmd, err := self.tunnelSSH(ctx, remoteHost, localSocket)
+	stderr := newTailBuffer(maxStderrTailLength)
+	process, err := self.startTunnel(ctx, backend, target, local, stderr)
 	if err != nil {
-		return nil, fmt.Errorf("tunnel docker host over ssh: %w", err)
+		_ = self.deps.removeAll(socketDir)
+		return nil, self.newTunnelError(target, "", fmt.Errorf("tunnel docker host over ssh: %w", err))
 	}
 
-	// set a reasonable timeout, then wait for the socket to dial successfully
-	// before attempting to create a new docker client
-	const socketTunnelTimeout = 8 * time.Second
-	ctx, cancel := context.WithTimeout(ctx, socketTunnelTimeout)
-	defer cancel()
+	// reconnects happen long after ctx, which is just for setting up, has
+	// had its day, so they go by the tunnel's lifetime instead, which ends
+	// when it's closed
+	lifetime, endLifetime := context.WithCancel(context.Background())
+	var reconnect func() (tunnelProcess, error)
+	if self.maxReconnects > 0 {
+		reconnect = func() (tunnelProcess, error) {
+			return self.reconnectTunnel(lifetime, backend, target, local)
+		}
+	}
+
+	tunnel := newTunneledDockerHost(local.url(), socketDir, process, self.deps, self.logger(), reconnect, self.maxReconnects)
+	tunnel.endLifetime = endLifetime
+	if backend != BackendNative {
+		tunnel.forwardSSH = func(ctx context.Context, remoteAddress string) (*PortForward, error) {
+			return self.forwardPortSSH(ctx, target, local.dir, remoteAddress)
+		}
+	}
 
-	err = self.retrySocketDial(ctx, localSocket)
+	// wait for the socket to dial successfully before attempting to create a
+	// new docker client
+	attempts, err := self.waitForSocket(ctx, target, local, stderr)
 	if err != nil {
-		return nil, fmt.Errorf("ssh tunneled socket never became available: %w", err)
+		tunnel.abort()
+		return nil, err
 	}
 
-	// construct the new DOCKER_HOST url with the proper scheme
-	newDockerHostURL := url.URL{Scheme: "unix", Path: localSocket}
-	return &tunneledDockerHost{
-		socketPath: newDockerHostURL.String(),
-		cmd:        cmd,
-	}, nil
+	tunnel.stats = TunnelStats{SetupDuration: self.deps.now().Sub(setupStart), DialAttempts: attempts}
+	self.logger().Debugf("ssh tunnel to %s connected in %s after %d dial attempt(s)", target.host, tunnel.stats.SetupDuration, attempts)
+
+	return tunnel, nil
 }
 
-// Attempt to dial the socket until it becomes available.
-// The retry loop will continue until the parent context is canceled.
-func (self *SSHHandler) retrySocketDial(ctx context.Context, socketPath string) error {
-	t := time.NewTicker(1 * time.Second)
-	defer t.Stop()
+// reconnectTunnel starts a fresh tunnel forwarding to the same local socket
+// path or port, so that anything holding the old DOCKER_HOST keeps working, and
+// waits for the socket to come back.
+func (self *SSHHandler) reconnectTunnel(ctx context.Context, backend Backend, target tunnelTarget, local localEndpoint) (tunnelProcess, error) {
+	if err := self.removeStaleSocket(local); err != nil {
+		return nil, err
+	}
 
-	for {
+	stderr := newTailBuffer(maxStderrTailLength)
+	process, err := self.startTunnel(ctx, backend, target, local, stderr)
+	if err != nil {
+		return nil, self.newTunnelError(target, "", fmt.Errorf("tunnel docker host over ssh: %w", err))
+	}
+
+	if _, err := self.waitForSocket(ctx, target, local, stderr); err != nil {
+		_ = process.kill()
+		_ = process.wait()
+		return nil, err
+	}
+
+	return process, nil
+}
+
+// removeStaleSocket removes whatever is left at the local socket path, e.g. by
+// a previous tunnel process, since ssh can't bind to a path that already
+// exists. There's nothing to do for a tcp port.
+func (self *SSHHandler) removeStaleSocket(local localEndpoint) error {
+	if local.network != "unix" {
+		return nil
+	}
+	localSocket := local.address
+	err := self.deps.remove(localSocket)
+	if err == nil {
+		self.logger().Debugf("removed stale tunneled socket %s", localSocket)
+		return nil
+	}
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return fmt.Errorf("remove stale tunneled socket %s: %w", localSocket, err)
+}
+
+// startTunnel starts forwarding the local endpoint to the remote docker socket
+// using the given backend
+func (self *SSHHandler) startTunnel(ctx context.Context, backend Backend, target tunnelTarget, local localEndpoint, stderr io.Writer) (tunnelProcess, error) {
+	self.logger().Debugf("starting %s ssh tunnel to %s, forwarding %s to %s", backend, target.host, local.address, target.remoteEndpoint())
+
+	if backend == BackendNative {
+		return self.tunnelNative(ctx, target, local)
+	}
+
+	cmd, err := self.tunnelSSH(target, local, stderr)
+	if err != nil {
+		return nil, err
+	}
+	return &execProcess{cmd: cmd, deps: self.deps}, nil
+}
+
+// waitForSocket dials the tunneled socket until it accepts connections or the
+// tunnel timeout elapses. Returns how many dial attempts were made.
+func (self *SSHHandler) waitForSocket(ctx context.Context, target tunnelTarget, local localEndpoint, stderr *tailBuffer) (int, error) {
+	socketTunnelTimeout := self.getTunnelTimeout()
+	ctx, cancel := self.withTimeout(ctx, socketTunnelTimeout)
+	defer cancel()
+
+	attempts, err := self.retrySocketDial(ctx, local)
+	if err == nil {
+		return attempts, nil
+	}
+
+	var cause error = fmt.Errorf("%s: %w", ErrTunnelTimeout, err)
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		cause = &sentinelError{sentinel: ErrTunnelTimeout, err: fmt.Errorf("gave up after %s: %w", socketTunnelTimeout, err)}
+	case errors.Is(err, ErrDialAttemptsExhausted):
+		cause = err
+	}
+
+	// whatever ssh printed is far more useful than a timeout, e.g.
+	// 'Permission denied (publickey)', so TunnelError includes it
+	return attempts, self.newTunnelError(target, stderr.String(), cause)
+}
+
+// newTunnelError builds a TunnelError for target, noting where ~/.ssh/config
+// points it if the host is an alias
+func (self *SSHHandler) newTunnelError(target tunnelTarget, stderr string, cause error) *TunnelError {
+	tunnelErr := &TunnelError{Host: target.host, Stderr: stderr, Cause: cause}
+	if host, err := self.resolveSSHConfig(target); err == nil && host.fromConfig {
+		tunnelErr.ResolvedHost = host.String()
+	}
+	return tunnelErr
+}
+
+const (
+	socketDirPattern = "lazydocker-sshtunnel-"
+
+	// socketFileName is the name we give the local socket when there's nothing
+	// usable in the host to name it after
+	socketFileName = "dockerhost.sock"
+
+	// maxSocketNameLength caps the host-derived part of the socket's file
+	// name, so that we can always fit the socket path under shortTempRoot.
+	// Every tunnel gets its own temp dir so truncated names can't collide.
+	maxSocketNameLength = 48
+
+	// controlSocketFileName is the ssh ControlPath, kept short because ssh
+	// appends a random suffix while setting up the master connection
+	controlSocketFileName = "cm.sock"
+
+	// controlPersist is how long an idle master connection is kept around
+	controlPersist = "60s"
+
+	// maxSocketPathLength is the longest path we can bind a unix socket to.
+	// sun_path is 104 bytes on macOS and the BSDs (108 on linux), including the
+	// terminating NUL, so we go with the smallest.
+	maxSocketPathLength = 103
+
+	// tempDirSuffixLength is the most characters ioutil.TempDir appends to the
+	// pattern (a random uint32)
+	tempDirSuffixLength = 10
+)
+
+// socketFileNameFor names the local socket after the host it's tunneled to,
+// e.g. 192.168.5.178-2222.sock, so that with several tunnels open it's clear
+// which socket goes where
+func socketFileNameFor(target tunnelTarget) string {
+	name := target.host
+	if target.port != "" && target.port != "22" {
+		name += "-" + target.port
+	}
+
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-':
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+	if len(name) > maxSocketNameLength {
+		name = strings.TrimRight(name[:maxSocketNameLength], "._-")
+	}
+
+	if strings.Trim(name, "._") == "" {
+		return socketFileName
+	}
+	return name + ".sock"
+}
+
+// createSocketDir creates the temp dir holding the local socket. Hardened
+// systems sometimes have an unwritable /tmp, so if we can't create it in the
+// usual place we try $XDG_RUNTIME_DIR and then the user cache dir.
+func (self *SSHHandler) createSocketDir(socketName string) (string, error) {
+	root := self.socketTempRoot(socketName)
+	dir, err := self.deps.tempDir(root, socketDirPattern)
+	if err == nil {
+		return dir, nil
+	}
+
+	tried := []string{root}
+	for _, fallback := range self.fallbackSocketRoots(socketName) {
+		if fallback == root {
+			continue
+		}
+		dir, err = self.deps.tempDir(fallback, socketDirPattern)
+		if err == nil {
+			return dir, nil
+		}
+		tried = append(tried, fallback)
+	}
+
+	return "", fmt.Errorf("create ssh tunnel tmp file (tried %s): %w", strings.Join(tried, ", "), err)
+}
+
+// fallbackSocketRoots returns the other directories we can put the socket's
+// temp dir in, skipping any that would make the socket path too long
+func (self *SSHHandler) fallbackSocketRoots(socketName string) []string {
+	candidates := []string{self.deps.getenv("XDG_RUNTIME_DIR")}
+	if cacheDir, err := self.deps.userCacheDir(); err == nil {
+		candidates = append(candidates, cacheDir)
+	}
+
+	roots := []string{}
+	for _, candidate := range candidates {
+		if candidate == "" || len(longestSocketPath(candidate, socketName)) > maxSocketPathLength {
+			continue
+		}
+		roots = append(roots, candidate)
+	}
+	return roots
+}
+
+func longestSocketPath(root string, socketName string) string {
+	return filepath.Join(root, socketDirPattern+strings.Repeat("0", tempDirSuffixLength), socketName)
+}
+
+// socketTempRoot returns the directory we create the socket's temp dir in. We
+// use the OS temp dir so that e.g. $TMPDIR is respected, but on macOS that
+// tends to be a long path under /var/folders, and unix socket paths have a
+// length limit. If the socket path could exceed that limit we fall back to a
+// shorter directory, if the platform has one.
+func (self *SSHHandler) socketTempRoot(socketName string) string {
+	root := self.deps.tempRoot()
+	if len(longestSocketPath(root, socketName)) > maxSocketPathLength && shortTempRoot != "" {
+		return shortTempRoot
+	}
+	return root
+}
+
+func (self *SSHHandler) logger() Logger {
+	if self.log == nil {
+		return noopLogger{}
+	}
+	return self.log
+}
+
+func (self *SSHHandler) getTunnelTimeout() time.Duration {
+	if self.tunnelTimeout == 0 {
+		return defaultTunnelTimeout
+	}
+	return self.tunnelTimeout
+}
+
+// defaultDialTimeout is how long a single dial attempt may take. Dialing a
+// local unix socket is normally instant, so anything slower is likely stuck.
+const defaultDialTimeout = 1 * time.Second
+
+func (self *SSHHandler) getDialTimeout() time.Duration {
+	if self.dialTimeout == 0 {
+		return defaultDialTimeout
+	}
+	return self.dialTimeout
+}
+
+const (
+	// defaultInitialDialInterval is how long we wait before the first dial
+	// attempt. Local tunnels tend to come up almost immediately so we start
+	// short.
+	defaultInitialDialInterval = 100 * time.Millisecond
+
+	// defaultMaxDialInterval caps the backoff between dial attempts
+	defaultMaxDialInterval = 1 * time.Second
+)
+
+func (self *SSHHandler) getDialIntervals() (initial time.Duration, max time.Duration) {
+	initial, max = self.deps.initialDialInterval, self.deps.maxDialInterval
+	if initial == 0 {
+		initial = defaultInitialDialInterval
+	}
+	if max == 0 {
+		max = defaultMaxDialInterval
+	}
+	return initial, max
+}
+
+// nextDialInterval doubles the interval, up to max
+func nextDialInterval(interval time.Duration, max time.Duration) time.Duration {
+	interval *= 2
+	if interval > max {
+		return max
+	}
+	return interval
+}
+
+// Attempt to dial the socket until it becomes available, backing off
+// exponentially between attempts.
+// The retry loop will continue until the parent context is canceled, or until
+// we've run out of attempts if maxDialAttempts is set. Returns how many
+// attempts were made.
+func (self *SSHHandler) retrySocketDial(ctx context.Context, local localEndpoint) (int, error) {
+	interval, maxInterval := self.getDialIntervals()
+
+	for attempt := 1; ; attempt++ {
 		select {
 		case <-ctx.Done():
-			return ctx.Err()
-		case <-t.C:
+			return attempt - 1, ctx.Err()
+		case <-self.deps.after(interval):
 		}
+		self.reportProgress(ctx, attempt)
 		// attempt to dial the socket, exit on success
-		err := self.tryDial(ctx, socketPath)
+		err := self.tryDial(ctx, local)
 		if err != nil {
+			self.logger().Debugf("dial attempt %d to %s failed: %v", attempt, local.address, err)
+			// don't go round again if we ran out of time mid-dial
+			if ctx.Err() != nil {
+				return attempt, ctx.Err()
+			}
+			if self.maxDialAttempts > 0 && attempt >= self.maxDialAttempts {
+				return attempt, &sentinelError{sentinel: ErrDialAttemptsExhausted, err: fmt.Errorf("gave up after %d attempt(s): %w", attempt, err)}
+			}
+			interval = nextDialInterval(interval, maxInterval)
 			continue
 		}
-		return nil
+		self.logger().Debugf("tunneled socket %s became available after %d attempt(s)", local.address, attempt)
+		return attempt, nil
 	}
 }
 
-// Try to dial the specified unix socket, immediately close the connection if successfully created.
-func (self *SSHHandler) tryDial(ctx context.Context, socketPath string) error {
-	conn, err := self.deps.dialContext(ctx, "unix", socketPath)
+func (self *SSHHandler) reportProgress(ctx context.Context, attempt int) {
+	if self.progress == nil {
+		return
+	}
+	var remaining time.Duration
+	if deadline, ok := ctx.Deadline(); ok {
+		remaining = deadline.Sub(self.deps.now())
+		if remaining < 0 {
+			remaining = 0
+		}
+	}
+	self.progress(attempt, remaining)
+}
+
+// Try to dial the local end of the tunnel, immediately close the connection if successfully created.
+// With ping verification on, we also check there's a docker daemon behind it.
+// Each attempt gets its own deadline on top of the parent context's.
+func (self *SSHHandler) tryDial(ctx context.Context, local localEndpoint) error {
+	dialCtx, cancel := self.withTimeout(ctx, self.getDialTimeout())
+	defer cancel()
+
+	conn, err := self.deps.dialContext(dialCtx, local.network, local.address)
 	if err != nil {
 		return err
 	}
 	defer conn.Close()
+
+	if self.pingCheck {
+		return pingDockerDaemon(conn)
+	}
 	return nil
 }
 
-func (self *SSHHandler) tunnelSSH(ctx context.Context, host, localSocket string) (*exec.Cmd, error) {
-	cmd := exec.CommandContext(ctx, "ssh", "-L", localSocket+":/var/run/docker.sock", host, "-N")
-	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+// pingTimeout is how long we give the daemon to answer a ping
+const pingTimeout = 2 * time.Second
+
+// pingDockerDaemon sends GET /_ping over conn and expects a 200 back. A socket
+// which accepts connections but has nothing usable behind it, e.g. because ssh
+// is still setting up the forward or the remote daemon is down, fails this.
+func pingDockerDaemon(conn io.Closer) error {
+	rw, ok := conn.(io.ReadWriter)
+	if !ok {
+		return errors.New("can't ping docker daemon over a connection that can't be read from and written to")
+	}
+	if deadliner, ok := conn.(interface{ SetDeadline(time.Time) error }); ok {
+		if err := deadliner.SetDeadline(time.Now().Add(pingTimeout)); err != nil {
+			return err
+		}
+	}
+
+	req, err := http.NewRequest(http.MethodGet, "http://docker/_ping", nil)
+	if err != nil {
+		return err
+	}
+	req.Close = true
+	if err := req.Write(rw); err != nil {
+		return fmt.Errorf("ping docker daemon: %w", err)
+	}
+
+	resp, err := http.ReadResponse(bufio.NewReader(rw), req)
+	if err != nil {
+		return fmt.Errorf("ping docker daemon: %w", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("ping docker daemon: got %s", resp.Status)
+	}
+	return nil
+}
+
+// tunnelSSH starts ssh forwarding the local endpoint. It isn't tied to the
+// setup's context, so that it keeps running once the setup's done: if setting
+// up fails we kill it ourselves, and otherwise it runs until the tunnel's
+// closed.
+func (self *SSHHandler) tunnelSSH(target tunnelTarget, local localEndpoint, stderr io.Writer) (*exec.Cmd, error) {
+	cmd := exec.Command(self.getSSHBinary(), self.sshArgs(target, local)...)
+	prepareTunnelProcess(cmd)
+	cmd.Stderr = stderr
 	err := self.deps.startCmd(cmd)
 	if err != nil {
 		return nil, err
 	}
 	return cmd, nil
 }
+
+// sshArgs are the arguments we run the ssh binary with to forward the local
+// endpoint to the target's docker socket
+func (self *SSHHandler) sshArgs(target tunnelTarget, local localEndpoint) []string {
+	// a tcp endpoint is host:port, which is just what -L takes
+	return self.sshForwardArgs(target, local.dir, local.address+":"+target.remoteEndpoint())
+}
+
+// sshForwardArgs are the arguments we run the ssh binary with to set up the
+// given -L forward. controlDir is where the control socket goes, if we're
+// multiplexing.
+func (self *SSHHandler) sshForwardArgs(target tunnelTarget, controlDir string, forward string) []string {
+	args := []string{"-L", forward}
+	// ssh would otherwise treat ':port' as part of the hostname
+	if target.port != "" && target.port != "22" {
+		args = append(args, "-p", target.port)
+	}
+	if target.user != "" {
+		args = append(args, "-l", target.user)
+	}
+	if target.jumpHosts != "" {
+		args = append(args, "-J", target.jumpHosts)
+	}
+	if self.agentForwarding {
+		args = append(args, "-A")
+	}
+	if self.compression {
+		args = append(args, "-C")
+	}
+	// we've nowhere to show a password or host key prompt, and ssh would
+	// otherwise block on one
+	args = append(args, "-o", "BatchMode=yes")
+	if interval, countMax := self.getKeepAlive(); interval > 0 {
+		args = append(args,
+			"-o", fmt.Sprintf("ServerAliveInterval=%d", int(interval/time.Second)),
+			"-o", fmt.Sprintf("ServerAliveCountMax=%d", countMax),
+		)
+	}
+	if self.hostKeyChecking != "" {
+		args = append(args, "-o", "StrictHostKeyChecking="+self.hostKeyChecking)
+	}
+	if self.identitiesOnly && hasIdentityFile(self.sshOptions) {
+		args = append(args, "-o", "IdentitiesOnly=yes")
+	}
+	if self.controlMaster {
+		controlPath := filepath.Join(controlDir, controlSocketFileName)
+		args = append(args,
+			"-o", "ControlMaster=auto",
+			"-o", "ControlPath="+controlPath,
+			"-o", "ControlPersist="+controlPersist,
+		)
+	}
+	// user options go last so that they can't shift the host out of position
+	args = append(args, self.sshOptions...)
+	return append(args, target.host, "-N")
+}
+
+// hasIdentityFile reports whether the ssh options name an identity file, either
+// with -i or as an IdentityFile option. The values of flags are skipped, so
+// that e.g. the user in `-l -ivan` isn't taken for an identity file.
+func hasIdentityFile(options []string) bool {
+	for i := 0; i < len(options); i++ {
+		option := options[i]
+		if !strings.HasPrefix(option, "-") || len(option) < 2 {
+			continue
+		}
+		flag := option[1]
+		if !strings.ContainsRune(sshValueFlags, rune(flag)) {
+			continue
+		}
+		value := option[2:]
+		if value == "" {
+			if i+1 == len(options) {
+				break
+			}
+			i++
+			value = options[i]
+		}
+		switch {
+		case flag == 'i':
+			return true
+		case flag == 'o' && isIdentityFileOption(value):
+			return true
+		}
+	}
+	return false
+}
+
+// sshValueFlags are the ssh flags which take a value, either as the next
+// argument or stuck onto the flag
+const sshValueFlags = "BbcDEeFIiJLlmOopQRSWw"
+
+func isIdentityFileOption(option string) bool {
+	return strings.HasPrefix(strings.ToLower(option), "identityfile")
+}
+
+// maxStderrTailLength is how much of ssh's stderr we hold onto for the sake of
+// error messages. ssh is quiet with -N so the tail is all we need.
+const maxStderrTailLength = 1024
+
+// tailBuffer is an io.Writer that retains only the last max bytes written to
+// it. It's safe to read from while the process is still writing.
+type tailBuffer struct {
+	mutex sync.Mutex
+	buf   []byte
+	max   int
+}
+
+func newTailBuffer(max int) *tailBuffer {
+	return &tailBuffer{max: max}
+}
+
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.buf = append(b.buf, p...)
+	if len(b.buf) > b.max {
+		b.buf = b.buf[len(b.buf)-b.max:]
+	}
+	return len(p), nil
+}
+
+// String returns the retained output with surrounding whitespace trimmed
+func (b *tailBuffer) String() string {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	return strings.TrimSpace(string(b.buf))
+}
@@ -1,10 +1,22 @@
 package ssh
 
 import (
+	"bufio"
 	"context"
+	"errors"
+	"fmt"
 	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -15,6 +27,8 @@ func TestSSHHandlerHandleSSHDockerHost(t *testing.T) {
 		envVarValue              string
 		expectedDialContextCount int
 		expectedStartCmdCount    int
+		expectedCmdArgs          []string
+		expectedLocalSocket      string
 	}
 
 	scenarios := []scenario{
@@ -35,6 +49,92 @@ func TestSSHHandlerHandleSSHDockerHost(t *testing.T) {
 			envVarValue:              "ssh://myhost@192.168.5.178",
 			expectedStartCmdCount:    1,
 			expectedDialContextCount: 1,
+			expectedCmdArgs:          []string{"ssh", "-L", "/tmp/lazydocker-ssh-tunnel-12345/192.168.5.178.sock:/var/run/docker.sock", "-l", "myhost", "-o", "BatchMode=yes", "-o", "ServerAliveInterval=30", "-o", "ServerAliveCountMax=3", "192.168.5.178", "-N"},
+			expectedLocalSocket:      "/tmp/lazydocker-ssh-tunnel-12345/192.168.5.178.sock",
+		},
+		{
+			testName:                 "Env var set with ssh scheme padded with whitespace",
+			envVarValue:              "  ssh://myhost@192.168.5.178 \n",
+			expectedStartCmdCount:    1,
+			expectedDialContextCount: 1,
+			expectedCmdArgs:          []string{"ssh", "-L", "/tmp/lazydocker-ssh-tunnel-12345/192.168.5.178.sock:/var/run/docker.sock", "-l", "myhost", "-o", "BatchMode=yes", "-o", "ServerAliveInterval=30", "-o", "ServerAliveCountMax=3", "192.168.5.178", "-N"},
+			expectedLocalSocket:      "/tmp/lazydocker-ssh-tunnel-12345/192.168.5.178.sock",
+		},
+		{
+			testName:                 "Env var set to whitespace only",
+			envVarValue:              "   ",
+			expectedDialContextCount: 0,
+			expectedStartCmdCount:    0,
+		},
+		{
+			testName:                 "Env var set with uppercase ssh scheme",
+			envVarValue:              "SSH://myhost@192.168.5.178",
+			expectedStartCmdCount:    1,
+			expectedDialContextCount: 1,
+			expectedCmdArgs:          []string{"ssh", "-L", "/tmp/lazydocker-ssh-tunnel-12345/192.168.5.178.sock:/var/run/docker.sock", "-l", "myhost", "-o", "BatchMode=yes", "-o", "ServerAliveInterval=30", "-o", "ServerAliveCountMax=3", "192.168.5.178", "-N"},
+			expectedLocalSocket:      "/tmp/lazydocker-ssh-tunnel-12345/192.168.5.178.sock",
+		},
+		{
+			testName:                 "Env var set with ssh+docker scheme",
+			envVarValue:              "ssh+docker://myhost@192.168.5.178",
+			expectedStartCmdCount:    1,
+			expectedDialContextCount: 1,
+			expectedCmdArgs:          []string{"ssh", "-L", "/tmp/lazydocker-ssh-tunnel-12345/192.168.5.178.sock:/var/run/docker.sock", "-l", "myhost", "-o", "BatchMode=yes", "-o", "ServerAliveInterval=30", "-o", "ServerAliveCountMax=3", "192.168.5.178", "-N"},
+			expectedLocalSocket:      "/tmp/lazydocker-ssh-tunnel-12345/192.168.5.178.sock",
+		},
+		{
+			testName:                 "Env var set with unrecognised ssh-like scheme",
+			envVarValue:              "ssh+git://myhost@192.168.5.178",
+			expectedStartCmdCount:    0,
+			expectedDialContextCount: 0,
+		},
+		{
+			testName:                 "Env var set with ssh scheme and remote socket path",
+			envVarValue:              "ssh://myhost@192.168.5.178/run/user/1000/docker.sock",
+			expectedStartCmdCount:    1,
+			expectedDialContextCount: 1,
+			expectedCmdArgs:          []string{"ssh", "-L", "/tmp/lazydocker-ssh-tunnel-12345/192.168.5.178.sock:/run/user/1000/docker.sock", "-l", "myhost", "-o", "BatchMode=yes", "-o", "ServerAliveInterval=30", "-o", "ServerAliveCountMax=3", "192.168.5.178", "-N"},
+			expectedLocalSocket:      "/tmp/lazydocker-ssh-tunnel-12345/192.168.5.178.sock",
+		},
+		{
+			testName:                 "Env var set with ssh scheme and custom port",
+			envVarValue:              "ssh://myhost@192.168.5.178:2222",
+			expectedStartCmdCount:    1,
+			expectedDialContextCount: 1,
+			expectedCmdArgs:          []string{"ssh", "-L", "/tmp/lazydocker-ssh-tunnel-12345/192.168.5.178-2222.sock:/var/run/docker.sock", "-p", "2222", "-l", "myhost", "-o", "BatchMode=yes", "-o", "ServerAliveInterval=30", "-o", "ServerAliveCountMax=3", "192.168.5.178", "-N"},
+			expectedLocalSocket:      "/tmp/lazydocker-ssh-tunnel-12345/192.168.5.178-2222.sock",
+		},
+		{
+			testName:                 "Env var set with ssh scheme and default port",
+			envVarValue:              "ssh://myhost@192.168.5.178:22",
+			expectedStartCmdCount:    1,
+			expectedDialContextCount: 1,
+			expectedCmdArgs:          []string{"ssh", "-L", "/tmp/lazydocker-ssh-tunnel-12345/192.168.5.178.sock:/var/run/docker.sock", "-l", "myhost", "-o", "BatchMode=yes", "-o", "ServerAliveInterval=30", "-o", "ServerAliveCountMax=3", "192.168.5.178", "-N"},
+			expectedLocalSocket:      "/tmp/lazydocker-ssh-tunnel-12345/192.168.5.178.sock",
+		},
+		{
+			testName:                 "Env var set with ssh scheme, user and custom port",
+			envVarValue:              "ssh://alice@host:2222",
+			expectedStartCmdCount:    1,
+			expectedDialContextCount: 1,
+			expectedCmdArgs:          []string{"ssh", "-L", "/tmp/lazydocker-ssh-tunnel-12345/host-2222.sock:/var/run/docker.sock", "-p", "2222", "-l", "alice", "-o", "BatchMode=yes", "-o", "ServerAliveInterval=30", "-o", "ServerAliveCountMax=3", "host", "-N"},
+			expectedLocalSocket:      "/tmp/lazydocker-ssh-tunnel-12345/host-2222.sock",
+		},
+		{
+			testName:                 "Env var set with ssh scheme and IPv6 host with port",
+			envVarValue:              "ssh://myhost@[fe80::1]:2222",
+			expectedStartCmdCount:    1,
+			expectedDialContextCount: 1,
+			expectedCmdArgs:          []string{"ssh", "-L", "/tmp/lazydocker-ssh-tunnel-12345/fe80__1-2222.sock:/var/run/docker.sock", "-p", "2222", "-l", "myhost", "-o", "BatchMode=yes", "-o", "ServerAliveInterval=30", "-o", "ServerAliveCountMax=3", "fe80::1", "-N"},
+			expectedLocalSocket:      "/tmp/lazydocker-ssh-tunnel-12345/fe80__1-2222.sock",
+		},
+		{
+			testName:                 "Env var set with ssh scheme and IPv6 host without port",
+			envVarValue:              "ssh://myhost@[fe80::1]",
+			expectedStartCmdCount:    1,
+			expectedDialContextCount: 1,
+			expectedCmdArgs:          []string{"ssh", "-L", "/tmp/lazydocker-ssh-tunnel-12345/fe80__1.sock:/var/run/docker.sock", "-l", "myhost", "-o", "BatchMode=yes", "-o", "ServerAliveInterval=30", "-o", "ServerAliveCountMax=3", "fe80::1", "-N"},
+			expectedLocalSocket:      "/tmp/lazydocker-ssh-tunnel-12345/fe80__1.sock",
 		},
 	}
 
@@ -42,6 +142,9 @@ func TestSSHHandlerHandleSSHDockerHost(t *testing.T) {
 		s := s
 		t.Run(s.testName, func(t *testing.T) {
 			getenv := func(key string) string {
+				if key == disableTunnelEnvVar || key == "DOCKER_CONTEXT" || key == "DOCKER_CONFIG" {
+					return ""
+				}
 				if key != "DOCKER_HOST" {
 					t.Errorf("Expected key to be DOCKER_HOST, got %s", key)
 				}
@@ -56,16 +159,20 @@ func TestSSHHandlerHandleSSHDockerHost(t *testing.T) {
 				return "/tmp/lazydocker-ssh-tunnel-12345", nil
 			}
 
+			tempRoot := func() string {
+				return "/tmp"
+			}
+
 			setenv := func(key, value string) error {
 				assert.Equal(t, "DOCKER_HOST", key)
-				assert.Equal(t, "unix:///tmp/lazydocker-ssh-tunnel-12345/dockerhost.sock", value)
+				assert.Equal(t, "unix://"+s.expectedLocalSocket, value)
 				return nil
 			}
 
 			startCmdCount := 0
 			startCmd := func(cmd *exec.Cmd) error {
-				assert.EqualValues(t, []string{"ssh", "-L", "/tmp/lazydocker-ssh-tunnel-12345/dockerhost.sock:/var/run/docker.sock", "192.168.5.178", "-N"}, cmd.Args)
-				assert.Equal(t, true, cmd.SysProcAttr.Setpgid)
+				assert.EqualValues(t, s.expectedCmdArgs, cmd.Args)
+				assertTunnelProcessPrepared(t, cmd)
 
 				startCmdCount++
 
@@ -75,7 +182,7 @@ func TestSSHHandlerHandleSSHDockerHost(t *testing.T) {
 			dialContextCount := 0
 			dialContext := func(ctx context.Context, network string, address string) (io.Closer, error) {
 				assert.Equal(t, "unix", network)
-				assert.Equal(t, "/tmp/lazydocker-ssh-tunnel-12345/dockerhost.sock", address)
+				assert.Equal(t, s.expectedLocalSocket, address)
 
 				dialContextCount++
 
@@ -84,19 +191,1501 @@ func TestSSHHandlerHandleSSHDockerHost(t *testing.T) {
 
 			handler := &SSHHandler{
 				deps: dependencies{
+					now:         time.Now,
+					after:       time.After,
+					remove:      func(path string) error { return os.ErrNotExist },
 					dialContext: dialContext,
 					startCmd:    startCmd,
 					tempDir:     tempDir,
+					tempRoot:    tempRoot,
 					getenv:      getenv,
 					setenv:      setenv,
+					lookPath:    func(file string) (string, error) { return "/usr/bin/" + file, nil },
+					sshConfig:   noSSHConfig,
+					homeDir:     func() (string, error) { return "/home/user", nil },
+					readFile:    func(filename string) ([]byte, error) { return nil, os.ErrNotExist },
+
+					initialDialInterval: time.Millisecond,
 				},
 			}
 
-			_, err := handler.HandleSSHDockerHost()
+			closer, err := handler.HandleSSHDockerHost(context.Background())
 			assert.NoError(t, err)
 
+			if s.expectedLocalSocket != "" {
+				tunnel, ok := closer.(interface{ SocketPath() string })
+				if assert.True(t, ok, "expected the closer to expose its socket path") {
+					assert.Equal(t, "unix://"+s.expectedLocalSocket, tunnel.SocketPath())
+				}
+			}
+
 			assert.Equal(t, s.expectedDialContextCount, dialContextCount)
 			assert.Equal(t, s.expectedStartCmdCount, startCmdCount)
 		})
 	}
 }
+
+func TestSSHHandlerHandleSSHDockerHostMissingBinary(t *testing.T) {
+	startCmdCount := 0
+	handler := &SSHHandler{
+		backend: BackendExec,
+		deps: dependencies{
+			now:    time.Now,
+			after:  time.After,
+			remove: func(path string) error { return os.ErrNotExist },
+			getenv: dockerHostEnv("ssh://myhost@192.168.5.178"),
+			lookPath: func(file string) (string, error) {
+				assert.Equal(t, "ssh", file)
+				return "", exec.ErrNotFound
+			},
+			startCmd: func(cmd *exec.Cmd) error {
+				startCmdCount++
+				return nil
+			},
+		},
+	}
+
+	_, err := handler.HandleSSHDockerHost(context.Background())
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "ssh binary not found in PATH")
+	assert.True(t, errors.Is(err, exec.ErrNotFound))
+	assert.True(t, errors.Is(err, ErrSSHBinaryMissing))
+	assert.Equal(t, 0, startCmdCount)
+}
+
+func TestNewTunnelTarget(t *testing.T) {
+	type scenario struct {
+		testName       string
+		dockerHost     string
+		expectedTarget tunnelTarget
+		expectError    bool
+	}
+
+	scenarios := []scenario{
+		{
+			testName:       "Host only",
+			dockerHost:     "ssh://user@myhost",
+			expectedTarget: tunnelTarget{host: "myhost", user: "user", remoteSocket: "/var/run/docker.sock"},
+		},
+		{
+			testName:       "No user",
+			dockerHost:     "ssh://myhost",
+			expectedTarget: tunnelTarget{host: "myhost", remoteSocket: "/var/run/docker.sock"},
+		},
+		{
+			testName:       "Jump host",
+			dockerHost:     "ssh://user@myhost?jump=bastion",
+			expectedTarget: tunnelTarget{host: "myhost", user: "user", remoteSocket: "/var/run/docker.sock", jumpHosts: "bastion"},
+		},
+		{
+			testName:       "Multiple jump hosts",
+			dockerHost:     "ssh://user@myhost:2222?jump=user@bastion1,bastion2:2222",
+			expectedTarget: tunnelTarget{host: "myhost", port: "2222", user: "user", remoteSocket: "/var/run/docker.sock", jumpHosts: "user@bastion1,bastion2:2222"},
+		},
+		{
+			testName:       "Remote tcp endpoint",
+			dockerHost:     "ssh://user@myhost?remote=tcp://127.0.0.1:2375",
+			expectedTarget: tunnelTarget{host: "myhost", user: "user", remoteSocket: "/var/run/docker.sock", remoteTCP: "127.0.0.1:2375"},
+		},
+		{
+			testName:       "Remote IPv6 tcp endpoint",
+			dockerHost:     "ssh://user@myhost?remote=tcp://[::1]:2375",
+			expectedTarget: tunnelTarget{host: "myhost", user: "user", remoteSocket: "/var/run/docker.sock", remoteTCP: "[::1]:2375"},
+		},
+		{
+			testName:    "Remote endpoint with unsupported scheme",
+			dockerHost:  "ssh://user@myhost?remote=unix:///var/run/docker.sock",
+			expectError: true,
+		},
+		{
+			testName:    "Remote tcp endpoint without port",
+			dockerHost:  "ssh://user@myhost?remote=tcp://127.0.0.1",
+			expectError: true,
+		},
+	}
+
+	for _, s := range scenarios {
+		s := s
+		t.Run(s.testName, func(t *testing.T) {
+			u, err := url.Parse(s.dockerHost)
+			assert.NoError(t, err)
+			target, err := newTunnelTarget(u)
+			if s.expectError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, s.expectedTarget, target)
+		})
+	}
+}
+
+func TestRemoteSocketPath(t *testing.T) {
+	type scenario struct {
+		testName     string
+		dockerHost   string
+		expectedPath string
+	}
+
+	scenarios := []scenario{
+		{
+			testName:     "No path given",
+			dockerHost:   "ssh://user@myhost",
+			expectedPath: "/var/run/docker.sock",
+		},
+		{
+			testName:     "Trailing slash only",
+			dockerHost:   "ssh://user@myhost/",
+			expectedPath: "/var/run/docker.sock",
+		},
+		{
+			testName:     "Path component",
+			dockerHost:   "ssh://user@myhost/run/user/1000/docker.sock",
+			expectedPath: "/run/user/1000/docker.sock",
+		},
+		{
+			testName:     "Socket query parameter",
+			dockerHost:   "ssh://user@myhost?socket=/run/podman/podman.sock",
+			expectedPath: "/run/podman/podman.sock",
+		},
+		{
+			testName:     "Socket query parameter takes precedence over path",
+			dockerHost:   "ssh://user@myhost/ignored.sock?socket=/run/podman/podman.sock",
+			expectedPath: "/run/podman/podman.sock",
+		},
+	}
+
+	for _, s := range scenarios {
+		s := s
+		t.Run(s.testName, func(t *testing.T) {
+			u, err := url.Parse(s.dockerHost)
+			assert.NoError(t, err)
+			assert.Equal(t, s.expectedPath, remoteSocketPath(u))
+		})
+	}
+}
+
+func TestSSHHandlerSocketTempRoot(t *testing.T) {
+	type scenario struct {
+		testName     string
+		tempRoot     string
+		socketName   string
+		expectedRoot string
+	}
+
+	longestSocketName := socketFileNameFor(tunnelTarget{host: strings.Repeat("a", 100)})
+
+	scenarios := []scenario{
+		{
+			testName:     "Short temp root",
+			tempRoot:     "/tmp",
+			socketName:   longestSocketName,
+			expectedRoot: "/tmp",
+		},
+		{
+			testName:     "Typical macOS temp root",
+			tempRoot:     "/var/folders/zz/zyxvpxvq6csfxvn_n0000000000000/T/",
+			socketName:   "192.168.5.178.sock",
+			expectedRoot: "/var/folders/zz/zyxvpxvq6csfxvn_n0000000000000/T/",
+		},
+		{
+			testName:     "Typical macOS temp root with a long host name",
+			tempRoot:     "/var/folders/zz/zyxvpxvq6csfxvn_n0000000000000/T/",
+			socketName:   longestSocketName,
+			expectedRoot: shortTempRoot,
+		},
+		{
+			testName:     "Temp root too long for a unix socket",
+			tempRoot:     "/home/someone/with/a/really/long/path/to/their/own/temporary/directory/tmp",
+			socketName:   "192.168.5.178.sock",
+			expectedRoot: shortTempRoot,
+		},
+	}
+
+	for _, s := range scenarios {
+		s := s
+		t.Run(s.testName, func(t *testing.T) {
+			if s.expectedRoot == "" {
+				t.Skip("no short temp root on this platform")
+			}
+
+			handler := &SSHHandler{
+				deps: dependencies{
+					now:      time.Now,
+					after:    time.After,
+					remove:   func(path string) error { return os.ErrNotExist },
+					tempRoot: func() string { return s.tempRoot },
+				},
+			}
+
+			root := handler.socketTempRoot(s.socketName)
+			assert.Equal(t, s.expectedRoot, root)
+
+			longestSocketPath := filepath.Join(root, socketDirPattern+"4294967295", s.socketName)
+			assert.True(t, len(longestSocketPath) <= maxSocketPathLength, "socket path %q exceeds %d characters", longestSocketPath, maxSocketPathLength)
+		})
+	}
+}
+
+func TestSSHHandlerTunnelSSHArgs(t *testing.T) {
+	type scenario struct {
+		testName     string
+		opts         []Option
+		target       tunnelTarget
+		expectedArgs []string
+	}
+
+	defaultTarget := tunnelTarget{host: "192.168.5.178", remoteSocket: defaultRemoteSocket}
+	localSocket := "/tmp/lazydocker-ssh-tunnel-12345/dockerhost.sock"
+
+	scenarios := []scenario{
+		{
+			testName:     "No options",
+			opts:         nil,
+			target:       defaultTarget,
+			expectedArgs: []string{"ssh", "-L", localSocket + ":/var/run/docker.sock", "-o", "BatchMode=yes", "-o", "ServerAliveInterval=30", "-o", "ServerAliveCountMax=3", "192.168.5.178", "-N"},
+		},
+		{
+			testName: "Control master",
+			opts:     []Option{WithControlMaster()},
+			target:   defaultTarget,
+			expectedArgs: []string{
+				"ssh", "-L", localSocket + ":/var/run/docker.sock",
+				"-o", "BatchMode=yes", "-o", "ServerAliveInterval=30", "-o", "ServerAliveCountMax=3",
+				"-o", "ControlMaster=auto",
+				"-o", "ControlPath=" + filepath.Join("/tmp/lazydocker-ssh-tunnel-12345", "cm.sock"),
+				"-o", "ControlPersist=60s",
+				"192.168.5.178", "-N",
+			},
+		},
+		{
+			testName:     "Remote tcp endpoint",
+			target:       tunnelTarget{host: "192.168.5.178", remoteSocket: defaultRemoteSocket, remoteTCP: "127.0.0.1:2375"},
+			expectedArgs: []string{"ssh", "-L", localSocket + ":127.0.0.1:2375", "-o", "BatchMode=yes", "-o", "ServerAliveInterval=30", "-o", "ServerAliveCountMax=3", "192.168.5.178", "-N"},
+		},
+		{
+			testName:     "Agent forwarding",
+			opts:         []Option{WithAgentForwarding()},
+			target:       defaultTarget,
+			expectedArgs: []string{"ssh", "-L", localSocket + ":/var/run/docker.sock", "-A", "-o", "BatchMode=yes", "-o", "ServerAliveInterval=30", "-o", "ServerAliveCountMax=3", "192.168.5.178", "-N"},
+		},
+		{
+			testName:     "Custom ssh binary",
+			opts:         []Option{WithSSHBinary("/usr/local/bin/ssh")},
+			target:       defaultTarget,
+			expectedArgs: []string{"/usr/local/bin/ssh", "-L", localSocket + ":/var/run/docker.sock", "-o", "BatchMode=yes", "-o", "ServerAliveInterval=30", "-o", "ServerAliveCountMax=3", "192.168.5.178", "-N"},
+		},
+		{
+			testName: "Identities only with an identity file",
+			opts:     []Option{WithIdentitiesOnly(), WithSSHOptions("-i", "~/.ssh/prod")},
+			target:   defaultTarget,
+			expectedArgs: []string{
+				"ssh", "-L", localSocket + ":/var/run/docker.sock",
+				"-o", "BatchMode=yes", "-o", "ServerAliveInterval=30", "-o", "ServerAliveCountMax=3",
+				"-o", "IdentitiesOnly=yes",
+				"-i", "~/.ssh/prod",
+				"192.168.5.178", "-N",
+			},
+		},
+		{
+			testName: "Identities only with an IdentityFile option",
+			opts:     []Option{WithIdentitiesOnly(), WithSSHOptions("-o", "IdentityFile=~/.ssh/prod")},
+			target:   defaultTarget,
+			expectedArgs: []string{
+				"ssh", "-L", localSocket + ":/var/run/docker.sock",
+				"-o", "BatchMode=yes", "-o", "ServerAliveInterval=30", "-o", "ServerAliveCountMax=3",
+				"-o", "IdentitiesOnly=yes",
+				"-o", "IdentityFile=~/.ssh/prod",
+				"192.168.5.178", "-N",
+			},
+		},
+		{
+			testName:     "Identities only without an identity file",
+			opts:         []Option{WithIdentitiesOnly()},
+			target:       defaultTarget,
+			expectedArgs: []string{"ssh", "-L", localSocket + ":/var/run/docker.sock", "-o", "BatchMode=yes", "-o", "ServerAliveInterval=30", "-o", "ServerAliveCountMax=3", "192.168.5.178", "-N"},
+		},
+		{
+			testName:     "Identity file without identities only",
+			opts:         []Option{WithSSHOptions("-i", "~/.ssh/prod")},
+			target:       defaultTarget,
+			expectedArgs: []string{"ssh", "-L", localSocket + ":/var/run/docker.sock", "-o", "BatchMode=yes", "-o", "ServerAliveInterval=30", "-o", "ServerAliveCountMax=3", "-i", "~/.ssh/prod", "192.168.5.178", "-N"},
+		},
+		{
+			testName:     "Identities only with a flag value that starts with -i",
+			opts:         []Option{WithIdentitiesOnly(), WithSSHOptions("-l", "-ivan")},
+			target:       defaultTarget,
+			expectedArgs: []string{"ssh", "-L", localSocket + ":/var/run/docker.sock", "-o", "BatchMode=yes", "-o", "ServerAliveInterval=30", "-o", "ServerAliveCountMax=3", "-l", "-ivan", "192.168.5.178", "-N"},
+		},
+		{
+			testName: "Identities only with agent forwarding",
+			opts:     []Option{WithIdentitiesOnly(), WithAgentForwarding(), WithSSHOptions("-i~/.ssh/prod")},
+			target:   defaultTarget,
+			expectedArgs: []string{
+				"ssh", "-L", localSocket + ":/var/run/docker.sock",
+				"-A",
+				"-o", "BatchMode=yes", "-o", "ServerAliveInterval=30", "-o", "ServerAliveCountMax=3",
+				"-o", "IdentitiesOnly=yes",
+				"-i~/.ssh/prod",
+				"192.168.5.178", "-N",
+			},
+		},
+		{
+			testName: "Default keepalive",
+			opts:     nil,
+			target:   defaultTarget,
+			expectedArgs: []string{
+				"ssh", "-L", localSocket + ":/var/run/docker.sock",
+				"-o", "BatchMode=yes",
+				"-o", "ServerAliveInterval=30",
+				"-o", "ServerAliveCountMax=3",
+				"192.168.5.178", "-N",
+			},
+		},
+		{
+			testName: "Custom keepalive",
+			opts:     []Option{WithKeepAlive(15*time.Second, 8)},
+			target:   defaultTarget,
+			expectedArgs: []string{
+				"ssh", "-L", localSocket + ":/var/run/docker.sock",
+				"-o", "BatchMode=yes",
+				"-o", "ServerAliveInterval=15",
+				"-o", "ServerAliveCountMax=8",
+				"192.168.5.178", "-N",
+			},
+		},
+		{
+			testName: "Sub-second keepalive rounds up to a second",
+			opts:     []Option{WithKeepAlive(100*time.Millisecond, 0)},
+			target:   defaultTarget,
+			expectedArgs: []string{
+				"ssh", "-L", localSocket + ":/var/run/docker.sock",
+				"-o", "BatchMode=yes",
+				"-o", "ServerAliveInterval=1",
+				"-o", "ServerAliveCountMax=3",
+				"192.168.5.178", "-N",
+			},
+		},
+		{
+			testName:     "Keepalive off",
+			opts:         []Option{WithKeepAlive(-1, 0)},
+			target:       defaultTarget,
+			expectedArgs: []string{"ssh", "-L", localSocket + ":/var/run/docker.sock", "-o", "BatchMode=yes", "192.168.5.178", "-N"},
+		},
+		{
+			testName:     "Compression",
+			opts:         []Option{WithCompression()},
+			target:       defaultTarget,
+			expectedArgs: []string{"ssh", "-L", localSocket + ":/var/run/docker.sock", "-C", "-o", "BatchMode=yes", "-o", "ServerAliveInterval=30", "-o", "ServerAliveCountMax=3", "192.168.5.178", "-N"},
+		},
+		{
+			testName:     "Agent forwarding and compression",
+			opts:         []Option{WithAgentForwarding(), WithCompression()},
+			target:       defaultTarget,
+			expectedArgs: []string{"ssh", "-L", localSocket + ":/var/run/docker.sock", "-A", "-C", "-o", "BatchMode=yes", "-o", "ServerAliveInterval=30", "-o", "ServerAliveCountMax=3", "192.168.5.178", "-N"},
+		},
+		{
+			testName:     "Single jump host",
+			target:       tunnelTarget{host: "192.168.5.178", remoteSocket: defaultRemoteSocket, jumpHosts: "bastion"},
+			expectedArgs: []string{"ssh", "-L", localSocket + ":/var/run/docker.sock", "-J", "bastion", "-o", "BatchMode=yes", "-o", "ServerAliveInterval=30", "-o", "ServerAliveCountMax=3", "192.168.5.178", "-N"},
+		},
+		{
+			testName:     "Multiple jump hosts",
+			target:       tunnelTarget{host: "192.168.5.178", remoteSocket: defaultRemoteSocket, jumpHosts: "user@bastion1,bastion2:2222"},
+			expectedArgs: []string{"ssh", "-L", localSocket + ":/var/run/docker.sock", "-J", "user@bastion1,bastion2:2222", "-o", "BatchMode=yes", "-o", "ServerAliveInterval=30", "-o", "ServerAliveCountMax=3", "192.168.5.178", "-N"},
+		},
+		{
+			testName: "Extra ssh options",
+			opts:     []Option{WithSSHOptions("-o", "StrictHostKeyChecking=accept-new", "-i", "~/.ssh/id_ed25519")},
+			target:   defaultTarget,
+			expectedArgs: []string{
+				"ssh", "-L", localSocket + ":/var/run/docker.sock",
+				"-o", "BatchMode=yes", "-o", "ServerAliveInterval=30", "-o", "ServerAliveCountMax=3",
+				"-o", "StrictHostKeyChecking=accept-new", "-i", "~/.ssh/id_ed25519",
+				"192.168.5.178", "-N",
+			},
+		},
+		{
+			testName: "Host key checking",
+			opts:     []Option{WithHostKeyChecking("accept-new")},
+			target:   defaultTarget,
+			expectedArgs: []string{
+				"ssh", "-L", localSocket + ":/var/run/docker.sock",
+				"-o", "BatchMode=yes", "-o", "ServerAliveInterval=30", "-o", "ServerAliveCountMax=3",
+				"-o", "StrictHostKeyChecking=accept-new",
+				"192.168.5.178", "-N",
+			},
+		},
+		{
+			testName: "Extra ssh options after port and control master",
+			opts:     []Option{WithSSHOptions("-J", "jumphost"), WithControlMaster()},
+			target:   tunnelTarget{host: "192.168.5.178", port: "2222", remoteSocket: defaultRemoteSocket},
+			expectedArgs: []string{
+				"ssh", "-L", localSocket + ":/var/run/docker.sock",
+				"-p", "2222",
+				"-o", "BatchMode=yes", "-o", "ServerAliveInterval=30", "-o", "ServerAliveCountMax=3",
+				"-o", "ControlMaster=auto",
+				"-o", "ControlPath=" + filepath.Join("/tmp/lazydocker-ssh-tunnel-12345", "cm.sock"),
+				"-o", "ControlPersist=60s",
+				"-J", "jumphost",
+				"192.168.5.178", "-N",
+			},
+		},
+	}
+
+	for _, s := range scenarios {
+		s := s
+		t.Run(s.testName, func(t *testing.T) {
+			handler := NewSSHHandler(s.opts...)
+			handler.deps.startCmd = func(cmd *exec.Cmd) error { return nil }
+
+			cmd, err := handler.tunnelSSH(s.target, unixEndpoint(localSocket), ioutil.Discard)
+			assert.NoError(t, err)
+			assert.EqualValues(t, s.expectedArgs, cmd.Args)
+		})
+	}
+}
+
+func TestHasIdentityFile(t *testing.T) {
+	type scenario struct {
+		options  []string
+		expected bool
+	}
+
+	scenarios := []scenario{
+		{options: nil, expected: false},
+		{options: []string{"-i", "~/.ssh/prod"}, expected: true},
+		{options: []string{"-i~/.ssh/prod"}, expected: true},
+		{options: []string{"-o", "IdentityFile=~/.ssh/prod"}, expected: true},
+		{options: []string{"-oIdentityFile=~/.ssh/prod"}, expected: true},
+		{options: []string{"-l", "-ivan"}, expected: false},
+		{options: []string{"-o", "User=ivan", "-A"}, expected: false},
+		{options: []string{"-i"}, expected: false},
+	}
+
+	for _, s := range scenarios {
+		assert.Equal(t, s.expected, hasIdentityFile(s.options), "%v", s.options)
+	}
+}
+
+func TestSSHHandlerStderrInTunnelError(t *testing.T) {
+	handler := &SSHHandler{
+		deps: dependencies{
+			now:    time.Now,
+			after:  time.After,
+			remove: func(path string) error { return os.ErrNotExist },
+			dialContext: func(ctx context.Context, network string, address string) (io.Closer, error) {
+				return nil, errors.New("connection refused")
+			},
+			startCmd: func(cmd *exec.Cmd) error {
+				_, err := cmd.Stderr.Write([]byte("myhost@192.168.5.178: Permission denied (publickey).\n"))
+				return err
+			},
+			tempDir:   func(dir string, pattern string) (string, error) { return "/tmp/lazydocker-ssh-tunnel-12345", nil },
+			tempRoot:  func() string { return "/tmp" },
+			removeAll: func(path string) error { return nil },
+			sshConfig: noSSHConfig,
+		},
+		tunnelTimeout: 10 * time.Millisecond,
+	}
+
+	_, err := handler.createDockerHostTunnel(context.Background(), tunnelTarget{host: "192.168.5.178", remoteSocket: defaultRemoteSocket}, BackendExec)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "Permission denied (publickey).")
+	assert.Contains(t, err.Error(), "interactive authentication isn't supported")
+	assert.True(t, errors.Is(err, ErrTunnelTimeout))
+	assert.True(t, errors.Is(err, ErrAuthFailed))
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+
+	var tunnelErr *TunnelError
+	if assert.True(t, errors.As(err, &tunnelErr)) {
+		assert.Equal(t, "192.168.5.178", tunnelErr.Host)
+		assert.Equal(t, "myhost@192.168.5.178: Permission denied (publickey).", tunnelErr.Stderr)
+	}
+	assert.Contains(t, err.Error(), "10ms")
+}
+
+func TestSSHHandlerRetrySocketDial(t *testing.T) {
+	type scenario struct {
+		testName         string
+		failedDials      int
+		timeout          time.Duration
+		maxDialAttempts  int
+		expectedAttempts int
+		// expectedErr is the error we expect to give up with, or nil if we
+		// expect the socket to become available
+		expectedErr error
+	}
+
+	scenarios := []scenario{
+		{
+			testName:         "Available straight away",
+			failedDials:      0,
+			timeout:          time.Second,
+			expectedAttempts: 1,
+			expectedErr:      nil,
+		},
+		{
+			testName:         "Available after backing off",
+			failedDials:      9,
+			timeout:          time.Second,
+			expectedAttempts: 10,
+			expectedErr:      nil,
+		},
+		{
+			testName:    "Never available",
+			failedDials: 1000000,
+			timeout:     20 * time.Millisecond,
+			expectedErr: context.DeadlineExceeded,
+		},
+		{
+			testName:         "Available before running out of attempts",
+			failedDials:      2,
+			timeout:          time.Second,
+			maxDialAttempts:  3,
+			expectedAttempts: 3,
+			expectedErr:      nil,
+		},
+		{
+			testName:         "Out of attempts",
+			failedDials:      1000000,
+			timeout:          time.Minute,
+			maxDialAttempts:  3,
+			expectedAttempts: 3,
+			expectedErr:      ErrDialAttemptsExhausted,
+		},
+		{
+			testName:        "Deadline before running out of attempts",
+			failedDials:     1000000,
+			timeout:         20 * time.Millisecond,
+			maxDialAttempts: 1000000,
+			expectedErr:     context.DeadlineExceeded,
+		},
+	}
+
+	for _, s := range scenarios {
+		s := s
+		t.Run(s.testName, func(t *testing.T) {
+			attempts := 0
+			handler := &SSHHandler{
+				deps: dependencies{
+					now:    time.Now,
+					after:  time.After,
+					remove: func(path string) error { return os.ErrNotExist },
+					dialContext: func(ctx context.Context, network string, address string) (io.Closer, error) {
+						attempts++
+						if attempts <= s.failedDials {
+							return nil, errors.New("connection refused")
+						}
+						return noopCloser{}, nil
+					},
+					initialDialInterval: time.Millisecond,
+					maxDialInterval:     2 * time.Millisecond,
+				},
+				maxDialAttempts: s.maxDialAttempts,
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+			defer cancel()
+
+			reportedAttempts, err := handler.retrySocketDial(ctx, unixEndpoint("/tmp/lazydocker-ssh-tunnel-12345/dockerhost.sock"))
+			assert.Equal(t, attempts, reportedAttempts)
+			if s.expectedErr != nil {
+				assert.True(t, errors.Is(err, s.expectedErr), "unexpected error: %v", err)
+				if s.expectedAttempts != 0 {
+					assert.Equal(t, s.expectedAttempts, attempts)
+				}
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, s.expectedAttempts, attempts)
+		})
+	}
+}
+
+func TestNextDialInterval(t *testing.T) {
+	interval := defaultInitialDialInterval
+	intervals := []time.Duration{}
+	for i := 0; i < 6; i++ {
+		intervals = append(intervals, interval)
+		interval = nextDialInterval(interval, defaultMaxDialInterval)
+	}
+
+	assert.EqualValues(t, []time.Duration{
+		100 * time.Millisecond,
+		200 * time.Millisecond,
+		400 * time.Millisecond,
+		800 * time.Millisecond,
+		time.Second,
+		time.Second,
+	}, intervals)
+}
+
+func TestTailBuffer(t *testing.T) {
+	buffer := newTailBuffer(8)
+	_, _ = buffer.Write([]byte("first line\n"))
+	_, _ = buffer.Write([]byte("second\n"))
+	assert.Equal(t, "second", buffer.String())
+}
+
+func TestSSHHandlerTunnelTimeout(t *testing.T) {
+	type scenario struct {
+		testName string
+		opts     []Option
+		expected time.Duration
+	}
+
+	scenarios := []scenario{
+		{
+			testName: "No timeout configured",
+			opts:     nil,
+			expected: 8 * time.Second,
+		},
+		{
+			testName: "Zero timeout falls back to default",
+			opts:     []Option{WithTunnelTimeout(0)},
+			expected: 8 * time.Second,
+		},
+		{
+			testName: "Custom timeout",
+			opts:     []Option{WithTunnelTimeout(30 * time.Second)},
+			expected: 30 * time.Second,
+		},
+	}
+
+	for _, s := range scenarios {
+		s := s
+		t.Run(s.testName, func(t *testing.T) {
+			handler := NewSSHHandler(s.opts...)
+			assert.Equal(t, s.expected, handler.getTunnelTimeout())
+		})
+	}
+}
+
+func TestSSHHandlerReconnectTunnel(t *testing.T) {
+	removedPaths := []string{}
+	startedCmds := []*exec.Cmd{}
+	handler := NewSSHHandler(WithAutoReconnect(3))
+	handler.deps = dependencies{
+		now:   time.Now,
+		after: time.After,
+		dialContext: func(ctx context.Context, network string, address string) (io.Closer, error) {
+			assert.Equal(t, "unix", network)
+			assert.Equal(t, "/tmp/lazydocker-ssh-tunnel-12345/dockerhost.sock", address)
+			return ioutil.NopCloser(nil), nil
+		},
+		startCmd: func(cmd *exec.Cmd) error {
+			startedCmds = append(startedCmds, cmd)
+			return nil
+		},
+		remove: func(path string) error {
+			removedPaths = append(removedPaths, path)
+			return nil
+		},
+	}
+
+	process, err := handler.reconnectTunnel(context.Background(), BackendExec, tunnelTarget{host: "192.168.5.178", remoteSocket: defaultRemoteSocket}, unixEndpoint("/tmp/lazydocker-ssh-tunnel-12345/dockerhost.sock"))
+	assert.NoError(t, err)
+	assert.EqualValues(t, []string{"/tmp/lazydocker-ssh-tunnel-12345/dockerhost.sock"}, removedPaths)
+	assert.Len(t, startedCmds, 1)
+	if assert.IsType(t, &execProcess{}, process) {
+		assert.Equal(t, startedCmds[0], process.(*execProcess).cmd)
+	}
+	assert.EqualValues(t, []string{"ssh", "-L", "/tmp/lazydocker-ssh-tunnel-12345/dockerhost.sock:/var/run/docker.sock", "-o", "BatchMode=yes", "-o", "ServerAliveInterval=30", "-o", "ServerAliveCountMax=3", "192.168.5.178", "-N"}, startedCmds[0].Args)
+}
+
+func TestSocketFileNameFor(t *testing.T) {
+	type scenario struct {
+		testName     string
+		target       tunnelTarget
+		expectedName string
+	}
+
+	scenarios := []scenario{
+		{
+			testName:     "IPv4 host",
+			target:       tunnelTarget{host: "192.168.5.178"},
+			expectedName: "192.168.5.178.sock",
+		},
+		{
+			testName:     "Hostname with default port",
+			target:       tunnelTarget{host: "myhost.example.com", port: "22"},
+			expectedName: "myhost.example.com.sock",
+		},
+		{
+			testName:     "IPv6 host with custom port",
+			target:       tunnelTarget{host: "fe80::1", port: "2222"},
+			expectedName: "fe80__1-2222.sock",
+		},
+		{
+			testName:     "Long hostname",
+			target:       tunnelTarget{host: "a-very-long-hostname.eu-west-1.compute.internal.example.com"},
+			expectedName: "a-very-long-hostname.eu-west-1.compute.internal.sock",
+		},
+		{
+			testName:     "Nothing usable",
+			target:       tunnelTarget{host: ""},
+			expectedName: "dockerhost.sock",
+		},
+	}
+
+	for _, s := range scenarios {
+		s := s
+		t.Run(s.testName, func(t *testing.T) {
+			assert.Equal(t, s.expectedName, socketFileNameFor(s.target))
+		})
+	}
+}
+
+func TestSSHHandlerOpenTunnel(t *testing.T) {
+	type scenario struct {
+		testName            string
+		sshURL              string
+		expectedSocketPath  string
+		expectedErrorSubstr string
+	}
+
+	scenarios := []scenario{
+		{
+			testName:           "ssh url",
+			sshURL:             "ssh://myhost@192.168.5.178",
+			expectedSocketPath: "unix:///tmp/lazydocker-ssh-tunnel-12345/192.168.5.178.sock",
+		},
+		{
+			testName:           "Aliased ssh url",
+			sshURL:             "Docker+SSH://myhost@192.168.5.178",
+			expectedSocketPath: "unix:///tmp/lazydocker-ssh-tunnel-12345/192.168.5.178.sock",
+		},
+		{
+			testName:            "Non-ssh url",
+			sshURL:              "tcp://192.168.5.178:2375",
+			expectedErrorSubstr: "expected an ssh:// docker host",
+		},
+		{
+			testName:            "Invalid url",
+			sshURL:              "ssh://192.168.5.178:port",
+			expectedErrorSubstr: "parse ssh docker host",
+		},
+	}
+
+	for _, s := range scenarios {
+		s := s
+		t.Run(s.testName, func(t *testing.T) {
+			// no getenv or setenv: opening a tunnel mustn't touch the environment
+			handler := &SSHHandler{
+				deps: dependencies{
+					now:    time.Now,
+					after:  time.After,
+					remove: func(path string) error { return os.ErrNotExist },
+					dialContext: func(ctx context.Context, network string, address string) (io.Closer, error) {
+						return noopCloser{}, nil
+					},
+					startCmd:  func(cmd *exec.Cmd) error { return nil },
+					tempDir:   func(dir string, pattern string) (string, error) { return "/tmp/lazydocker-ssh-tunnel-12345", nil },
+					tempRoot:  func() string { return "/tmp" },
+					lookPath:  func(file string) (string, error) { return "/usr/bin/" + file, nil },
+					sshConfig: noSSHConfig,
+				},
+			}
+
+			tunnel, err := handler.OpenTunnel(context.Background(), s.sshURL)
+			if s.expectedErrorSubstr != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), s.expectedErrorSubstr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, s.expectedSocketPath, tunnel.SocketPath())
+		})
+	}
+}
+
+func TestSSHHandlerHandleSSHDockerHostCancelled(t *testing.T) {
+	handler := &SSHHandler{
+		deps: dependencies{
+			now:    time.Now,
+			after:  time.After,
+			remove: func(path string) error { return os.ErrNotExist },
+			dialContext: func(ctx context.Context, network string, address string) (io.Closer, error) {
+				return nil, errors.New("connection refused")
+			},
+			startCmd:  func(cmd *exec.Cmd) error { return nil },
+			tempDir:   func(dir string, pattern string) (string, error) { return "/tmp/lazydocker-ssh-tunnel-12345", nil },
+			tempRoot:  func() string { return "/tmp" },
+			removeAll: func(path string) error { return nil },
+			getenv:    dockerHostEnv("ssh://myhost@192.168.5.178"),
+			lookPath:  func(file string) (string, error) { return "/usr/bin/" + file, nil },
+			sshConfig: noSSHConfig,
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := handler.HandleSSHDockerHost(ctx)
+	assert.Error(t, err)
+	assert.True(t, errors.Is(err, context.Canceled))
+	assert.False(t, errors.Is(err, ErrTunnelTimeout))
+}
+
+type recordingLogger struct {
+	mutex    sync.Mutex
+	messages []string
+}
+
+func (l *recordingLogger) Debugf(format string, args ...interface{}) {
+	l.record("debug: "+format, args...)
+}
+
+func (l *recordingLogger) Warnf(format string, args ...interface{}) {
+	l.record("warn: "+format, args...)
+}
+
+func (l *recordingLogger) record(format string, args ...interface{}) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.messages = append(l.messages, fmt.Sprintf(format, args...))
+}
+
+func TestSSHHandlerLogsTunnelSetup(t *testing.T) {
+	log := &recordingLogger{}
+	dialCount := 0
+	handler := NewSSHHandler(WithLogger(log))
+	handler.deps.dialContext = func(ctx context.Context, network string, address string) (io.Closer, error) {
+		dialCount++
+		if dialCount == 1 {
+			return nil, errors.New("connection refused")
+		}
+		return noopCloser{}, nil
+	}
+	handler.deps.startCmd = func(cmd *exec.Cmd) error { return nil }
+	handler.deps.tempDir = func(dir string, pattern string) (string, error) { return "/tmp/lazydocker-ssh-tunnel-12345", nil }
+	handler.deps.tempRoot = func() string { return "/tmp" }
+
+	_, err := handler.createDockerHostTunnel(context.Background(), tunnelTarget{host: "192.168.5.178", remoteSocket: defaultRemoteSocket}, BackendExec)
+	assert.NoError(t, err)
+
+	log.mutex.Lock()
+	defer log.mutex.Unlock()
+	assert.Contains(t, log.messages, "debug: starting exec ssh tunnel to 192.168.5.178, forwarding /tmp/lazydocker-ssh-tunnel-12345/192.168.5.178.sock to /var/run/docker.sock")
+	assert.Contains(t, log.messages, "debug: dial attempt 1 to /tmp/lazydocker-ssh-tunnel-12345/192.168.5.178.sock failed: connection refused")
+	assert.Contains(t, log.messages, "debug: tunneled socket /tmp/lazydocker-ssh-tunnel-12345/192.168.5.178.sock became available after 2 attempt(s)")
+}
+
+func TestSSHHandlerHandleSSHDockerHostMissingHost(t *testing.T) {
+	type scenario struct {
+		testName    string
+		envVarValue string
+		expectError bool
+	}
+
+	scenarios := []scenario{
+		{
+			testName:    "No host",
+			envVarValue: "ssh://",
+			expectError: true,
+		},
+		{
+			testName:    "User but no host",
+			envVarValue: "ssh://myhost@",
+			expectError: true,
+		},
+		{
+			testName:    "Port but no host",
+			envVarValue: "ssh://:2222",
+			expectError: true,
+		},
+		{
+			testName:    "Host only",
+			envVarValue: "ssh://192.168.5.178",
+			expectError: false,
+		},
+	}
+
+	for _, s := range scenarios {
+		s := s
+		t.Run(s.testName, func(t *testing.T) {
+			startCmdCount := 0
+			handler := &SSHHandler{
+				deps: dependencies{
+					now:    time.Now,
+					after:  time.After,
+					remove: func(path string) error { return os.ErrNotExist },
+					dialContext: func(ctx context.Context, network string, address string) (io.Closer, error) {
+						return noopCloser{}, nil
+					},
+					startCmd: func(cmd *exec.Cmd) error {
+						startCmdCount++
+						return nil
+					},
+					tempDir:   func(dir string, pattern string) (string, error) { return "/tmp/lazydocker-ssh-tunnel-12345", nil },
+					tempRoot:  func() string { return "/tmp" },
+					getenv:    dockerHostEnv(s.envVarValue),
+					setenv:    func(key, value string) error { return nil },
+					lookPath:  func(file string) (string, error) { return "/usr/bin/" + file, nil },
+					sshConfig: noSSHConfig,
+				},
+			}
+
+			_, err := handler.HandleSSHDockerHost(context.Background())
+			if s.expectError {
+				assert.Error(t, err)
+				assert.True(t, errors.Is(err, ErrMissingHost))
+				assert.Equal(t, 0, startCmdCount)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, 1, startCmdCount)
+			}
+		})
+	}
+}
+
+func TestSSHHandlerTryDialPingCheck(t *testing.T) {
+	type scenario struct {
+		testName    string
+		pingCheck   bool
+		response    string
+		expectError bool
+	}
+
+	scenarios := []scenario{
+		{
+			testName:    "No ping check",
+			pingCheck:   false,
+			response:    "",
+			expectError: false,
+		},
+		{
+			testName:    "Daemon answers",
+			pingCheck:   true,
+			response:    "HTTP/1.1 200 OK\r\nContent-Length: 2\r\n\r\nOK",
+			expectError: false,
+		},
+		{
+			testName:    "Daemon errors",
+			pingCheck:   true,
+			response:    "HTTP/1.1 500 Internal Server Error\r\nContent-Length: 0\r\n\r\n",
+			expectError: true,
+		},
+		{
+			testName:    "Nothing behind the socket",
+			pingCheck:   true,
+			response:    "",
+			expectError: true,
+		},
+	}
+
+	for _, s := range scenarios {
+		s := s
+		t.Run(s.testName, func(t *testing.T) {
+			requests := make(chan string, 1)
+			handler := &SSHHandler{
+				pingCheck: s.pingCheck,
+				deps: dependencies{
+					now:    time.Now,
+					after:  time.After,
+					remove: func(path string) error { return os.ErrNotExist },
+					dialContext: func(ctx context.Context, network string, address string) (io.Closer, error) {
+						client, server := net.Pipe()
+						go func() {
+							defer server.Close()
+							req, err := http.ReadRequest(bufio.NewReader(server))
+							if err != nil {
+								return
+							}
+							requests <- req.Method + " " + req.URL.Path
+							_, _ = server.Write([]byte(s.response))
+						}()
+						return client, nil
+					},
+				},
+			}
+
+			err := handler.tryDial(context.Background(), unixEndpoint("/tmp/lazydocker-ssh-tunnel-12345/dockerhost.sock"))
+			if s.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			if s.pingCheck {
+				assert.Equal(t, "GET /_ping", <-requests)
+			}
+		})
+	}
+}
+
+func TestSSHHandlerHandleSSHDockerHostWithoutEnvOverride(t *testing.T) {
+	handler := NewSSHHandler(WithoutEnvOverride())
+	handler.deps.dialContext = func(ctx context.Context, network string, address string) (io.Closer, error) {
+		return noopCloser{}, nil
+	}
+	handler.deps.startCmd = func(cmd *exec.Cmd) error { return nil }
+	handler.deps.tempDir = func(dir string, pattern string) (string, error) { return "/tmp/lazydocker-ssh-tunnel-12345", nil }
+	handler.deps.tempRoot = func() string { return "/tmp" }
+	handler.deps.getenv = dockerHostEnv("ssh://myhost@192.168.5.178")
+	handler.deps.lookPath = func(file string) (string, error) { return "/usr/bin/" + file, nil }
+	handler.deps.sshConfig = noSSHConfig
+	setenvCount := 0
+	handler.deps.setenv = func(key, value string) error {
+		setenvCount++
+		return nil
+	}
+
+	closer, err := handler.HandleSSHDockerHost(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 0, setenvCount)
+
+	tunnel, ok := closer.(interface{ SocketPath() string })
+	if assert.True(t, ok) {
+		assert.Equal(t, "unix:///tmp/lazydocker-ssh-tunnel-12345/192.168.5.178.sock", tunnel.SocketPath())
+	}
+}
+
+func TestSSHHandlerCreateSocketDir(t *testing.T) {
+	type scenario struct {
+		testName            string
+		writableDirs        []string
+		xdgRuntimeDir       string
+		expectedDir         string
+		expectedErrorSubstr string
+	}
+
+	scenarios := []scenario{
+		{
+			testName:     "Temp dir writable",
+			writableDirs: []string{"/tmp", "/run/user/1000", "/home/me/.cache"},
+			expectedDir:  "/tmp/lazydocker-sshtunnel-12345",
+		},
+		{
+			testName:      "Falls back to XDG_RUNTIME_DIR",
+			writableDirs:  []string{"/run/user/1000", "/home/me/.cache"},
+			xdgRuntimeDir: "/run/user/1000",
+			expectedDir:   "/run/user/1000/lazydocker-sshtunnel-12345",
+		},
+		{
+			testName:     "Falls back to the user cache dir",
+			writableDirs: []string{"/home/me/.cache"},
+			expectedDir:  "/home/me/.cache/lazydocker-sshtunnel-12345",
+		},
+		{
+			testName:            "Nothing writable",
+			writableDirs:        []string{},
+			xdgRuntimeDir:       "/run/user/1000",
+			expectedErrorSubstr: "tried /tmp, /run/user/1000, /home/me/.cache",
+		},
+	}
+
+	for _, s := range scenarios {
+		s := s
+		t.Run(s.testName, func(t *testing.T) {
+			handler := NewSSHHandler()
+			handler.deps.tempRoot = func() string { return "/tmp" }
+			handler.deps.tempDir = func(dir string, pattern string) (string, error) {
+				for _, writableDir := range s.writableDirs {
+					if dir == writableDir {
+						return filepath.Join(dir, pattern+"12345"), nil
+					}
+				}
+				return "", os.ErrPermission
+			}
+			handler.deps.getenv = func(key string) string {
+				assert.Equal(t, "XDG_RUNTIME_DIR", key)
+				return s.xdgRuntimeDir
+			}
+			handler.deps.userCacheDir = func() (string, error) { return "/home/me/.cache", nil }
+
+			dir, err := handler.createSocketDir("192.168.5.178.sock")
+			if s.expectedErrorSubstr != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), s.expectedErrorSubstr)
+				assert.True(t, errors.Is(err, os.ErrPermission))
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, s.expectedDir, dir)
+		})
+	}
+}
+
+func TestSSHHandlerTryDialTimeout(t *testing.T) {
+	type scenario struct {
+		testName      string
+		opts          []Option
+		expectedLimit time.Duration
+	}
+
+	scenarios := []scenario{
+		{
+			testName:      "Default dial timeout",
+			opts:          nil,
+			expectedLimit: 1 * time.Second,
+		},
+		{
+			testName:      "Custom dial timeout",
+			opts:          []Option{WithDialTimeout(10 * time.Millisecond)},
+			expectedLimit: 10 * time.Millisecond,
+		},
+	}
+
+	for _, s := range scenarios {
+		s := s
+		t.Run(s.testName, func(t *testing.T) {
+			handler := NewSSHHandler(s.opts...)
+			// a dial that hangs until its context gives up
+			handler.deps.dialContext = func(ctx context.Context, network string, address string) (io.Closer, error) {
+				deadline, ok := ctx.Deadline()
+				assert.True(t, ok, "expected the dial to have a deadline")
+				assert.True(t, time.Until(deadline) <= s.expectedLimit)
+				<-ctx.Done()
+				return nil, ctx.Err()
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			err := handler.tryDial(ctx, unixEndpoint("/tmp/lazydocker-ssh-tunnel-12345/dockerhost.sock"))
+			assert.True(t, errors.Is(err, context.DeadlineExceeded))
+			assert.NoError(t, ctx.Err(), "parent context shouldn't be affected by a single attempt timing out")
+		})
+	}
+}
+
+func TestSSHHandlerRetrySocketDialAfterHungAttempt(t *testing.T) {
+	handler := NewSSHHandler(WithDialTimeout(10 * time.Millisecond))
+	handler.deps.initialDialInterval = time.Millisecond
+	handler.deps.maxDialInterval = time.Millisecond
+
+	attempts := 0
+	handler.deps.dialContext = func(ctx context.Context, network string, address string) (io.Closer, error) {
+		attempts++
+		if attempts == 1 {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+		return ioutil.NopCloser(nil), nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	_, err := handler.retrySocketDial(ctx, unixEndpoint("/tmp/lazydocker-ssh-tunnel-12345/dockerhost.sock"))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, attempts)
+}
+
+func noSSHConfig(alias, key string) []string {
+	return nil
+}
+
+func TestSSHHandlerWaitForSocketAttemptsExhausted(t *testing.T) {
+	handler := NewSSHHandler(WithMaxDialAttempts(2), WithTunnelTimeout(time.Minute))
+	handler.deps.initialDialInterval = time.Millisecond
+	handler.deps.sshConfig = noSSHConfig
+	handler.deps.dialContext = func(ctx context.Context, network string, address string) (io.Closer, error) {
+		return nil, errors.New("connection refused")
+	}
+
+	_, err := handler.waitForSocket(context.Background(), tunnelTarget{host: "192.168.5.178"}, unixEndpoint("/tmp/lazydocker-ssh-tunnel-12345/192.168.5.178.sock"), newTailBuffer(maxStderrTailLength))
+	assert.Error(t, err)
+	assert.True(t, errors.Is(err, ErrDialAttemptsExhausted))
+	assert.False(t, errors.Is(err, ErrTunnelTimeout))
+	assert.False(t, errors.Is(err, context.DeadlineExceeded))
+	assert.Contains(t, err.Error(), "gave up after 2 attempt(s): connection refused")
+}
+
+func TestSSHHandlerRetrySocketDialProgress(t *testing.T) {
+	attempts := []int{}
+	remainings := []time.Duration{}
+	handler := NewSSHHandler(WithProgress(func(attempt int, remaining time.Duration) {
+		attempts = append(attempts, attempt)
+		remainings = append(remainings, remaining)
+	}))
+	handler.deps.initialDialInterval = time.Millisecond
+	handler.deps.maxDialInterval = time.Millisecond
+
+	dials := 0
+	handler.deps.dialContext = func(ctx context.Context, network string, address string) (io.Closer, error) {
+		dials++
+		if dials < 3 {
+			return nil, errors.New("connection refused")
+		}
+		return noopCloser{}, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 8*time.Second)
+	defer cancel()
+
+	_, err := handler.retrySocketDial(ctx, unixEndpoint("/tmp/lazydocker-ssh-tunnel-12345/dockerhost.sock"))
+	assert.NoError(t, err)
+	assert.EqualValues(t, []int{1, 2, 3}, attempts)
+	for i, remaining := range remainings {
+		assert.True(t, remaining > 0 && remaining <= 8*time.Second, "unexpected remaining time %s", remaining)
+		if i > 0 {
+			assert.True(t, remaining <= remainings[i-1], "remaining time went up")
+		}
+	}
+}
+
+func TestSSHHandlerRetrySocketDialProgressWithoutDeadline(t *testing.T) {
+	remainings := []time.Duration{}
+	handler := NewSSHHandler(WithProgress(func(attempt int, remaining time.Duration) {
+		remainings = append(remainings, remaining)
+	}))
+	handler.deps.initialDialInterval = time.Millisecond
+	handler.deps.dialContext = func(ctx context.Context, network string, address string) (io.Closer, error) {
+		return noopCloser{}, nil
+	}
+
+	_, err := handler.retrySocketDial(context.Background(), unixEndpoint("/tmp/lazydocker-ssh-tunnel-12345/dockerhost.sock"))
+	assert.NoError(t, err)
+	assert.EqualValues(t, []time.Duration{0}, remainings)
+}
+
+// dockerHostEnv is a getenv with only DOCKER_HOST set
+func dockerHostEnv(dockerHost string) func(key string) string {
+	return func(key string) string {
+		if key == "DOCKER_HOST" {
+			return dockerHost
+		}
+		return ""
+	}
+}
+
+func TestSSHHandlerHandleSSHDockerHostTunnelingDisabled(t *testing.T) {
+	type scenario struct {
+		testName       string
+		disableValue   string
+		opts           []Option
+		expectTunnel   bool
+		expectUnsetenv bool
+	}
+
+	scenarios := []scenario{
+		{
+			testName:       "Not set",
+			disableValue:   "",
+			expectTunnel:   true,
+			expectUnsetenv: false,
+		},
+		{
+			testName:       "Set to a false value",
+			disableValue:   "false",
+			expectTunnel:   true,
+			expectUnsetenv: false,
+		},
+		{
+			testName:       "Set to 1",
+			disableValue:   "1",
+			expectTunnel:   false,
+			expectUnsetenv: true,
+		},
+		{
+			testName:       "Set to something other than a boolean",
+			disableValue:   "yes",
+			expectTunnel:   false,
+			expectUnsetenv: true,
+		},
+		{
+			testName:       "Set without env override",
+			disableValue:   "true",
+			opts:           []Option{WithoutEnvOverride()},
+			expectTunnel:   false,
+			expectUnsetenv: false,
+		},
+	}
+
+	for _, s := range scenarios {
+		s := s
+		t.Run(s.testName, func(t *testing.T) {
+			handler := NewSSHHandler(s.opts...)
+			handler.deps.initialDialInterval = time.Millisecond
+			handler.deps.getenv = func(key string) string {
+				switch key {
+				case "DOCKER_HOST":
+					return "ssh://myhost@192.168.5.178"
+				case disableTunnelEnvVar:
+					return s.disableValue
+				}
+				return ""
+			}
+			handler.deps.lookPath = func(file string) (string, error) { return "/usr/bin/" + file, nil }
+			handler.deps.sshConfig = noSSHConfig
+			handler.deps.tempDir = func(dir string, pattern string) (string, error) { return "/tmp/lazydocker-ssh-tunnel-12345", nil }
+			handler.deps.dialContext = func(ctx context.Context, network string, address string) (io.Closer, error) {
+				return noopCloser{}, nil
+			}
+			handler.deps.setenv = func(key, value string) error { return nil }
+
+			startCmdCount := 0
+			handler.deps.startCmd = func(cmd *exec.Cmd) error {
+				startCmdCount++
+				return nil
+			}
+			unsetKeys := []string{}
+			handler.deps.unsetenv = func(key string) error {
+				unsetKeys = append(unsetKeys, key)
+				return nil
+			}
+
+			closer, err := handler.HandleSSHDockerHost(context.Background())
+			assert.NoError(t, err)
+
+			if s.expectTunnel {
+				assert.Equal(t, 1, startCmdCount)
+				assert.IsType(t, &tunnelRef{}, closer)
+			} else {
+				assert.Equal(t, 0, startCmdCount)
+				assert.Equal(t, noopCloser{}, closer)
+			}
+
+			if s.expectUnsetenv {
+				assert.EqualValues(t, []string{"DOCKER_HOST"}, unsetKeys)
+			} else {
+				assert.Len(t, unsetKeys, 0)
+			}
+		})
+	}
+}
+
+func TestSSHHandlerTunnelStats(t *testing.T) {
+	handler := NewSSHHandler(WithBackend(BackendExec))
+	handler.deps.initialDialInterval = time.Millisecond
+	handler.deps.maxDialInterval = time.Millisecond
+	handler.deps.lookPath = func(file string) (string, error) { return "/usr/bin/" + file, nil }
+	handler.deps.sshConfig = noSSHConfig
+	handler.deps.startCmd = func(cmd *exec.Cmd) error { return nil }
+	handler.deps.tempDir = func(dir string, pattern string) (string, error) { return "/tmp/lazydocker-ssh-tunnel-12345", nil }
+
+	dials := 0
+	handler.deps.dialContext = func(ctx context.Context, network string, address string) (io.Closer, error) {
+		dials++
+		if dials < 3 {
+			return nil, errors.New("connection refused")
+		}
+		return noopCloser{}, nil
+	}
+
+	before := time.Now()
+	tunnel, err := handler.OpenTunnel(context.Background(), "ssh://myhost@192.168.5.178")
+	elapsed := time.Since(before)
+	assert.NoError(t, err)
+
+	stats := tunnel.Stats()
+	assert.Equal(t, 3, stats.DialAttempts)
+	assert.True(t, stats.SetupDuration > 0 && stats.SetupDuration <= elapsed, "unexpected setup duration %s", stats.SetupDuration)
+}
+
+func TestIsSSHScheme(t *testing.T) {
+	type scenario struct {
+		scheme   string
+		expected bool
+	}
+
+	scenarios := []scenario{
+		{scheme: "ssh", expected: true},
+		{scheme: "SSH", expected: true},
+		{scheme: "ssh+docker", expected: true},
+		{scheme: "Docker+SSH", expected: true},
+		{scheme: "ssh+git", expected: false},
+		{scheme: "tcp", expected: false},
+		{scheme: "unix", expected: false},
+		{scheme: "", expected: false},
+	}
+
+	for _, s := range scenarios {
+		s := s
+		t.Run(s.scheme, func(t *testing.T) {
+			assert.Equal(t, s.expected, isSSHScheme(s.scheme))
+		})
+	}
+}
+
+func TestSSHHandlerRemovesStaleSocket(t *testing.T) {
+	type scenario struct {
+		testName            string
+		staleFile           bool
+		removeErr           error
+		expectedErrorSubstr string
+	}
+
+	scenarios := []scenario{
+		{
+			testName:  "Nothing at the socket path",
+			staleFile: false,
+		},
+		{
+			testName:  "Stale socket left behind",
+			staleFile: true,
+		},
+		{
+			testName:            "Stale socket can't be removed",
+			staleFile:           true,
+			removeErr:           os.ErrPermission,
+			expectedErrorSubstr: "remove stale tunneled socket",
+		},
+	}
+
+	for _, s := range scenarios {
+		s := s
+		t.Run(s.testName, func(t *testing.T) {
+			socketDir, err := ioutil.TempDir("", "lazydocker-stale-socket-test")
+			assert.NoError(t, err)
+			defer os.RemoveAll(socketDir)
+
+			localSocket := filepath.Join(socketDir, "192.168.5.178.sock")
+			if s.staleFile {
+				assert.NoError(t, ioutil.WriteFile(localSocket, []byte{}, 0600))
+			}
+
+			handler := NewSSHHandler(WithBackend(BackendExec))
+			handler.deps.initialDialInterval = time.Millisecond
+			handler.deps.lookPath = func(file string) (string, error) { return "/usr/bin/" + file, nil }
+			handler.deps.sshConfig = noSSHConfig
+			handler.deps.tempDir = func(dir string, pattern string) (string, error) { return socketDir, nil }
+			handler.deps.removeAll = func(path string) error { return nil }
+			if s.removeErr != nil {
+				handler.deps.remove = func(path string) error { return s.removeErr }
+			}
+			handler.deps.dialContext = func(ctx context.Context, network string, address string) (io.Closer, error) {
+				return noopCloser{}, nil
+			}
+
+			started := false
+			handler.deps.startCmd = func(cmd *exec.Cmd) error {
+				started = true
+				_, err := os.Stat(localSocket)
+				assert.True(t, os.IsNotExist(err), "expected the stale socket to be gone before starting ssh")
+				return nil
+			}
+
+			_, err = handler.OpenTunnel(context.Background(), "ssh://myhost@192.168.5.178")
+			if s.expectedErrorSubstr != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), s.expectedErrorSubstr)
+				assert.True(t, errors.Is(err, os.ErrPermission))
+				assert.False(t, started)
+				return
+			}
+			assert.NoError(t, err)
+			assert.True(t, started)
+		})
+	}
+}
+
+func TestSSHHandlerHandleSSHDockerHostWithoutTunnel(t *testing.T) {
+	handler := NewSSHHandler()
+	handler.deps.getenv = dockerHostEnv("unix:///var/run/docker.sock")
+
+	tunnel, err := handler.HandleSSHDockerHost(context.Background())
+	assert.NoError(t, err)
+
+	assert.Equal(t, "", tunnel.SocketPath())
+	assert.Equal(t, TunnelStats{}, tunnel.Stats())
+	select {
+	case <-tunnel.Done():
+		t.Fatal("expected a tunnel that isn't there to never go down")
+	default:
+	}
+	assert.NoError(t, tunnel.Close())
+}
@@ -0,0 +1,38 @@
+//go:build !windows
+// +build !windows
+
+package ssh
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// shortTempRoot is where we put the tunnel socket when the OS temp dir would
+// make the socket path too long
+const shortTempRoot = "/tmp"
+
+// prepareTunnelProcess gives the ssh process its own process group so that
+// we can kill it along with any children it spawns (e.g. ProxyCommand helpers)
+func prepareTunnelProcess(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// terminateTunnelProcess asks the ssh process group to exit. The minus sign
+// means we're talking about a PGID as opposed to a PID.
+func terminateTunnelProcess(cmd *exec.Cmd, deps dependencies) error {
+	return deps.terminateProcessGroup(-cmd.Process.Pid)
+}
+
+// killTunnelProcess kills the ssh process group
+func killTunnelProcess(cmd *exec.Cmd, deps dependencies) error {
+	return deps.killProcessGroup(-cmd.Process.Pid)
+}
+
+func terminateProcessGroup(pid int) error {
+	return syscall.Kill(pid, syscall.SIGTERM)
+}
+
+func killProcessGroup(pid int) error {
+	return syscall.Kill(pid, syscall.SIGKILL)
+}
@@ -0,0 +1,657 @@
+//go:build !windows
+// +build !windows
+
+package ssh
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func assertTunnelProcessPrepared(t *testing.T, cmd *exec.Cmd) {
+	assert.Equal(t, true, cmd.SysProcAttr.Setpgid)
+}
+
+func TestTunneledDockerHostClose(t *testing.T) {
+	type scenario struct {
+		testName        string
+		command         []string
+		waitFirst       bool
+		failTerminate   bool
+		expectedSignals []string
+		expectError     bool
+	}
+
+	scenarios := []scenario{
+		{
+			testName:        "Running process",
+			command:         []string{"sleep", "30"},
+			waitFirst:       false,
+			expectedSignals: []string{"TERM"},
+			expectError:     false,
+		},
+		{
+			testName:        "Terminating fails",
+			command:         []string{"sleep", "30"},
+			waitFirst:       false,
+			failTerminate:   true,
+			expectedSignals: []string{"TERM", "KILL"},
+			expectError:     false,
+		},
+		{
+			testName:        "Process already gone",
+			command:         []string{"true"},
+			waitFirst:       true,
+			expectedSignals: []string{"TERM", "KILL"},
+			expectError:     true,
+		},
+	}
+
+	for _, s := range scenarios {
+		s := s
+		t.Run(s.testName, func(t *testing.T) {
+			cmd := exec.Command(s.command[0], s.command[1:]...)
+			prepareTunnelProcess(cmd)
+			assert.NoError(t, cmd.Start())
+			pid := cmd.Process.Pid
+
+			removedPaths := []string{}
+			signals := []string{}
+			deps := dependencies{
+				removeAll: func(path string) error {
+					removedPaths = append(removedPaths, path)
+					return nil
+				},
+				terminateProcessGroup: func(pgid int) error {
+					assert.Equal(t, -pid, pgid)
+					signals = append(signals, "TERM")
+					if s.failTerminate {
+						return errors.New("operation not permitted")
+					}
+					return terminateProcessGroup(pgid)
+				},
+				killProcessGroup: func(pgid int) error {
+					assert.Equal(t, -pid, pgid)
+					signals = append(signals, "KILL")
+					return killProcessGroup(pgid)
+				},
+			}
+
+			var tunnel *tunneledDockerHost
+			if s.waitFirst {
+				// reaping the process ourselves, so the tunnel doesn't know it
+				// has exited and will fail to signal it
+				assert.NoError(t, cmd.Wait())
+				tunnel = &tunneledDockerHost{socketDir: "/tmp/lazydocker-ssh-tunnel-12345", process: &execProcess{cmd: cmd, deps: deps}, deps: deps, log: noopLogger{}}
+			} else {
+				tunnel = newTunneledDockerHost("", "/tmp/lazydocker-ssh-tunnel-12345", &execProcess{cmd: cmd, deps: deps}, deps, noopLogger{}, nil, 0)
+			}
+
+			err := tunnel.Close()
+			if s.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.EqualValues(t, s.expectedSignals, signals)
+			assert.EqualValues(t, []string{"/tmp/lazydocker-ssh-tunnel-12345"}, removedPaths)
+		})
+	}
+}
+
+func TestTunneledDockerHostCloseTwice(t *testing.T) {
+	cmd := exec.Command("sleep", "30")
+	prepareTunnelProcess(cmd)
+	assert.NoError(t, cmd.Start())
+
+	removeAllCount := 0
+	terminateCount := 0
+	killCount := 0
+	deps := dependencies{
+		removeAll: func(path string) error {
+			removeAllCount++
+			return nil
+		},
+		terminateProcessGroup: func(pgid int) error {
+			terminateCount++
+			// forcing a kill, so that a second Close would have the most to
+			// get wrong
+			return errors.New("operation not permitted")
+		},
+		killProcessGroup: func(pgid int) error {
+			killCount++
+			return killProcessGroup(pgid)
+		},
+	}
+
+	tunnel := newTunneledDockerHost("", "/tmp/lazydocker-ssh-tunnel-12345", &execProcess{cmd: cmd, deps: deps}, deps, noopLogger{}, nil, 0)
+
+	firstErr := tunnel.Close()
+	assert.NoError(t, firstErr)
+	assert.Equal(t, firstErr, tunnel.Close())
+
+	assert.Equal(t, 1, terminateCount)
+	assert.Equal(t, 1, killCount)
+	assert.Equal(t, 1, removeAllCount)
+}
+
+func TestTunneledDockerHostDone(t *testing.T) {
+	cmd := exec.Command("sh", "-c", "exit 3")
+	prepareTunnelProcess(cmd)
+	assert.NoError(t, cmd.Start())
+
+	tunnel := newTunneledDockerHost("", "/tmp/lazydocker-ssh-tunnel-12345", &execProcess{cmd: cmd}, dependencies{}, noopLogger{}, nil, 0)
+
+	select {
+	case err := <-tunnel.Done():
+		exitErr, ok := err.(*exec.ExitError)
+		assert.True(t, ok, "expected an exit error, got %v", err)
+		if ok {
+			assert.Equal(t, 3, exitErr.ExitCode())
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("tunnel never reported that the process exited")
+	}
+
+	_, open := <-tunnel.Done()
+	assert.False(t, open)
+}
+
+func TestTunneledDockerHostReconnect(t *testing.T) {
+	type scenario struct {
+		testName string
+		// reconnectCommand is what each reconnect attempt starts, or nil for
+		// the attempt to fail
+		reconnectCommand  []string
+		maxReconnects     int
+		expectedAttempts  int
+		expectTunnelAlive bool
+	}
+
+	scenarios := []scenario{
+		{
+			testName:          "Reconnect succeeds",
+			reconnectCommand:  []string{"sleep", "30"},
+			maxReconnects:     3,
+			expectedAttempts:  1,
+			expectTunnelAlive: true,
+		},
+		{
+			testName:          "Reconnect keeps failing",
+			reconnectCommand:  nil,
+			maxReconnects:     3,
+			expectedAttempts:  3,
+			expectTunnelAlive: false,
+		},
+	}
+
+	for _, s := range scenarios {
+		s := s
+		t.Run(s.testName, func(t *testing.T) {
+			cmd := exec.Command("sh", "-c", "exit 255")
+			prepareTunnelProcess(cmd)
+			assert.NoError(t, cmd.Start())
+
+			deps := NewSSHHandler().deps
+			deps.removeAll = func(path string) error { return nil }
+
+			attempts := make(chan struct{}, s.maxReconnects)
+			reconnect := func() (tunnelProcess, error) {
+				attempts <- struct{}{}
+				if s.reconnectCommand == nil {
+					return nil, errors.New("connection refused")
+				}
+				cmd := exec.Command(s.reconnectCommand[0], s.reconnectCommand[1:]...)
+				prepareTunnelProcess(cmd)
+				return &execProcess{cmd: cmd, deps: deps}, cmd.Start()
+			}
+			log := &recordingLogger{}
+			tunnel := newTunneledDockerHost("unix:///tmp/lazydocker-ssh-tunnel-12345/192.168.5.178.sock", "/tmp/lazydocker-ssh-tunnel-12345", &execProcess{cmd: cmd, deps: deps}, deps, log, reconnect, s.maxReconnects)
+
+			select {
+			case err := <-tunnel.Done():
+				assert.False(t, s.expectTunnelAlive, "tunnel went down: %v", err)
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), "connection refused")
+			case <-time.After(500 * time.Millisecond):
+				assert.True(t, s.expectTunnelAlive, "tunnel never reported giving up")
+			}
+
+			assert.NoError(t, tunnel.Close())
+			assert.Len(t, attempts, s.expectedAttempts)
+
+			log.mutex.Lock()
+			assert.Contains(t, log.messages, "warn: ssh tunnel for unix:///tmp/lazydocker-ssh-tunnel-12345/192.168.5.178.sock dropped: process exited with code 255")
+			log.mutex.Unlock()
+
+			select {
+			case <-tunnel.Done():
+			case <-time.After(5 * time.Second):
+				t.Fatal("tunnel never reported that it was closed")
+			}
+		})
+	}
+}
+
+func TestSSHHandlerKillsTunnelOnSetupFailure(t *testing.T) {
+	type scenario struct {
+		testName    string
+		cancelFirst bool
+		expectedErr error
+	}
+
+	scenarios := []scenario{
+		{
+			testName:    "Dial loop times out",
+			cancelFirst: false,
+			expectedErr: ErrTunnelTimeout,
+		},
+		{
+			testName:    "Context cancelled",
+			cancelFirst: true,
+			expectedErr: context.Canceled,
+		},
+	}
+
+	for _, s := range scenarios {
+		s := s
+		t.Run(s.testName, func(t *testing.T) {
+			handler := NewSSHHandler(WithTunnelTimeout(20 * time.Millisecond))
+			handler.deps.initialDialInterval = time.Millisecond
+			handler.deps.maxDialInterval = time.Millisecond
+			handler.deps.lookPath = func(file string) (string, error) { return "/usr/bin/" + file, nil }
+			handler.deps.tempDir = func(dir string, pattern string) (string, error) { return "/tmp/lazydocker-ssh-tunnel-12345", nil }
+			handler.deps.dialContext = func(ctx context.Context, network string, address string) (io.Closer, error) {
+				return nil, errors.New("connection refused")
+			}
+
+			removedPaths := []string{}
+			handler.deps.removeAll = func(path string) error {
+				removedPaths = append(removedPaths, path)
+				return nil
+			}
+
+			// swap ssh for something that stays up without ever creating the socket
+			var pid int
+			handler.deps.startCmd = func(cmd *exec.Cmd) error {
+				sleepPath, err := exec.LookPath("sleep")
+				if err != nil {
+					return err
+				}
+				cmd.Path = sleepPath
+				cmd.Args = []string{"sleep", "30"}
+				if err := cmd.Start(); err != nil {
+					return err
+				}
+				pid = cmd.Process.Pid
+				return nil
+			}
+
+			killed := []int{}
+			handler.deps.killProcessGroup = func(pgid int) error {
+				killed = append(killed, pgid)
+				return killProcessGroup(pgid)
+			}
+			handler.deps.terminateProcessGroup = func(pgid int) error {
+				t.Errorf("expected the tunnel to be killed outright, not terminated")
+				return terminateProcessGroup(pgid)
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if s.cancelFirst {
+				handler.deps.dialContext = func(ctx context.Context, network string, address string) (io.Closer, error) {
+					cancel()
+					return nil, errors.New("connection refused")
+				}
+			}
+
+			_, err := handler.OpenTunnel(ctx, "ssh://myhost@192.168.5.178")
+			assert.Error(t, err)
+			assert.True(t, errors.Is(err, s.expectedErr), "unexpected error: %v", err)
+
+			assert.EqualValues(t, []int{-pid}, killed)
+			assert.EqualValues(t, []string{"/tmp/lazydocker-ssh-tunnel-12345"}, removedPaths)
+		})
+	}
+}
+
+func TestSSHHandlerTunnelOutlivesSetupContext(t *testing.T) {
+	handler := NewSSHHandler(WithBackend(BackendExec))
+	handler.deps.initialDialInterval = time.Millisecond
+	handler.deps.lookPath = func(file string) (string, error) { return "/usr/bin/" + file, nil }
+	handler.deps.tempDir = func(dir string, pattern string) (string, error) { return "/tmp/lazydocker-ssh-tunnel-12345", nil }
+	handler.deps.removeAll = func(path string) error { return nil }
+	handler.deps.sshConfig = noSSHConfig
+	handler.deps.dialContext = func(ctx context.Context, network string, address string) (io.Closer, error) {
+		return noopCloser{}, nil
+	}
+
+	// swap ssh for something that stays up
+	var pid int
+	handler.deps.startCmd = func(cmd *exec.Cmd) error {
+		sleepPath, err := exec.LookPath("sleep")
+		if err != nil {
+			return err
+		}
+		cmd.Path = sleepPath
+		cmd.Args = []string{"sleep", "30"}
+		if err := cmd.Start(); err != nil {
+			return err
+		}
+		pid = cmd.Process.Pid
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	tunnel, err := handler.OpenTunnel(ctx, "ssh://myhost@192.168.5.178")
+	if !assert.NoError(t, err) {
+		cancel()
+		return
+	}
+	defer tunnel.Close()
+
+	// like the gui does once it's connected
+	cancel()
+
+	select {
+	case err := <-tunnel.Done():
+		t.Fatalf("tunnel went down once the setup context was cancelled: %v", err)
+	case <-time.After(200 * time.Millisecond):
+	}
+	assert.NoError(t, syscall.Kill(pid, 0), "the tunnel process should still be running")
+}
+
+func TestSSHHandlerCustomSSHBinary(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lazydocker-ssh-binary-test")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	// a stand-in for ssh that records how it was called
+	argsFile := filepath.Join(dir, "args")
+	fakeSSH := filepath.Join(dir, "fake-ssh")
+	script := "#!/bin/sh\necho \"$@\" > " + argsFile + "\n"
+	assert.NoError(t, ioutil.WriteFile(fakeSSH, []byte(script), 0755))
+
+	handler := NewSSHHandler(WithSSHBinary(fakeSSH), WithBackend(BackendExec))
+
+	backend, err := handler.resolveBackend()
+	assert.NoError(t, err)
+	assert.Equal(t, BackendExec, backend)
+
+	target := tunnelTarget{host: "192.168.5.178", remoteSocket: defaultRemoteSocket}
+	cmd, err := handler.tunnelSSH(target, unixEndpoint("/tmp/lazydocker-ssh-tunnel-12345/192.168.5.178.sock"), ioutil.Discard)
+	assert.NoError(t, err)
+	assert.NoError(t, cmd.Wait())
+
+	args, err := ioutil.ReadFile(argsFile)
+	assert.NoError(t, err)
+	assert.Equal(t, "-L /tmp/lazydocker-ssh-tunnel-12345/192.168.5.178.sock:/var/run/docker.sock -o BatchMode=yes -o ServerAliveInterval=30 -o ServerAliveCountMax=3 192.168.5.178 -N\n", string(args))
+}
+
+func TestSSHHandlerCustomSSHBinaryMissing(t *testing.T) {
+	handler := NewSSHHandler(WithSSHBinary("/nonexistent/ssh"), WithBackend(BackendExec))
+
+	_, err := handler.resolveBackend()
+	assert.Error(t, err)
+	assert.True(t, errors.Is(err, ErrSSHBinaryMissing))
+	assert.Contains(t, err.Error(), "/nonexistent/ssh")
+}
+
+func TestSSHHandlerHandleSSHDockerHostReusesTunnel(t *testing.T) {
+	handler := NewSSHHandler(WithoutEnvOverride())
+	handler.deps.initialDialInterval = time.Millisecond
+	handler.deps.getenv = dockerHostEnv("ssh://myhost@192.168.5.178")
+	handler.deps.lookPath = func(file string) (string, error) { return "/usr/bin/" + file, nil }
+	handler.deps.sshConfig = noSSHConfig
+	handler.deps.tempDir = func(dir string, pattern string) (string, error) { return "/tmp/lazydocker-ssh-tunnel-12345", nil }
+	handler.deps.removeAll = func(path string) error { return nil }
+	handler.deps.dialContext = func(ctx context.Context, network string, address string) (io.Closer, error) {
+		return noopCloser{}, nil
+	}
+
+	// swap ssh for something that stays up until we stop it
+	startCount := 0
+	handler.deps.startCmd = func(cmd *exec.Cmd) error {
+		startCount++
+		sleepPath, err := exec.LookPath("sleep")
+		if err != nil {
+			return err
+		}
+		cmd.Path = sleepPath
+		cmd.Args = []string{"sleep", "30"}
+		return cmd.Start()
+	}
+
+	terminateCount := 0
+	handler.deps.terminateProcessGroup = func(pgid int) error {
+		terminateCount++
+		return terminateProcessGroup(pgid)
+	}
+
+	first, err := handler.HandleSSHDockerHost(context.Background())
+	assert.NoError(t, err)
+	second, err := handler.HandleSSHDockerHost(context.Background())
+	assert.NoError(t, err)
+
+	assert.Equal(t, 1, startCount)
+	assert.Equal(t, first.SocketPath(), second.SocketPath())
+
+	// the second caller is still using the tunnel
+	assert.NoError(t, first.Close())
+	assert.NoError(t, first.Close())
+	assert.Equal(t, 0, terminateCount)
+	assert.True(t, second.(*tunnelRef).shared.tunnel.alive())
+
+	assert.NoError(t, second.Close())
+	assert.Equal(t, 1, terminateCount)
+
+	// with the last ref gone, asking again opens a fresh tunnel
+	third, err := handler.HandleSSHDockerHost(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 2, startCount)
+	assert.NoError(t, third.Close())
+}
+
+func TestSSHHandlerHandleSSHDockerHostReplacesDeadTunnel(t *testing.T) {
+	handler := NewSSHHandler(WithoutEnvOverride())
+	handler.deps.initialDialInterval = time.Millisecond
+	handler.deps.getenv = dockerHostEnv("ssh://myhost@192.168.5.178")
+	handler.deps.lookPath = func(file string) (string, error) { return "/usr/bin/" + file, nil }
+	handler.deps.sshConfig = noSSHConfig
+	handler.deps.tempDir = func(dir string, pattern string) (string, error) { return "/tmp/lazydocker-ssh-tunnel-12345", nil }
+	handler.deps.removeAll = func(path string) error { return nil }
+	handler.deps.dialContext = func(ctx context.Context, network string, address string) (io.Closer, error) {
+		return noopCloser{}, nil
+	}
+
+	startCount := 0
+	handler.deps.startCmd = func(cmd *exec.Cmd) error {
+		startCount++
+		sleepPath, err := exec.LookPath("sleep")
+		if err != nil {
+			return err
+		}
+		cmd.Path = sleepPath
+		cmd.Args = []string{"sleep", "30"}
+		return cmd.Start()
+	}
+
+	first, err := handler.HandleSSHDockerHost(context.Background())
+	assert.NoError(t, err)
+
+	// simulate the connection dropping
+	firstTunnel := first.(*tunnelRef).shared.tunnel
+	assert.NoError(t, firstTunnel.process.kill())
+	select {
+	case <-first.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("tunnel never reported that it dropped")
+	}
+
+	second, err := handler.HandleSSHDockerHost(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 2, startCount)
+	assert.False(t, first.(*tunnelRef).shared == second.(*tunnelRef).shared)
+
+	assert.NoError(t, first.Close())
+	assert.True(t, second.(*tunnelRef).shared.tunnel.alive())
+	assert.NoError(t, second.Close())
+}
+
+// TestSSHHandlerTunnelEndToEnd brings a tunnel all the way up and back down,
+// with ssh swapped for a process that stays up and a listener standing in for
+// the forwarded docker socket
+func TestSSHHandlerTunnelEndToEnd(t *testing.T) {
+	handler := NewSSHHandler()
+	handler.deps.initialDialInterval = time.Millisecond
+	handler.deps.lookPath = func(file string) (string, error) { return "/usr/bin/" + file, nil }
+	handler.deps.sshConfig = noSSHConfig
+
+	var listener net.Listener
+	accepted := make(chan struct{}, 1)
+	handler.deps.startCmd = func(cmd *exec.Cmd) error {
+		// like ssh, listen on the local end of the -L spec
+		assert.Equal(t, "-L", cmd.Args[1])
+		localSocket := strings.SplitN(cmd.Args[2], ":", 2)[0]
+		assert.EqualValues(t, []string{"192.168.5.178", "-N"}, cmd.Args[len(cmd.Args)-2:])
+
+		var err error
+		listener, err = net.Listen("unix", localSocket)
+		if err != nil {
+			return err
+		}
+		go func() {
+			for {
+				conn, err := listener.Accept()
+				if err != nil {
+					return
+				}
+				_ = conn.Close()
+				select {
+				case accepted <- struct{}{}:
+				default:
+				}
+			}
+		}()
+
+		sleepPath, err := exec.LookPath("sleep")
+		if err != nil {
+			return err
+		}
+		cmd.Path = sleepPath
+		cmd.Args = []string{"sleep", "30"}
+		return cmd.Start()
+	}
+
+	u, err := parseSSHURL("ssh://myhost@192.168.5.178")
+	assert.NoError(t, err)
+	target, err := newTunnelTarget(u)
+	assert.NoError(t, err)
+
+	tunnel, err := handler.createDockerHostTunnel(context.Background(), target, BackendExec)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer listener.Close()
+
+	select {
+	case <-accepted:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the tunnel never dialed the socket")
+	}
+
+	localSocket := strings.TrimPrefix(tunnel.SocketPath(), "unix://")
+	assert.Equal(t, "192.168.5.178.sock", filepath.Base(localSocket))
+	assert.True(t, tunnel.Stats().DialAttempts >= 1)
+	assert.True(t, tunnel.alive())
+
+	assert.NoError(t, tunnel.Close())
+
+	select {
+	case <-tunnel.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("the tunnel process was never stopped")
+	}
+	assert.False(t, tunnel.alive())
+	_, err = os.Stat(filepath.Dir(localSocket))
+	assert.True(t, os.IsNotExist(err), "expected the socket dir to be removed, got %v", err)
+}
+
+func TestSSHHandlerForwardPortSSH(t *testing.T) {
+	var args []string
+	handler := NewSSHHandler()
+	handler.deps.initialDialInterval = time.Millisecond
+	handler.deps.startCmd = func(cmd *exec.Cmd) error {
+		args = cmd.Args[1:]
+		// standing in for ssh, which would stay up until we stop it
+		sleep := exec.Command("sleep", "30")
+		cmd.Path, cmd.Args = sleep.Path, sleep.Args
+		return cmd.Start()
+	}
+	handler.deps.dialContext = func(ctx context.Context, network string, address string) (io.Closer, error) {
+		return noopCloser{}, nil
+	}
+
+	target := tunnelTarget{host: "192.168.5.178", remoteSocket: defaultRemoteSocket}
+	forward, err := handler.forwardPortSSH(context.Background(), target, "/tmp/lazydocker-ssh-tunnel-12345", "127.0.0.1:8080")
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(forward.LocalAddress, "127.0.0.1:"))
+	assert.EqualValues(t, []string{
+		"-o", "ExitOnForwardFailure=yes",
+		"-L", forward.LocalAddress + ":127.0.0.1:8080",
+		"-o", "BatchMode=yes",
+		"-o", "ServerAliveInterval=30",
+		"-o", "ServerAliveCountMax=3",
+		"192.168.5.178", "-N",
+	}, args)
+
+	assert.NoError(t, forward.Close())
+	assert.NoError(t, forward.Close())
+}
+
+func TestTunneledDockerHostForwardPort(t *testing.T) {
+	cmd := exec.Command("sleep", "30")
+	prepareTunnelProcess(cmd)
+	assert.NoError(t, cmd.Start())
+
+	deps := NewSSHHandler().deps
+	deps.removeAll = func(path string) error { return nil }
+	tunnel := newTunneledDockerHost("", "/tmp/lazydocker-ssh-tunnel-12345", &execProcess{cmd: cmd, deps: deps}, deps, noopLogger{}, nil, 0)
+
+	closed := false
+	tunnel.forwardSSH = func(ctx context.Context, remoteAddress string) (*PortForward, error) {
+		assert.Equal(t, "127.0.0.1:8080", remoteAddress)
+		return newPortForward("127.0.0.1:54321", func() error {
+			closed = true
+			return nil
+		}), nil
+	}
+
+	forward, err := tunnel.ForwardPort(context.Background(), "127.0.0.1:8080")
+	assert.NoError(t, err)
+	assert.Equal(t, "127.0.0.1:54321", forward.LocalAddress)
+
+	// closing the tunnel takes its forwards with it
+	assert.NoError(t, tunnel.Close())
+	assert.True(t, closed)
+
+	_, err = tunnel.ForwardPort(context.Background(), "127.0.0.1:8080")
+	assert.Equal(t, ErrTunnelDown, err)
+
+	_, err = noopCloser{}.ForwardPort(context.Background(), "127.0.0.1:8080")
+	assert.Equal(t, ErrNotTunneled, err)
+}
@@ -0,0 +1,36 @@
+package ssh
+
+import (
+	"errors"
+	"os/exec"
+)
+
+// shortTempRoot is blank because there's no conventionally short temp directory
+// on windows, so we always use the OS temp dir
+const shortTempRoot = ""
+
+// prepareTunnelProcess is a no-op on windows, where there is no equivalent of
+// a unix process group we can set up for the child
+func prepareTunnelProcess(cmd *exec.Cmd) {}
+
+// terminateTunnelProcess kills the ssh process outright given windows has no
+// SIGTERM we can send to a console process
+func terminateTunnelProcess(cmd *exec.Cmd, deps dependencies) error {
+	return cmd.Process.Kill()
+}
+
+// killTunnelProcess kills the ssh process itself. The windows OpenSSH client
+// doesn't spawn children for a plain port forward so this is sufficient.
+func killTunnelProcess(cmd *exec.Cmd, deps dependencies) error {
+	return cmd.Process.Kill()
+}
+
+var errNoProcessGroups = errors.New("process groups aren't supported on windows")
+
+func terminateProcessGroup(pid int) error {
+	return errNoProcessGroups
+}
+
+func killProcessGroup(pid int) error {
+	return errNoProcessGroups
+}
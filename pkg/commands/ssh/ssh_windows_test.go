@@ -0,0 +1,8 @@
+package ssh
+
+import (
+	"os/exec"
+	"testing"
+)
+
+func assertTunnelProcessPrepared(t *testing.T, cmd *exec.Cmd) {}
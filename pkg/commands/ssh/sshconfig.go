@@ -0,0 +1,86 @@
+package ssh
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// resolvedHost is what an ssh host alias points at according to
+// ~/.ssh/config, with anything given in the DOCKER_HOST url taking precedence
+type resolvedHost struct {
+	alias    string
+	hostname string
+	port     string
+	// user is blank if neither the url nor the config names one
+	user string
+	// fromConfig is set if any of the above came from the config
+	fromConfig bool
+}
+
+// addr is the host:port we actually connect to
+func (h resolvedHost) addr() string {
+	return net.JoinHostPort(h.hostname, h.port)
+}
+
+// String describes where the alias points, e.g. for error messages
+func (h resolvedHost) String() string {
+	if h.user == "" {
+		return h.addr()
+	}
+	return h.user + "@" + h.addr()
+}
+
+// resolveSSHConfig looks the target's host up in ~/.ssh/config the way the ssh
+// binary would. The exec backend leaves the actual lookup to ssh, but we still
+// need it to catch a broken config up front and to say which host we were
+// really talking to when something goes wrong.
+func (self *SSHHandler) resolveSSHConfig(target tunnelTarget) (resolvedHost, error) {
+	alias := target.host
+
+	fromConfig := false
+
+	hostname := self.sshConfigValue(alias, "HostName")
+	if hostname == "" {
+		hostname = alias
+	} else {
+		fromConfig = true
+	}
+	// %h is the only token worth supporting: it's commonly used to append a
+	// domain to the alias
+	hostname = strings.Replace(hostname, "%h", alias, -1)
+
+	port := target.port
+	if port == "" {
+		port = self.sshConfigValue(alias, "Port")
+		if port != "" && !isValidPort(port) {
+			return resolvedHost{}, fmt.Errorf("invalid port %q for host %s in ssh config", port, alias)
+		}
+		fromConfig = fromConfig || port != ""
+	}
+	if port == "" {
+		port = "22"
+	}
+
+	user := target.user
+	if user == "" {
+		user = self.sshConfigValue(alias, "User")
+		fromConfig = fromConfig || user != ""
+	}
+
+	return resolvedHost{alias: alias, hostname: hostname, port: port, user: user, fromConfig: fromConfig}, nil
+}
+
+func isValidPort(port string) bool {
+	n, err := strconv.Atoi(port)
+	return err == nil && n > 0 && n <= 65535
+}
+
+func (self *SSHHandler) sshConfigValue(alias, key string) string {
+	values := self.deps.sshConfig(alias, key)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
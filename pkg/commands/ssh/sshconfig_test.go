@@ -0,0 +1,125 @@
+package ssh
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSSHHandlerResolveSSHConfig(t *testing.T) {
+	type scenario struct {
+		testName     string
+		target       tunnelTarget
+		sshConfig    map[string][]string
+		expectedHost resolvedHost
+		expectError  bool
+	}
+
+	scenarios := []scenario{
+		{
+			testName:     "Not in ssh config",
+			target:       tunnelTarget{host: "192.168.5.178"},
+			expectedHost: resolvedHost{alias: "192.168.5.178", hostname: "192.168.5.178", port: "22"},
+		},
+		{
+			testName: "Alias from ssh config",
+			target:   tunnelTarget{host: "prod"},
+			sshConfig: map[string][]string{
+				"HostName": {"10.0.0.5"},
+				"Port":     {"2222"},
+				"User":     {"deploy"},
+			},
+			expectedHost: resolvedHost{alias: "prod", hostname: "10.0.0.5", port: "2222", user: "deploy", fromConfig: true},
+		},
+		{
+			testName: "Only the user from ssh config",
+			target:   tunnelTarget{host: "myhost"},
+			sshConfig: map[string][]string{
+				"User": {"deploy"},
+			},
+			expectedHost: resolvedHost{alias: "myhost", hostname: "myhost", port: "22", user: "deploy", fromConfig: true},
+		},
+		{
+			testName: "URL takes precedence over ssh config",
+			target:   tunnelTarget{host: "prod", port: "2022", user: "admin"},
+			sshConfig: map[string][]string{
+				"Port": {"2222"},
+				"User": {"deploy"},
+			},
+			expectedHost: resolvedHost{alias: "prod", hostname: "prod", port: "2022", user: "admin"},
+		},
+		{
+			testName: "Invalid port in ssh config",
+			target:   tunnelTarget{host: "prod"},
+			sshConfig: map[string][]string{
+				"Port": {"ssh"},
+			},
+			expectError: true,
+		},
+	}
+
+	for _, s := range scenarios {
+		s := s
+		t.Run(s.testName, func(t *testing.T) {
+			handler := NewSSHHandler()
+			handler.deps.sshConfig = func(alias, key string) []string {
+				assert.Equal(t, s.target.host, alias)
+				return s.sshConfig[key]
+			}
+
+			host, err := handler.resolveSSHConfig(s.target)
+			if s.expectError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, s.expectedHost, host)
+		})
+	}
+}
+
+func TestSSHHandlerTunnelErrorMentionsResolvedHost(t *testing.T) {
+	handler := NewSSHHandler(WithTunnelTimeout(10*time.Millisecond), WithBackend(BackendExec))
+	handler.deps.initialDialInterval = time.Millisecond
+	handler.deps.dialContext = func(ctx context.Context, network string, address string) (io.Closer, error) {
+		return nil, errors.New("connection refused")
+	}
+	handler.deps.startCmd = func(cmd *exec.Cmd) error { return nil }
+	handler.deps.tempDir = func(dir string, pattern string) (string, error) { return "/tmp/lazydocker-ssh-tunnel-12345", nil }
+	handler.deps.removeAll = func(path string) error { return nil }
+	handler.deps.lookPath = func(file string) (string, error) { return "/usr/bin/" + file, nil }
+	handler.deps.sshConfig = func(alias, key string) []string {
+		return map[string][]string{"HostName": {"10.0.0.5"}, "User": {"deploy"}}[key]
+	}
+
+	_, err := handler.OpenTunnel(context.Background(), "ssh://prod")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "prod resolves to deploy@10.0.0.5:22 via ssh config")
+
+	var tunnelErr *TunnelError
+	if assert.True(t, errors.As(err, &tunnelErr)) {
+		assert.Equal(t, "prod", tunnelErr.Host)
+		assert.Equal(t, "deploy@10.0.0.5:22", tunnelErr.ResolvedHost)
+	}
+}
+
+func TestSSHHandlerOpenTunnelInvalidSSHConfig(t *testing.T) {
+	handler := NewSSHHandler(WithBackend(BackendExec))
+	handler.deps.lookPath = func(file string) (string, error) { return "/usr/bin/" + file, nil }
+	handler.deps.startCmd = func(cmd *exec.Cmd) error {
+		t.Error("expected the tunnel not to be started")
+		return nil
+	}
+	handler.deps.sshConfig = func(alias, key string) []string {
+		return map[string][]string{"Port": {"22x"}}[key]
+	}
+
+	_, err := handler.OpenTunnel(context.Background(), "ssh://prod")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `invalid port "22x" for host prod in ssh config`)
+}
@@ -0,0 +1,40 @@
+package ssh
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// dockerTLSFiles are the files the docker client loads from DOCKER_CERT_PATH
+var dockerTLSFiles = []string{"ca.pem", "cert.pem", "key.pem"}
+
+// CheckDockerTLS makes sure the TLS files the docker client needs are there
+// when DOCKER_TLS_VERIFY is set. Otherwise a missing file surfaces as e.g.
+// "x509: certificate signed by unknown authority" from deep inside the client,
+// which doesn't tell you what to fix. It's a no-op without DOCKER_TLS_VERIFY.
+func (self *SSHHandler) CheckDockerTLS() error {
+	if self.deps.getenv("DOCKER_TLS_VERIFY") == "" {
+		return nil
+	}
+
+	certPath := self.deps.getenv("DOCKER_CERT_PATH")
+	if certPath == "" {
+		// the docker client only loads certs from DOCKER_CERT_PATH, so
+		// without it we'd talk plain http to a daemon expecting TLS
+		return fmt.Errorf("%w: DOCKER_TLS_VERIFY is set but DOCKER_CERT_PATH isn't", ErrTLSFilesMissing)
+	}
+
+	missing := []string{}
+	for _, name := range dockerTLSFiles {
+		info, err := self.deps.stat(filepath.Join(certPath, name))
+		if err != nil || info.IsDir() {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%w: missing %s in DOCKER_CERT_PATH (%s)", ErrTLSFilesMissing, strings.Join(missing, ", "), certPath)
+	}
+
+	return nil
+}
@@ -0,0 +1,88 @@
+package ssh
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSSHHandlerCheckDockerTLS(t *testing.T) {
+	type scenario struct {
+		testName            string
+		tlsVerify           string
+		certPath            string
+		files               []string
+		expectedErrorSubstr string
+	}
+
+	scenarios := []scenario{
+		{
+			testName:  "TLS verify not set",
+			tlsVerify: "",
+			certPath:  "/nonexistent",
+		},
+		{
+			testName:  "All files present",
+			tlsVerify: "1",
+			files:     []string{"ca.pem", "cert.pem", "key.pem"},
+		},
+		{
+			testName:            "Missing ca",
+			tlsVerify:           "1",
+			files:               []string{"cert.pem", "key.pem"},
+			expectedErrorSubstr: "missing ca.pem in DOCKER_CERT_PATH",
+		},
+		{
+			testName:            "Missing everything",
+			tlsVerify:           "1",
+			files:               []string{},
+			expectedErrorSubstr: "missing ca.pem, cert.pem, key.pem in DOCKER_CERT_PATH",
+		},
+		{
+			testName:            "Cert path not set",
+			tlsVerify:           "1",
+			certPath:            "",
+			expectedErrorSubstr: "DOCKER_TLS_VERIFY is set but DOCKER_CERT_PATH isn't",
+		},
+	}
+
+	for _, s := range scenarios {
+		s := s
+		t.Run(s.testName, func(t *testing.T) {
+			certPath := s.certPath
+			if s.files != nil {
+				dir, err := ioutil.TempDir("", "lazydocker-tls-test")
+				assert.NoError(t, err)
+				defer os.RemoveAll(dir)
+				for _, name := range s.files {
+					assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, name), []byte{}, 0600))
+				}
+				certPath = dir
+			}
+
+			handler := NewSSHHandler()
+			handler.deps.getenv = func(key string) string {
+				switch key {
+				case "DOCKER_TLS_VERIFY":
+					return s.tlsVerify
+				case "DOCKER_CERT_PATH":
+					return certPath
+				}
+				return ""
+			}
+
+			err := handler.CheckDockerTLS()
+			if s.expectedErrorSubstr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), s.expectedErrorSubstr)
+			assert.True(t, errors.Is(err, ErrTLSFilesMissing))
+		})
+	}
+}
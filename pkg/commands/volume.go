@@ -56,12 +56,6 @@ func (c *DockerCommand) RefreshVolumes() error {
 	return nil
 }
 
-// PruneVolumes prunes volumes
-func (c *DockerCommand) PruneVolumes() error {
-	_, err := c.Client.VolumesPrune(context.Background(), filters.Args{})
-	return err
-}
-
 // Remove removes the volume
 func (v *Volume) Remove(force bool) error {
 	return v.Client.VolumeRemove(context.Background(), v.Name, force)
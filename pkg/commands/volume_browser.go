@@ -0,0 +1,214 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/fatih/color"
+	"github.com/jesseduffield/lazydocker/pkg/utils"
+)
+
+const (
+	// volumeBrowserMountPoint is where the volume goes in the helper container
+	volumeBrowserMountPoint = "/volume"
+	// volumeBrowserLabel marks our helper containers, with the name of the
+	// volume they're for
+	volumeBrowserLabel = "lazydocker.volume-browser"
+	// volumeBrowserLifetime is how long the helper container lives for if we
+	// never get round to removing it, e.g. because we were killed
+	volumeBrowserLifetime = "3600"
+)
+
+// VolumeBrowser lets us look at the files in a volume, through a helper
+// container that has the volume mounted read-only. Close it when you're done,
+// which removes the container.
+type VolumeBrowser struct {
+	Volume      *Volume
+	containerID string
+}
+
+// VolumeEntry is a file or directory in a volume
+type VolumeEntry struct {
+	Name  string
+	IsDir bool
+}
+
+// GetDisplayStrings is a function.
+func (e *VolumeEntry) GetDisplayStrings(isFocused bool) []string {
+	if e.IsDir {
+		return []string{utils.ColoredString(e.Name+"/", color.FgBlue)}
+	}
+	return []string{e.Name}
+}
+
+// Browse starts a helper container for looking at the volume's files. If the
+// docker host doesn't have the helper image we pull it first.
+func (v *Volume) Browse(ctx context.Context) (*VolumeBrowser, error) {
+	image := v.OSCommand.Config.UserConfig.VolumeBrowserImage
+	config := &container.Config{
+		Image:  image,
+		Cmd:    []string{"sleep", volumeBrowserLifetime},
+		Labels: map[string]string{volumeBrowserLabel: v.Name},
+	}
+	hostConfig := &container.HostConfig{
+		Binds:       []string{v.Name + ":" + volumeBrowserMountPoint + ":ro"},
+		AutoRemove:  true,
+		NetworkMode: "none",
+	}
+
+	created, err := v.Client.ContainerCreate(ctx, config, hostConfig, nil, "")
+	if client.IsErrNotFound(err) {
+		if err := v.DockerCommand.PullImage(ctx, image, func(*ImagePullProgress) {}); err != nil {
+			return nil, fmt.Errorf("pull %s: %w", image, err)
+		}
+		created, err = v.Client.ContainerCreate(ctx, config, hostConfig, nil, "")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	browser := &VolumeBrowser{Volume: v, containerID: created.ID}
+	if err := v.Client.ContainerStart(ctx, created.ID, types.ContainerStartOptions{}); err != nil {
+		// it's been created, so it needs removing even though it never ran
+		if closeErr := browser.Close(); closeErr != nil {
+			v.Log.Error(closeErr)
+		}
+		return nil, err
+	}
+	return browser, nil
+}
+
+// containerPath turns a path in the volume into one in the helper container.
+// Paths can't climb out of the volume: /../etc is just /etc in the volume.
+func containerPath(volumePath string) string {
+	return path.Join(volumeBrowserMountPoint, path.Clean("/"+volumePath))
+}
+
+// List returns what's in a directory of the volume, directories first
+func (b *VolumeBrowser) List(ctx context.Context, dir string) ([]*VolumeEntry, error) {
+	output := &bytes.Buffer{}
+	// -p marks directories with a trailing slash, -A shows dotfiles
+	if err := b.exec(ctx, []string{"ls", "-1Ap", containerPath(dir)}, output); err != nil {
+		return nil, err
+	}
+	return parseVolumeListing(output.String()), nil
+}
+
+func parseVolumeListing(output string) []*VolumeEntry {
+	entries := []*VolumeEntry{}
+	for _, line := range strings.Split(output, "\n") {
+		if line == "" {
+			continue
+		}
+		entry := &VolumeEntry{Name: strings.TrimSuffix(line, "/"), IsDir: strings.HasSuffix(line, "/")}
+		entries = append(entries, entry)
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].IsDir != entries[j].IsDir {
+			return entries[i].IsDir
+		}
+		return entries[i].Name < entries[j].Name
+	})
+	return entries
+}
+
+// ReadFile streams up to limit bytes of a file in the volume to w
+func (b *VolumeBrowser) ReadFile(ctx context.Context, file string, limit int64, w io.Writer) error {
+	return b.exec(ctx, []string{"head", "-c", strconv.FormatInt(limit, 10), containerPath(file)}, w)
+}
+
+// exec runs cmd in the helper container, streaming its stdout to w. If it
+// fails, the error is whatever it said on stderr.
+func (b *VolumeBrowser) exec(ctx context.Context, cmd []string, w io.Writer) error {
+	cli := b.Volume.Client
+	created, err := cli.ContainerExecCreate(ctx, b.containerID, types.ExecConfig{Cmd: cmd, AttachStdout: true, AttachStderr: true})
+	if err != nil {
+		return err
+	}
+	resp, err := cli.ContainerExecAttach(ctx, created.ID, types.ExecStartCheck{})
+	if err != nil {
+		return err
+	}
+	defer resp.Close()
+
+	// the hijacked connection doesn't know about our context, so we close it
+	// ourselves if we're cancelled
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			resp.Close()
+		case <-done:
+		}
+	}()
+
+	stderr := &bytes.Buffer{}
+	if _, err := stdcopy.StdCopy(w, stderr, resp.Reader); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return err
+	}
+
+	exitCode, err := b.waitForExec(ctx, created.ID)
+	if err != nil {
+		return err
+	}
+	if exitCode != 0 {
+		message := strings.TrimSpace(stderr.String())
+		if message == "" {
+			message = fmt.Sprintf("%s exited with code %d", cmd[0], exitCode)
+		}
+		return errors.New(message)
+	}
+	return nil
+}
+
+// waitForExec returns the exit code of an exec. Its output can finish a moment
+// before the daemon marks it as done.
+func (b *VolumeBrowser) waitForExec(ctx context.Context, execID string) (int, error) {
+	ticker := time.NewTicker(20 * time.Millisecond)
+	defer ticker.Stop()
+	timeout := time.After(5 * time.Second)
+	for {
+		inspect, err := b.Volume.Client.ContainerExecInspect(ctx, execID)
+		if err != nil {
+			return 0, err
+		}
+		if !inspect.Running {
+			return inspect.ExitCode, nil
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		case <-timeout:
+			return 0, errors.New("timed out waiting for the volume browser")
+		}
+	}
+}
+
+// Close removes the helper container. We don't use the caller's context for
+// this, since we want the container gone even if they've given up.
+func (b *VolumeBrowser) Close() error {
+	err := b.Volume.Client.ContainerRemove(context.Background(), b.containerID, types.ContainerRemoveOptions{Force: true})
+	if err == nil || client.IsErrNotFound(err) || strings.Contains(err.Error(), "already in progress") {
+		// being an auto-remove container, it may have beaten us to it
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,180 @@
+package commands
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/jesseduffield/lazydocker/pkg/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseVolumeListing(t *testing.T) {
+	entries := parseVolumeListing("b.txt\nconf/\n.hidden\na.txt\ndata/\n")
+	assert.EqualValues(t, []*VolumeEntry{
+		{Name: "conf", IsDir: true},
+		{Name: "data", IsDir: true},
+		{Name: ".hidden"},
+		{Name: "a.txt"},
+		{Name: "b.txt"},
+	}, entries)
+
+	assert.EqualValues(t, []*VolumeEntry{}, parseVolumeListing(""))
+}
+
+func TestContainerPath(t *testing.T) {
+	type scenario struct {
+		volumePath string
+		expected   string
+	}
+
+	scenarios := []scenario{
+		{"/", "/volume"},
+		{"", "/volume"},
+		{"/data/db", "/volume/data/db"},
+		{"data/../conf", "/volume/conf"},
+		// no climbing out of the volume
+		{"/../../etc/passwd", "/volume/etc/passwd"},
+	}
+
+	for _, s := range scenarios {
+		assert.Equal(t, s.expected, containerPath(s.volumePath), s.volumePath)
+	}
+}
+
+// fakeVolumeBrowserDaemon answers the exec endpoints for the helper container
+// abc, writing stdout and stderr down the hijacked connection and then exiting
+// with exitCode
+func fakeVolumeBrowserDaemon(t *testing.T, stdout string, stderr string, exitCode int) (http.HandlerFunc, *[]string) {
+	var mutex sync.Mutex
+	cmds := []string{}
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/containers/abc/exec"):
+			config := types.ExecConfig{}
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&config))
+			mutex.Lock()
+			cmds = append(cmds, strings.Join(config.Cmd, " "))
+			mutex.Unlock()
+			_ = json.NewEncoder(w).Encode(types.IDResponse{ID: "exec1"})
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/exec/exec1/start"):
+			conn, buf, err := w.(http.Hijacker).Hijack()
+			if !assert.NoError(t, err) {
+				return
+			}
+			defer conn.Close()
+			_, _ = buf.WriteString("HTTP/1.1 101 UPGRADED\r\nContent-Type: application/vnd.docker.raw-stream\r\nConnection: Upgrade\r\nUpgrade: tcp\r\n\r\n")
+			writeFrames(buf, stdout, stderr)
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/exec/exec1/json"):
+			_ = json.NewEncoder(w).Encode(types.ContainerExecInspect{ExecID: "exec1", ExitCode: exitCode})
+		default:
+			http.NotFound(w, r)
+		}
+	}
+	return handler, &cmds
+}
+
+func writeFrames(buf *bufio.ReadWriter, stdout string, stderr string) {
+	if stdout != "" {
+		_, _ = stdcopy.NewStdWriter(buf, stdcopy.Stdout).Write([]byte(stdout))
+	}
+	if stderr != "" {
+		_, _ = stdcopy.NewStdWriter(buf, stdcopy.Stderr).Write([]byte(stderr))
+	}
+	_ = buf.Flush()
+}
+
+func TestVolumeBrowserList(t *testing.T) {
+	type scenario struct {
+		testName            string
+		stdout              string
+		stderr              string
+		exitCode            int
+		expectedEntries     []*VolumeEntry
+		expectedErrorSubstr string
+	}
+
+	scenarios := []scenario{
+		{
+			testName:        "Listing",
+			stdout:          "postgresql.conf\nbase/\n",
+			expectedEntries: []*VolumeEntry{{Name: "base", IsDir: true}, {Name: "postgresql.conf"}},
+		},
+		{
+			testName:            "Missing directory",
+			stderr:              "ls: /volume/data: No such file or directory\n",
+			exitCode:            1,
+			expectedErrorSubstr: "No such file or directory",
+		},
+		{
+			testName:            "Failure with nothing on stderr",
+			exitCode:            2,
+			expectedErrorSubstr: "ls exited with code 2",
+		},
+	}
+
+	for _, s := range scenarios {
+		s := s
+		t.Run(s.testName, func(t *testing.T) {
+			handler, cmds := fakeVolumeBrowserDaemon(t, s.stdout, s.stderr, s.exitCode)
+			dockerCommand := newFakeDaemonCommand(t, handler)
+			browser := &VolumeBrowser{Volume: &Volume{Name: "pgdata", Client: dockerCommand.Client}, containerID: "abc"}
+
+			entries, err := browser.List(context.Background(), "/data")
+			assert.EqualValues(t, []string{"ls -1Ap /volume/data"}, *cmds)
+			if s.expectedErrorSubstr != "" {
+				if assert.Error(t, err) {
+					assert.Contains(t, err.Error(), s.expectedErrorSubstr)
+				}
+				return
+			}
+			assert.NoError(t, err)
+			assert.EqualValues(t, s.expectedEntries, entries)
+		})
+	}
+}
+
+func TestVolumeBrowseRemovesContainerWhenStartFails(t *testing.T) {
+	removed := []string{}
+	dockerCommand := newFakeDaemonCommand(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/containers/create"):
+			body := struct {
+				Image      string
+				HostConfig struct {
+					Binds      []string
+					AutoRemove bool
+				}
+			}{}
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			assert.Equal(t, "busybox:latest", body.Image)
+			assert.EqualValues(t, []string{"pgdata:/volume:ro"}, body.HostConfig.Binds)
+			assert.True(t, body.HostConfig.AutoRemove)
+			_, _ = w.Write([]byte(`{"Id": "abc"}`))
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/containers/abc/start"):
+			http.Error(w, `{"message": "no space left on device"}`, http.StatusInternalServerError)
+		case r.Method == http.MethodDelete:
+			removed = append(removed, r.URL.Path+"?"+r.URL.RawQuery)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	userConfig := config.GetDefaultConfig()
+	osCommand := NewOSCommand(NewDummyLog(), &config.AppConfig{UserConfig: &userConfig})
+	volume := &Volume{Name: "pgdata", Client: dockerCommand.Client, OSCommand: osCommand, Log: NewDummyLog()}
+
+	browser, err := volume.Browse(context.Background())
+	assert.Nil(t, browser)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "no space left on device")
+	}
+	assert.EqualValues(t, []string{"/v" + APIVersion + "/containers/abc?force=1"}, removed)
+}
@@ -33,6 +33,21 @@ type UserConfig struct {
 	// hit esc or q when no confirmation panels are open
 	ConfirmOnQuit bool `yaml:"confirmOnQuit,omitempty"`
 
+	// ConfirmDestructive when enabled asks before the quick remove keys (D)
+	// remove anything. Turning it off makes them remove straight away.
+	ConfirmDestructive bool `yaml:"confirmDestructive"`
+
+	// ReadOnly turns off everything that would change anything, like starting,
+	// stopping or removing containers, pruning and exec'ing, leaving you free
+	// to look around e.g. while screen-sharing a production daemon. The
+	// --read-only flag turns it on too.
+	ReadOnly bool `yaml:"readOnly,omitempty"`
+
+	// StopTimeout is how long stopping a container gives it to exit before
+	// docker kills it. Zero leaves it to the container, which waits 10 seconds
+	// unless it was created with a --stop-timeout of its own.
+	StopTimeout time.Duration `yaml:"stopTimeout,omitempty"`
+
 	// CommandTemplates determines what commands actually get called when we run
 	// certain commands
 	CommandTemplates CommandTemplatesConfig `yaml:"commandTemplates,omitempty"`
@@ -57,13 +72,50 @@ type UserConfig struct {
 	// Stats determines how long lazydocker will gather container stats for, and
 	// what stat info to graph
 	Stats StatsConfig `yaml:"stats,omitempty"`
+
+	// SSH determines how we tunnel to a remote docker host when DOCKER_HOST is
+	// an ssh:// url
+	SSH SSHConfig `yaml:"ssh,omitempty"`
+
+	// VolumeBrowserImage is the image of the helper container we start to
+	// browse the files in a volume. It needs sh, ls and head, and we'll pull it
+	// if the docker host doesn't have it.
+	VolumeBrowserImage string `yaml:"volumeBrowserImage,omitempty"`
+
+	// Keybinding remaps keys, keyed by view and then by action name, e.g.
+	// containers: {stop: S}. Bindings for every view go under 'universal'. Run
+	// lazydocker --keymap to see the actions and the keys they're bound to.
+	Keybinding map[string]map[string]string `yaml:"keybinding,omitempty"`
 }
 
-// ThemeConfig is for setting the colors of panels and some text.
+// ThemeConfig is for setting the colors of panels and some text. Each color
+// is a list of colors and attributes, e.g. [red, bold]. Any you leave out
+// come from the preset.
 type ThemeConfig struct {
+	// Preset is the theme we start from: dark, light or high-contrast.
+	// Defaults to dark.
+	Preset string `yaml:"preset,omitempty"`
+
 	ActiveBorderColor   []string `yaml:"activeBorderColor,omitempty"`
 	InactiveBorderColor []string `yaml:"inactiveBorderColor,omitempty"`
 	OptionsTextColor    []string `yaml:"optionsTextColor,omitempty"`
+
+	// SelectedLineBgColor is the background of the selected line in the
+	// focused panel, which is also always bold
+	SelectedLineBgColor []string `yaml:"selectedLineBgColor,omitempty"`
+
+	// RunningColor, StoppedColor and FailedColor are for a container's status.
+	// A container that exited with a non-zero exit code, or is dead, has failed.
+	RunningColor []string `yaml:"runningColor,omitempty"`
+	StoppedColor []string `yaml:"stoppedColor,omitempty"`
+	FailedColor  []string `yaml:"failedColor,omitempty"`
+
+	// LogErrorColor and co are for log lines at each level. Lines that are
+	// already colored are left alone.
+	LogErrorColor   []string `yaml:"logErrorColor,omitempty"`
+	LogWarningColor []string `yaml:"logWarningColor,omitempty"`
+	LogInfoColor    []string `yaml:"logInfoColor,omitempty"`
+	LogDebugColor   []string `yaml:"logDebugColor,omitempty"`
 }
 
 // GuiConfig is for configuring visual things like colors and whether we show or
@@ -81,8 +133,13 @@ type GuiConfig struct {
 	ScrollPastBottom bool `yaml:"scrollPastBottom,omitempty"`
 
 	// IgnoreMouseEvents is for when you do not want to use your mouse to interact
-	// with anything
-	IgnoreMouseEvents bool `yaml:"mouseEvents,omitempty"`
+	// with anything, e.g. because your terminal or ssh client gets in the way of
+	// the mouse reporting
+	IgnoreMouseEvents bool `yaml:"ignoreMouseEvents,omitempty"`
+
+	// LegacyIgnoreMouseEvents is what IgnoreMouseEvents used to be called in the
+	// config. It still works, but is left out of the docs.
+	LegacyIgnoreMouseEvents bool `yaml:"mouseEvents,omitempty"`
 
 	// Theme determines what colors and color attributes your panel borders have.
 	// I always set inactiveBorderColor to black because in my terminal it's more
@@ -158,9 +215,10 @@ type CommandTemplatesConfig struct {
 	// ViewContainerLogs is like ViewServiceLogs but for containers
 	ViewContainerLogs string `yaml:"viewContainerLogs,omitempty"`
 
-	// ContainerLogs shows the logs of a container. By default this restricts the
-	// output to (as of right now) the last hour. This is for the sake of
-	// performance, and you can feel free to change this
+	// ContainerLogs shows the logs of a container. Left as the default, we
+	// stream the logs from the docker API instead and you can pick how far back
+	// they go from the logs tab. If you change it, we run your command like we
+	// used to.
 	ContainerLogs string `yaml:"containerLogs,omitempty"`
 
 	// AllLogs is for showing what you get from doing `docker-compose logs`. It
@@ -185,6 +243,13 @@ type CommandTemplatesConfig struct {
 
 	// ServiceTop is the command for viewing the processes under a given service
 	ServiceTop string `yaml:"serviceTop,omitempty"`
+
+	// ComposeProjectUp, ComposeProjectDown and ComposeProjectRestart act on a
+	// whole compose project from the containers panel. They're run from the
+	// project's working directory, as recorded in its containers' labels.
+	ComposeProjectUp      string `yaml:"composeProjectUp,omitempty"`
+	ComposeProjectDown    string `yaml:"composeProjectDown,omitempty"`
+	ComposeProjectRestart string `yaml:"composeProjectRestart,omitempty"`
 }
 
 // OSConfig contains config on the level of the os
@@ -194,6 +259,10 @@ type OSConfig struct {
 
 	// OpenCommand is the command for opening a link
 	OpenLinkCommand string `yaml:"openLinkCommand,omitempty"`
+
+	// CopyToClipboardCommand is the command for copying to the clipboard. What
+	// we're copying is passed on its stdin.
+	CopyToClipboardCommand string `yaml:"copyToClipboardCommand,omitempty"`
 }
 
 // UpdateConfig determines what the default settings are for updating the ui
@@ -202,6 +271,60 @@ type UpdateConfig struct {
 	// It expects a valid duration like: 100ms, 2s, 200ns
 	// for docs see: https://golang.org/pkg/time/#ParseDuration
 	DockerRefreshInterval time.Duration `yaml:"dockerRefreshInterval,omitempty"`
+
+	// ContainerCacheTTL is how long we reuse the container list for before
+	// asking docker again, which cuts down on traffic to a remote daemon. We
+	// always ask again after you start, stop or remove a container. Set it to
+	// a negative value to ask on every refresh. Defaults to 1s.
+	ContainerCacheTTL time.Duration `yaml:"containerCacheTTL,omitempty"`
+
+	// ContainerDetailsTTL is how long we reuse what docker inspect told us
+	// about a container, e.g. its uptime, restart count and health checks, as
+	// long as nothing about it has changed in the container list. A container that's
+	// started, stopped or restarted is inspected on the next refresh anyway.
+	// Set it to a negative value to inspect every container on every refresh.
+	// Defaults to 10s.
+	ContainerDetailsTTL time.Duration `yaml:"containerDetailsTTL,omitempty"`
+
+	// Panels is how often each side panel refreshes. A panel left at zero
+	// refreshes every DockerRefreshInterval.
+	Panels PanelRefreshConfig `yaml:"panels,omitempty"`
+
+	// SSHMultiplier stretches every refresh interval when we're talking to
+	// the daemon over ssh, where each refresh is a round trip down the tunnel.
+	// Set it to 1 to refresh just as often as you would locally. Defaults to 5.
+	SSHMultiplier float64 `yaml:"sshMultiplier,omitempty"`
+
+	// RefreshUnderPopups keeps the side panels refreshing while a menu or
+	// confirmation popup is open. By default they wait until it's closed.
+	RefreshUnderPopups bool `yaml:"refreshUnderPopups,omitempty"`
+}
+
+// PanelRefreshConfig is how often each side panel refreshes. Images aren't
+// here as they're refreshed as the daemon tells us about changes to them.
+type PanelRefreshConfig struct {
+	Project    time.Duration `yaml:"project,omitempty"`
+	Containers time.Duration `yaml:"containers,omitempty"`
+	Volumes    time.Duration `yaml:"volumes,omitempty"`
+	Networks   time.Duration `yaml:"networks,omitempty"`
+}
+
+// RefreshInterval is how long to wait between refreshes of a panel that's
+// configured to refresh every panelInterval, stretched by SSHMultiplier if
+// overSSH
+func (c UpdateConfig) RefreshInterval(panelInterval time.Duration, overSSH bool) time.Duration {
+	interval := panelInterval
+	if interval <= 0 {
+		interval = c.DockerRefreshInterval
+	}
+	if overSSH && c.SSHMultiplier > 0 {
+		interval = time.Duration(float64(interval) * c.SSHMultiplier)
+	}
+	if interval <= 0 {
+		// a zero interval would have us refreshing flat out
+		interval = time.Millisecond * 100
+	}
+	return interval
 }
 
 // GraphConfig specifies how to make a graph of recorded container stats
@@ -257,6 +380,47 @@ type StatsConfig struct {
 	MaxDuration time.Duration `yaml:"maxDuration,omitempty"`
 }
 
+// SSHConfig determines how we tunnel to a remote docker host over ssh
+type SSHConfig struct {
+	// Options are extra arguments passed verbatim to the ssh command when
+	// tunneling, e.g. ["-o", "StrictHostKeyChecking=accept-new"] or
+	// ["-i", "~/.ssh/id_ed25519"]. They go before the host so they can't
+	// replace the port forward or the host itself.
+	Options []string `yaml:"options,omitempty"`
+
+	// Binary is the ssh executable to tunnel with, e.g. "/usr/local/bin/ssh" or
+	// a wrapper script. A bare name is looked up in PATH. Defaults to "ssh".
+	Binary string `yaml:"binary,omitempty"`
+
+	// IdentitiesOnly makes ssh offer only the identity file given in Options,
+	// rather than every key in your ssh agent first. Worth turning on if you
+	// have lots of keys loaded and hosts reject you for too many auth attempts.
+	IdentitiesOnly bool `yaml:"identitiesOnly,omitempty"`
+
+	// KeepAliveInterval is how often ssh pings the host while the tunnel is
+	// idle, so that firewalls don't drop it. Set it to a negative value to turn
+	// keepalives off. Defaults to "30s".
+	KeepAliveInterval time.Duration `yaml:"keepAliveInterval,omitempty"`
+
+	// KeepAliveCountMax is how many unanswered pings ssh tolerates before
+	// dropping the tunnel. Defaults to 3.
+	KeepAliveCountMax int `yaml:"keepAliveCountMax,omitempty"`
+
+	// LocalBind makes the tunnel listen on a local tcp port instead of a unix
+	// socket, e.g. "tcp://127.0.0.1:2375", so that other tools can use it too.
+	// Only loopback addresses are allowed. Port 0 picks a free port. Defaults
+	// to a unix socket.
+	LocalBind string `yaml:"localBind,omitempty"`
+
+	// PasswordAuth lets us log in to hosts with a password when none of your
+	// keys are accepted, asking for it each time we connect. It's off by
+	// default, as keys are the safer bet. Only lazydocker's built-in ssh client
+	// can ask for a password, so turning this on means we use that rather than
+	// the ssh binary, losing what only the binary supports, e.g. jump hosts
+	// and the rest of your ~/.ssh/config.
+	PasswordAuth bool `yaml:"passwordAuth,omitempty"`
+}
+
 // CustomCommands contains the custom commands that you might want to use on any
 // given service or container
 type CustomCommands struct {
@@ -300,6 +464,11 @@ type CustomCommand struct {
 	InternalFunction func() error `yaml:"-"`
 }
 
+// MouseEnabled is whether to listen for clicks and the mouse wheel
+func (c GuiConfig) MouseEnabled() bool {
+	return !c.IgnoreMouseEvents && !c.LegacyIgnoreMouseEvents
+}
+
 // GetDefaultConfig returns the application default configuration NOTE (to
 // contributors, not users): do not default a boolean to true, because false is
 // the boolean zero value and this will be ignored when parsing the user's
@@ -317,16 +486,15 @@ func GetDefaultConfig() UserConfig {
 			ScrollPastBottom:  false,
 			IgnoreMouseEvents: false,
 			Theme: ThemeConfig{
-				ActiveBorderColor:   []string{"green", "bold"},
-				InactiveBorderColor: []string{"default"},
-				OptionsTextColor:    []string{"blue"},
+				Preset: "dark",
 			},
 			ShowAllContainers:    false,
 			ReturnImmediately:    false,
 			WrapMainPanel:        false,
 			LegacySortContainers: false,
 		},
-		ConfirmOnQuit: false,
+		ConfirmOnQuit:      false,
+		ConfirmDestructive: true,
 		CommandTemplates: CommandTemplatesConfig{
 			DockerCompose:            "docker-compose",
 			RestartService:           "{{ .DockerCompose }} restart {{ .Service.Name }}",
@@ -342,6 +510,9 @@ func GetDefaultConfig() UserConfig {
 			ContainerLogs:            "docker logs --timestamps --follow --since=60m {{ .Container.ID }}",
 			ViewContainerLogs:        "docker logs --timestamps --follow --since=60m {{ .Container.ID }}",
 			ServiceTop:               "{{ .DockerCompose }} top {{ .Service.Name }}",
+			ComposeProjectUp:         "docker compose -p {{ .Project.Name }} up -d",
+			ComposeProjectDown:       "docker compose -p {{ .Project.Name }} down",
+			ComposeProjectRestart:    "docker compose -p {{ .Project.Name }} restart",
 		},
 		CustomCommands: CustomCommands{
 			Containers: []CustomCommand{
@@ -404,6 +575,14 @@ func GetDefaultConfig() UserConfig {
 		OS: GetPlatformDefaultConfig(),
 		Update: UpdateConfig{
 			DockerRefreshInterval: time.Millisecond * 100,
+			ContainerCacheTTL:     time.Second,
+			ContainerDetailsTTL:   time.Second * 10,
+			Panels: PanelRefreshConfig{
+				Project:  time.Second,
+				Volumes:  time.Second * 2,
+				Networks: time.Second * 2,
+			},
+			SSHMultiplier: 5,
 		},
 		Stats: StatsConfig{
 			MaxDuration: duration,
@@ -420,6 +599,14 @@ func GetDefaultConfig() UserConfig {
 				},
 			},
 		},
+		SSH: SSHConfig{
+			Options:           []string{},
+			Binary:            "ssh",
+			IdentitiesOnly:    false,
+			KeepAliveInterval: 30 * time.Second,
+			KeepAliveCountMax: 3,
+		},
+		VolumeBrowserImage: "busybox:latest",
 	}
 }
 
@@ -434,6 +621,8 @@ type AppConfig struct {
 	UserConfig  *UserConfig
 	ConfigDir   string
 	ProjectDir  string
+	// AppState is what we remembered about the ui from the last run
+	AppState *AppState
 }
 
 // NewAppConfig makes a new app config
@@ -448,6 +637,16 @@ func NewAppConfig(name, version, commit, date string, buildSource string, debugg
 		return nil, err
 	}
 
+	if err := userConfig.Gui.Theme.Validate(); err != nil {
+		return nil, err
+	}
+
+	appState, err := loadAppState(configDir)
+	if err != nil {
+		// a state file we can't read just means starting afresh
+		appState = &AppState{}
+	}
+
 	// Pass compose files as individual -f flags to docker-compose
 	if len(composeFiles) > 0 {
 		userConfig.CommandTemplates.DockerCompose += " -f " + strings.Join(composeFiles, " -f ")
@@ -463,6 +662,7 @@ func NewAppConfig(name, version, commit, date string, buildSource string, debugg
 		UserConfig:  userConfig,
 		ConfigDir:   configDir,
 		ProjectDir:  projectDir,
+		AppState:    appState,
 	}
 
 	return appConfig, nil
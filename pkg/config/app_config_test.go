@@ -1,8 +1,11 @@
 package config
 
 import (
+	"io/ioutil"
 	"os"
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/jesseduffield/yaml"
 )
@@ -98,3 +101,163 @@ func TestWritingToConfigFile(t *testing.T) {
 	// modifying an existing file that already has 'ConfirmOnQuit'
 	testFn(conf, false, t)
 }
+
+func TestUpdateConfigRefreshInterval(t *testing.T) {
+	type scenario struct {
+		testName      string
+		panelInterval time.Duration
+		overSSH       bool
+		expected      time.Duration
+	}
+
+	config := UpdateConfig{DockerRefreshInterval: time.Millisecond * 100, SSHMultiplier: 5}
+
+	scenarios := []scenario{
+		{testName: "The panel's own interval", panelInterval: time.Second, expected: time.Second},
+		{testName: "The docker refresh interval when the panel's isn't set", expected: time.Millisecond * 100},
+		{testName: "Stretched over ssh", panelInterval: time.Second, overSSH: true, expected: time.Second * 5},
+		{testName: "Stretched over ssh when the panel's isn't set", overSSH: true, expected: time.Millisecond * 500},
+	}
+
+	for _, s := range scenarios {
+		s := s
+		t.Run(s.testName, func(t *testing.T) {
+			actual := config.RefreshInterval(s.panelInterval, s.overSSH)
+			if actual != s.expected {
+				t.Errorf("Expected %s, got %s", s.expected, actual)
+			}
+		})
+	}
+
+	actual := UpdateConfig{}.RefreshInterval(0, true)
+	if actual != time.Millisecond*100 {
+		t.Errorf("Expected an unset interval not to refresh flat out, got %s", actual)
+	}
+}
+
+func TestThemeConfigResolved(t *testing.T) {
+	theme := ThemeConfig{Preset: "light", FailedColor: []string{"red", "underline"}}.Resolved()
+
+	if !reflect.DeepEqual(theme.FailedColor, []string{"red", "underline"}) {
+		t.Errorf("Expected the theme's own failed color, got %v", theme.FailedColor)
+	}
+	if !reflect.DeepEqual(theme.StoppedColor, ThemePresets["light"].StoppedColor) {
+		t.Errorf("Expected the light preset's stopped color, got %v", theme.StoppedColor)
+	}
+	if len(ThemePresets["light"].FailedColor) != 1 {
+		t.Errorf("Expected resolving a theme to leave its preset alone, got %v", ThemePresets["light"].FailedColor)
+	}
+
+	theme = GetDefaultConfig().Gui.Theme.Resolved()
+	if !reflect.DeepEqual(theme.ActiveBorderColor, []string{"green", "bold"}) {
+		t.Errorf("Expected the default theme to have the usual active border color, got %v", theme.ActiveBorderColor)
+	}
+}
+
+func TestThemeConfigValidate(t *testing.T) {
+	type scenario struct {
+		testName string
+		theme    ThemeConfig
+		expected string
+	}
+
+	scenarios := []scenario{
+		{testName: "The default theme", theme: GetDefaultConfig().Gui.Theme},
+		{testName: "A preset with a color of its own", theme: ThemeConfig{Preset: "high-contrast", RunningColor: []string{"green", "reverse"}}},
+		{
+			testName: "An unknown preset and colors",
+			theme:    ThemeConfig{Preset: "solarized", RunningColor: []string{"lime"}, LogErrorColor: []string{"red", "blink"}},
+			expected: "invalid theme config:\n" +
+				`  unknown preset "solarized": expected one of dark, high-contrast, light` + "\n" +
+				`  logErrorColor: unknown color "blink"` + "\n" +
+				`  runningColor: unknown color "lime"`,
+		},
+		{
+			testName: "An unknown selected line color",
+			theme:    ThemeConfig{SelectedLineBgColor: []string{"grey"}},
+			expected: "invalid theme config:\n" + `  selectedLineBgColor: unknown color "grey"`,
+		},
+	}
+
+	for _, s := range scenarios {
+		s := s
+		t.Run(s.testName, func(t *testing.T) {
+			err := s.theme.Validate()
+			switch {
+			case s.expected == "" && err != nil:
+				t.Errorf("Unexpected error: %s", err)
+			case s.expected != "" && (err == nil || err.Error() != s.expected):
+				t.Errorf("Expected error %q, got %v", s.expected, err)
+			}
+		})
+	}
+}
+
+func TestAppStateRoundTrip(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lazydocker-state")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	state, err := loadAppState(dir)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if !reflect.DeepEqual(state, &AppState{}) {
+		t.Errorf("Expected an empty state with no state file, got %+v", state)
+	}
+
+	conf := &AppConfig{ConfigDir: dir}
+	saved := &AppState{
+		FocusedPanel:    "images",
+		Selected:        map[string]string{"images": "sha256:abc", "volumes": "pgdata"},
+		ContainerFilter: "status=running",
+		HideStopped:     true,
+		ContainerSort:   SortState{Field: "cpu", Descending: true},
+	}
+	if err := conf.SaveAppState(saved); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	loaded, err := loadAppState(dir)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if !reflect.DeepEqual(loaded, saved) {
+		t.Errorf("Got %+v, Expected %+v", loaded, saved)
+	}
+
+	if err := ioutil.WriteFile(conf.AppStateFilename(), []byte("selected: [not, a, map]"), 0644); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if _, err := loadAppState(dir); err == nil {
+		t.Errorf("Expected an error for a broken state file")
+	}
+}
+
+func TestGuiConfigMouseEnabled(t *testing.T) {
+	type scenario struct {
+		testName string
+		content  string
+		expected bool
+	}
+
+	scenarios := []scenario{
+		{"By default", "", true},
+		{"Ignoring mouse events", "gui:\n  ignoreMouseEvents: true\n", false},
+		{"The option's old name", "gui:\n  mouseEvents: true\n", false},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.testName, func(t *testing.T) {
+			config := GetDefaultConfig()
+			if err := yaml.Unmarshal([]byte(s.content), &config); err != nil {
+				t.Fatalf("Unexpected error %v", err)
+			}
+			if config.Gui.MouseEnabled() != s.expected {
+				t.Errorf("Expected MouseEnabled to be %v", s.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/jesseduffield/yaml"
+)
+
+// AppState is what we remember about the ui between runs, so that lazydocker
+// opens up where you left it. Anything that no longer exists by the next run,
+// like a removed container, is left out when it's restored.
+type AppState struct {
+	// FocusedPanel is the side panel that was focused, e.g. containers
+	FocusedPanel string `yaml:"focusedPanel,omitempty"`
+
+	// Selected holds what was selected in each side panel, by panel: the ID
+	// of containers, images and networks, and the name of services and
+	// volumes
+	Selected map[string]string `yaml:"selected,omitempty"`
+
+	// ContainerFilter is what the containers panel was filtered by
+	ContainerFilter string `yaml:"containerFilter,omitempty"`
+
+	// HideStopped is whether stopped containers were hidden
+	HideStopped bool `yaml:"hideStopped,omitempty"`
+
+	// EventFilter is what the events tab was filtered by
+	EventFilter string `yaml:"eventFilter,omitempty"`
+
+	// ContainerSort and ImageSort are how those panels were sorted, if they'd
+	// been sorted at all
+	ContainerSort SortState `yaml:"containerSort,omitempty"`
+	ImageSort     SortState `yaml:"imageSort,omitempty"`
+
+	// RecentCommands are the commands last run from the command palette, most
+	// recent first, e.g. containers.restart
+	RecentCommands []string `yaml:"recentCommands,omitempty"`
+}
+
+// SortState is how a list was sorted: the field, and which way round
+type SortState struct {
+	Field      string `yaml:"field,omitempty"`
+	Descending bool   `yaml:"descending,omitempty"`
+}
+
+// AppStateFilename returns the filename of the state file
+func (c *AppConfig) AppStateFilename() string {
+	return filepath.Join(c.ConfigDir, "state.yml")
+}
+
+// loadAppState reads the state file in configDir, returning an empty state if
+// there isn't one yet
+func loadAppState(configDir string) (*AppState, error) {
+	state := &AppState{}
+
+	content, err := ioutil.ReadFile(filepath.Join(configDir, "state.yml"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return state, nil
+		}
+		return nil, err
+	}
+
+	if err := yaml.Unmarshal(content, state); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// SaveAppState writes the state file. It writes to a temporary file first so
+// that a run that's cut short doesn't leave half a state file behind.
+func (c *AppConfig) SaveAppState(state *AppState) error {
+	content, err := yaml.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	fileName := c.AppStateFilename()
+	tempFileName := fileName + ".tmp"
+	if err := ioutil.WriteFile(tempFileName, content, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tempFileName, fileName)
+}
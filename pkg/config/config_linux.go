@@ -3,7 +3,8 @@ package config
 // GetPlatformDefaultConfig gets the defaults for the platform
 func GetPlatformDefaultConfig() OSConfig {
 	return OSConfig{
-		OpenCommand:     `sh -c "xdg-open {{filename}} >/dev/null"`,
-		OpenLinkCommand: `sh -c "xdg-open {{link}} >/dev/null"`,
+		OpenCommand:            `sh -c "xdg-open {{filename}} >/dev/null"`,
+		OpenLinkCommand:        `sh -c "xdg-open {{link}} >/dev/null"`,
+		CopyToClipboardCommand: `sh -c "if command -v wl-copy >/dev/null 2>&1; then wl-copy; else xclip -selection clipboard; fi"`,
 	}
 }
@@ -3,7 +3,8 @@ package config
 // GetPlatformDefaultConfig gets the defaults for the platform
 func GetPlatformDefaultConfig() OSConfig {
 	return OSConfig{
-		OpenCommand:     `cmd /c "start "" {{filename}}"`,
-		OpenLinkCommand: `cmd /c "start "" {{link}}"`,
+		OpenCommand:            `cmd /c "start "" {{filename}}"`,
+		OpenLinkCommand:        `cmd /c "start "" {{link}}"`,
+		CopyToClipboardCommand: "clip",
 	}
 }
@@ -0,0 +1,120 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jesseduffield/lazydocker/pkg/utils"
+)
+
+// ThemePresets are the themes you can start from with gui.theme.preset
+var ThemePresets = map[string]ThemeConfig{
+	"dark": {
+		ActiveBorderColor:   []string{"green", "bold"},
+		InactiveBorderColor: []string{"default"},
+		OptionsTextColor:    []string{"blue"},
+		SelectedLineBgColor: []string{"blue"},
+		RunningColor:        []string{"green"},
+		StoppedColor:        []string{"yellow"},
+		FailedColor:         []string{"red"},
+		LogErrorColor:       []string{"red"},
+		LogWarningColor:     []string{"yellow"},
+	},
+	// yellow is hard to make out on a white background
+	"light": {
+		ActiveBorderColor:   []string{"blue", "bold"},
+		InactiveBorderColor: []string{"black"},
+		OptionsTextColor:    []string{"blue"},
+		SelectedLineBgColor: []string{"cyan"},
+		RunningColor:        []string{"green"},
+		StoppedColor:        []string{"magenta"},
+		FailedColor:         []string{"red"},
+		LogErrorColor:       []string{"red"},
+		LogWarningColor:     []string{"magenta"},
+	},
+	// sticking to bold colors, which still stand out on a terminal with only
+	// the 8 basic ones
+	"high-contrast": {
+		ActiveBorderColor:   []string{"yellow", "bold"},
+		InactiveBorderColor: []string{"white"},
+		OptionsTextColor:    []string{"white", "bold"},
+		SelectedLineBgColor: []string{"blue"},
+		RunningColor:        []string{"green", "bold"},
+		StoppedColor:        []string{"yellow", "bold"},
+		FailedColor:         []string{"red", "bold", "reverse"},
+		LogErrorColor:       []string{"red", "bold"},
+		LogWarningColor:     []string{"yellow", "bold"},
+		LogDebugColor:       []string{"cyan"},
+	},
+}
+
+// Resolved fills in the colors the theme leaves out from its preset
+func (t ThemeConfig) Resolved() ThemeConfig {
+	preset, ok := ThemePresets[t.Preset]
+	if !ok {
+		preset = ThemePresets["dark"]
+	}
+
+	resolved := preset
+	resolved.Preset = t.Preset
+	for name, colors := range t.colors() {
+		if len(*colors) > 0 {
+			*resolved.colors()[name] = *colors
+		}
+	}
+	return resolved
+}
+
+// Validate checks the preset is one we have and every color is one we know
+func (t ThemeConfig) Validate() error {
+	problems := []string{}
+	if _, ok := ThemePresets[t.Preset]; !ok && t.Preset != "" {
+		problems = append(problems, fmt.Sprintf("unknown preset %q: expected one of %s", t.Preset, strings.Join(themePresetNames(), ", ")))
+	}
+
+	colors := t.colors()
+	names := []string{}
+	for name := range colors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, color := range *colors[name] {
+			if !utils.IsColorName(color) {
+				problems = append(problems, fmt.Sprintf("%s: unknown color %q", name, color))
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid theme config:\n  %s", strings.Join(problems, "\n  "))
+	}
+	return nil
+}
+
+// colors are the theme's colors, keyed by their names in the config
+func (t *ThemeConfig) colors() map[string]*[]string {
+	return map[string]*[]string{
+		"activeBorderColor":   &t.ActiveBorderColor,
+		"inactiveBorderColor": &t.InactiveBorderColor,
+		"optionsTextColor":    &t.OptionsTextColor,
+		"selectedLineBgColor": &t.SelectedLineBgColor,
+		"runningColor":        &t.RunningColor,
+		"stoppedColor":        &t.StoppedColor,
+		"failedColor":         &t.FailedColor,
+		"logErrorColor":       &t.LogErrorColor,
+		"logWarningColor":     &t.LogWarningColor,
+		"logInfoColor":        &t.LogInfoColor,
+		"logDebugColor":       &t.LogDebugColor,
+	}
+}
+
+func themePresetNames() []string {
+	names := []string{}
+	for name := range ThemePresets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
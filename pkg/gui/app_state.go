@@ -0,0 +1,134 @@
+package gui
+
+import (
+	"github.com/jesseduffield/gocui"
+	"github.com/jesseduffield/lazydocker/pkg/commands"
+	"github.com/jesseduffield/lazydocker/pkg/config"
+)
+
+// appState is what we remembered about the ui from the last run
+func (gui *Gui) appState() *config.AppState {
+	if gui.Config.AppState == nil {
+		gui.Config.AppState = &config.AppState{}
+	}
+	return gui.Config.AppState
+}
+
+// restoreAppState picks up the filters and sort orders from the last run. What
+// was selected in each panel is restored once the panel's first loaded, if
+// it's still there.
+func (gui *Gui) restoreAppState() {
+	state := gui.appState()
+
+	if args, err := commands.ParseContainerFilter(state.ContainerFilter); err == nil && state.ContainerFilter != "" {
+		gui.State.Panels.Containers.Filter = state.ContainerFilter
+		gui.DockerCommand.SetContainerFilter(args)
+	}
+	gui.DockerCommand.ShowExited = !state.HideStopped
+
+	if filter, err := commands.ParseEventFilter(state.EventFilter); err == nil {
+		gui.State.Panels.Project.EventFilter = filter
+		gui.State.Panels.Project.EventFilterText = state.EventFilter
+	}
+
+	if order, ok := restoredSortOrder(state.ContainerSort, commands.ContainerSortFields); ok {
+		gui.DockerCommand.SetContainerSort(order)
+	}
+	if order, ok := restoredSortOrder(state.ImageSort, commands.ImageSortFields); ok {
+		gui.DockerCommand.SetImageSort(order)
+	}
+
+	gui.State.PendingSelections = map[string]string{}
+	for panel, key := range state.Selected {
+		gui.State.PendingSelections[panel] = key
+	}
+}
+
+func restoredSortOrder(sort config.SortState, fields []commands.SortField) (commands.SortOrder, bool) {
+	for _, field := range fields {
+		if string(field) == sort.Field {
+			return commands.SortOrder{Field: field, Descending: sort.Descending}, true
+		}
+	}
+	return commands.SortOrder{}, false
+}
+
+// restoreSelection selects what was selected in the panel last run, the first
+// time the panel's loaded, given the keys of the panel's lines. If it's gone
+// we leave the selection as it is.
+func (gui *Gui) restoreSelection(v *gocui.View, keys []string, selectedLine *int) error {
+	key, ok := gui.State.PendingSelections[v.Name()]
+	if !ok {
+		return nil
+	}
+	delete(gui.State.PendingSelections, v.Name())
+
+	for i, k := range keys {
+		if k != "" && k == key {
+			*selectedLine = i
+			return gui.focusPoint(0, i, len(keys), v)
+		}
+	}
+	return nil
+}
+
+// saveAppState remembers the focused panel, what's selected in each panel,
+// and the filters and sort orders, for the next run
+func (gui *Gui) saveAppState() error {
+	state := gui.appState()
+
+	if panel := gui.focusedSidePanelName(); panel != "" {
+		state.FocusedPanel = panel
+	}
+
+	state.Selected = map[string]string{}
+	if container, err := gui.getSelectedContainer(); err == nil {
+		state.Selected["containers"] = container.ID
+	}
+	if service, err := gui.getSelectedService(); err == nil {
+		state.Selected["services"] = service.Name
+	}
+	if image, err := gui.getSelectedImage(); err == nil {
+		state.Selected["images"] = image.ID
+	}
+	if volume, err := gui.getSelectedVolume(); err == nil {
+		state.Selected["volumes"] = volume.Name
+	}
+	if network, err := gui.getSelectedNetwork(); err == nil {
+		state.Selected["networks"] = network.ID
+	}
+
+	state.ContainerFilter = gui.State.Panels.Containers.Filter
+	state.HideStopped = !gui.DockerCommand.ShowExited
+	state.EventFilter = gui.State.Panels.Project.EventFilterText
+
+	containerSort := gui.DockerCommand.ContainerSort()
+	state.ContainerSort = config.SortState{Field: string(containerSort.Field), Descending: containerSort.Descending}
+	imageSort := gui.DockerCommand.ImageSort()
+	state.ImageSort = config.SortState{Field: string(imageSort.Field), Descending: imageSort.Descending}
+
+	return gui.Config.SaveAppState(state)
+}
+
+// focusedSidePanelName is the side panel that's focused, or that the main
+// panel or a popup was opened from. It's blank if we can't tell.
+func (gui *Gui) focusedSidePanelName() string {
+	currentView := gui.g.CurrentView()
+	if currentView == nil {
+		return ""
+	}
+
+	name := currentView.Name()
+	if name == "main" && currentView.ParentView != nil {
+		name = currentView.ParentView.Name()
+	} else if gui.isPopupPanel(name) {
+		name = gui.peekPreviousView()
+	}
+
+	for _, viewName := range gui.CyclableViews {
+		if viewName == name {
+			return name
+		}
+	}
+	return ""
+}
@@ -1,6 +1,7 @@
 package gui
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/jesseduffield/gocui"
@@ -11,6 +12,9 @@ type appStatus struct {
 	name       string
 	statusType string
 	duration   int
+	// started is set for waiting statuses that show how long they've been
+	// waiting
+	started time.Time
 }
 
 type statusManager struct {
@@ -37,21 +41,75 @@ func (m *statusManager) addWaitingStatus(name string) {
 	m.statuses = append([]appStatus{newStatus}, m.statuses...)
 }
 
+// addTimedWaitingStatus is addWaitingStatus for something that can take a
+// while, showing how long it's been going
+func (m *statusManager) addTimedWaitingStatus(name string) {
+	m.addWaitingStatus(name)
+	m.statuses[0].started = time.Now()
+}
+
+func (m *statusManager) addToastStatus(name string) {
+	m.removeStatus(name)
+	newStatus := appStatus{
+		name:       name,
+		statusType: "toast",
+		duration:   0,
+	}
+	m.statuses = append([]appStatus{newStatus}, m.statuses...)
+}
+
 func (m *statusManager) getStatusString() string {
 	if len(m.statuses) == 0 {
 		return ""
 	}
 	topStatus := m.statuses[0]
 	if topStatus.statusType == "waiting" {
+		if !topStatus.started.IsZero() {
+			elapsed := time.Since(topStatus.started) / time.Second * time.Second
+			return fmt.Sprintf("%s %s %s", topStatus.name, elapsed, utils.Loader())
+		}
 		return topStatus.name + " " + utils.Loader()
 	}
 	return topStatus.name
 }
 
+// toastDuration is how long a toast stays in the status bar
+const toastDuration = 2 * time.Second
+
+// showToast shows a message in the status bar for a moment, for letting the
+// user know something worked without them having to close a popup
+func (gui *Gui) showToast(message string) {
+	gui.statusManager.addToastStatus(message)
+	if err := gui.renderString(gui.g, "appStatus", message); err != nil {
+		gui.Log.Warn(err)
+	}
+
+	go func() {
+		time.Sleep(toastDuration)
+		gui.statusManager.removeStatus(message)
+		// laying out again hides the status bar now it's empty
+		gui.g.Update(func(*gocui.Gui) error { return nil })
+	}()
+}
+
 // WithWaitingStatus wraps a function and shows a waiting status while the function is still executing
 func (gui *Gui) WithWaitingStatus(name string, f func() error) error {
+	return gui.withWaitingStatus(name, false, f)
+}
+
+// WithTimedWaitingStatus is WithWaitingStatus for something that can take a
+// while, like stopping a container, showing how long it's been going
+func (gui *Gui) WithTimedWaitingStatus(name string, f func() error) error {
+	return gui.withWaitingStatus(name, true, f)
+}
+
+func (gui *Gui) withWaitingStatus(name string, timed bool, f func() error) error {
 	go func() {
-		gui.statusManager.addWaitingStatus(name)
+		if timed {
+			gui.statusManager.addTimedWaitingStatus(name)
+		} else {
+			gui.statusManager.addWaitingStatus(name)
+		}
 
 		defer func() {
 			gui.statusManager.removeStatus(name)
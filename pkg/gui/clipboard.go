@@ -0,0 +1,70 @@
+package gui
+
+import (
+	"fmt"
+
+	"github.com/jesseduffield/gocui"
+	"github.com/jesseduffield/lazydocker/pkg/commands"
+)
+
+// copyToClipboard copies text to the clipboard and shows toast in the status
+// bar. If that fails, e.g. because there's no clipboard over ssh, the error is shown
+// once whatever's focused now has been dealt with, e.g. a menu closing.
+func (gui *Gui) copyToClipboard(text string, toast string) error {
+	if err := gui.OSCommand.CopyToClipboard(text); err != nil {
+		gui.g.Update(func(g *gocui.Gui) error {
+			return gui.createErrorPanel(g, err.Error())
+		})
+		return nil
+	}
+	gui.showToast(toast)
+	return nil
+}
+
+// copyID copies the ID, or its short form as docker ps shows it
+func (gui *Gui) copyID(id string, short bool) error {
+	if short {
+		id = commands.ShortID(id)
+	}
+	return gui.copyToClipboard(id, fmt.Sprintf(gui.Tr.CopiedToClipboardValue, id))
+}
+
+func (gui *Gui) handleContainerCopyID(short bool) func(*gocui.Gui, *gocui.View) error {
+	return func(g *gocui.Gui, v *gocui.View) error {
+		container, err := gui.getSelectedContainer()
+		if err != nil {
+			return nil
+		}
+		return gui.copyID(container.ID, short)
+	}
+}
+
+func (gui *Gui) handleServiceCopyID(short bool) func(*gocui.Gui, *gocui.View) error {
+	return func(g *gocui.Gui, v *gocui.View) error {
+		service, err := gui.getSelectedService()
+		if err != nil || service.Container == nil {
+			return nil
+		}
+		return gui.copyID(service.Container.ID, short)
+	}
+}
+
+func (gui *Gui) handleImageCopyID(short bool) func(*gocui.Gui, *gocui.View) error {
+	return func(g *gocui.Gui, v *gocui.View) error {
+		image, err := gui.getSelectedImage()
+		if err != nil {
+			return nil
+		}
+		return gui.copyID(image.ID, short)
+	}
+}
+
+// handleVolumeCopyName copies the volume's name, which is what docker knows it
+// by, having no other ID
+func (gui *Gui) handleVolumeCopyName(g *gocui.Gui, v *gocui.View) error {
+	volume, err := gui.getSelectedVolume()
+	if err != nil {
+		return nil
+	}
+	return gui.copyToClipboard(volume.Name, fmt.Sprintf(gui.Tr.CopiedToClipboardValue, volume.Name))
+}
@@ -0,0 +1,324 @@
+package gui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/jesseduffield/gocui"
+	"github.com/jesseduffield/lazydocker/pkg/commands"
+	"github.com/jesseduffield/lazydocker/pkg/utils"
+)
+
+// recentCommandsLimit is how many of the commands last run from the palette
+// it keeps at the top
+const recentCommandsLimit = 5
+
+// paletteCommand is something the command palette can run: a keybinding, and
+// the panel to run it in
+type paletteCommand struct {
+	// ID is the binding as the keybinding config names it, e.g.
+	// containers.restart
+	ID          string
+	Key         string
+	Description string
+	Panel       string
+	// Disabled is for the commands that would change something, in read-only
+	// mode
+	Disabled bool
+	// Positions are the runes of the description that matched the query
+	Positions []int
+
+	score    int
+	binding  *Binding
+	viewName string
+}
+
+type commandPaletteState struct {
+	// Commands are all the commands the palette was opened with, and Results
+	// those that match the query. SelectedLine is the result enter runs.
+	Query        string
+	Commands     []paletteCommand
+	Results      []paletteCommand
+	SelectedLine int
+}
+
+type paletteCommandRow struct {
+	command  paletteCommand
+	selected bool
+}
+
+// GetDisplayStrings is a function.
+func (r *paletteCommandRow) GetDisplayStrings(isFocused bool) []string {
+	marker := " "
+	if r.selected {
+		marker = utils.ColoredString(">", color.FgCyan)
+	}
+
+	if r.command.Disabled {
+		return []string{
+			marker,
+			utils.ColoredString(r.command.Key, color.FgHiBlack),
+			utils.ColoredString(r.command.Description, color.FgHiBlack),
+			utils.ColoredString(r.command.Panel, color.FgHiBlack),
+		}
+	}
+	return []string{
+		marker,
+		utils.ColoredString(r.command.Key, color.FgCyan),
+		commands.HighlightPositions(r.command.Description, r.command.Positions),
+		utils.ColoredString(r.command.Panel, color.FgMagenta),
+	}
+}
+
+// handleCommandPalette opens the command palette, which lists every command
+// there's a key for, narrowing them down as you type. The commands for the
+// focused panel come first, then the global ones, then those for the other
+// panels, and the ones you ran last come before all of them. Enter runs the
+// selected command, focusing its panel first if need be.
+func (gui *Gui) handleCommandPalette(g *gocui.Gui, v *gocui.View) error {
+	if v == nil || gui.popupPanelFocused() {
+		return nil
+	}
+
+	gui.State.Panels.Palette = &commandPaletteState{Commands: gui.paletteCommands(v)}
+	// the commands take over the main panel, so whatever was there has to be
+	// rendered again once we're done
+	gui.State.Panels.Main.ObjectKey = ""
+
+	gui.onNewPopupPanel()
+	paletteView, err := gui.prepareConfirmationPanel(v, gui.Tr.CommandPaletteTitle, "", false)
+	if err != nil {
+		return err
+	}
+	paletteView.Editable = true
+	paletteView.Editor = gocui.EditorFunc(func(paletteView *gocui.View, key gocui.Key, ch rune, mod gocui.Modifier) {
+		gocui.DefaultEditor.Edit(paletteView, key, ch, mod)
+		if err := gui.filterPalette(gui.trimmedContent(paletteView)); err != nil {
+			gui.Log.Error(err)
+		}
+	})
+
+	mainView := gui.getMainView()
+	mainView.Tabs = []string{gui.Tr.CommandsTitle}
+	mainView.TabIndex = 0
+	mainView.Autoscroll = false
+	mainView.Wrap = false
+
+	bindings := []struct {
+		key     gocui.Key
+		handler func(*gocui.Gui, *gocui.View) error
+	}{
+		{key: gocui.KeyArrowUp, handler: gui.handlePalettePrevCommand},
+		{key: gocui.KeyCtrlP, handler: gui.handlePalettePrevCommand},
+		{key: gocui.KeyArrowDown, handler: gui.handlePaletteNextCommand},
+		{key: gocui.KeyCtrlN, handler: gui.handlePaletteNextCommand},
+		{key: gocui.KeyEnter, handler: gui.handlePaletteRun},
+		{key: gocui.KeyEsc, handler: gui.handlePaletteClose},
+	}
+	for _, binding := range bindings {
+		if err := g.SetKeybinding("confirmation", nil, binding.key, gocui.ModNone, binding.handler); err != nil {
+			return err
+		}
+	}
+	return gui.filterPalette("")
+}
+
+// paletteCommands gathers up what the palette can run from v: the bindings
+// for v itself, and for the panel it was opened from if it's the main panel,
+// the global bindings, and the bindings for the other side panels
+func (gui *Gui) paletteCommands(v *gocui.View) []paletteCommand {
+	viewNames := []string{v.Name()}
+	if v.Name() == "main" && v.ParentView != nil {
+		viewNames = append(viewNames, v.ParentView.Name())
+	}
+	viewNames = append(viewNames, "")
+L:
+	for _, viewName := range gui.CyclableViews {
+		for _, included := range viewNames {
+			if viewName == included {
+				continue L
+			}
+		}
+		viewNames = append(viewNames, viewName)
+	}
+
+	titles := map[string]string{
+		"":           gui.Tr.GlobalTitle,
+		"main":       gui.Tr.MainTitle,
+		"project":    gui.Tr.ProjectTitle,
+		"services":   gui.Tr.ServicesTitle,
+		"containers": gui.Tr.ContainersTitle,
+		"images":     gui.Tr.ImagesTitle,
+		"volumes":    gui.Tr.VolumesTitle,
+		"networks":   gui.Tr.NetworksTitle,
+	}
+
+	// the keybinding config was validated at startup, so there's no error
+	bindings, _ := gui.GetKeybindings()
+	paletteCommands := []paletteCommand{}
+	for _, viewName := range viewNames {
+		for _, binding := range bindings {
+			if binding.ViewName != viewName || binding.Name == "" || binding.Description == "" || binding.Name == "commandPalette" {
+				continue
+			}
+			runIn := viewName
+			if viewName == "" {
+				runIn = v.Name()
+			}
+			paletteCommands = append(paletteCommands, paletteCommand{
+				ID:          configViewName(viewName) + "." + binding.Name,
+				Key:         binding.GetKey(),
+				Description: binding.Description,
+				Panel:       titles[viewName],
+				Disabled:    gui.readOnly() && isMutating(binding),
+				binding:     binding,
+				viewName:    runIn,
+			})
+		}
+	}
+	return paletteCommands
+}
+
+// filterPaletteCommands returns the commands whose descriptions match query
+// fuzzily, best first. Without a query it's all of them in order, except that
+// those in recent, the IDs of the commands run last, come first.
+func filterPaletteCommands(query string, paletteCommands []paletteCommand, recent []string) []paletteCommand {
+	recency := map[string]int{}
+	for i, id := range recent {
+		if _, ok := recency[id]; !ok {
+			recency[id] = i
+		}
+	}
+	rank := func(command paletteCommand) int {
+		if index, ok := recency[command.ID]; ok {
+			return index
+		}
+		return len(recent)
+	}
+
+	query = strings.TrimSpace(query)
+	results := []paletteCommand{}
+	for _, command := range paletteCommands {
+		command.score, command.Positions = 0, nil
+		if query != "" {
+			score, positions, ok := commands.FuzzyMatch(query, command.Description)
+			if !ok {
+				continue
+			}
+			command.score, command.Positions = score, positions
+		}
+		results = append(results, command)
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].score != results[j].score {
+			return results[i].score > results[j].score
+		}
+		return rank(results[i]) < rank(results[j])
+	})
+	return results
+}
+
+func (gui *Gui) filterPalette(query string) error {
+	state := gui.State.Panels.Palette
+	state.Query = query
+	state.Results = filterPaletteCommands(query, state.Commands, gui.appState().RecentCommands)
+	state.SelectedLine = 0
+	return gui.renderPaletteCommands()
+}
+
+func (gui *Gui) renderPaletteCommands() error {
+	state := gui.State.Panels.Palette
+	mainView := gui.getMainView()
+	if err := mainView.SetOrigin(0, 0); err != nil {
+		return err
+	}
+
+	if len(state.Results) == 0 {
+		return gui.setViewContent(gui.g, mainView, fmt.Sprintf(gui.Tr.NoCommandMatches, state.Query))
+	}
+
+	rows := make([]*paletteCommandRow, len(state.Results))
+	for i, command := range state.Results {
+		rows[i] = &paletteCommandRow{command: command, selected: i == state.SelectedLine}
+	}
+	list, err := utils.RenderList(rows)
+	if err != nil {
+		return err
+	}
+	if err := gui.setViewContent(gui.g, mainView, list); err != nil {
+		return err
+	}
+	return gui.focusPoint(0, state.SelectedLine, len(rows), mainView)
+}
+
+func (gui *Gui) handlePaletteNextCommand(g *gocui.Gui, v *gocui.View) error {
+	state := gui.State.Panels.Palette
+	gui.changeSelectedLine(&state.SelectedLine, len(state.Results), false)
+	return gui.renderPaletteCommands()
+}
+
+func (gui *Gui) handlePalettePrevCommand(g *gocui.Gui, v *gocui.View) error {
+	state := gui.State.Panels.Palette
+	gui.changeSelectedLine(&state.SelectedLine, len(state.Results), true)
+	return gui.renderPaletteCommands()
+}
+
+// handlePaletteClose closes the palette, putting back what the main panel
+// showed before
+func (gui *Gui) handlePaletteClose(g *gocui.Gui, v *gocui.View) error {
+	gui.State.Panels.Palette = &commandPaletteState{}
+	if err := gui.closeConfirmationPrompt(g); err != nil {
+		return err
+	}
+
+	// going back to a side panel renders its item in the main panel again,
+	// but going back to the main panel doesn't
+	if currentView := g.CurrentView(); currentView != nil && currentView.Name() == "main" {
+		return gui.newLineFocused(currentView.ParentView)
+	}
+	return nil
+}
+
+// handlePaletteRun runs the selected command, remembering it so that it's at
+// the top next time
+func (gui *Gui) handlePaletteRun(g *gocui.Gui, v *gocui.View) error {
+	state := gui.State.Panels.Palette
+	if state.SelectedLine < 0 || state.SelectedLine >= len(state.Results) {
+		return nil
+	}
+	command := state.Results[state.SelectedLine]
+
+	if !command.Disabled {
+		gui.rememberPaletteCommand(command.ID)
+	}
+	if err := gui.handlePaletteClose(g, v); err != nil {
+		return err
+	}
+
+	view, err := g.View(command.viewName)
+	if err != nil {
+		return nil
+	}
+	if currentView := g.CurrentView(); currentView != view {
+		if err := gui.switchFocus(g, currentView, view, false); err != nil {
+			return err
+		}
+	}
+	return command.binding.Handler(g, view)
+}
+
+// rememberPaletteCommand puts the command with this ID at the front of the
+// recent commands, which are saved with the rest of the app state
+func (gui *Gui) rememberPaletteCommand(id string) {
+	state := gui.appState()
+	recent := []string{id}
+	for _, other := range state.RecentCommands {
+		if other != id && len(recent) < recentCommandsLimit {
+			recent = append(recent, other)
+		}
+	}
+	state.RecentCommands = recent
+}
@@ -0,0 +1,70 @@
+package gui
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilterPaletteCommands(t *testing.T) {
+	paletteCommands := []paletteCommand{
+		{ID: "containers.stop", Description: "stop"},
+		{ID: "containers.restart", Description: "restart"},
+		{ID: "containers.remove", Description: "remove"},
+		{ID: "universal.quit", Description: "quit"},
+	}
+	ids := func(results []paletteCommand) []string {
+		ids := []string{}
+		for _, result := range results {
+			ids = append(ids, result.ID)
+		}
+		return ids
+	}
+
+	type scenario struct {
+		testName string
+		query    string
+		recent   []string
+		expected []string
+	}
+
+	scenarios := []scenario{
+		{
+			testName: "Everything, in order",
+			expected: []string{"containers.stop", "containers.restart", "containers.remove", "universal.quit"},
+		},
+		{
+			testName: "The recent commands first",
+			recent:   []string{"universal.quit", "containers.remove", "images.pull"},
+			expected: []string{"universal.quit", "containers.remove", "containers.stop", "containers.restart"},
+		},
+		{
+			testName: "Only the matches, best first",
+			query:    "re",
+			expected: []string{"containers.restart", "containers.remove"},
+		},
+		{
+			testName: "Recent commands win between matches that are as good",
+			query:    "re",
+			recent:   []string{"containers.remove"},
+			expected: []string{"containers.remove", "containers.restart"},
+		},
+		{
+			testName: "No matches",
+			query:    "zzz",
+			expected: []string{},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.testName, func(t *testing.T) {
+			assert.Equal(t, s.expected, ids(filterPaletteCommands(s.query, paletteCommands, s.recent)))
+		})
+	}
+
+	// the matched runes are highlighted
+	results := filterPaletteCommands("rst", paletteCommands, nil)
+	if assert.Len(t, results, 1) {
+		assert.Equal(t, []int{0, 2, 3}, results[0].Positions)
+	}
+}
@@ -0,0 +1,234 @@
+package gui
+
+import (
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/jesseduffield/gocui"
+	"github.com/jesseduffield/lazydocker/pkg/commands"
+	"github.com/jesseduffield/lazydocker/pkg/utils"
+)
+
+// containerRow is a line in the containers panel: either the heading of a
+// compose project, or a container. Containers from a compose project are shown
+// under its heading, unless it's collapsed.
+type containerRow struct {
+	project   *commands.ComposeProject
+	container *commands.Container
+	collapsed bool
+	// showMarks adds a column saying whether the container is marked for a
+	// bulk action, which we only bother with when something is
+	showMarks bool
+	marked    bool
+}
+
+// GetDisplayStrings is a function.
+func (r *containerRow) GetDisplayStrings(isFocused bool) []string {
+	var displayStrings []string
+	if r.project != nil {
+		arrow := "▼"
+		if r.collapsed {
+			arrow = "▶"
+		}
+		name := utils.ColoredString(arrow+" "+r.project.Name, color.FgCyan)
+		displayStrings = []string{r.project.GetDisplayStatus(), r.project.GetDisplaySubstatus(), name, "", "", "", ""}
+	} else {
+		displayStrings = r.container.GetDisplayStrings(isFocused)
+		if r.container.ProjectName != "" {
+			displayStrings[2] = "  " + displayStrings[2]
+		}
+	}
+
+	if !r.showMarks {
+		return displayStrings
+	}
+	mark := " "
+	if r.marked {
+		mark = utils.ColoredString("*", color.FgYellow)
+	}
+	return append([]string{mark}, displayStrings...)
+}
+
+// getContainerRows lays out the containers panel. A compose project's heading
+// goes where its first container would be, with the rest of its containers
+// pulled up underneath.
+func (gui *Gui) getContainerRows() []*containerRow {
+	projects, _ := commands.GroupContainersByProject(gui.DockerCommand.DisplayContainers)
+	projectsByName := map[string]*commands.ComposeProject{}
+	for _, project := range projects {
+		projectsByName[project.Name] = project
+	}
+	showMarks := len(gui.getMarkedContainers()) > 0
+
+	rows := []*containerRow{}
+	addContainer := func(container *commands.Container) {
+		rows = append(rows, &containerRow{
+			container: container,
+			showMarks: showMarks,
+			marked:    gui.State.Panels.Containers.Marked[container.ID],
+		})
+	}
+
+	for _, container := range gui.DockerCommand.DisplayContainers {
+		if container.ProjectName == "" {
+			addContainer(container)
+			continue
+		}
+		project, ok := projectsByName[container.ProjectName]
+		if !ok {
+			// we've already laid it out
+			continue
+		}
+		delete(projectsByName, container.ProjectName)
+
+		collapsed := gui.State.Panels.Containers.Collapsed[project.Name]
+		rows = append(rows, &containerRow{project: project, collapsed: collapsed, showMarks: showMarks})
+		if collapsed {
+			continue
+		}
+		for _, projectContainer := range project.Containers {
+			addContainer(projectContainer)
+		}
+	}
+
+	return rows
+}
+
+func (gui *Gui) getSelectedContainerRow() *containerRow {
+	rows := gui.getContainerRows()
+	selectedLine := gui.State.Panels.Containers.SelectedLine
+	if selectedLine < 0 || selectedLine >= len(rows) {
+		return nil
+	}
+	return rows[selectedLine]
+}
+
+// getSelectedComposeProject returns the project whose heading is selected, or
+// failing that the project of the selected container
+func (gui *Gui) getSelectedComposeProject() (*commands.ComposeProject, error) {
+	row := gui.getSelectedContainerRow()
+	if row == nil {
+		return nil, gui.Errors.ErrNoContainers
+	}
+	if row.project != nil {
+		return row.project, nil
+	}
+
+	projects, _ := commands.GroupContainersByProject(gui.DockerCommand.DisplayContainers)
+	for _, project := range projects {
+		if project.Name == row.container.ProjectName {
+			return project, nil
+		}
+	}
+	return nil, gui.Errors.ErrNoContainers
+}
+
+func (gui *Gui) handleComposeProjectSelect(v *gocui.View, project *commands.ComposeProject) error {
+	key := "compose-project-" + project.Name + "-" + project.GetDisplaySubstatus()
+	if !gui.shouldRefresh(key) {
+		return nil
+	}
+
+	mainView := gui.getMainView()
+	mainView.Tabs = []string{gui.Tr.ProjectTitle}
+	mainView.TabIndex = 0
+	gui.clearMainView()
+
+	return gui.renderComposeProject(project)
+}
+
+func (gui *Gui) renderComposeProject(project *commands.ComposeProject) error {
+	mainView := gui.getMainView()
+	mainView.Autoscroll = false
+	mainView.Wrap = true
+
+	padding := 19
+	output := ""
+	output += utils.WithPadding(gui.Tr.ComposeProjectName+": ", padding) + project.Name + "\n"
+	output += utils.WithPadding(gui.Tr.ComposeWorkingDir+": ", padding) + project.WorkingDir + "\n"
+	output += utils.WithPadding(gui.Tr.ComposeStatus+": ", padding) + project.GetDisplayStatus() + " " + project.GetDisplaySubstatus() + "\n"
+	if err := gui.DockerCommand.CheckComposeWorkingDir(project); err != nil {
+		output += "\n" + utils.ColoredString(err.Error(), color.FgRed) + "\n"
+	}
+
+	list, err := utils.RenderList(project.Containers)
+	if err != nil {
+		return err
+	}
+	output += "\n" + list
+
+	return gui.T.NewTask(func(stop chan struct{}) {
+		gui.renderString(gui.g, "main", output)
+	})
+}
+
+// handleComposeProjectToggleCollapsed collapses or expands the selected
+// project. From one of its containers, it collapses the project and selects its
+// heading.
+func (gui *Gui) handleComposeProjectToggleCollapsed(g *gocui.Gui, v *gocui.View) error {
+	row := gui.getSelectedContainerRow()
+	if row == nil {
+		return nil
+	}
+
+	collapsed := gui.State.Panels.Containers.Collapsed
+	if row.project != nil {
+		collapsed[row.project.Name] = !collapsed[row.project.Name]
+	} else if row.container.ProjectName != "" {
+		collapsed[row.container.ProjectName] = true
+		for i, other := range gui.getContainerRows() {
+			if other.project != nil && other.project.Name == row.container.ProjectName {
+				gui.State.Panels.Containers.SelectedLine = i
+				break
+			}
+		}
+	} else {
+		return nil
+	}
+
+	list, err := gui.renderContainersList(true)
+	if err != nil {
+		return err
+	}
+	if err := gui.setViewContent(g, v, list); err != nil {
+		return err
+	}
+	return gui.handleContainerSelect(g, v)
+}
+
+// handleComposeProjectMenu offers to bring the selected compose project up,
+// down, or restart it. The commands run from the project's working directory.
+func (gui *Gui) handleComposeProjectMenu(g *gocui.Gui, v *gocui.View) error {
+	project, err := gui.getSelectedComposeProject()
+	if err != nil {
+		return nil
+	}
+
+	templates := gui.Config.UserConfig.CommandTemplates
+	commandObject := gui.DockerCommand.NewCommandObject(commands.CommandObject{Project: project})
+	option := func(description string, templateString string, status string) *commandOption {
+		return &commandOption{
+			description: description,
+			command:     utils.ApplyTemplate(templateString, commandObject),
+			f: func() error {
+				return gui.WithWaitingStatus(status, func() error {
+					return gui.DockerCommand.RunComposeProjectCommand(project, templateString)
+				})
+			},
+		}
+	}
+
+	options := []*commandOption{
+		option(gui.Tr.ComposeUp, templates.ComposeProjectUp, gui.Tr.StartingStatus),
+		option(gui.Tr.ComposeDown, templates.ComposeProjectDown, gui.Tr.StoppingStatus),
+		option(gui.Tr.Restart, templates.ComposeProjectRestart, gui.Tr.RestartingStatus),
+		{
+			description: gui.Tr.Cancel,
+			f:           func() error { return nil },
+		},
+	}
+
+	handleMenuPress := func(index int) error { return options[index].f() }
+
+	return gui.createMenu(fmt.Sprintf(gui.Tr.ComposeProjectMenuTitle, project.Name), options, len(options), handleMenuPress)
+}
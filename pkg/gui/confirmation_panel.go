@@ -67,7 +67,20 @@ func (gui *Gui) createPromptPanel(g *gocui.Gui, currentView *gocui.View, title s
 		return err
 	}
 	confirmationView.Editable = true
-	return gui.setKeyBindings(g, handleConfirm, nil)
+	return gui.setPromptKeyBindings(g, handleConfirm, nil)
+}
+
+// createPasswordPromptPanel is createPromptPanel for typing in something that
+// shouldn't be on screen, like a password
+func (gui *Gui) createPasswordPromptPanel(g *gocui.Gui, currentView *gocui.View, title string, handleConfirm func(*gocui.Gui, *gocui.View) error) error {
+	gui.onNewPopupPanel()
+	confirmationView, err := gui.prepareConfirmationPanel(currentView, title, "", false)
+	if err != nil {
+		return err
+	}
+	confirmationView.Editable = true
+	confirmationView.Mask = '*'
+	return gui.setPromptKeyBindings(g, handleConfirm, nil)
 }
 
 func (gui *Gui) prepareConfirmationPanel(currentView *gocui.View, title, prompt string, hasLoader bool) (*gocui.View, error) {
@@ -144,6 +157,15 @@ func (gui *Gui) setKeyBindings(g *gocui.Gui, handleConfirm, handleClose func(*go
 	return nil
 }
 
+// setPromptKeyBindings is setKeyBindings for a panel you type into, where y
+// and n have to be left for typing
+func (gui *Gui) setPromptKeyBindings(g *gocui.Gui, handleConfirm, handleClose func(*gocui.Gui, *gocui.View) error) error {
+	if err := g.SetKeybinding("confirmation", nil, gocui.KeyEnter, gocui.ModNone, gui.wrappedConfirmationFunction(handleConfirm)); err != nil {
+		return err
+	}
+	return g.SetKeybinding("confirmation", nil, gocui.KeyEsc, gocui.ModNone, gui.wrappedConfirmationFunction(handleClose))
+}
+
 // createSpecificErrorPanel allows you to create an error popup, specifying the
 //  view to be focused when the user closes the popup, and a boolean specifying
 // whether we will log the error. If the message may include a user password,
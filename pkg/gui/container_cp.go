@@ -0,0 +1,135 @@
+package gui
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/jesseduffield/gocui"
+	"github.com/jesseduffield/lazydocker/pkg/commands"
+	"github.com/jesseduffield/lazydocker/pkg/utils"
+)
+
+// handleContainerCopyFiles asks which way to copy files between the selected
+// container and this machine, then for the paths on either end, like
+// docker cp
+func (gui *Gui) handleContainerCopyFiles(g *gocui.Gui, v *gocui.View) error {
+	container, err := gui.getSelectedContainer()
+	if err != nil {
+		return nil
+	}
+
+	options := []*commandOption{
+		{
+			description: gui.Tr.CopyFromContainer,
+			command:     "docker cp " + container.Name + ":<path> <path>",
+			f: func() error {
+				return gui.promptCopyPaths(v, container, true)
+			},
+		},
+		{
+			description: gui.Tr.CopyToContainer,
+			command:     "docker cp <path> " + container.Name + ":<path>",
+			f: func() error {
+				return gui.promptCopyPaths(v, container, false)
+			},
+		},
+		{
+			description: gui.Tr.Cancel,
+			f:           func() error { return nil },
+		},
+	}
+
+	handleMenuPress := func(index int) error {
+		return options[index].f()
+	}
+
+	return gui.createMenu("", options, len(options), handleMenuPress)
+}
+
+// promptCopyPaths asks for the path to copy from, then the path to copy to,
+// then copies
+func (gui *Gui) promptCopyPaths(v *gocui.View, container *commands.Container, fromContainer bool) error {
+	srcTitle := gui.Tr.CopyLocalSourceTitle
+	dstTitle := fmt.Sprintf(gui.Tr.CopyContainerDestinationTitle, container.Name)
+	if fromContainer {
+		srcTitle = fmt.Sprintf(gui.Tr.CopyContainerSourceTitle, container.Name)
+		dstTitle = gui.Tr.CopyLocalDestinationTitle
+	}
+
+	// once the menu has closed
+	gui.g.Update(func(g *gocui.Gui) error {
+		return gui.createPromptPanel(g, v, srcTitle, func(g *gocui.Gui, promptView *gocui.View) error {
+			src := gui.trimmedContent(promptView)
+			if src == "" {
+				return nil
+			}
+			// once the first prompt has closed
+			g.Update(func(g *gocui.Gui) error {
+				return gui.createPromptPanel(g, v, dstTitle, func(g *gocui.Gui, promptView *gocui.View) error {
+					dst := gui.trimmedContent(promptView)
+					if dst == "" {
+						if !fromContainer {
+							return nil
+						}
+						// like docker cp, where . is usual for the destination
+						dst = "."
+					}
+					return gui.copyFiles(v, container, fromContainer, src, dst)
+				})
+			})
+			return nil
+		})
+	})
+	return nil
+}
+
+// copyFiles copies between the container and this machine, showing how it's
+// going in a popup. Closing the popup cancels the copy.
+func (gui *Gui) copyFiles(v *gocui.View, container *commands.Container, fromContainer bool, src, dst string) error {
+	from, to := src, container.Name+":"+dst
+	if fromContainer {
+		from, to = container.Name+":"+src, dst
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	handleClose := func(g *gocui.Gui, v *gocui.View) error {
+		cancel()
+		return nil
+	}
+
+	title := fmt.Sprintf(gui.Tr.CopyingTitle, from, to)
+	if err := gui.createPopupPanel(gui.g, v, title, gui.Tr.CopyStartingStatus, true, handleClose, handleClose); err != nil {
+		cancel()
+		return err
+	}
+
+	go func() {
+		defer cancel()
+
+		progress := commands.CopyProgress{}
+		onProgress := func(p commands.CopyProgress) {
+			progress = p
+			gui.renderPopupProgress(p.Render(), true)
+		}
+
+		var err error
+		if fromContainer {
+			err = container.CopyFromContainer(ctx, src, dst, onProgress)
+		} else {
+			err = container.CopyToContainer(ctx, src, dst, onProgress)
+		}
+		if ctx.Err() != nil {
+			// the user closed the popup, so there's nobody to tell
+			return
+		}
+		if err != nil {
+			gui.renderPopupProgress(utils.ColoredString(err.Error(), color.FgRed), false)
+			return
+		}
+		message := fmt.Sprintf(gui.Tr.CopiedValue, commands.CopyProgress{Copied: progress.Copied}.Render(), from, to)
+		gui.renderPopupProgress(utils.ColoredString(message, color.FgGreen), false)
+	}()
+
+	return nil
+}
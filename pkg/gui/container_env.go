@@ -0,0 +1,108 @@
+package gui
+
+import (
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/jesseduffield/gocui"
+	"github.com/jesseduffield/lazydocker/pkg/commands"
+	"github.com/jesseduffield/lazydocker/pkg/utils"
+)
+
+// renderContainerEnv shows the container's environment variables, with the
+// values of any that look like secrets masked until they're revealed. Moving
+// on to another container masks them again.
+func (gui *Gui) renderContainerEnv(container *commands.Container) error {
+	mainView := gui.getMainView()
+	mainView.Autoscroll = false
+	mainView.Wrap = gui.Config.UserConfig.Gui.WrapMainPanel
+
+	mainState := gui.State.Panels.Main
+	if mainState.EnvKey != mainState.ObjectKey {
+		mainState.RevealSecrets = false
+	}
+	mainState.EnvKey = mainState.ObjectKey
+	mainState.EnvVars = container.EnvVars()
+
+	renderedTable := gui.renderEnvVars(mainState.EnvVars, mainState.RevealSecrets)
+	return gui.T.NewTask(func(stop chan struct{}) {
+		gui.renderString(gui.g, "main", renderedTable)
+	})
+}
+
+func (gui *Gui) renderEnvVars(envVars []commands.EnvVar, reveal bool) string {
+	if len(envVars) == 0 {
+		return gui.Tr.NothingToDisplay
+	}
+
+	envVariablesList := make([][]string, len(envVars))
+	for i, envVar := range envVars {
+		envVariablesList[i] = []string{
+			utils.ColoredString(envVar.Key+":", color.FgGreen),
+			utils.ColoredString(envVar.DisplayValue(reveal), color.FgYellow),
+		}
+	}
+	renderedTable, err := utils.RenderTable(envVariablesList)
+	if err != nil {
+		gui.Log.Error(err)
+		return gui.Tr.CannotDisplayEnvVariables
+	}
+	return renderedTable
+}
+
+// showingEnv tells us whether the main panel has environment variables in it
+func (gui *Gui) showingEnv() bool {
+	mainState := gui.State.Panels.Main
+	return mainState.EnvKey != "" && mainState.EnvKey == mainState.ObjectKey
+}
+
+// handleEnvReveal toggles between masking and showing secrets in the env tab,
+// keeping the scroll position
+func (gui *Gui) handleEnvReveal(g *gocui.Gui, v *gocui.View) error {
+	if !gui.showingEnv() {
+		return nil
+	}
+	mainState := gui.State.Panels.Main
+	mainState.RevealSecrets = !mainState.RevealSecrets
+	return gui.reRenderString(gui.g, "main", gui.renderEnvVars(mainState.EnvVars, mainState.RevealSecrets))
+}
+
+// handleMainCopy copies what's in the main panel: in the env and labels tabs,
+// a variable or label picked from a menu, and otherwise the inspect output
+func (gui *Gui) handleMainCopy(g *gocui.Gui, v *gocui.View) error {
+	if gui.showingEnv() {
+		return gui.createEnvCopyMenu()
+	}
+	if gui.showingLabels() {
+		return gui.createLabelsCopyMenu()
+	}
+	return gui.handleInspectCopy(g, v)
+}
+
+// createEnvCopyMenu lets the user pick an environment variable and copies its
+// value. Secrets are masked in the menu as they are in the panel, but it's the
+// real value that's copied.
+func (gui *Gui) createEnvCopyMenu() error {
+	mainState := gui.State.Panels.Main
+	if len(mainState.EnvVars) == 0 {
+		return nil
+	}
+
+	options := make([]*commandOption, len(mainState.EnvVars))
+	for i, envVar := range mainState.EnvVars {
+		envVar := envVar
+		options[i] = &commandOption{
+			description: envVar.Key,
+			command:     envVar.DisplayValue(mainState.RevealSecrets),
+			f: func() error {
+				return gui.copyToClipboard(envVar.Value, fmt.Sprintf(gui.Tr.CopiedToClipboardValue, envVar.Key))
+			},
+		}
+	}
+
+	handleMenuPress := func(index int) error {
+		return options[index].f()
+	}
+
+	return gui.createMenu(gui.Tr.CopyEnvVarTitle, options, len(options), handleMenuPress)
+}
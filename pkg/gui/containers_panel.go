@@ -1,8 +1,10 @@
 package gui
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"strings"
 	"time"
 
@@ -18,24 +20,24 @@ import (
 // list panel functions
 
 func (gui *Gui) getContainerContexts() []string {
-	return []string{"logs", "stats", "env", "config", "top"}
+	return []string{"logs", "stats", "env", "labels", "config", "top", "inspect", "mergedLogs"}
 }
 
 func (gui *Gui) getContainerContextTitles() []string {
-	return []string{gui.Tr.LogsTitle, gui.Tr.StatsTitle, gui.Tr.EnvTitle, gui.Tr.ConfigTitle, gui.Tr.TopTitle}
+	return []string{gui.getLogsTitle(), gui.Tr.StatsTitle, gui.Tr.EnvTitle, gui.Tr.LabelsTitle, gui.Tr.ConfigTitle, gui.Tr.TopTitle, gui.Tr.InspectTitle, gui.getMergedLogsTitle()}
 }
 
 func (gui *Gui) getSelectedContainer() (*commands.Container, error) {
-	selectedLine := gui.State.Panels.Containers.SelectedLine
-	if selectedLine == -1 {
+	row := gui.getSelectedContainerRow()
+	if row == nil || row.container == nil {
 		return &commands.Container{}, gui.Errors.ErrNoContainers
 	}
 
-	return gui.DockerCommand.DisplayContainers[selectedLine], nil
+	return row.container, nil
 }
 
 func (gui *Gui) handleContainersClick(g *gocui.Gui, v *gocui.View) error {
-	itemCount := len(gui.DockerCommand.DisplayContainers)
+	itemCount := len(gui.getContainerRows())
 	handleSelect := gui.handleContainerSelect
 	selectedLine := &gui.State.Panels.Containers.SelectedLine
 
@@ -43,6 +45,13 @@ func (gui *Gui) handleContainersClick(g *gocui.Gui, v *gocui.View) error {
 }
 
 func (gui *Gui) handleContainerSelect(g *gocui.Gui, v *gocui.View) error {
+	if row := gui.getSelectedContainerRow(); row != nil && row.project != nil {
+		if err := gui.focusPoint(0, gui.State.Panels.Containers.SelectedLine, len(gui.getContainerRows()), v); err != nil {
+			return err
+		}
+		return gui.handleComposeProjectSelect(v, row.project)
+	}
+
 	container, err := gui.getSelectedContainer()
 	if err != nil {
 		if err != gui.Errors.ErrNoContainers {
@@ -51,11 +60,18 @@ func (gui *Gui) handleContainerSelect(g *gocui.Gui, v *gocui.View) error {
 		return nil
 	}
 
-	if err := gui.focusPoint(0, gui.State.Panels.Containers.SelectedLine, len(gui.DockerCommand.DisplayContainers), v); err != nil {
+	if err := gui.focusPoint(0, gui.State.Panels.Containers.SelectedLine, len(gui.getContainerRows()), v); err != nil {
 		return err
 	}
 
 	key := "containers-" + container.ID + "-" + gui.getContainerContexts()[gui.State.Panels.Containers.ContextIndex]
+	if gui.showingMergedLogs() {
+		key = gui.getMergedLogsKey()
+	}
+	if gui.getContainerContexts()[gui.State.Panels.Containers.ContextIndex] == "config" {
+		// so that new health checks show up
+		key += "-" + containerHealthKey(container)
+	}
 	if !gui.shouldRefresh(key) {
 		return nil
 	}
@@ -79,6 +95,10 @@ func (gui *Gui) handleContainerSelect(g *gocui.Gui, v *gocui.View) error {
 		if err := gui.renderContainerEnv(container); err != nil {
 			return err
 		}
+	case "labels":
+		if err := gui.renderLabels(container.Labels(), gui.Tr.ContainerLabelsReadOnly); err != nil {
+			return err
+		}
 	case "stats":
 		if err := gui.renderContainerStats(container); err != nil {
 			return err
@@ -87,6 +107,14 @@ func (gui *Gui) handleContainerSelect(g *gocui.Gui, v *gocui.View) error {
 		if err := gui.renderContainerTop(container); err != nil {
 			return err
 		}
+	case "inspect":
+		if err := gui.renderInspect(container.InspectRaw); err != nil {
+			return err
+		}
+	case "mergedLogs":
+		if err := gui.renderMergedLogs(); err != nil {
+			return err
+		}
 	default:
 		return errors.New("Unknown context for containers panel")
 	}
@@ -94,38 +122,6 @@ func (gui *Gui) handleContainerSelect(g *gocui.Gui, v *gocui.View) error {
 	return nil
 }
 
-func (gui *Gui) renderContainerEnv(container *commands.Container) error {
-	mainView := gui.getMainView()
-	mainView.Autoscroll = false
-	mainView.Wrap = gui.Config.UserConfig.Gui.WrapMainPanel
-	envVariablesList := [][]string{}
-	renderedTable := gui.Tr.NothingToDisplay
-	if len(container.Details.Config.Env) > 0 {
-		var err error
-		for _, env := range container.Details.Config.Env {
-			splitEnv := strings.SplitN(env, "=", 2)
-			key := splitEnv[0]
-			value := ""
-			if len(splitEnv) > 1 {
-				value = splitEnv[1]
-			}
-			envVariablesList = append(envVariablesList,
-				[]string{
-					utils.ColoredString(key+":", color.FgGreen),
-					utils.ColoredString(value, color.FgYellow),
-				})
-		}
-		renderedTable, err = utils.RenderTable(envVariablesList)
-		if err != nil {
-			gui.Log.Error(err)
-			renderedTable = gui.Tr.CannotDisplayEnvVariables
-		}
-	}
-	return gui.T.NewTask(func(stop chan struct{}) {
-		gui.renderString(gui.g, "main", renderedTable)
-	})
-}
-
 func (gui *Gui) renderContainerConfig(container *commands.Container) error {
 	mainView := gui.getMainView()
 	mainView.Autoscroll = false
@@ -136,6 +132,7 @@ func (gui *Gui) renderContainerConfig(container *commands.Container) error {
 	output += utils.WithPadding("ID: ", padding) + container.ID + "\n"
 	output += utils.WithPadding("Name: ", padding) + container.Name + "\n"
 	output += utils.WithPadding("Command: ", padding) + strings.Join(append([]string{container.Details.Path}, container.Details.Args...), " ") + "\n"
+	output += utils.WithPadding("Restart: ", padding) + container.RestartPolicy() + "\n"
 	output += utils.WithPadding("Labels: ", padding) + utils.FormatMap(padding, container.Details.Config.Labels)
 	output += "\n"
 
@@ -154,17 +151,17 @@ func (gui *Gui) renderContainerConfig(container *commands.Container) error {
 	}
 
 	output += utils.WithPadding("Ports: ", padding)
-	if len(container.Details.NetworkSettings.Ports) > 0 {
+	if ports := container.PublishedPorts(); len(ports) > 0 {
 		output += "\n"
-		for k, v := range container.Details.NetworkSettings.Ports {
-			for _, host := range v {
-				output += fmt.Sprintf("%s%s %s\n", strings.Repeat(" ", padding), utils.ColoredString(host.HostPort+":", color.FgYellow), k)
-			}
+		for _, port := range ports {
+			output += fmt.Sprintf("%s%s %d/%s\n", strings.Repeat(" ", padding), utils.ColoredString(port.Host()+" ->", color.FgYellow), port.ContainerPort, port.Protocol)
 		}
 	} else {
 		output += "none\n"
 	}
 
+	output += gui.formatContainerHealth(container, padding)
+
 	data, err := json.MarshalIndent(&container.Details, "", "  ")
 	if err != nil {
 		return err
@@ -176,12 +173,60 @@ func (gui *Gui) renderContainerConfig(container *commands.Container) error {
 	})
 }
 
+// maxHealthChecksShown is how many of a container's latest health checks the
+// config tab shows
+const maxHealthChecksShown = 5
+
+// formatContainerHealth shows the container's health status and its latest
+// health checks, if it has a health check at all
+func (gui *Gui) formatContainerHealth(container *commands.Container, padding int) string {
+	status := container.HealthStatus()
+	if status == "" {
+		return ""
+	}
+
+	theme := gui.theme()
+	health := container.Details.State.Health
+	output := utils.WithPadding("Health: ", padding) + utils.ThemedString(status, container.GetHealthColor())
+	if health.FailingStreak > 0 {
+		output += fmt.Sprintf(" (%d failing in a row)", health.FailingStreak)
+	}
+	output += "\n"
+
+	for _, result := range container.RecentHealthChecks(maxHealthChecksShown) {
+		resultColor := theme.RunningColor
+		if result.ExitCode != 0 {
+			resultColor = theme.FailedColor
+		}
+		summary := fmt.Sprintf("%s exit %d:", result.End.Local().Format("15:04:05"), result.ExitCode)
+		output += strings.Repeat(" ", padding) + utils.ThemedString(summary, resultColor)
+		if text := strings.TrimSpace(result.Output); text != "" {
+			output += " " + strings.Join(strings.Fields(text), " ")
+		}
+		output += "\n"
+	}
+
+	return output
+}
+
+// containerHealthKey changes whenever the container has a new health check
+func containerHealthKey(container *commands.Container) string {
+	checks := container.RecentHealthChecks(1)
+	if len(checks) == 0 {
+		return container.HealthStatus()
+	}
+	return container.HealthStatus() + "-" + checks[0].End.String()
+}
+
 func (gui *Gui) renderContainerStats(container *commands.Container) error {
 	mainView := gui.getMainView()
 	mainView.Autoscroll = false
 	mainView.Wrap = gui.Config.UserConfig.Gui.WrapMainPanel
 
-	return gui.T.NewTickerTask(time.Second, func(stop chan struct{}) { gui.clearMainView() }, func(stop, notifyStopped chan struct{}) {
+	return gui.T.NewTickerTask(time.Second, func(stop chan struct{}) {
+		gui.clearMainView()
+		go gui.streamContainerStats(container, stop)
+	}, func(stop, notifyStopped chan struct{}) {
 		width, _ := mainView.Size()
 
 		contents, err := container.RenderStats(width)
@@ -193,6 +238,31 @@ func (gui *Gui) renderContainerStats(container *commands.Container) error {
 	})
 }
 
+// contextFromStop returns a context that's cancelled when the task's stop
+// channel is closed, for handing to the docker client
+func contextFromStop(stop chan struct{}) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		select {
+		case <-stop:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, cancel
+}
+
+// streamContainerStats keeps the container's stat history up to date until
+// we're told to stop, which we are as soon as something else is selected
+func (gui *Gui) streamContainerStats(container *commands.Container, stop chan struct{}) {
+	ctx, cancel := contextFromStop(stop)
+	defer cancel()
+
+	if err := gui.DockerCommand.StreamContainerStats(ctx, container); err != nil {
+		gui.Log.Warn(err)
+	}
+}
+
 func (gui *Gui) renderContainerTop(container *commands.Container) error {
 	mainView := gui.getMainView()
 	mainView.Autoscroll = false
@@ -213,14 +283,96 @@ func (gui *Gui) renderContainerLogs(container *commands.Container) error {
 	mainView.Autoscroll = true
 	mainView.Wrap = gui.Config.UserConfig.Gui.WrapMainPanel
 
+	key := gui.State.Panels.Main.ObjectKey
 	return gui.T.NewTickerTask(time.Millisecond*200, nil, func(stop, notifyStopped chan struct{}) {
-		gui.renderContainerLogsAux(container, stop, notifyStopped)
+		gui.renderContainerLogsAux(container, key, stop, notifyStopped)
 	})
 }
 
-func (gui *Gui) renderContainerLogsAux(container *commands.Container, stop, notifyStopped chan struct{}) {
+func (gui *Gui) renderContainerLogsAux(container *commands.Container, key string, stop, notifyStopped chan struct{}) {
 	gui.clearMainView()
+	writer := gui.newLogsWriter(key)
+
+	if gui.Config.UserConfig.CommandTemplates.ContainerLogs != config.GetDefaultConfig().CommandTemplates.ContainerLogs {
+		// the user has their own logs command, so we run that instead
+		gui.runContainerLogsCommand(container, writer, stop)
+	} else {
+		gui.streamContainerLogs(container, writer, stop)
+	}
+	if err := writer.Flush(); err != nil {
+		gui.Log.Warn(err)
+	}
+
+	// if we are here because the task has been stopped, we should return
+	// if we are here then the container must have exited, meaning we should wait until it's back again before
+	ticker := time.NewTicker(time.Millisecond * 100)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			result, err := container.Inspect()
+			if err != nil {
+				// if we get an error, then the container has probably been removed so we'll get out of here
+				gui.Log.Error(err)
+				notifyStopped <- struct{}{}
+				return
+			}
+			if result.State.Running {
+				return
+			}
+		}
+	}
+}
+
+// getLogsSinceOptions are the periods you can cycle through for how far back
+// container logs go. Blank means all of them.
+func (gui *Gui) getLogsSinceOptions() []string {
+	return []string{"5m", "1h", ""}
+}
+
+func (gui *Gui) getLogsSince() string {
+	return gui.getLogsSinceOptions()[gui.State.Panels.Main.LogsSinceIndex]
+}
+
+// getLogsTitle is the logs tab's title, which says how far back we're going
+func (gui *Gui) getLogsTitle() string {
+	since := gui.getLogsSince()
+	if since == "" {
+		since = gui.Tr.LogsSinceAll
+	}
+	return fmt.Sprintf("%s (%s)", gui.Tr.LogsTitle, since)
+}
+
+func (gui *Gui) handleCycleLogsSince(g *gocui.Gui, v *gocui.View) error {
+	options := gui.getLogsSinceOptions()
+	gui.State.Panels.Main.LogsSinceIndex = (gui.State.Panels.Main.LogsSinceIndex + 1) % len(options)
+
+	// forcing the logs to be fetched again from the new starting point
+	gui.State.Panels.Main.ObjectKey = ""
+	if v.Name() == "services" {
+		return gui.handleServiceSelect(g, v)
+	}
+	return gui.handleContainerSelect(g, v)
+}
+
+// streamContainerLogs follows the container's logs from the docker API until
+// the container stops or we're told to stop
+func (gui *Gui) streamContainerLogs(container *commands.Container, output io.Writer, stop chan struct{}) {
+	ctx, cancel := contextFromStop(stop)
+	defer cancel()
+
+	writer := utils.NewLogLevelWriter(output, gui.logLevelColors())
+	if err := container.StreamLogs(ctx, gui.getLogsSince(), writer); err != nil {
+		gui.Log.Warn(err)
+	}
+	if err := writer.Flush(); err != nil {
+		gui.Log.Warn(err)
+	}
+}
 
+func (gui *Gui) runContainerLogsCommand(container *commands.Container, output io.Writer, stop chan struct{}) {
 	command := utils.ApplyTemplate(
 		gui.Config.UserConfig.CommandTemplates.ContainerLogs,
 		gui.DockerCommand.NewCommandObject(commands.CommandObject{Container: container}),
@@ -232,9 +384,8 @@ func (gui *Gui) renderContainerLogsAux(container *commands.Container, stop, noti
 	// process ID.
 	gui.OSCommand.PrepareForChildren(cmd)
 
-	mainView := gui.getMainView()
-	cmd.Stdout = mainView
-	cmd.Stderr = mainView
+	cmd.Stdout = output
+	cmd.Stderr = output
 
 	cmd.Start()
 
@@ -248,28 +399,6 @@ func (gui *Gui) renderContainerLogsAux(container *commands.Container, stop, noti
 	}()
 
 	cmd.Wait()
-
-	// if we are here because the task has been stopped, we should return
-	// if we are here then the container must have exited, meaning we should wait until it's back again before
-	ticker := time.NewTicker(time.Millisecond * 100)
-	defer ticker.Stop()
-	for {
-		select {
-		case <-stop:
-			return
-		case <-ticker.C:
-			result, err := container.Inspect()
-			if err != nil {
-				// if we get an error, then the container has probably been removed so we'll get out of here
-				gui.Log.Error(err)
-				notifyStopped <- struct{}{}
-				return
-			}
-			if result.State.Running {
-				return
-			}
-		}
-	}
 }
 
 func (gui *Gui) refreshContainersAndServices() error {
@@ -285,6 +414,8 @@ func (gui *Gui) refreshContainersAndServices() error {
 	if len(gui.DockerCommand.Services) > 0 {
 		selectedService = gui.DockerCommand.Services[sl]
 	}
+	// and of the selected container, which moves when the containers are re-sorted
+	selectedContainer, _ := gui.getSelectedContainer()
 
 	if err := gui.DockerCommand.RefreshContainersAndServices(); err != nil {
 		return err
@@ -305,14 +436,50 @@ func (gui *Gui) refreshContainersAndServices() error {
 		}
 	}
 
-	if len(gui.DockerCommand.DisplayContainers) > 0 && gui.State.Panels.Containers.SelectedLine == -1 {
+	rows := gui.getContainerRows()
+	if selectedContainer.ID != "" {
+		for i, row := range rows {
+			if row.container == nil || row.container.ID != selectedContainer.ID {
+				continue
+			}
+			if i != gui.State.Panels.Containers.SelectedLine {
+				gui.State.Panels.Containers.SelectedLine = i
+				if err := gui.focusPoint(0, i, len(rows), containersView); err != nil {
+					return err
+				}
+			}
+			break
+		}
+	}
+
+	containerIDs := make([]string, len(rows))
+	for i, row := range rows {
+		if row.container != nil {
+			containerIDs[i] = row.container.ID
+		}
+	}
+	if err := gui.restoreSelection(containersView, containerIDs, &gui.State.Panels.Containers.SelectedLine); err != nil {
+		return err
+	}
+
+	rowCount := len(rows)
+	if rowCount > 0 && gui.State.Panels.Containers.SelectedLine == -1 {
 		gui.State.Panels.Containers.SelectedLine = 0
 	}
-	if len(gui.DockerCommand.DisplayContainers)-1 < gui.State.Panels.Containers.SelectedLine {
-		gui.State.Panels.Containers.SelectedLine = len(gui.DockerCommand.DisplayContainers) - 1
+	if rowCount-1 < gui.State.Panels.Containers.SelectedLine {
+		gui.State.Panels.Containers.SelectedLine = rowCount - 1
 	}
 
 	// doing the exact same thing for services
+	if servicesView := gui.getServicesView(); servicesView != nil {
+		serviceNames := make([]string, len(gui.DockerCommand.Services))
+		for i, service := range gui.DockerCommand.Services {
+			serviceNames[i] = service.Name
+		}
+		if err := gui.restoreSelection(servicesView, serviceNames, &gui.State.Panels.Services.SelectedLine); err != nil {
+			return err
+		}
+	}
 	if len(gui.DockerCommand.Services) > 0 && gui.State.Panels.Services.SelectedLine == -1 {
 		gui.State.Panels.Services.SelectedLine = 0
 	}
@@ -322,9 +489,10 @@ func (gui *Gui) refreshContainersAndServices() error {
 
 	gui.g.Update(func(g *gocui.Gui) error {
 		containersView.Clear()
+		containersView.Title = gui.getContainersTitle()
 		isFocused := gui.g.CurrentView().Name() == "containers"
 
-		list, err := utils.RenderList(gui.DockerCommand.DisplayContainers, utils.IsFocused(isFocused))
+		list, err := gui.renderContainersList(isFocused)
 		if err != nil {
 			return err
 		}
@@ -364,7 +532,7 @@ func (gui *Gui) handleContainersNextLine(g *gocui.Gui, v *gocui.View) error {
 	}
 
 	panelState := gui.State.Panels.Containers
-	gui.changeSelectedLine(&panelState.SelectedLine, len(gui.DockerCommand.DisplayContainers), false)
+	gui.changeSelectedLine(&panelState.SelectedLine, len(gui.getContainerRows()), false)
 
 	return gui.handleContainerSelect(gui.g, v)
 }
@@ -375,7 +543,7 @@ func (gui *Gui) handleContainersPrevLine(g *gocui.Gui, v *gocui.View) error {
 	}
 
 	panelState := gui.State.Panels.Containers
-	gui.changeSelectedLine(&panelState.SelectedLine, len(gui.DockerCommand.DisplayContainers), true)
+	gui.changeSelectedLine(&panelState.SelectedLine, len(gui.getContainerRows()), true)
 
 	return gui.handleContainerSelect(gui.g, v)
 }
@@ -418,6 +586,159 @@ func (r *removeContainerOption) GetDisplayStrings(isFocused bool) []string {
 	return []string{r.description, color.New(color.FgRed).Sprint(r.command)}
 }
 
+// getContainersTitle is the containers panel's title, which shows the filter
+// and how many containers it matches, if there is one
+func (gui *Gui) getContainersTitle() string {
+	title := gui.Tr.StandaloneContainersTitle
+	if gui.Config.UserConfig.Gui.ShowAllContainers || !gui.DockerCommand.InDockerComposeProject {
+		title = gui.Tr.ContainersTitle
+	}
+	title = gui.withSortLabel(title, gui.DockerCommand.ContainerSort())
+
+	filter := gui.State.Panels.Containers.Filter
+	if filter == "" {
+		return title
+	}
+	return fmt.Sprintf("%s [%s] (%d/%d)", title, filter, len(gui.DockerCommand.DisplayContainers), gui.DockerCommand.TotalDisplayContainers)
+}
+
+// handleContainersFilter opens the filter bar, which narrows down the
+// containers panel as you type. Enter keeps the filter and esc goes back to
+// the one you had before.
+func (gui *Gui) handleContainersFilter(g *gocui.Gui, v *gocui.View) error {
+	previousFilter := gui.State.Panels.Containers.Filter
+
+	gui.onNewPopupPanel()
+	filterView, err := gui.prepareConfirmationPanel(v, gui.Tr.FilterContainersTitle, "", false)
+	if err != nil {
+		return err
+	}
+	filterView.Editable = true
+	filterView.Editor = gocui.EditorFunc(func(filterView *gocui.View, key gocui.Key, ch rune, mod gocui.Modifier) {
+		gocui.DefaultEditor.Edit(filterView, key, ch, mod)
+		gui.applyContainerFilter(filterView, gui.trimmedContent(filterView))
+	})
+	fmt.Fprint(filterView, previousFilter)
+	if err := filterView.SetCursor(len(previousFilter), 0); err != nil {
+		return err
+	}
+
+	return gui.setPromptKeyBindings(g, nil, func(g *gocui.Gui, filterView *gocui.View) error {
+		gui.applyContainerFilter(filterView, previousFilter)
+		return nil
+	})
+}
+
+// applyContainerFilter filters the containers panel by text, if it's a valid
+// filter. If it isn't, we leave the current filter be and say what's wrong in
+// the filter bar's title.
+func (gui *Gui) applyContainerFilter(filterView *gocui.View, text string) {
+	args, err := commands.ParseContainerFilter(text)
+	if err != nil {
+		filterView.Title = fmt.Sprintf("%s (%s)", gui.Tr.FilterContainersTitle, err.Error())
+		return
+	}
+	filterView.Title = gui.Tr.FilterContainersTitle
+
+	gui.State.Panels.Containers.Filter = strings.Join(strings.Fields(text), " ")
+	gui.DockerCommand.SetContainerFilter(args)
+
+	// refreshing in the background so that typing stays snappy
+	go func() {
+		if err := gui.refreshContainersAndServices(); err != nil {
+			gui.Log.Error(err)
+		}
+	}()
+}
+
+func (gui *Gui) renderContainersList(isFocused bool) (string, error) {
+	return utils.RenderList(gui.getContainerRows(), utils.IsFocused(isFocused))
+}
+
+// getMarkedContainers returns the marked containers that are still in the
+// panel, in the order they're shown
+func (gui *Gui) getMarkedContainers() []*commands.Container {
+	marked := []*commands.Container{}
+	for _, container := range gui.DockerCommand.DisplayContainers {
+		if gui.State.Panels.Containers.Marked[container.ID] {
+			marked = append(marked, container)
+		}
+	}
+	return marked
+}
+
+func (gui *Gui) handleContainerToggleMarked(g *gocui.Gui, v *gocui.View) error {
+	container, err := gui.getSelectedContainer()
+	if err != nil {
+		return nil
+	}
+
+	if gui.State.Panels.Containers.Marked[container.ID] {
+		delete(gui.State.Panels.Containers.Marked, container.ID)
+	} else {
+		gui.State.Panels.Containers.Marked[container.ID] = true
+	}
+
+	list, err := gui.renderContainersList(true)
+	if err != nil {
+		return err
+	}
+	if err := gui.setViewContent(g, v, list); err != nil {
+		return err
+	}
+	if gui.showingMergedLogs() {
+		// the merged logs follow the marked containers
+		return gui.handleContainerSelect(g, v)
+	}
+	return nil
+}
+
+// runBulkActionOnMarked runs action on each of the marked containers, then
+// tells the user how each one went
+func (gui *Gui) runBulkActionOnMarked(status string, action func(*commands.Container) error) error {
+	containers := gui.getMarkedContainers()
+	gui.State.Panels.Containers.Marked = map[string]bool{}
+
+	return gui.WithTimedWaitingStatus(status, func() error {
+		results := commands.RunBulkContainerAction(containers, action)
+
+		if err := gui.refreshContainersAndServices(); err != nil {
+			gui.Log.Error(err)
+		}
+
+		lines := make([]string, len(results))
+		for i, result := range results {
+			if result.Err != nil {
+				lines[i] = utils.ColoredString("✗ "+result.Container.Name+": "+result.Err.Error(), color.FgRed)
+			} else {
+				lines[i] = utils.ColoredString("✓ "+result.Container.Name, color.FgGreen)
+			}
+		}
+		return gui.createConfirmationPanel(gui.g, gui.getContainersView(), gui.Tr.BulkResultsTitle, strings.Join(lines, "\n"), nil, nil)
+	})
+}
+
+func (gui *Gui) handleStopMarkedContainers() error {
+	return gui.runBulkActionOnMarked(gui.Tr.StoppingStatus, func(container *commands.Container) error {
+		return container.Stop()
+	})
+}
+
+func (gui *Gui) handleRestartMarkedContainers() error {
+	return gui.runBulkActionOnMarked(gui.Tr.RestartingStatus, func(container *commands.Container) error {
+		return container.Restart()
+	})
+}
+
+func (gui *Gui) handleRemoveMarkedContainers() error {
+	message := fmt.Sprintf(gui.Tr.ConfirmRemoveMarked, len(gui.getMarkedContainers()))
+	return gui.createConfirmationPanel(gui.g, gui.getContainersView(), gui.Tr.Confirm, message, func(g *gocui.Gui, v *gocui.View) error {
+		return gui.runBulkActionOnMarked(gui.Tr.RemovingStatus, func(container *commands.Container) error {
+			return container.Remove(types.ContainerRemoveOptions{Force: true})
+		})
+	}, nil)
+}
+
 func (gui *Gui) handleHideStoppedContainers(g *gocui.Gui, v *gocui.View) error {
 	gui.DockerCommand.ShowExited = !gui.DockerCommand.ShowExited
 	return nil
@@ -481,7 +802,7 @@ func (gui *Gui) handleContainerStop(g *gocui.Gui, v *gocui.View) error {
 	}
 
 	return gui.createConfirmationPanel(gui.g, v, gui.Tr.Confirm, gui.Tr.StopContainer, func(g *gocui.Gui, v *gocui.View) error {
-		return gui.WithWaitingStatus(gui.Tr.StoppingStatus, func() error {
+		return gui.WithTimedWaitingStatus(gui.Tr.StoppingStatus, func() error {
 			if err := container.Stop(); err != nil {
 				return gui.createErrorPanel(gui.g, err.Error())
 			}
@@ -492,13 +813,56 @@ func (gui *Gui) handleContainerStop(g *gocui.Gui, v *gocui.View) error {
 	}, nil)
 }
 
+// handleContainerStopWithTimeout asks how long to give the container to stop
+// before it's killed, for when the configured timeout isn't right this time
+func (gui *Gui) handleContainerStopWithTimeout(g *gocui.Gui, v *gocui.View) error {
+	container, err := gui.getSelectedContainer()
+	if err != nil {
+		return nil
+	}
+
+	return gui.createPromptPanel(g, v, gui.Tr.StopTimeoutTitle, func(g *gocui.Gui, promptView *gocui.View) error {
+		timeout, err := commands.ParseStopTimeout(gui.trimmedContent(promptView))
+		if err != nil {
+			return gui.createErrorPanel(gui.g, err.Error())
+		}
+
+		return gui.WithTimedWaitingStatus(gui.Tr.StoppingStatus, func() error {
+			if err := container.StopWithTimeout(timeout); err != nil {
+				return gui.createErrorPanel(gui.g, err.Error())
+			}
+
+			return gui.refreshContainersAndServices()
+		})
+	})
+}
+
+// handleContainerKill kills the container without waiting for it to stop, for
+// containers that ignore being asked to
+func (gui *Gui) handleContainerKill(g *gocui.Gui, v *gocui.View) error {
+	container, err := gui.getSelectedContainer()
+	if err != nil {
+		return nil
+	}
+
+	return gui.createConfirmationPanel(gui.g, v, gui.Tr.Confirm, gui.Tr.KillContainer, func(g *gocui.Gui, v *gocui.View) error {
+		return gui.WithWaitingStatus(gui.Tr.KillingStatus, func() error {
+			if err := container.Kill(); err != nil {
+				return gui.createErrorPanel(gui.g, err.Error())
+			}
+
+			return gui.refreshContainersAndServices()
+		})
+	}, nil)
+}
+
 func (gui *Gui) handleContainerRestart(g *gocui.Gui, v *gocui.View) error {
 	container, err := gui.getSelectedContainer()
 	if err != nil {
 		return nil
 	}
 
-	return gui.WithWaitingStatus(gui.Tr.RestartingStatus, func() error {
+	return gui.WithTimedWaitingStatus(gui.Tr.RestartingStatus, func() error {
 		if err := container.Restart(); err != nil {
 			return gui.createErrorPanel(gui.g, err.Error())
 		}
@@ -507,6 +871,105 @@ func (gui *Gui) handleContainerRestart(g *gocui.Gui, v *gocui.View) error {
 	})
 }
 
+// handleContainerRestartMenu offers to restart the container or change its
+// restart policy, saying what the policy is now, so that a container which
+// keeps coming back because it's set to always restart is no surprise
+func (gui *Gui) handleContainerRestartMenu(g *gocui.Gui, v *gocui.View) error {
+	container, err := gui.getSelectedContainer()
+	if err != nil {
+		return nil
+	}
+
+	options := []*commandOption{
+		{
+			description: gui.Tr.Restart,
+			command:     "docker restart " + container.Name,
+			f: func() error {
+				return gui.handleContainerRestart(g, v)
+			},
+		},
+	}
+	for _, policy := range commands.RestartPolicies {
+		policy := policy
+		if policy == container.RestartPolicy() {
+			continue
+		}
+		options = append(options, &commandOption{
+			description: fmt.Sprintf(gui.Tr.SetRestartPolicy, policy),
+			command:     fmt.Sprintf("docker update --restart %s %s", policy, container.Name),
+			f: func() error {
+				return gui.WithWaitingStatus(gui.Tr.UpdatingStatus, func() error {
+					if err := container.UpdateRestartPolicy(policy); err != nil {
+						return gui.createErrorPanel(gui.g, err.Error())
+					}
+					return gui.refreshContainersAndServices()
+				})
+			},
+		})
+	}
+
+	handleMenuPress := func(index int) error {
+		return options[index].f()
+	}
+
+	return gui.createMenu(fmt.Sprintf(gui.Tr.RestartPolicyMenuTitle, container.RestartPolicy()), options, len(options), handleMenuPress)
+}
+
+// handleContainerRename asks for a new name for the container, starting from
+// the one it has. The name is checked as it's typed, and one docker wouldn't
+// take keeps the prompt open.
+func (gui *Gui) handleContainerRename(g *gocui.Gui, v *gocui.View) error {
+	container, err := gui.getSelectedContainer()
+	if err != nil {
+		return nil
+	}
+
+	title := fmt.Sprintf(gui.Tr.RenameContainerTitle, container.Name)
+	gui.onNewPopupPanel()
+	promptView, err := gui.prepareConfirmationPanel(v, title, "", false)
+	if err != nil {
+		return err
+	}
+	promptView.Editable = true
+	validate := func() error {
+		err := commands.ValidateContainerName(gui.trimmedContent(promptView))
+		promptView.Title = title
+		if err != nil {
+			promptView.Title = fmt.Sprintf("%s (%s)", title, err.Error())
+		}
+		return err
+	}
+	promptView.Editor = gocui.EditorFunc(func(promptView *gocui.View, key gocui.Key, ch rune, mod gocui.Modifier) {
+		gocui.DefaultEditor.Edit(promptView, key, ch, mod)
+		_ = validate()
+	})
+	fmt.Fprint(promptView, container.Name)
+	if err := promptView.SetCursor(len(container.Name), 0); err != nil {
+		return err
+	}
+
+	handleConfirm := func(g *gocui.Gui, promptView *gocui.View) error {
+		if validate() != nil {
+			return nil
+		}
+		name := gui.trimmedContent(promptView)
+		if err := gui.closeConfirmationPrompt(g); err != nil {
+			return err
+		}
+		return gui.WithWaitingStatus(gui.Tr.RenamingStatus, func() error {
+			if err := container.Rename(name); err != nil {
+				return gui.createErrorPanel(gui.g, err.Error())
+			}
+			// the cursor follows the container to wherever its new name puts it
+			return gui.refreshContainersAndServices()
+		})
+	}
+	if err := g.SetKeybinding("confirmation", nil, gocui.KeyEnter, gocui.ModNone, handleConfirm); err != nil {
+		return err
+	}
+	return g.SetKeybinding("confirmation", nil, gocui.KeyEsc, gocui.ModNone, gui.wrappedConfirmationFunction(nil))
+}
+
 func (gui *Gui) handleContainerAttach(g *gocui.Gui, v *gocui.View) error {
 	container, err := gui.getSelectedContainer()
 	if err != nil {
@@ -523,15 +986,11 @@ func (gui *Gui) handleContainerAttach(g *gocui.Gui, v *gocui.View) error {
 }
 
 func (gui *Gui) handlePruneContainers() error {
-	return gui.createConfirmationPanel(gui.g, gui.getContainersView(), gui.Tr.Confirm, gui.Tr.ConfirmPruneContainers, func(g *gocui.Gui, v *gocui.View) error {
-		return gui.WithWaitingStatus(gui.Tr.PruningStatus, func() error {
-			err := gui.DockerCommand.PruneContainers()
-			if err != nil {
-				return gui.createErrorPanel(gui.g, err.Error())
-			}
-			return nil
-		})
-	}, nil)
+	return gui.runPrune(gui.getContainersView(), gui.Tr.ConfirmPruneContainers, gui.DockerCommand.PruneContainers, nil)
+}
+
+func (gui *Gui) handlePruneNetworks() error {
+	return gui.runPrune(gui.getContainersView(), gui.Tr.ConfirmPruneNetworks, gui.DockerCommand.PruneNetworks, nil)
 }
 
 func (gui *Gui) handleContainerViewLogs(g *gocui.Gui, v *gocui.View) error {
@@ -554,12 +1013,13 @@ func (gui *Gui) handleContainersExecShell(g *gocui.Gui, v *gocui.View) error {
 	if err != nil {
 		return nil
 	}
-	commandObject := gui.DockerCommand.NewCommandObject(commands.CommandObject{
-		Container: container,
-	})
-	resolvedCommand := utils.ApplyTemplate("docker exec -it {{ .Container.ID }} /bin/sh -c 'eval $(grep ^$(id -un): /etc/passwd | cut -d : -f 7-)'", commandObject)
-	// attach and return the subprocess error
-	cmd := gui.OSCommand.ExecutableFromString(resolvedCommand)
+
+	cmd, err := container.ExecShell()
+	if err != nil {
+		return gui.createErrorPanel(gui.g, err.Error())
+	}
+
+	// exec and return the subprocess error
 	gui.SubProcess = cmd
 	return gui.Errors.ErrSubProcess
 }
@@ -619,6 +1079,27 @@ func (gui *Gui) handleContainersBulkCommand(g *gocui.Gui, v *gocui.View) error {
 			Name:             gui.Tr.PruneContainers,
 			InternalFunction: gui.handlePruneContainers,
 		},
+		{
+			Name:             gui.Tr.PruneNetworks,
+			InternalFunction: gui.handlePruneNetworks,
+		},
+	}
+
+	if marked := len(gui.getMarkedContainers()); marked > 0 {
+		baseBulkCommands = append([]config.CustomCommand{
+			{
+				Name:             fmt.Sprintf(gui.Tr.StopMarked, marked),
+				InternalFunction: gui.handleStopMarkedContainers,
+			},
+			{
+				Name:             fmt.Sprintf(gui.Tr.RestartMarked, marked),
+				InternalFunction: gui.handleRestartMarkedContainers,
+			},
+			{
+				Name:             fmt.Sprintf(gui.Tr.RemoveMarked, marked),
+				InternalFunction: gui.handleRemoveMarkedContainers,
+			},
+		}, baseBulkCommands...)
 	}
 
 	bulkCommands := append(baseBulkCommands, gui.Config.UserConfig.BulkCommands.Containers...)
@@ -634,23 +1115,5 @@ func (gui *Gui) handleContainersOpenInBrowserCommand(g *gocui.Gui, v *gocui.View
 		return nil
 	}
 
-	return gui.openContainerInBrowser(container)
-}
-
-func (gui *Gui) openContainerInBrowser(container *commands.Container) error {
-	// skip if no any ports
-	if len(container.Container.Ports) == 0 {
-		return nil
-	}
-	// skip if the first port is not published
-	port := container.Container.Ports[0]
-	if port.IP == "" {
-		return nil
-	}
-	ip := port.IP
-	if ip == "0.0.0.0" {
-		ip = "localhost"
-	}
-	link := fmt.Sprintf("http://%s:%d/", ip, port.PublicPort)
-	return gui.OSCommand.OpenLink(link)
+	return gui.createPublishedPortsMenu(v, container)
 }
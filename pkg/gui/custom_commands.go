@@ -58,6 +58,8 @@ func (gui *Gui) createCommandMenu(customCommands []config.CustomCommand, command
 		}
 
 		return gui.WithWaitingStatus(waitingStatus, func() error {
+			// who knows what the command did to our containers
+			defer gui.DockerCommand.InvalidateContainerCache()
 			if err := gui.OSCommand.RunCommand(option.command); err != nil {
 				return gui.createErrorPanel(gui.g, err.Error())
 			}
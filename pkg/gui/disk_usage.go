@@ -0,0 +1,207 @@
+package gui
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/jesseduffield/gocui"
+	"github.com/jesseduffield/lazydocker/pkg/commands"
+	"github.com/jesseduffield/lazydocker/pkg/utils"
+)
+
+func (gui *Gui) getDiskUsageTitle() string {
+	if gui.State.Panels.Project.DiskUsageKind == "" {
+		return gui.Tr.DiskUsageTitle
+	}
+	return fmt.Sprintf("%s (%s)", gui.Tr.DiskUsageTitle, gui.State.Panels.Project.DiskUsageKind)
+}
+
+// renderDiskUsage shows how much space everything takes up, either as a
+// summary or for the kind of thing being looked at. Working it out is slow, so
+// we only ask the daemon the first time and when asked to refresh.
+func (gui *Gui) renderDiskUsage() error {
+	mainView := gui.getMainView()
+	mainView.Autoscroll = false
+	mainView.Wrap = gui.Config.UserConfig.Gui.WrapMainPanel
+
+	state := gui.State.Panels.Project
+	return gui.T.NewTask(func(stop chan struct{}) {
+		if state.DiskUsage == nil {
+			gui.renderString(gui.g, "main", gui.Tr.CalculatingDiskUsage)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			go func() {
+				select {
+				case <-stop:
+					cancel()
+				case <-ctx.Done():
+				}
+			}()
+
+			usage, err := gui.DockerCommand.GetDiskUsage(ctx)
+			if err != nil {
+				if ctx.Err() == nil {
+					gui.renderString(gui.g, "main", err.Error())
+				}
+				return
+			}
+			state.DiskUsage = usage
+		}
+
+		gui.renderString(gui.g, "main", gui.formatDiskUsage(state.DiskUsage, state.DiskUsageKind))
+	})
+}
+
+func (gui *Gui) formatDiskUsage(usage []commands.DiskUsageCategory, kind string) string {
+	for _, category := range usage {
+		if category.Kind == kind {
+			return gui.formatDiskUsageCategory(category)
+		}
+	}
+
+	rows := [][]string{{gui.Tr.DiskUsageType, gui.Tr.DiskUsageTotal, gui.Tr.DiskUsageActive, gui.Tr.DiskUsageSize, gui.Tr.DiskUsageReclaimable}}
+	for _, category := range usage {
+		rows = append(rows, []string{
+			utils.ColoredString(category.Kind, color.FgCyan),
+			fmt.Sprint(category.Count),
+			fmt.Sprint(category.Active),
+			utils.FormatDecimalBytes(int(category.Size)),
+			formatReclaimable(category),
+		})
+	}
+	table, err := utils.RenderTable(rows)
+	if err != nil {
+		gui.Log.Error(err)
+		return err.Error()
+	}
+	return table
+}
+
+func (gui *Gui) formatDiskUsageCategory(category commands.DiskUsageCategory) string {
+	output := fmt.Sprintf(gui.Tr.DiskUsageSummary, category.Kind, utils.FormatDecimalBytes(int(category.Size)), formatReclaimable(category)) + "\n\n"
+	if len(category.Items) == 0 {
+		return output + gui.Tr.NothingToDisplay
+	}
+
+	rows := make([][]string, len(category.Items))
+	for i, item := range category.Items {
+		inUse := ""
+		if item.InUse {
+			inUse = utils.ColoredString(gui.Tr.InUse, color.FgGreen)
+		}
+		rows[i] = []string{utils.FormatDecimalBytes(int(item.Size)), item.Name, inUse}
+	}
+	table, err := utils.RenderTable(rows)
+	if err != nil {
+		gui.Log.Error(err)
+		return err.Error()
+	}
+	return output + table
+}
+
+// formatReclaimable is how much pruning would get back, and how much of the
+// total that is, like docker system df shows it
+func formatReclaimable(category commands.DiskUsageCategory) string {
+	reclaimable := utils.FormatDecimalBytes(int(category.Reclaimable))
+	if category.Size == 0 {
+		return reclaimable
+	}
+	return fmt.Sprintf("%s (%d%%)", reclaimable, category.Reclaimable*100/category.Size)
+}
+
+func (gui *Gui) showingDiskUsage() bool {
+	return gui.getProjectContexts()[gui.State.Panels.Project.ContextIndex] == "diskUsage"
+}
+
+// rerenderDiskUsage shows the disk usage again, asking the daemon afresh if
+// refresh is set. It waits for whatever's focused now to be dealt with, e.g.
+// for the menu to close.
+func (gui *Gui) rerenderDiskUsage(refresh bool) {
+	if refresh {
+		gui.State.Panels.Project.DiskUsage = nil
+	}
+	gui.State.Panels.Main.ObjectKey = ""
+	gui.g.Update(func(g *gocui.Gui) error {
+		return gui.handleProjectSelect(g, gui.getProjectView())
+	})
+}
+
+func (gui *Gui) handleDiskUsageRefresh(g *gocui.Gui, v *gocui.View) error {
+	if !gui.showingDiskUsage() {
+		return nil
+	}
+	gui.rerenderDiskUsage(true)
+	return nil
+}
+
+// handleDiskUsageMenu lets the user look closer at one kind of thing, or prune
+// it. Each prune is the one that gets back what the tab says is reclaimable.
+func (gui *Gui) handleDiskUsageMenu(g *gocui.Gui, v *gocui.View) error {
+	if !gui.showingDiskUsage() || gui.State.Panels.Project.DiskUsage == nil {
+		return nil
+	}
+
+	showKind := func(kind string) func() error {
+		return func() error {
+			gui.State.Panels.Project.DiskUsageKind = kind
+			gui.rerenderDiskUsage(false)
+			return nil
+		}
+	}
+	prune := func(confirmText string, prune func() (commands.PruneReport, error)) func() error {
+		return func() error {
+			return gui.runPrune(v, confirmText, prune, func() error {
+				gui.rerenderDiskUsage(true)
+				return nil
+			})
+		}
+	}
+
+	options := []*commandOption{{description: gui.Tr.DiskUsageShowSummary, f: showKind("")}}
+	for _, category := range gui.State.Panels.Project.DiskUsage {
+		options = append(options, &commandOption{
+			description: fmt.Sprintf(gui.Tr.DiskUsageShow, category.Kind),
+			command:     utils.FormatDecimalBytes(int(category.Size)),
+			f:           showKind(category.Kind),
+		})
+	}
+	options = append(options,
+		&commandOption{
+			description: gui.Tr.PruneContainers,
+			command:     "docker container prune",
+			f:           prune(gui.Tr.ConfirmPruneContainers, gui.DockerCommand.PruneContainers),
+		},
+		&commandOption{
+			description: gui.Tr.PruneDanglingImages,
+			command:     "docker image prune",
+			f: prune(gui.Tr.ConfirmPruneDanglingImages, func() (commands.PruneReport, error) {
+				return gui.DockerCommand.PruneImages(false)
+			}),
+		},
+		&commandOption{
+			description: gui.Tr.PruneAllImages,
+			command:     "docker image prune --all",
+			f: prune(gui.Tr.ConfirmPruneAllImages, func() (commands.PruneReport, error) {
+				return gui.DockerCommand.PruneImages(true)
+			}),
+		},
+		&commandOption{
+			description: gui.Tr.PruneVolumes,
+			command:     "docker volume prune",
+			f:           prune(gui.Tr.ConfirmPruneVolumes, gui.DockerCommand.PruneVolumes),
+		},
+		&commandOption{
+			description: gui.Tr.PruneBuildCache,
+			command:     "docker builder prune",
+			f:           prune(gui.Tr.ConfirmPruneBuildCache, gui.DockerCommand.PruneBuildCache),
+		},
+	)
+
+	handleMenuPress := func(index int) error {
+		return options[index].f()
+	}
+
+	return gui.createMenu(gui.Tr.DiskUsageTitle, options, len(options), handleMenuPress)
+}
@@ -0,0 +1,221 @@
+package gui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/jesseduffield/gocui"
+	"github.com/jesseduffield/lazydocker/pkg/commands/ssh"
+	"github.com/jesseduffield/lazydocker/pkg/utils"
+)
+
+type dockerContextRow struct {
+	dockerContext ssh.DockerContext
+	description   string
+	current       bool
+}
+
+// GetDisplayStrings is a function.
+func (r *dockerContextRow) GetDisplayStrings(isFocused bool) []string {
+	marker := " "
+	if r.current {
+		marker = utils.ColoredString("*", color.FgGreen)
+	}
+	return []string{marker, r.dockerContext.Name, utils.ColoredString(r.dockerContext.Host, color.FgCyan), r.description}
+}
+
+// handleDockerContextsMenu lists the docker contexts, for switching to another
+// one without restarting
+func (gui *Gui) handleDockerContextsMenu(g *gocui.Gui, v *gocui.View) error {
+	if v.Name() == "menu" || v.Name() == "confirmation" {
+		return nil
+	}
+
+	dockerContexts, err := gui.DockerCommand.ListDockerContexts()
+	if err != nil {
+		return gui.createErrorPanel(gui.g, err.Error())
+	}
+
+	rows := make([]*dockerContextRow, len(dockerContexts))
+	for i, dockerContext := range dockerContexts {
+		description := dockerContext.Description
+		if dockerContext.Name == ssh.DefaultDockerContext {
+			description = gui.Tr.DefaultContextDescription
+		}
+		rows[i] = &dockerContextRow{
+			dockerContext: dockerContext,
+			description:   description,
+			current:       dockerContext.Name == gui.DockerCommand.ContextName,
+		}
+	}
+
+	handleMenuPress := func(index int) error {
+		if rows[index].current {
+			return nil
+		}
+		return gui.switchDockerContext(v, rows[index].dockerContext)
+	}
+
+	return gui.createMenu(gui.Tr.DockerContextsTitle, rows, len(rows), handleMenuPress)
+}
+
+// switchDockerContext connects to the given context, showing how it's going in
+// a popup. Once it's connected, everything is refreshed from the new daemon.
+// If it fails, the popup says why and we stay on the current context.
+func (gui *Gui) switchDockerContext(v *gocui.View, dockerContext ssh.DockerContext) error {
+	target := dockerContext.Name
+	if dockerContext.Host != "" {
+		target = dockerContext.Host
+	}
+	previous := gui.DockerCommand.ContextName
+
+	title := fmt.Sprintf(gui.Tr.ConnectingToContextTitle, dockerContext.Name)
+	connect := func(ctx context.Context, progress ssh.ProgressFunc, prompt ssh.PasswordPrompt) error {
+		return gui.DockerCommand.SwitchDockerContext(ctx, dockerContext, progress, prompt)
+	}
+	return gui.connectWithProgress(v, title, target, fmt.Sprintf(gui.Tr.StillConnectedTo, previous), connect, func() {
+		// the selections were into the old daemon's lists
+		gui.State.Panels.Containers.SelectedLine = 0
+		gui.State.Panels.Images.SelectedLine = 0
+		gui.State.Panels.Volumes.SelectedLine = 0
+		gui.State.Panels.Networks.SelectedLine = 0
+		gui.State.Panels.Project.DiskUsage = nil
+		gui.State.Panels.Main.ObjectKey = ""
+	})
+}
+
+// handleReconnect connects to the daemon all over again, for when the
+// connection's dropped. Over ssh, that means a new tunnel.
+func (gui *Gui) handleReconnect(g *gocui.Gui, v *gocui.View) error {
+	if v.Name() == "menu" || v.Name() == "confirmation" {
+		return nil
+	}
+
+	endpoint := gui.DockerCommand.Endpoint()
+	title := fmt.Sprintf(gui.Tr.ReconnectingTitle, endpoint)
+	return gui.connectWithProgress(v, title, endpoint, "", gui.DockerCommand.Reconnect, func() {})
+}
+
+// connectWithProgress connects to target with connect, showing how it's going
+// in a popup, whose esc cancels. If it works, onConnected is called and
+// everything's refreshed; if not, the popup says why, and then failedNote. If
+// the host wants a password, the popup asks for it.
+func (gui *Gui) connectWithProgress(v *gocui.View, title, target, failedNote string, connect func(context.Context, ssh.ProgressFunc, ssh.PasswordPrompt) error, onConnected func()) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	handleClose := func(g *gocui.Gui, v *gocui.View) error {
+		cancel()
+		return nil
+	}
+
+	status := fmt.Sprintf(gui.Tr.ConnectingToContext, target)
+	if err := gui.createPopupPanel(gui.g, v, title, status, true, handleClose, handleClose); err != nil {
+		cancel()
+		return err
+	}
+
+	go func() {
+		defer cancel()
+
+		progress := func(attempt int, remaining time.Duration) {
+			gui.renderPopupProgress(status+"\n"+fmt.Sprintf(gui.Tr.TunnelDialAttempt, attempt, remaining.Round(time.Second)), true)
+		}
+		prompt := func(user, host string) ([]byte, error) {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			password, err := gui.promptSSHPassword(v, user, host)
+			if err != nil {
+				cancel()
+				return nil, err
+			}
+			// back to how the connecting's going
+			if err := gui.createPopupPanel(gui.g, v, title, status, true, handleClose, handleClose); err != nil {
+				gui.Log.Error(err)
+			}
+			return password, nil
+		}
+		err := connect(ctx, progress, prompt)
+		if err != nil {
+			if ctx.Err() != nil {
+				// the user closed the popup, so there's nobody to tell
+				return
+			}
+			message := []string{status, utils.ColoredString(err.Error(), color.FgRed)}
+			if failedNote != "" {
+				message = append(message, failedNote)
+			}
+			gui.renderPopupProgress(strings.Join(message, "\n\n"), false)
+			return
+		}
+
+		onConnected()
+
+		gui.g.Update(func(g *gocui.Gui) error {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return gui.closeConfirmationPrompt(g)
+		})
+
+		if err := gui.refreshProject(); err != nil {
+			gui.Log.Error(err)
+		}
+		if err := gui.refreshContainersAndServices(); err != nil {
+			gui.Log.Error(err)
+		}
+		if err := gui.refreshImages(); err != nil {
+			gui.Log.Error(err)
+		}
+		if err := gui.refreshVolumes(); err != nil {
+			gui.Log.Error(err)
+		}
+		if err := gui.refreshNetworks(); err != nil {
+			gui.Log.Error(err)
+		}
+	}()
+
+	return nil
+}
+
+// promptSSHPassword swaps the connecting popup for a prompt for the ssh
+// password of user on host, and waits for it. Closing the prompt gives
+// context.Canceled. The prompt's cleared as soon as it's read, but the
+// copies gocui makes along the way are out of our reach.
+func (gui *Gui) promptSSHPassword(v *gocui.View, user, host string) ([]byte, error) {
+	passwords := make(chan []byte, 1)
+	gui.g.Update(func(g *gocui.Gui) error {
+		if err := gui.closeConfirmationPrompt(g); err != nil {
+			close(passwords)
+			return err
+		}
+		gui.onNewPopupPanel()
+		promptView, err := gui.prepareConfirmationPanel(v, fmt.Sprintf(gui.Tr.SSHPasswordTitle, user, host), "", false)
+		if err != nil {
+			close(passwords)
+			return err
+		}
+		promptView.Editable = true
+		promptView.Mask = '*'
+
+		handleConfirm := func(g *gocui.Gui, promptView *gocui.View) error {
+			passwords <- []byte(strings.TrimRight(promptView.Buffer(), "\n"))
+			promptView.Clear()
+			return nil
+		}
+		handleClose := func(g *gocui.Gui, promptView *gocui.View) error {
+			promptView.Clear()
+			close(passwords)
+			return nil
+		}
+		return gui.setPromptKeyBindings(g, handleConfirm, handleClose)
+	})
+
+	password, ok := <-passwords
+	if !ok {
+		return nil, context.Canceled
+	}
+	return password, nil
+}
@@ -0,0 +1,121 @@
+package gui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types/events"
+	"github.com/fatih/color"
+	"github.com/jesseduffield/gocui"
+	"github.com/jesseduffield/lazydocker/pkg/commands"
+	"github.com/jesseduffield/lazydocker/pkg/utils"
+)
+
+// eventTypeColors are what each kind of object's events are shown in. Others
+// are left white.
+var eventTypeColors = map[string]color.Attribute{
+	"container": color.FgCyan,
+	"image":     color.FgMagenta,
+	"volume":    color.FgYellow,
+	"network":   color.FgGreen,
+	"daemon":    color.FgBlue,
+}
+
+// alarmingEventActions are shown in red, being the ones you'd want to notice
+var alarmingEventActions = map[string]bool{"die": true, "kill": true, "oom": true, "destroy": true, "delete": true}
+
+func (gui *Gui) getEventsTitle() string {
+	if gui.State.Panels.Project.EventFilterText == "" {
+		return gui.Tr.EventsTitle
+	}
+	return fmt.Sprintf("%s (%s)", gui.Tr.EventsTitle, gui.State.Panels.Project.EventFilterText)
+}
+
+// renderEvents shows the daemon's events in the main panel, adding to them as
+// they come in. After the first render we leave the scroll position alone, so
+// that scrolling back through the feed isn't interrupted.
+func (gui *Gui) renderEvents() error {
+	mainView := gui.getMainView()
+	mainView.Autoscroll = true
+	mainView.Wrap = gui.Config.UserConfig.Gui.WrapMainPanel
+
+	filter := gui.State.Panels.Project.EventFilter
+	return gui.T.NewTask(func(stop chan struct{}) {
+		gui.renderString(gui.g, "main", gui.formatEvents(filter))
+		for {
+			select {
+			case <-stop:
+				return
+			case <-gui.DockerCommand.EventsChanged():
+				gui.reRenderString(gui.g, "main", gui.formatEvents(filter))
+			}
+		}
+	})
+}
+
+func (gui *Gui) formatEvents(filter commands.EventFilter) string {
+	messages, err := gui.DockerCommand.Events()
+
+	rows := [][]string{}
+	for _, message := range messages {
+		if filter.Matches(message) {
+			rows = append(rows, formatEvent(message))
+		}
+	}
+	output := gui.Tr.NoEvents
+	if len(rows) > 0 {
+		var renderErr error
+		output, renderErr = utils.RenderTable(rows)
+		if renderErr != nil {
+			gui.Log.Error(renderErr)
+			output = renderErr.Error()
+		}
+	}
+
+	if err != nil {
+		output += "\n" + utils.ColoredString(fmt.Sprintf(gui.Tr.EventStreamDown, err), color.FgRed)
+	}
+	return output
+}
+
+func formatEvent(message events.Message) []string {
+	typeColor, ok := eventTypeColors[message.Type]
+	if !ok {
+		typeColor = color.FgWhite
+	}
+	actionColor := color.FgWhite
+	if alarmingEventActions[message.Action] {
+		actionColor = color.FgRed
+	}
+	return []string{
+		time.Unix(0, message.TimeNano).Format("15:04:05"),
+		utils.ColoredString(message.Type, typeColor),
+		utils.ColoredString(message.Action, actionColor),
+		commands.EventResource(message),
+	}
+}
+
+// handleEventsFilter asks what to narrow the events tab down by
+func (gui *Gui) handleEventsFilter(g *gocui.Gui, v *gocui.View) error {
+	if gui.getProjectContexts()[gui.State.Panels.Project.ContextIndex] != "events" {
+		return nil
+	}
+
+	return gui.createPromptPanel(g, v, gui.Tr.FilterEventsTitle, func(g *gocui.Gui, promptView *gocui.View) error {
+		text := strings.Join(strings.Fields(gui.trimmedContent(promptView)), " ")
+		filter, err := commands.ParseEventFilter(text)
+		if err != nil {
+			return gui.createErrorPanel(gui.g, err.Error())
+		}
+		gui.State.Panels.Project.EventFilter = filter
+		gui.State.Panels.Project.EventFilterText = text
+
+		// once the prompt has closed and we're back on the project panel
+		gui.State.Panels.Main.ObjectKey = ""
+		gui.g.Update(func(g *gocui.Gui) error {
+			return gui.handleProjectSelect(g, v)
+		})
+		return nil
+	})
+}
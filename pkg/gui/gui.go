@@ -18,6 +18,7 @@ import (
 
 	"github.com/jesseduffield/gocui"
 	"github.com/jesseduffield/lazydocker/pkg/commands"
+	"github.com/jesseduffield/lazydocker/pkg/commands/ssh"
 	"github.com/jesseduffield/lazydocker/pkg/config"
 	"github.com/jesseduffield/lazydocker/pkg/i18n"
 	"github.com/jesseduffield/lazydocker/pkg/tasks"
@@ -34,6 +35,7 @@ type SentinelErrors struct {
 	ErrNoContainers error
 	ErrNoImages     error
 	ErrNoVolumes    error
+	ErrNoNetworks   error
 }
 
 // GenerateSentinelErrors makes the sentinel errors for the gui. We're defining it here
@@ -52,6 +54,7 @@ func (gui *Gui) GenerateSentinelErrors() {
 		ErrNoContainers: errors.New(gui.Tr.NoContainers),
 		ErrNoImages:     errors.New(gui.Tr.NoImages),
 		ErrNoVolumes:    errors.New(gui.Tr.NoVolumes),
+		ErrNoNetworks:   errors.New(gui.Tr.NoNetworks),
 	}
 }
 
@@ -71,6 +74,10 @@ type Gui struct {
 	T             *tasks.TaskManager
 	ErrorChan     chan error
 	CyclableViews []string
+
+	// selectedLineBgColor is the theme's background for the selected line,
+	// which gocui wants on each view
+	selectedLineBgColor gocui.Attribute
 }
 
 type servicePanelState struct {
@@ -81,20 +88,73 @@ type servicePanelState struct {
 type containerPanelState struct {
 	SelectedLine int
 	ContextIndex int // for specifying if you are looking at logs/stats/config/etc
+	// Filter is the last valid filter typed into the filter bar, which we
+	// keep for the rest of the session
+	Filter string
+	// Marked holds the IDs of the containers marked for a bulk action
+	Marked map[string]bool
+	// Collapsed holds the names of the compose projects whose containers are
+	// hidden under their heading
+	Collapsed map[string]bool
 }
 
 type projectState struct {
 	ContextIndex int // for specifying if you are looking at credits/logs
+	// EventFilter narrows down the events tab, and EventFilterText is what was
+	// typed to get it
+	EventFilter     commands.EventFilter
+	EventFilterText string
+	// DiskUsage is what the daemon last told us about disk usage, or nil if
+	// we've yet to ask, and DiskUsageKind is the kind of thing we're looking
+	// closer at, or blank for the summary
+	DiskUsage     []commands.DiskUsageCategory
+	DiskUsageKind string
 }
 
 type menuPanelState struct {
 	SelectedLine int
 	OnPress      func(*gocui.Gui, *gocui.View) error
+	// OnClose, if set, is called once when the menu is closed
+	OnClose func() error
 }
 
 type mainPanelState struct {
 	// ObjectKey tells us what context we are in. For example, if we are looking at the logs of a particular service in the services panel this key might be 'services-<service id>-logs'. The key is made so that if something changes which might require us to re-run the logs command or run a different command, the key will be different, and we'll then know to do whatever is required. Object key probably isn't the best name for this but Context is already used to refer to tabs. Maybe I should just call them tabs.
 	ObjectKey string
+	// LogsSinceIndex is which of getLogsSinceOptions we're showing container
+	// logs from
+	LogsSinceIndex int
+	// InspectJSON is the inspect output we last rendered, for copying, and
+	// InspectKey is the ObjectKey it was rendered for
+	InspectJSON string
+	InspectKey  string
+	// EnvVars are the environment variables we last rendered, for copying,
+	// and EnvKey is the ObjectKey they were rendered for
+	EnvVars []commands.EnvVar
+	EnvKey  string
+	// Labels are the labels we last rendered, for copying, and LabelsKey is
+	// the ObjectKey they were rendered for
+	Labels    []commands.Label
+	LabelsKey string
+	// RevealSecrets is whether the env tab shows the values of variables that
+	// look like secrets, rather than masking them
+	RevealSecrets bool
+	// MergedLogs are the logs the merged logs tab is following, for showing
+	// and hiding containers in
+	MergedLogs *commands.MergedLogs
+	// MergedLogLines are the merged log lines as last shown, so that we know
+	// which container each line is from
+	MergedLogLines []commands.MergedLogLine
+	// SearchTerm is what we last searched the main panel for
+	SearchTerm string
+	// SearchRegex is true if the search term is a regex rather than text
+	SearchRegex bool
+	// SearchCaseSensitive is true if searches match case rather than ignoring it
+	SearchCaseSensitive bool
+	// LogsWriter writes the logs the main panel is showing while LogsKey is its
+	// ObjectKey, for highlighting searches in
+	LogsWriter *logsWriter
+	LogsKey    string
 }
 
 type imagePanelState struct {
@@ -105,6 +165,22 @@ type imagePanelState struct {
 type volumePanelState struct {
 	SelectedLine int
 	ContextIndex int
+	// BrowseDirs holds the directory we were last in when browsing each
+	// volume, so that we can pick up where we left off
+	BrowseDirs map[string]string
+}
+
+type networkPanelState struct {
+	SelectedLine int
+	ContextIndex int
+}
+
+type searchPanelState struct {
+	// Query is what the results are for, and SelectedLine is the result that
+	// enter jumps to
+	Query        string
+	Results      []commands.SearchResult
+	SelectedLine int
 }
 
 type panelStates struct {
@@ -114,7 +190,10 @@ type panelStates struct {
 	Main       *mainPanelState
 	Images     *imagePanelState
 	Volumes    *volumePanelState
+	Networks   *networkPanelState
 	Project    *projectState
+	Search     *searchPanelState
+	Palette    *commandPaletteState
 }
 
 type guiState struct {
@@ -125,6 +204,10 @@ type guiState struct {
 	SubProcessOutput string
 	Stats            map[string]commands.ContainerStats
 
+	// PendingSelections are what was selected in each panel last run, by
+	// panel, until the panel's loaded and we've put the selection back
+	PendingSelections map[string]string
+
 	// SessionIndex tells us how many times we've come back from a subprocess.
 	// We increment it each time we switch to a new subprocess
 	// Every time we go to a subprocess we need to close a few goroutines so this index is used for that purpose
@@ -137,22 +220,26 @@ func NewGui(log *logrus.Entry, dockerCommand *commands.DockerCommand, oSCommand
 		Platform: *oSCommand.Platform,
 		Panels: &panelStates{
 			Services:   &servicePanelState{SelectedLine: -1, ContextIndex: 0},
-			Containers: &containerPanelState{SelectedLine: -1, ContextIndex: 0},
+			Containers: &containerPanelState{SelectedLine: -1, ContextIndex: 0, Marked: map[string]bool{}, Collapsed: map[string]bool{}},
 			Images:     &imagePanelState{SelectedLine: -1, ContextIndex: 0},
-			Volumes:    &volumePanelState{SelectedLine: -1, ContextIndex: 0},
+			Volumes:    &volumePanelState{SelectedLine: -1, ContextIndex: 0, BrowseDirs: map[string]string{}},
+			Networks:   &networkPanelState{SelectedLine: -1, ContextIndex: 0},
 			Menu:       &menuPanelState{SelectedLine: 0},
 			Main: &mainPanelState{
-				ObjectKey: "",
+				ObjectKey:      "",
+				LogsSinceIndex: 1,
 			},
 			Project: &projectState{ContextIndex: 0},
+			Search:  &searchPanelState{},
+			Palette: &commandPaletteState{},
 		},
 		SessionIndex:  0,
 		PreviousViews: stack.New(),
 	}
 
-	cyclableViews := []string{"project", "containers", "images", "volumes"}
+	cyclableViews := []string{"project", "containers", "images", "volumes", "networks"}
 	if dockerCommand.InDockerComposeProject {
-		cyclableViews = []string{"project", "services", "containers", "images", "volumes"}
+		cyclableViews = []string{"project", "services", "containers", "images", "volumes", "networks"}
 	}
 
 	gui := &Gui{
@@ -170,6 +257,7 @@ func NewGui(log *logrus.Entry, dockerCommand *commands.DockerCommand, oSCommand
 	}
 
 	gui.GenerateSentinelErrors()
+	gui.restoreAppState()
 
 	return gui, nil
 }
@@ -216,6 +304,32 @@ func (gui *Gui) goEvery(interval time.Duration, function func() error) {
 	}()
 }
 
+// goRefreshEvery is goEvery for refreshing a side panel. How long it waits
+// between refreshes is worked out afresh each time, as it depends on whether
+// we're talking to the daemon over ssh, and it skips refreshing while a popup
+// is open unless we've been told not to.
+func (gui *Gui) goRefreshEvery(panelInterval time.Duration, function func() error) {
+	currentSessionIndex := gui.State.SessionIndex
+	_ = function()
+	go func() {
+		for {
+			time.Sleep(gui.refreshInterval(panelInterval))
+			if gui.State.SessionIndex > currentSessionIndex {
+				return
+			}
+			if gui.popupPanelFocused() && !gui.Config.UserConfig.Update.RefreshUnderPopups {
+				continue
+			}
+			_ = function()
+		}
+	}()
+}
+
+func (gui *Gui) refreshInterval(panelInterval time.Duration) time.Duration {
+	overSSH := ssh.IsSSHDockerHost(gui.DockerCommand.Endpoint())
+	return gui.Config.UserConfig.Update.RefreshInterval(panelInterval, overSSH)
+}
+
 // Run setup the gui with keybindings and start the mainloop
 func (gui *Gui) Run() error {
 	// closing our task manager which in turn closes the current task if there is any, so we aren't leaving processes lying around after closing lazydocker
@@ -227,10 +341,8 @@ func (gui *Gui) Run() error {
 	}
 	defer g.Close()
 
-	// forgive the double-negative, this is because of my yaml `omitempty` woes
-	if !gui.Config.UserConfig.Gui.IgnoreMouseEvents {
-		g.Mouse = true
-	}
+	// terminals that don't report the mouse just never send us any mouse events
+	g.Mouse = gui.Config.UserConfig.Gui.MouseEnabled()
 
 	gui.g = g // TODO: always use gui.g rather than passing g around everywhere
 
@@ -240,18 +352,21 @@ func (gui *Gui) Run() error {
 
 	gui.waitForIntro.Add(1)
 
-	dockerRefreshInterval := gui.Config.UserConfig.Update.DockerRefreshInterval
+	panelRefreshIntervals := gui.Config.UserConfig.Update.Panels
 	go func() {
 		gui.waitForIntro.Wait()
 		gui.goEvery(time.Millisecond*30, gui.reRenderMain)
-		gui.goEvery(dockerRefreshInterval, gui.refreshProject)
-		gui.goEvery(dockerRefreshInterval, gui.refreshContainersAndServices)
-		gui.goEvery(dockerRefreshInterval, gui.refreshVolumes)
+		gui.goRefreshEvery(panelRefreshIntervals.Project, gui.refreshProject)
+		gui.goRefreshEvery(panelRefreshIntervals.Containers, gui.refreshContainersAndServices)
+		gui.goRefreshEvery(panelRefreshIntervals.Volumes, gui.refreshVolumes)
+		gui.goRefreshEvery(panelRefreshIntervals.Networks, gui.refreshNetworks)
 		gui.goEvery(time.Millisecond*1000, gui.DockerCommand.UpdateContainerDetails)
 		gui.goEvery(time.Millisecond*1000, gui.checkForContextChange)
+		gui.goEvery(time.Millisecond*2000, gui.checkConnection)
 	}()
 
 	gui.DockerCommand.MonitorContainerStats()
+	gui.DockerCommand.MonitorEvents()
 
 	go func() {
 		for err := range gui.ErrorChan {
@@ -363,6 +478,11 @@ func (gui *Gui) shouldRefresh(key string) bool {
 }
 
 func (gui *Gui) initiallyFocusedViewName() string {
+	for _, viewName := range gui.CyclableViews {
+		if viewName == gui.appState().FocusedPanel {
+			return viewName
+		}
+	}
 	if gui.DockerCommand.InDockerComposeProject {
 		return "services"
 	}
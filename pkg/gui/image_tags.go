@@ -0,0 +1,88 @@
+package gui
+
+import (
+	"fmt"
+
+	"github.com/jesseduffield/gocui"
+	"github.com/jesseduffield/lazydocker/pkg/commands"
+)
+
+// handleImageTag asks for a new tag for the selected image. If the tag is
+// already on another image, we check before moving it over.
+func (gui *Gui) handleImageTag(g *gocui.Gui, v *gocui.View) error {
+	image, err := gui.getSelectedImage()
+	if err != nil {
+		return nil
+	}
+
+	return gui.createPromptPanel(g, v, gui.Tr.TagImageTitle, func(g *gocui.Gui, promptView *gocui.View) error {
+		input := gui.trimmedContent(promptView)
+		if input == "" {
+			return nil
+		}
+		tag, err := commands.NormaliseImageTag(input)
+		if err != nil {
+			return gui.createErrorPanel(gui.g, err.Error())
+		}
+
+		addTag := func(g *gocui.Gui, v *gocui.View) error {
+			return gui.WithWaitingStatus(gui.Tr.TaggingStatus, func() error {
+				if err := image.AddTag(tag); err != nil {
+					return err
+				}
+				return gui.refreshImages()
+			})
+		}
+
+		other := gui.DockerCommand.ImageWithTag(tag)
+		if other != nil && other.ID == image.ID {
+			return gui.createErrorPanel(gui.g, fmt.Sprintf(gui.Tr.AlreadyTagged, tag))
+		}
+		if other == nil {
+			return addTag(g, v)
+		}
+		// once the prompt has closed
+		gui.g.Update(func(g *gocui.Gui) error {
+			message := fmt.Sprintf(gui.Tr.ConfirmMoveTag, tag, commands.ShortID(other.ID))
+			return gui.createConfirmationPanel(g, v, gui.Tr.Confirm, message, addTag, nil)
+		})
+		return nil
+	})
+}
+
+// handleImageUntag offers to take one of the selected image's tags off it.
+// The last tag stays, as removing it would remove the image.
+func (gui *Gui) handleImageUntag(g *gocui.Gui, v *gocui.View) error {
+	image, err := gui.getSelectedImage()
+	if err != nil {
+		return nil
+	}
+
+	tags := image.Tags()
+	if len(tags) < 2 {
+		return gui.createErrorPanel(gui.g, gui.Tr.CannotRemoveOnlyTag)
+	}
+
+	options := make([]*commandOption, len(tags))
+	for i, tag := range tags {
+		tag := tag
+		options[i] = &commandOption{
+			description: tag,
+			command:     "docker image rm " + tag,
+			f: func() error {
+				return gui.WithWaitingStatus(gui.Tr.UntaggingStatus, func() error {
+					if err := image.RemoveTag(tag); err != nil {
+						return err
+					}
+					return gui.refreshImages()
+				})
+			},
+		}
+	}
+
+	handleMenuPress := func(index int) error {
+		return options[index].f()
+	}
+
+	return gui.createMenu(gui.Tr.UntagImageTitle, options, len(options), handleMenuPress)
+}
@@ -1,7 +1,9 @@
 package gui
 
 import (
+	"context"
 	"fmt"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -17,11 +19,11 @@ import (
 // list panel functions
 
 func (gui *Gui) getImageContexts() []string {
-	return []string{"config"}
+	return []string{"config", "labels", "history", "inspect"}
 }
 
 func (gui *Gui) getImageContextTitles() []string {
-	return []string{gui.Tr.ConfigTitle}
+	return []string{gui.Tr.ConfigTitle, gui.Tr.LabelsTitle, gui.Tr.HistoryTitle, gui.Tr.InspectTitle}
 }
 
 func (gui *Gui) getSelectedImage() (*commands.Image, error) {
@@ -68,6 +70,18 @@ func (gui *Gui) handleImageSelect(g *gocui.Gui, v *gocui.View) error {
 		if err := gui.renderImageConfig(mainView, Image); err != nil {
 			return err
 		}
+	case "labels":
+		if err := gui.renderLabels(Image.Labels(), gui.Tr.ImageLabelsReadOnly); err != nil {
+			return err
+		}
+	case "history":
+		if err := gui.renderImageHistory(mainView, Image); err != nil {
+			return err
+		}
+	case "inspect":
+		if err := gui.renderInspect(Image.InspectRaw); err != nil {
+			return err
+		}
 	default:
 		return errors.New("Unknown context for Images panel")
 	}
@@ -85,29 +99,70 @@ func (gui *Gui) renderImageConfig(mainView *gocui.View, image *commands.Image) e
 		output += utils.WithPadding("Size: ", padding) + utils.FormatDecimalBytes(int(image.Image.Size)) + "\n"
 		output += utils.WithPadding("Created: ", padding) + fmt.Sprintf("%v", time.Unix(image.Image.Created, 0).Format(time.RFC1123)) + "\n"
 
+		mainView.Autoscroll = false
+		mainView.Wrap = gui.Config.UserConfig.Gui.WrapMainPanel
+
+		gui.renderString(gui.g, "main", output)
+	})
+}
+
+// renderImageHistory shows the layers the image is made of, newest first, with
+// the ones that take up the most space highlighted
+func (gui *Gui) renderImageHistory(mainView *gocui.View, image *commands.Image) error {
+	return gui.T.NewTask(func(stop chan struct{}) {
 		history, err := image.RenderHistory()
 		if err != nil {
 			gui.Log.Error(err)
+			history = err.Error()
 		}
 
-		output += "\n\n" + history
-
 		mainView.Autoscroll = false
 		mainView.Wrap = false // don't care what your config is this page is ugly without wrapping
 
-		gui.renderString(gui.g, "main", output)
+		gui.renderString(gui.g, "main", history)
 	})
 }
 
+func (gui *Gui) handleImageHistory(g *gocui.Gui, v *gocui.View) error {
+	if _, err := gui.getSelectedImage(); err != nil {
+		return nil
+	}
+	return gui.showContext(v, "history", &gui.State.Panels.Images.ContextIndex, gui.getImageContexts(), gui.handleImageSelect)
+}
+
 func (gui *Gui) refreshImages() error {
 	ImagesView := gui.getImagesView()
 	if ImagesView == nil {
 		// if the ImagesView hasn't been instantiated yet we just return
 		return nil
 	}
+	// keep track of the selected image, which moves when the images are re-sorted
+	selectedImage, _ := gui.getSelectedImage()
 	if err := gui.refreshStateImages(); err != nil {
 		return err
 	}
+	if selectedImage.ID != "" {
+		for i, image := range gui.DockerCommand.Images {
+			if image.ID != selectedImage.ID {
+				continue
+			}
+			if i != gui.State.Panels.Images.SelectedLine {
+				gui.State.Panels.Images.SelectedLine = i
+				if err := gui.focusPoint(0, i, len(gui.DockerCommand.Images), ImagesView); err != nil {
+					return err
+				}
+			}
+			break
+		}
+	}
+
+	imageIDs := make([]string, len(gui.DockerCommand.Images))
+	for i, image := range gui.DockerCommand.Images {
+		imageIDs[i] = image.ID
+	}
+	if err := gui.restoreSelection(ImagesView, imageIDs, &gui.State.Panels.Images.SelectedLine); err != nil {
+		return err
+	}
 
 	if len(gui.DockerCommand.Images) > 0 && gui.State.Panels.Images.SelectedLine == -1 {
 		gui.State.Panels.Images.SelectedLine = 0
@@ -119,6 +174,7 @@ func (gui *Gui) refreshImages() error {
 	gui.g.Update(func(g *gocui.Gui) error {
 
 		ImagesView.Clear()
+		ImagesView.Title = gui.getImagesTitle()
 		isFocused := gui.g.CurrentView().Name() == "Images"
 		list, err := utils.RenderList(gui.DockerCommand.Images, utils.IsFocused(isFocused))
 		if err != nil {
@@ -250,15 +306,268 @@ func (gui *Gui) handleImagesRemoveMenu(g *gocui.Gui, v *gocui.View) error {
 }
 
 func (gui *Gui) handlePruneImages() error {
-	return gui.createConfirmationPanel(gui.g, gui.getImagesView(), gui.Tr.Confirm, gui.Tr.ConfirmPruneImages, func(g *gocui.Gui, v *gocui.View) error {
-		return gui.WithWaitingStatus(gui.Tr.PruningStatus, func() error {
-			err := gui.DockerCommand.PruneImages()
-			if err != nil {
-				return gui.createErrorPanel(gui.g, err.Error())
+	return gui.runPrune(gui.getImagesView(), gui.Tr.ConfirmPruneDanglingImages, func() (commands.PruneReport, error) {
+		return gui.DockerCommand.PruneImages(false)
+	}, gui.refreshImages)
+}
+
+func (gui *Gui) handlePruneAllImages() error {
+	return gui.runPrune(gui.getImagesView(), gui.Tr.ConfirmPruneAllImages, func() (commands.PruneReport, error) {
+		return gui.DockerCommand.PruneImages(true)
+	}, gui.refreshImages)
+}
+
+func (gui *Gui) handleImagesPull(g *gocui.Gui, v *gocui.View) error {
+	return gui.createPromptPanel(g, v, gui.Tr.PullImageTitle, func(g *gocui.Gui, promptView *gocui.View) error {
+		ref := gui.trimmedContent(promptView)
+		if ref == "" {
+			return nil
+		}
+		return gui.pullImage(ref)
+	})
+}
+
+// pullImage pulls ref, showing its progress in a popup. Closing the popup
+// before it's done cancels the pull.
+func (gui *Gui) pullImage(ref string) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	handleClose := func(g *gocui.Gui, v *gocui.View) error {
+		cancel()
+		return nil
+	}
+
+	title := fmt.Sprintf(gui.Tr.PullingImageTitle, ref)
+	if err := gui.createPopupPanel(gui.g, gui.getImagesView(), title, gui.Tr.PullStartingStatus, true, handleClose, handleClose); err != nil {
+		cancel()
+		return err
+	}
+
+	go func() {
+		defer cancel()
+
+		rendered := ""
+		err := gui.DockerCommand.PullImage(ctx, ref, func(progress *commands.ImagePullProgress) {
+			rendered = progress.Render()
+			gui.renderPopupProgress(rendered, true)
+		})
+		if ctx.Err() != nil {
+			// the user closed the popup, so there's nobody to tell
+			return
+		}
+		if err != nil {
+			rendered = strings.TrimSpace(rendered + "\n\n" + utils.ColoredString(err.Error(), color.FgRed))
+		}
+		gui.renderPopupProgress(rendered, false)
+
+		if err := gui.refreshImages(); err != nil {
+			gui.Log.Error(err)
+		}
+	}()
+
+	return nil
+}
+
+// handleImagesBuild asks what to build and what to tag it, then builds it.
+// Relative paths are from the project directory.
+func (gui *Gui) handleImagesBuild(g *gocui.Gui, v *gocui.View) error {
+	projectDir := gui.Config.ProjectDir
+	dirTitle := fmt.Sprintf(gui.Tr.BuildImageDirTitle, projectDir)
+	return gui.createPromptPanel(g, v, dirTitle, func(g *gocui.Gui, promptView *gocui.View) error {
+		path := gui.trimmedContent(promptView)
+		if path == "" {
+			path = projectDir
+		} else if !filepath.IsAbs(path) {
+			path = filepath.Join(projectDir, path)
+		}
+		dir, dockerfile, err := commands.FindDockerfile(path)
+		if err != nil {
+			return gui.createErrorPanel(g, err.Error())
+		}
+
+		defaultTag := commands.DefaultBuildTag(dir)
+		// once the first prompt has closed
+		g.Update(func(g *gocui.Gui) error {
+			return gui.createPromptPanel(g, v, fmt.Sprintf(gui.Tr.BuildImageTagTitle, defaultTag), func(g *gocui.Gui, promptView *gocui.View) error {
+				tag := gui.trimmedContent(promptView)
+				if tag == "" {
+					tag = defaultTag
+				}
+				tag, err := commands.NormaliseImageTag(tag)
+				if err != nil {
+					return gui.createErrorPanel(g, err.Error())
+				}
+				return gui.buildImage(dir, dockerfile, tag)
+			})
+		})
+		return nil
+	})
+}
+
+// buildImage builds the image in dir, showing BuildKit's progress in a popup.
+// If it fails, the popup keeps the failing step's output. Closing the popup
+// before it's done cancels the build.
+func (gui *Gui) buildImage(dir, dockerfile, tag string) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	handleClose := func(g *gocui.Gui, v *gocui.View) error {
+		cancel()
+		return nil
+	}
+
+	title := fmt.Sprintf(gui.Tr.BuildingImageTitle, tag)
+	if err := gui.createPopupPanel(gui.g, gui.getImagesView(), title, gui.Tr.BuildStartingStatus, true, handleClose, handleClose); err != nil {
+		cancel()
+		return err
+	}
+
+	go func() {
+		defer cancel()
+
+		rendered := ""
+		err := gui.DockerCommand.BuildImage(ctx, dir, dockerfile, tag, func(progress *commands.ImageBuildProgress) {
+			rendered = progress.Render()
+			gui.renderPopupProgress(rendered, true)
+		})
+		if ctx.Err() != nil {
+			// the user closed the popup, so there's nobody to tell
+			return
+		}
+		if err != nil {
+			rendered = strings.TrimSpace(rendered + "\n\n" + utils.ColoredString(err.Error(), color.FgRed))
+		}
+		gui.renderPopupProgress(rendered, false)
+
+		if err := gui.refreshImages(); err != nil {
+			gui.Log.Error(err)
+		}
+	}()
+
+	return nil
+}
+
+// handleImagePush pushes the selected image, asking which tag to push if it
+// has more than one
+func (gui *Gui) handleImagePush(g *gocui.Gui, v *gocui.View) error {
+	image, err := gui.getSelectedImage()
+	if err != nil {
+		return nil
+	}
+
+	tags := image.Tags()
+	switch len(tags) {
+	case 0:
+		return gui.createErrorPanel(gui.g, gui.Tr.CannotPushUntagged)
+	case 1:
+		return gui.pushImage(tags[0], nil)
+	}
+
+	options := make([]*commandOption, len(tags))
+	for i, tag := range tags {
+		tag := tag
+		options[i] = &commandOption{
+			description: tag,
+			command:     "docker push " + tag,
+			f:           func() error { return gui.pushImage(tag, nil) },
+		}
+	}
+
+	handleMenuPress := func(index int) error {
+		return options[index].f()
+	}
+
+	return gui.createMenu(gui.Tr.PushImageTitle, options, len(options), handleMenuPress)
+}
+
+// pushImage pushes ref, showing its progress in a popup. Closing the popup
+// before it's done cancels the push. If the registry turns us down, pressing
+// enter on the popup asks for credentials to try again with.
+func (gui *Gui) pushImage(ref string, credentials *commands.RegistryCredentials) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	// only touched from the gui's goroutine
+	authFailed := false
+	handleConfirm := func(g *gocui.Gui, v *gocui.View) error {
+		cancel()
+		if authFailed {
+			// once the popup has closed
+			g.Update(func(g *gocui.Gui) error {
+				return gui.promptRegistryLogin(ref)
+			})
+		}
+		return nil
+	}
+	handleClose := func(g *gocui.Gui, v *gocui.View) error {
+		cancel()
+		return nil
+	}
+
+	title := fmt.Sprintf(gui.Tr.PushingImageTitle, ref)
+	if err := gui.createPopupPanel(gui.g, gui.getImagesView(), title, gui.Tr.PushStartingStatus, true, handleConfirm, handleClose); err != nil {
+		cancel()
+		return err
+	}
+
+	go func() {
+		defer cancel()
+
+		rendered := ""
+		err := gui.DockerCommand.PushImage(ctx, ref, credentials, func(progress *commands.ImagePullProgress) {
+			rendered = progress.Render()
+			gui.renderPopupProgress(rendered, true)
+		})
+		if ctx.Err() != nil {
+			// the user closed the popup, so there's nobody to tell
+			return
+		}
+		if err != nil {
+			rendered = strings.TrimSpace(rendered + "\n\n" + utils.ColoredString(err.Error(), color.FgRed))
+			if commands.IsRegistryAuthError(err) {
+				rendered += "\n\n" + fmt.Sprintf(gui.Tr.PushLoginHint, commands.RegistryDomain(ref))
+				gui.g.Update(func(*gocui.Gui) error {
+					authFailed = true
+					return nil
+				})
 			}
-			return gui.refreshImages()
+		}
+		gui.renderPopupProgress(rendered, false)
+	}()
+
+	return nil
+}
+
+// promptRegistryLogin asks for a username and password for ref's registry,
+// then pushes ref again with them. They're only used for the one push, rather
+// than saved like docker login would.
+func (gui *Gui) promptRegistryLogin(ref string) error {
+	domain := commands.RegistryDomain(ref)
+	return gui.createPromptPanel(gui.g, gui.getImagesView(), fmt.Sprintf(gui.Tr.RegistryUsernameTitle, domain), func(g *gocui.Gui, promptView *gocui.View) error {
+		username := gui.trimmedContent(promptView)
+		if username == "" {
+			return nil
+		}
+		// once the username prompt has closed
+		g.Update(func(g *gocui.Gui) error {
+			return gui.createPasswordPromptPanel(g, gui.getImagesView(), fmt.Sprintf(gui.Tr.RegistryPasswordTitle, username, domain), func(g *gocui.Gui, promptView *gocui.View) error {
+				password := strings.TrimRight(promptView.Buffer(), "\n")
+				return gui.pushImage(ref, &commands.RegistryCredentials{Username: username, Password: password})
+			})
 		})
-	}, nil)
+		return nil
+	})
+}
+
+// renderPopupProgress shows how a long-running job is going, e.g. a pull, in
+// the popup we opened for it with createPopupPanel
+func (gui *Gui) renderPopupProgress(content string, loading bool) {
+	gui.g.Update(func(g *gocui.Gui) error {
+		v, err := g.View("confirmation")
+		if err != nil {
+			return nil // the popup has been closed
+		}
+		v.HasLoader = loading
+		if err := gui.setViewContent(g, v, content); err != nil {
+			return err
+		}
+		return gui.resizePopupPanel(g, v)
+	})
 }
 
 func (gui *Gui) handleImagesCustomCommand(g *gocui.Gui, v *gocui.View) error {
@@ -279,9 +588,13 @@ func (gui *Gui) handleImagesCustomCommand(g *gocui.Gui, v *gocui.View) error {
 func (gui *Gui) handleImagesBulkCommand(g *gocui.Gui, v *gocui.View) error {
 	baseBulkCommands := []config.CustomCommand{
 		{
-			Name:             gui.Tr.PruneImages,
+			Name:             gui.Tr.PruneDanglingImages,
 			InternalFunction: gui.handlePruneImages,
 		},
+		{
+			Name:             gui.Tr.PruneAllImages,
+			InternalFunction: gui.handlePruneAllImages,
+		},
 	}
 
 	bulkCommands := append(baseBulkCommands, gui.Config.UserConfig.BulkCommands.Images...)
@@ -0,0 +1,177 @@
+package gui
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/jesseduffield/gocui"
+	"github.com/jesseduffield/lazydocker/pkg/commands"
+	"github.com/jesseduffield/lazydocker/pkg/utils"
+)
+
+// renderInspect shows an object's inspect output in the main panel, pretty
+// printed and highlighted. We hold on to it so that it can be copied.
+func (gui *Gui) renderInspect(inspect func() ([]byte, error)) error {
+	key := gui.State.Panels.Main.ObjectKey
+	mainView := gui.getMainView()
+	mainView.Autoscroll = false
+	mainView.Wrap = gui.Config.UserConfig.Gui.WrapMainPanel
+
+	return gui.T.NewTask(func(stop chan struct{}) {
+		raw, err := inspect()
+		if err != nil {
+			gui.renderString(gui.g, "main", err.Error())
+			return
+		}
+		formatted, err := commands.FormatInspectJSON(raw)
+		if err != nil {
+			gui.renderString(gui.g, "main", err.Error())
+			return
+		}
+
+		gui.State.Panels.Main.InspectKey = key
+		gui.State.Panels.Main.InspectJSON = formatted
+		gui.renderString(gui.g, "main", commands.HighlightJSON(formatted))
+	})
+}
+
+// showInspect switches to the panel's inspect tab and focuses the main panel,
+// so that the inspect output can be scrolled through straight away
+func (gui *Gui) showInspect(v *gocui.View, contextIndex *int, contexts []string, handleSelect func(*gocui.Gui, *gocui.View) error) error {
+	return gui.showContext(v, "inspect", contextIndex, contexts, handleSelect)
+}
+
+// showContext switches to the panel's tab for the given context and focuses
+// the main panel
+func (gui *Gui) showContext(v *gocui.View, context string, contextIndex *int, contexts []string, handleSelect func(*gocui.Gui, *gocui.View) error) error {
+	for i, c := range contexts {
+		if c == context {
+			*contextIndex = i
+		}
+	}
+	if err := handleSelect(gui.g, v); err != nil {
+		return err
+	}
+	return gui.handleEnterMain(gui.g, v)
+}
+
+func (gui *Gui) handleContainerInspect(g *gocui.Gui, v *gocui.View) error {
+	if _, err := gui.getSelectedContainer(); err != nil {
+		return nil
+	}
+	return gui.showInspect(v, &gui.State.Panels.Containers.ContextIndex, gui.getContainerContexts(), gui.handleContainerSelect)
+}
+
+func (gui *Gui) handleImageInspect(g *gocui.Gui, v *gocui.View) error {
+	if _, err := gui.getSelectedImage(); err != nil {
+		return nil
+	}
+	return gui.showInspect(v, &gui.State.Panels.Images.ContextIndex, gui.getImageContexts(), gui.handleImageSelect)
+}
+
+func (gui *Gui) handleVolumeInspect(g *gocui.Gui, v *gocui.View) error {
+	if _, err := gui.getSelectedVolume(); err != nil {
+		return nil
+	}
+	return gui.showInspect(v, &gui.State.Panels.Volumes.ContextIndex, gui.getVolumeContexts(), gui.handleVolumeSelect)
+}
+
+func (gui *Gui) handleNetworkInspect(g *gocui.Gui, v *gocui.View) error {
+	if _, err := gui.getSelectedNetwork(); err != nil {
+		return nil
+	}
+	return gui.showInspect(v, &gui.State.Panels.Networks.ContextIndex, gui.getNetworkContexts(), gui.handleNetworkSelect)
+}
+
+// handleInspectCopy copies the inspect output that's in the main panel, as
+// plain JSON
+func (gui *Gui) handleInspectCopy(g *gocui.Gui, v *gocui.View) error {
+	mainState := gui.State.Panels.Main
+	if mainState.InspectJSON == "" || mainState.InspectKey != mainState.ObjectKey {
+		return nil
+	}
+	return gui.copyToClipboard(mainState.InspectJSON, gui.Tr.CopiedToClipboard)
+}
+
+// handleMainSearch asks what to search the main panel for, then scrolls to
+// the first match. Searching for nothing stops highlighting matches.
+func (gui *Gui) handleMainSearch(g *gocui.Gui, v *gocui.View) error {
+	return gui.createPromptPanel(g, v, gui.Tr.SearchTitle, func(g *gocui.Gui, promptView *gocui.View) error {
+		gui.State.Panels.Main.SearchTerm = gui.trimmedContent(promptView)
+		return gui.search(v)
+	})
+}
+
+// search highlights the matches of the search if the main panel is showing
+// logs, and scrolls to the first one
+func (gui *Gui) search(v *gocui.View) error {
+	matcher, err := gui.searchMatcher()
+	if err != nil {
+		return gui.createErrorPanel(gui.g, err.Error())
+	}
+	if err := gui.highlightSearch(matcher); err != nil {
+		return err
+	}
+	if matcher == nil {
+		return nil
+	}
+
+	// the view's lines are only worked out again once it's been drawn
+	gui.g.Update(func(g *gocui.Gui) error {
+		return gui.scrollToSearchMatch(v, matcher, 0, 1)
+	})
+	return nil
+}
+
+// handleMainNextMatch scrolls to the next match of the last search, wrapping
+// back round to the top
+func (gui *Gui) handleMainNextMatch(g *gocui.Gui, v *gocui.View) error {
+	return gui.jumpToSearchMatch(v, 1)
+}
+
+// handleMainPrevMatch scrolls to the previous match of the last search,
+// wrapping back round to the bottom
+func (gui *Gui) handleMainPrevMatch(g *gocui.Gui, v *gocui.View) error {
+	return gui.jumpToSearchMatch(v, -1)
+}
+
+func (gui *Gui) jumpToSearchMatch(v *gocui.View, step int) error {
+	matcher, err := gui.searchMatcher()
+	if err != nil || matcher == nil {
+		return nil
+	}
+
+	_, current := v.Origin()
+	if gui.mainShowingMergedLogs() {
+		current = v.SelectedLineIdx()
+	}
+	return gui.scrollToSearchMatch(v, matcher, current+step, step)
+}
+
+func (gui *Gui) scrollToSearchMatch(v *gocui.View, matcher *regexp.Regexp, from int, step int) error {
+	lines := v.ViewBufferLines()
+	line := findMatchingLine(lines, matcher, from, step)
+	if line == -1 {
+		return gui.createErrorPanel(gui.g, fmt.Sprintf(gui.Tr.NoSearchMatches, gui.State.Panels.Main.SearchTerm))
+	}
+
+	v.Autoscroll = false
+	if gui.mainShowingMergedLogs() {
+		return gui.focusPoint(0, line, len(lines), v)
+	}
+	ox, _ := v.Origin()
+	return v.SetOrigin(ox, line)
+}
+
+// findMatchingLine returns the first line that matcher matches, ignoring
+// colours, looking from the given line in steps of step and wrapping round.
+// It's -1 if none do.
+func findMatchingLine(lines []string, matcher *regexp.Regexp, from int, step int) int {
+	for i := 0; i < len(lines); i++ {
+		index := ((from+i*step)%len(lines) + len(lines)) % len(lines)
+		if matcher.MatchString(utils.Decolorise(lines[index])) {
+			return index
+		}
+	}
+	return -1
+}
@@ -0,0 +1,308 @@
+package gui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/jesseduffield/gocui"
+	"github.com/jesseduffield/lazydocker/pkg/config"
+	"github.com/jesseduffield/lazydocker/pkg/i18n"
+)
+
+// universalViewName is what the keybinding config calls the bindings that
+// apply whichever view has focus, i.e. those with a blank view name
+const universalViewName = "universal"
+
+// namedKeys are the keys that can't be written as a single character in the
+// keybinding config. Where gocui has two names for the same key (e.g. ctrl+m is
+// enter) the first one listed here is the one we print.
+var namedKeys = []struct {
+	name string
+	key  gocui.Key
+}{
+	{"<enter>", gocui.KeyEnter},
+	{"<esc>", gocui.KeyEsc},
+	{"<space>", gocui.KeySpace},
+	{"<tab>", gocui.KeyTab},
+	{"<backspace>", gocui.KeyBackspace2},
+	{"<backspace>", gocui.KeyBackspace},
+	{"<delete>", gocui.KeyDelete},
+	{"<insert>", gocui.KeyInsert},
+	{"<home>", gocui.KeyHome},
+	{"<end>", gocui.KeyEnd},
+	{"<pgup>", gocui.KeyPgup},
+	{"<pgdown>", gocui.KeyPgdn},
+	{"<up>", gocui.KeyArrowUp},
+	{"<down>", gocui.KeyArrowDown},
+	{"<left>", gocui.KeyArrowLeft},
+	{"<right>", gocui.KeyArrowRight},
+	{"<f1>", gocui.KeyF1},
+	{"<f2>", gocui.KeyF2},
+	{"<f3>", gocui.KeyF3},
+	{"<f4>", gocui.KeyF4},
+	{"<f5>", gocui.KeyF5},
+	{"<f6>", gocui.KeyF6},
+	{"<f7>", gocui.KeyF7},
+	{"<f8>", gocui.KeyF8},
+	{"<f9>", gocui.KeyF9},
+	{"<f10>", gocui.KeyF10},
+	{"<f11>", gocui.KeyF11},
+	{"<f12>", gocui.KeyF12},
+	{"<c-a>", gocui.KeyCtrlA},
+	{"<c-b>", gocui.KeyCtrlB},
+	{"<c-c>", gocui.KeyCtrlC},
+	{"<c-d>", gocui.KeyCtrlD},
+	{"<c-e>", gocui.KeyCtrlE},
+	{"<c-f>", gocui.KeyCtrlF},
+	{"<c-g>", gocui.KeyCtrlG},
+	{"<c-h>", gocui.KeyCtrlH},
+	{"<c-i>", gocui.KeyCtrlI},
+	{"<c-j>", gocui.KeyCtrlJ},
+	{"<c-k>", gocui.KeyCtrlK},
+	{"<c-l>", gocui.KeyCtrlL},
+	{"<c-m>", gocui.KeyCtrlM},
+	{"<c-n>", gocui.KeyCtrlN},
+	{"<c-o>", gocui.KeyCtrlO},
+	{"<c-p>", gocui.KeyCtrlP},
+	{"<c-q>", gocui.KeyCtrlQ},
+	{"<c-r>", gocui.KeyCtrlR},
+	{"<c-s>", gocui.KeyCtrlS},
+	{"<c-t>", gocui.KeyCtrlT},
+	{"<c-u>", gocui.KeyCtrlU},
+	{"<c-v>", gocui.KeyCtrlV},
+	{"<c-w>", gocui.KeyCtrlW},
+	{"<c-x>", gocui.KeyCtrlX},
+	{"<c-y>", gocui.KeyCtrlY},
+	{"<c-z>", gocui.KeyCtrlZ},
+}
+
+// keyNames maps each named key to the name we print for it
+var keyNames = map[gocui.Key]string{}
+
+func init() {
+	for _, namedKey := range namedKeys {
+		if _, ok := keyNames[namedKey.key]; !ok {
+			keyNames[namedKey.key] = namedKey.name
+		}
+	}
+}
+
+// parseKey turns a key from the keybinding config into what gocui expects:
+// a rune for a single character, or a gocui.Key for a name like <enter> or
+// <c-u>
+func parseKey(value string) (interface{}, error) {
+	if utf8.RuneCountInString(value) == 1 {
+		r, _ := utf8.DecodeRuneInString(value)
+		if r == ' ' {
+			return gocui.KeySpace, nil
+		}
+		return r, nil
+	}
+
+	name := strings.ToLower(value)
+	for _, namedKey := range namedKeys {
+		if namedKey.name == name {
+			return namedKey.key, nil
+		}
+	}
+
+	return nil, fmt.Errorf("invalid key %q: expected a single character, <c-a> to <c-z>, or one of %s", value, strings.Join(namedKeyList(), ", "))
+}
+
+// namedKeyList lists the named keys for error messages, leaving out the ctrl
+// keys, which there are too many of to list
+func namedKeyList() []string {
+	names := []string{}
+	for _, namedKey := range namedKeys {
+		if strings.HasPrefix(namedKey.name, "<c-") {
+			continue
+		}
+		if len(names) == 0 || names[len(names)-1] != namedKey.name {
+			names = append(names, namedKey.name)
+		}
+	}
+	return names
+}
+
+// formatKey is the inverse of parseKey, giving keys the way the keybinding
+// config writes them. Mouse events, which can't be configured, come out as
+// their number.
+func formatKey(key interface{}) string {
+	switch key := key.(type) {
+	case rune:
+		if key == ' ' {
+			return keyNames[gocui.KeySpace]
+		}
+		return string(key)
+	case gocui.Key:
+		if name, ok := keyNames[key]; ok {
+			return name
+		}
+		return fmt.Sprintf("<%d>", key)
+	}
+	return fmt.Sprintf("%v", key)
+}
+
+// sortedOverrideKeys returns the keys of a section of the keybinding config in
+// order, so that problems with it are always reported the same way
+func sortedOverrideKeys(section interface{}) []string {
+	keys := []string{}
+	switch section := section.(type) {
+	case map[string]map[string]string:
+		for key := range section {
+			keys = append(keys, key)
+		}
+	case map[string]string:
+		for key := range section {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func configViewName(viewName string) string {
+	if viewName == "" {
+		return universalViewName
+	}
+	return viewName
+}
+
+// KeybindingConfigError is what we return if the keybinding section of the
+// config has problems, with one entry per problem so that they can all be
+// fixed in one go
+type KeybindingConfigError struct {
+	Problems []string
+}
+
+func (e *KeybindingConfigError) Error() string {
+	return "invalid keybinding config:\n  " + strings.Join(e.Problems, "\n  ")
+}
+
+// GetKeybindings returns the initial keybindings with the user's keybinding
+// config applied. It fails if the config names a view or action we don't
+// have, has a key we can't parse, or leaves two bindings on the same key.
+func (gui *Gui) GetKeybindings() ([]*Binding, error) {
+	bindings := gui.GetInitialKeybindings()
+	overrides := gui.Config.UserConfig.Keybinding
+
+	named := map[string]map[string]*Binding{}
+	for _, binding := range bindings {
+		if binding.Name == "" {
+			continue
+		}
+		viewName := configViewName(binding.ViewName)
+		if named[viewName] == nil {
+			named[viewName] = map[string]*Binding{}
+		}
+		named[viewName][binding.Name] = binding
+	}
+
+	viewNames := []string{}
+	for viewName := range named {
+		viewNames = append(viewNames, viewName)
+	}
+	sort.Strings(viewNames)
+
+	problems := []string{}
+	overridden := map[*Binding]bool{}
+	for _, viewName := range sortedOverrideKeys(overrides) {
+		actions, ok := named[viewName]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("unknown view %q: expected one of %s", viewName, strings.Join(viewNames, ", ")))
+			continue
+		}
+		for _, action := range sortedOverrideKeys(overrides[viewName]) {
+			binding, ok := actions[action]
+			if !ok {
+				actionNames := []string{}
+				for name := range actions {
+					actionNames = append(actionNames, name)
+				}
+				sort.Strings(actionNames)
+				problems = append(problems, fmt.Sprintf("unknown action %q for view %s: expected one of %s", action, viewName, strings.Join(actionNames, ", ")))
+				continue
+			}
+			key, err := parseKey(overrides[viewName][action])
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("%s.%s: %s", viewName, action, err))
+				continue
+			}
+			binding.Key = key
+			overridden[binding] = true
+		}
+	}
+
+	problems = append(problems, keybindingConflicts(bindings, overridden)...)
+	if len(problems) > 0 {
+		return nil, &KeybindingConfigError{Problems: problems}
+	}
+
+	gui.disableMutatingBindings(bindings)
+
+	return bindings, nil
+}
+
+// keybindingConflicts reports the remapped bindings that share a key with
+// another binding for the same view, or with a universal binding. Clashes
+// between the defaults themselves are left alone: some are deliberate, such
+// as the menu's q closing it rather than quitting.
+func keybindingConflicts(bindings []*Binding, overridden map[*Binding]bool) []string {
+	describe := func(binding *Binding) string {
+		if binding.Name == "" {
+			return "a built-in " + configViewName(binding.ViewName) + " binding"
+		}
+		return configViewName(binding.ViewName) + "." + binding.Name
+	}
+
+	problems := []string{}
+	seen := map[[2]*Binding]bool{}
+	for _, binding := range bindings {
+		if !overridden[binding] {
+			continue
+		}
+		for _, other := range bindings {
+			if other == binding || (other.ViewName != binding.ViewName && other.ViewName != "" && binding.ViewName != "") {
+				continue
+			}
+			if formatKey(other.Key) != formatKey(binding.Key) || other.Modifier != binding.Modifier {
+				continue
+			}
+			// two remapped bindings on the same key would otherwise be
+			// reported twice
+			if seen[[2]*Binding{other, binding}] {
+				continue
+			}
+			seen[[2]*Binding{binding, other}] = true
+			problems = append(problems, fmt.Sprintf("%s conflicts with %s: both are bound to %s", describe(binding), describe(other), formatKey(binding.Key)))
+		}
+	}
+	return problems
+}
+
+// GetKeymap returns the effective keymap, i.e. the key for each action that
+// can be remapped once the user's keybinding config is applied, in the same
+// shape as the keybinding config
+func GetKeymap(config *config.AppConfig) (map[string]map[string]string, error) {
+	// the descriptions don't come into it, so there's no need for a language
+	gui := &Gui{Config: config, Tr: &i18n.TranslationSet{}}
+	bindings, err := gui.GetKeybindings()
+	if err != nil {
+		return nil, err
+	}
+
+	keymap := map[string]map[string]string{}
+	for _, binding := range bindings {
+		if binding.Name == "" {
+			continue
+		}
+		viewName := configViewName(binding.ViewName)
+		if keymap[viewName] == nil {
+			keymap[viewName] = map[string]string{}
+		}
+		keymap[viewName][binding.Name] = formatKey(binding.Key)
+	}
+	return keymap, nil
+}
@@ -0,0 +1,239 @@
+package gui
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jesseduffield/gocui"
+	"github.com/jesseduffield/lazydocker/pkg/config"
+	"github.com/jesseduffield/lazydocker/pkg/i18n"
+	"github.com/stretchr/testify/assert"
+)
+
+func newKeybindingTestGui(keybinding map[string]map[string]string) *Gui {
+	userConfig := config.GetDefaultConfig()
+	userConfig.Keybinding = keybinding
+	return &Gui{Config: &config.AppConfig{UserConfig: &userConfig}, Tr: &i18n.TranslationSet{}}
+}
+
+func findBinding(bindings []*Binding, viewName string, name string) *Binding {
+	for _, binding := range bindings {
+		if binding.ViewName == viewName && binding.Name == name {
+			return binding
+		}
+	}
+	return nil
+}
+
+func TestParseKey(t *testing.T) {
+	type scenario struct {
+		value               string
+		expected            interface{}
+		expectedErrorSubstr string
+	}
+
+	scenarios := []scenario{
+		{value: "s", expected: 's'},
+		{value: "é", expected: 'é'},
+		{value: " ", expected: gocui.KeySpace},
+		{value: "<space>", expected: gocui.KeySpace},
+		{value: "<enter>", expected: gocui.KeyEnter},
+		{value: "<PgDown>", expected: gocui.KeyPgdn},
+		{value: "<c-u>", expected: gocui.KeyCtrlU},
+		{value: "<backspace>", expected: gocui.KeyBackspace2},
+		{value: "", expectedErrorSubstr: "invalid key"},
+		{value: "ss", expectedErrorSubstr: `invalid key "ss"`},
+		{value: "<ctrl-u>", expectedErrorSubstr: "<c-a> to <c-z>"},
+	}
+
+	for _, s := range scenarios {
+		s := s
+		t.Run(s.value, func(t *testing.T) {
+			key, err := parseKey(s.value)
+			if s.expectedErrorSubstr != "" {
+				if assert.Error(t, err) {
+					assert.Contains(t, err.Error(), s.expectedErrorSubstr)
+				}
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, s.expected, key)
+			// and printing it gives something that parses back to the same key
+			reparsed, err := parseKey(formatKey(key))
+			assert.NoError(t, err)
+			assert.Equal(t, s.expected, reparsed)
+		})
+	}
+}
+
+func TestGetKeybindingsNamesAreUnique(t *testing.T) {
+	bindings, err := newKeybindingTestGui(nil).GetKeybindings()
+	assert.NoError(t, err)
+
+	seen := map[string]bool{}
+	for _, binding := range bindings {
+		if binding.Name == "" {
+			continue
+		}
+		id := configViewName(binding.ViewName) + "." + binding.Name
+		assert.False(t, seen[id], "%s is used by more than one binding", id)
+		seen[id] = true
+	}
+}
+
+func TestGetKeybindingsKeysAreUnique(t *testing.T) {
+	bindings, err := newKeybindingTestGui(nil).GetKeybindings()
+	assert.NoError(t, err)
+
+	type boundKey struct {
+		viewName string
+		key      interface{}
+		modifier gocui.Modifier
+	}
+	// the project panel's click is bound twice, harmlessly, as the first one
+	// selects the panel and then does what the second does
+	allowed := map[boundKey]bool{{viewName: "project", key: gocui.MouseLeft, modifier: gocui.ModNone}: true}
+
+	// a view's binding deliberately wins over a global one for the same key,
+	// but two for the same view means one of them can never run
+	seen := map[boundKey]*Binding{}
+	for _, binding := range bindings {
+		id := boundKey{viewName: binding.ViewName, key: binding.Key, modifier: binding.Modifier}
+		if other, ok := seen[id]; ok && !allowed[id] {
+			t.Errorf("%s and %s are both bound to %s in the %q view", other.Name, binding.Name, formatKey(binding.Key), binding.ViewName)
+		}
+		seen[id] = binding
+	}
+}
+
+func TestGetKeybindings(t *testing.T) {
+	type scenario struct {
+		testName         string
+		keybinding       map[string]map[string]string
+		test             func([]*Binding)
+		expectedProblems []string
+	}
+
+	scenarios := []scenario{
+		{
+			testName:   "Remapping an action",
+			keybinding: map[string]map[string]string{"containers": {"stop": "S", "cycleLogsSince": "<c-s>"}},
+			test: func(bindings []*Binding) {
+				assert.Equal(t, 'S', findBinding(bindings, "containers", "stop").Key)
+				assert.Equal(t, gocui.KeyCtrlS, findBinding(bindings, "containers", "cycleLogsSince").Key)
+				// services have their own stop binding, which is left alone
+				assert.Equal(t, 's', findBinding(bindings, "services", "stop").Key)
+			},
+		},
+		{
+			testName:   "Universal bindings",
+			keybinding: map[string]map[string]string{"universal": {"quit": "Q"}},
+			test: func(bindings []*Binding) {
+				assert.Equal(t, 'Q', findBinding(bindings, "", "quit").Key)
+			},
+		},
+		{
+			testName:   "Swapping two keys",
+			keybinding: map[string]map[string]string{"containers": {"stop": "r", "restart": "s"}},
+			test: func(bindings []*Binding) {
+				assert.Equal(t, 'r', findBinding(bindings, "containers", "stop").Key)
+				assert.Equal(t, 's', findBinding(bindings, "containers", "restart").Key)
+			},
+		},
+		{
+			testName:   "Unknown view",
+			keybinding: map[string]map[string]string{"imgs": {"pull": "P"}},
+			expectedProblems: []string{
+				`unknown view "imgs": expected one of containers, images, main, menu, networks, project, services, universal, volumes`,
+			},
+		},
+		{
+			testName:         "Unknown action",
+			keybinding:       map[string]map[string]string{"main": {"nuke": "n"}},
+			expectedProblems: []string{`unknown action "nuke" for view main: expected one of copy, goToContainer, nextItem, nextMatch, prevItem, prevMatch, return, revealSecrets, scrollLeft, scrollRight, search, toggleSearchCase, toggleSearchRegex`},
+		},
+		{
+			testName:   "Conflicts",
+			keybinding: map[string]map[string]string{"containers": {"stop": "r", "remove": "x"}, "images": {"pull": "I", "inspect": "I"}},
+			expectedProblems: []string{
+				"containers.remove conflicts with universal.optionsMenu: both are bound to x",
+				"containers.stop conflicts with containers.restart: both are bound to r",
+				"images.pull conflicts with images.inspect: both are bound to I",
+			},
+		},
+		{
+			testName:         "Conflict with a binding that can't be remapped",
+			keybinding:       map[string]map[string]string{"volumes": {"browse": "<down>"}},
+			expectedProblems: []string{"volumes.browse conflicts with a built-in volumes binding: both are bound to <down>"},
+		},
+		{
+			testName:   "All the problems are reported together",
+			keybinding: map[string]map[string]string{"containers": {"stop": "<bogus>", "nuke": "n"}, "imgs": {}},
+			expectedProblems: []string{
+				`unknown action "nuke" for view containers`,
+				`containers.stop: invalid key "<bogus>"`,
+				`unknown view "imgs"`,
+			},
+		},
+	}
+
+	for _, s := range scenarios {
+		s := s
+		t.Run(s.testName, func(t *testing.T) {
+			bindings, err := newKeybindingTestGui(s.keybinding).GetKeybindings()
+			if len(s.expectedProblems) > 0 {
+				if assert.IsType(t, &KeybindingConfigError{}, err) {
+					problems := err.(*KeybindingConfigError).Problems
+					if assert.Len(t, problems, len(s.expectedProblems)) {
+						for i, expected := range s.expectedProblems {
+							assert.Contains(t, problems[i], expected)
+						}
+					}
+				}
+				return
+			}
+			assert.NoError(t, err)
+			s.test(bindings)
+		})
+	}
+}
+
+func TestGetKeymap(t *testing.T) {
+	userConfig := config.GetDefaultConfig()
+	userConfig.Keybinding = map[string]map[string]string{"containers": {"stop": "<c-s>"}}
+
+	keymap, err := GetKeymap(&config.AppConfig{UserConfig: &userConfig})
+	assert.NoError(t, err)
+	assert.Equal(t, "<c-s>", keymap["containers"]["stop"])
+	assert.Equal(t, "<space>", keymap["containers"]["toggleMarked"])
+	assert.Equal(t, "q", keymap["universal"]["quit"])
+	assert.Equal(t, "<pgup>", keymap["universal"]["pageUpMain"])
+}
+
+func TestGetKeybindingsReadOnly(t *testing.T) {
+	gui := newKeybindingTestGui(nil)
+	bindings, err := gui.GetKeybindings()
+	assert.NoError(t, err)
+
+	gui.Config.UserConfig.ReadOnly = true
+	readOnlyBindings, err := gui.GetKeybindings()
+	assert.NoError(t, err)
+
+	for viewName, names := range mutatingBindings {
+		for _, name := range names {
+			// a typo here would leave the binding working in read-only mode
+			assert.NotNil(t, findBinding(bindings, viewName, name), "there's no %s binding in view %q", name, viewName)
+		}
+	}
+
+	readOnly := func(viewName string, name string) bool {
+		handler := findBinding(readOnlyBindings, viewName, name).Handler
+		return reflect.ValueOf(handler).Pointer() == reflect.ValueOf(gui.handleReadOnly).Pointer()
+	}
+	assert.True(t, readOnly("containers", "stop"))
+	assert.True(t, readOnly("images", "pull"))
+	assert.True(t, readOnly("", "runCommand"))
+	for _, name := range []string{"viewLogs", "inspect", "copyID", "filter"} {
+		assert.False(t, readOnly("containers", name), name)
+	}
+}
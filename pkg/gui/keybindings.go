@@ -2,6 +2,7 @@ package gui
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/jesseduffield/gocui"
 )
@@ -15,6 +16,10 @@ type Binding struct {
 	Key         interface{} // FIXME: find out how to get `gocui.Key | rune`
 	Modifier    gocui.Modifier
 	Description string
+
+	// Name identifies the binding in the keybinding section of the config, so
+	// that users can remap it. Bindings without a name can't be remapped.
+	Name string
 }
 
 // GetDisplayStrings returns the display string of a file
@@ -55,6 +60,12 @@ func (b *Binding) GetKey() string {
 		return "PgDn"
 	}
 
+	if k, ok := b.Key.(gocui.Key); ok {
+		if name, ok := keyNames[k]; ok {
+			return strings.Trim(name, "<>")
+		}
+	}
+
 	return fmt.Sprintf("%c", key)
 }
 
@@ -66,6 +77,7 @@ func (gui *Gui) GetInitialKeybindings() []*Binding {
 			Key:      'q',
 			Modifier: gocui.ModNone,
 			Handler:  gui.quit,
+			Name:     "quit",
 		},
 		{
 			ViewName: "",
@@ -84,12 +96,14 @@ func (gui *Gui) GetInitialKeybindings() []*Binding {
 			Key:      gocui.KeyPgup,
 			Modifier: gocui.ModNone,
 			Handler:  gui.scrollUpMain,
+			Name:     "pageUpMain",
 		},
 		{
 			ViewName: "",
 			Key:      gocui.KeyPgdn,
 			Modifier: gocui.ModNone,
 			Handler:  gui.scrollDownMain,
+			Name:     "pageDownMain",
 		},
 		{
 			ViewName: "",
@@ -108,12 +122,14 @@ func (gui *Gui) GetInitialKeybindings() []*Binding {
 			Key:      gocui.KeyEnd,
 			Modifier: gocui.ModNone,
 			Handler:  gui.autoScrollMain,
+			Name:     "autoScrollMain",
 		},
 		{
 			ViewName: "",
 			Key:      'x',
 			Modifier: gocui.ModNone,
 			Handler:  gui.handleCreateOptionsMenu,
+			Name:     "optionsMenu",
 		},
 		{
 			ViewName: "",
@@ -126,12 +142,44 @@ func (gui *Gui) GetInitialKeybindings() []*Binding {
 			Key:      'X',
 			Modifier: gocui.ModNone,
 			Handler:  gui.handleCustomCommand,
+			Name:     "runCommand",
+		},
+		{
+			ViewName:    "",
+			Key:         'C',
+			Modifier:    gocui.ModNone,
+			Handler:     gui.handleDockerContextsMenu,
+			Name:        "switchContext",
+			Description: gui.Tr.SwitchDockerContext,
+		},
+		{
+			ViewName:    "",
+			Key:         ':',
+			Modifier:    gocui.ModNone,
+			Handler:     gui.handleCommandPalette,
+			Name:        "commandPalette",
+			Description: gui.Tr.CommandPalette,
+		},
+		{
+			ViewName: "",
+			Key:      gocui.KeyCtrlP,
+			Modifier: gocui.ModNone,
+			Handler:  gui.handleCommandPalette,
+		},
+		{
+			ViewName:    "",
+			Key:         gocui.KeyCtrlR,
+			Modifier:    gocui.ModNone,
+			Handler:     gui.handleReconnect,
+			Name:        "reconnect",
+			Description: gui.Tr.Reconnect,
 		},
 		{
 			ViewName:    "project",
 			Key:         'e',
 			Modifier:    gocui.ModNone,
 			Handler:     gui.handleEditConfig,
+			Name:        "editConfig",
 			Description: gui.Tr.EditConfig,
 		},
 		{
@@ -139,6 +187,7 @@ func (gui *Gui) GetInitialKeybindings() []*Binding {
 			Key:         'o',
 			Modifier:    gocui.ModNone,
 			Handler:     gui.handleOpenConfig,
+			Name:        "openConfig",
 			Description: gui.Tr.OpenConfig,
 		},
 		{
@@ -146,6 +195,7 @@ func (gui *Gui) GetInitialKeybindings() []*Binding {
 			Key:         '[',
 			Modifier:    gocui.ModNone,
 			Handler:     gui.handleProjectPrevContext,
+			Name:        "prevTab",
 			Description: gui.Tr.PreviousContext,
 		},
 		{
@@ -153,6 +203,7 @@ func (gui *Gui) GetInitialKeybindings() []*Binding {
 			Key:         ']',
 			Modifier:    gocui.ModNone,
 			Handler:     gui.handleProjectNextContext,
+			Name:        "nextTab",
 			Description: gui.Tr.NextContext,
 		},
 		{
@@ -166,8 +217,33 @@ func (gui *Gui) GetInitialKeybindings() []*Binding {
 			Key:         'm',
 			Modifier:    gocui.ModNone,
 			Handler:     gui.handleViewAllLogs,
+			Name:        "viewLogs",
 			Description: gui.Tr.ViewLogs,
 		},
+		{
+			ViewName:    "project",
+			Key:         'f',
+			Modifier:    gocui.ModNone,
+			Handler:     gui.handleEventsFilter,
+			Name:        "filterEvents",
+			Description: gui.Tr.FilterEvents,
+		},
+		{
+			ViewName:    "project",
+			Key:         'd',
+			Modifier:    gocui.ModNone,
+			Handler:     gui.handleDiskUsageMenu,
+			Name:        "diskUsageMenu",
+			Description: gui.Tr.DiskUsageMenu,
+		},
+		{
+			ViewName:    "project",
+			Key:         'r',
+			Modifier:    gocui.ModNone,
+			Handler:     gui.handleDiskUsageRefresh,
+			Name:        "refreshDiskUsage",
+			Description: gui.Tr.RefreshDiskUsage,
+		},
 		{
 			ViewName: "project",
 			Key:      gocui.MouseLeft,
@@ -197,6 +273,7 @@ func (gui *Gui) GetInitialKeybindings() []*Binding {
 			Key:         '[',
 			Modifier:    gocui.ModNone,
 			Handler:     gui.handleContainersPrevContext,
+			Name:        "prevTab",
 			Description: gui.Tr.PreviousContext,
 		},
 		{
@@ -204,6 +281,7 @@ func (gui *Gui) GetInitialKeybindings() []*Binding {
 			Key:         ']',
 			Modifier:    gocui.ModNone,
 			Handler:     gui.handleContainersNextContext,
+			Name:        "nextTab",
 			Description: gui.Tr.NextContext,
 		},
 		{
@@ -211,13 +289,23 @@ func (gui *Gui) GetInitialKeybindings() []*Binding {
 			Key:         'd',
 			Modifier:    gocui.ModNone,
 			Handler:     gui.handleContainersRemoveMenu,
+			Name:        "remove",
 			Description: gui.Tr.Remove,
 		},
+		{
+			ViewName:    "containers",
+			Key:         'D',
+			Modifier:    gocui.ModNone,
+			Handler:     gui.handleContainerQuickRemove,
+			Name:        "quickRemove",
+			Description: gui.Tr.QuickRemove,
+		},
 		{
 			ViewName:    "containers",
 			Key:         'e',
 			Modifier:    gocui.ModNone,
 			Handler:     gui.handleHideStoppedContainers,
+			Name:        "hideStopped",
 			Description: gui.Tr.HideStopped,
 		},
 		{
@@ -225,20 +313,63 @@ func (gui *Gui) GetInitialKeybindings() []*Binding {
 			Key:         's',
 			Modifier:    gocui.ModNone,
 			Handler:     gui.handleContainerStop,
+			Name:        "stop",
 			Description: gui.Tr.Stop,
 		},
+		{
+			ViewName:    "containers",
+			Key:         'T',
+			Modifier:    gocui.ModNone,
+			Handler:     gui.handleContainerStopWithTimeout,
+			Name:        "stopWithTimeout",
+			Description: gui.Tr.StopWithTimeout,
+		},
+		{
+			ViewName:    "containers",
+			Key:         gocui.KeyCtrlK,
+			Modifier:    gocui.ModNone,
+			Handler:     gui.handleContainerKill,
+			Name:        "kill",
+			Description: gui.Tr.Kill,
+		},
 		{
 			ViewName:    "containers",
 			Key:         'r',
 			Modifier:    gocui.ModNone,
 			Handler:     gui.handleContainerRestart,
+			Name:        "restart",
 			Description: gui.Tr.Restart,
 		},
+		{
+			ViewName:    "containers",
+			Key:         'R',
+			Modifier:    gocui.ModNone,
+			Handler:     gui.handleContainerRestartMenu,
+			Name:        "restartOptions",
+			Description: gui.Tr.ViewRestartOptions,
+		},
+		{
+			ViewName:    "containers",
+			Key:         'N',
+			Modifier:    gocui.ModNone,
+			Handler:     gui.handleContainerRename,
+			Name:        "rename",
+			Description: gui.Tr.RenameContainer,
+		},
+		{
+			ViewName:    "containers",
+			Key:         't',
+			Modifier:    gocui.ModNone,
+			Handler:     gui.handleMergedLogsMenu,
+			Name:        "toggleMergedLogs",
+			Description: gui.Tr.ToggleMergedLogs,
+		},
 		{
 			ViewName:    "containers",
 			Key:         'a',
 			Modifier:    gocui.ModNone,
 			Handler:     gui.handleContainerAttach,
+			Name:        "attach",
 			Description: gui.Tr.Attach,
 		},
 		{
@@ -246,13 +377,39 @@ func (gui *Gui) GetInitialKeybindings() []*Binding {
 			Key:         'm',
 			Modifier:    gocui.ModNone,
 			Handler:     gui.handleContainerViewLogs,
+			Name:        "viewLogs",
 			Description: gui.Tr.ViewLogs,
 		},
+		{
+			ViewName:    "containers",
+			Key:         gocui.KeySpace,
+			Modifier:    gocui.ModNone,
+			Handler:     gui.handleContainerToggleMarked,
+			Name:        "toggleMarked",
+			Description: gui.Tr.ToggleMarked,
+		},
+		{
+			ViewName:    "containers",
+			Key:         'f',
+			Modifier:    gocui.ModNone,
+			Handler:     gui.handleContainersFilter,
+			Name:        "filter",
+			Description: gui.Tr.FilterContainers,
+		},
+		{
+			ViewName:    "containers",
+			Key:         'S',
+			Modifier:    gocui.ModNone,
+			Handler:     gui.handleCycleLogsSince,
+			Name:        "cycleLogsSince",
+			Description: gui.Tr.CycleLogsSince,
+		},
 		{
 			ViewName:    "containers",
 			Key:         'E',
 			Modifier:    gocui.ModNone,
 			Handler:     gui.handleContainersExecShell,
+			Name:        "execShell",
 			Description: gui.Tr.ExecShell,
 		},
 		{
@@ -260,6 +417,7 @@ func (gui *Gui) GetInitialKeybindings() []*Binding {
 			Key:         'c',
 			Modifier:    gocui.ModNone,
 			Handler:     gui.handleContainersCustomCommand,
+			Name:        "customCommand",
 			Description: gui.Tr.RunCustomCommand,
 		},
 		{
@@ -267,6 +425,7 @@ func (gui *Gui) GetInitialKeybindings() []*Binding {
 			Key:         'b',
 			Modifier:    gocui.ModNone,
 			Handler:     gui.handleContainersBulkCommand,
+			Name:        "bulkCommand",
 			Description: gui.Tr.ViewBulkCommands,
 		},
 		{
@@ -274,20 +433,95 @@ func (gui *Gui) GetInitialKeybindings() []*Binding {
 			Key:         'w',
 			Modifier:    gocui.ModNone,
 			Handler:     gui.handleContainersOpenInBrowserCommand,
-			Description: gui.Tr.OpenInBrowser,
+			Name:        "openInBrowser",
+			Description: gui.Tr.OpenPortInBrowser,
+		},
+		{
+			ViewName:    "containers",
+			Key:         'z',
+			Modifier:    gocui.ModNone,
+			Handler:     gui.handleComposeProjectToggleCollapsed,
+			Name:        "toggleComposeProject",
+			Description: gui.Tr.ToggleComposeProject,
+		},
+		{
+			ViewName:    "containers",
+			Key:         'P',
+			Modifier:    gocui.ModNone,
+			Handler:     gui.handleComposeProjectMenu,
+			Name:        "composeProjectMenu",
+			Description: gui.Tr.ComposeProjectMenu,
+		},
+		{
+			ViewName:    "containers",
+			Key:         'I',
+			Modifier:    gocui.ModNone,
+			Handler:     gui.handleContainerInspect,
+			Name:        "inspect",
+			Description: gui.Tr.Inspect,
+		},
+		{
+			ViewName:    "containers",
+			Key:         'y',
+			Modifier:    gocui.ModNone,
+			Handler:     gui.handleContainerCopyID(false),
+			Name:        "copyID",
+			Description: gui.Tr.CopyID,
+		},
+		{
+			ViewName:    "containers",
+			Key:         'Y',
+			Modifier:    gocui.ModNone,
+			Handler:     gui.handleContainerCopyID(true),
+			Name:        "copyShortID",
+			Description: gui.Tr.CopyShortID,
+		},
+		{
+			ViewName:    "containers",
+			Key:         'F',
+			Modifier:    gocui.ModNone,
+			Handler:     gui.handleContainerCopyFiles,
+			Name:        "copyFiles",
+			Description: gui.Tr.CopyFiles,
+		},
+		{
+			ViewName:    "containers",
+			Key:         'o',
+			Modifier:    gocui.ModNone,
+			Handler:     gui.handleContainersSortMenu,
+			Name:        "sort",
+			Description: gui.Tr.SortList,
+		},
+		{
+			ViewName:    "containers",
+			Key:         'O',
+			Modifier:    gocui.ModNone,
+			Handler:     gui.handleContainersReverseSort,
+			Name:        "reverseSort",
+			Description: gui.Tr.ReverseSort,
 		},
 		{
 			ViewName:    "services",
 			Key:         'd',
 			Modifier:    gocui.ModNone,
 			Handler:     gui.handleServiceRemoveMenu,
+			Name:        "remove",
 			Description: gui.Tr.RemoveService,
 		},
+		{
+			ViewName:    "services",
+			Key:         'D',
+			Modifier:    gocui.ModNone,
+			Handler:     gui.handleServiceQuickRemove,
+			Name:        "quickRemove",
+			Description: gui.Tr.QuickRemove,
+		},
 		{
 			ViewName:    "services",
 			Key:         's',
 			Modifier:    gocui.ModNone,
 			Handler:     gui.handleServiceStop,
+			Name:        "stop",
 			Description: gui.Tr.Stop,
 		},
 		{
@@ -295,6 +529,7 @@ func (gui *Gui) GetInitialKeybindings() []*Binding {
 			Key:         'r',
 			Modifier:    gocui.ModNone,
 			Handler:     gui.handleServiceRestart,
+			Name:        "restart",
 			Description: gui.Tr.Restart,
 		},
 		{
@@ -302,6 +537,7 @@ func (gui *Gui) GetInitialKeybindings() []*Binding {
 			Key:         'a',
 			Modifier:    gocui.ModNone,
 			Handler:     gui.handleServiceAttach,
+			Name:        "attach",
 			Description: gui.Tr.Attach,
 		},
 		{
@@ -309,13 +545,23 @@ func (gui *Gui) GetInitialKeybindings() []*Binding {
 			Key:         'm',
 			Modifier:    gocui.ModNone,
 			Handler:     gui.handleServiceViewLogs,
+			Name:        "viewLogs",
 			Description: gui.Tr.ViewLogs,
 		},
+		{
+			ViewName:    "services",
+			Key:         'S',
+			Modifier:    gocui.ModNone,
+			Handler:     gui.handleCycleLogsSince,
+			Name:        "cycleLogsSince",
+			Description: gui.Tr.CycleLogsSince,
+		},
 		{
 			ViewName:    "services",
 			Key:         '[',
 			Modifier:    gocui.ModNone,
 			Handler:     gui.handleServicesPrevContext,
+			Name:        "prevTab",
 			Description: gui.Tr.PreviousContext,
 		},
 		{
@@ -323,6 +569,7 @@ func (gui *Gui) GetInitialKeybindings() []*Binding {
 			Key:         ']',
 			Modifier:    gocui.ModNone,
 			Handler:     gui.handleServicesNextContext,
+			Name:        "nextTab",
 			Description: gui.Tr.NextContext,
 		},
 		{
@@ -330,6 +577,7 @@ func (gui *Gui) GetInitialKeybindings() []*Binding {
 			Key:         'R',
 			Modifier:    gocui.ModNone,
 			Handler:     gui.handleServiceRestartMenu,
+			Name:        "restartOptions",
 			Description: gui.Tr.ViewRestartOptions,
 		},
 		{
@@ -337,6 +585,7 @@ func (gui *Gui) GetInitialKeybindings() []*Binding {
 			Key:         'c',
 			Modifier:    gocui.ModNone,
 			Handler:     gui.handleServicesCustomCommand,
+			Name:        "customCommand",
 			Description: gui.Tr.RunCustomCommand,
 		},
 		{
@@ -344,6 +593,7 @@ func (gui *Gui) GetInitialKeybindings() []*Binding {
 			Key:         'b',
 			Modifier:    gocui.ModNone,
 			Handler:     gui.handleServicesBulkCommand,
+			Name:        "bulkCommand",
 			Description: gui.Tr.ViewBulkCommands,
 		},
 		{
@@ -351,13 +601,31 @@ func (gui *Gui) GetInitialKeybindings() []*Binding {
 			Key:         'w',
 			Modifier:    gocui.ModNone,
 			Handler:     gui.handleServicesOpenInBrowserCommand,
-			Description: gui.Tr.OpenInBrowser,
+			Name:        "openInBrowser",
+			Description: gui.Tr.OpenPortInBrowser,
+		},
+		{
+			ViewName:    "services",
+			Key:         'y',
+			Modifier:    gocui.ModNone,
+			Handler:     gui.handleServiceCopyID(false),
+			Name:        "copyID",
+			Description: gui.Tr.CopyID,
+		},
+		{
+			ViewName:    "services",
+			Key:         'Y',
+			Modifier:    gocui.ModNone,
+			Handler:     gui.handleServiceCopyID(true),
+			Name:        "copyShortID",
+			Description: gui.Tr.CopyShortID,
 		},
 		{
 			ViewName:    "images",
 			Key:         '[',
 			Modifier:    gocui.ModNone,
 			Handler:     gui.handleImagesPrevContext,
+			Name:        "prevTab",
 			Description: gui.Tr.PreviousContext,
 		},
 		{
@@ -365,6 +633,7 @@ func (gui *Gui) GetInitialKeybindings() []*Binding {
 			Key:         ']',
 			Modifier:    gocui.ModNone,
 			Handler:     gui.handleImagesNextContext,
+			Name:        "nextTab",
 			Description: gui.Tr.NextContext,
 		},
 		{
@@ -372,6 +641,7 @@ func (gui *Gui) GetInitialKeybindings() []*Binding {
 			Key:         'c',
 			Modifier:    gocui.ModNone,
 			Handler:     gui.handleImagesCustomCommand,
+			Name:        "customCommand",
 			Description: gui.Tr.RunCustomCommand,
 		},
 		{
@@ -379,20 +649,119 @@ func (gui *Gui) GetInitialKeybindings() []*Binding {
 			Key:         'd',
 			Modifier:    gocui.ModNone,
 			Handler:     gui.handleImagesRemoveMenu,
+			Name:        "remove",
 			Description: gui.Tr.RemoveImage,
 		},
+		{
+			ViewName:    "images",
+			Key:         'D',
+			Modifier:    gocui.ModNone,
+			Handler:     gui.handleImageQuickRemove,
+			Name:        "quickRemove",
+			Description: gui.Tr.QuickRemove,
+		},
+		{
+			ViewName:    "images",
+			Key:         'p',
+			Modifier:    gocui.ModNone,
+			Handler:     gui.handleImagesPull,
+			Name:        "pull",
+			Description: gui.Tr.PullImage,
+		},
+		{
+			ViewName:    "images",
+			Key:         'B',
+			Modifier:    gocui.ModNone,
+			Handler:     gui.handleImagesBuild,
+			Name:        "build",
+			Description: gui.Tr.BuildImage,
+		},
 		{
 			ViewName:    "images",
 			Key:         'b',
 			Modifier:    gocui.ModNone,
 			Handler:     gui.handleImagesBulkCommand,
+			Name:        "bulkCommand",
 			Description: gui.Tr.ViewBulkCommands,
 		},
+		{
+			ViewName:    "images",
+			Key:         'I',
+			Modifier:    gocui.ModNone,
+			Handler:     gui.handleImageInspect,
+			Name:        "inspect",
+			Description: gui.Tr.Inspect,
+		},
+		{
+			ViewName:    "images",
+			Key:         'P',
+			Modifier:    gocui.ModNone,
+			Handler:     gui.handleImagePush,
+			Name:        "push",
+			Description: gui.Tr.PushImage,
+		},
+		{
+			ViewName:    "images",
+			Key:         't',
+			Modifier:    gocui.ModNone,
+			Handler:     gui.handleImageTag,
+			Name:        "tag",
+			Description: gui.Tr.TagImage,
+		},
+		{
+			ViewName:    "images",
+			Key:         'u',
+			Modifier:    gocui.ModNone,
+			Handler:     gui.handleImageUntag,
+			Name:        "untag",
+			Description: gui.Tr.UntagImage,
+		},
+		{
+			ViewName:    "images",
+			Key:         'v',
+			Modifier:    gocui.ModNone,
+			Handler:     gui.handleImageHistory,
+			Name:        "history",
+			Description: gui.Tr.ViewHistory,
+		},
+		{
+			ViewName:    "images",
+			Key:         'y',
+			Modifier:    gocui.ModNone,
+			Handler:     gui.handleImageCopyID(false),
+			Name:        "copyID",
+			Description: gui.Tr.CopyID,
+		},
+		{
+			ViewName:    "images",
+			Key:         'Y',
+			Modifier:    gocui.ModNone,
+			Handler:     gui.handleImageCopyID(true),
+			Name:        "copyShortID",
+			Description: gui.Tr.CopyShortID,
+		},
+		{
+			ViewName:    "images",
+			Key:         'o',
+			Modifier:    gocui.ModNone,
+			Handler:     gui.handleImagesSortMenu,
+			Name:        "sort",
+			Description: gui.Tr.SortList,
+		},
+		{
+			ViewName:    "images",
+			Key:         'O',
+			Modifier:    gocui.ModNone,
+			Handler:     gui.handleImagesReverseSort,
+			Name:        "reverseSort",
+			Description: gui.Tr.ReverseSort,
+		},
 		{
 			ViewName:    "volumes",
 			Key:         '[',
 			Modifier:    gocui.ModNone,
 			Handler:     gui.handleVolumesPrevContext,
+			Name:        "prevTab",
 			Description: gui.Tr.PreviousContext,
 		},
 		{
@@ -400,6 +769,7 @@ func (gui *Gui) GetInitialKeybindings() []*Binding {
 			Key:         ']',
 			Modifier:    gocui.ModNone,
 			Handler:     gui.handleVolumesNextContext,
+			Name:        "nextTab",
 			Description: gui.Tr.NextContext,
 		},
 		{
@@ -407,6 +777,7 @@ func (gui *Gui) GetInitialKeybindings() []*Binding {
 			Key:         'c',
 			Modifier:    gocui.ModNone,
 			Handler:     gui.handleVolumesCustomCommand,
+			Name:        "customCommand",
 			Description: gui.Tr.RunCustomCommand,
 		},
 		{
@@ -414,22 +785,177 @@ func (gui *Gui) GetInitialKeybindings() []*Binding {
 			Key:         'd',
 			Modifier:    gocui.ModNone,
 			Handler:     gui.handleVolumesRemoveMenu,
+			Name:        "remove",
 			Description: gui.Tr.RemoveVolume,
 		},
+		{
+			ViewName:    "volumes",
+			Key:         'D',
+			Modifier:    gocui.ModNone,
+			Handler:     gui.handleVolumeQuickRemove,
+			Name:        "quickRemove",
+			Description: gui.Tr.QuickRemove,
+		},
 		{
 			ViewName:    "volumes",
 			Key:         'b',
 			Modifier:    gocui.ModNone,
 			Handler:     gui.handleVolumesBulkCommand,
+			Name:        "bulkCommand",
 			Description: gui.Tr.ViewBulkCommands,
 		},
+		{
+			ViewName:    "volumes",
+			Key:         'I',
+			Modifier:    gocui.ModNone,
+			Handler:     gui.handleVolumeInspect,
+			Name:        "inspect",
+			Description: gui.Tr.Inspect,
+		},
+		{
+			ViewName:    "volumes",
+			Key:         'o',
+			Modifier:    gocui.ModNone,
+			Handler:     gui.handleVolumeBrowse,
+			Name:        "browse",
+			Description: gui.Tr.BrowseVolume,
+		},
+		{
+			ViewName:    "volumes",
+			Key:         'y',
+			Modifier:    gocui.ModNone,
+			Handler:     gui.handleVolumeCopyName,
+			Name:        "copyName",
+			Description: gui.Tr.CopyVolumeName,
+		},
+		{
+			ViewName:    "volumes",
+			Key:         'n',
+			Modifier:    gocui.ModNone,
+			Handler:     gui.handleVolumeCreate,
+			Name:        "create",
+			Description: gui.Tr.CreateVolume,
+		},
+		{
+			ViewName:    "networks",
+			Key:         '[',
+			Modifier:    gocui.ModNone,
+			Handler:     gui.handleNetworksPrevContext,
+			Name:        "prevTab",
+			Description: gui.Tr.PreviousContext,
+		},
+		{
+			ViewName:    "networks",
+			Key:         ']',
+			Modifier:    gocui.ModNone,
+			Handler:     gui.handleNetworksNextContext,
+			Name:        "nextTab",
+			Description: gui.Tr.NextContext,
+		},
+		{
+			ViewName:    "networks",
+			Key:         'c',
+			Modifier:    gocui.ModNone,
+			Handler:     gui.handleNetworkConnect,
+			Name:        "connect",
+			Description: gui.Tr.ConnectContainer,
+		},
+		{
+			ViewName:    "networks",
+			Key:         'd',
+			Modifier:    gocui.ModNone,
+			Handler:     gui.handleNetworkDisconnect,
+			Name:        "disconnect",
+			Description: gui.Tr.DisconnectContainer,
+		},
+		{
+			ViewName:    "networks",
+			Key:         'I',
+			Modifier:    gocui.ModNone,
+			Handler:     gui.handleNetworkInspect,
+			Name:        "inspect",
+			Description: gui.Tr.Inspect,
+		},
+		{
+			ViewName:    "networks",
+			Key:         'n',
+			Modifier:    gocui.ModNone,
+			Handler:     gui.handleNetworkCreate,
+			Name:        "create",
+			Description: gui.Tr.CreateNetwork,
+		},
 		{
 			ViewName:    "main",
 			Key:         gocui.KeyEsc,
 			Modifier:    gocui.ModNone,
 			Handler:     gui.handleExitMain,
+			Name:        "return",
 			Description: gui.Tr.Return,
 		},
+		{
+			ViewName:    "main",
+			Key:         gocui.KeyEnter,
+			Modifier:    gocui.ModNone,
+			Handler:     gui.handleMergedLogsJump,
+			Name:        "goToContainer",
+			Description: gui.Tr.MergedLogsGoToContainer,
+		},
+		{
+			ViewName:    "main",
+			Key:         '/',
+			Modifier:    gocui.ModNone,
+			Handler:     gui.handleMainSearch,
+			Name:        "search",
+			Description: gui.Tr.SearchMain,
+		},
+		{
+			ViewName:    "main",
+			Key:         'n',
+			Modifier:    gocui.ModNone,
+			Handler:     gui.handleMainNextMatch,
+			Name:        "nextMatch",
+			Description: gui.Tr.NextMatch,
+		},
+		{
+			ViewName:    "main",
+			Key:         'N',
+			Modifier:    gocui.ModNone,
+			Handler:     gui.handleMainPrevMatch,
+			Name:        "prevMatch",
+			Description: gui.Tr.PrevMatch,
+		},
+		{
+			ViewName:    "main",
+			Key:         'c',
+			Modifier:    gocui.ModNone,
+			Handler:     gui.handleToggleSearchCase,
+			Name:        "toggleSearchCase",
+			Description: gui.Tr.ToggleSearchCase,
+		},
+		{
+			ViewName:    "main",
+			Key:         'e',
+			Modifier:    gocui.ModNone,
+			Handler:     gui.handleToggleSearchRegex,
+			Name:        "toggleSearchRegex",
+			Description: gui.Tr.ToggleSearchRegex,
+		},
+		{
+			ViewName:    "main",
+			Key:         'y',
+			Modifier:    gocui.ModNone,
+			Handler:     gui.handleMainCopy,
+			Name:        "copy",
+			Description: gui.Tr.CopyMain,
+		},
+		{
+			ViewName:    "main",
+			Key:         'r',
+			Modifier:    gocui.ModNone,
+			Handler:     gui.handleEnvReveal,
+			Name:        "revealSecrets",
+			Description: gui.Tr.RevealSecrets,
+		},
 		{
 			ViewName: "main",
 			Key:      gocui.KeyArrowLeft,
@@ -447,46 +973,52 @@ func (gui *Gui) GetInitialKeybindings() []*Binding {
 			Key:      'h',
 			Modifier: gocui.ModNone,
 			Handler:  gui.scrollLeftMain,
+			Name:     "scrollLeft",
 		},
 		{
 			ViewName: "main",
 			Key:      'l',
 			Modifier: gocui.ModNone,
 			Handler:  gui.scrollRightMain,
+			Name:     "scrollRight",
 		},
 		{
 			ViewName: "",
 			Key:      'J',
 			Modifier: gocui.ModNone,
 			Handler:  gui.scrollDownMain,
+			Name:     "scrollDownMain",
 		},
 		{
 			ViewName: "",
 			Key:      'K',
 			Modifier: gocui.ModNone,
 			Handler:  gui.scrollUpMain,
+			Name:     "scrollUpMain",
 		},
 		{
 			ViewName: "",
 			Key:      'H',
 			Modifier: gocui.ModNone,
 			Handler:  gui.scrollLeftMain,
+			Name:     "scrollLeftMain",
 		},
 		{
 			ViewName: "",
 			Key:      'L',
 			Modifier: gocui.ModNone,
 			Handler:  gui.scrollRightMain,
+			Name:     "scrollRightMain",
 		},
 	}
 
 	// TODO: add more views here
-	for _, viewName := range []string{"project", "services", "containers", "images", "volumes", "menu"} {
+	for _, viewName := range []string{"project", "services", "containers", "images", "volumes", "networks", "menu"} {
 		bindings = append(bindings, []*Binding{
 			{ViewName: viewName, Key: gocui.KeyArrowLeft, Modifier: gocui.ModNone, Handler: gui.previousView},
 			{ViewName: viewName, Key: gocui.KeyArrowRight, Modifier: gocui.ModNone, Handler: gui.nextView},
-			{ViewName: viewName, Key: 'h', Modifier: gocui.ModNone, Handler: gui.previousView},
-			{ViewName: viewName, Key: 'l', Modifier: gocui.ModNone, Handler: gui.nextView},
+			{ViewName: viewName, Key: 'h', Modifier: gocui.ModNone, Handler: gui.previousView, Name: "prevPanel"},
+			{ViewName: viewName, Key: 'l', Modifier: gocui.ModNone, Handler: gui.nextView, Name: "nextPanel"},
 		}...)
 	}
 
@@ -500,36 +1032,51 @@ func (gui *Gui) GetInitialKeybindings() []*Binding {
 		"containers": {onKeyUpPress: gui.handleContainersPrevLine, onKeyDownPress: gui.handleContainersNextLine, onClick: gui.handleContainersClick},
 		"images":     {onKeyUpPress: gui.handleImagesPrevLine, onKeyDownPress: gui.handleImagesNextLine, onClick: gui.handleImagesClick},
 		"volumes":    {onKeyUpPress: gui.handleVolumesPrevLine, onKeyDownPress: gui.handleVolumesNextLine, onClick: gui.handleVolumesClick},
+		"networks":   {onKeyUpPress: gui.handleNetworksPrevLine, onKeyDownPress: gui.handleNetworksNextLine, onClick: gui.handleNetworksClick},
 		"main":       {onKeyUpPress: gui.scrollUpMain, onKeyDownPress: gui.scrollDownMain, onClick: gui.handleMainClick},
 	}
 
 	for viewName, functions := range panelMap {
 		bindings = append(bindings, []*Binding{
-			{ViewName: viewName, Key: 'k', Modifier: gocui.ModNone, Handler: functions.onKeyUpPress},
+			{ViewName: viewName, Key: 'k', Modifier: gocui.ModNone, Handler: functions.onKeyUpPress, Name: "prevItem"},
 			{ViewName: viewName, Key: gocui.KeyArrowUp, Modifier: gocui.ModNone, Handler: functions.onKeyUpPress},
-			{ViewName: viewName, Key: gocui.MouseWheelUp, Modifier: gocui.ModNone, Handler: functions.onKeyUpPress},
-			{ViewName: viewName, Key: 'j', Modifier: gocui.ModNone, Handler: functions.onKeyDownPress},
+			{ViewName: viewName, Key: gocui.MouseWheelUp, Modifier: gocui.ModNone, Handler: gui.onWheel(functions.onKeyUpPress)},
+			{ViewName: viewName, Key: 'j', Modifier: gocui.ModNone, Handler: functions.onKeyDownPress, Name: "nextItem"},
 			{ViewName: viewName, Key: gocui.KeyArrowDown, Modifier: gocui.ModNone, Handler: functions.onKeyDownPress},
-			{ViewName: viewName, Key: gocui.MouseWheelDown, Modifier: gocui.ModNone, Handler: functions.onKeyDownPress},
+			{ViewName: viewName, Key: gocui.MouseWheelDown, Modifier: gocui.ModNone, Handler: gui.onWheel(functions.onKeyDownPress)},
 			{ViewName: viewName, Key: gocui.MouseLeft, Modifier: gocui.ModNone, Handler: functions.onClick},
 		}...)
 	}
 
-	for _, viewName := range []string{"project", "services", "containers", "images", "volumes"} {
-		bindings = append(bindings, &Binding{
-			ViewName:    viewName,
-			Key:         gocui.KeyEnter,
-			Modifier:    gocui.ModNone,
-			Handler:     gui.handleEnterMain,
-			Description: gui.Tr.FocusMain,
-		})
+	for _, viewName := range []string{"project", "services", "containers", "images", "volumes", "networks"} {
+		bindings = append(bindings, []*Binding{
+			{
+				ViewName:    viewName,
+				Key:         gocui.KeyEnter,
+				Modifier:    gocui.ModNone,
+				Handler:     gui.handleEnterMain,
+				Name:        "focusMain",
+				Description: gui.Tr.FocusMain,
+			},
+			{
+				ViewName:    viewName,
+				Key:         '/',
+				Modifier:    gocui.ModNone,
+				Handler:     gui.handleGlobalSearch,
+				Name:        "search",
+				Description: gui.Tr.GlobalSearch,
+			},
+		}...)
 	}
 
 	return bindings
 }
 
 func (gui *Gui) keybindings(g *gocui.Gui) error {
-	bindings := gui.GetInitialKeybindings()
+	bindings, err := gui.GetKeybindings()
+	if err != nil {
+		return err
+	}
 
 	for _, binding := range bindings {
 		if err := g.SetKeybinding(binding.ViewName, nil, binding.Key, binding.Modifier, binding.Handler); err != nil {
@@ -0,0 +1,93 @@
+package gui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/jesseduffield/lazydocker/pkg/commands"
+	"github.com/jesseduffield/lazydocker/pkg/utils"
+)
+
+// renderLabels shows a container's or image's labels, under a note saying why
+// they can't be changed from here. Docker has no way of changing the labels
+// of something that already exists.
+func (gui *Gui) renderLabels(labels []commands.Label, readOnlyNote string) error {
+	mainView := gui.getMainView()
+	mainView.Autoscroll = false
+	mainView.Wrap = gui.Config.UserConfig.Gui.WrapMainPanel
+
+	mainState := gui.State.Panels.Main
+	mainState.LabelsKey = mainState.ObjectKey
+	mainState.Labels = labels
+
+	output := utils.ColoredString(readOnlyNote, color.FgBlue) + "\n\n" + gui.renderLabelsTable(labels)
+	return gui.T.NewTask(func(stop chan struct{}) {
+		gui.renderString(gui.g, "main", output)
+	})
+}
+
+func (gui *Gui) renderLabelsTable(labels []commands.Label) string {
+	if len(labels) == 0 {
+		return gui.Tr.NoLabels
+	}
+
+	rows := make([][]string, len(labels))
+	for i, label := range labels {
+		rows[i] = []string{
+			utils.ColoredString(label.Key+":", color.FgGreen),
+			utils.ColoredString(label.Value, color.FgYellow),
+		}
+	}
+	renderedTable, err := utils.RenderTable(rows)
+	if err != nil {
+		gui.Log.Error(err)
+		return err.Error()
+	}
+	return renderedTable
+}
+
+// showingLabels tells us whether the main panel has labels in it
+func (gui *Gui) showingLabels() bool {
+	mainState := gui.State.Panels.Main
+	return mainState.LabelsKey != "" && mainState.LabelsKey == mainState.ObjectKey
+}
+
+// createLabelsCopyMenu lets the user copy all the labels, as key=value lines,
+// or pick one and copy its value
+func (gui *Gui) createLabelsCopyMenu() error {
+	labels := gui.State.Panels.Main.Labels
+	if len(labels) == 0 {
+		return nil
+	}
+
+	lines := make([]string, len(labels))
+	for i, label := range labels {
+		lines[i] = label.String()
+	}
+	options := []*commandOption{
+		{
+			description: gui.Tr.CopyAllLabels,
+			command:     fmt.Sprintf("%d", len(labels)),
+			f: func() error {
+				return gui.copyToClipboard(strings.Join(lines, "\n"), fmt.Sprintf(gui.Tr.CopiedToClipboardValue, gui.Tr.CopyAllLabels))
+			},
+		},
+	}
+	for _, label := range labels {
+		label := label
+		options = append(options, &commandOption{
+			description: label.Key,
+			command:     label.Value,
+			f: func() error {
+				return gui.copyToClipboard(label.Value, fmt.Sprintf(gui.Tr.CopiedToClipboardValue, label.Key))
+			},
+		})
+	}
+
+	handleMenuPress := func(index int) error {
+		return options[index].f()
+	}
+
+	return gui.createMenu(gui.Tr.CopyLabelTitle, options, len(options), handleMenuPress)
+}
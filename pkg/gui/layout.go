@@ -31,7 +31,9 @@ func (gui *Gui) getFocusLayout() func(g *gocui.Gui) error {
 func (gui *Gui) onFocusChange() error {
 	currentView := gui.g.CurrentView()
 	for _, view := range gui.g.Views() {
-		view.Highlight = view == currentView && view.Name() != "main"
+		// the main panel only has a selected line when it's showing merged logs
+		view.Highlight = view == currentView && (view.Name() != "main" || gui.mainShowingMergedLogs())
+		view.SelBgColor = gui.selectedLineBgColor
 	}
 	return nil
 }
@@ -112,7 +114,7 @@ func (gui *Gui) layout(g *gocui.Gui) error {
 
 	usableSpace := height - 4
 
-	tallPanels := 3
+	tallPanels := 4
 	var vHeights map[string]int
 	if gui.DockerCommand.InDockerComposeProject {
 		tallPanels++
@@ -122,6 +124,7 @@ func (gui *Gui) layout(g *gocui.Gui) error {
 			"containers": usableSpace / tallPanels,
 			"images":     usableSpace / tallPanels,
 			"volumes":    usableSpace / tallPanels,
+			"networks":   usableSpace / tallPanels,
 			"options":    1,
 		}
 	} else {
@@ -130,6 +133,7 @@ func (gui *Gui) layout(g *gocui.Gui) error {
 			"containers": usableSpace/tallPanels + usableSpace%tallPanels,
 			"images":     usableSpace / tallPanels,
 			"volumes":    usableSpace / tallPanels,
+			"networks":   usableSpace / tallPanels,
 			"options":    1,
 		}
 	}
@@ -144,6 +148,7 @@ func (gui *Gui) layout(g *gocui.Gui) error {
 			"containers": defaultHeight,
 			"images":     defaultHeight,
 			"volumes":    defaultHeight,
+			"networks":   defaultHeight,
 			"options":    defaultHeight,
 		}
 		if gui.DockerCommand.InDockerComposeProject {
@@ -205,11 +210,7 @@ func (gui *Gui) layout(g *gocui.Gui) error {
 			return err
 		}
 		containersView.Highlight = true
-		if gui.Config.UserConfig.Gui.ShowAllContainers || !gui.DockerCommand.InDockerComposeProject {
-			containersView.Title = gui.Tr.ContainersTitle
-		} else {
-			containersView.Title = gui.Tr.StandaloneContainersTitle
-		}
+		containersView.Title = gui.getContainersTitle()
 		containersView.FgColor = gocui.ColorDefault
 	}
 
@@ -219,7 +220,7 @@ func (gui *Gui) layout(g *gocui.Gui) error {
 			return err
 		}
 		imagesView.Highlight = true
-		imagesView.Title = gui.Tr.ImagesTitle
+		imagesView.Title = gui.getImagesTitle()
 		imagesView.FgColor = gocui.ColorDefault
 	}
 
@@ -233,6 +234,16 @@ func (gui *Gui) layout(g *gocui.Gui) error {
 		volumesView.FgColor = gocui.ColorDefault
 	}
 
+	networksView, err := g.SetViewBeneath("networks", "volumes", vHeights["networks"])
+	if err != nil {
+		if err.Error() != "unknown view" {
+			return err
+		}
+		networksView.Highlight = true
+		networksView.Title = gui.Tr.NetworksTitle
+		networksView.FgColor = gocui.ColorDefault
+	}
+
 	if v, err := g.SetView("options", appStatusOptionsBoundary-1, height-2, optionsVersionBoundary-1, height, 0); err != nil {
 		if err.Error() != "unknown view" {
 			return err
@@ -305,9 +316,10 @@ func (gui *Gui) focusPointInView(view *gocui.View) error {
 	}
 
 	listViews := map[string]listViewState{
-		"containers": {selectedLine: gui.State.Panels.Containers.SelectedLine, lineCount: len(gui.DockerCommand.DisplayContainers)},
+		"containers": {selectedLine: gui.State.Panels.Containers.SelectedLine, lineCount: len(gui.getContainerRows())},
 		"images":     {selectedLine: gui.State.Panels.Images.SelectedLine, lineCount: len(gui.DockerCommand.Images)},
 		"volumes":    {selectedLine: gui.State.Panels.Volumes.SelectedLine, lineCount: len(gui.DockerCommand.Volumes)},
+		"networks":   {selectedLine: gui.State.Panels.Networks.SelectedLine, lineCount: len(gui.DockerCommand.Networks)},
 		"services":   {selectedLine: gui.State.Panels.Services.SelectedLine, lineCount: len(gui.DockerCommand.Services)},
 		"menu":       {selectedLine: gui.State.Panels.Menu.SelectedLine, lineCount: gui.State.MenuItemCount},
 	}
@@ -0,0 +1,140 @@
+package gui
+
+import (
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/jesseduffield/gocui"
+	"github.com/jesseduffield/lazydocker/pkg/utils"
+)
+
+// logsWriter writes a container's logs to the main panel, highlighting what
+// we're searching for. It holds on to the lines so that they can be
+// highlighted again when the search changes.
+type logsWriter struct {
+	view    io.Writer
+	clear   func()
+	mutex   sync.Mutex
+	lines   []string
+	partial string
+	matcher *regexp.Regexp
+}
+
+// newLogsWriter returns a logsWriter for the main panel, remembering it as the
+// one to highlight searches in while key is what the main panel shows
+func (gui *Gui) newLogsWriter(key string) *logsWriter {
+	mainView := gui.getMainView()
+	matcher, _ := gui.searchMatcher()
+	writer := &logsWriter{view: mainView, clear: mainView.Clear, matcher: matcher}
+
+	gui.State.Panels.Main.LogsKey = key
+	gui.State.Panels.Main.LogsWriter = writer
+	return writer
+}
+
+func (w *logsWriter) Write(p []byte) (int, error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	lines := strings.Split(w.partial+string(p), "\n")
+	w.partial = lines[len(lines)-1]
+	lines = lines[:len(lines)-1]
+	w.lines = append(w.lines, lines...)
+
+	if err := w.writeLines(lines); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Flush writes out the last line, if it didn't end with a newline
+func (w *logsWriter) Flush() error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if w.partial == "" {
+		return nil
+	}
+	_, err := io.WriteString(w.view, utils.HighlightMatches(w.partial, w.matcher))
+	w.lines = append(w.lines, w.partial)
+	w.partial = ""
+	return err
+}
+
+// highlight starts highlighting what matcher matches, in the lines we've
+// written already and in those still to come. A nil matcher stops it.
+func (w *logsWriter) highlight(matcher *regexp.Regexp) error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	w.matcher = matcher
+	w.clear()
+	return w.writeLines(w.lines)
+}
+
+func (w *logsWriter) writeLines(lines []string) error {
+	output := strings.Builder{}
+	for _, line := range lines {
+		output.WriteString(utils.HighlightMatches(line, w.matcher))
+		output.WriteString("\n")
+	}
+	_, err := io.WriteString(w.view, output.String())
+	return err
+}
+
+// searchMatcher is what to search the main panel with: nil if we're not
+// searching, and an error if the search is an invalid regex
+func (gui *Gui) searchMatcher() (*regexp.Regexp, error) {
+	mainState := gui.State.Panels.Main
+	if mainState.SearchTerm == "" {
+		return nil, nil
+	}
+	return utils.SearchRegex(mainState.SearchTerm, mainState.SearchRegex, mainState.SearchCaseSensitive)
+}
+
+// highlightSearch highlights the matches of the search in the main panel, if
+// it's showing logs
+func (gui *Gui) highlightSearch(matcher *regexp.Regexp) error {
+	mainState := gui.State.Panels.Main
+	if mainState.LogsWriter != nil && mainState.LogsKey == mainState.ObjectKey {
+		return mainState.LogsWriter.highlight(matcher)
+	}
+	if gui.showingMergedLogs() && mainState.MergedLogs != nil {
+		return gui.setMergedLogsContent(gui.getMainView(), mainState.MergedLogs)
+	}
+	return nil
+}
+
+// handleToggleSearchCase switches between ignoring case and matching it when
+// searching the main panel
+func (gui *Gui) handleToggleSearchCase(g *gocui.Gui, v *gocui.View) error {
+	mainState := gui.State.Panels.Main
+	mainState.SearchCaseSensitive = !mainState.SearchCaseSensitive
+	if mainState.SearchCaseSensitive {
+		return gui.searchAgain(v, gui.Tr.SearchMatchingCase)
+	}
+	return gui.searchAgain(v, gui.Tr.SearchIgnoringCase)
+}
+
+// handleToggleSearchRegex switches between searching the main panel for text
+// and for a regex
+func (gui *Gui) handleToggleSearchRegex(g *gocui.Gui, v *gocui.View) error {
+	mainState := gui.State.Panels.Main
+	mainState.SearchRegex = !mainState.SearchRegex
+	if mainState.SearchRegex {
+		return gui.searchAgain(v, gui.Tr.SearchForRegex)
+	}
+	return gui.searchAgain(v, gui.Tr.SearchForText)
+}
+
+// searchAgain says what's changed about the search, and searches again if
+// we're searching
+func (gui *Gui) searchAgain(v *gocui.View, message string) error {
+	gui.showToast(message)
+	if gui.State.Panels.Main.SearchTerm == "" {
+		return nil
+	}
+	return gui.search(v)
+}
@@ -0,0 +1,57 @@
+package gui
+
+import (
+	"bytes"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogsWriter(t *testing.T) {
+	view := &bytes.Buffer{}
+	writer := &logsWriter{view: view, clear: view.Reset, matcher: regexp.MustCompile("oops")}
+
+	_, err := writer.Write([]byte("starting\noops\nhalf"))
+	assert.NoError(t, err)
+	assert.Equal(t, "starting\n\x1b[7moops\x1b[0m\n", view.String())
+
+	// the search changing highlights what's already been written
+	assert.NoError(t, writer.highlight(regexp.MustCompile("start")))
+	assert.Equal(t, "\x1b[7mstart\x1b[0ming\noops\n", view.String())
+
+	assert.NoError(t, writer.highlight(nil))
+	_, err = writer.Write([]byte(" done\n"))
+	assert.NoError(t, err)
+	assert.NoError(t, writer.Flush())
+	assert.Equal(t, "starting\noops\nhalf done\n", view.String())
+}
+
+func TestFindMatchingLine(t *testing.T) {
+	lines := []string{"web | starting", "db  | \x1b[31mERROR\x1b[0m oops", "web | ready", "db  | error again"}
+	matcher := regexp.MustCompile("(?i)error")
+
+	type scenario struct {
+		testName string
+		from     int
+		step     int
+		expected int
+	}
+
+	scenarios := []scenario{
+		{testName: "First", from: 0, step: 1, expected: 1},
+		{testName: "Next", from: 2, step: 1, expected: 3},
+		{testName: "Wrapping round", from: 4, step: 1, expected: 1},
+		{testName: "Previous", from: 2, step: -1, expected: 1},
+		{testName: "Previous wrapping round", from: 0, step: -1, expected: 3},
+	}
+
+	for _, s := range scenarios {
+		s := s
+		t.Run(s.testName, func(t *testing.T) {
+			assert.Equal(t, s.expected, findMatchingLine(lines, matcher, s.from, s.step))
+		})
+	}
+
+	assert.Equal(t, -1, findMatchingLine(lines, regexp.MustCompile("nothing"), 0, 1))
+}
@@ -8,6 +8,9 @@ import (
 
 func (gui *Gui) scrollUpMain(g *gocui.Gui, v *gocui.View) error {
 	mainView := gui.getMainView()
+	if gui.mainShowingMergedLogs() {
+		return gui.handleMergedLogsPrevLine(mainView)
+	}
 	mainView.Autoscroll = false
 	ox, oy := mainView.Origin()
 	newOy := int(math.Max(0, float64(oy-gui.Config.UserConfig.Gui.ScrollHeight)))
@@ -16,6 +19,9 @@ func (gui *Gui) scrollUpMain(g *gocui.Gui, v *gocui.View) error {
 
 func (gui *Gui) scrollDownMain(g *gocui.Gui, v *gocui.View) error {
 	mainView := gui.getMainView()
+	if gui.mainShowingMergedLogs() {
+		return gui.handleMergedLogsNextLine(mainView)
+	}
 	mainView.Autoscroll = false
 	ox, oy := mainView.Origin()
 
@@ -92,6 +98,9 @@ func (gui *Gui) onMainTabClick(tabIndex int) error {
 	case "volumes":
 		gui.State.Panels.Volumes.ContextIndex = tabIndex
 		return gui.handleVolumeSelect(gui.g, gui.getVolumesView())
+	case "networks":
+		gui.State.Panels.Networks.ContextIndex = tabIndex
+		return gui.handleNetworkSelect(gui.g, gui.getNetworksView())
 	}
 
 	return nil
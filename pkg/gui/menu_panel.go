@@ -60,7 +60,16 @@ func (gui *Gui) handleMenuClose(g *gocui.Gui, v *gocui.View) error {
 	if err != nil {
 		return err
 	}
-	return gui.returnFocus(g, v)
+	if err := gui.returnFocus(g, v); err != nil {
+		return err
+	}
+	if onClose := gui.State.Panels.Menu.OnClose; onClose != nil {
+		gui.State.Panels.Menu.OnClose = nil
+		if err := onClose(); err != nil {
+			return gui.createErrorPanel(gui.g, err.Error())
+		}
+	}
+	return nil
 }
 
 func (gui *Gui) createMenu(title string, items interface{}, itemCount int, handlePress func(int) error) error {
@@ -0,0 +1,262 @@
+package gui
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/jesseduffield/gocui"
+	"github.com/jesseduffield/lazydocker/pkg/commands"
+	"github.com/jesseduffield/lazydocker/pkg/utils"
+)
+
+// maxMergedLogLines is how many lines the merged logs tab holds on to, across
+// all its containers
+const maxMergedLogLines = 5000
+
+// mergedLogsRenderInterval stops a chatty container from re-rendering the
+// merged logs more often than is worth it
+const mergedLogsRenderInterval = 100 * time.Millisecond
+
+// mergedLogsColors are what each container's name is shown in, in turn
+var mergedLogsColors = []color.Attribute{color.FgCyan, color.FgYellow, color.FgGreen, color.FgMagenta, color.FgBlue, color.FgRed}
+
+// getMergedLogsContainers returns the containers whose logs go in the merged
+// logs tab: the marked ones, or failing that the ones from the selected
+// container's compose project
+func (gui *Gui) getMergedLogsContainers() []*commands.Container {
+	if marked := gui.getMarkedContainers(); len(marked) > 0 {
+		return marked
+	}
+	project, err := gui.getSelectedComposeProject()
+	if err != nil {
+		return nil
+	}
+	return project.Containers
+}
+
+func (gui *Gui) getMergedLogsTitle() string {
+	return fmt.Sprintf("%s (%d)", gui.Tr.MergedLogsTitle, len(gui.getMergedLogsContainers()))
+}
+
+// getMergedLogsKey is the ObjectKey for the merged logs tab, which only changes
+// with the containers in it, so that moving around the panel doesn't start the
+// logs over
+func (gui *Gui) getMergedLogsKey() string {
+	containers := gui.getMergedLogsContainers()
+	ids := make([]string, len(containers))
+	for i, container := range containers {
+		ids[i] = container.ID
+	}
+	sort.Strings(ids)
+	return "containers-mergedLogs-" + strings.Join(ids, ",")
+}
+
+// renderMergedLogs follows the logs of several containers at once, each line
+// starting with the name of the container it's from, like docker compose logs
+func (gui *Gui) renderMergedLogs() error {
+	mainView := gui.getMainView()
+	mainView.Autoscroll = true
+	mainView.Wrap = gui.Config.UserConfig.Gui.WrapMainPanel
+
+	containers := gui.getMergedLogsContainers()
+	if len(containers) == 0 {
+		gui.State.Panels.Main.MergedLogs = nil
+		gui.State.Panels.Main.MergedLogLines = nil
+		return gui.T.NewTask(func(stop chan struct{}) {
+			gui.renderString(gui.g, "main", gui.Tr.NoMergedLogs)
+		})
+	}
+
+	logs := commands.NewMergedLogs(containers, maxMergedLogLines)
+	gui.State.Panels.Main.MergedLogs = logs
+	gui.State.Panels.Main.MergedLogLines = nil
+	since := gui.getLogsSince()
+	return gui.T.NewTask(func(stop chan struct{}) {
+		ctx, cancel := contextFromStop(stop)
+		defer cancel()
+		go logs.Follow(ctx, since)
+
+		gui.renderString(gui.g, "main", "")
+		for {
+			select {
+			case <-stop:
+				return
+			case <-logs.Changed():
+				gui.reRenderMergedLogs(logs)
+			}
+
+			select {
+			case <-stop:
+				return
+			case <-time.After(mergedLogsRenderInterval):
+			}
+		}
+	})
+}
+
+// reRenderMergedLogs shows the lines we have so far
+func (gui *Gui) reRenderMergedLogs(logs *commands.MergedLogs) {
+	gui.g.Update(func(g *gocui.Gui) error {
+		v, err := g.View("main")
+		if err != nil {
+			return nil
+		}
+		return gui.setMergedLogsContent(v, logs)
+	})
+}
+
+// setMergedLogsContent puts the merged logs in the view, keeping the lines
+// around so that we know which container each line in the view is from
+func (gui *Gui) setMergedLogsContent(v *gocui.View, logs *commands.MergedLogs) error {
+	lines := logs.Lines()
+	matcher, _ := gui.searchMatcher()
+	gui.State.Panels.Main.MergedLogLines = lines
+	return gui.setViewContent(gui.g, v, formatMergedLogs(logs, lines, gui.logLevelColors(), matcher))
+}
+
+func formatMergedLogs(logs *commands.MergedLogs, lines []commands.MergedLogLine, colors utils.LogLevelColors, matcher *regexp.Regexp) string {
+	width := 0
+	prefixes := map[string]string{}
+	for _, container := range logs.Containers {
+		if len(container.Name) > width {
+			width = len(container.Name)
+		}
+	}
+	for i, container := range logs.Containers {
+		prefix := utils.WithPadding(container.Name, width) + " | "
+		prefixes[container.ID] = utils.ColoredString(prefix, mergedLogsColors[i%len(mergedLogsColors)])
+	}
+
+	output := strings.Builder{}
+	for _, line := range lines {
+		output.WriteString(prefixes[line.Container.ID])
+		output.WriteString(utils.HighlightMatches(utils.ColorLogLine(line.Text, colors), matcher))
+		output.WriteString("\n")
+	}
+	return output.String()
+}
+
+func (gui *Gui) showingMergedLogs() bool {
+	return gui.getContainerContexts()[gui.State.Panels.Containers.ContextIndex] == "mergedLogs"
+}
+
+// handleMergedLogsMenu lets the user hide the logs of some of the containers in
+// the merged logs tab, or show them again
+func (gui *Gui) handleMergedLogsMenu(g *gocui.Gui, v *gocui.View) error {
+	logs := gui.State.Panels.Main.MergedLogs
+	if !gui.showingMergedLogs() || logs == nil {
+		return nil
+	}
+
+	options := make([]*commandOption, len(logs.Containers))
+	for i, container := range logs.Containers {
+		container := container
+		state := utils.ColoredString(gui.Tr.MergedLogsShown, color.FgGreen)
+		if logs.IsHidden(container) {
+			state = utils.ColoredString(gui.Tr.MergedLogsHidden, color.FgRed)
+		}
+		options[i] = &commandOption{
+			description: container.Name,
+			command:     state,
+			f: func() error {
+				logs.ToggleHidden(container)
+				return nil
+			},
+		}
+	}
+
+	handleMenuPress := func(index int) error {
+		return options[index].f()
+	}
+
+	return gui.createMenu(gui.Tr.MergedLogsMenuTitle, options, len(options), handleMenuPress)
+}
+
+// mainShowingMergedLogs is true when the main panel has been entered from the
+// merged logs tab, where we move a cursor through the lines rather than
+// scrolling
+func (gui *Gui) mainShowingMergedLogs() bool {
+	mainView := gui.getMainView()
+	return mainView.ParentView != nil && mainView.ParentView.Name() == "containers" &&
+		gui.showingMergedLogs() && gui.State.Panels.Main.MergedLogs != nil
+}
+
+func (gui *Gui) handleMergedLogsPrevLine(v *gocui.View) error {
+	return gui.moveMergedLogsCursor(v, -1)
+}
+
+func (gui *Gui) handleMergedLogsNextLine(v *gocui.View) error {
+	return gui.moveMergedLogsCursor(v, 1)
+}
+
+func (gui *Gui) moveMergedLogsCursor(v *gocui.View, change int) error {
+	v.Autoscroll = false
+	lineCount := v.ViewLinesHeight()
+	selectedLine := v.SelectedLineIdx() + change
+	if selectedLine >= lineCount {
+		selectedLine = lineCount - 1
+	}
+	selectedLine = utils.Max(selectedLine, 0)
+	return gui.focusPoint(0, selectedLine, lineCount, v)
+}
+
+// handleMergedLogsJump goes to the container that the selected line in the
+// merged logs is from, showing its own logs
+func (gui *Gui) handleMergedLogsJump(g *gocui.Gui, v *gocui.View) error {
+	if !gui.mainShowingMergedLogs() {
+		return nil
+	}
+
+	lines := gui.State.Panels.Main.MergedLogLines
+	index := bufferLineIndex(v.BufferLines(), v.ViewBufferLines(), v.SelectedLineIdx())
+	if index < 0 || index >= len(lines) {
+		return nil
+	}
+	container := lines[index].Container
+
+	shown := false
+	for _, displayed := range gui.DockerCommand.DisplayContainers {
+		if displayed.ID == container.ID {
+			shown = true
+		}
+	}
+	if !shown {
+		gui.showToast(gui.Tr.MergedLogsContainerNotShown)
+		return nil
+	}
+
+	for i, context := range gui.getContainerContexts() {
+		if context == "logs" {
+			gui.State.Panels.Containers.ContextIndex = i
+		}
+	}
+	gui.State.Panels.Main.ObjectKey = ""
+	v.ParentView = nil
+
+	return gui.jumpToSearchItem(commands.SearchItem{Kind: commands.SearchKindContainer, ID: container.ID})
+}
+
+// bufferLineIndex returns which of a view's lines the given view line is part
+// of, given that a line is wrapped over several view lines when it doesn't
+// fit. It returns -1 if there's no such view line.
+func bufferLineIndex(bufferLines []string, viewLines []string, viewLineIndex int) int {
+	viewLine := 0
+	for i, line := range bufferLines {
+		length := 0
+		for viewLine < len(viewLines) {
+			if viewLine == viewLineIndex {
+				return i
+			}
+			length += len(viewLines[viewLine])
+			viewLine++
+			if length >= len(line) {
+				break
+			}
+		}
+	}
+	return -1
+}
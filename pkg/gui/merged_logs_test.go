@@ -0,0 +1,55 @@
+package gui
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBufferLineIndex(t *testing.T) {
+	type scenario struct {
+		testName      string
+		bufferLines   []string
+		viewLines     []string
+		viewLineIndex int
+		expected      int
+	}
+
+	scenarios := []scenario{
+		{
+			testName:      "Not wrapped",
+			bufferLines:   []string{"web | a", "db  | b"},
+			viewLines:     []string{"web | a", "db  | b"},
+			viewLineIndex: 1,
+			expected:      1,
+		},
+		{
+			testName:      "Wrapped",
+			bufferLines:   []string{"web | a long line", "db  | b"},
+			viewLines:     []string{"web | a ", "long lin", "e", "db  | b"},
+			viewLineIndex: 2,
+			expected:      0,
+		},
+		{
+			testName:      "After a wrapped line",
+			bufferLines:   []string{"web | a long line", "", "db  | b"},
+			viewLines:     []string{"web | a ", "long lin", "e", "", "db  | b"},
+			viewLineIndex: 4,
+			expected:      2,
+		},
+		{
+			testName:      "Past the end",
+			bufferLines:   []string{"web | a"},
+			viewLines:     []string{"web | a"},
+			viewLineIndex: 1,
+			expected:      -1,
+		},
+	}
+
+	for _, s := range scenarios {
+		s := s
+		t.Run(s.testName, func(t *testing.T) {
+			assert.Equal(t, s.expected, bufferLineIndex(s.bufferLines, s.viewLines, s.viewLineIndex))
+		})
+	}
+}
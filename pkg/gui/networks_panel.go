@@ -0,0 +1,359 @@
+package gui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-errors/errors"
+	"github.com/jesseduffield/gocui"
+	"github.com/jesseduffield/lazydocker/pkg/commands"
+	"github.com/jesseduffield/lazydocker/pkg/utils"
+)
+
+// list panel functions
+
+func (gui *Gui) getNetworkContexts() []string {
+	return []string{"config", "inspect"}
+}
+
+func (gui *Gui) getNetworkContextTitles() []string {
+	return []string{gui.Tr.ConfigTitle, gui.Tr.InspectTitle}
+}
+
+func (gui *Gui) getSelectedNetwork() (*commands.Network, error) {
+	selectedLine := gui.State.Panels.Networks.SelectedLine
+	if selectedLine == -1 {
+		return nil, gui.Errors.ErrNoNetworks
+	}
+
+	return gui.DockerCommand.Networks[selectedLine], nil
+}
+
+func (gui *Gui) handleNetworksClick(g *gocui.Gui, v *gocui.View) error {
+	itemCount := len(gui.DockerCommand.Networks)
+	handleSelect := gui.handleNetworkSelect
+	selectedLine := &gui.State.Panels.Networks.SelectedLine
+
+	return gui.handleClick(v, itemCount, selectedLine, handleSelect)
+}
+
+func (gui *Gui) handleNetworkSelect(g *gocui.Gui, v *gocui.View) error {
+	network, err := gui.getSelectedNetwork()
+	if err != nil {
+		if err != gui.Errors.ErrNoNetworks {
+			return err
+		}
+		return gui.renderString(g, "main", gui.Tr.NoNetworks)
+	}
+
+	if err := gui.focusPoint(0, gui.State.Panels.Networks.SelectedLine, len(gui.DockerCommand.Networks), v); err != nil {
+		return err
+	}
+
+	// the attached containers are part of the key so that the config tab
+	// keeps up with containers coming and going
+	key := "networks-" + network.ID + "-" + gui.getNetworkContexts()[gui.State.Panels.Networks.ContextIndex] + "-" + networkAttachmentsKey(network)
+	if !gui.shouldRefresh(key) {
+		return nil
+	}
+
+	mainView := gui.getMainView()
+	mainView.Tabs = gui.getNetworkContextTitles()
+	mainView.TabIndex = gui.State.Panels.Networks.ContextIndex
+
+	switch gui.getNetworkContexts()[gui.State.Panels.Networks.ContextIndex] {
+	case "config":
+		if err := gui.renderNetworkConfig(mainView, network); err != nil {
+			return err
+		}
+	case "inspect":
+		if err := gui.renderInspect(network.InspectRaw); err != nil {
+			return err
+		}
+	default:
+		return errors.New("Unknown context for Networks panel")
+	}
+
+	return nil
+}
+
+func networkAttachmentsKey(network *commands.Network) string {
+	ids := make([]string, len(network.Attachments))
+	for i, attachment := range network.Attachments {
+		ids[i] = attachment.Container.ID + "=" + attachment.Endpoint.IPAddress
+	}
+	return strings.Join(ids, ",")
+}
+
+func (gui *Gui) renderNetworkConfig(mainView *gocui.View, network *commands.Network) error {
+	return gui.T.NewTask(func(stop chan struct{}) {
+		mainView.Autoscroll = false
+		mainView.Wrap = gui.Config.UserConfig.Gui.WrapMainPanel
+
+		padding := 15
+		output := ""
+		output += utils.WithPadding("Name: ", padding) + network.Name + "\n"
+		output += utils.WithPadding("ID: ", padding) + network.ID + "\n"
+		output += utils.WithPadding("Driver: ", padding) + network.Network.Driver + "\n"
+		output += utils.WithPadding("Scope: ", padding) + network.Network.Scope + "\n"
+		output += utils.WithPadding("Internal: ", padding) + fmt.Sprintf("%t", network.Network.Internal) + "\n"
+		for _, ipam := range network.Network.IPAM.Config {
+			output += utils.WithPadding("Subnet: ", padding) + ipam.Subnet + "\n"
+			if ipam.Gateway != "" {
+				output += utils.WithPadding("Gateway: ", padding) + ipam.Gateway + "\n"
+			}
+		}
+		output += utils.WithPadding("Labels: ", padding) + utils.FormatMap(padding, network.Network.Labels) + "\n"
+		output += utils.WithPadding("Options: ", padding) + utils.FormatMap(padding, network.Network.Options) + "\n"
+
+		output += utils.WithPadding("Containers: ", padding)
+		if len(network.Attachments) == 0 {
+			output += "none\n"
+		} else {
+			output += "\n"
+			for _, attachment := range network.Attachments {
+				value := attachment.Endpoint.IPAddress
+				if len(attachment.Endpoint.Aliases) > 0 {
+					value += " (" + strings.Join(attachment.Endpoint.Aliases, ", ") + ")"
+				}
+				output += utils.FormatMapItem(padding, attachment.Container.Name, value)
+			}
+		}
+
+		gui.renderString(gui.g, "main", output)
+	})
+}
+
+func (gui *Gui) refreshNetworks() error {
+	networksView := gui.getNetworksView()
+	if networksView == nil {
+		// if the networksView hasn't been instantiated yet we just return
+		return nil
+	}
+	if err := gui.DockerCommand.RefreshNetworks(); err != nil {
+		return err
+	}
+
+	networkIDs := make([]string, len(gui.DockerCommand.Networks))
+	for i, network := range gui.DockerCommand.Networks {
+		networkIDs[i] = network.ID
+	}
+	if err := gui.restoreSelection(networksView, networkIDs, &gui.State.Panels.Networks.SelectedLine); err != nil {
+		return err
+	}
+
+	if len(gui.DockerCommand.Networks) > 0 && gui.State.Panels.Networks.SelectedLine == -1 {
+		gui.State.Panels.Networks.SelectedLine = 0
+	}
+	if len(gui.DockerCommand.Networks)-1 < gui.State.Panels.Networks.SelectedLine {
+		gui.State.Panels.Networks.SelectedLine = len(gui.DockerCommand.Networks) - 1
+	}
+
+	gui.g.Update(func(g *gocui.Gui) error {
+		networksView.Clear()
+		isFocused := gui.g.CurrentView().Name() == "networks"
+		list, err := utils.RenderList(gui.DockerCommand.Networks, utils.IsFocused(isFocused))
+		if err != nil {
+			return err
+		}
+		fmt.Fprint(networksView, list)
+
+		if networksView == g.CurrentView() {
+			return gui.handleNetworkSelect(g, networksView)
+		}
+		return nil
+	})
+
+	return nil
+}
+
+func (gui *Gui) handleNetworksNextLine(g *gocui.Gui, v *gocui.View) error {
+	if gui.popupPanelFocused() || gui.g.CurrentView() != v {
+		return nil
+	}
+
+	panelState := gui.State.Panels.Networks
+	gui.changeSelectedLine(&panelState.SelectedLine, len(gui.DockerCommand.Networks), false)
+
+	return gui.handleNetworkSelect(gui.g, v)
+}
+
+func (gui *Gui) handleNetworksPrevLine(g *gocui.Gui, v *gocui.View) error {
+	if gui.popupPanelFocused() || gui.g.CurrentView() != v {
+		return nil
+	}
+
+	panelState := gui.State.Panels.Networks
+	gui.changeSelectedLine(&panelState.SelectedLine, len(gui.DockerCommand.Networks), true)
+
+	return gui.handleNetworkSelect(gui.g, v)
+}
+
+func (gui *Gui) handleNetworksNextContext(g *gocui.Gui, v *gocui.View) error {
+	contexts := gui.getNetworkContexts()
+	if gui.State.Panels.Networks.ContextIndex >= len(contexts)-1 {
+		gui.State.Panels.Networks.ContextIndex = 0
+	} else {
+		gui.State.Panels.Networks.ContextIndex++
+	}
+
+	gui.handleNetworkSelect(gui.g, v)
+
+	return nil
+}
+
+func (gui *Gui) handleNetworksPrevContext(g *gocui.Gui, v *gocui.View) error {
+	contexts := gui.getNetworkContexts()
+	if gui.State.Panels.Networks.ContextIndex <= 0 {
+		gui.State.Panels.Networks.ContextIndex = len(contexts) - 1
+	} else {
+		gui.State.Panels.Networks.ContextIndex--
+	}
+
+	gui.handleNetworkSelect(gui.g, v)
+
+	return nil
+}
+
+// handleNetworkConnect offers the containers that aren't on the selected
+// network yet, then asks for any aliases to give the one picked
+func (gui *Gui) handleNetworkConnect(g *gocui.Gui, v *gocui.View) error {
+	network, err := gui.getSelectedNetwork()
+	if err != nil {
+		return nil
+	}
+
+	options := []*commandOption{}
+	for _, container := range gui.DockerCommand.Containers {
+		if network.HasContainer(container) {
+			continue
+		}
+		container := container
+		options = append(options, &commandOption{
+			description: container.Name,
+			command:     "docker network connect " + network.Name + " " + container.Name,
+			f: func() error {
+				// once the menu has closed
+				gui.g.Update(func(g *gocui.Gui) error {
+					return gui.promptNetworkAliases(v, network, container)
+				})
+				return nil
+			},
+		})
+	}
+	if len(options) == 0 {
+		return gui.createErrorPanel(gui.g, gui.Tr.NoContainersToConnect)
+	}
+
+	handleMenuPress := func(index int) error {
+		return options[index].f()
+	}
+
+	return gui.createMenu(fmt.Sprintf(gui.Tr.ConnectContainerTitle, network.Name), options, len(options), handleMenuPress)
+}
+
+func (gui *Gui) promptNetworkAliases(v *gocui.View, network *commands.Network, container *commands.Container) error {
+	title := fmt.Sprintf(gui.Tr.NetworkAliasesTitle, container.Name, network.Name)
+	return gui.createPromptPanel(gui.g, v, title, func(g *gocui.Gui, promptView *gocui.View) error {
+		aliases := strings.Fields(gui.trimmedContent(promptView))
+		return gui.WithWaitingStatus(gui.Tr.ConnectingStatus, func() error {
+			if err := network.Connect(container, aliases); err != nil {
+				return gui.createErrorPanel(gui.g, err.Error())
+			}
+			return gui.refreshAfterNetworkChange()
+		})
+	})
+}
+
+// handleNetworkDisconnect offers the containers on the selected network to
+// take off it. Taking a container off its only network cuts it off entirely,
+// so we check first.
+func (gui *Gui) handleNetworkDisconnect(g *gocui.Gui, v *gocui.View) error {
+	network, err := gui.getSelectedNetwork()
+	if err != nil {
+		return nil
+	}
+	if len(network.Attachments) == 0 {
+		return gui.createErrorPanel(gui.g, gui.Tr.NoContainersToDisconnect)
+	}
+
+	options := make([]*commandOption, len(network.Attachments))
+	for i, attachment := range network.Attachments {
+		container := attachment.Container
+		disconnect := func(g *gocui.Gui, _ *gocui.View) error {
+			return gui.WithWaitingStatus(gui.Tr.DisconnectingStatus, func() error {
+				if err := network.Disconnect(container); err != nil {
+					return gui.createErrorPanel(gui.g, err.Error())
+				}
+				return gui.refreshAfterNetworkChange()
+			})
+		}
+		options[i] = &commandOption{
+			description: container.Name,
+			command:     "docker network disconnect " + network.Name + " " + container.Name,
+			f: func() error {
+				if len(commands.ContainerNetworks(container)) > 1 {
+					return disconnect(gui.g, v)
+				}
+				// once the menu has closed
+				gui.g.Update(func(g *gocui.Gui) error {
+					message := fmt.Sprintf(gui.Tr.ConfirmDisconnectOnlyNetwork, container.Name, network.Name)
+					return gui.createConfirmationPanel(g, v, gui.Tr.Confirm, message, disconnect, nil)
+				})
+				return nil
+			},
+		}
+	}
+
+	handleMenuPress := func(index int) error {
+		return options[index].f()
+	}
+
+	return gui.createMenu(fmt.Sprintf(gui.Tr.DisconnectContainerTitle, network.Name), options, len(options), handleMenuPress)
+}
+
+// refreshAfterNetworkChange refreshes the containers before the networks, as
+// it's the containers that tell us which networks they're on
+func (gui *Gui) refreshAfterNetworkChange() error {
+	if err := gui.refreshContainersAndServices(); err != nil {
+		return err
+	}
+	return gui.refreshNetworks()
+}
+
+// handleNetworkCreate asks for the new network's name, driver, subnet and
+// gateway, as docker network create takes them, then selects the network once
+// it's made
+func (gui *Gui) handleNetworkCreate(g *gocui.Gui, v *gocui.View) error {
+	return gui.createPromptPanel(g, v, gui.Tr.CreateNetworkTitle, func(g *gocui.Gui, promptView *gocui.View) error {
+		input := gui.trimmedContent(promptView)
+		if input == "" {
+			return nil
+		}
+		name, options, err := commands.ParseNetworkCreate(input)
+		if err != nil {
+			return gui.createErrorPanel(gui.g, err.Error())
+		}
+
+		return gui.WithWaitingStatus(gui.Tr.CreatingStatus, func() error {
+			id, err := gui.DockerCommand.CreateNetwork(name, options)
+			if err != nil {
+				return gui.createErrorPanel(gui.g, err.Error())
+			}
+			if err := gui.refreshNetworks(); err != nil {
+				return err
+			}
+			// after the refresh has rendered the list
+			gui.g.Update(func(g *gocui.Gui) error {
+				for i, network := range gui.DockerCommand.Networks {
+					if network.ID == id {
+						gui.State.Panels.Networks.SelectedLine = i
+						return gui.handleNetworkSelect(g, gui.getNetworksView())
+					}
+				}
+				return nil
+			})
+			return nil
+		})
+	})
+}
@@ -13,7 +13,8 @@ func (gui *Gui) getBindings(v *gocui.View) []*Binding {
 		bindingsGlobal, bindingsPanel []*Binding
 	)
 
-	bindings := gui.GetInitialKeybindings()
+	// the keybinding config was validated at startup, so there's no error
+	bindings, _ := gui.GetKeybindings()
 
 	for _, binding := range bindings {
 		if binding.GetKey() != "" && binding.Description != "" {
@@ -0,0 +1,76 @@
+package gui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jesseduffield/gocui"
+	"github.com/jesseduffield/lazydocker/pkg/commands"
+)
+
+// createPublishedPortsMenu offers to open the container's http ports in the
+// browser. Over an ssh tunnel the ports are on the remote host, so instead we
+// offer to forward them here through the tunnel first.
+func (gui *Gui) createPublishedPortsMenu(v *gocui.View, container *commands.Container) error {
+	ports := container.PublishedPorts()
+	if len(ports) == 0 {
+		return gui.createErrorPanel(gui.g, gui.Tr.NoPublishedPorts)
+	}
+
+	tunneled := gui.DockerCommand.IsTunneled()
+	options := []*commandOption{}
+	for _, port := range ports {
+		port := port
+		switch {
+		case tunneled && port.Protocol != "tcp":
+			// ssh only forwards tcp
+		case tunneled && port.IsHTTP():
+			options = append(options, &commandOption{
+				description: fmt.Sprintf(gui.Tr.ForwardAndOpenPort, port),
+				f:           func() error { return gui.forwardPort(v, port) },
+			})
+		case tunneled:
+			options = append(options, &commandOption{
+				description: fmt.Sprintf(gui.Tr.ForwardPort, port),
+				f:           func() error { return gui.forwardPort(v, port) },
+			})
+		case port.IsHTTP():
+			link := port.URL(port.HostAddress())
+			options = append(options, &commandOption{
+				description: fmt.Sprintf(gui.Tr.OpenPort, port),
+				command:     link,
+				f:           func() error { return gui.OSCommand.OpenLink(link) },
+			})
+		}
+	}
+
+	if len(options) == 0 {
+		descriptions := make([]string, len(ports))
+		for i, port := range ports {
+			descriptions[i] = port.String()
+		}
+		return gui.createErrorPanel(gui.g, fmt.Sprintf(gui.Tr.NoHTTPPorts, strings.Join(descriptions, ", ")))
+	}
+
+	handleMenuPress := func(index int) error {
+		return options[index].f()
+	}
+
+	return gui.createMenu(gui.Tr.PublishedPortsTitle, options, len(options), handleMenuPress)
+}
+
+// forwardPort forwards the port through the ssh tunnel, then opens it in the
+// browser if it looks like http, or says where it can be reached otherwise
+func (gui *Gui) forwardPort(v *gocui.View, port commands.PublishedPort) error {
+	return gui.WithWaitingStatus(gui.Tr.ForwardingStatus, func() error {
+		localAddress, err := gui.DockerCommand.ForwardPort(context.Background(), port)
+		if err != nil {
+			return err
+		}
+		if port.IsHTTP() {
+			return gui.OSCommand.OpenLink(port.URL(localAddress))
+		}
+		return gui.createConfirmationPanel(gui.g, v, gui.Tr.PortForwardedTitle, fmt.Sprintf(gui.Tr.PortForwarded, port, localAddress), nil, nil)
+	})
+}
@@ -16,16 +16,16 @@ import (
 
 func (gui *Gui) getProjectContexts() []string {
 	if gui.DockerCommand.InDockerComposeProject {
-		return []string{"logs", "config", "credits"}
+		return []string{"logs", "config", "credits", "events", "diskUsage"}
 	}
-	return []string{"credits"}
+	return []string{"credits", "events", "diskUsage"}
 }
 
 func (gui *Gui) getProjectContextTitles() []string {
 	if gui.DockerCommand.InDockerComposeProject {
-		return []string{gui.Tr.LogsTitle, gui.Tr.DockerComposeConfigTitle, gui.Tr.CreditsTitle}
+		return []string{gui.Tr.LogsTitle, gui.Tr.DockerComposeConfigTitle, gui.Tr.CreditsTitle, gui.getEventsTitle(), gui.getDiskUsageTitle()}
 	}
-	return []string{gui.Tr.CreditsTitle}
+	return []string{gui.Tr.CreditsTitle, gui.getEventsTitle(), gui.getDiskUsageTitle()}
 }
 
 func (gui *Gui) refreshProject() error {
@@ -41,15 +41,42 @@ func (gui *Gui) refreshProject() error {
 		}
 	}
 
+	connection := gui.renderConnectionStatus(gui.DockerCommand.ConnectionStatus())
 	gui.g.Update(func(*gocui.Gui) error {
 		v.Clear()
-		fmt.Fprint(v, projectName)
+		fmt.Fprint(v, projectName+"  "+connection+gui.readOnlyLabel())
 		return nil
 	})
 
 	return nil
 }
 
+// renderConnectionStatus says where the daemon is and how our connection to
+// it is doing, e.g. '● connected to ssh://me@box'
+func (gui *Gui) renderConnectionStatus(status commands.ConnectionStatus) string {
+	switch status.State {
+	case commands.ConnectionReconnecting:
+		return utils.ColoredString("◌ "+gui.Tr.ConnectionReconnecting, color.FgYellow) + " " + status.Endpoint
+	case commands.ConnectionFailed:
+		return utils.ColoredString("✗ "+gui.Tr.ConnectionLost, color.FgRed) + " " + status.Endpoint
+	}
+	return utils.ColoredString("● "+gui.Tr.ConnectionUp, color.FgGreen) + " " + status.Endpoint
+}
+
+// checkConnection pings the daemon, updating the project panel if the
+// connection's gone up or down since last time
+func (gui *Gui) checkConnection() error {
+	previous := gui.DockerCommand.ConnectionStatus()
+	status := gui.DockerCommand.CheckConnection()
+	if status.State == previous.State && status.Endpoint == previous.Endpoint {
+		return nil
+	}
+	if status.Err != nil {
+		gui.Log.Warn(fmt.Sprintf("connection to %s: %v", status.Endpoint, status.Err))
+	}
+	return gui.refreshProject()
+}
+
 func (gui *Gui) handleProjectClick(g *gocui.Gui, v *gocui.View) error {
 	if gui.popupPanelFocused() {
 		return nil
@@ -91,6 +118,14 @@ func (gui *Gui) handleProjectSelect(g *gocui.Gui, v *gocui.View) error {
 		if err := gui.renderDockerComposeConfig(); err != nil {
 			return err
 		}
+	case "events":
+		if err := gui.renderEvents(); err != nil {
+			return err
+		}
+	case "diskUsage":
+		if err := gui.renderDiskUsage(); err != nil {
+			return err
+		}
 	default:
 		return errors.New("Unknown context for status panel")
 	}
@@ -0,0 +1,39 @@
+package gui
+
+import (
+	"fmt"
+
+	"github.com/jesseduffield/gocui"
+	"github.com/jesseduffield/lazydocker/pkg/commands"
+	"github.com/jesseduffield/lazydocker/pkg/utils"
+)
+
+// runPrune asks the user to confirm the prune, runs it, and then tells them
+// how much it got rid of. refresh, if given, is called once the prune is done.
+func (gui *Gui) runPrune(v *gocui.View, confirmText string, prune func() (commands.PruneReport, error), refresh func() error) error {
+	// the disk usage menu prunes as well as shows things, so it's still open
+	// in read-only mode
+	if gui.readOnly() {
+		return gui.handleReadOnly(gui.g, v)
+	}
+
+	return gui.createConfirmationPanel(gui.g, v, gui.Tr.Confirm, confirmText, func(g *gocui.Gui, _ *gocui.View) error {
+		return gui.WithWaitingStatus(gui.Tr.PruningStatus, func() error {
+			report, err := prune()
+			if err != nil {
+				return err
+			}
+			if refresh != nil {
+				if err := refresh(); err != nil {
+					return err
+				}
+			}
+
+			message := fmt.Sprintf(gui.Tr.PruneReport, report.ItemsDeleted, utils.FormatDecimalBytes(int(report.SpaceReclaimed)))
+			gui.g.Update(func(g *gocui.Gui) error {
+				return gui.createConfirmationPanel(gui.g, v, gui.Tr.PrunedTitle, message, nil, nil)
+			})
+			return nil
+		})
+	}, nil)
+}
@@ -0,0 +1,80 @@
+package gui
+
+import (
+	"fmt"
+
+	"github.com/docker/docker/api/types"
+	"github.com/jesseduffield/gocui"
+	"github.com/jesseduffield/lazydocker/pkg/commands"
+)
+
+// quickRemove removes something with the remove menu's first option, skipping
+// the menu. Unless confirmDestructive is off, we still ask first. Either way,
+// a toast says what went, so that a slip of the finger doesn't go unnoticed.
+func (gui *Gui) quickRemove(v *gocui.View, name string, remove func() error, refresh func() error) error {
+	run := func(g *gocui.Gui, v *gocui.View) error {
+		return gui.WithWaitingStatus(gui.Tr.RemovingStatus, func() error {
+			if err := remove(); err != nil {
+				return gui.createErrorPanel(gui.g, err.Error())
+			}
+			gui.showToast(fmt.Sprintf(gui.Tr.RemovedValue, name))
+			return refresh()
+		})
+	}
+
+	if !gui.Config.UserConfig.ConfirmDestructive {
+		return run(gui.g, v)
+	}
+	return gui.createConfirmationPanel(gui.g, v, gui.Tr.Confirm, fmt.Sprintf(gui.Tr.ConfirmQuickRemove, name), run, nil)
+}
+
+func (gui *Gui) handleContainerQuickRemove(g *gocui.Gui, v *gocui.View) error {
+	container, err := gui.getSelectedContainer()
+	if err != nil {
+		return nil
+	}
+
+	// forcing, as otherwise a running container would need asking about
+	return gui.quickRemove(v, container.Name, func() error {
+		return container.Remove(types.ContainerRemoveOptions{Force: true})
+	}, gui.refreshContainersAndServices)
+}
+
+func (gui *Gui) handleServiceQuickRemove(g *gocui.Gui, v *gocui.View) error {
+	service, err := gui.getSelectedService()
+	if err != nil {
+		return nil
+	}
+
+	command := fmt.Sprintf("%s rm --stop --force %s", gui.Config.UserConfig.CommandTemplates.DockerCompose, service.Name)
+	return gui.quickRemove(v, service.Name, func() error {
+		defer gui.DockerCommand.InvalidateContainerCache()
+		return gui.OSCommand.RunCommand(command)
+	}, gui.refreshContainersAndServices)
+}
+
+func (gui *Gui) handleImageQuickRemove(g *gocui.Gui, v *gocui.View) error {
+	image, err := gui.getSelectedImage()
+	if err != nil {
+		return nil
+	}
+
+	name := commands.ShortID(image.ID)
+	if tags := image.Tags(); len(tags) > 0 {
+		name = tags[0]
+	}
+	return gui.quickRemove(v, name, func() error {
+		return image.Remove(types.ImageRemoveOptions{PruneChildren: true})
+	}, gui.refreshImages)
+}
+
+func (gui *Gui) handleVolumeQuickRemove(g *gocui.Gui, v *gocui.View) error {
+	volume, err := gui.getSelectedVolume()
+	if err != nil {
+		return nil
+	}
+
+	return gui.quickRemove(v, volume.Name, func() error {
+		return volume.Remove(false)
+	}, gui.refreshVolumes)
+}
@@ -0,0 +1,72 @@
+package gui
+
+import (
+	"github.com/fatih/color"
+	"github.com/jesseduffield/gocui"
+	"github.com/jesseduffield/lazydocker/pkg/utils"
+)
+
+// mutatingBindings are the bindings that change something on the daemon, or
+// run something that could, keyed by view then binding name. In read-only
+// mode they show a toast rather than doing anything.
+var mutatingBindings = map[string][]string{
+	"": {"runCommand"},
+	"containers": {
+		"remove", "quickRemove", "stop", "stopWithTimeout", "kill", "restart",
+		"restartOptions", "rename", "attach", "execShell", "customCommand",
+		"bulkCommand", "composeProjectMenu", "copyFiles",
+	},
+	"services": {
+		"remove", "quickRemove", "stop", "restart", "restartOptions", "attach",
+		"customCommand", "bulkCommand",
+	},
+	"images": {
+		"remove", "quickRemove", "pull", "build", "push", "tag", "untag", "customCommand",
+		"bulkCommand",
+	},
+	// browsing a volume runs a helper container with it mounted
+	"volumes":  {"remove", "quickRemove", "create", "browse", "customCommand", "bulkCommand"},
+	"networks": {"connect", "disconnect", "create"},
+}
+
+func (gui *Gui) readOnly() bool {
+	return gui.Config.UserConfig.ReadOnly
+}
+
+// disableMutatingBindings points the mutating bindings at handleReadOnly if
+// we're in read-only mode
+func (gui *Gui) disableMutatingBindings(bindings []*Binding) {
+	if !gui.readOnly() {
+		return
+	}
+
+	for _, binding := range bindings {
+		if isMutating(binding) {
+			binding.Handler = gui.handleReadOnly
+		}
+	}
+}
+
+// isMutating tells us whether binding is one of the mutatingBindings
+func isMutating(binding *Binding) bool {
+	for _, name := range mutatingBindings[binding.ViewName] {
+		if binding.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (gui *Gui) handleReadOnly(g *gocui.Gui, v *gocui.View) error {
+	gui.showToast(gui.Tr.ReadOnlyMode)
+	return nil
+}
+
+// readOnlyLabel is what the project panel shows after the connection status
+// in read-only mode
+func (gui *Gui) readOnlyLabel() string {
+	if !gui.readOnly() {
+		return ""
+	}
+	return "  " + utils.ColoredString(gui.Tr.ReadOnlyMode, color.FgYellow)
+}
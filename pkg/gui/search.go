@@ -0,0 +1,240 @@
+package gui
+
+import (
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/jesseduffield/gocui"
+	"github.com/jesseduffield/lazydocker/pkg/commands"
+	"github.com/jesseduffield/lazydocker/pkg/utils"
+)
+
+// searchResultLimit is how many results the global search shows at most
+const searchResultLimit = 50
+
+type searchResultRow struct {
+	result   commands.SearchResult
+	kind     string
+	selected bool
+}
+
+// GetDisplayStrings is a function.
+func (r *searchResultRow) GetDisplayStrings(isFocused bool) []string {
+	marker := " "
+	if r.selected {
+		marker = utils.ColoredString(">", color.FgCyan)
+	}
+
+	name := r.result.Item.Name
+	detail := ""
+	if r.result.MatchedName() {
+		name = commands.HighlightPositions(name, r.result.Positions)
+	} else {
+		// it was the ID or a label that matched, so we show that too
+		detail = commands.HighlightPositions(r.result.Field, r.result.Positions)
+	}
+
+	return []string{marker, utils.ColoredString(r.kind, color.FgMagenta), name, detail}
+}
+
+// handleGlobalSearch opens the search bar, which looks through the containers,
+// images, volumes and networks as you type and shows what it finds in the main
+// panel. Enter jumps to the selected result and esc goes back to where you were.
+func (gui *Gui) handleGlobalSearch(g *gocui.Gui, v *gocui.View) error {
+	gui.State.Panels.Search = &searchPanelState{}
+	// the results take over the main panel, so whatever was there has to be
+	// rendered again once we're done
+	gui.State.Panels.Main.ObjectKey = ""
+
+	gui.onNewPopupPanel()
+	searchView, err := gui.prepareConfirmationPanel(v, gui.Tr.GlobalSearchTitle, "", false)
+	if err != nil {
+		return err
+	}
+	searchView.Editable = true
+	searchView.Editor = gocui.EditorFunc(func(searchView *gocui.View, key gocui.Key, ch rune, mod gocui.Modifier) {
+		gocui.DefaultEditor.Edit(searchView, key, ch, mod)
+		gui.runGlobalSearch(gui.trimmedContent(searchView))
+	})
+	gui.runGlobalSearch("")
+
+	bindings := []struct {
+		key     gocui.Key
+		handler func(*gocui.Gui, *gocui.View) error
+	}{
+		{key: gocui.KeyArrowUp, handler: gui.handleSearchPrevResult},
+		{key: gocui.KeyCtrlP, handler: gui.handleSearchPrevResult},
+		{key: gocui.KeyArrowDown, handler: gui.handleSearchNextResult},
+		{key: gocui.KeyCtrlN, handler: gui.handleSearchNextResult},
+		{key: gocui.KeyEnter, handler: gui.handleSearchJump},
+		{key: gocui.KeyEsc, handler: gui.wrappedConfirmationFunction(gui.handleSearchClose)},
+	}
+	for _, binding := range bindings {
+		if err := g.SetKeybinding("confirmation", nil, binding.key, gocui.ModNone, binding.handler); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runGlobalSearch searches for query in the background, so that typing stays
+// snappy however much there is to look through. If another keypress comes in
+// before it's done, its results are thrown away.
+func (gui *Gui) runGlobalSearch(query string) {
+	state := gui.State.Panels.Search
+	state.Query = query
+	// taking the lists as they are now, because the refresh loop swaps in
+	// new ones as it goes
+	items := commands.GetSearchItems(gui.DockerCommand.DisplayContainers, gui.DockerCommand.Images, gui.DockerCommand.Volumes, gui.DockerCommand.Networks)
+
+	mainView := gui.getMainView()
+	mainView.Tabs = []string{gui.Tr.SearchTitle}
+	mainView.TabIndex = 0
+	mainView.Autoscroll = false
+	mainView.Wrap = false
+
+	_ = gui.T.NewTask(func(stop chan struct{}) {
+		results := commands.Search(query, items, searchResultLimit)
+		gui.g.Update(func(g *gocui.Gui) error {
+			if state != gui.State.Panels.Search || state.Query != query {
+				return nil
+			}
+			state.Results = results
+			state.SelectedLine = 0
+			return gui.renderSearchResults()
+		})
+	})
+}
+
+func (gui *Gui) renderSearchResults() error {
+	state := gui.State.Panels.Search
+	mainView := gui.getMainView()
+	if err := mainView.SetOrigin(0, 0); err != nil {
+		return err
+	}
+
+	if state.Query == "" {
+		return gui.setViewContent(gui.g, mainView, gui.Tr.TypeToSearch)
+	}
+	if len(state.Results) == 0 {
+		return gui.setViewContent(gui.g, mainView, fmt.Sprintf(gui.Tr.NoSearchMatches, state.Query))
+	}
+
+	kinds := map[string]string{
+		commands.SearchKindContainer: gui.Tr.SearchContainer,
+		commands.SearchKindImage:     gui.Tr.SearchImage,
+		commands.SearchKindVolume:    gui.Tr.SearchVolume,
+		commands.SearchKindNetwork:   gui.Tr.SearchNetwork,
+	}
+	rows := make([]*searchResultRow, len(state.Results))
+	for i, result := range state.Results {
+		rows[i] = &searchResultRow{result: result, kind: kinds[result.Item.Kind], selected: i == state.SelectedLine}
+	}
+	list, err := utils.RenderList(rows)
+	if err != nil {
+		return err
+	}
+	if err := gui.setViewContent(gui.g, mainView, list); err != nil {
+		return err
+	}
+	return gui.focusPoint(0, state.SelectedLine, len(rows), mainView)
+}
+
+func (gui *Gui) handleSearchNextResult(g *gocui.Gui, v *gocui.View) error {
+	state := gui.State.Panels.Search
+	gui.changeSelectedLine(&state.SelectedLine, len(state.Results), false)
+	return gui.renderSearchResults()
+}
+
+func (gui *Gui) handleSearchPrevResult(g *gocui.Gui, v *gocui.View) error {
+	state := gui.State.Panels.Search
+	gui.changeSelectedLine(&state.SelectedLine, len(state.Results), true)
+	return gui.renderSearchResults()
+}
+
+// handleSearchClose forgets the search, so that a search still running in the
+// background doesn't render its results over whatever comes next
+func (gui *Gui) handleSearchClose(g *gocui.Gui, v *gocui.View) error {
+	gui.State.Panels.Search = &searchPanelState{}
+	return nil
+}
+
+func (gui *Gui) handleSearchJump(g *gocui.Gui, v *gocui.View) error {
+	state := gui.State.Panels.Search
+	if state.SelectedLine < 0 || state.SelectedLine >= len(state.Results) {
+		return nil
+	}
+	item := state.Results[state.SelectedLine].Item
+
+	if err := gui.handleSearchClose(g, v); err != nil {
+		return err
+	}
+	if err := gui.closeConfirmationPrompt(g); err != nil {
+		return err
+	}
+	return gui.jumpToSearchItem(item)
+}
+
+// jumpToSearchItem selects the item in its panel and focuses the panel. If
+// it's gone since we searched, we stay where we are.
+func (gui *Gui) jumpToSearchItem(item commands.SearchItem) error {
+	viewName := ""
+	switch item.Kind {
+	case commands.SearchKindContainer:
+		for _, container := range gui.DockerCommand.DisplayContainers {
+			if container.ID == item.ID {
+				// so that the container has a row to select
+				delete(gui.State.Panels.Containers.Collapsed, container.ProjectName)
+			}
+		}
+		for i, row := range gui.getContainerRows() {
+			if row.container != nil && row.container.ID == item.ID {
+				gui.State.Panels.Containers.SelectedLine = i
+				viewName = "containers"
+			}
+		}
+		if viewName != "" {
+			list, err := gui.renderContainersList(true)
+			if err != nil {
+				return err
+			}
+			if err := gui.setViewContent(gui.g, gui.getContainersView(), list); err != nil {
+				return err
+			}
+		}
+	case commands.SearchKindImage:
+		for i, image := range gui.DockerCommand.Images {
+			if image.ID == item.ID {
+				gui.State.Panels.Images.SelectedLine = i
+				viewName = "images"
+			}
+		}
+	case commands.SearchKindVolume:
+		for i, volume := range gui.DockerCommand.Volumes {
+			if volume.Name == item.ID {
+				gui.State.Panels.Volumes.SelectedLine = i
+				viewName = "volumes"
+			}
+		}
+	case commands.SearchKindNetwork:
+		for i, network := range gui.DockerCommand.Networks {
+			if network.ID == item.ID {
+				gui.State.Panels.Networks.SelectedLine = i
+				viewName = "networks"
+			}
+		}
+	}
+	if viewName == "" {
+		return nil
+	}
+
+	view, err := gui.g.View(viewName)
+	if err != nil {
+		return nil
+	}
+	currentView := gui.g.CurrentView()
+	if currentView == view {
+		return gui.newLineFocused(view)
+	}
+	return gui.switchFocus(gui.g, currentView, view, false)
+}
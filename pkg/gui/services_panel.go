@@ -20,7 +20,7 @@ func (gui *Gui) getServiceContexts() []string {
 
 func (gui *Gui) getServiceContextTitles() []string {
 	return []string{
-		gui.Tr.LogsTitle,
+		gui.getLogsTitle(),
 		gui.Tr.StatsTitle,
 		gui.Tr.ContainerEnvTitle,
 		gui.Tr.ContainerConfigTitle,
@@ -332,6 +332,7 @@ func (gui *Gui) handleServiceRestartMenu(g *gocui.Gui, v *gocui.View) error {
 			),
 			f: func() error {
 				return gui.WithWaitingStatus(gui.Tr.RestartingStatus, func() error {
+					defer gui.DockerCommand.InvalidateContainerCache()
 					if err := gui.OSCommand.RunCommand(recreateCommand); err != nil {
 						return gui.createErrorPanel(gui.g, err.Error())
 					}
@@ -367,6 +368,7 @@ func (gui *Gui) createServiceCommandMenu(options []*commandOption, status string
 			return nil
 		}
 		return gui.WithWaitingStatus(status, func() error {
+			defer gui.DockerCommand.InvalidateContainerCache()
 			if err := gui.OSCommand.RunCommand(options[index].command); err != nil {
 				return gui.createErrorPanel(gui.g, err.Error())
 			}
@@ -433,5 +435,5 @@ func (gui *Gui) handleServicesOpenInBrowserCommand(g *gocui.Gui, v *gocui.View)
 		return gui.createErrorPanel(gui.g, gui.Tr.NoContainers)
 	}
 
-	return gui.openContainerInBrowser(container)
+	return gui.createPublishedPortsMenu(v, container)
 }
@@ -0,0 +1,117 @@
+package gui
+
+import (
+	"fmt"
+
+	"github.com/jesseduffield/gocui"
+	"github.com/jesseduffield/lazydocker/pkg/commands"
+)
+
+func (gui *Gui) sortFieldName(field commands.SortField) string {
+	switch field {
+	case commands.SortByName:
+		return gui.Tr.SortName
+	case commands.SortByStatus:
+		return gui.Tr.SortStatus
+	case commands.SortByCreated:
+		return gui.Tr.SortCreated
+	case commands.SortBySize:
+		return gui.Tr.SortSize
+	case commands.SortByCPU:
+		return gui.Tr.SortCPU
+	case commands.SortByMemory:
+		return gui.Tr.SortMemory
+	}
+	return string(field)
+}
+
+// sortLabel says what a list is sorted by and which way, e.g. 'CPU ▼'
+func (gui *Gui) sortLabel(order commands.SortOrder) string {
+	arrow := "▲"
+	if order.Descending {
+		arrow = "▼"
+	}
+	return gui.sortFieldName(order.Field) + " " + arrow
+}
+
+// withSortLabel adds how a list is sorted to its panel's title, once the user
+// has sorted it
+func (gui *Gui) withSortLabel(title string, order commands.SortOrder) string {
+	if order.Field == "" {
+		return title
+	}
+	return fmt.Sprintf("%s (%s)", title, fmt.Sprintf(gui.Tr.SortedByValue, gui.sortLabel(order)))
+}
+
+func (gui *Gui) getImagesTitle() string {
+	return gui.withSortLabel(gui.Tr.ImagesTitle, gui.DockerCommand.ImageSort())
+}
+
+// createSortMenu lets the user pick what a list is sorted by. Picking what
+// it's already sorted by turns it the other way round.
+func (gui *Gui) createSortMenu(fields []commands.SortField, current commands.SortOrder, sortBy func(commands.SortOrder) error) error {
+	options := make([]*commandOption, 0, len(fields)+1)
+	for _, field := range fields {
+		field := field
+		option := &commandOption{
+			description: gui.sortFieldName(field),
+			f: func() error {
+				return sortBy(current.Select(field))
+			},
+		}
+		if field == current.Field {
+			option.command = gui.sortLabel(current)
+		}
+		options = append(options, option)
+	}
+	options = append(options, &commandOption{
+		description: gui.Tr.Cancel,
+		f:           func() error { return nil },
+	})
+
+	handleMenuPress := func(index int) error {
+		return options[index].f()
+	}
+
+	return gui.createMenu(gui.Tr.SortTitle, options, len(options), handleMenuPress)
+}
+
+func (gui *Gui) sortContainers(order commands.SortOrder) error {
+	gui.DockerCommand.SetContainerSort(order)
+	return gui.refreshContainersAndServices()
+}
+
+func (gui *Gui) sortImages(order commands.SortOrder) error {
+	gui.DockerCommand.SetImageSort(order)
+	return gui.refreshImages()
+}
+
+func (gui *Gui) handleContainersSortMenu(g *gocui.Gui, v *gocui.View) error {
+	return gui.createSortMenu(commands.ContainerSortFields, gui.DockerCommand.ContainerSort(), gui.sortContainers)
+}
+
+func (gui *Gui) handleContainersReverseSort(g *gocui.Gui, v *gocui.View) error {
+	order := gui.DockerCommand.ContainerSort()
+	if order.Field == "" {
+		// the usual order is by status, or newest first as the daemon lists
+		// them if we're sorting the legacy way
+		order = commands.SortOrder{Field: commands.SortByStatus}
+		if gui.Config.UserConfig.Gui.LegacySortContainers {
+			order = commands.SortOrder{Field: commands.SortByCreated, Descending: true}
+		}
+	}
+	return gui.sortContainers(order.Reversed())
+}
+
+func (gui *Gui) handleImagesSortMenu(g *gocui.Gui, v *gocui.View) error {
+	return gui.createSortMenu(commands.ImageSortFields, gui.DockerCommand.ImageSort(), gui.sortImages)
+}
+
+func (gui *Gui) handleImagesReverseSort(g *gocui.Gui, v *gocui.View) error {
+	order := gui.DockerCommand.ImageSort()
+	if order.Field == "" {
+		// the daemon lists the newest images first
+		order = commands.SortOrder{Field: commands.SortByCreated, Descending: true}
+	}
+	return gui.sortImages(order.Reversed())
+}
@@ -19,6 +19,9 @@ func (gui *Gui) RunWithSubprocesses() error {
 	for {
 		if err := gui.Run(); err != nil {
 			if err == gocui.ErrQuit {
+				if err := gui.saveAppState(); err != nil {
+					gui.Log.Error(err)
+				}
 				break
 			} else if err == gui.Errors.ErrSubProcess {
 				// preparing the state for when we return
@@ -2,6 +2,7 @@ package gui
 
 import (
 	"github.com/jesseduffield/gocui"
+	"github.com/jesseduffield/lazydocker/pkg/config"
 	"github.com/jesseduffield/lazydocker/pkg/utils"
 )
 
@@ -14,14 +15,34 @@ func (gui *Gui) GetColor(keys []string) gocui.Attribute {
 	return attribute
 }
 
+// theme is the user's theme, with what they've left out filled in from its
+// preset
+func (gui *Gui) theme() config.ThemeConfig {
+	return gui.Config.UserConfig.Gui.Theme.Resolved()
+}
+
 // GetOptionsPanelTextColor gets the color of the options panel text
 func (gui *Gui) GetOptionsPanelTextColor() (gocui.Attribute, error) {
-	return gui.GetColor(gui.Config.UserConfig.Gui.Theme.OptionsTextColor), nil
+	return gui.GetColor(gui.theme().OptionsTextColor), nil
 }
 
 // SetColorScheme sets the color scheme for the app based on the user config
 func (gui *Gui) SetColorScheme() error {
-	gui.g.FgColor = gui.GetColor(gui.Config.UserConfig.Gui.Theme.InactiveBorderColor)
-	gui.g.SelFgColor = gui.GetColor(gui.Config.UserConfig.Gui.Theme.ActiveBorderColor)
+	theme := gui.theme()
+	gui.g.FgColor = gui.GetColor(theme.InactiveBorderColor)
+	gui.g.SelFgColor = gui.GetColor(theme.ActiveBorderColor)
+	// not gui.g.SelBgColor, which would fill in the focused panel's frame too
+	gui.selectedLineBgColor = gui.GetColor(theme.SelectedLineBgColor)
 	return nil
 }
+
+// logLevelColors are the theme's colors for log lines at each level
+func (gui *Gui) logLevelColors() utils.LogLevelColors {
+	theme := gui.theme()
+	return utils.LogLevelColors{
+		Error:   theme.LogErrorColor,
+		Warning: theme.LogWarningColor,
+		Info:    theme.LogInfoColor,
+		Debug:   theme.LogDebugColor,
+	}
+}
@@ -94,6 +94,8 @@ func (gui *Gui) newLineFocused(v *gocui.View) error {
 		return gui.handleImageSelect(gui.g, v)
 	case "volumes":
 		return gui.handleVolumeSelect(gui.g, v)
+	case "networks":
+		return gui.handleNetworkSelect(gui.g, v)
 	case "confirmation":
 		return nil
 	case "main":
@@ -281,6 +283,11 @@ func (gui *Gui) getVolumesView() *gocui.View {
 	return v
 }
 
+func (gui *Gui) getNetworksView() *gocui.View {
+	v, _ := gui.g.View("networks")
+	return v
+}
+
 func (gui *Gui) getMainView() *gocui.View {
 	v, _ := gui.g.View("main")
 	return v
@@ -358,6 +365,24 @@ func (gui *Gui) clearMainView() {
 	mainView.SetCursor(0, 0)
 }
 
+// onWheel wraps what the mouse wheel does over a panel, so that it scrolls the
+// panel it's over rather than only the focused one. A side panel is focused
+// first, so that the main panel shows what's selected in it; the main panel
+// scrolls without being focused. Nothing happens behind a popup.
+func (gui *Gui) onWheel(handler func(*gocui.Gui, *gocui.View) error) func(*gocui.Gui, *gocui.View) error {
+	return func(g *gocui.Gui, v *gocui.View) error {
+		if gui.popupPanelFocused() && !gui.isPopupPanel(v.Name()) {
+			return nil
+		}
+		if v.Name() != "main" && g.CurrentView() != v {
+			if _, err := g.SetCurrentView(v.Name()); err != nil {
+				return err
+			}
+		}
+		return handler(g, v)
+	}
+}
+
 func (gui *Gui) handleClick(v *gocui.View, itemCount int, selectedLine *int, handleSelect func(*gocui.Gui, *gocui.View) error) error {
 	if gui.popupPanelFocused() && v != nil && !gui.isPopupPanel(v.Name()) {
 		return nil
@@ -367,6 +392,11 @@ func (gui *Gui) handleClick(v *gocui.View, itemCount int, selectedLine *int, han
 		return err
 	}
 
+	if itemCount == 0 {
+		// there's nothing to select, so this just focuses the panel
+		return handleSelect(gui.g, v)
+	}
+
 	_, cy := v.Cursor()
 	_, oy := v.Origin()
 
@@ -0,0 +1,132 @@
+package gui
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"path"
+
+	"github.com/jesseduffield/gocui"
+	"github.com/jesseduffield/lazydocker/pkg/commands"
+	"github.com/jesseduffield/lazydocker/pkg/utils"
+)
+
+// volumeFileLimit is as much of a file as we'll show from a volume
+const volumeFileLimit = 1024 * 1024
+
+// handleVolumeBrowse opens a menu of the files in the selected volume, in the
+// directory we were last in. The helper container behind it is removed when
+// the menu's closed or a file's picked.
+func (gui *Gui) handleVolumeBrowse(g *gocui.Gui, v *gocui.View) error {
+	volume, err := gui.getSelectedVolume()
+	if err != nil {
+		return nil
+	}
+
+	return gui.WithWaitingStatus(gui.Tr.BrowsingVolumeStatus, func() error {
+		browser, err := volume.Browse(context.Background())
+		if err != nil {
+			return err
+		}
+		dir := gui.State.Panels.Volumes.BrowseDirs[volume.Name]
+		if dir == "" {
+			dir = "/"
+		}
+		return gui.showVolumeDir(browser, dir)
+	})
+}
+
+// showVolumeDir lists dir and shows it in the menu. It's called from a waiting
+// status, so it does the listing before touching the ui.
+func (gui *Gui) showVolumeDir(browser *commands.VolumeBrowser, dir string) error {
+	entries, err := browser.List(context.Background(), dir)
+	if err != nil && dir != "/" {
+		// the directory may have gone since we were last in it
+		dir = "/"
+		entries, err = browser.List(context.Background(), dir)
+	}
+	if err != nil {
+		gui.closeVolumeBrowser(browser)
+		return err
+	}
+	volumeName := browser.Volume.Name
+	gui.State.Panels.Volumes.BrowseDirs[volumeName] = dir
+
+	if dir != "/" {
+		entries = append([]*commands.VolumeEntry{{Name: "..", IsDir: true}}, entries...)
+	}
+	if len(entries) == 0 {
+		gui.closeVolumeBrowser(browser)
+		gui.g.Update(func(g *gocui.Gui) error {
+			return gui.createErrorPanel(gui.g, gui.Tr.EmptyVolume)
+		})
+		return nil
+	}
+
+	handleMenuPress := func(index int) error {
+		entry := entries[index]
+		entryPath := path.Join(dir, entry.Name)
+		if entry.IsDir {
+			return gui.WithWaitingStatus(gui.Tr.BrowsingVolumeStatus, func() error {
+				return gui.showVolumeDir(browser, entryPath)
+			})
+		}
+
+		// there's nothing more to browse once we're looking at a file
+		return gui.WithWaitingStatus(gui.Tr.BrowsingVolumeStatus, func() error {
+			defer gui.closeVolumeBrowser(browser)
+			return gui.renderVolumeFile(browser, entryPath)
+		})
+	}
+
+	gui.g.Update(func(g *gocui.Gui) error {
+		title := fmt.Sprintf("%s:%s", volumeName, dir)
+		if err := gui.createMenu(title, entries, len(entries), handleMenuPress); err != nil {
+			return err
+		}
+		gui.State.Panels.Menu.OnClose = func() error {
+			gui.closeVolumeBrowser(browser)
+			return nil
+		}
+		return nil
+	})
+	return nil
+}
+
+func (gui *Gui) renderVolumeFile(browser *commands.VolumeBrowser, file string) error {
+	output := &bytes.Buffer{}
+	if err := browser.ReadFile(context.Background(), file, volumeFileLimit+1, output); err != nil {
+		return err
+	}
+
+	content := output.String()
+	switch {
+	case bytes.IndexByte(output.Bytes(), 0) != -1:
+		content = gui.Tr.BinaryFile
+	case output.Len() > volumeFileLimit:
+		content = content[:volumeFileLimit] + "\n\n" + fmt.Sprintf(gui.Tr.FileTruncated, utils.FormatBinaryBytes(volumeFileLimit))
+	}
+
+	// so that selecting the volume again puts its own tabs back
+	gui.State.Panels.Main.ObjectKey = "volume-file-" + browser.Volume.Name + "-" + file
+	gui.g.Update(func(g *gocui.Gui) error {
+		mainView := gui.getMainView()
+		mainView.Tabs = []string{browser.Volume.Name + ":" + file}
+		mainView.TabIndex = 0
+		mainView.Autoscroll = false
+		gui.clearMainView()
+		return nil
+	})
+	return gui.renderString(gui.g, "main", content)
+}
+
+// closeVolumeBrowser removes the browser's helper container. We don't make a
+// fuss if that fails, as it'll go away on its own in the end.
+func (gui *Gui) closeVolumeBrowser(browser *commands.VolumeBrowser) {
+	gui.State.Panels.Menu.OnClose = nil
+	go func() {
+		if err := browser.Close(); err != nil {
+			gui.Log.Error(err)
+		}
+	}()
+}
@@ -14,11 +14,11 @@ import (
 // list panel functions
 
 func (gui *Gui) getVolumeContexts() []string {
-	return []string{"config"}
+	return []string{"config", "inspect"}
 }
 
 func (gui *Gui) getVolumeContextTitles() []string {
-	return []string{gui.Tr.ConfigTitle}
+	return []string{gui.Tr.ConfigTitle, gui.Tr.InspectTitle}
 }
 
 func (gui *Gui) getSelectedVolume() (*commands.Volume, error) {
@@ -65,6 +65,10 @@ func (gui *Gui) handleVolumeSelect(g *gocui.Gui, v *gocui.View) error {
 		if err := gui.renderVolumeConfig(mainView, volume); err != nil {
 			return err
 		}
+	case "inspect":
+		if err := gui.renderInspect(volume.InspectRaw); err != nil {
+			return err
+		}
 	default:
 		return errors.New("Unknown context for Volumes panel")
 	}
@@ -115,6 +119,14 @@ func (gui *Gui) refreshVolumes() error {
 		return err
 	}
 
+	volumeNames := make([]string, len(gui.DockerCommand.Volumes))
+	for i, volume := range gui.DockerCommand.Volumes {
+		volumeNames[i] = volume.Name
+	}
+	if err := gui.restoreSelection(volumesView, volumeNames, &gui.State.Panels.Volumes.SelectedLine); err != nil {
+		return err
+	}
+
 	if len(gui.DockerCommand.Volumes) > 0 && gui.State.Panels.Volumes.SelectedLine == -1 {
 		gui.State.Panels.Volumes.SelectedLine = 0
 	}
@@ -241,15 +253,7 @@ func (gui *Gui) handleVolumesRemoveMenu(g *gocui.Gui, v *gocui.View) error {
 }
 
 func (gui *Gui) handlePruneVolumes() error {
-	return gui.createConfirmationPanel(gui.g, gui.getVolumesView(), gui.Tr.Confirm, gui.Tr.ConfirmPruneVolumes, func(g *gocui.Gui, v *gocui.View) error {
-		return gui.WithWaitingStatus(gui.Tr.PruningStatus, func() error {
-			err := gui.DockerCommand.PruneVolumes()
-			if err != nil {
-				return gui.createErrorPanel(gui.g, err.Error())
-			}
-			return nil
-		})
-	}, nil)
+	return gui.runPrune(gui.getVolumesView(), gui.Tr.ConfirmPruneVolumes, gui.DockerCommand.PruneVolumes, nil)
 }
 
 func (gui *Gui) handleVolumesCustomCommand(g *gocui.Gui, v *gocui.View) error {
@@ -280,3 +284,39 @@ func (gui *Gui) handleVolumesBulkCommand(g *gocui.Gui, v *gocui.View) error {
 
 	return gui.createBulkCommandMenu(bulkCommands, commandObject)
 }
+
+// handleVolumeCreate asks for the new volume's name, driver and labels, as
+// docker volume create takes them, then selects the volume once it's made
+func (gui *Gui) handleVolumeCreate(g *gocui.Gui, v *gocui.View) error {
+	return gui.createPromptPanel(g, v, gui.Tr.CreateVolumeTitle, func(g *gocui.Gui, promptView *gocui.View) error {
+		input := gui.trimmedContent(promptView)
+		if input == "" {
+			return nil
+		}
+		options, err := commands.ParseVolumeCreate(input)
+		if err != nil {
+			return gui.createErrorPanel(gui.g, err.Error())
+		}
+
+		return gui.WithWaitingStatus(gui.Tr.CreatingStatus, func() error {
+			name, err := gui.DockerCommand.CreateVolume(options)
+			if err != nil {
+				return gui.createErrorPanel(gui.g, err.Error())
+			}
+			if err := gui.refreshVolumes(); err != nil {
+				return err
+			}
+			// after the refresh has rendered the list
+			gui.g.Update(func(g *gocui.Gui) error {
+				for i, volume := range gui.DockerCommand.Volumes {
+					if volume.Name == name {
+						gui.State.Panels.Volumes.SelectedLine = i
+						return gui.handleVolumeSelect(g, gui.getVolumesView())
+					}
+				}
+				return nil
+			})
+			return nil
+		})
+	})
+}
@@ -21,6 +21,10 @@ type TranslationSet struct {
 	ConnectionFailed                           string
 	UnattachableContainerError                 string
 	CannotAttachStoppedContainerError          string
+	CannotExecStoppedContainerError            string
+	NoShellInContainerError                    string
+	NoComposeWorkingDirError                   string
+	ComposeWorkingDirMissingError              string
 	CannotAccessDockerSocketError              string
 	CannotKillChildError                       string
 
@@ -39,6 +43,8 @@ type TranslationSet struct {
 	StopContainer              string
 	RestartingStatus           string
 	StoppingStatus             string
+	StartingStatus             string
+	BrowsingVolumeStatus       string
 	RemovingStatus             string
 	RunningCustomCommandStatus string
 	RunningBulkCommandStatus   string
@@ -54,7 +60,28 @@ type TranslationSet struct {
 	ServicesTitle              string
 	ContainersTitle            string
 	StandaloneContainersTitle  string
+	FilterContainersTitle      string
+	FilterContainers           string
+	FilterEventsTitle          string
+	FilterEvents               string
+	DiskUsageMenu              string
+	RefreshDiskUsage           string
+	PruneBuildCache            string
+	ConfirmPruneBuildCache     string
+	DiskUsageShowSummary       string
+	DiskUsageShow              string
+	CalculatingDiskUsage       string
+	DiskUsageSummary           string
+	DiskUsageType              string
+	DiskUsageTotal             string
+	DiskUsageActive            string
+	DiskUsageSize              string
+	DiskUsageReclaimable       string
+	InUse                      string
+	NoEvents                   string
+	EventStreamDown            string
 	TopTitle                   string
+	InspectTitle               string
 	ImagesTitle                string
 	VolumesTitle               string
 	NoContainers               string
@@ -65,31 +92,214 @@ type TranslationSet struct {
 	RemoveVolume               string
 	RemoveWithoutPrune         string
 	PruneImages                string
+	PruneDanglingImages        string
+	PullImage                  string
+	PullImageTitle             string
+	PullingImageTitle          string
+	PullStartingStatus         string
+	BuildImage                 string
+	BuildImageDirTitle         string
+	BuildImageTagTitle         string
+	BuildingImageTitle         string
+	BuildStartingStatus        string
 	PruneContainers            string
 	PruneVolumes               string
+	PruneAllImages             string
+	PruneNetworks              string
 	ConfirmPruneContainers     string
 	ConfirmStopContainers      string
 	ConfirmRemoveContainers    string
+	ConfirmRemoveMarked        string
 	ConfirmPruneImages         string
+	ConfirmPruneDanglingImages string
 	ConfirmPruneVolumes        string
+	ConfirmPruneAllImages      string
+	ConfirmPruneNetworks       string
 	PruningStatus              string
 	StopService                string
 	PressEnterToReturn         string
 	StopAllContainers          string
 	RemoveAllContainers        string
+	StopMarked                 string
+	RestartMarked              string
+	RemoveMarked               string
+	ToggleMarked               string
+	BulkResultsTitle           string
+	PrunedTitle                string
+	PruneReport                string
 	ViewRestartOptions         string
+	ViewHistory                string
+	PushImage                  string
+	PushImageTitle             string
+	PushingImageTitle          string
+	PushStartingStatus         string
+	CannotPushUntagged         string
+	PushLoginHint              string
+	RegistryUsernameTitle      string
+	RegistryPasswordTitle      string
+	TagImage                   string
+	UntagImage                 string
+	TagImageTitle              string
+	UntagImageTitle            string
+	TaggingStatus              string
+	UntaggingStatus            string
+	AlreadyTagged              string
+	ConfirmMoveTag             string
+	CreateVolume               string
+	CreateNetwork              string
+	CreateVolumeTitle          string
+	CreateNetworkTitle         string
+	CreatingStatus             string
+	QuickRemove                string
+	ConfirmQuickRemove         string
+	RemovedValue               string
+	SortList                   string
+	ReverseSort                string
+	SortTitle                  string
+	SortedByValue              string
+	SortName                   string
+	SortStatus                 string
+	SortCreated                string
+	SortSize                   string
+	SortCPU                    string
+	SortMemory                 string
+	RenameContainer            string
+	RenameContainerTitle       string
+	RenamingStatus             string
+	LabelsTitle                string
+	NoLabels                   string
+	ContainerLabelsReadOnly    string
+	ImageLabelsReadOnly        string
+	CopyLabelTitle             string
+	CopyAllLabels              string
+	Reconnect                  string
+	ReconnectingTitle          string
+	ConnectionUp               string
+	ConnectionReconnecting     string
+	ConnectionLost             string
+	ReadOnlyMode               string
+	PrevMatch                  string
+	ToggleSearchCase           string
+	ToggleSearchRegex          string
+	SearchMatchingCase         string
+	SearchIgnoringCase         string
+	SearchForRegex             string
+	SearchForText              string
+	StopWithTimeout            string
+	Kill                       string
+	KillContainer              string
+	RestartCount               string
+	RestartedOnce              string
+	KillingStatus              string
+	StopTimeoutTitle           string
+	CannotRemoveOnlyTag        string
 	ExecShell                  string
 	RunCustomCommand           string
 	ViewBulkCommands           string
 	OpenInBrowser              string
+	OpenPortInBrowser          string
 	SortContainersByState      string
+	BrowseVolume               string
+	EmptyVolume                string
+	BinaryFile                 string
+	FileTruncated              string
+	Inspect                    string
+	SearchMain                 string
+	NextMatch                  string
+	CopyMain                   string
+	RevealSecrets              string
+	CopyEnvVarTitle            string
+	SearchTitle                string
+	NoSearchMatches            string
+	CopiedToClipboard          string
+	CopiedToClipboardValue     string
+	CopyID                     string
+	CopyShortID                string
+	CopyVolumeName             string
+	ToggleComposeProject       string
+	ComposeProjectMenu         string
+	ComposeProjectMenuTitle    string
+	ComposeUp                  string
+	ComposeDown                string
+	ComposeProjectName         string
+	ComposeWorkingDir          string
+	ComposeStatus              string
+	GlobalSearch               string
+	GlobalSearchTitle          string
+	TypeToSearch               string
+	CommandPalette             string
+	CommandPaletteTitle        string
+	CommandsTitle              string
+	NoCommandMatches           string
+	SearchContainer            string
+	SearchImage                string
+	SearchVolume               string
+	SearchNetwork              string
+	SwitchDockerContext        string
+	DockerContextsTitle        string
+	DefaultContextDescription  string
+	ConnectingToContextTitle   string
+	ConnectingToContext        string
+	TunnelDialAttempt          string
+	SSHPasswordTitle           string
+	StillConnectedTo           string
+	PublishedPortsTitle        string
+	NoPublishedPorts           string
+	NoHTTPPorts                string
+	OpenPort                   string
+	ForwardPort                string
+	ForwardAndOpenPort         string
+	ForwardingStatus           string
+	UpdatingStatus             string
+	SetRestartPolicy           string
+	RestartPolicyMenuTitle     string
+	PortForwardedTitle         string
+	PortForwarded              string
+	ToggleMergedLogs           string
+	MergedLogsMenuTitle        string
+	MergedLogsShown            string
+	MergedLogsHidden           string
+	NoMergedLogs               string
+
+	NetworksTitle                string
+	NoNetworks                   string
+	ConnectContainer             string
+	DisconnectContainer          string
+	ConnectContainerTitle        string
+	DisconnectContainerTitle     string
+	NetworkAliasesTitle          string
+	NoContainersToConnect        string
+	NoContainersToDisconnect     string
+	ConfirmDisconnectOnlyNetwork string
+	ConnectingStatus             string
+	DisconnectingStatus          string
+
+	CopyFiles                     string
+	CopyFromContainer             string
+	CopyToContainer               string
+	CopyContainerSourceTitle      string
+	CopyContainerDestinationTitle string
+	CopyLocalSourceTitle          string
+	CopyLocalDestinationTitle     string
+	CopyingTitle                  string
+	CopyStartingStatus            string
+	CopiedValue                   string
+
+	MergedLogsGoToContainer     string
+	MergedLogsContainerNotShown string
 
 	LogsTitle                 string
+	LogsSinceAll              string
+	CycleLogsSince            string
 	ConfigTitle               string
 	EnvTitle                  string
 	DockerComposeConfigTitle  string
 	StatsTitle                string
 	CreditsTitle              string
+	EventsTitle               string
+	HistoryTitle              string
+	MergedLogsTitle           string
+	DiskUsageTitle            string
 	ContainerConfigTitle      string
 	ContainerEnvTitle         string
 	NothingToDisplay          string
@@ -105,6 +315,10 @@ func englishSet() TranslationSet {
 		RemovingStatus:             "removing",
 		RestartingStatus:           "restarting",
 		StoppingStatus:             "stopping",
+		StartingStatus:             "starting",
+		ForwardingStatus:           "forwarding",
+		UpdatingStatus:             "updating",
+		BrowsingVolumeStatus:       "browsing volume",
 		RunningCustomCommandStatus: "running custom command",
 		RunningBulkCommandStatus:   "running bulk command",
 
@@ -114,6 +328,10 @@ func englishSet() TranslationSet {
 		ErrorOccurred:                     "An error occurred! Please create an issue at https://github.com/jesseduffield/lazydocker/issues",
 		ConnectionFailed:                  "connection to docker client failed. You may need to restart the docker client",
 		UnattachableContainerError:        "Container does not support attaching. You must either run the service with the '-it' flag or use `stdin_open: true, tty: true` in the docker-compose.yml file",
+		CannotExecStoppedContainerError:   "You cannot open a shell in a stopped container, you need to start it first",
+		NoShellInContainerError:           "Could not find a shell in this container (tried %s). Images built from scratch or distroless images often don't have one",
+		NoComposeWorkingDirError:          "The containers of compose project %s don't say what directory it was brought up from, so there's nowhere to run docker compose",
+		ComposeWorkingDirMissingError:     "The directory compose project %s was brought up from no longer exists: %s. Its containers are still around, but docker compose needs the project's files for this",
 		CannotAttachStoppedContainerError: "You cannot attach to a stopped container, you need to start it first (which you can actually do with the 'r' key) (yes I'm too lazy to do this automatically for you) (pretty cool that I get to communicate one-on-one with you in the form of an error message though)",
 		CannotAccessDockerSocketError:     "Can't access docker socket at: unix:///var/run/docker.sock\nRun lazydocker as root or read https://docs.docker.com/install/linux/linux-postinstall/",
 		CannotKillChildError:              "Waited three seconds for child process to stop. There may be an orphan process that continues to run on your system.",
@@ -150,14 +368,129 @@ func englishSet() TranslationSet {
 		PruneContainers:       "prune exited containers",
 		PruneVolumes:          "prune unused volumes",
 		PruneImages:           "prune unused images",
+		PruneDanglingImages:   "prune dangling images",
+		PruneAllImages:        "prune all unused images",
+		PruneNetworks:         "prune unused networks",
+		PullImage:             "pull image",
+		BrowseVolume:          "browse files",
+		Inspect:               "inspect",
+		SearchMain:            "search",
+		NextMatch:             "next match",
+		CopyMain:              "copy inspect JSON, env variable or label",
+		RevealSecrets:         "reveal/mask secret env values",
+		ToggleMergedLogs:      "show/hide containers in merged logs",
+		ToggleComposeProject:  "collapse/expand compose project",
+		ComposeProjectMenu:    "compose project: up/down/restart",
+		ComposeUp:             "up",
+		ComposeDown:           "down",
 		StopAllContainers:     "stop all containers",
 		RemoveAllContainers:   "remove all containers (forced)",
+		StopMarked:            "stop marked containers (%d)",
+		RestartMarked:         "restart marked containers (%d)",
+		RemoveMarked:          "remove marked containers (%d, forced)",
+		ToggleMarked:          "mark/unmark for bulk commands",
 		ViewRestartOptions:    "view restart options",
+		ViewHistory:           "view layer history",
+		PushImage:             "push image",
+		PushImageTitle:        "Push which tag?",
+		PushingImageTitle:     "Pushing %s (esc to cancel)",
+		PushStartingStatus:    "Starting push...",
+		CannotPushUntagged:    "This image has no tags to push it as. Press t to tag it first",
+		PushLoginHint:         "Press enter to log in to %s and try again",
+		RegistryUsernameTitle: "Username for %s",
+		RegistryPasswordTitle: "Password for %s on %s",
+		TagImage:              "add a tag",
+		UntagImage:            "remove a tag",
+		TagImageTitle:         "New tag (e.g. myapp:v2)",
+		UntagImageTitle:       "Remove tag",
+		TaggingStatus:         "tagging",
+		UntaggingStatus:       "untagging",
+		AlreadyTagged:         "The image is already tagged %s",
+		ConfirmMoveTag:        "%s is already on image %s. Move it to this one?",
+		CreateVolume:          "create a volume",
+		CreateNetwork:         "create a network",
+		CreateVolumeTitle:     "New volume (e.g. data --driver local --label env=dev)",
+		CreateNetworkTitle:    "New network (e.g. backend --subnet 172.28.0.0/16 --gateway 172.28.0.1)",
+		CreatingStatus:        "creating",
+		QuickRemove:           "remove, skipping the menu",
+		ConfirmQuickRemove:    "Remove %s? Set confirmDestructive to false in your config to remove without asking.",
+		RemovedValue:          "Removed %s",
+		SortList:              "sort",
+		ReverseSort:           "reverse sort order",
+		SortTitle:             "Sort by",
+		SortedByValue:         "by %s",
+		SortName:              "name",
+		SortStatus:            "status",
+		SortCreated:           "created",
+		SortSize:              "size",
+		SortCPU:               "CPU",
+		SortMemory:            "memory",
+		RenameContainer:       "rename",
+		RenameContainerTitle:  "Rename %s",
+		RenamingStatus:        "renaming",
+		ReadOnlyMode:          "read-only mode",
+		PrevMatch:             "previous match",
+		ToggleSearchCase:      "toggle matching case when searching",
+		ToggleSearchRegex:     "toggle searching for a regex",
+		SearchMatchingCase:    "searches match case",
+		SearchIgnoringCase:    "searches ignore case",
+		SearchForRegex:        "searching for a regex",
+		SearchForText:         "searching for text",
+		StopWithTimeout:       "stop, choosing how long to wait before it's killed",
+		Kill:                  "kill",
+		KillContainer:         "Are you sure you want to kill this container? It won't get the chance to shut down cleanly.",
+		RestartCount:          "%d restarts",
+		RestartedOnce:         "1 restart",
+		KillingStatus:         "killing",
+		StopTimeoutTitle:      "Seconds to wait before killing it",
+		CannotRemoveOnlyTag:   "This image only has the one tag, and removing it would remove the image. Press d to remove the image instead",
+		SetRestartPolicy:      "set restart policy to %s",
 		ExecShell:             "exec shell",
 		RunCustomCommand:      "run predefined custom command",
 		ViewBulkCommands:      "view bulk commands",
 		OpenInBrowser:         "open in browser (first port is http)",
+		OpenPortInBrowser:     "open a published port in the browser",
 		SortContainersByState: "sort containers by state",
+		GlobalSearch:          "search containers, images, volumes and networks",
+		SwitchDockerContext:   "switch docker context",
+
+		ConnectContainer:             "connect a container",
+		DisconnectContainer:          "disconnect a container",
+		ConnectContainerTitle:        "Connect which container to %s?",
+		DisconnectContainerTitle:     "Disconnect which container from %s?",
+		NetworkAliasesTitle:          "Aliases for %s on %s (optional, space separated)",
+		NoContainersToConnect:        "Every container is already on this network",
+		NoContainersToDisconnect:     "No containers are on this network",
+		ConfirmDisconnectOnlyNetwork: "%s isn't on any other network. Disconnecting it from %s leaves it unable to reach other containers or the outside world, and its published ports stop working, until you connect it to a network again. Disconnect it anyway?",
+		ConnectingStatus:             "connecting",
+		DisconnectingStatus:          "disconnecting",
+
+		CopyFiles:                     "copy files to/from the container",
+		CopyFromContainer:             "copy from the container to this machine",
+		CopyToContainer:               "copy from this machine into the container",
+		CopyContainerSourceTitle:      "Path to copy in %s",
+		CopyContainerDestinationTitle: "Path to copy to in %s",
+		CopyLocalSourceTitle:          "Path to copy on this machine",
+		CopyLocalDestinationTitle:     "Path to copy to on this machine (empty for the current directory)",
+		CopyingTitle:                  "Copying %s to %s (esc to cancel)",
+		CopyStartingStatus:            "Starting copy...",
+		CopiedValue:                   "Copied %s from %s to %s",
+
+		LabelsTitle:             "Labels",
+		NoLabels:                "No labels",
+		ContainerLabelsReadOnly: "Docker can't change a container's labels once it's been created. To change them, recreate the container, e.g. by editing its compose file and running up again.",
+		ImageLabelsReadOnly:     "An image's labels are set when it's built, by the LABEL instructions in its Dockerfile.",
+		CopyLabelTitle:          "Copy label",
+		CopyAllLabels:           "all labels",
+
+		Reconnect:              "reconnect to the docker daemon",
+		ReconnectingTitle:      "Reconnecting to %s (esc to cancel)",
+		ConnectionUp:           "connected to",
+		ConnectionReconnecting: "reconnecting to",
+		ConnectionLost:         "lost connection to",
+
+		MergedLogsGoToContainer:     "go to the container this line is from",
+		MergedLogsContainerNotShown: "that container isn't in the containers panel",
 
 		GlobalTitle:               "Global",
 		MainTitle:                 "Main",
@@ -165,18 +498,102 @@ func englishSet() TranslationSet {
 		ServicesTitle:             "Services",
 		ContainersTitle:           "Containers",
 		StandaloneContainersTitle: "Standalone Containers",
+		FilterContainersTitle:     "Filter (e.g. status=running name=web label=app=foo)",
+		FilterContainers:          "filter containers",
+		FilterEventsTitle:         "Filter (e.g. type=container event=die web)",
+		FilterEvents:              "filter events",
+		DiskUsageMenu:             "disk usage: look closer/prune",
+		RefreshDiskUsage:          "refresh disk usage",
+		PruneBuildCache:           "prune unused build cache",
+		ConfirmPruneBuildCache:    "Are you sure you want to prune all build cache that isn't in use?",
+		DiskUsageShowSummary:      "show summary",
+		DiskUsageShow:             "show %s",
+		CalculatingDiskUsage:      "Working out disk usage...",
+		DiskUsageSummary:          "%s: %s, of which %s is reclaimable",
+		DiskUsageType:             "TYPE",
+		DiskUsageTotal:            "TOTAL",
+		DiskUsageActive:           "ACTIVE",
+		DiskUsageSize:             "SIZE",
+		DiskUsageReclaimable:      "RECLAIMABLE",
+		InUse:                     "in use",
+		NoEvents:                  "No events yet",
+		EventStreamDown:           "Lost the docker event stream, reconnecting: %v",
 		ImagesTitle:               "Images",
+		PullImageTitle:            "Image to pull (e.g. alpine:latest)",
+		PullingImageTitle:         "Pulling %s (esc to cancel)",
+		PullStartingStatus:        "Starting pull...",
+		BuildImage:                "build an image from a Dockerfile",
+		BuildImageDirTitle:        "Directory or Dockerfile to build (leave blank for %s)",
+		BuildImageTagTitle:        "Tag for the image (leave blank for %s)",
+		BuildingImageTitle:        "Building %s (esc to cancel)",
+		BuildStartingStatus:       "Sending the build context...",
 		VolumesTitle:              "Volumes",
+		NetworksTitle:             "Networks",
+		InspectTitle:              "Inspect",
+		SearchTitle:               "Search",
+		NoSearchMatches:           "No matches for %q",
+		CopiedToClipboard:         "Copied to clipboard",
+		CopiedToClipboardValue:    "Copied %s to clipboard",
+		CopyID:                    "copy ID to clipboard",
+		CopyShortID:               "copy short ID to clipboard",
+		CopyVolumeName:            "copy name to clipboard",
+		CopyEnvVarTitle:           "Copy env variable",
+		EmptyVolume:               "This volume is empty",
+		BinaryFile:                "This looks like a binary file, so it's not shown",
+		FileTruncated:             "(only the first %s of this file is shown)",
+		ComposeProjectMenuTitle:   "Compose project %s",
+		ComposeProjectName:        "Project",
+		ComposeWorkingDir:         "Working directory",
+		ComposeStatus:             "Status",
+		GlobalSearchTitle:         "Search (up/down to pick, enter to jump)",
+		TypeToSearch:              "Type to search containers, images, volumes and networks by name, ID or label",
+		CommandPalette:            "search for a command to run",
+		CommandPaletteTitle:       "Command (up/down to pick, enter to run)",
+		CommandsTitle:             "Commands",
+		NoCommandMatches:          "No commands match %q",
+		SearchContainer:           "container",
+		SearchImage:               "image",
+		SearchVolume:              "volume",
+		SearchNetwork:             "network",
+		DockerContextsTitle:       "Docker contexts",
+		DefaultContextDescription: "DOCKER_HOST, or the local docker socket",
+		ConnectingToContextTitle:  "Connecting to %s (esc to cancel)",
+		ConnectingToContext:       "Connecting to %s...",
+		TunnelDialAttempt:         "Waiting for the ssh tunnel (attempt %d, %s left)",
+		SSHPasswordTitle:          "%s@%s's password",
+		StillConnectedTo:          "Still connected to %s",
+		PublishedPortsTitle:       "Published ports",
+		RestartPolicyMenuTitle:    "Restart policy: %s",
+		NoPublishedPorts:          "This container has no published ports",
+		NoHTTPPorts:               "None of this container's published ports look like they serve http: %s",
+		OpenPort:                  "open %s",
+		ForwardPort:               "forward %s through the ssh tunnel",
+		ForwardAndOpenPort:        "forward %s through the ssh tunnel and open it",
+		PortForwardedTitle:        "Port forwarded",
+		PortForwarded:             "%s on the docker host is now reachable at %s",
+		MergedLogsMenuTitle:       "Show logs from",
+		MergedLogsShown:           "shown",
+		MergedLogsHidden:          "hidden",
+		NoMergedLogs:              "Mark containers with space to see their logs together here, or select a container from a compose project to see the whole project's",
 		CustomCommandTitle:        "Custom Command:",
 		BulkCommandTitle:          "Bulk Command:",
+		BulkResultsTitle:          "Results",
+		PrunedTitle:               "Pruned",
+		PruneReport:               "Deleted %d, reclaimed %s",
 		ErrorTitle:                "Error",
 		LogsTitle:                 "Logs",
+		LogsSinceAll:              "all",
+		CycleLogsSince:            "cycle how far back logs go (5m/1h/all)",
 		ConfigTitle:               "Config",
 		EnvTitle:                  "Env",
 		DockerComposeConfigTitle:  "Docker-Compose Config",
 		TopTitle:                  "Top",
 		StatsTitle:                "Stats",
 		CreditsTitle:              "About",
+		EventsTitle:               "Events",
+		HistoryTitle:              "History",
+		MergedLogsTitle:           "Merged logs",
+		DiskUsageTitle:            "Disk usage",
 		ContainerConfigTitle:      "Container Config",
 		ContainerEnvTitle:         "Container Env",
 		NothingToDisplay:          "Nothing to display",
@@ -186,14 +603,19 @@ func englishSet() TranslationSet {
 		NoContainer:  "No container",
 		NoImages:     "No images",
 		NoVolumes:    "No volumes",
+		NoNetworks:   "No networks",
 
 		ConfirmQuit:                "Are you sure you want to quit?",
 		MustForceToRemoveContainer: "You cannot remove a running container unless you force it. Do you want to force it?",
 		NotEnoughSpace:             "Not enough space to render panels",
 		ConfirmPruneImages:         "Are you sure you want to prune all unused images?",
+		ConfirmPruneDanglingImages: "Are you sure you want to prune all dangling images?",
+		ConfirmPruneAllImages:      "Are you sure you want to prune all images that no container is using, not just dangling ones?",
+		ConfirmPruneNetworks:       "Are you sure you want to prune all unused networks?",
 		ConfirmPruneContainers:     "Are you sure you want to prune all stopped containers?",
 		ConfirmStopContainers:      "Are you sure you want to stop all containers?",
 		ConfirmRemoveContainers:    "Are you sure you want to remove all containers?",
+		ConfirmRemoveMarked:        "Are you sure you want to remove the %d marked containers?",
 		ConfirmPruneVolumes:        "Are you sure you want to prune all unused volumes?",
 		StopService:                "Are you sure you want to stop this service's containers?",
 		StopContainer:              "Are you sure you want to stop this container?",
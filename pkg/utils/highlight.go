@@ -0,0 +1,105 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+)
+
+// escapeRegex finds the color escape sequences in a line
+var escapeRegex = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+const (
+	highlightOn = "\x1b[7m"
+	resetColor  = "\x1b[0m"
+)
+
+// SearchRegex returns what to search for term with: the term itself if it's a
+// regex, and otherwise the text as it is, ignoring case unless caseSensitive
+func SearchRegex(term string, isRegex bool, caseSensitive bool) (*regexp.Regexp, error) {
+	pattern := term
+	if !isRegex {
+		pattern = regexp.QuoteMeta(term)
+	}
+	if !caseSensitive {
+		pattern = "(?i)" + pattern
+	}
+	return regexp.Compile(pattern)
+}
+
+// HighlightMatches shows what re matches in a line in reverse video. It looks
+// past the line's colors to find matches, and keeps them either side of each
+// match.
+func HighlightMatches(line string, re *regexp.Regexp) string {
+	if re == nil {
+		return line
+	}
+
+	// the line without its colors, and for each byte of that, where it is in
+	// the line
+	plain := strings.Builder{}
+	offsets := make([]int, 0, len(line))
+	escapes := escapeRegex.FindAllStringIndex(line, -1)
+	position := 0
+	for _, escape := range append(escapes, []int{len(line), len(line)}) {
+		for ; position < escape[0]; position++ {
+			plain.WriteByte(line[position])
+			offsets = append(offsets, position)
+		}
+		position = escape[1]
+	}
+
+	starts := map[int]bool{}
+	ends := map[int]bool{}
+	for _, match := range re.FindAllStringIndex(plain.String(), -1) {
+		if match[0] == match[1] {
+			continue
+		}
+		starts[offsets[match[0]]] = true
+		// straight after the match's last byte, before any colors that follow
+		ends[offsets[match[1]-1]+1] = true
+	}
+	if len(starts) == 0 {
+		return line
+	}
+
+	output := strings.Builder{}
+	// active are the colors in effect since the last reset, for putting back
+	// after a match
+	active := ""
+	inMatch := false
+	escapeIndex := 0
+	for i := 0; i <= len(line); {
+		if ends[i] && inMatch {
+			output.WriteString(resetColor + active)
+			inMatch = false
+		}
+		if starts[i] && !inMatch {
+			output.WriteString(highlightOn)
+			inMatch = true
+		}
+		if i == len(line) {
+			break
+		}
+
+		if escapeIndex < len(escapes) && escapes[escapeIndex][0] == i {
+			escape := line[i:escapes[escapeIndex][1]]
+			output.WriteString(escape)
+			if escape == resetColor || escape == "\x1b[m" {
+				active = ""
+			} else {
+				active += escape
+			}
+			if inMatch {
+				output.WriteString(highlightOn)
+			}
+			i = escapes[escapeIndex][1]
+			escapeIndex++
+			continue
+		}
+
+		output.WriteByte(line[i])
+		i++
+	}
+
+	return output.String()
+}
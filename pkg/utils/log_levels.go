@@ -0,0 +1,90 @@
+package utils
+
+import (
+	"bytes"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// LogLevelColors are the colors for log lines at each level, as theme color
+// names. Lines at a level without colors are left alone.
+type LogLevelColors struct {
+	Error   []string
+	Warning []string
+	Info    []string
+	Debug   []string
+}
+
+// logLevelRegex finds the log level in a line, whether it's 'ERROR ...',
+// '[warn] ...' or 'level=info ...'
+var logLevelRegex = regexp.MustCompile(`(?i)\b(?:(error|err|fatal|panic|crit|critical)|(warn|warning)|(info|notice)|(debug|trace))\b`)
+
+// ColorLogLine colors a log line by the first log level it mentions. Lines
+// that are already colored are left as they are.
+func ColorLogLine(line string, colors LogLevelColors) string {
+	if strings.Contains(line, "\x1b[") {
+		return line
+	}
+
+	match := logLevelRegex.FindStringSubmatchIndex(line)
+	if match == nil {
+		return line
+	}
+	for i, levelColors := range [][]string{colors.Error, colors.Warning, colors.Info, colors.Debug} {
+		if match[2+i*2] >= 0 {
+			return ThemedString(line, levelColors)
+		}
+	}
+	return line
+}
+
+// LogLevelWriter colors each line written to it by its log level before
+// passing it on. It holds on to a line until it's seen the end of it, so
+// call Flush when there's no more to write.
+type LogLevelWriter struct {
+	writer  io.Writer
+	colors  LogLevelColors
+	partial []byte
+}
+
+// NewLogLevelWriter returns a LogLevelWriter writing to w
+func NewLogLevelWriter(w io.Writer, colors LogLevelColors) *LogLevelWriter {
+	return &LogLevelWriter{writer: w, colors: colors}
+}
+
+func (w *LogLevelWriter) Write(p []byte) (int, error) {
+	w.partial = append(w.partial, p...)
+
+	end := bytes.LastIndexByte(w.partial, '\n')
+	if end < 0 {
+		return len(p), nil
+	}
+
+	lines := strings.SplitAfter(string(w.partial[:end+1]), "\n")
+	colored := strings.Builder{}
+	for _, line := range lines {
+		if line == "" {
+			continue
+		}
+		colored.WriteString(ColorLogLine(strings.TrimSuffix(line, "\n"), w.colors))
+		colored.WriteString("\n")
+	}
+	w.partial = append([]byte{}, w.partial[end+1:]...)
+
+	if _, err := io.WriteString(w.writer, colored.String()); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Flush writes out the last line, if it didn't end with a newline
+func (w *LogLevelWriter) Flush() error {
+	if len(w.partial) == 0 {
+		return nil
+	}
+	line := ColorLogLine(string(w.partial), w.colors)
+	w.partial = nil
+	_, err := io.WriteString(w.writer, line)
+	return err
+}
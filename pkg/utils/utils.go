@@ -310,6 +310,7 @@ func GetColorAttribute(key string) color.Attribute {
 		"white":     color.FgWhite,
 		"bold":      color.Bold,
 		"underline": color.Underline,
+		"reverse":   color.ReverseVideo,
 	}
 	value, present := colorMap[key]
 	if present {
@@ -318,6 +319,27 @@ func GetColorAttribute(key string) color.Attribute {
 	return color.FgWhite
 }
 
+// IsColorName tells us whether key is one of the colors or attributes that
+// GetGocuiAttribute and GetColorAttribute know, e.g. 'green' or 'bold'
+func IsColorName(key string) bool {
+	return GetGocuiAttribute(key) != gocui.ColorWhite || key == "white"
+}
+
+// ThemedString colors a string with the colors and attributes from a theme,
+// e.g. ['red', 'bold']. 'default' leaves the terminal's color alone.
+func ThemedString(str string, keys []string) string {
+	attributes := []color.Attribute{}
+	for _, key := range keys {
+		if key != "default" {
+			attributes = append(attributes, GetColorAttribute(key))
+		}
+	}
+	if len(attributes) == 0 {
+		return str
+	}
+	return MultiColoredString(str, attributes...)
+}
+
 // WithShortSha returns a command but with a shorter SHA. in the terminal we're all used to 10 character SHAs but under the hood they're actually 64 characters long. No need including all the characters when we're just displaying a command
 func WithShortSha(str string) string {
 	split := strings.Split(str, " ")
@@ -1,8 +1,11 @@
 package utils
 
 import (
+	"bytes"
+	"regexp"
 	"testing"
 
+	"github.com/fatih/color"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -380,3 +383,116 @@ func TestGetPadWidths(t *testing.T) {
 		assert.EqualValues(t, s.expected, getPadWidths(s.stringArrays))
 	}
 }
+
+// TestColorLogLine is a function.
+func TestColorLogLine(t *testing.T) {
+	defer func(noColor bool) { color.NoColor = noColor }(color.NoColor)
+	color.NoColor = false
+
+	colors := LogLevelColors{Error: []string{"red"}, Warning: []string{"yellow", "bold"}}
+	red := func(str string) string { return ThemedString(str, colors.Error) }
+	yellow := func(str string) string { return ThemedString(str, colors.Warning) }
+
+	type scenario struct {
+		line     string
+		expected string
+	}
+
+	scenarios := []scenario{
+		{"2024-01-01T00:00:00Z ERROR could not connect", red("2024-01-01T00:00:00Z ERROR could not connect")},
+		{"[warn] disk nearly full", yellow("[warn] disk nearly full")},
+		{`level=info msg="error count reset"`, `level=info msg="error count reset"`},
+		{"listening on :8080", "listening on :8080"},
+		{"interrupted by stderr", "interrupted by stderr"},
+		{"\x1b[31mERROR\x1b[0m already colored", "\x1b[31mERROR\x1b[0m already colored"},
+	}
+
+	for _, s := range scenarios {
+		assert.EqualValues(t, s.expected, ColorLogLine(s.line, colors))
+	}
+}
+
+// TestSearchRegex is a function.
+func TestSearchRegex(t *testing.T) {
+	type scenario struct {
+		term          string
+		isRegex       bool
+		caseSensitive bool
+		line          string
+		expected      bool
+	}
+
+	scenarios := []scenario{
+		{"error", false, false, "ERROR oops", true},
+		{"error", false, true, "ERROR oops", false},
+		{"a.c", false, false, "abc", false},
+		{"a.c", true, false, "abc", true},
+		{"timeout|refused", true, false, "connection REFUSED", true},
+	}
+
+	for _, s := range scenarios {
+		re, err := SearchRegex(s.term, s.isRegex, s.caseSensitive)
+		assert.NoError(t, err)
+		assert.EqualValues(t, s.expected, re.MatchString(s.line))
+	}
+
+	_, err := SearchRegex("(", true, false)
+	assert.Error(t, err)
+}
+
+// TestHighlightMatches is a function.
+func TestHighlightMatches(t *testing.T) {
+	type scenario struct {
+		line     string
+		pattern  string
+		expected string
+	}
+
+	scenarios := []scenario{
+		{"no match here", "oops", "no match here"},
+		{"an error and another error", "error", "an \x1b[7merror\x1b[0m and another \x1b[7merror\x1b[0m"},
+		{"\x1b[31mERROR oops\x1b[0m", "oops", "\x1b[31mERROR \x1b[7moops\x1b[0m\x1b[31m\x1b[0m"},
+		{"\x1b[36mweb | \x1b[0mhello", "b \\| h", "\x1b[36mwe\x1b[7mb | \x1b[0m\x1b[7mh\x1b[0mello"},
+		{"aaa", "x*", "aaa"},
+	}
+
+	for _, s := range scenarios {
+		assert.EqualValues(t, s.expected, HighlightMatches(s.line, regexp.MustCompile(s.pattern)))
+	}
+
+	assert.EqualValues(t, "error", HighlightMatches("error", nil))
+}
+
+// TestLogLevelWriter is a function.
+func TestLogLevelWriter(t *testing.T) {
+	defer func(noColor bool) { color.NoColor = noColor }(color.NoColor)
+	color.NoColor = false
+
+	colors := LogLevelColors{Error: []string{"red"}}
+	output := &bytes.Buffer{}
+	writer := NewLogLevelWriter(output, colors)
+
+	_, err := writer.Write([]byte("starting\nERR"))
+	assert.NoError(t, err)
+	assert.EqualValues(t, "starting\n", output.String())
+
+	_, err = writer.Write([]byte("OR oops\nbye"))
+	assert.NoError(t, err)
+	assert.EqualValues(t, "starting\n"+ThemedString("ERROR oops", colors.Error)+"\n", output.String())
+
+	assert.NoError(t, writer.Flush())
+	assert.EqualValues(t, "starting\n"+ThemedString("ERROR oops", colors.Error)+"\nbye", output.String())
+}
+
+// TestThemedString is a function.
+func TestThemedString(t *testing.T) {
+	defer func(noColor bool) { color.NoColor = noColor }(color.NoColor)
+	color.NoColor = false
+
+	assert.EqualValues(t, "plain", ThemedString("plain", nil))
+	assert.EqualValues(t, "plain", ThemedString("plain", []string{"default"}))
+	assert.NotEqual(t, "bold", ThemedString("bold", []string{"bold"}))
+	assert.True(t, IsColorName("white"))
+	assert.True(t, IsColorName("reverse"))
+	assert.False(t, IsColorName("purple"))
+}
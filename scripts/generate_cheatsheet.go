@@ -9,6 +9,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
@@ -33,7 +34,7 @@ func main() {
 
 	for lang := range i18n.GetTranslationSets() {
 		os.Setenv("LC_ALL", lang)
-		mApp, _ := app.NewApp(mConfig)
+		mApp, _ := app.NewApp(context.Background(), mConfig)
 		file, err := os.Create("./docs/keybindings/Keybindings_" + lang + ".md")
 		if err != nil {
 			panic(err)
@@ -81,6 +82,7 @@ func getBindingSections(mApp *app.App) []*bindingSection {
 			"containers": mApp.Tr.ContainersTitle,
 			"images":     mApp.Tr.ImagesTitle,
 			"volumes":    mApp.Tr.VolumesTitle,
+			"networks":   mApp.Tr.NetworksTitle,
 		}
 
 		bindingSections = addBinding(titleMap[viewName], bindingSections, binding)